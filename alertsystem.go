@@ -0,0 +1,266 @@
+// Package alertsystem embeds the alert system in another process (wallet backends, node management tools), instead
+// of running the alert-system binary next to it: the alerts are received from the peers, verified, enforced against
+// the node and saved in the datastore, and the embedder subscribes to the events of the alert processing
+//
+//	conf, err := config.New(config.WithRPCConnection("http://localhost:8332", "user", "password"))
+//	...
+//	system, err := alertsystem.New(conf)
+//	...
+//	system.Events().Subscribe(func(ctx context.Context, e *events.Event) { ... }, events.AlertEnforced)
+//	go system.Run(ctx) // Until the context is done or Stop is called
+package alertsystem
+
+import (
+	"context"
+	"sync"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/app/p2p"
+	"github.com/bitcoin-sv/alert-system/app/shutdown"
+	"github.com/bitcoin-sv/alert-system/app/store"
+	"github.com/bitcoin-sv/alert-system/app/webserver"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/mrz1836/go-datastore"
+)
+
+// Options allow functional options to be supplied to New
+type Options func(s *AlertSystem)
+
+// WithWebServer will serve the web server (the API, health and metrics) on the web_server port
+func WithWebServer() Options {
+	return func(s *AlertSystem) {
+		s.serveWeb = true
+	}
+}
+
+// WithHost will use the libp2p host instead of listening on the P2P IP and port (e.g. an in-memory host), the peers
+// are connected by the caller (no DHT or peer discovery)
+func WithHost(h host.Host) Options {
+	return func(s *AlertSystem) {
+		s.host = h
+	}
+}
+
+// WithServices will use the services (node, logger, HTTP client, clock or datastore client) instead of the ones
+// built from the config, when New loads the services
+func WithServices(opts ...config.ServiceOptions) Options {
+	return func(s *AlertSystem) {
+		s.services = append(s.services, opts...)
+	}
+}
+
+// WithStore will store the alerts and peer bans in the store instead of the datastore (e.g. store.NewMemory), the
+// other records (node actions, outbox events, webhooks...) stay in the datastore
+func WithStore(alertStore store.AlertStore) Options {
+	return func(s *AlertSystem) {
+		s.store = alertStore
+	}
+}
+
+// AlertSystem is the alert system embedded in the process
+type AlertSystem struct {
+	config    *config.Config
+	done      chan struct{} // Closed once Run has shut down
+	host      host.Host
+	mu        sync.Mutex
+	p2p       *p2p.Server
+	ready     chan struct{} // Closed once Run has started
+	running   bool
+	serveWeb  bool
+	services  []config.ServiceOptions
+	stop      chan struct{} // Closed by Stop
+	stopOnce  sync.Once
+	stopped   bool
+	store     store.AlertStore
+	webServer *webserver.Server
+}
+
+// New will load the services of the config (unless they are loaded, see config.New and config.LoadDependencies),
+// save the genesis alert and create the P2P server (started by Run)
+func New(conf *config.Config, opts ...Options) (*AlertSystem, error) {
+	s := &AlertSystem{
+		config: conf,
+		done:   make(chan struct{}),
+		ready:  make(chan struct{}),
+		stop:   make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	// Load the node, datastore and other services
+	ctx := context.Background()
+	if conf.Services.Datastore == nil {
+		if err := conf.LoadServices(ctx, models.BaseModels, false, s.services...); err != nil {
+			return nil, err
+		}
+	}
+
+	// Ensure we have the genesis alert in the database (and in the store, the public keys stay in the database)
+	if err := models.CreateGenesisAlert(ctx, model.WithAllDependencies(conf)); err != nil {
+		conf.CloseAll(ctx)
+		return nil, err
+	}
+	if s.store != nil {
+		if err := s.saveGenesisAlert(ctx); err != nil {
+			conf.CloseAll(ctx)
+			return nil, err
+		}
+	}
+
+	// Create the p2p server (and the web server)
+	var err error
+	if s.p2p, err = p2p.NewServer(p2p.ServerOptions{
+		Config:           conf,
+		DisableDiscovery: conf.Devnet || s.host != nil, // A standalone node, or the peers are connected by the caller
+		Host:             s.host,
+		Store:            s.store,
+		TopicNames:       []string{conf.P2P.TopicName},
+	}); err != nil {
+		conf.CloseAll(ctx)
+		return nil, err
+	}
+	if s.serveWeb {
+		s.webServer = webserver.NewServer(conf, s.p2p)
+	}
+	return s, nil
+}
+
+// saveGenesisAlert will save the genesis alert in the store if it is not there
+func (s *AlertSystem) saveGenesisAlert(ctx context.Context) error {
+	genesis, err := s.store.GetBySequence(ctx, 0)
+	if err != nil || genesis != nil {
+		return err
+	}
+	if genesis, err = models.NewGenesisAlert(nil, model.WithAllDependencies(s.config), model.New()); err != nil {
+		return err
+	}
+	return s.store.SaveAlert(ctx, genesis)
+}
+
+// Run will start the alert system and block until the context is done or Stop is called, then shut it down in order
+// (the alerts being processed are finished before P2P and the datastore are closed)
+func (s *AlertSystem) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return ErrStopped
+	} else if s.running {
+		s.mu.Unlock()
+		return ErrAlreadyRunning
+	}
+	s.running = true
+	s.mu.Unlock()
+	defer close(s.done)
+
+	// Ensure that RPC connection is valid
+	if !s.config.DisableRPCVerification {
+		if _, err := s.config.Services.Node.BestBlockHash(ctx); err != nil {
+			s.shutdown()
+			return err
+		}
+	}
+
+	// Start the p2p server (its background jobs run until it is stopped)
+	if err := s.p2p.Start(context.Background()); err != nil {
+		s.shutdown()
+		return err
+	}
+	if s.webServer != nil {
+		go s.webServer.Serve()
+	}
+	close(s.ready)
+
+	// Wait for the context or Stop
+	select {
+	case <-ctx.Done():
+		s.config.Services.Log.Info("context done, stopping the alert system")
+	case <-s.stop:
+		s.config.Services.Log.Info("stop requested, stopping the alert system")
+	}
+	return s.shutdown()
+}
+
+// shutdown will run the shutdown stages in order (each with its own deadline)
+func (s *AlertSystem) shutdown() error {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+
+	conf := s.config
+	manager := shutdown.New(conf.Services.Log)
+	manager.Add("stop the alert intake", conf.Shutdown.P2P, s.p2p.StopIntake)
+	manager.Add("finish the alerts being processed", conf.Shutdown.Alerts, s.p2p.DrainAlerts)
+	manager.Add("deliver the queued notifications", conf.Shutdown.Notifications, s.p2p.FlushNotifications)
+	if s.webServer != nil {
+		manager.Add("close the web server", conf.WebServer.ShutdownTimeout, s.webServer.Shutdown)
+	}
+	manager.Add("close the p2p server", conf.Shutdown.P2P, s.p2p.Stop)
+	manager.Add("close the datastore", conf.Shutdown.Datastore, func(ctx context.Context) error {
+		conf.CloseAll(ctx)
+		return nil
+	})
+	return manager.Shutdown(context.Background())
+}
+
+// Stop will stop a running alert system and wait until it is shut down (or the context is done)
+// An alert system that was never run only closes its services
+func (s *AlertSystem) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	running, stopped := s.running, s.stopped
+	if !running {
+		s.stopped = true
+	}
+	s.mu.Unlock()
+	if !running {
+		if !stopped {
+			_ = s.p2p.Stop(ctx)
+			s.config.CloseAll(ctx)
+		}
+		return nil
+	}
+
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Ready will return a channel closed once the alert system is running (P2P started)
+func (s *AlertSystem) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// Config will return the config and its services (node, logger, clock...)
+func (s *AlertSystem) Config() *config.Config {
+	return s.config
+}
+
+// Datastore will return the datastore of the alerts (see the models package to query it)
+func (s *AlertSystem) Datastore() datastore.ClientInterface {
+	return s.config.Services.Datastore
+}
+
+// Events will return the event bus of the alert processing (alerts received, verified and enforced, peers banned,
+// node health), the handlers are called synchronously so slow work should be queued
+func (s *AlertSystem) Events() *events.Bus {
+	return s.p2p.Events()
+}
+
+// Store will return the store of the alerts and peer bans (nil if they are stored in the datastore)
+func (s *AlertSystem) Store() store.AlertStore {
+	return s.store
+}
+
+// P2P will return the P2P server (peers, bans, syncing and the alert submission)
+func (s *AlertSystem) P2P() *p2p.Server {
+	return s.p2p
+}
@@ -0,0 +1,111 @@
+package alertsystem
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/bitcoin-sv/alert-system/app/store"
+	"github.com/bitcoin-sv/alert-system/app/testutil"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimeout is the max time for the alert system to start, process an alert and stop
+const testTimeout = 30 * time.Second
+
+// newTestAlertSystem will create an alert system with the test network settings, on an in-memory host
+// (skipped by the short tests)
+func newTestAlertSystem(t *testing.T, opts ...Options) *AlertSystem {
+	if testing.Short() {
+		t.Skip("integration test of an embedded alert system")
+	}
+	mn := mocknet.New()
+	t.Cleanup(func() {
+		_ = mn.Close()
+	})
+	h, err := mn.GenPeer()
+	require.NoError(t, err)
+
+	var conf *config.Config
+	conf, err = config.New(
+		config.WithEnvironment(config.EnvironmentTest),
+		config.WithNodeMock(),
+		config.WithPrivateKeyPath(filepath.Join(t.TempDir(), "key")),
+		config.WithRPCConnection("http://localhost:8332", "user", "pass"),
+	)
+	require.NoError(t, err)
+
+	var system *AlertSystem
+	system, err = New(conf, append(opts, WithHost(h))...)
+	require.NoError(t, err)
+	return system
+}
+
+// TestAlertSystem_Run will test the embedded alert system processes the alerts until it is stopped
+func TestAlertSystem_Run(t *testing.T) {
+	system := newTestAlertSystem(t)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	enforced := make(chan *events.Event, 1)
+	system.Events().Subscribe(func(_ context.Context, e *events.Event) {
+		enforced <- e
+	}, events.AlertEnforced)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- system.Run(ctx)
+	}()
+	select {
+	case <-system.Ready():
+	case <-ctx.Done():
+		require.Fail(t, "not started")
+	}
+	require.ErrorIs(t, system.Run(ctx), ErrAlreadyRunning)
+	assert.NotNil(t, system.Datastore())
+
+	// Process an alert signed with the genesis keys of the test network
+	alert, err := testutil.NewInformationalAlert(1, "embedded")
+	require.NoError(t, err)
+	_, err = system.P2P().SubmitAlert(ctx, alert.Serialize())
+	require.NoError(t, err)
+	select {
+	case e := <-enforced:
+		assert.Equal(t, uint32(1), e.Alert.SequenceNumber)
+		require.NoError(t, e.Err)
+	case <-ctx.Done():
+		require.Fail(t, "alert not enforced")
+	}
+
+	require.NoError(t, system.Stop(ctx))
+	require.NoError(t, <-done)
+	require.NoError(t, system.Stop(ctx), "stopped twice")
+	require.ErrorIs(t, system.Run(ctx), ErrStopped)
+}
+
+// TestAlertSystem_Stop will test an alert system that was never run closes its services
+func TestAlertSystem_Stop(t *testing.T) {
+	system := newTestAlertSystem(t)
+	require.NoError(t, system.Stop(context.Background()))
+	require.ErrorIs(t, system.Run(context.Background()), ErrStopped)
+}
+
+// TestAlertSystem_WithStore will test the genesis alert is saved in the store
+func TestAlertSystem_WithStore(t *testing.T) {
+	memory := store.NewMemory()
+	system := newTestAlertSystem(t, WithStore(memory))
+	defer func() {
+		require.NoError(t, system.Stop(context.Background()))
+	}()
+	assert.Equal(t, store.AlertStore(memory), system.Store())
+
+	genesis, err := memory.GetBySequence(context.Background(), 0)
+	require.NoError(t, err)
+	require.NotNil(t, genesis)
+	assert.True(t, genesis.Processed)
+}
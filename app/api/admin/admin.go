@@ -0,0 +1,2 @@
+// Package admin is for all the admin API requests (requires the admin token)
+package admin
@@ -0,0 +1,96 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/bitcoin-sv/alert-system/app"
+	"github.com/bitcoin-sv/alert-system/app/audit"
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/julienschmidt/httprouter"
+	apirouter "github.com/mrz1836/go-api-router"
+)
+
+// AuditEntriesResponse is the response for the audit log endpoint
+type AuditEntriesResponse struct {
+	Entries    []*audit.Entry `json:"entries"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// auditVerification is the result of verifying the audit log
+type auditVerification struct {
+	Entries int    `json:"entries"`         // Number of entries verified
+	Error   string `json:"error,omitempty"` // Where the chain is broken (if it is)
+	Valid   bool   `json:"valid"`           // True if the hash chain is intact
+}
+
+// verifyAudit will verify the hash chain of the audit log
+func (a *Action) verifyAudit(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Make sure the audit log is enabled
+	if a.Config.Services.Audit == nil {
+		app.APIErrorResponse(w, req, http.StatusServiceUnavailable, app.ErrAuditDisabled)
+		return
+	}
+
+	// Verify the chain (a broken chain is reported, not an error)
+	entries, err := a.Config.Services.Audit.Verify(req.Context())
+	result := &auditVerification{Entries: entries, Valid: err == nil}
+	if errors.Is(err, audit.ErrChainBroken) {
+		a.Logger(req).Errorf("audit log verification failed: %s", err.Error())
+		result.Error = err.Error()
+	} else if err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(w, http.StatusOK, json.NewEncoder(w), result, []string{"entries", "error", "valid"})
+}
+
+// auditEntries will return a page of the audit log entries (in order, from the first entry)
+func (a *Action) auditEntries(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Make sure the audit log is enabled
+	if a.Config.Services.Audit == nil {
+		app.APIErrorResponse(w, req, http.StatusServiceUnavailable, app.ErrAuditDisabled)
+		return
+	}
+
+	// Get the requested page
+	page, err := app.GetPageRequest(req)
+	if err != nil {
+		app.APIErrorResponse(w, req, http.StatusBadRequest, err)
+		return
+	}
+
+	// Get the page of entries (one extra entry to detect the next page)
+	var after uint64
+	if page.Cursor != nil {
+		after = page.Cursor.Sequence
+	}
+	entries, err := a.Config.Services.Audit.Page(req.Context(), after, page.Limit+1)
+	if err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Set the cursor to the last entry in the page
+	var next *utils.Cursor
+	if len(entries) > page.Limit {
+		entries = entries[:page.Limit]
+		last := entries[page.Limit-1]
+		next = &utils.Cursor{Sequence: last.Sequence, Timestamp: last.Time.Unix()}
+	}
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(
+		w,
+		http.StatusOK,
+		json.NewEncoder(w),
+		AuditEntriesResponse{
+			Entries:    entries,
+			NextCursor: app.EncodeNextCursor(next),
+		}, []string{"entries", "next_cursor"})
+}
@@ -0,0 +1,91 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/audit"
+	"github.com/bitcoin-sv/alert-system/app/config"
+	apirouter "github.com/mrz1836/go-api-router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAuditRouter will return the admin router with an audit log of the subjects (nil if no audit log)
+func newTestAuditRouter(t *testing.T, subjects ...string) *apirouter.Router {
+	conf := &config.Config{}
+	conf.Services.Log = &config.ExtendedLogger{Logger: log.New(io.Discard, "", 0)}
+	conf.WebServer.AdminToken = "secret"
+	if subjects != nil {
+		store, err := audit.NewFileStore(filepath.Join(t.TempDir(), "audit.log"))
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = store.Close()
+		})
+		conf.Services.Audit, err = audit.New(context.Background(), store)
+		require.NoError(t, err)
+		for _, subject := range subjects {
+			require.NoError(t, conf.Services.Audit.Record(
+				context.Background(), audit.EventAlertEnforced, audit.ActorNetwork, subject, nil,
+			))
+		}
+	}
+	router := apirouter.New()
+	RegisterRoutes(router, conf, nil)
+	return router
+}
+
+// getAuditPage will get the page of the audit log and return the sequences of the entries and the next cursor
+func getAuditPage(t *testing.T, router *apirouter.Router, query string) ([]uint64, string) {
+	w := serveAdmin(router, "/api/v1/admin/audit"+query, "secret")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response AuditEntriesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	sequences := make([]uint64, 0, len(response.Entries))
+	for _, entry := range response.Entries {
+		sequences = append(sequences, entry.Sequence)
+	}
+	return sequences, response.NextCursor
+}
+
+// TestAction_auditEntries will test listing the audit log entries page by page
+func TestAction_auditEntries(t *testing.T) {
+	t.Parallel()
+
+	t.Run("audit log disabled", func(t *testing.T) {
+		router := newTestAuditRouter(t)
+		assert.Equal(t, http.StatusServiceUnavailable, serveAdmin(router, "/api/v1/admin/audit", "secret").Code)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		router := newTestAuditRouter(t, "1")
+		assert.Equal(t, http.StatusUnauthorized, serveAdmin(router, "/api/v1/admin/audit", "wrong").Code)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		router := newTestAuditRouter(t, "1")
+		assert.Equal(t, http.StatusBadRequest, serveAdmin(router, "/api/v1/admin/audit?cursor=%21", "secret").Code)
+	})
+
+	t.Run("paginated", func(t *testing.T) {
+		router := newTestAuditRouter(t, "1", "2", "3")
+
+		// Each admin request is recorded in the audit log before it is served
+		sequences, next := getAuditPage(t, router, "?limit=2")
+		assert.Equal(t, []uint64{1, 2}, sequences)
+		require.NotEmpty(t, next)
+
+		sequences, next = getAuditPage(t, router, "?limit=2&cursor="+next)
+		assert.Equal(t, []uint64{3, 4}, sequences)
+		require.NotEmpty(t, next)
+
+		sequences, next = getAuditPage(t, router, "?limit=10&cursor="+next)
+		assert.Equal(t, []uint64{5, 6}, sequences)
+		assert.Empty(t, next)
+	})
+}
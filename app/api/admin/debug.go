@@ -0,0 +1,54 @@
+package admin
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// debugHandler will adapt a standard http handler for the router
+func debugHandler(h http.Handler) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		h.ServeHTTP(w, req)
+	}
+}
+
+// pprofHandler will serve the pprof endpoints (/debug/pprof/<name>)
+// The index and named profiles (heap, goroutine, allocs, etc.) are served by pprof.Index
+func pprofHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	switch strings.TrimPrefix(ps.ByName("name"), "/") {
+	case "cmdline":
+		pprof.Cmdline(w, req)
+	case "profile":
+		pprof.Profile(w, req)
+	case "symbol":
+		pprof.Symbol(w, req)
+	case "trace":
+		pprof.Trace(w, req)
+	default:
+		pprof.Index(w, req)
+	}
+}
+
+// goroutineDump will write the stack traces of all goroutines as plain text
+func goroutineDump(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf)
+}
+
+// expvarHandler will serve the published expvars (memstats, cmdline and any app vars)
+var expvarHandler = debugHandler(expvar.Handler())
@@ -0,0 +1,119 @@
+package admin
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	apirouter "github.com/mrz1836/go-api-router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDebugRouter will return the admin router of the config (debug endpoints mounted if enabled)
+func newTestDebugRouter(adminToken string, enableDebug bool) *apirouter.Router {
+	conf := &config.Config{}
+	conf.Services.Log = &config.ExtendedLogger{Logger: log.New(io.Discard, "", 0)}
+	conf.WebServer.AdminToken = adminToken
+	conf.WebServer.EnableDebug = enableDebug
+	router := apirouter.New()
+	RegisterRoutes(router, conf, nil)
+	return router
+}
+
+// serveAdmin will serve the GET request of the path with the bearer token (none if empty)
+func serveAdmin(router *apirouter.Router, path, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if len(token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	router.HTTPRouter.ServeHTTP(w, req)
+	return w
+}
+
+// TestDebugRoutes will test the auth gating of the debug endpoints
+func TestDebugRoutes(t *testing.T) {
+	t.Parallel()
+
+	paths := []string{"/debug/pprof/", "/debug/vars", "/debug/goroutines"}
+
+	t.Run("not mounted unless enabled", func(t *testing.T) {
+		router := newTestDebugRouter("secret", false)
+		for _, path := range paths {
+			assert.Equal(t, http.StatusNotFound, serveAdmin(router, path, "secret").Code, path)
+		}
+	})
+
+	t.Run("admin token not set", func(t *testing.T) {
+		router := newTestDebugRouter("", true)
+		for _, path := range paths {
+			assert.Equal(t, http.StatusForbidden, serveAdmin(router, path, "").Code, path)
+		}
+	})
+
+	t.Run("missing or invalid token", func(t *testing.T) {
+		router := newTestDebugRouter("secret", true)
+		for _, path := range paths {
+			assert.Equal(t, http.StatusUnauthorized, serveAdmin(router, path, "").Code, path)
+			assert.Equal(t, http.StatusUnauthorized, serveAdmin(router, path, "wrong").Code, path)
+		}
+	})
+
+	t.Run("valid token", func(t *testing.T) {
+		router := newTestDebugRouter("secret", true)
+		for _, path := range paths {
+			assert.Equal(t, http.StatusOK, serveAdmin(router, path, "secret").Code, path)
+		}
+	})
+}
+
+// TestDebugHandlers will test the responses of the debug endpoints
+func TestDebugHandlers(t *testing.T) {
+	t.Parallel()
+
+	router := newTestDebugRouter("secret", true)
+
+	t.Run("pprof index", func(t *testing.T) {
+		w := serveAdmin(router, "/debug/pprof/", "secret")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, w.Body.String(), "goroutine")
+		assert.Contains(t, w.Body.String(), "heap")
+	})
+
+	t.Run("pprof named profile", func(t *testing.T) {
+		w := serveAdmin(router, "/debug/pprof/goroutine?debug=1", "secret")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "goroutine profile:")
+	})
+
+	t.Run("pprof cmdline", func(t *testing.T) {
+		w := serveAdmin(router, "/debug/pprof/cmdline", "secret")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+		assert.NotEmpty(t, w.Body.String())
+	})
+
+	t.Run("expvar", func(t *testing.T) {
+		w := serveAdmin(router, "/debug/vars", "secret")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+		var vars map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &vars))
+		assert.Contains(t, vars, "cmdline")
+		assert.Contains(t, vars, "memstats")
+	})
+
+	t.Run("goroutine dump", func(t *testing.T) {
+		w := serveAdmin(router, "/debug/goroutines", "secret")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), "goroutine ")
+		assert.Contains(t, w.Body.String(), "TestDebugHandlers")
+	})
+}
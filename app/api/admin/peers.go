@@ -0,0 +1,95 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app"
+	"github.com/bitcoin-sv/alert-system/app/p2p"
+	"github.com/julienschmidt/httprouter"
+	"github.com/libp2p/go-libp2p/core/peer"
+	apirouter "github.com/mrz1836/go-api-router"
+)
+
+// peerBanFields are the fields returned for a peer ban
+var peerBanFields = []string{"peer_id", "node_address", "reason", "duration", "expires_at", "active", "created_at", "updated_at"}
+
+// banPeer will ban a peer on the P2P network (and optionally on the node)
+func (a *Action) banPeer(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Read params
+	validation := new(app.ValidationError)
+	params := apirouter.GetParams(req)
+	peerID, err := peer.Decode(params.GetString("id"))
+	if err != nil {
+		validation.Add("id", "peer id is invalid")
+	}
+
+	// Parse the duration (empty is a permanent ban)
+	var duration time.Duration
+	if durationStr := params.GetString("duration"); len(durationStr) > 0 {
+		if duration, err = time.ParseDuration(durationStr); err != nil || duration < 0 {
+			validation.Add("duration", "must be a positive duration (e.g. 24h)")
+		}
+	}
+	if err = validation.Err(); err != nil {
+		app.APIErrorResponse(w, req, http.StatusBadRequest, err)
+		return
+	}
+
+	// Make sure the P2P server is running
+	if a.P2P == nil {
+		app.APIErrorResponse(w, req, http.StatusServiceUnavailable, app.ErrP2PNotRunning)
+		return
+	}
+
+	// Ban the peer
+	ban, err := a.P2P.BanPeer(
+		req.Context(), peerID, params.GetString("node_address"), params.GetString("reason"), duration,
+	)
+	if errors.Is(err, p2p.ErrCannotBanSelf) {
+		app.APIErrorResponse(w, req, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	}
+	a.Logger(req).Infof("peer %s banned via admin api", peerID.String())
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(w, http.StatusOK, json.NewEncoder(w), ban, peerBanFields)
+}
+
+// unbanPeer will lift the ban on a peer (and on the node if it was banned there)
+func (a *Action) unbanPeer(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Read params
+	params := apirouter.GetParams(req)
+	peerID, err := peer.Decode(params.GetString("id"))
+	if err != nil {
+		app.APIErrorResponse(w, req, http.StatusBadRequest, errors.New("peer id is invalid"))
+		return
+	}
+
+	// Make sure the P2P server is running
+	if a.P2P == nil {
+		app.APIErrorResponse(w, req, http.StatusServiceUnavailable, app.ErrP2PNotRunning)
+		return
+	}
+
+	// Unban the peer
+	ban, err := a.P2P.UnbanPeer(req.Context(), peerID)
+	if errors.Is(err, p2p.ErrPeerNotBanned) {
+		app.APIErrorResponse(w, req, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	}
+	a.Logger(req).Infof("peer %s unbanned via admin api", peerID.String())
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(w, http.StatusOK, json.NewEncoder(w), ban, peerBanFields)
+}
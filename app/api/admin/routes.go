@@ -0,0 +1,55 @@
+package admin
+
+import (
+	"github.com/bitcoin-sv/alert-system/app"
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/p2p"
+	apirouter "github.com/mrz1836/go-api-router"
+)
+
+// Action is an extension of app.Action for this package
+type Action struct {
+	app.Action
+}
+
+// RegisterRoutes register all the package specific routes
+func RegisterRoutes(router *apirouter.Router, conf *config.Config, p2pServer *p2p.Server) {
+
+	// Load the actions and set the services
+	action := &Action{app.Action{Allowlist: conf.WebServer.AdminAllowlist, Config: conf, P2P: p2pServer}}
+
+	// List the audit log entries (paginated with limit and cursor)
+	router.HTTPRouter.GET(app.APIVersion1+"/admin/audit", action.Request(router, action.RequireAdmin(action.auditEntries)))
+
+	// Verify the audit log hash chain
+	router.HTTPRouter.GET(app.APIVersion1+"/admin/audit/verify", action.Request(router, action.RequireAdmin(action.verifyAudit)))
+
+	// Ban a peer (P2P and optionally the node)
+	router.HTTPRouter.POST(app.APIVersion1+"/admin/peers/:id/ban", action.Request(router, action.RequireAdmin(action.banPeer)))
+
+	// Unban a peer
+	router.HTTPRouter.POST(app.APIVersion1+"/admin/peers/:id/unban", action.Request(router, action.RequireAdmin(action.unbanPeer)))
+
+	// Status summary (peers, sequences, backlog and health)
+	router.HTTPRouter.GET(app.APIVersion1+"/admin/status", action.Request(router, action.RequireAdmin(action.status)))
+
+	// Trigger a sync (with a peer or all connected peers) and poll its progress
+	router.HTTPRouter.POST(app.APIVersion1+"/admin/sync", action.Request(router, action.RequireAdmin(action.startSync)))
+	router.HTTPRouter.GET(app.APIVersion1+"/admin/sync/:id", action.Request(router, action.RequireAdmin(action.syncJob)))
+
+	// Runtime debugging (pprof, expvar and a goroutine dump), only mounted if enabled
+	if conf.WebServer.EnableDebug {
+		router.HTTPRouter.GET("/debug/pprof/*name", action.Request(router, action.RequireAdmin(pprofHandler)))
+		router.HTTPRouter.GET("/debug/vars", action.Request(router, action.RequireAdmin(expvarHandler)))
+		router.HTTPRouter.GET("/debug/goroutines", action.Request(router, action.RequireAdmin(goroutineDump)))
+	}
+
+	// Webhook registration (CRUD)
+	router.HTTPRouter.POST(app.APIVersion1+"/admin/webhooks", action.Request(router, action.RequireAdmin(action.createWebhook)))
+	router.HTTPRouter.GET(app.APIVersion1+"/admin/webhooks", action.Request(router, action.RequireAdmin(action.webhooks)))
+	router.HTTPRouter.GET(app.APIVersion1+"/admin/webhooks/:id", action.Request(router, action.RequireAdmin(action.webhook)))
+	router.HTTPRouter.PUT(app.APIVersion1+"/admin/webhooks/:id", action.Request(router, action.RequireAdmin(action.updateWebhook)))
+	router.HTTPRouter.DELETE(app.APIVersion1+"/admin/webhooks/:id", action.Request(router, action.RequireAdmin(action.deleteWebhook)))
+	router.HTTPRouter.GET(app.APIVersion1+"/admin/webhooks/:id/deliveries", action.Request(router, action.RequireAdmin(action.webhookDeliveries)))
+	router.HTTPRouter.GET(app.APIVersion1+"/admin/webhooks/:id/deliveries/:delivery_id/attempts", action.Request(router, action.RequireAdmin(action.webhookAttempts)))
+}
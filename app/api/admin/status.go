@@ -0,0 +1,29 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bitcoin-sv/alert-system/app"
+	"github.com/julienschmidt/httprouter"
+	apirouter "github.com/mrz1836/go-api-router"
+)
+
+// statusFields are the fields returned for the status summary
+var statusFields = []string{
+	"best_sequence", "health", "last_synced_at", "latest_sequence", "node_error", "node_healthy", "peer_count",
+	"peer_id", "pending_alerts", "pending_events", "time", "uptime_seconds", "version",
+}
+
+// status will return the status summary of the alert system (used by the status command)
+func (a *Action) status(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Make sure the P2P server is running
+	if a.P2P == nil {
+		app.APIErrorResponse(w, req, http.StatusServiceUnavailable, app.ErrP2PNotRunning)
+		return
+	}
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(w, http.StatusOK, json.NewEncoder(w), a.P2P.Status(req.Context()), statusFields)
+}
@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/bitcoin-sv/alert-system/app"
+	"github.com/bitcoin-sv/alert-system/app/p2p"
+	"github.com/julienschmidt/httprouter"
+	"github.com/libp2p/go-libp2p/core/peer"
+	apirouter "github.com/mrz1836/go-api-router"
+)
+
+// syncJobFields are the fields returned for a sync job
+var syncJobFields = []string{"id", "peer_id", "status", "error", "peers", "started_at", "finished_at"}
+
+// startSync will start an on-demand sync with a peer (peer_id) or all connected peers
+func (a *Action) startSync(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Make sure the P2P server is running
+	if a.P2P == nil {
+		app.APIErrorResponse(w, req, http.StatusServiceUnavailable, app.ErrP2PNotRunning)
+		return
+	}
+
+	// Validate the peer (empty is all connected peers)
+	peerID := apirouter.GetParams(req).GetString("peer_id")
+	if len(peerID) > 0 {
+		if _, err := peer.Decode(peerID); err != nil {
+			validation := new(app.ValidationError)
+			validation.Add("peer_id", "peer id is invalid")
+			app.APIErrorResponse(w, req, http.StatusBadRequest, validation)
+			return
+		}
+	}
+
+	// Start the sync
+	job, err := a.P2P.StartSync(peerID)
+	if errors.Is(err, p2p.ErrPeerNotConnected) || errors.Is(err, p2p.ErrNoConnectedPeers) || errors.Is(err, p2p.ErrStandby) ||
+		errors.Is(err, p2p.ErrSyncJobRunning) {
+		app.APIErrorResponse(w, req, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		app.APIErrorResponse(w, req, http.StatusBadRequest, err)
+		return
+	}
+	a.Logger(req).Infof("sync job %s started via admin api", job.ID)
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(w, http.StatusAccepted, json.NewEncoder(w), job, syncJobFields)
+}
+
+// syncJob will return the progress of a sync job
+func (a *Action) syncJob(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Make sure the P2P server is running
+	if a.P2P == nil {
+		app.APIErrorResponse(w, req, http.StatusServiceUnavailable, app.ErrP2PNotRunning)
+		return
+	}
+
+	// Get the job
+	job := a.P2P.SyncJob(apirouter.GetParams(req).GetString("id"))
+	if job == nil {
+		app.APIErrorResponse(w, req, http.StatusNotFound, errors.New("sync job not found"))
+		return
+	}
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(w, http.StatusOK, json.NewEncoder(w), job, syncJobFields)
+}
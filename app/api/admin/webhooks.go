@@ -0,0 +1,266 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bitcoin-sv/alert-system/app"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/app/webhook"
+	"github.com/julienschmidt/httprouter"
+	apirouter "github.com/mrz1836/go-api-router"
+	parameters "github.com/mrz1836/go-parameters"
+)
+
+// webhookFields are the fields returned for a webhook (the secret is never returned)
+var webhookFields = []string{"id", "url", "events", "active", "created_at", "updated_at"}
+
+// createWebhook will register a new webhook
+func (a *Action) createWebhook(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Create the webhook
+	params := apirouter.GetParams(req)
+	hook := models.NewWebhook(model.WithAllDependencies(a.Config), model.New())
+	hook.Active = true
+	if err := setWebhookParams(hook, params); err != nil {
+		app.APIErrorResponse(w, req, http.StatusBadRequest, err)
+		return
+	}
+
+	// Save the webhook
+	if err := hook.Save(req.Context()); err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	}
+	a.Logger(req).Infof("webhook %d registered via admin api", hook.ID)
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(w, http.StatusCreated, json.NewEncoder(w), hook, webhookFields)
+}
+
+// webhooks will return all the registered webhooks
+func (a *Action) webhooks(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Get all the webhooks
+	hooks, err := models.GetWebhooks(req.Context(), nil, model.WithAllDependencies(a.Config))
+	if err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(w, http.StatusOK, json.NewEncoder(w), hooks, webhookFields)
+}
+
+// webhook will return a registered webhook
+func (a *Action) webhook(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Get the webhook
+	hook, ok := a.getWebhook(w, req)
+	if !ok {
+		return
+	}
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(w, http.StatusOK, json.NewEncoder(w), hook, webhookFields)
+}
+
+// updateWebhook will update a registered webhook (only the given fields are updated)
+func (a *Action) updateWebhook(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Get the webhook
+	hook, ok := a.getWebhook(w, req)
+	if !ok {
+		return
+	}
+
+	// Update the fields
+	params := apirouter.GetParams(req)
+	if err := setWebhookParams(hook, params); err != nil {
+		app.APIErrorResponse(w, req, http.StatusBadRequest, err)
+		return
+	}
+	if active, found := params.GetBoolOk("active"); found {
+		hook.Active = active
+	}
+
+	// Save the webhook
+	if err := hook.Save(req.Context()); err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(w, http.StatusOK, json.NewEncoder(w), hook, webhookFields)
+}
+
+// deleteWebhook will delete a registered webhook
+func (a *Action) deleteWebhook(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Get the webhook
+	hook, ok := a.getWebhook(w, req)
+	if !ok {
+		return
+	}
+
+	// Delete the webhook
+	if err := hook.Delete(req.Context()); err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	}
+	a.Logger(req).Infof("webhook %d deleted via admin api", hook.ID)
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(w, http.StatusOK, json.NewEncoder(w), hook, webhookFields)
+}
+
+// WebhookDeliveriesResponse is the response for the webhook deliveries endpoint
+type WebhookDeliveriesResponse struct {
+	Deliveries []*models.WebhookDelivery `json:"deliveries"`
+	NextCursor string                    `json:"next_cursor,omitempty"`
+}
+
+// webhookDeliveries will return the deliveries to a registered webhook (oldest first, paginated)
+func (a *Action) webhookDeliveries(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Get the requested page
+	page, err := app.GetPageRequest(req)
+	if err != nil {
+		app.APIErrorResponse(w, req, http.StatusBadRequest, err)
+		return
+	}
+
+	// Get the webhook
+	hook, ok := a.getWebhook(w, req)
+	if !ok {
+		return
+	}
+
+	// Get the deliveries
+	deliveries, next, err := models.GetWebhookDeliveriesPage(
+		req.Context(), hook.ID, page.Cursor, page.Limit, nil, model.WithAllDependencies(a.Config),
+	)
+	if err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(
+		w,
+		http.StatusOK,
+		json.NewEncoder(w),
+		WebhookDeliveriesResponse{
+			Deliveries: deliveries,
+			NextCursor: app.EncodeNextCursor(next),
+		}, []string{"deliveries", "next_cursor"})
+}
+
+// WebhookAttemptsResponse is the response for the webhook delivery attempts endpoint
+type WebhookAttemptsResponse struct {
+	Attempts []*models.WebhookAttempt `json:"attempts"`
+	Delivery *models.WebhookDelivery  `json:"delivery"`
+}
+
+// webhookAttempts will return the attempts of a delivery to a registered webhook (oldest first)
+func (a *Action) webhookAttempts(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Get the webhook
+	hook, ok := a.getWebhook(w, req)
+	if !ok {
+		return
+	}
+
+	// Get the delivery (of this webhook)
+	deliveryID := apirouter.GetParams(req).GetUint64("delivery_id")
+	if deliveryID == 0 {
+		app.APIErrorResponse(w, req, http.StatusBadRequest, errors.New("delivery id is invalid"))
+		return
+	}
+	delivery, err := models.GetWebhookDeliveryByID(req.Context(), hook.ID, deliveryID, model.WithAllDependencies(a.Config))
+	if err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	} else if delivery == nil {
+		app.APIErrorResponse(w, req, http.StatusNotFound, errors.New("delivery not found"))
+		return
+	}
+
+	// Get the attempts
+	var attempts []*models.WebhookAttempt
+	if attempts, err = models.GetWebhookAttempts(
+		req.Context(), delivery.ID, nil, model.WithAllDependencies(a.Config),
+	); err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(
+		w,
+		http.StatusOK,
+		json.NewEncoder(w),
+		WebhookAttemptsResponse{
+			Attempts: attempts,
+			Delivery: delivery,
+		}, []string{"attempts", "delivery"})
+}
+
+// getWebhook will get the webhook from the id param (writes the error response if not found)
+func (a *Action) getWebhook(w http.ResponseWriter, req *http.Request) (*models.Webhook, bool) {
+	id := apirouter.GetParams(req).GetUint64("id")
+	if id == 0 {
+		app.APIErrorResponse(w, req, http.StatusBadRequest, errors.New("webhook id is invalid"))
+		return nil, false
+	}
+	hook, err := models.GetWebhookByID(req.Context(), id, model.WithAllDependencies(a.Config))
+	if err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return nil, false
+	} else if hook == nil {
+		app.APIErrorResponse(w, req, http.StatusNotFound, errors.New("webhook not found"))
+		return nil, false
+	}
+	return hook, true
+}
+
+// setWebhookParams will validate and set the webhook fields from the params (url is required for new webhooks)
+// All invalid fields are returned in the validation error
+func setWebhookParams(hook *models.Webhook, params *parameters.Params) error {
+	validation := new(app.ValidationError)
+
+	// Set the URL
+	if url, ok := params.GetStringOk("url"); ok || hook.ID == 0 {
+		if len(url) == 0 {
+			validation.Add("url", "is required")
+		} else if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			validation.Add("url", fmt.Sprintf("[%s] does not have a valid prefix (http:// or https://)", url))
+		} else {
+			hook.URL = url
+		}
+	}
+
+	// Set the event filter
+	if events, ok := params.GetStringSliceOk("events"); ok {
+		valid := true
+		for _, event := range events {
+			if event = strings.TrimSpace(event); len(event) > 0 && !webhook.IsValidEvent(event) {
+				validation.Add("events", fmt.Sprintf("event [%s] is not supported", event))
+				valid = false
+			}
+		}
+		if valid {
+			hook.SetEvents(events)
+		}
+	}
+
+	// Set the shared secret
+	if secret, ok := params.GetStringOk("secret"); ok {
+		hook.Secret = secret
+	}
+	return validation.Err()
+}
@@ -16,6 +16,9 @@ import (
 	apirouter "github.com/mrz1836/go-api-router"
 )
 
+// alertFields are the fields returned for an alert
+var alertFields = []string{"sequence", "raw", "text", "alert_type"}
+
 // alerts will return the saved
 func (a *Action) alert(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	// Read params
@@ -47,31 +50,40 @@ func (a *Action) alert(w http.ResponseWriter, req *http.Request, _ httprouter.Pa
 		app.APIErrorResponse(w, req, http.StatusNotFound, errors.New("alert not found"))
 		return
 	}
-	err = alertModel.ReadRaw()
-	if err != nil {
-		app.APIErrorResponse(w, req, http.StatusInternalServerError, errors.New("alert faile"))
-		return
-	}
-	am := alertModel.ProcessAlertMessage()
-	if am == nil {
-		app.APIErrorResponse(w, req, http.StatusInternalServerError, errors.New("alert not valid type"))
+
+	// The payload of a saved alert never changes (the processed flag is not in it), the ETag is derived from the sequence
+	if app.NotModified(w, req, app.ETag("alert", strconv.FormatUint(uint64(alertModel.SequenceNumber), 10))) {
 		return
 	}
-	err = am.Read(alertModel.GetRawMessage())
-	if err != nil {
+	var p *webhook.Payload
+	if p, err = alertPayload(alertModel); err != nil {
 		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
 		return
 	}
-	p := webhook.Payload{
-		AlertType: alertModel.GetAlertType(),
-		Sequence:  alertModel.SequenceNumber,
-		Raw:       hex.EncodeToString(alertModel.GetRawData()),
-		Text:      am.MessageString(),
-	}
 	// Return the response
 	_ = apirouter.ReturnJSONEncode(
 		w,
 		http.StatusOK,
 		json.NewEncoder(w),
-		p, []string{"sequence", "raw", "text", "alert_type"})
+		p, alertFields)
+}
+
+// alertPayload will decode the saved alert (its type, raw data and message)
+func alertPayload(alertModel *models.AlertMessage) (*webhook.Payload, error) {
+	if err := alertModel.ReadRaw(); err != nil {
+		return nil, errors.New("alert faile")
+	}
+	am := alertModel.ProcessAlertMessage()
+	if am == nil {
+		return nil, errors.New("alert not valid type")
+	}
+	if err := am.Read(alertModel.GetRawMessage()); err != nil {
+		return nil, err
+	}
+	return &webhook.Payload{
+		AlertType: alertModel.GetAlertType(),
+		Sequence:  alertModel.SequenceNumber,
+		Raw:       hex.EncodeToString(alertModel.GetRawData()),
+		Text:      am.MessageString(),
+	}, nil
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/bitcoin-sv/alert-system/app"
 	"github.com/bitcoin-sv/alert-system/app/models"
@@ -16,11 +17,43 @@ import (
 type AlertsResponse struct {
 	Alerts         []*models.AlertMessage `json:"alerts"`
 	LatestSequence uint32                 `json:"latest_sequence"`
+	NextCursor     string                 `json:"next_cursor,omitempty"`
 }
 
-// alerts will return the saved
+// alertsResponseFields are the fields returned for the alerts endpoint
+var alertsResponseFields = []string{"alerts", "latest_sequence", "next_cursor"}
+
+// alerts will return the saved alerts (paginated if a cursor or limit is given)
+// The ETag is derived from the latest sequence and the counts of the alerts and the unprocessed alerts, pollers can
+// send If-None-Match to get a 304 until an alert is saved or processed
 func (a *Action) alerts(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 
+	// Get the requested page
+	page, err := app.GetPageRequest(req)
+	if err != nil {
+		app.APIErrorResponse(w, req, http.StatusBadRequest, err)
+		return
+	}
+
+	// Get the latest alert (the ETag changes when an alert is saved or processed)
+	latest, err := models.GetLatestAlert(req.Context(), nil, model.WithAllDependencies(a.Config))
+	if err != nil {
+		app.APIErrorResponse(w, req, http.StatusBadRequest, err)
+		return
+	} else if latest != nil {
+		var etag string
+		if etag, err = a.alertsETag(req, latest); err != nil {
+			app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+			return
+		} else if app.NotModified(w, req, etag) {
+			return
+		}
+	}
+	if page.Requested {
+		a.alertsPage(w, req, page, latest)
+		return
+	}
+
 	// Get all alerts
 	alerts, err := models.GetAllAlerts(req.Context(), nil, model.WithAllDependencies(a.Config))
 	if err != nil {
@@ -39,5 +72,48 @@ func (a *Action) alerts(w http.ResponseWriter, req *http.Request, _ httprouter.P
 		AlertsResponse{
 			Alerts:         alerts,
 			LatestSequence: alerts[len(alerts)-1].SequenceNumber,
-		}, []string{"alerts", "latest_sequence"})
+		}, alertsResponseFields)
+}
+
+// alertsETag will return the ETag of the alerts (changes when an older alert is backfilled or an alert is processed)
+func (a *Action) alertsETag(req *http.Request, latest *models.AlertMessage) (string, error) {
+	count, err := models.CountAlertsInRange(
+		req.Context(), 0, latest.SequenceNumber, model.WithAllDependencies(a.Config),
+	)
+	if err != nil {
+		return "", err
+	}
+	var unprocessed int64
+	if unprocessed, err = models.CountUnprocessedAlerts(req.Context(), model.WithAllDependencies(a.Config)); err != nil {
+		return "", err
+	}
+	return app.ETag(
+		"alerts", strconv.FormatUint(uint64(latest.SequenceNumber), 10), strconv.FormatInt(count, 10),
+		strconv.FormatInt(unprocessed, 10), req.URL.RawQuery,
+	), nil
+}
+
+// alertsPage will return a page of the saved alerts (latest is the latest alert, can be nil)
+func (a *Action) alertsPage(w http.ResponseWriter, req *http.Request, page *app.PageRequest, latest *models.AlertMessage) {
+
+	// Get the page of alerts
+	alerts, next, err := models.GetAlertsPage(
+		req.Context(), page.Cursor, page.Limit, nil, model.WithAllDependencies(a.Config),
+	)
+	if err != nil {
+		app.APIErrorResponse(w, req, http.StatusBadRequest, err)
+		return
+	}
+
+	// The latest sequence is for the whole history, not the page
+	res := AlertsResponse{
+		Alerts:     alerts,
+		NextCursor: app.EncodeNextCursor(next),
+	}
+	if latest != nil {
+		res.LatestSequence = latest.SequenceNumber
+	}
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(w, http.StatusOK, json.NewEncoder(w), res, alertsResponseFields)
 }
@@ -0,0 +1,20 @@
+package base
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// dashboard will serve the embedded status dashboard (the page loads its data from the JSON endpoints)
+func (a *Action) dashboard(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	page, err := content.ReadFile("ui/templates/dashboard.html")
+	if err != nil {
+		a.Logger(req).Errorf("failed to load dashboard: %s", err.Error())
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(page)
+}
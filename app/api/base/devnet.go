@@ -0,0 +1,99 @@
+package base
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/bitcoin-sv/alert-system/app"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/app/p2p"
+	"github.com/bitcoin-sv/alert-system/app/webhook"
+	"github.com/julienschmidt/httprouter"
+	"github.com/libsv/go-p2p/wire"
+	apirouter "github.com/mrz1836/go-api-router"
+)
+
+// signDevnetAlert will sign a test alert with the throwaway genesis keys of the devnet, then process it like a
+// gossiped alert (enforced against the mock node and saved) and publish it to the devnet peers
+// The alert is the next sequence, its message is the hex message (the wire format of its alert_type) or the text
+// of an informational alert
+func (a *Action) signDevnetAlert(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Make sure the P2P server is running
+	signer := a.Config.Services.Devnet
+	if a.P2P == nil || signer == nil {
+		app.APIErrorResponse(w, req, http.StatusServiceUnavailable, app.ErrP2PNotRunning)
+		return
+	}
+
+	// Read the alert type and message
+	params := apirouter.GetParams(req)
+	alertType := models.AlertType(params.GetUint64("alert_type"))
+	if alertType == 0 {
+		alertType = models.AlertTypeInformational
+	}
+	validation := new(app.ValidationError)
+	message, err := hex.DecodeString(params.GetString("message"))
+	if err != nil {
+		validation.Add("message", "message must be hex")
+	} else if text := params.GetString("text"); len(message) == 0 && len(text) > 0 {
+		if alertType != models.AlertTypeInformational {
+			validation.Add("text", "text is only for informational alerts, set the hex message")
+		}
+		var buf bytes.Buffer
+		_ = wire.WriteVarInt(&buf, 0, uint64(len(text)))
+		buf.WriteString(text)
+		message = buf.Bytes()
+	} else if len(message) == 0 {
+		validation.Add("message", "message or text is required")
+	}
+	if len(alertType.Name()) == 0 {
+		validation.Add("alert_type", "alert type is unknown")
+	}
+	if err = validation.Err(); err != nil {
+		app.APIErrorResponse(w, req, http.StatusBadRequest, err)
+		return
+	}
+
+	// Sign the next sequence (one alert at a time)
+	signer.Lock()
+	defer signer.Unlock()
+	var latest *models.AlertMessage
+	if latest, err = models.GetLatestAlert(req.Context(), nil, model.WithAllDependencies(a.Config)); err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	} else if latest == nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, errors.New("genesis alert not found"))
+		return
+	}
+	var raw []byte
+	if raw, err = signer.Sign(latest.SequenceNumber+1, uint32(alertType), message); err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Process and publish the alert (not saved if its message is invalid)
+	var alert *models.AlertMessage
+	if alert, err = a.P2P.SubmitAlert(req.Context(), raw); errors.Is(err, p2p.ErrAlertNotSaved) {
+		app.APIErrorResponse(w, req, http.StatusUnprocessableEntity, err)
+		return
+	} else if alert == nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	} else if err != nil {
+		a.Logger(req).Errorf("error publishing devnet alert %d: %s", alert.SequenceNumber, err.Error())
+	}
+	a.Logger(req).Infof("devnet alert %d signed via api", alert.SequenceNumber)
+
+	// Return the response
+	var p *webhook.Payload
+	if p, err = alertPayload(alert); err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	}
+	_ = apirouter.ReturnJSONEncode(w, http.StatusCreated, json.NewEncoder(w), p, alertFields)
+}
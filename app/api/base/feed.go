@@ -0,0 +1,85 @@
+package base
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bitcoin-sv/alert-system/app"
+	"github.com/bitcoin-sv/alert-system/app/feed"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/julienschmidt/httprouter"
+	apirouter "github.com/mrz1836/go-api-router"
+)
+
+// Feed formats
+const (
+	feedFormatAtom = "atom"
+	feedFormatRSS  = "rss"
+)
+
+// feedRSS will return the recent alerts as an RSS 2.0 feed
+func (a *Action) feedRSS(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	a.feed(w, req, feedFormatRSS)
+}
+
+// feedAtom will return the recent alerts as an Atom 1.0 feed
+func (a *Action) feedAtom(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	a.feed(w, req, feedFormatAtom)
+}
+
+// feed will return the recent alerts in the format (newest first, up to the limit param)
+// The ETag is derived from the latest sequence, feed readers get a 304 until a new alert is saved
+func (a *Action) feed(w http.ResponseWriter, req *http.Request, format string) {
+
+	// Get the requested size
+	limit, ok := apirouter.GetParams(req).GetIntOk(app.ParamLimit)
+	if !ok {
+		limit = feed.DefaultSize
+	}
+
+	// Get the recent alerts
+	alerts, err := models.GetRecentAlerts(req.Context(), limit, nil, model.WithAllDependencies(a.Config))
+	if err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	}
+	latest := ""
+	if len(alerts) > 0 {
+		latest = strconv.FormatUint(uint64(alerts[0].SequenceNumber), 10)
+	}
+	if app.NotModified(w, req, app.ETag("feed", format, latest, strconv.Itoa(limit))) {
+		return
+	}
+
+	// Render the feed
+	baseURL := a.publicURL(req)
+	f := feed.New(alerts, baseURL, baseURL+req.URL.RequestURI())
+	body, contentType := []byte(nil), feed.ContentTypeRSS
+	if format == feedFormatAtom {
+		body, err = f.Atom()
+		contentType = feed.ContentTypeAtom
+	} else {
+		body, err = f.RSS()
+	}
+	if err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// publicURL will return the external URL of the web server (the request host if not configured)
+func (a *Action) publicURL(req *http.Request) string {
+	if len(a.Config.WebServer.PublicURL) > 0 {
+		return strings.TrimSuffix(a.Config.WebServer.PublicURL, "/")
+	}
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + req.Host
+}
@@ -0,0 +1,22 @@
+package base
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/health"
+	"github.com/julienschmidt/httprouter"
+	apirouter "github.com/mrz1836/go-api-router"
+)
+
+// live will return ok while the web server is serving (no subsystem is checked, see ready)
+// It is the liveness probe: a failing dependency does not make the process worth restarting
+func (a *Action) live(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	_ = apirouter.ReturnJSONEncode(
+		w,
+		http.StatusOK,
+		json.NewEncoder(w),
+		&health.Report{Checks: []*health.Check{}, Status: health.StatusOK, Time: time.Now().UTC()},
+		[]string{"checks", "status", "time"})
+}
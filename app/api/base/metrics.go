@@ -0,0 +1,16 @@
+package base
+
+import (
+	"net/http"
+
+	"github.com/bitcoin-sv/alert-system/app/metrics"
+	"github.com/julienschmidt/httprouter"
+)
+
+// metricsHandler serves the registered Prometheus metrics (text exposition format)
+var metricsHandler = metrics.Handler()
+
+// metrics will return the Prometheus metrics for scraping
+func (a *Action) metrics(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	metricsHandler.ServeHTTP(w, req)
+}
@@ -0,0 +1,49 @@
+package base
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bitcoin-sv/alert-system/app"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/julienschmidt/httprouter"
+	apirouter "github.com/mrz1836/go-api-router"
+)
+
+// NodeActionsResponse is the response for the node actions endpoint
+type NodeActionsResponse struct {
+	Actions    []*models.NodeAction `json:"actions"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+// nodeActions will return a page of the actions executed against the nodes (optionally filtered by rpc_host)
+func (a *Action) nodeActions(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Get the requested page
+	page, err := app.GetPageRequest(req)
+	if err != nil {
+		app.APIErrorResponse(w, req, http.StatusBadRequest, err)
+		return
+	}
+
+	// Get the page of actions
+	rpcHost, _ := apirouter.GetParams(req).GetStringOk("rpc_host")
+	actions, next, err := models.GetNodeActionsPage(
+		req.Context(), rpcHost, page.Cursor, page.Limit, nil, model.WithAllDependencies(a.Config),
+	)
+	if err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(
+		w,
+		http.StatusOK,
+		json.NewEncoder(w),
+		NodeActionsResponse{
+			Actions:    actions,
+			NextCursor: app.EncodeNextCursor(next),
+		}, []string{"actions", "next_cursor"})
+}
@@ -0,0 +1,102 @@
+package base
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bitcoin-sv/alert-system/app"
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/julienschmidt/httprouter"
+	apirouter "github.com/mrz1836/go-api-router"
+)
+
+// NodeStatus is the status of a configured node (RPC connection)
+type NodeStatus struct {
+	BannedPeers     int                `json:"banned_peers"`
+	BestBlockHash   string             `json:"best_block_hash"`
+	BlockHeight     uint32             `json:"block_height"`
+	Error           string             `json:"error,omitempty"`
+	Host            string             `json:"host"`
+	LastAction      *models.NodeAction `json:"last_action"`
+	ProtocolVersion int                `json:"protocol_version"`
+	Reachable       bool               `json:"reachable"`
+	SubVersion      string             `json:"sub_version"`
+	Version         int                `json:"version"`
+}
+
+// NodesResponse is the response for the nodes endpoint
+type NodesResponse struct {
+	Nodes []*NodeStatus `json:"nodes"`
+}
+
+// nodes will return the status of each configured node (RPC connection)
+func (a *Action) nodes(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Get the status of each node
+	nodes := make([]*NodeStatus, 0, len(a.Config.Services.Nodes))
+	for _, node := range a.Config.Services.Nodes {
+		status, err := a.nodeStatus(req, node)
+		if err != nil {
+			app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+			return
+		}
+		nodes = append(nodes, status)
+	}
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(
+		w,
+		http.StatusOK,
+		json.NewEncoder(w),
+		NodesResponse{
+			Nodes: nodes,
+		}, []string{"nodes"})
+}
+
+// nodeStatus will get the status of the node (an unreachable node is not an error)
+func (a *Action) nodeStatus(req *http.Request, node config.NodeInterface) (*NodeStatus, error) {
+	status := &NodeStatus{Host: node.GetRPCHost()}
+
+	// Get the last action executed against the node (recorded for each node of a pool)
+	var err error
+	if status.LastAction, err = models.GetLatestNodeAction(
+		req.Context(), status.Host, nil, model.WithAllDependencies(a.Config),
+	); err != nil {
+		return nil, err
+	}
+
+	// Get the network info (determines if the node is reachable)
+	info, err := node.NetworkInfo(req.Context())
+	if err != nil {
+		status.Error = err.Error()
+		return status, nil
+	}
+	status.Reachable = true
+	if info != nil {
+		status.ProtocolVersion = info.ProtocolVersion
+		status.SubVersion = info.SubVersion
+		status.Version = info.Version
+	}
+
+	// Get the best block
+	if status.BlockHeight, err = node.BlockCount(req.Context()); err != nil {
+		status.Error = err.Error()
+		return status, nil
+	}
+	if status.BestBlockHash, err = node.BestBlockHash(req.Context()); err != nil {
+		status.Error = err.Error()
+		return status, nil
+	}
+
+	// Get the banned peers
+	banned, err := node.ListBanned(req.Context())
+	if err != nil {
+		status.Error = err.Error()
+		return status, nil
+	}
+	status.BannedPeers = len(banned)
+
+	return status, nil
+}
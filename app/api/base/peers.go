@@ -0,0 +1,76 @@
+package base
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/bitcoin-sv/alert-system/app"
+	"github.com/bitcoin-sv/alert-system/app/p2p"
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/julienschmidt/httprouter"
+	apirouter "github.com/mrz1836/go-api-router"
+)
+
+// PeersResponse is the response for the peers endpoint
+type PeersResponse struct {
+	Connected  bool            `json:"connected"`
+	Count      int             `json:"count"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	Peers      []*p2p.PeerInfo `json:"peers"`
+}
+
+// peers will return the connected peers and their sync status (paginated if a cursor or limit is given)
+func (a *Action) peers(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Get the requested page
+	page, err := app.GetPageRequest(req)
+	if err != nil {
+		app.APIErrorResponse(w, req, http.StatusBadRequest, err)
+		return
+	}
+
+	// Make sure the P2P server is running
+	if a.P2P == nil {
+		app.APIErrorResponse(w, req, http.StatusServiceUnavailable, app.ErrP2PNotRunning)
+		return
+	}
+
+	// Get the connected peers
+	peers := a.P2P.Peers()
+	count := len(peers)
+	var next *utils.Cursor
+	if page.Requested {
+		peers, next = pagePeers(peers, page)
+	}
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(
+		w,
+		http.StatusOK,
+		json.NewEncoder(w),
+		PeersResponse{
+			Connected:  a.P2P.Connected(),
+			Count:      count,
+			NextCursor: app.EncodeNextCursor(next),
+			Peers:      peers,
+		}, []string{"connected", "count", "next_cursor", "peers"})
+}
+
+// pagePeers will return the page of peers (ordered by peer ID) and the cursor for the next page
+func pagePeers(peers []*p2p.PeerInfo, page *app.PageRequest) ([]*p2p.PeerInfo, *utils.Cursor) {
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].ID < peers[j].ID
+	})
+	if page.Cursor != nil {
+		start := sort.Search(len(peers), func(i int) bool {
+			return peers[i].ID > page.Cursor.ID
+		})
+		peers = peers[start:]
+	}
+	if len(peers) <= page.Limit {
+		return peers, nil
+	}
+	peers = peers[:page.Limit]
+	return peers, &utils.Cursor{ID: peers[page.Limit-1].ID}
+}
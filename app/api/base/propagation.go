@@ -0,0 +1,28 @@
+package base
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bitcoin-sv/alert-system/app"
+	"github.com/julienschmidt/httprouter"
+	apirouter "github.com/mrz1836/go-api-router"
+)
+
+// propagation will return how the recent gossip messages propagated to this node
+// (the peer that delivered each message first and how long after it the duplicates arrived from the other peers)
+func (a *Action) propagation(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Make sure the P2P server is running
+	if a.P2P == nil {
+		app.APIErrorResponse(w, req, http.StatusServiceUnavailable, app.ErrP2PNotRunning)
+		return
+	}
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(
+		w,
+		http.StatusOK,
+		json.NewEncoder(w),
+		a.P2P.Propagation(), []string{"duplicates", "first_seen", "messages", "peers"})
+}
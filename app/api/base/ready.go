@@ -0,0 +1,35 @@
+package base
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bitcoin-sv/alert-system/app"
+	"github.com/julienschmidt/httprouter"
+	apirouter "github.com/mrz1836/go-api-router"
+)
+
+// ready will return the aggregated health of the alert system (each subsystem check and the overall status)
+// Responds with 503 if a critical check failed, a degraded system is still ready
+func (a *Action) ready(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Make sure the P2P server is running
+	if a.P2P == nil {
+		app.APIErrorResponse(w, req, http.StatusServiceUnavailable, app.ErrP2PNotRunning)
+		return
+	}
+
+	// Check the subsystems
+	report := a.P2P.Health(req.Context())
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+	}
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(
+		w,
+		status,
+		json.NewEncoder(w),
+		report, []string{"checks", "status", "time"})
+}
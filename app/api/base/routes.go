@@ -5,6 +5,7 @@ import (
 
 	"github.com/bitcoin-sv/alert-system/app"
 	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/p2p"
 	apirouter "github.com/mrz1836/go-api-router"
 )
 
@@ -14,10 +15,10 @@ type Action struct {
 }
 
 // RegisterRoutes register all the package specific routes
-func RegisterRoutes(router *apirouter.Router, conf *config.Config) {
+func RegisterRoutes(router *apirouter.Router, conf *config.Config, p2pServer *p2p.Server) {
 
 	// Load the actions and set the services
-	action := &Action{app.Action{Config: conf}}
+	action := &Action{app.Action{Allowlist: conf.WebServer.APIAllowlist, Config: conf, P2P: p2pServer}}
 
 	// Set the main index page (navigating to slash or the root of the major version)
 	router.HTTPRouter.GET("/", action.Request(router, action.index))
@@ -34,12 +35,68 @@ func RegisterRoutes(router *apirouter.Router, conf *config.Config) {
 	// Set the method not allowed
 	router.HTTPRouter.MethodNotAllowed = http.HandlerFunc(app.MethodNotAllowed)
 
+	// Set the status dashboard
+	router.HTTPRouter.GET("/dashboard", action.Request(router, action.dashboard))
+
 	// Set the health request
-	router.HTTPRouter.GET("/health", action.Request(router, action.health))
+	router.HTTPRouter.GET(app.APIVersion1+"/health", action.Request(router, action.health))
+
+	// Set the liveness request (the web server is serving)
+	router.HTTPRouter.GET("/livez", action.Request(router, action.live))
+
+	// Set the readiness request (aggregated health of the subsystems)
+	router.HTTPRouter.GET("/readyz", action.Request(router, action.ready))
+
+	// Set the build info request
+	router.HTTPRouter.GET(app.APIVersion1+"/version", action.Request(router, action.version))
 
 	// Set the get alerts request
-	router.HTTPRouter.GET("/alerts", action.Request(router, action.alerts))
+	router.HTTPRouter.GET(app.APIVersion1+"/alerts", action.Request(router, action.alerts))
+
+	// Set the search alerts request
+	router.HTTPRouter.GET(app.APIVersion1+"/alerts/search", action.Request(router, action.searchAlerts))
+
+	// Set the feeds of the recent alerts (RSS and Atom)
+	router.HTTPRouter.GET(app.APIVersion1+"/alerts/feed.rss", action.Request(router, action.feedRSS))
+	router.HTTPRouter.GET(app.APIVersion1+"/alerts/feed.atom", action.Request(router, action.feedAtom))
 
 	// Set the get alert request
-	router.HTTPRouter.GET("/alert/:sequence", action.Request(router, action.alert))
+	router.HTTPRouter.GET(app.APIVersion1+"/alert/:sequence", action.Request(router, action.alert))
+
+	// Legacy (unversioned) routes, deprecated in favor of the v1 routes
+	router.HTTPRouter.GET("/health", action.Request(router, action.Deprecated(action.health)))
+	router.HTTPRouter.GET("/alerts", action.Request(router, action.Deprecated(action.alerts)))
+	router.HTTPRouter.GET("/alert/:sequence", action.Request(router, action.Deprecated(action.alert)))
+
+	// Set the get nodes request
+	router.HTTPRouter.GET(app.APIVersion1+"/nodes", action.Request(router, action.nodes))
+
+	// Set the get node actions request
+	router.HTTPRouter.GET(app.APIVersion1+"/node-actions", action.Request(router, action.nodeActions))
+
+	// Set the sync status request
+	router.HTTPRouter.GET(app.APIVersion1+"/sync", action.Request(router, action.syncStatus))
+
+	// Set the get peers request
+	router.HTTPRouter.GET(app.APIVersion1+"/peers", action.Request(router, action.peers))
+
+	// Set the gossip propagation report request
+	router.HTTPRouter.GET(app.APIVersion1+"/propagation", action.Request(router, action.propagation))
+
+	// Set the JSON-RPC service (if enabled)
+	if conf.WebServer.EnableRPC {
+		rpc := action.rpc()
+		router.HTTPRouter.POST("/", action.Request(router, rpc))
+		router.HTTPRouter.POST(app.APIVersion1+"/rpc", action.Request(router, rpc))
+	}
+
+	// Sign the test alerts with the throwaway genesis keys (only on the devnet)
+	if conf.Devnet {
+		router.HTTPRouter.POST(app.APIVersion1+"/devnet/alerts", action.Request(router, action.signDevnetAlert))
+	}
+
+	// Set the Prometheus metrics (if enabled)
+	if conf.WebServer.EnableMetrics {
+		router.HTTPRouter.GET("/metrics", action.Request(router, action.metrics))
+	}
 }
@@ -0,0 +1,159 @@
+package base
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"github.com/bitcoin-sv/alert-system/app"
+	"github.com/bitcoin-sv/alert-system/app/buildinfo"
+	"github.com/bitcoin-sv/alert-system/app/jsonrpc"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/julienschmidt/httprouter"
+)
+
+// JSON-RPC methods
+const (
+	rpcGetAlertBySequence   = "getalertbysequence"
+	rpcGetAlerts            = "getalerts"
+	rpcGetAlertSystemStatus = "getalertsystemstatus"
+)
+
+// RPCAlert is an alert returned by the JSON-RPC methods (decoded like the alert endpoint)
+type RPCAlert struct {
+	AlertName string `json:"alert_name"`
+	AlertType uint32 `json:"alert_type"`
+	Hash      string `json:"hash"`
+	Raw       string `json:"raw"`
+	Sequence  uint32 `json:"sequence"`
+	Text      string `json:"text,omitempty"` // Decoded alert message (empty if it cannot be decoded)
+	Timestamp uint64 `json:"timestamp"`
+}
+
+// RPCAlertsResult is the result of getalerts
+type RPCAlertsResult struct {
+	Alerts         []*RPCAlert `json:"alerts"`
+	LatestSequence uint32      `json:"latest_sequence"`
+	NextCursor     string      `json:"next_cursor,omitempty"`
+}
+
+// RPCStatusResult is the result of getalertsystemstatus
+type RPCStatusResult struct {
+	BestPeerSequence uint32 `json:"best_peer_sequence"`
+	Connected        bool   `json:"connected"` // Connected to the alert network
+	Lag              uint32 `json:"lag"`
+	LatestSequence   uint32 `json:"latest_sequence"`
+	Peers            int    `json:"peers"`
+	Synced           bool   `json:"synced"`
+	Version          string `json:"version"`
+}
+
+// rpc will return the handler of the JSON-RPC service
+func (a *Action) rpc() httprouter.Handle {
+	server := jsonrpc.NewServer()
+	server.Register(rpcGetAlertBySequence, a.rpcGetAlertBySequence)
+	server.Register(rpcGetAlerts, a.rpcGetAlerts)
+	server.Register(rpcGetAlertSystemStatus, a.rpcGetAlertSystemStatus)
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		server.ServeHTTP(w, req)
+	}
+}
+
+// rpcGetAlerts will return a page of the saved alerts (params: limit, cursor)
+func (a *Action) rpcGetAlerts(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Cursor string `json:"cursor"`
+		Limit  int    `json:"limit"`
+	}
+	if err := jsonrpc.Bind(params, &p, "limit", "cursor"); err != nil {
+		return nil, err
+	}
+	cursor, err := utils.DecodeCursor(p.Cursor)
+	if err != nil {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, err.Error())
+	}
+
+	// Get the page of alerts
+	var alerts []*models.AlertMessage
+	var next *utils.Cursor
+	if alerts, next, err = models.GetAlertsPage(ctx, cursor, p.Limit, nil, model.WithAllDependencies(a.Config)); err != nil {
+		return nil, err
+	}
+	result := &RPCAlertsResult{Alerts: make([]*RPCAlert, 0, len(alerts)), NextCursor: app.EncodeNextCursor(next)}
+	for _, alert := range alerts {
+		result.Alerts = append(result.Alerts, newRPCAlert(alert))
+	}
+
+	// The latest sequence is for the whole history, not the page
+	var latest *models.AlertMessage
+	if latest, err = models.GetLatestAlert(ctx, nil, model.WithAllDependencies(a.Config)); err != nil {
+		return nil, err
+	} else if latest != nil {
+		result.LatestSequence = latest.SequenceNumber
+	}
+	return result, nil
+}
+
+// rpcGetAlertBySequence will return the saved alert (params: sequence)
+func (a *Action) rpcGetAlertBySequence(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Sequence uint32 `json:"sequence"`
+	}
+	if err := jsonrpc.Bind(params, &p, "sequence"); err != nil {
+		return nil, err
+	} else if p.Sequence == 0 {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "sequence is required")
+	}
+
+	alert, err := models.GetAlertMessageBySequenceNumber(ctx, p.Sequence, model.WithAllDependencies(a.Config))
+	if err != nil {
+		return nil, err
+	} else if alert == nil {
+		return nil, jsonrpc.NewError(jsonrpc.CodeNotFound, "alert not found")
+	}
+	return newRPCAlert(alert), nil
+}
+
+// rpcGetAlertSystemStatus will return the version, the latest sequence and the sync state with the peers
+func (a *Action) rpcGetAlertSystemStatus(ctx context.Context, _ json.RawMessage) (interface{}, error) {
+	result := &RPCStatusResult{Version: buildinfo.Get().Version}
+
+	// Get the latest local alert
+	latest, err := models.GetLatestAlert(ctx, nil, model.WithAllDependencies(a.Config))
+	if err != nil {
+		return nil, err
+	} else if latest != nil {
+		result.LatestSequence = latest.SequenceNumber
+	}
+
+	// Get the best sequence observed from the peers
+	if a.P2P != nil {
+		result.BestPeerSequence = a.P2P.SyncState().BestSequence
+		result.Connected = a.P2P.Connected()
+		result.Peers = len(a.P2P.Peers())
+	}
+	if result.BestPeerSequence > result.LatestSequence {
+		result.Lag = result.BestPeerSequence - result.LatestSequence
+	}
+	result.Synced = result.Lag == 0
+	return result, nil
+}
+
+// newRPCAlert will decode the saved alert
+func newRPCAlert(alert *models.AlertMessage) *RPCAlert {
+	r := &RPCAlert{Raw: alert.Raw, Sequence: alert.SequenceNumber}
+	if alert.ReadRaw() == nil {
+		r.Raw = hex.EncodeToString(alert.GetRawData())
+		r.Timestamp = alert.Timestamp()
+		if am := alert.ProcessAlertMessage(); am != nil && am.Read(alert.GetRawMessage()) == nil {
+			r.Text = am.MessageString()
+		}
+	}
+	r.AlertName = alert.GetAlertType().Name()
+	r.AlertType = uint32(alert.GetAlertType())
+	r.Hash = alert.Hash
+	return r
+}
@@ -0,0 +1,76 @@
+package base
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/bitcoin-sv/alert-system/app"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/julienschmidt/httprouter"
+	apirouter "github.com/mrz1836/go-api-router"
+)
+
+// searchParams maps the search query params to the searchable fields (q searches for a single word)
+var searchParams = map[string]string{
+	"block_hash": models.SearchFieldBlockHash,
+	"peer":       models.SearchFieldPeer,
+	"q":          models.SearchFieldWord,
+	"txid":       models.SearchFieldTxID,
+}
+
+// SearchResponse is the response for the alert search endpoint
+type SearchResponse struct {
+	Alerts     []*models.AlertMessage `json:"alerts"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+}
+
+// searchAlerts will return a page of alerts matching a decoded payload field
+// e.g. ?txid=<txid> (freeze, unfreeze and confiscation alerts), ?peer=<ip> (ban alerts), ?q=<word>
+func (a *Action) searchAlerts(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+
+	// Get the search field (exactly one is required)
+	params := apirouter.GetParams(req)
+	var field, value string
+	for param, searchField := range searchParams {
+		if v, ok := params.GetStringOk(param); ok && len(v) > 0 {
+			if len(field) > 0 {
+				app.APIErrorResponse(w, req, http.StatusBadRequest, errors.New("only one search field is supported per request"))
+				return
+			}
+			field, value = searchField, v
+		}
+	}
+	if len(field) == 0 {
+		app.APIErrorResponse(w, req, http.StatusBadRequest, errors.New("a search field is required (txid, peer, block_hash or q)"))
+		return
+	}
+
+	// Get the requested page
+	page, err := app.GetPageRequest(req)
+	if err != nil {
+		app.APIErrorResponse(w, req, http.StatusBadRequest, err)
+		return
+	}
+
+	// Search the alerts
+	alerts, next, err := models.SearchAlerts(
+		req.Context(), field, value, models.AlertType(params.GetUint64("type")), page.Cursor, page.Limit,
+		model.WithAllDependencies(a.Config),
+	)
+	if err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(
+		w,
+		http.StatusOK,
+		json.NewEncoder(w),
+		SearchResponse{
+			Alerts:     alerts,
+			NextCursor: app.EncodeNextCursor(next),
+		}, []string{"alerts", "next_cursor"})
+}
@@ -0,0 +1,73 @@
+package base
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/julienschmidt/httprouter"
+	apirouter "github.com/mrz1836/go-api-router"
+)
+
+// maxReportedGaps is the max number of missing sequence numbers returned
+const maxReportedGaps = 100
+
+// SyncResponse is the response for the sync status endpoint
+type SyncResponse struct {
+	BestPeerID       string     `json:"best_peer_id,omitempty"`
+	BestPeerSequence uint32     `json:"best_peer_sequence"`
+	Gaps             []uint32   `json:"gaps"`
+	Lag              uint32     `json:"lag"`
+	LastSyncedAt     *time.Time `json:"last_synced_at"`
+	LatestSequence   uint32     `json:"latest_sequence"`
+	Synced           bool       `json:"synced"`
+}
+
+// syncStatus will return the sync status (local vs best observed peer sequence and any gaps)
+// Responds with 503 if we are behind the peers or missing alerts, so load balancers can key off the status
+func (a *Action) syncStatus(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	res := SyncResponse{}
+
+	// Get the latest local alert
+	latest, err := models.GetLatestAlert(req.Context(), nil, model.WithAllDependencies(a.Config))
+	if err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	} else if latest != nil {
+		res.LatestSequence = latest.SequenceNumber
+	}
+
+	// Get any gaps in the local alerts
+	if res.Gaps, err = models.GetMissingSequences(
+		req.Context(), maxReportedGaps, model.WithAllDependencies(a.Config),
+	); err != nil {
+		app.APIErrorResponse(w, req, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Get the best sequence observed from the peers
+	if a.P2P != nil {
+		state := a.P2P.SyncState()
+		res.BestPeerID = state.BestPeerID
+		res.BestPeerSequence = state.BestSequence
+		res.LastSyncedAt = state.LastSyncedAt
+	}
+	if res.BestPeerSequence > res.LatestSequence {
+		res.Lag = res.BestPeerSequence - res.LatestSequence
+	}
+	res.Synced = res.Lag == 0 && len(res.Gaps) == 0
+
+	// Return the response
+	status := http.StatusOK
+	if !res.Synced {
+		status = http.StatusServiceUnavailable
+	}
+	_ = apirouter.ReturnJSONEncode(
+		w,
+		status,
+		json.NewEncoder(w),
+		res, []string{"best_peer_id", "best_peer_sequence", "gaps", "lag", "last_synced_at", "latest_sequence", "synced"})
+}
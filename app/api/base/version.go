@@ -0,0 +1,21 @@
+package base
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bitcoin-sv/alert-system/app/buildinfo"
+	"github.com/julienschmidt/httprouter"
+	apirouter "github.com/mrz1836/go-api-router"
+)
+
+// version will return the build info of the running alert system (version, commit, build date and features)
+func (a *Action) version(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+
+	// Return the response
+	_ = apirouter.ReturnJSONEncode(
+		w,
+		http.StatusOK,
+		json.NewEncoder(w),
+		buildinfo.Get(), []string{"commit", "date", "dirty", "features", "go_version", "platform", "version"})
+}
@@ -2,30 +2,44 @@
 package app
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/p2p"
 	apirouter "github.com/mrz1836/go-api-router"
 )
 
+// APIVersion1 is the route prefix for the v1 API
+const APIVersion1 = "/api/v1"
+
 // Action is the configuration for the actions and related services
 type Action struct {
-	Config *config.Config // Combination of configuration and services, being passed down into the handlers
+	Allowlist []string       // Client IPs/CIDRs allowed to use the route group (all if empty)
+	Config    *config.Config // Combination of configuration and services, being passed down into the handlers
+	P2P       *p2p.Server    // P2P server (peers, bans and syncing), can be nil if not running
 }
 
 // APIError is the enriched error message for API related errors
 type APIError struct {
-	Message    string `json:"message" url:"message"`         // Public error message
-	StatusCode int    `json:"status_code" url:"status_code"` // Associated HTTP status code (should be in request as well)
+	Errors     []FieldError `json:"errors,omitempty" url:"errors"`         // Invalid fields (validation errors)
+	Message    string       `json:"message" url:"message"`                 // Public error message
+	RequestID  string       `json:"request_id,omitempty" url:"request_id"` // Request (correlation) ID for tracing
+	StatusCode int          `json:"status_code" url:"status_code"`         // Associated HTTP status code (should be in request as well)
 }
 
 // APIErrorResponse will return an error response message
+// Validation errors are returned with each invalid field (errors)
 func APIErrorResponse(w http.ResponseWriter, req *http.Request, statusCode int, err error) {
-	apirouter.ReturnResponse(
-		w, req, statusCode,
-		&APIError{
-			Message:    err.Error(),
-			StatusCode: statusCode,
-		},
-	)
+	apiErr := &APIError{
+		Message:    err.Error(),
+		RequestID:  GetRequestID(req.Context()),
+		StatusCode: statusCode,
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		apiErr.Errors = validationErr.Fields
+		apiErr.Message = ErrRequestInvalid.Error()
+	}
+	apirouter.ReturnResponse(w, req, statusCode, apiErr)
 }
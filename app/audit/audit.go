@@ -0,0 +1,147 @@
+// Package audit is the append-only audit log of the security-relevant events (alerts enforced,
+// admin API calls, keys and configuration loaded)
+// Each entry includes the hash of the previous entry, so editing or removing an entry breaks the chain
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Event types
+const (
+	EventAdminDenied   = "admin.denied"   // Admin API call was rejected (missing or invalid token)
+	EventAdminRequest  = "admin.request"  // Admin API call was authorized
+	EventAlertEnforced = "alert.enforced" // Alert action was executed against the node
+	EventConfigLoaded  = "config.loaded"  // Configuration was loaded
+	EventKeyLoaded     = "key.loaded"     // P2P private key was loaded (or generated)
+)
+
+// Actors (who caused the event)
+const (
+	ActorNetwork = "network" // Alert received from the network (or synced from a peer)
+	ActorSystem  = "system"  // The alert system itself
+)
+
+// Entry is an audit log entry
+type Entry struct {
+	Actor    string            `json:"actor"`             // Who caused the event (system, network or the admin client IP)
+	Details  map[string]string `json:"details,omitempty"` // Event specific details
+	Hash     string            `json:"hash"`              // SHA-256 of the entry (without the hash)
+	PrevHash string            `json:"prev_hash"`         // Hash of the previous entry (empty for the first entry)
+	Sequence uint64            `json:"sequence"`          // Position in the log (starts at 1)
+	Subject  string            `json:"subject"`           // What the event is about (alert sequence, route, key path, etc.)
+	Time     time.Time         `json:"time"`              // When the event occurred (UTC)
+	Type     string            `json:"type"`              // Event type
+}
+
+// Store is where the audit log entries are appended (a file or the datastore)
+type Store interface {
+	Append(ctx context.Context, entry *Entry) error
+	Entries(ctx context.Context) ([]*Entry, error)
+	Last(ctx context.Context) (*Entry, error)
+	Page(ctx context.Context, after uint64, limit int) ([]*Entry, error)
+}
+
+// Log is the hash-chained audit log
+// A nil log is valid and records nothing (audit logging is disabled)
+type Log struct {
+	last  *Entry
+	mu    sync.Mutex
+	store Store
+}
+
+// New will create the audit log, continuing the chain from the last entry in the store
+func New(ctx context.Context, store Store) (*Log, error) {
+	last, err := store.Last(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Log{last: last, store: store}, nil
+}
+
+// Record will append the event to the audit log
+func (l *Log) Record(ctx context.Context, eventType, actor, subject string, details map[string]string) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry := &Entry{
+		Actor:    actor,
+		Details:  details,
+		Sequence: 1,
+		Subject:  subject,
+		Time:     time.Now().UTC().Truncate(time.Microsecond),
+		Type:     eventType,
+	}
+	if l.last != nil {
+		entry.PrevHash = l.last.Hash
+		entry.Sequence = l.last.Sequence + 1
+	}
+	var err error
+	if entry.Hash, err = Hash(entry); err != nil {
+		return err
+	}
+	if err = l.store.Append(ctx, entry); err != nil {
+		return err
+	}
+	l.last = entry
+	return nil
+}
+
+// Verify will verify the hash chain of all the entries in the store
+func (l *Log) Verify(ctx context.Context) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries, err := l.store.Entries(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return len(entries), Verify(entries)
+}
+
+// Page will return up to limit entries after the sequence (in order, the first entries if the sequence is 0)
+func (l *Log) Page(ctx context.Context, after uint64, limit int) ([]*Entry, error) {
+	return l.store.Page(ctx, after, limit)
+}
+
+// Verify will verify the hash chain of the entries (in order)
+func Verify(entries []*Entry) error {
+	prevHash := ""
+	for i, entry := range entries {
+		if entry.Sequence != uint64(i+1) {
+			return fmt.Errorf("%w: expected sequence %d, got %d", ErrChainBroken, i+1, entry.Sequence)
+		}
+		if entry.PrevHash != prevHash {
+			return fmt.Errorf("%w: previous hash mismatch at sequence %d", ErrChainBroken, entry.Sequence)
+		}
+		hash, err := Hash(entry)
+		if err != nil {
+			return err
+		}
+		if hash != entry.Hash {
+			return fmt.Errorf("%w: hash mismatch at sequence %d", ErrChainBroken, entry.Sequence)
+		}
+		prevHash = entry.Hash
+	}
+	return nil
+}
+
+// Hash will return the SHA-256 (hex) of the entry without its hash (details are sorted by key)
+func Hash(entry *Entry) (string, error) {
+	unhashed := *entry
+	unhashed.Hash = ""
+	b, err := json.Marshal(&unhashed)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,153 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestLog will create an audit log with a file store in a temp directory
+func newTestLog(t *testing.T) (*Log, *FileStore) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "audit.log"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = store.Close()
+	})
+	var l *Log
+	l, err = New(context.Background(), store)
+	require.NoError(t, err)
+	return l, store
+}
+
+// TestLog_Record will test recording the events with a hash chain
+func TestLog_Record(t *testing.T) {
+	t.Run("entries are chained", func(t *testing.T) {
+		l, store := newTestLog(t)
+		ctx := context.Background()
+		require.NoError(t, l.Record(ctx, EventConfigLoaded, ActorSystem, "test", nil))
+		require.NoError(t, l.Record(ctx, EventAlertEnforced, ActorNetwork, "7", map[string]string{"success": "true"}))
+
+		entries, err := store.Entries(ctx)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, uint64(1), entries[0].Sequence)
+		assert.Empty(t, entries[0].PrevHash)
+		assert.Len(t, entries[0].Hash, 64)
+		assert.Equal(t, uint64(2), entries[1].Sequence)
+		assert.Equal(t, entries[0].Hash, entries[1].PrevHash)
+		assert.Equal(t, "true", entries[1].Details["success"])
+
+		var count int
+		count, err = l.Verify(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("chain continues after a restart", func(t *testing.T) {
+		l, store := newTestLog(t)
+		ctx := context.Background()
+		require.NoError(t, l.Record(ctx, EventKeyLoaded, ActorSystem, "key", nil))
+
+		restarted, err := New(ctx, store)
+		require.NoError(t, err)
+		require.NoError(t, restarted.Record(ctx, EventAdminRequest, "127.0.0.1", "/v1/admin/sync", nil))
+
+		var entries []*Entry
+		entries, err = store.Entries(ctx)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, uint64(2), entries[1].Sequence)
+		require.NoError(t, Verify(entries))
+	})
+
+	t.Run("nil log records nothing", func(t *testing.T) {
+		var l *Log
+		require.NoError(t, l.Record(context.Background(), EventAdminDenied, "127.0.0.1", "/v1/admin/sync", nil))
+	})
+}
+
+// TestLog_Page will test reading the entries after a sequence
+func TestLog_Page(t *testing.T) {
+	l, _ := newTestLog(t)
+	ctx := context.Background()
+	for _, subject := range []string{"1", "2", "3", "4", "5"} {
+		require.NoError(t, l.Record(ctx, EventAlertEnforced, ActorNetwork, subject, nil))
+	}
+
+	tests := []struct {
+		name     string
+		after    uint64
+		limit    int
+		expected []string
+	}{
+		{"first page", 0, 2, []string{"1", "2"}},
+		{"next page", 2, 2, []string{"3", "4"}},
+		{"last page", 4, 2, []string{"5"}},
+		{"after the last entry", 5, 2, []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entries, err := l.Page(ctx, test.after, test.limit)
+			require.NoError(t, err)
+			subjects := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				subjects = append(subjects, entry.Subject)
+			}
+			assert.Equal(t, test.expected, subjects)
+		})
+	}
+}
+
+// TestVerify will test detecting edited, removed and inserted entries
+func TestVerify(t *testing.T) {
+	newEntries := func(t *testing.T) []*Entry {
+		l, store := newTestLog(t)
+		ctx := context.Background()
+		for _, subject := range []string{"1", "2", "3"} {
+			require.NoError(t, l.Record(ctx, EventAlertEnforced, ActorNetwork, subject, nil))
+		}
+		entries, err := store.Entries(ctx)
+		require.NoError(t, err)
+		require.NoError(t, Verify(entries))
+		return entries
+	}
+
+	t.Run("edited entry", func(t *testing.T) {
+		entries := newEntries(t)
+		entries[1].Subject = "99"
+		require.ErrorIs(t, Verify(entries), ErrChainBroken)
+	})
+
+	t.Run("edited entry with a new hash", func(t *testing.T) {
+		entries := newEntries(t)
+		entries[1].Subject = "99"
+		var err error
+		entries[1].Hash, err = Hash(entries[1])
+		require.NoError(t, err)
+		require.ErrorIs(t, Verify(entries), ErrChainBroken)
+	})
+
+	t.Run("removed entry", func(t *testing.T) {
+		entries := newEntries(t)
+		require.ErrorIs(t, Verify(append(entries[:1], entries[2:]...)), ErrChainBroken)
+	})
+
+	t.Run("edited file", func(t *testing.T) {
+		l, store := newTestLog(t)
+		ctx := context.Background()
+		require.NoError(t, l.Record(ctx, EventAdminRequest, "10.0.0.1", "/v1/admin/sync", nil))
+		require.NoError(t, l.Record(ctx, EventAdminRequest, "10.0.0.1", "/v1/admin/webhooks", nil))
+
+		b, err := os.ReadFile(store.path)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(store.path, []byte(strings.Replace(string(b), "10.0.0.1", "10.0.0.2", 1)), 0o600))
+
+		_, err = l.Verify(ctx)
+		require.ErrorIs(t, err, ErrChainBroken)
+	})
+}
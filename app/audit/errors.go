@@ -0,0 +1,6 @@
+package audit
+
+import "errors"
+
+// ErrChainBroken is when an audit log entry was edited, removed or inserted
+var ErrChainBroken = errors.New("audit log hash chain is broken")
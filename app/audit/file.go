@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// FileStore appends the audit log entries to a file (one JSON entry per line)
+// The file is opened append-only and synced after every entry
+type FileStore struct {
+	file *os.File
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileStore will open (or create) the audit log file
+func NewFileStore(path string) (*FileStore, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) //nolint:gosec // Path is from the config
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{file: file, path: path}, nil
+}
+
+// Append will write the entry and sync the file
+func (s *FileStore) Append(_ context.Context, entry *Entry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err = s.file.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// Entries will read all the entries in the file
+func (s *FileStore) Entries(_ context.Context) ([]*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	entries := make([]*Entry, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		entry := new(Entry)
+		if err = json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			return nil, fmt.Errorf("%w: invalid entry: %s", ErrChainBroken, err.Error())
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Last will return the last entry in the file (nil if the file is empty)
+func (s *FileStore) Last(ctx context.Context) (*Entry, error) {
+	entries, err := s.Entries(ctx)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return entries[len(entries)-1], nil
+}
+
+// Page will return up to limit entries after the sequence (the file is read from the start)
+func (s *FileStore) Page(ctx context.Context, after uint64, limit int) ([]*Entry, error) {
+	entries, err := s.Entries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	page := make([]*Entry, 0, limit)
+	for _, entry := range entries {
+		if len(page) == limit {
+			break
+		} else if entry.Sequence > after {
+			page = append(page, entry)
+		}
+	}
+	return page, nil
+}
+
+// Close will close the file
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,220 @@
+// Package budget is the memory and goroutine budget of the alert system, so a deployment co-located with the node
+// (bitcoind) cannot grow without bound and starve it. The soft memory limit of the Go runtime (GOMEMLIMIT) is set from
+// the config and the concurrent handlers (API requests and P2P sync streams) are capped, the usage is exported in the
+// metrics and a warning is logged once a resource is used above the warning percent of its limit
+package budget
+
+import (
+	"math"
+	"os"
+	"runtime/debug"
+	"runtime/metrics"
+	"sync"
+	"time"
+
+	appmetrics "github.com/bitcoin-sv/alert-system/app/metrics"
+)
+
+// Monitor defaults
+const (
+	DefaultInterval    = 30 * time.Second // Default interval between the usage samples
+	DefaultWarnPercent = 90.0             // Default percent of a limit used before warning
+)
+
+// Resources of the budget (metrics labels)
+const (
+	ResourceAPIHandlers    = "api_handlers"    // Concurrent API requests
+	ResourceMemory         = "memory"          // Memory of the Go runtime (bytes)
+	ResourceStreamHandlers = "stream_handlers" // Concurrent P2P sync streams served to the peers
+)
+
+// envMemoryLimit is the environment variable of the Go runtime soft memory limit (wins over the config)
+const envMemoryLimit = "GOMEMLIMIT"
+
+// SetMemoryLimit will set the soft memory limit of the Go runtime (the garbage collector works harder as it is
+// approached), unless GOMEMLIMIT is set. Returns the limit in effect (math.MaxInt64 if none) and if it is from GOMEMLIMIT
+func SetMemoryLimit(bytes int64) (limit int64, fromEnv bool) {
+	if len(os.Getenv(envMemoryLimit)) > 0 {
+		limit, fromEnv = debug.SetMemoryLimit(-1), true
+	} else if bytes > 0 {
+		debug.SetMemoryLimit(bytes)
+		limit = bytes
+	} else {
+		limit = debug.SetMemoryLimit(-1)
+	}
+	if limit < math.MaxInt64 {
+		appmetrics.BudgetLimit.WithLabelValues(ResourceMemory).Set(float64(limit))
+	}
+	return limit, fromEnv
+}
+
+// Limiter caps the concurrent handlers of a resource (each is a goroutine), a nil limiter has no limit
+type Limiter struct {
+	resource string
+	slots    chan struct{}
+}
+
+// NewLimiter will create the limiter of up to max concurrent handlers (nil if max <= 0, no limit)
+func NewLimiter(resource string, max int) *Limiter {
+	if max <= 0 {
+		return nil
+	}
+	appmetrics.BudgetLimit.WithLabelValues(resource).Set(float64(max))
+	return &Limiter{resource: resource, slots: make(chan struct{}, max)}
+}
+
+// Acquire will take a handler slot without waiting (false if all are taken, the handler must be rejected)
+func (l *Limiter) Acquire() bool {
+	if l == nil {
+		return true
+	}
+	select {
+	case l.slots <- struct{}{}:
+		appmetrics.BudgetUsed.WithLabelValues(l.resource).Inc()
+		return true
+	default:
+		appmetrics.BudgetRejected.WithLabelValues(l.resource).Inc()
+		return false
+	}
+}
+
+// Release will return the handler slot taken by Acquire()
+func (l *Limiter) Release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+	appmetrics.BudgetUsed.WithLabelValues(l.resource).Dec()
+}
+
+// InUse will return the number of handlers running
+func (l *Limiter) InUse() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.slots)
+}
+
+// Max will return the max concurrent handlers (0 if no limit)
+func (l *Limiter) Max() int {
+	if l == nil {
+		return 0
+	}
+	return cap(l.slots)
+}
+
+// MonitorOptions are the options for the budget monitor
+type MonitorOptions struct {
+	Interval    time.Duration                             // Interval between the samples (DefaultInterval if 0)
+	Limiters    []*Limiter                                // Handler limiters to watch (nil ones are ignored)
+	MemoryLimit int64                                     // Memory limit in bytes (0 or math.MaxInt64 to ignore the memory)
+	Memory      func() uint64                             // Samples the memory (the Go runtime memory if nil)
+	OnWarning   func(resource string, used, limit uint64) // Called once a resource goes above the warning percent
+	WarnPercent float64                                   // Percent of a limit used before warning (DefaultWarnPercent if 0)
+}
+
+// Monitor samples the usage of the budget and warns once a resource is approaching its limit (again once it went
+// back below the warning percent)
+type Monitor struct {
+	done    chan struct{}
+	mu      sync.Mutex
+	opts    MonitorOptions
+	quit    chan struct{}
+	started bool
+	warned  map[string]bool // Resources above the warning percent at the last sample
+}
+
+// NewMonitor will create the monitor, call Start() to sample the usage on the interval
+func NewMonitor(opts MonitorOptions) *Monitor {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.WarnPercent <= 0 {
+		opts.WarnPercent = DefaultWarnPercent
+	}
+	if opts.MemoryLimit == math.MaxInt64 {
+		opts.MemoryLimit = 0
+	}
+	if opts.Memory == nil {
+		opts.Memory = runtimeMemory
+	}
+	return &Monitor{
+		done:   make(chan struct{}),
+		opts:   opts,
+		quit:   make(chan struct{}),
+		warned: make(map[string]bool),
+	}
+}
+
+// Start will sample the usage on the interval until Stop() is called
+func (m *Monitor) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.started {
+		return
+	}
+	m.started = true
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(m.opts.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.Check()
+			case <-m.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop will stop the sampling
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	started := m.started
+	m.mu.Unlock()
+	close(m.quit)
+	if started {
+		<-m.done
+	}
+}
+
+// Check will sample the usage once, returns the resources approaching their limit
+func (m *Monitor) Check() []string {
+	var approaching []string
+	if m.opts.MemoryLimit > 0 {
+		used := m.opts.Memory()
+		appmetrics.BudgetUsed.WithLabelValues(ResourceMemory).Set(float64(used))
+		if m.approaching(ResourceMemory, used, uint64(m.opts.MemoryLimit)) {
+			approaching = append(approaching, ResourceMemory)
+		}
+	}
+	for _, l := range m.opts.Limiters {
+		if l != nil && m.approaching(l.resource, uint64(l.InUse()), uint64(l.Max())) {
+			approaching = append(approaching, l.resource)
+		}
+	}
+	return approaching
+}
+
+// approaching will return true if the resource is above the warning percent of its limit (warns once it goes above)
+func (m *Monitor) approaching(resource string, used, limit uint64) bool {
+	above := float64(used) >= float64(limit)*m.opts.WarnPercent/100
+	if above && !m.warned[resource] && m.opts.OnWarning != nil {
+		m.opts.OnWarning(resource, used, limit)
+	}
+	m.warned[resource] = above
+	return above
+}
+
+// runtimeMemory will return the memory obtained from the OS by the Go runtime (and not released), the memory
+// counted against the soft memory limit
+func runtimeMemory() uint64 {
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/released:bytes"},
+	}
+	metrics.Read(samples)
+	return samples[0].Value.Uint64() - samples[1].Value.Uint64()
+}
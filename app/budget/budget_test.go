@@ -0,0 +1,116 @@
+package budget
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLimiter will test the cap of the concurrent handlers
+func TestLimiter(t *testing.T) {
+	t.Run("no limit", func(t *testing.T) {
+		l := NewLimiter(ResourceAPIHandlers, 0)
+		require.Nil(t, l)
+		assert.True(t, l.Acquire())
+		l.Release()
+		assert.Equal(t, 0, l.InUse())
+		assert.Equal(t, 0, l.Max())
+	})
+
+	t.Run("rejected at the limit", func(t *testing.T) {
+		l := NewLimiter(ResourceAPIHandlers, 2)
+		require.NotNil(t, l)
+		assert.True(t, l.Acquire())
+		assert.True(t, l.Acquire())
+		assert.False(t, l.Acquire())
+		assert.Equal(t, 2, l.InUse())
+
+		l.Release()
+		assert.Equal(t, 1, l.InUse())
+		assert.True(t, l.Acquire())
+		assert.Equal(t, 2, l.Max())
+	})
+
+	t.Run("concurrent handlers", func(t *testing.T) {
+		l := NewLimiter(ResourceStreamHandlers, 4)
+		var wg sync.WaitGroup
+		for i := 0; i < 16; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if l.Acquire() {
+					assert.LessOrEqual(t, l.InUse(), 4)
+					l.Release()
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 0, l.InUse())
+	})
+}
+
+// TestMonitor_Check will test the warnings once a resource approaches its limit
+func TestMonitor_Check(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		var used uint64 = 50
+		var warnings []string
+		m := NewMonitor(MonitorOptions{
+			MemoryLimit: 100,
+			Memory:      func() uint64 { return used },
+			OnWarning: func(resource string, used, limit uint64) {
+				warnings = append(warnings, resource)
+				assert.Equal(t, uint64(100), limit)
+			},
+		})
+		assert.Empty(t, m.Check())
+
+		used = 95
+		assert.Equal(t, []string{ResourceMemory}, m.Check())
+		assert.Equal(t, []string{ResourceMemory}, m.Check())
+		assert.Len(t, warnings, 1, "warned once while above")
+
+		used = 10
+		assert.Empty(t, m.Check())
+		used = 99
+		assert.Equal(t, []string{ResourceMemory}, m.Check())
+		assert.Len(t, warnings, 2, "warned again once back above")
+	})
+
+	t.Run("no memory limit", func(t *testing.T) {
+		m := NewMonitor(MonitorOptions{
+			MemoryLimit: math.MaxInt64,
+			Memory:      func() uint64 { return math.MaxUint64 },
+		})
+		assert.Empty(t, m.Check())
+	})
+
+	t.Run("handlers", func(t *testing.T) {
+		l := NewLimiter(ResourceStreamHandlers, 4)
+		var warnings []string
+		m := NewMonitor(MonitorOptions{
+			Limiters:    []*Limiter{l, nil},
+			OnWarning:   func(resource string, _, _ uint64) { warnings = append(warnings, resource) },
+			WarnPercent: 75,
+		})
+		require.True(t, l.Acquire())
+		require.True(t, l.Acquire())
+		assert.Empty(t, m.Check())
+
+		require.True(t, l.Acquire())
+		assert.Equal(t, []string{ResourceStreamHandlers}, m.Check())
+		assert.Equal(t, []string{ResourceStreamHandlers}, warnings)
+	})
+}
+
+// TestMonitor_Start will test the monitor can be started and stopped
+func TestMonitor_Start(t *testing.T) {
+	m := NewMonitor(MonitorOptions{})
+	m.Start()
+	m.Start()
+	m.Stop()
+
+	NewMonitor(MonitorOptions{}).Stop()
+}
@@ -0,0 +1,151 @@
+// Package buildinfo is the version, commit, build date and features of the alert system binary
+// The values are embedded at build time with -ldflags, for example:
+//
+//	-X github.com/bitcoin-sv/alert-system/app/buildinfo.Version=v1.2.0
+//	-X github.com/bitcoin-sv/alert-system/app/buildinfo.Commit=<sha>
+//	-X github.com/bitcoin-sv/alert-system/app/buildinfo.Date=<RFC3339 date>
+//
+// Values that are not set fall back to the VCS and module info recorded by the Go toolchain
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Unknown is the value of the build info fields that could not be determined
+const Unknown = "unknown"
+
+// Set at build time with -ldflags "-X"
+var (
+	Commit   = ""    // Git commit SHA
+	Date     = ""    // Build date (RFC3339)
+	Features = ""    // Comma separated features enabled at build time (merged with the build tags)
+	Version  = "dev" // Release version (e.g. v1.2.0)
+)
+
+// Info is the build info of the alert system binary
+type Info struct {
+	Commit    string   `json:"commit"`
+	Date      string   `json:"date"`
+	Dirty     bool     `json:"dirty"`      // Built from a modified working tree
+	Features  []string `json:"features"`   // Build tags and features (sorted)
+	GoVersion string   `json:"go_version"` // Go toolchain version
+	Platform  string   `json:"platform"`   // OS/architecture
+	Version   string   `json:"version"`
+}
+
+// String will return the build info as a single line
+func (i *Info) String() string {
+	s := i.Version + " (commit " + i.ShortCommit()
+	if i.Dirty {
+		s += "-dirty"
+	}
+	s += ", built " + i.Date + ", " + i.GoVersion + " " + i.Platform + ")"
+	if len(i.Features) > 0 {
+		s += " features: " + strings.Join(i.Features, ",")
+	}
+	return s
+}
+
+// ShortCommit will return the first 12 characters of the commit
+func (i *Info) ShortCommit() string {
+	if len(i.Commit) > 12 {
+		return i.Commit[:12]
+	}
+	return i.Commit
+}
+
+var (
+	info     *Info
+	infoOnce sync.Once
+)
+
+// Get will return the build info of the running binary (resolved once)
+func Get() *Info {
+	infoOnce.Do(func() {
+		bi, _ := debug.ReadBuildInfo()
+		info = resolve(bi)
+	})
+	return info
+}
+
+// resolve will combine the values set at build time with the info recorded by the Go toolchain
+func resolve(bi *debug.BuildInfo) *Info {
+	i := &Info{
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Version:   Version,
+	}
+	features := splitFeatures(Features)
+
+	if bi != nil {
+		if (len(i.Version) == 0 || i.Version == "dev") && len(bi.Main.Version) > 0 && bi.Main.Version != "(devel)" {
+			i.Version = bi.Main.Version
+		}
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if len(i.Commit) == 0 {
+					i.Commit = setting.Value
+				}
+			case "vcs.time":
+				if len(i.Date) == 0 {
+					i.Date = setting.Value
+				}
+			case "vcs.modified":
+				i.Dirty = setting.Value == "true"
+			case "-tags":
+				features = append(features, splitFeatures(setting.Value)...)
+			case "-race":
+				if setting.Value == "true" {
+					features = append(features, "race")
+				}
+			case "CGO_ENABLED":
+				if setting.Value == "1" {
+					features = append(features, "cgo")
+				}
+			}
+		}
+	}
+
+	if len(i.Commit) == 0 {
+		i.Commit = Unknown
+	}
+	if len(i.Date) == 0 {
+		i.Date = Unknown
+	}
+	if len(i.Version) == 0 {
+		i.Version = Unknown
+	}
+	i.Features = uniqueSorted(features)
+	return i
+}
+
+// splitFeatures will split the comma separated features
+func splitFeatures(s string) []string {
+	features := make([]string, 0)
+	for _, feature := range strings.Split(s, ",") {
+		if feature = strings.TrimSpace(feature); len(feature) > 0 {
+			features = append(features, feature)
+		}
+	}
+	return features
+}
+
+// uniqueSorted will return the values sorted without duplicates
+func uniqueSorted(values []string) []string {
+	sort.Strings(values)
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if len(unique) == 0 || unique[len(unique)-1] != v {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
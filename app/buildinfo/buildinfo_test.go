@@ -0,0 +1,72 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setVars will set the build time values for the test
+func setVars(t *testing.T, version, commit, date, features string) {
+	oldVersion, oldCommit, oldDate, oldFeatures := Version, Commit, Date, Features
+	t.Cleanup(func() {
+		Version, Commit, Date, Features = oldVersion, oldCommit, oldDate, oldFeatures
+	})
+	Version, Commit, Date, Features = version, commit, date, features
+}
+
+// TestResolve will test combining the build time values with the toolchain build info
+func TestResolve(t *testing.T) {
+	bi := &debug.BuildInfo{
+		Main: debug.Module{Version: "v1.1.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "-tags", Value: "sqlite,postgres"},
+			{Key: "CGO_ENABLED", Value: "1"},
+			{Key: "vcs.modified", Value: "true"},
+			{Key: "vcs.revision", Value: "0123456789abcdef0123"},
+			{Key: "vcs.time", Value: "2024-01-02T03:04:05Z"},
+		},
+	}
+
+	t.Run("set at build time", func(t *testing.T) {
+		setVars(t, "v1.2.0", "fedcba9876543210", "2024-02-03T04:05:06Z", "mongo, sqlite")
+		info := resolve(bi)
+		assert.Equal(t, "v1.2.0", info.Version)
+		assert.Equal(t, "fedcba9876543210", info.Commit)
+		assert.Equal(t, "2024-02-03T04:05:06Z", info.Date)
+		assert.True(t, info.Dirty)
+		assert.Equal(t, []string{"cgo", "mongo", "postgres", "sqlite"}, info.Features)
+		assert.Equal(t, runtime.Version(), info.GoVersion)
+		assert.Equal(t, runtime.GOOS+"/"+runtime.GOARCH, info.Platform)
+	})
+
+	t.Run("toolchain fallback", func(t *testing.T) {
+		setVars(t, "dev", "", "", "")
+		info := resolve(bi)
+		assert.Equal(t, "v1.1.0", info.Version)
+		assert.Equal(t, "0123456789abcdef0123", info.Commit)
+		assert.Equal(t, "2024-01-02T03:04:05Z", info.Date)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		setVars(t, "dev", "", "", "")
+		info := resolve(&debug.BuildInfo{Main: debug.Module{Version: "(devel)"}})
+		assert.Equal(t, "dev", info.Version)
+		assert.Equal(t, Unknown, info.Commit)
+		assert.Equal(t, Unknown, info.Date)
+		assert.False(t, info.Dirty)
+		assert.Empty(t, info.Features)
+	})
+}
+
+// TestInfo_String will test the build info summary
+func TestInfo_String(t *testing.T) {
+	info := &Info{
+		Commit: "0123456789abcdef0123", Date: "2024-01-02T03:04:05Z", Dirty: true,
+		Features: []string{"cgo"}, GoVersion: "go1.21.5", Platform: "linux/amd64", Version: "v1.2.0",
+	}
+	assert.Equal(t, "v1.2.0 (commit 0123456789ab-dirty, built 2024-01-02T03:04:05Z, go1.21.5 linux/amd64) features: cgo", info.String())
+	assert.Equal(t, "0123456789ab", info.ShortCommit())
+}
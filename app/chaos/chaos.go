@@ -0,0 +1,112 @@
+// Package chaos injects faults in the P2P and RPC layers (dropped gossip, delayed sync responses and failed node RPC
+// calls) so operators and CI can verify the alert system converges under realistic network misbehavior
+// It is enabled with the chaos config, never on mainnet
+package chaos
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/clock"
+)
+
+// ErrInjectedRPC is returned by the node RPC calls failed on purpose
+var ErrInjectedRPC = errors.New("chaos: injected node rpc failure")
+
+// Options are the faults to inject (zero values inject nothing)
+type Options struct {
+	Clock             clock.Clock   // Clock of the sync delays (the real clock if nil)
+	GossipDropPercent float64       // Percent of the gossiped alerts dropped on receipt (0-100)
+	RPCFailEvery      int           // Every Nth node RPC call fails with ErrInjectedRPC
+	Seed              int64         // Seed of the dropped gossip (0 for a random seed, set it to reproduce a run)
+	SyncDelay         time.Duration // Delay before each sync response is sent to a peer
+}
+
+// Stats are the faults injected so far
+type Stats struct {
+	DroppedGossip int64 `json:"dropped_gossip"`
+	DelayedSyncs  int64 `json:"delayed_syncs"`
+	FailedRPCs    int64 `json:"failed_rpcs"`
+}
+
+// Injector decides which messages and calls fail, a nil injector injects nothing
+type Injector struct {
+	mu       sync.Mutex
+	opts     Options
+	random   *rand.Rand
+	rpcCalls int64
+	stats    Stats
+}
+
+// New will return the injector of the faults
+func New(opts Options) *Injector {
+	if opts.Clock == nil {
+		opts.Clock = clock.New()
+	}
+	seed := opts.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Injector{
+		opts:   opts,
+		random: rand.New(rand.NewSource(seed)), //nolint:gosec // Not security sensitive, reproducible with the seed
+	}
+}
+
+// DropGossip will return true if the gossiped message is dropped
+func (i *Injector) DropGossip() bool {
+	if i == nil || i.opts.GossipDropPercent <= 0 {
+		return false
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.random.Float64()*100 >= i.opts.GossipDropPercent {
+		return false
+	}
+	i.stats.DroppedGossip++
+	return true
+}
+
+// DelaySync will wait the sync delay before a sync response is sent (an error if the context is done)
+func (i *Injector) DelaySync(ctx context.Context) error {
+	if i == nil || i.opts.SyncDelay <= 0 {
+		return nil
+	}
+	i.mu.Lock()
+	i.stats.DelayedSyncs++
+	i.mu.Unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-i.opts.Clock.After(i.opts.SyncDelay):
+		return nil
+	}
+}
+
+// FailRPC will return ErrInjectedRPC if the node RPC call fails (every Nth call)
+func (i *Injector) FailRPC() error {
+	if i == nil || i.opts.RPCFailEvery <= 0 {
+		return nil
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.rpcCalls++
+	if i.rpcCalls%int64(i.opts.RPCFailEvery) != 0 {
+		return nil
+	}
+	i.stats.FailedRPCs++
+	return ErrInjectedRPC
+}
+
+// Stats will return the faults injected so far
+func (i *Injector) Stats() Stats {
+	if i == nil {
+		return Stats{}
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.stats
+}
@@ -0,0 +1,73 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInjector_Nil will test a nil injector injects nothing
+func TestInjector_Nil(t *testing.T) {
+	var i *Injector
+	assert.False(t, i.DropGossip())
+	require.NoError(t, i.DelaySync(context.Background()))
+	require.NoError(t, i.FailRPC())
+	assert.Equal(t, Stats{}, i.Stats())
+}
+
+// TestInjector_DropGossip will test the percent of the gossip dropped (reproducible with the seed)
+func TestInjector_DropGossip(t *testing.T) {
+	drops := func(seed int64) []bool {
+		i := New(Options{GossipDropPercent: 30, Seed: seed})
+		var dropped []bool
+		for n := 0; n < 1000; n++ {
+			dropped = append(dropped, i.DropGossip())
+		}
+		assert.InDelta(t, 300, i.Stats().DroppedGossip, 60)
+		return dropped
+	}
+	assert.Equal(t, drops(42), drops(42))
+
+	assert.False(t, New(Options{}).DropGossip())
+	assert.True(t, New(Options{GossipDropPercent: 100}).DropGossip())
+}
+
+// TestInjector_DelaySync will test the sync responses wait for the delay on the clock
+func TestInjector_DelaySync(t *testing.T) {
+	now := clock.NewMock(time.Now())
+	i := New(Options{Clock: now, SyncDelay: time.Second})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- i.DelaySync(context.Background())
+	}()
+	require.Eventually(t, func() bool {
+		return now.Timers() == 1
+	}, time.Second, time.Millisecond)
+	assert.Empty(t, done)
+	now.Advance(time.Second)
+	require.NoError(t, <-done)
+	assert.Equal(t, int64(1), i.Stats().DelayedSyncs)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, i.DelaySync(ctx), context.Canceled)
+}
+
+// TestInjector_FailRPC will test every Nth node RPC call fails
+func TestInjector_FailRPC(t *testing.T) {
+	i := New(Options{RPCFailEvery: 3})
+	var failed []int
+	for n := 1; n <= 9; n++ {
+		if err := i.FailRPC(); err != nil {
+			require.ErrorIs(t, err, ErrInjectedRPC)
+			failed = append(failed, n)
+		}
+	}
+	assert.Equal(t, []int{3, 6, 9}, failed)
+	assert.Equal(t, int64(3), i.Stats().FailedRPCs)
+}
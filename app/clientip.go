@@ -0,0 +1,68 @@
+package app
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// HeaderForwardedFor is the header set by proxies with the chain of client addresses
+const HeaderForwardedFor = "X-Forwarded-For"
+
+// ClientIP will return the client IP address for the request
+// X-Forwarded-For is only used if the request came from a trusted proxy, the chain is walked from
+// the right (closest hop) and the first address that is not a trusted proxy is the client
+func ClientIP(req *http.Request, trustedProxies []string) netip.Addr {
+	remote := parseIP(req.RemoteAddr)
+	if !remote.IsValid() || !containsIP(trustedProxies, remote) {
+		return remote
+	}
+	hops := strings.Split(strings.Join(req.Header.Values(HeaderForwardedFor), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := parseIP(strings.TrimSpace(hops[i]))
+		if !hop.IsValid() {
+			break
+		}
+		if !containsIP(trustedProxies, hop) {
+			return hop
+		}
+		remote = hop
+	}
+	return remote
+}
+
+// parseIP will parse an IP address (with or without a port)
+func parseIP(address string) netip.Addr {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+	ip, err := netip.ParseAddr(address)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return ip.Unmap()
+}
+
+// containsIP will return true if the IP matches any of the networks (IP addresses or CIDR ranges)
+func containsIP(networks []string, ip netip.Addr) bool {
+	for _, network := range networks {
+		if prefix, err := netip.ParsePrefix(network); err == nil {
+			if prefix.Contains(ip) {
+				return true
+			}
+		} else if addr, err := netip.ParseAddr(network); err == nil && addr.Unmap() == ip {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedIP will return true if the allowlist is empty or the client IP is in the allowlist
+// (requests received on the admin socket are always allowed)
+func (a *Action) allowedIP(req *http.Request) bool {
+	if len(a.Allowlist) == 0 || FromAdminSocket(req) {
+		return true
+	}
+	return containsIP(a.Allowlist, ClientIP(req, a.Config.WebServer.TrustedProxies))
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	apirouter "github.com/mrz1836/go-api-router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClientIP will test the method ClientIP()
+func TestClientIP(t *testing.T) {
+	t.Parallel()
+
+	newRequest := func(remoteAddr string, forwardedFor ...string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		for _, hop := range forwardedFor {
+			req.Header.Add(HeaderForwardedFor, hop)
+		}
+		return req
+	}
+
+	t.Run("no trusted proxies ignores forwarded for", func(t *testing.T) {
+		ip := ClientIP(newRequest("10.0.0.1:1234", "1.2.3.4"), nil)
+		assert.Equal(t, "10.0.0.1", ip.String())
+	})
+
+	t.Run("untrusted remote ignores forwarded for", func(t *testing.T) {
+		ip := ClientIP(newRequest("192.168.1.5:1234", "1.2.3.4"), []string{"10.0.0.0/8"})
+		assert.Equal(t, "192.168.1.5", ip.String())
+	})
+
+	t.Run("trusted proxy uses the closest untrusted hop", func(t *testing.T) {
+		ip := ClientIP(newRequest("10.0.0.1:1234", "6.6.6.6, 1.2.3.4", "10.0.0.2"), []string{"10.0.0.0/8"})
+		assert.Equal(t, "1.2.3.4", ip.String())
+	})
+
+	t.Run("all hops trusted", func(t *testing.T) {
+		ip := ClientIP(newRequest("10.0.0.1:1234", "10.0.0.3"), []string{"10.0.0.0/8"})
+		assert.Equal(t, "10.0.0.3", ip.String())
+	})
+
+	t.Run("invalid hop stops the walk", func(t *testing.T) {
+		ip := ClientIP(newRequest("10.0.0.1:1234", "not-an-ip"), []string{"10.0.0.1"})
+		assert.Equal(t, "10.0.0.1", ip.String())
+	})
+}
+
+// TestAction_Request_Allowlist will test the route group allowlist in Request()
+func TestAction_Request_Allowlist(t *testing.T) {
+	t.Parallel()
+
+	newAction := func(allowlist, trustedProxies []string) Action {
+		dep := new(config.Config)
+		dep.WebServer = config.WebServerConfig{DisableCompression: true, TrustedProxies: trustedProxies}
+		return Action{Allowlist: allowlist, Config: dep}
+	}
+
+	t.Run("empty allowlist allows all", func(t *testing.T) {
+		a := newAction(nil, nil)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		a.Request(apirouter.New(), testHandle)(w, req, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("allowed network", func(t *testing.T) {
+		a := newAction([]string{"192.0.2.0/24"}, nil)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		a.Request(apirouter.New(), testHandle)(w, req, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("blocked address", func(t *testing.T) {
+		a := newAction([]string{"10.8.0.0/16"}, nil)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		a.Request(apirouter.New(), testHandle)(w, req, nil)
+		require.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), ErrIPNotAllowed.Error())
+	})
+
+	t.Run("allowed through a trusted proxy", func(t *testing.T) {
+		a := newAction([]string{"10.8.0.0/16"}, []string{"192.0.2.1"})
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderForwardedFor, "10.8.1.20")
+		a.Request(apirouter.New(), testHandle)(w, req, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("forwarded for ignored without a trusted proxy", func(t *testing.T) {
+		a := newAction([]string{"10.8.0.0/16"}, nil)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderForwardedFor, "10.8.1.20")
+		a.Request(apirouter.New(), testHandle)(w, req, nil)
+		require.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
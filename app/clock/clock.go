@@ -0,0 +1,180 @@
+// Package clock is the source of the current time and the timers of the alert system (processing intervals, peer
+// discovery, alert locks and ban expiry), so the tests can advance the time deterministically with the mock clock
+// instead of sleeping
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the current time and the timers
+type Clock interface {
+	After(d time.Duration) <-chan time.Time
+	NewTicker(d time.Duration) Ticker
+	Now() time.Time
+	Since(t time.Time) time.Duration
+}
+
+// Ticker delivers the ticks of an interval (see time.Ticker)
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// New will return the real clock (the time package)
+func New() Clock {
+	return realClock{}
+}
+
+// realClock is the time package
+type realClock struct{}
+
+// After will wait for the duration to elapse then send the current time on the returned channel
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// NewTicker will return a ticker of the interval
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return &realTicker{ticker: time.NewTicker(d)}
+}
+
+// Now will return the current time
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Since will return the time elapsed since t
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+// realTicker is a time.Ticker
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+// C will return the channel of the ticks
+func (t *realTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+// Stop will turn off the ticker
+func (t *realTicker) Stop() {
+	t.ticker.Stop()
+}
+
+// Mock is a clock whose time only moves with Advance and Set, firing the timers and tickers that are due
+type Mock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*mockTimer
+}
+
+// mockTimer is a timer (After) or a ticker (a period) of the mock clock
+type mockTimer struct {
+	c      chan time.Time
+	mock   *Mock
+	next   time.Time
+	period time.Duration // Zero for a timer (fired once)
+}
+
+// NewMock will return a mock clock set to the time
+func NewMock(now time.Time) *Mock {
+	return &Mock{now: now}
+}
+
+// After will return a channel receiving the time once the clock is advanced by the duration
+func (m *Mock) After(d time.Duration) <-chan time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := &mockTimer{c: make(chan time.Time, 1), mock: m, next: m.now.Add(d)}
+	m.timers = append(m.timers, t)
+	return t.c
+}
+
+// NewTicker will return a ticker of the interval, ticking as the clock is advanced (like time.Ticker, the ticks are
+// dropped while the previous one is not received)
+func (m *Mock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for clock.Mock.NewTicker")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := &mockTimer{c: make(chan time.Time, 1), mock: m, next: m.now.Add(d), period: d}
+	m.timers = append(m.timers, t)
+	return t
+}
+
+// Now will return the time of the clock
+func (m *Mock) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+// Since will return the time elapsed on the clock since t
+func (m *Mock) Since(t time.Time) time.Duration {
+	return m.Now().Sub(t)
+}
+
+// Timers will return the number of timers and tickers waiting (e.g. to wait for a goroutine to start its ticker)
+func (m *Mock) Timers() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.timers)
+}
+
+// Advance will move the clock forward, firing the timers and tickers that are due in order
+func (m *Mock) Advance(d time.Duration) {
+	m.Set(m.Now().Add(d))
+}
+
+// Set will move the clock to the time, firing the timers and tickers that are due in order (the time never goes back)
+func (m *Mock) Set(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for {
+		sort.SliceStable(m.timers, func(i, j int) bool {
+			return m.timers[i].next.Before(m.timers[j].next)
+		})
+		if len(m.timers) == 0 || m.timers[0].next.After(now) {
+			break
+		}
+		t := m.timers[0]
+		if t.next.After(m.now) {
+			m.now = t.next
+		}
+		select {
+		case t.c <- m.now:
+		default:
+		}
+		if t.period > 0 {
+			t.next = t.next.Add(t.period)
+		} else {
+			m.timers = m.timers[1:]
+		}
+	}
+	if now.After(m.now) {
+		m.now = now
+	}
+}
+
+// C will return the channel of the ticks
+func (t *mockTimer) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop will turn off the ticker
+func (t *mockTimer) Stop() {
+	t.mock.mu.Lock()
+	defer t.mock.mu.Unlock()
+	for i, timer := range t.mock.timers {
+		if timer == t {
+			t.mock.timers = append(t.mock.timers[:i], t.mock.timers[i+1:]...)
+			return
+		}
+	}
+}
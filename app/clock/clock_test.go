@@ -0,0 +1,91 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// start is the time of the mock clocks
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// TestNew will test the real clock follows the time package
+func TestNew(t *testing.T) {
+	c := New()
+	assert.WithinDuration(t, time.Now(), c.Now(), time.Second)
+	assert.GreaterOrEqual(t, c.Since(time.Now().Add(-time.Minute)), time.Minute)
+
+	ticker := c.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	select {
+	case <-ticker.C():
+	case <-time.After(time.Second):
+		require.Fail(t, "no tick")
+	}
+	select {
+	case <-c.After(time.Millisecond):
+	case <-time.After(time.Second):
+		require.Fail(t, "not fired")
+	}
+}
+
+// TestMock_Advance will test the time only moves with the mock
+func TestMock_Advance(t *testing.T) {
+	m := NewMock(start)
+	assert.Equal(t, start, m.Now())
+
+	m.Advance(time.Hour)
+	assert.Equal(t, start.Add(time.Hour), m.Now())
+	assert.Equal(t, time.Hour, m.Since(start))
+
+	m.Set(start)
+	assert.Equal(t, start.Add(time.Hour), m.Now(), "never goes back")
+}
+
+// TestMock_After will test a timer fires once the clock reaches it
+func TestMock_After(t *testing.T) {
+	m := NewMock(start)
+	c := m.After(time.Minute)
+	assert.Equal(t, 1, m.Timers())
+
+	m.Advance(59 * time.Second)
+	assert.Empty(t, c)
+
+	m.Advance(time.Second)
+	require.Len(t, c, 1)
+	assert.Equal(t, start.Add(time.Minute), <-c)
+	assert.Equal(t, 0, m.Timers())
+}
+
+// TestMock_NewTicker will test a ticker ticks for each interval and drops the ticks not received
+func TestMock_NewTicker(t *testing.T) {
+	m := NewMock(start)
+	ticker := m.NewTicker(time.Minute)
+
+	m.Advance(time.Minute)
+	require.Len(t, ticker.C(), 1)
+	assert.Equal(t, start.Add(time.Minute), <-ticker.C())
+
+	// Missed ticks are dropped (like time.Ticker)
+	m.Advance(5 * time.Minute)
+	require.Len(t, ticker.C(), 1)
+	assert.Equal(t, start.Add(2*time.Minute), <-ticker.C())
+	assert.Equal(t, start.Add(6*time.Minute), m.Now())
+
+	// The timers fire in order
+	c := m.After(30 * time.Second)
+	m.Advance(time.Minute)
+	assert.Equal(t, start.Add(6*time.Minute+30*time.Second), <-c)
+	assert.Equal(t, start.Add(7*time.Minute), <-ticker.C())
+
+	ticker.Stop()
+	assert.Equal(t, 0, m.Timers())
+	m.Advance(time.Hour)
+	assert.Empty(t, ticker.C())
+
+	assert.Panics(t, func() {
+		m.NewTicker(0)
+	})
+}
@@ -0,0 +1,216 @@
+// Package cluster is the active/standby clustering of the alert systems sharing a datastore
+// The instances elect a leader with a lease in the datastore: the leader renews it, a standby takes it over once it
+// expired (a failed leader is replaced within the lease duration and a renew interval). Only the leader enforces the
+// alerts and publishes their events, the standbys keep their P2P connections and serve the API from the same datastore.
+// The expiry of the lease is compared with the clock of each instance, the clocks must be in sync (NTP).
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/clock"
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/metrics"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+)
+
+// Roles of an instance in the cluster
+const (
+	RoleLeader  = "leader"  // Enforces the alerts and publishes their events
+	RoleStandby = "standby" // Stays connected to the peers, takes over if the leader fails
+)
+
+// Lease is the leader lease of the cluster
+type Lease struct {
+	ExpiresAt time.Time // Time the lease expires if it is not renewed
+	Holder    string    // Instance holding the lease
+	Term      int64     // Leader term (incremented each time another instance takes the lease)
+}
+
+// LeaseStore is the store of the leader lease shared by the instances
+type LeaseStore interface {
+	Acquire(ctx context.Context, holder string, duration time.Duration) (*Lease, error)
+	Release(ctx context.Context, holder string) error
+}
+
+// State is the role of the instance in the cluster (reported in the status)
+type State struct {
+	Instance       string     `json:"instance"`                   // Instance ID (the holder of the lease if leader)
+	Leader         string     `json:"leader"`                     // Instance holding the lease (empty if unknown)
+	LeaseExpiresAt *time.Time `json:"lease_expires_at,omitempty"` // Time the lease of the leader expires if it is not renewed
+	Role           string     `json:"role"`                       // leader or standby
+	Term           int64      `json:"term"`                       // Leader term
+}
+
+// ChangeFunc is called when the instance becomes the leader or a standby
+// The context is the one of Run (or Campaign), not the timeout of the renewal: a takeover started with it keeps running
+type ChangeFunc func(ctx context.Context, state *State)
+
+// Elector takes and renews the leader lease of the instance
+type Elector struct {
+	clock       clock.Clock
+	duration    time.Duration
+	instance    string
+	interval    time.Duration
+	lease       *Lease    // Last lease read
+	leaderUntil time.Time // Time our lease expires (from the start of the renewal, before the expiry in the store)
+	leading     bool      // Role reported by the last change
+	logger      config.LoggerInterface
+	mu          sync.RWMutex
+	onChange    ChangeFunc
+	quit        chan bool
+	store       LeaseStore
+}
+
+// NewElector will create the elector of the instance (starts as a standby until the lease is acquired)
+func NewElector(conf *config.Config, store LeaseStore, onChange ChangeFunc) *Elector {
+	metrics.ClusterLeader.Set(0)
+	return &Elector{
+		clock:    conf.Clock(),
+		duration: conf.Cluster.LeaseDuration,
+		instance: conf.Cluster.InstanceID,
+		interval: conf.Cluster.RenewInterval,
+		logger:   config.WithField(conf.Services.Log, config.LogFieldModule, "cluster"),
+		onChange: onChange,
+		quit:     make(chan bool, 1),
+		store:    store,
+	}
+}
+
+// IsLeader will return true if the instance holds the lease (a nil elector is a single instance, always the leader)
+// The lease is only held until it expires, a leader that cannot renew it stops leading before a standby takes over
+func (e *Elector) IsLeader() bool {
+	if e == nil {
+		return true
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.clock.Now().Before(e.leaderUntil)
+}
+
+// State will return the role of the instance and the last lease read
+func (e *Elector) State() *State {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.state()
+}
+
+// state will return the role of the instance and the last lease read (while holding the lock)
+func (e *Elector) state() *State {
+	s := &State{Instance: e.instance, Role: RoleStandby}
+	if e.clock.Now().Before(e.leaderUntil) {
+		s.Role = RoleLeader
+	}
+	if e.lease != nil {
+		expiresAt := e.lease.ExpiresAt
+		s.Leader, s.LeaseExpiresAt, s.Term = e.lease.Holder, &expiresAt, e.lease.Term
+	}
+	return s
+}
+
+// Run will take or renew the lease at each renew interval until the context is done or the elector is stopped
+func (e *Elector) Run(ctx context.Context) {
+	ticker := e.clock.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		if err := e.Campaign(ctx); err != nil {
+			e.logger.Warnf("failed to renew the cluster lease: %s", err.Error())
+		}
+		select {
+		case <-ticker.C():
+		case <-e.quit:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Campaign will take or renew the lease once and report the change of role
+// On an error the role is kept until our lease expires (a short datastore outage does not move the leader)
+func (e *Elector) Campaign(ctx context.Context) error {
+	acquireCtx, cancel := context.WithTimeout(ctx, e.interval)
+	start := e.clock.Now()
+	lease, err := e.store.Acquire(acquireCtx, e.instance, e.duration)
+	cancel()
+
+	e.mu.Lock()
+	if err == nil {
+		e.lease = lease
+		if lease.Holder == e.instance {
+			e.leaderUntil = start.Add(e.duration)
+		} else {
+			e.leaderUntil = time.Time{}
+		}
+	}
+	state := e.state()
+	changed := (state.Role == RoleLeader) != e.leading
+	e.leading = state.Role == RoleLeader
+	e.mu.Unlock()
+
+	if changed {
+		e.changed(ctx, state)
+	}
+	return err
+}
+
+// Stop will stop the renewals and release the lease if held (a standby takes over at its next renewal)
+func (e *Elector) Stop(ctx context.Context) error {
+	select {
+	case e.quit <- true:
+	default:
+	}
+	e.mu.Lock()
+	wasLeader := e.clock.Now().Before(e.leaderUntil)
+	e.leaderUntil = time.Time{}
+	e.leading = false
+	e.mu.Unlock()
+	metrics.ClusterLeader.Set(0)
+	if !wasLeader {
+		return nil
+	}
+	e.logger.Infof("releasing the cluster lease of %s", e.instance)
+	return e.store.Release(ctx, e.instance)
+}
+
+// changed will log the new role and report it
+func (e *Elector) changed(ctx context.Context, state *State) {
+	if state.Role == RoleLeader {
+		metrics.ClusterLeader.Set(1)
+		e.logger.Infof("%s is the cluster leader (term %d)", e.instance, state.Term)
+	} else {
+		metrics.ClusterLeader.Set(0)
+		e.logger.Warnf("%s is a cluster standby (leader %s)", e.instance, state.Leader)
+	}
+	if e.onChange != nil {
+		e.onChange(ctx, state)
+	}
+}
+
+// datastoreLeases is the lease store in the shared datastore
+type datastoreLeases struct {
+	config *config.Config
+	name   string
+}
+
+// NewDatastoreLeases will return the lease store of the cluster in the datastore
+func NewDatastoreLeases(conf *config.Config) LeaseStore {
+	return &datastoreLeases{config: conf, name: conf.Cluster.Name}
+}
+
+// Acquire will take, renew or read the lease of the cluster
+func (d *datastoreLeases) Acquire(ctx context.Context, holder string, duration time.Duration) (*Lease, error) {
+	lease, err := models.AcquireClusterLease(ctx, d.name, holder, duration, model.WithAllDependencies(d.config))
+	if err != nil {
+		return nil, err
+	}
+	return &Lease{ExpiresAt: lease.ExpiresAt, Holder: lease.Holder, Term: lease.Term}, nil
+}
+
+// Release will expire the lease of the cluster if the holder has it
+func (d *datastoreLeases) Release(ctx context.Context, holder string) error {
+	return models.ReleaseClusterLease(ctx, d.name, holder, model.WithAllDependencies(d.config))
+}
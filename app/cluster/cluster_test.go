@@ -0,0 +1,190 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/clock"
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryLeases is a lease store shared by the electors of a test
+type memoryLeases struct {
+	clock *clock.Mock // Clock of the store and the electors
+	err   error
+	lease *Lease
+	mu    sync.Mutex
+}
+
+// newMemoryLeases will return a lease store using a mock clock
+func newMemoryLeases() *memoryLeases {
+	return &memoryLeases{clock: clock.NewMock(time.Unix(1700000000, 0))}
+}
+
+// Acquire will take, renew or read the lease (the same rules as the datastore)
+func (m *memoryLeases) Acquire(_ context.Context, holder string, duration time.Duration) (*Lease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	now := m.clock.Now()
+	switch {
+	case m.lease == nil:
+		m.lease = &Lease{Holder: holder, Term: 1, ExpiresAt: now.Add(duration)}
+	case m.lease.Holder == holder:
+		m.lease.ExpiresAt = now.Add(duration)
+	case m.lease.ExpiresAt.Before(now):
+		m.lease = &Lease{Holder: holder, Term: m.lease.Term + 1, ExpiresAt: now.Add(duration)}
+	}
+	lease := *m.lease
+	return &lease, nil
+}
+
+// Release will expire the lease if the holder has it
+func (m *memoryLeases) Release(_ context.Context, holder string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.lease != nil && m.lease.Holder == holder {
+		m.lease.ExpiresAt = m.clock.Now()
+	}
+	return nil
+}
+
+// newTestElector will return an elector of the instance recording its changes of role
+func newTestElector(instance string, store *memoryLeases, duration time.Duration) (*Elector, *[]string) {
+	conf := &config.Config{}
+	conf.Services.Clock = store.clock
+	conf.Services.Log = &config.ExtendedLogger{Logger: log.New(io.Discard, "", 0)}
+	conf.Cluster = config.ClusterConfig{
+		Enabled: true, InstanceID: instance, LeaseDuration: duration, RenewInterval: duration / 4,
+	}
+	var changes []string
+	return NewElector(conf, store, func(_ context.Context, state *State) {
+		changes = append(changes, state.Role)
+	}), &changes
+}
+
+// TestElector will test the leader election of two instances
+func TestElector(t *testing.T) {
+	t.Parallel()
+
+	t.Run("single instance", func(t *testing.T) {
+		var e *Elector
+		assert.True(t, e.IsLeader(), "clustering disabled, always the leader")
+	})
+
+	t.Run("one leader, the standby takes over once the lease expired", func(t *testing.T) {
+		store := newMemoryLeases()
+		a, changesA := newTestElector("node-a", store, 100*time.Millisecond)
+		b, changesB := newTestElector("node-b", store, 100*time.Millisecond)
+		ctx := context.Background()
+
+		require.NoError(t, a.Campaign(ctx))
+		require.NoError(t, b.Campaign(ctx))
+		assert.True(t, a.IsLeader())
+		assert.False(t, b.IsLeader())
+		assert.Equal(t, &State{
+			Instance: "node-b", Leader: "node-a", LeaseExpiresAt: &store.lease.ExpiresAt, Role: RoleStandby, Term: 1,
+		}, b.State())
+
+		// The leader stops renewing (e.g. it crashed), it stops leading before the standby takes over
+		store.clock.Advance(120 * time.Millisecond)
+		assert.False(t, a.IsLeader())
+		require.NoError(t, b.Campaign(ctx))
+		assert.True(t, b.IsLeader())
+		assert.Equal(t, int64(2), b.State().Term)
+		require.NoError(t, a.Campaign(ctx))
+		assert.False(t, a.IsLeader())
+
+		assert.Equal(t, []string{RoleLeader, RoleStandby}, *changesA)
+		assert.Equal(t, []string{RoleLeader}, *changesB)
+	})
+
+	t.Run("the standby becomes the leader and runs the takeover", func(t *testing.T) {
+		store := newMemoryLeases()
+		a, _ := newTestElector("node-a", store, 100*time.Millisecond)
+		require.NoError(t, a.Campaign(context.Background()))
+		require.NoError(t, a.Stop(context.Background()))
+
+		// The takeover runs in the background after the change is reported (like the alert retries and syncs)
+		conf := &config.Config{}
+		conf.Services.Clock = store.clock
+		conf.Services.Log = &config.ExtendedLogger{Logger: log.New(io.Discard, "", 0)}
+		conf.Cluster = config.ClusterConfig{
+			Enabled: true, InstanceID: "node-b", LeaseDuration: time.Minute, RenewInterval: 10 * time.Millisecond,
+		}
+		takeover := make(chan error, 1)
+		b := NewElector(conf, store, func(ctx context.Context, state *State) {
+			if state.Role != RoleLeader {
+				return
+			}
+			go func() {
+				time.Sleep(50 * time.Millisecond) // After the renewal returned
+				takeover <- ctx.Err()
+			}()
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		store.clock.Advance(time.Millisecond)
+		require.NoError(t, b.Campaign(ctx))
+		assert.True(t, b.IsLeader())
+		select {
+		case err := <-takeover:
+			require.NoError(t, err, "the takeover context is still alive")
+		case <-time.After(time.Second):
+			t.Fatal("the takeover did not run")
+		}
+	})
+
+	t.Run("released at stop", func(t *testing.T) {
+		store := newMemoryLeases()
+		a, _ := newTestElector("node-a", store, time.Minute)
+		b, _ := newTestElector("node-b", store, time.Minute)
+		ctx := context.Background()
+
+		require.NoError(t, a.Campaign(ctx))
+		require.NoError(t, a.Stop(ctx))
+		assert.False(t, a.IsLeader())
+		store.clock.Advance(time.Millisecond) // The next renewal of the standby, well before the lease expires
+		require.NoError(t, b.Campaign(ctx))
+		assert.True(t, b.IsLeader(), "taken over without waiting for the lease to expire")
+	})
+
+	t.Run("leading until the lease expires on store errors", func(t *testing.T) {
+		store := newMemoryLeases()
+		a, changes := newTestElector("node-a", store, 100*time.Millisecond)
+		ctx := context.Background()
+
+		require.NoError(t, a.Campaign(ctx))
+		store.err = errors.New("datastore unavailable")
+		require.Error(t, a.Campaign(ctx))
+		assert.True(t, a.IsLeader())
+
+		store.clock.Advance(120 * time.Millisecond)
+		require.Error(t, a.Campaign(ctx))
+		assert.False(t, a.IsLeader())
+		assert.Equal(t, []string{RoleLeader, RoleStandby}, *changes)
+	})
+
+	t.Run("run until stopped", func(t *testing.T) {
+		store := newMemoryLeases()
+		a, _ := newTestElector("node-a", store, 200*time.Millisecond)
+		done := make(chan bool)
+		go func() {
+			a.Run(context.Background())
+			close(done)
+		}()
+		assert.Eventually(t, a.IsLeader, time.Second, 10*time.Millisecond)
+		require.NoError(t, a.Stop(context.Background()))
+		<-done
+		assert.False(t, a.IsLeader())
+	})
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// encodingGzip is the only supported response encoding (brotli would need a new dependency)
+const encodingGzip = "gzip"
+
+// gzipWriterPool re-uses gzip writers between responses (they are expensive to allocate)
+var gzipWriterPool = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(nil)
+	},
+}
+
+// acceptsGzip will return true if the Accept-Encoding header allows a gzip response
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != encodingGzip && coding != "*" {
+			continue
+		}
+
+		// A quality of zero means "not acceptable"
+		if name, value, found := strings.Cut(strings.TrimSpace(params), "="); found && strings.TrimSpace(name) == "q" {
+			if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && q <= 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// compressWriter buffers the response until it reaches the min size and then decides
+// whether to gzip it (small responses are not worth the overhead and are written as-is)
+type compressWriter struct {
+	http.ResponseWriter
+	buf      []byte
+	decided  bool
+	gz       *gzip.Writer
+	minBytes int
+	status   int
+}
+
+// newCompressWriter will create a new compressing response writer
+func newCompressWriter(w http.ResponseWriter, minBytes int) *compressWriter {
+	return &compressWriter{ResponseWriter: w, minBytes: minBytes}
+}
+
+// WriteHeader will record the status (it is written once the encoding is decided)
+func (c *compressWriter) WriteHeader(status int) {
+	if c.status == 0 {
+		c.status = status
+	}
+}
+
+// Write will buffer the body until the min size is reached, then write it (compressed if possible)
+func (c *compressWriter) Write(b []byte) (int, error) {
+	if c.decided {
+		if c.gz != nil {
+			return c.gz.Write(b)
+		}
+		return c.ResponseWriter.Write(b)
+	}
+	c.buf = append(c.buf, b...)
+	if len(c.buf) >= c.minBytes {
+		if err := c.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Close will flush any buffered body and finish the compressed stream
+func (c *compressWriter) Close() error {
+	if !c.decided {
+		if err := c.decide(len(c.buf) > 0 && len(c.buf) >= c.minBytes); err != nil {
+			return err
+		}
+	}
+	if c.gz == nil {
+		return nil
+	}
+	err := c.gz.Close()
+	c.gz.Reset(nil)
+	gzipWriterPool.Put(c.gz)
+	c.gz = nil
+	return err
+}
+
+// decide will write the header (choosing the encoding) and the buffered body
+func (c *compressWriter) decide(compress bool) error {
+	c.decided = true
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+
+	// Never compress bodiless responses or responses the handler already encoded
+	header := c.Header()
+	header.Add("Vary", "Accept-Encoding")
+	if compress && c.status != http.StatusNoContent && c.status != http.StatusNotModified &&
+		len(header.Get("Content-Encoding")) == 0 {
+		header.Set("Content-Encoding", encodingGzip)
+		header.Del("Content-Length")
+		c.gz = gzipWriterPool.Get().(*gzip.Writer)
+		c.gz.Reset(c.ResponseWriter)
+	}
+	c.ResponseWriter.WriteHeader(c.status)
+
+	// Write the buffered body
+	buf := c.buf
+	c.buf = nil
+	if len(buf) == 0 {
+		return nil
+	} else if c.gz != nil {
+		_, err := c.gz.Write(buf)
+		return err
+	}
+	_, err := c.ResponseWriter.Write(buf)
+	return err
+}
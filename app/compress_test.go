@@ -0,0 +1,110 @@
+package app
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/julienschmidt/httprouter"
+	apirouter "github.com/mrz1836/go-api-router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAcceptsGzip will test the method acceptsGzip()
+func TestAcceptsGzip(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]bool{
+		"":                       false,
+		"gzip":                   true,
+		"GZIP":                   true,
+		"deflate, gzip;q=0.8":    true,
+		"br, *":                  true,
+		"gzip;q=0":               false,
+		"gzip;q=0.0, deflate":    false,
+		"deflate, br":            false,
+		"identity":               false,
+		" gzip ; q=1.0 , br;q=1": true,
+	}
+	for header, expected := range tests {
+		assert.Equal(t, expected, acceptsGzip(header), "header: %q", header)
+	}
+}
+
+// TestAction_Request_Compression will test the response compression in Request()
+func TestAction_Request_Compression(t *testing.T) {
+	t.Parallel()
+
+	body := strings.Repeat(`{"alert":"test"},`, 200)
+	largeHandle := func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, body)
+	}
+
+	newAction := func(web config.WebServerConfig) Action {
+		dep := new(config.Config)
+		dep.WebServer = web
+		a, _ := NewStack(dep)
+		return a
+	}
+
+	t.Run("gzip accepted, large response is compressed", func(t *testing.T) {
+		a := newAction(config.WebServerConfig{CompressMinBytes: 1024})
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		a.Request(apirouter.New(), largeHandle)(w, req, nil)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.Contains(t, w.Header().Values("Vary"), "Accept-Encoding")
+
+		gz, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		var decoded []byte
+		decoded, err = io.ReadAll(gz)
+		require.NoError(t, err)
+		assert.Equal(t, body, string(decoded))
+	})
+
+	t.Run("gzip accepted, small response is not compressed", func(t *testing.T) {
+		a := newAction(config.WebServerConfig{CompressMinBytes: 1024})
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		a.Request(apirouter.New(), testHandle)(w, req, nil)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Contains(t, w.Header().Values("Vary"), "Accept-Encoding")
+	})
+
+	t.Run("gzip not accepted", func(t *testing.T) {
+		a := newAction(config.WebServerConfig{CompressMinBytes: 1024})
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		a.Request(apirouter.New(), largeHandle)(w, req, nil)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, body, w.Body.String())
+	})
+
+	t.Run("compression disabled", func(t *testing.T) {
+		a := newAction(config.WebServerConfig{CompressMinBytes: 1024, DisableCompression: true})
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		a.Request(apirouter.New(), largeHandle)(w, req, nil)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, body, w.Body.String())
+	})
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bitcoin-sv/alert-system/app/chaos"
+	"github.com/libsv/go-bn/models"
+)
+
+// validate will check the fault injection settings, it is never enabled on mainnet
+func (c ChaosConfig) validate(environment string) error {
+	if environment == EnvironmentMainnet || environment == EnvironmentProduction {
+		return fmt.Errorf("%w: %s", ErrChaosOnMainnet, environment)
+	}
+	if c.GossipDropPercent < 0 || c.GossipDropPercent > 100 || c.RPCFailEvery < 0 || c.SyncDelay < 0 {
+		return ErrInvalidChaos
+	}
+	return nil
+}
+
+// chaosNode is a node whose RPC calls fail on purpose (every Nth call, see chaos.Injector)
+type chaosNode struct {
+	NodeInterface
+	chaos *chaos.Injector
+}
+
+// newChaosNode will wrap the node (real or mock) with the fault injection
+func newChaosNode(node NodeInterface, injector *chaos.Injector) NodeInterface {
+	return &chaosNode{NodeInterface: node, chaos: injector}
+}
+
+// Unwrap will return the wrapped node
+func (n *chaosNode) Unwrap() NodeInterface {
+	return n.NodeInterface
+}
+
+// BanPeer bans a peer (unless the call fails on purpose)
+func (n *chaosNode) BanPeer(ctx context.Context, peer string) error {
+	if err := n.chaos.FailRPC(); err != nil {
+		return err
+	}
+	return n.NodeInterface.BanPeer(ctx, peer)
+}
+
+// BestBlockHash gets the best block hash (unless the call fails on purpose)
+func (n *chaosNode) BestBlockHash(ctx context.Context) (string, error) {
+	if err := n.chaos.FailRPC(); err != nil {
+		return "", err
+	}
+	return n.NodeInterface.BestBlockHash(ctx)
+}
+
+// BlockCount gets the current block height (unless the call fails on purpose)
+func (n *chaosNode) BlockCount(ctx context.Context) (uint32, error) {
+	if err := n.chaos.FailRPC(); err != nil {
+		return 0, err
+	}
+	return n.NodeInterface.BlockCount(ctx)
+}
+
+// InActiveChain checks if the block is in the active chain (unless the call fails on purpose)
+func (n *chaosNode) InActiveChain(ctx context.Context, hash string) (bool, error) {
+	if err := n.chaos.FailRPC(); err != nil {
+		return false, err
+	}
+	return n.NodeInterface.InActiveChain(ctx, hash)
+}
+
+// InvalidateBlock invalidates a block (unless the call fails on purpose)
+func (n *chaosNode) InvalidateBlock(ctx context.Context, hash string) error {
+	if err := n.chaos.FailRPC(); err != nil {
+		return err
+	}
+	return n.NodeInterface.InvalidateBlock(ctx, hash)
+}
+
+// ListBanned gets the list of banned peers (unless the call fails on purpose)
+func (n *chaosNode) ListBanned(ctx context.Context) ([]*models.BannedSubnet, error) {
+	if err := n.chaos.FailRPC(); err != nil {
+		return nil, err
+	}
+	return n.NodeInterface.ListBanned(ctx)
+}
+
+// NetworkInfo gets the network info (unless the call fails on purpose)
+func (n *chaosNode) NetworkInfo(ctx context.Context) (*models.NetworkInfo, error) {
+	if err := n.chaos.FailRPC(); err != nil {
+		return nil, err
+	}
+	return n.NodeInterface.NetworkInfo(ctx)
+}
+
+// QueryBlacklistedFunds gets the frozen funds (unless the call fails on purpose)
+func (n *chaosNode) QueryBlacklistedFunds(ctx context.Context) ([]models.Fund, error) {
+	if err := n.chaos.FailRPC(); err != nil {
+		return nil, err
+	}
+	return n.NodeInterface.QueryBlacklistedFunds(ctx)
+}
+
+// UnbanPeer unbans a peer (unless the call fails on purpose)
+func (n *chaosNode) UnbanPeer(ctx context.Context, peer string) error {
+	if err := n.chaos.FailRPC(); err != nil {
+		return err
+	}
+	return n.NodeInterface.UnbanPeer(ctx, peer)
+}
+
+// AddToConsensusBlacklist adds frozen utxos to the blacklist (unless the call fails on purpose)
+func (n *chaosNode) AddToConsensusBlacklist(ctx context.Context,
+	funds []models.Fund) (*models.AddToConsensusBlacklistResponse, error) {
+	if err := n.chaos.FailRPC(); err != nil {
+		return nil, err
+	}
+	return n.NodeInterface.AddToConsensusBlacklist(ctx, funds)
+}
+
+// AddToConfiscationTransactionWhitelist adds confiscation transactions to the whitelist (unless the call fails on purpose)
+func (n *chaosNode) AddToConfiscationTransactionWhitelist(ctx context.Context,
+	tx []models.ConfiscationTransactionDetails) (*models.AddToConfiscationTransactionWhitelistResponse, error) {
+	if err := n.chaos.FailRPC(); err != nil {
+		return nil, err
+	}
+	return n.NodeInterface.AddToConfiscationTransactionWhitelist(ctx, tx)
+}
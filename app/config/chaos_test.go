@@ -0,0 +1,54 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/chaos"
+	"github.com/bitcoin-sv/alert-system/app/config/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestChaosConfig_validate will test the fault injection is never enabled on mainnet and checks its ranges
+func TestChaosConfig_validate(t *testing.T) {
+	valid := ChaosConfig{Enabled: true, GossipDropPercent: 20, RPCFailEvery: 5, SyncDelay: time.Second}
+	require.NoError(t, valid.validate(EnvironmentTestnet))
+	require.NoError(t, valid.validate(EnvironmentTest))
+
+	require.ErrorIs(t, valid.validate(EnvironmentMainnet), ErrChaosOnMainnet)
+	require.ErrorIs(t, valid.validate(EnvironmentProduction), ErrChaosOnMainnet)
+
+	for _, invalid := range []ChaosConfig{
+		{GossipDropPercent: -1},
+		{GossipDropPercent: 101},
+		{RPCFailEvery: -1},
+		{SyncDelay: -time.Second},
+	} {
+		require.ErrorIs(t, invalid.validate(EnvironmentTestnet), ErrInvalidChaos)
+	}
+}
+
+// TestChaosNode will test every Nth node RPC call fails before reaching the wrapped node
+func TestChaosNode(t *testing.T) {
+	ctx := context.Background()
+	mock := &mocks.Node{
+		BlockCountFunc: func(context.Context) (uint32, error) {
+			return 100, nil
+		},
+	}
+	node := newChaosNode(mock, chaos.New(chaos.Options{RPCFailEvery: 2}))
+	assert.Equal(t, mock, node.(*chaosNode).Unwrap())
+
+	count, err := node.BlockCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(100), count)
+
+	_, err = node.BlockCount(ctx)
+	require.ErrorIs(t, err, chaos.ErrInjectedRPC)
+
+	require.NoError(t, node.BanPeer(ctx, "peer"))
+	require.ErrorIs(t, node.UnbanPeer(ctx, "peer"), chaos.ErrInjectedRPC)
+	assert.Len(t, mock.Calls(""), 2, "the failed calls never reach the node")
+}
@@ -0,0 +1,11 @@
+package config
+
+import "github.com/bitcoin-sv/alert-system/app/clock"
+
+// Clock will return the clock of the services (the real clock if none is set, e.g. a config built by hand)
+func (c *Config) Clock() clock.Clock {
+	if c == nil || c.Services.Clock == nil {
+		return clock.New()
+	}
+	return c.Services.Clock
+}
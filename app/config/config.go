@@ -6,6 +6,13 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/bitcoin-sv/alert-system/app/audit"
+	"github.com/bitcoin-sv/alert-system/app/budget"
+	"github.com/bitcoin-sv/alert-system/app/chaos"
+	"github.com/bitcoin-sv/alert-system/app/clock"
+	"github.com/bitcoin-sv/alert-system/app/devnet"
+	"github.com/bitcoin-sv/alert-system/app/reporting"
+	"github.com/bitcoin-sv/alert-system/app/sigcache"
 	"github.com/mrz1836/go-datastore"
 )
 
@@ -15,21 +22,40 @@ var envDir embed.FS // This is used for the config files
 // Constants for the environment
 const (
 	EnvironmentCustomFilePath = "ALERT_SYSTEM_CONFIG_FILEPATH" // Environment variable key for custom config file path
+	EnvironmentCustomFileType = "ALERT_SYSTEM_CONFIG_TYPE"     // Environment variable key for the type of the custom config file (detected from the extension by default)
 	EnvironmentKey            = "ALERT_SYSTEM_ENVIRONMENT"     // Environment variable key
+	EnvironmentDevnet         = "devnet"                       // Environment for the local devnet (throwaway genesis keys, mock node)
 	EnvironmentLocal          = "local"                        // Environment for local development
 	EnvironmentPrefix         = "alert_system"                 // Prefix for all environment variables
 	EnvironmentProduction     = "production"                   // Environment for production
+	EnvironmentSimulation     = "simulation"                   // Environment for replaying the mainnet alert history (in-memory datastore, mock node)
 	EnvironmentMainnet        = "mainnet"                      // Environment for mainnet (same as production)
 	EnvironmentTest           = "test"                         // Environment for testing
 	EnvironmentTestnet        = "testnet"                      // Environment for testnet
 	EnvironmentStn            = "stn"                          // Environment for STN testing
 )
 
+// Config file types (any type supported by viper can be used for a custom config file)
+const (
+	ConfigTypeHCL  = "hcl"  // HCL config file
+	ConfigTypeJSON = "json" // JSON config file (default, and the embedded environment files)
+	ConfigTypeTOML = "toml" // TOML config file
+	ConfigTypeYAML = "yaml" // YAML config file (.yaml or .yml)
+)
+
+// Audit log outputs
+const (
+	AuditOutputDatastore = "datastore" // Save the audit log in the datastore (audit_events table)
+	AuditOutputFile      = "file"      // Append the audit log to a file (default)
+)
+
 // Local variables for configuration
 var (
 	environments = []interface{}{
+		EnvironmentDevnet,
 		EnvironmentLocal,
 		EnvironmentProduction,
+		EnvironmentSimulation,
 		EnvironmentMainnet,
 		EnvironmentTest,
 		EnvironmentTestnet,
@@ -39,15 +65,74 @@ var (
 
 // Application configuration constants
 var (
-	ApplicationName                = "alert_system"                // Application name used in places where we need an application name space
-	DatabasePrefix                 = "alert_system"                // Default database prefix
-	DefaultAlertSystemProtocolID   = "/bitcoin/alert-system/0.0.1" // Default alert system protocol for libp2p syncing
-	DefaultTopicName               = "alert_system"                // Default alert system topic name for libp2p subscription
-	DefaultServerShutdown          = 5 * time.Second               // Default server shutdown delay time (to finish any requests or internal processes)
-	DefaultPeerDiscoveryInterval   = 10 * time.Minute              // Default peer discovery refresh interval
-	DefaultAlertProcessingInterval = 5 * time.Minute               // Default alert processing retry interval
-	LocalPrivateKeyDefault         = "alert_system_private_key"    // Default local private key
-	LocalPrivateKeyDirectory       = ".bitcoin"                    // Default local private key directory
+	ApplicationName                  = "alert_system"                // Application name used in places where we need an application name space
+	DatabasePrefix                   = "alert_system"                // Default database prefix
+	DefaultAlertSystemProtocolID     = "/bitcoin/alert-system/0.0.1" // Default alert system protocol for libp2p syncing
+	DefaultTopicName                 = "alert_system"                // Default alert system topic name for libp2p subscription
+	DefaultServerShutdown            = 5 * time.Second               // Default server shutdown grace period (to finish any requests or internal processes)
+	DefaultServerCompressMinBytes    = 1024                          // Default min response size before gzip compression is used
+	DefaultServerHTTP2MaxStreams     = uint32(250)                   // Default max concurrent HTTP/2 streams per connection
+	DefaultServerHTTP2StreamBuffer   = int32(1 << 20)                // Default max buffered HTTP/2 request body per stream (1MB)
+	DefaultServerIdleTimeout         = 60 * time.Second              // Default idle (keep-alive) timeout for the web server
+	DefaultServerMaxBodyBytes        = int64(1 << 20)                // Default max request body size for the web server (1MB)
+	DefaultServerMaxHeaderBytes      = 1 << 16                       // Default max request header size for the web server (64KB)
+	DefaultServerReadHeaderTimeout   = 5 * time.Second               // Default timeout for reading the request headers (slowloris protection)
+	DefaultServerReadTimeout         = 15 * time.Second              // Default timeout for reading the entire request
+	DefaultServerWriteTimeout        = 15 * time.Second              // Default timeout for writing the response
+	DefaultSignatureCacheSize        = 1024                          // Default number of signature verification results cached (LRU)
+	DefaultShutdownAlerts            = 30 * time.Second              // Default time for the alerts being processed to finish at shutdown
+	DefaultShutdownDatastore         = 5 * time.Second               // Default time for closing the datastore at shutdown
+	DefaultShutdownNotifications     = 10 * time.Second              // Default time for delivering the queued notifications and webhooks at shutdown
+	DefaultShutdownP2P               = 10 * time.Second              // Default time for closing the P2P host and DHT at shutdown
+	DefaultShutdownRestart           = 2 * time.Minute               // Default time for the new process to be ready on a restart
+	DefaultP2PIP                     = "0.0.0.0"                     // Default P2P listen address of the configs built with New
+	DefaultP2PPort                   = "9906"                        // Default P2P port of the configs built with New
+	DefaultServerPort                = "3000"                        // Default web server port of the configs built with New
+	DefaultPeerDiscoveryInterval     = 10 * time.Minute              // Default peer discovery refresh interval
+	DefaultPeerBanExpiryInterval     = 1 * time.Minute               // Default interval for lifting expired peer bans
+	DefaultPeerStateTTL              = 1 * time.Hour                 // Default time the tracked state of a disconnected peer is kept
+	DefaultAlertProcessingInterval   = 5 * time.Minute               // Default alert processing retry interval
+	DefaultAlertQueueSize            = 100                           // Default number of gossiped alert messages queued for processing
+	DefaultNodeRetryInterval         = 30 * time.Second              // Default time an unreachable node is skipped by the reads before it is tried again
+	DefaultFastSyncAlerts            = 10                            // Default number of the latest alerts synced first when the node starts behind (the older ones are backfilled after)
+	DefaultAlertLockTTL              = 5 * time.Minute               // Default time an alert lock is held (a lock of a crashed instance is taken over after it)
+	DefaultBudgetMaxAPIHandlers      = 256                           // Default number of concurrent API requests (503 over it)
+	DefaultBudgetMaxStreamHandlers   = 32                            // Default number of concurrent P2P sync streams served to the peers (reset over it)
+	DefaultAuditFile                 = "alert_system_audit.log"      // Default audit log file (for the file output)
+	DefaultClusterLeaseDuration      = 10 * time.Second              // Default time a cluster leader holds its lease without renewing it
+	DefaultClusterName               = "alert_system"                // Default cluster name (the instances sharing a datastore and name form a cluster)
+	DefaultClusterRenewInterval      = 3 * time.Second               // Default interval between the cluster lease renewals (and standby takeover attempts)
+	DefaultAutoCertCacheDir          = "alert_system_autocert"       // Default directory for caching ACME certificates
+	DefaultDiagnosticsDir            = "alert_system_diagnostics"    // Default directory for the diagnostic bundles written on a panic
+	DefaultProfilingCPUPercent       = 90.0                          // Default CPU threshold of the profiling watchdog (percent of all the CPUs)
+	DefaultProfilingDir              = "alert_system_profiles"       // Default directory of the profiles captured by the watchdog
+	DefaultProfilingMaxProfiles      = 10                            // Default captures kept by the profiling watchdog
+	DefaultProfilingMemoryMB         = 1024                          // Default memory threshold of the profiling watchdog
+	DefaultProfilingSustained        = 2 * time.Minute               // Default time a threshold is exceeded before the watchdog captures
+	DefaultDiagnosticsMaxBundles     = 10                            // Default max diagnostic bundles kept (the oldest are removed)
+	DefaultHeartbeatInterval         = 1 * time.Minute               // Default interval between heartbeats
+	DefaultLogDedupBurst             = 5                             // Default repeats of a message logged within the dedup window
+	DefaultLogDedupWindow            = 1 * time.Minute               // Default window for dropping repeated log messages
+	DefaultLogLevel                  = "info"                        // Default min log level
+	DefaultLogMaxSizeMB              = 100                           // Default max size of the log output file before it is rotated
+	DefaultNotificationMaxRetries    = 5                             // Default max retries of a notification
+	DefaultNotificationQueueSize     = 100                           // Default size of the notification queue
+	DefaultNotificationRetryInterval = 10 * time.Second              // Default interval between notification retries (doubles each attempt)
+	DefaultOutboxBatchSize           = 100                           // Default max outbox events replayed per run
+	DefaultOutboxInterval            = 1 * time.Minute               // Default interval between the outbox replays
+	DefaultOutboxMaxAttempts         = 10                            // Default max replay attempts of an outbox event (marked failed after)
+	DefaultOutboxMinAge              = 30 * time.Second              // Default age of a pending outbox event before it is replayed
+	DefaultAutoCertHTTPPort          = "80"                          // Default port for the ACME HTTP-01 challenge handler
+	DefaultTracingEndpoint           = "http://localhost:4318"       // Default OTLP/HTTP collector endpoint (traces are posted to /v1/traces)
+	DefaultTracingServiceName        = "alert-system"                // Default service name reported with the traces
+	DefaultWebhookMaxAge             = time.Hour                     // Default max age of a webhook delivery (no retry once it is older)
+	DefaultWebhookMaxRetries         = 5                             // Default max delivery retries for a registered webhook
+	DefaultWebhookQueueSize          = 100                           // Default size of the webhook delivery queue
+	DefaultWebhookRetryInterval      = 10 * time.Second              // Default interval between webhook delivery retries (doubles each attempt)
+	DefaultWebhookWorkers            = 2                             // Default number of webhook delivery workers
+	LegacySunsetLayout               = "2006-01-02"                  // Date layout for the legacy (unversioned) routes sunset date
+	LocalPrivateKeyDefault           = "alert_system_private_key"    // Default local private key
+	LocalPrivateKeyDirectory         = ".bitcoin"                    // Default local private key directory
 )
 
 // The global configuration settings
@@ -55,18 +140,47 @@ type (
 
 	// Config is the global configuration settings
 	Config struct {
-		AlertWebhookURL         string          `json:"alert_webhook_url" mapstructure:"alert_webhook_url"`                 // AlertWebhookURL is the URL for the alert webhook
-		GenesisKeys             []string        `json:"genesis_keys" mapstructure:"genesis_keys"`                           // GenesisKeys is list of public keys to use for the genesis alert
-		Datastore               DatastoreConfig `json:"datastore" mapstructure:"datastore"`                                 // Datastore's configuration
-		DisableRPCVerification  bool            `json:"disable_rpc_verification" mapstructure:"disable_rpc_verification"`   // DisableRPCVerification will disable the rpc verification check on startup. Useful if bitcoind isn't running yet
-		LogOutputFile           string          `json:"log_output_file" mapstructure:"log_output_file"`                     // LogOutputFile will set an output file for the logger to write to as opposed to stdout
-		BitcoinConfigPath       string          `json:"bitcoin_config_path" mapstructure:"bitcoin_config_path"`             // BitcoinConfigPath is the path to the bitcoin.conf file
-		P2P                     P2PConfig       `json:"p2p" mapstructure:"p2p"`                                             // P2P is the configuration for the P2P server
-		RPCConnections          []RPCConfig     `json:"rpc_connections" mapstructure:"rpc_connections"`                     // RPCConnections is a list of RPC connections
-		RequestLogging          bool            `json:"request_logging" mapstructure:"request_logging"`                     // Toggle for verbose request logging (API requests)
-		Services                Services        `json:"-" mapstructure:"services"`                                          // Services is the global services
-		WebServer               WebServerConfig `json:"web_server" mapstructure:"web_server"`                               // WebServer is the configuration for the web HTTP Server
-		AlertProcessingInterval time.Duration   `json:"alert_processing_interval" mapstructure:"alert_processing_interval"` // AlertProcessingInterval is the interval in which the system will go through all of the saved alerts and attempt to retry any unprocessed alerts
+		AlertWebhookURL         string              `json:"alert_webhook_url" mapstructure:"alert_webhook_url"`                 // AlertWebhookURL is the URL for the alert webhook
+		AlertLocks              AlertLocksConfig    `json:"alert_locks" mapstructure:"alert_locks"`                             // AlertLocks is the locking of each alert sequence in the datastore (one instance enforces an alert)
+		Audit                   AuditConfig         `json:"audit" mapstructure:"audit"`                                         // Audit is the hash-chained audit log of the security-relevant events
+		Chaos                   ChaosConfig         `json:"chaos" mapstructure:"chaos"`                                         // Chaos is the fault injection in the P2P and RPC layers (dropped gossip, delayed syncs, failed RPC calls), never on mainnet
+		GenesisKeys             []string            `json:"genesis_keys" mapstructure:"genesis_keys"`                           // GenesisKeys is list of public keys to use for the genesis alert
+		Heartbeat               HeartbeatConfig     `json:"heartbeat" mapstructure:"heartbeat"`                                 // Heartbeat is the periodic heartbeat (log, metrics and an optional dead man's switch URL)
+		Instance                InstanceConfig      `json:"instance" mapstructure:"instance"`                                   // Instance is the PID file and the lock preventing two instances with the same identity
+		Diagnostics             DiagnosticsConfig   `json:"diagnostics" mapstructure:"diagnostics"`                             // Diagnostics is the diagnostic bundles written when a goroutine panics
+		Datastore               DatastoreConfig     `json:"datastore" mapstructure:"datastore"`                                 // Datastore's configuration
+		Devnet                  bool                `json:"devnet" mapstructure:"devnet"`                                       // Devnet will generate throwaway genesis keys at startup and use the mock node (local development only)
+		Environment             string              `json:"environment" mapstructure:"environment"`                             // Environment is the network of the config (ALERT_SYSTEM_ENVIRONMENT, or set by New)
+		DisableRPCVerification  bool                `json:"disable_rpc_verification" mapstructure:"disable_rpc_verification"`   // DisableRPCVerification will disable the rpc verification check on startup. Useful if bitcoind isn't running yet
+		LogDedup                LogDedupConfig      `json:"log_dedup" mapstructure:"log_dedup"`                                 // LogDedup is the deduplication of repeated log messages (summarized as "repeated N more times")
+		LogFormat               string              `json:"log_format" mapstructure:"log_format"`                               // LogFormat is the log format, text (default) or json (structured fields for Loki/ELK)
+		LogLevel                string              `json:"log_level" mapstructure:"log_level"`                                 // LogLevel is the min log level, debug, info (default), warn or error
+		LogLevels               map[string]string   `json:"log_levels" mapstructure:"log_levels"`                               // LogLevels are the per-module log level overrides (e.g. p2p=debug, webserver=warn)
+		LogRotation             LogRotationConfig   `json:"log_rotation" mapstructure:"log_rotation"`                           // LogRotation is the rotation for the LogOutputFile (size based, with retention and compression)
+		LogOutput               string              `json:"log_output" mapstructure:"log_output"`                               // LogOutput is where the logs are written, stdout (default), file, syslog, journald or eventlog
+		LogOutputFile           string              `json:"log_output_file" mapstructure:"log_output_file"`                     // LogOutputFile will set an output file for the logger to write to as opposed to stdout
+		LogSyslog               SyslogConfig        `json:"log_syslog" mapstructure:"log_syslog"`                               // LogSyslog is the local or remote syslog for the syslog LogOutput (the tag is also the journald identifier and event log source)
+		BitcoinConfigPath       string              `json:"bitcoin_config_path" mapstructure:"bitcoin_config_path"`             // BitcoinConfigPath is the path to the bitcoin.conf file
+		Budget                  BudgetConfig        `json:"budget" mapstructure:"budget"`                                       // Budget is the memory and concurrent handler soft limits (protects a node running on the same host)
+		Cluster                 ClusterConfig       `json:"cluster" mapstructure:"cluster"`                                     // Cluster is the active/standby clustering of the instances sharing a datastore (only the leader enforces the alerts)
+		NodeMock                NodeMockConfig      `json:"node_mock" mapstructure:"node_mock"`                                 // NodeMock is the local dev mode replacing the node with a scripted mock (responses, latencies and failures)
+		NodePool                NodePoolConfig      `json:"node_pool" mapstructure:"node_pool"`                                 // NodePool is the failover between the nodes of the RPC connections (reads balanced, alert actions sent to all)
+		Notifications           NotificationsConfig `json:"notifications" mapstructure:"notifications"`                         // Notifications is the human-readable notifications of the alert and node events (Slack, ...)
+		Outbox                  OutboxConfig        `json:"outbox" mapstructure:"outbox"`                                       // Outbox is the replay of the alert events saved with the alerts but not published (e.g. after a crash)
+		P2P                     P2PConfig           `json:"p2p" mapstructure:"p2p"`                                             // P2P is the configuration for the P2P server
+		Profiling               ProfilingConfig     `json:"profiling" mapstructure:"profiling"`                                 // Profiling is the watchdog capturing CPU and heap profiles under sustained resource pressure
+		Reporting               ReportingConfig     `json:"reporting" mapstructure:"reporting"`                                 // Reporting is the error reporting of panics and error logs (Sentry)
+		RPCConnections          []RPCConfig         `json:"rpc_connections" mapstructure:"rpc_connections"`                     // RPCConnections is a list of RPC connections
+		RequestLogging          bool                `json:"request_logging" mapstructure:"request_logging"`                     // Toggle for verbose request logging (API requests)
+		Services                Services            `json:"-" mapstructure:"services"`                                          // Services is the global services
+		Shutdown                ShutdownConfig      `json:"shutdown" mapstructure:"shutdown"`                                   // Shutdown is the deadlines of the shutdown stages (the web server uses web_server.shutdown_timeout)
+		SignatureCacheSize      int                 `json:"signature_cache_size" mapstructure:"signature_cache_size"`           // SignatureCacheSize is the number of signature verification results cached (1024, negative to disable)
+		SlowLog                 SlowLogConfig       `json:"slow_log" mapstructure:"slow_log"`                                   // SlowLog is the thresholds for logging slow operations (latency regressions without tracing)
+		StatsD                  StatsDConfig        `json:"statsd" mapstructure:"statsd"`                                       // StatsD is the push of the metrics to a StatsD or DogStatsD agent (alternative to scraping /metrics)
+		Tracing                 TracingConfig       `json:"tracing" mapstructure:"tracing"`                                     // Tracing is the OpenTelemetry tracing of the alert pipeline (exported via OTLP)
+		WebServer               WebServerConfig     `json:"web_server" mapstructure:"web_server"`                               // WebServer is the configuration for the web HTTP Server
+		Webhooks                WebhookConfig       `json:"webhooks" mapstructure:"webhooks"`                                   // Webhooks is the configuration for delivering to registered webhooks
+		AlertProcessingInterval time.Duration       `json:"alert_processing_interval" mapstructure:"alert_processing_interval"` // AlertProcessingInterval is the interval in which the system will go through all of the saved alerts and attempt to retry any unprocessed alerts
 	}
 
 	// DatastoreConfig is the configuration for the datastore
@@ -88,16 +202,36 @@ type (
 
 	// Node is the configuration and functions for interacting with a node
 	Node struct {
-		RPCHost     string `json:"rpc_host" mapstructure:"rpc_host"`         // RPCHost is the RPC host
-		RPCPassword string `json:"rpc_password" mapstructure:"rpc_password"` // RPCPassword is the RPC password
-		RPCUser     string `json:"rpc_user" mapstructure:"rpc_user"`         // RPCUser is the RPC username
+		RPCHost     string          `json:"rpc_host" mapstructure:"rpc_host"`         // RPCHost is the RPC host
+		RPCPassword string          `json:"rpc_password" mapstructure:"rpc_password"` // RPCPassword is the RPC password
+		RPCUser     string          `json:"rpc_user" mapstructure:"rpc_user"`         // RPCUser is the RPC username
+		logger      LoggerInterface // Logger for the slow RPC calls
+		slowRPC     time.Duration   // Threshold for logging a slow RPC call (0 is disabled)
+	}
+
+	// LogDedupConfig is the configuration for dropping repeated log messages (e.g. the same peer failing signature verification)
+	LogDedupConfig struct {
+		Burst  int           `json:"burst" mapstructure:"burst"`   // 5 (repeats of a message logged within the window before they are dropped)
+		Sample int           `json:"sample" mapstructure:"sample"` // 0 (after the burst, log every Nth repeat, 0 drops them all)
+		Window time.Duration `json:"window" mapstructure:"window"` // 1m (a summary of the dropped repeats is logged when it ends, negative disables)
+	}
+
+	// LogRotationConfig is the configuration for rotating the log output file
+	LogRotationConfig struct {
+		Compress   bool          `json:"compress" mapstructure:"compress"`       // false (gzip the rotated files)
+		MaxAge     time.Duration `json:"max_age" mapstructure:"max_age"`         // 0 (rotated files are kept forever)
+		MaxBackups int           `json:"max_backups" mapstructure:"max_backups"` // 0 (all rotated files are kept)
+		MaxSizeMB  int           `json:"max_size_mb" mapstructure:"max_size_mb"` // 100 (size in megabytes before the file is rotated)
 	}
 
 	// P2PConfig is the configuration for the P2P server and connection
 	P2PConfig struct {
+		AlertQueueSize        int           `json:"alert_queue_size" mapstructure:"alert_queue_size"`                 // AlertQueueSize is the number of gossiped alert messages queued for processing (the pubsub reader waits once it is full)
 		AlertSystemProtocolID string        `json:"alert_system_protocol_id" mapstructure:"alert_system_protocol_id"` // AlertSystemProtocolID is the protocol ID to use on the libp2p network for alert system communication
 		BootstrapPeer         string        `json:"bootstrap_peer" mapstructure:"bootstrap_peer"`                     // BootstrapPeer is the bootstrap peer for the libp2p network
 		BroadcastIP           string        `json:"broadcast_ip" mapstructure:"broadcast_ip"`                         // BroadcastIP is the public facing IP address to broadcast to other peers
+		DisableKeyGeneration  bool          `json:"disable_key_generation" mapstructure:"disable_key_generation"`     // DisableKeyGeneration will fail the startup if the private key is missing (instead of generating it), the keygen command creates it
+		FastSyncAlerts        int           `json:"fast_sync_alerts" mapstructure:"fast_sync_alerts"`                 // FastSyncAlerts is the number of the latest alerts synced (and enforced) first by the startup sync when more are missing, the older ones are backfilled in the background (negative to sync all in order)
 		IP                    string        `json:"ip" mapstructure:"ip"`                                             // IP is the IP address for the P2P server
 		Port                  string        `json:"port" mapstructure:"port"`                                         // Port is the port for the P2P server
 		PrivateKeyPath        string        `json:"private_key_path" mapstructure:"private_key_path"`                 // PrivateKeyPath is the path to the private key
@@ -105,6 +239,18 @@ type (
 		PeerDiscoveryInterval time.Duration `json:"peer_discovery_interval" mapstructure:"peer_discovery_interval"`   // PeerDiscoveryInterval is the interval in which we will refresh the peer table and check peers for missing messages
 	}
 
+	// ProfilingConfig is the configuration for the profiling watchdog (pprof captures of a node under resource pressure)
+	ProfilingConfig struct {
+		CPUPercent         float64       `json:"cpu_percent" mapstructure:"cpu_percent"`                   // 90 (percent of all the CPUs, negative to ignore the CPU)
+		CPUProfileDuration time.Duration `json:"cpu_profile_duration" mapstructure:"cpu_profile_duration"` // 30s
+		Dir                string        `json:"dir" mapstructure:"dir"`                                   // alert_system_profiles
+		Enabled            bool          `json:"enabled" mapstructure:"enabled"`                           // false
+		Interval           time.Duration `json:"interval" mapstructure:"interval"`                         // 10s (between the usage samples)
+		MaxProfiles        int           `json:"max_profiles" mapstructure:"max_profiles"`                 // 10 (captures kept, the oldest are removed)
+		MemoryMB           int           `json:"memory_mb" mapstructure:"memory_mb"`                       // 1024 (memory held by the Go runtime, negative to ignore the memory)
+		Sustained          time.Duration `json:"sustained" mapstructure:"sustained"`                       // 2m (time a threshold is exceeded before capturing)
+	}
+
 	// RPCConfig is the configuration for the RPC client
 	RPCConfig struct {
 		Host     string `json:"host" mapstructure:"host"`         // Host is the RPC host
@@ -114,17 +260,381 @@ type (
 
 	// Services is the global services
 	Services struct {
-		Datastore  datastore.ClientInterface // Datastore interface
-		Log        LoggerInterface           // Logger interface
-		Node       NodeInterface             // Node interface
-		HTTPClient HTTPInterface             // HTTP client interface
+		APIHandlers    *budget.Limiter           // Concurrent API requests (nil if no limit)
+		Audit          *audit.Log                // Audit log (nil unless enabled)
+		Chaos          *chaos.Injector           // Fault injection (nil unless enabled)
+		Clock          clock.Clock               // Current time and timers (a mock clock in the tests)
+		Datastore      datastore.ClientInterface // Datastore interface
+		Devnet         *devnet.Signer            // Throwaway genesis keys signing the test alerts (nil unless devnet)
+		Log            LoggerInterface           // Logger interface
+		Node           NodeInterface             // Node interface (a NodePool of the Nodes if there are several RPC connections)
+		Nodes          []NodeInterface           // Node interfaces (one per RPC connection)
+		HTTPClient     HTTPInterface             // HTTP client interface
+		Reporter       reporting.Reporter        // Error reporter (nil unless a DSN is set)
+		Signatures     *sigcache.Cache           // Signature verification results (nil if the cache is disabled)
+		StreamHandlers *budget.Limiter           // Concurrent P2P sync streams served (nil if no limit)
+	}
+
+	// AuditConfig is the configuration for the audit log (alerts enforced, admin API calls, keys and config loaded)
+	AuditConfig struct {
+		Enabled bool   `json:"enabled" mapstructure:"enabled"` // false
+		File    string `json:"file" mapstructure:"file"`       // alert_system_audit.log (for the file output)
+		Output  string `json:"output" mapstructure:"output"`   // file (file or datastore, the audit_events table)
+	}
+
+	// AutoCertConfig is the configuration for automatic TLS certificates (ACME/Let's Encrypt)
+	AutoCertConfig struct {
+		CacheDir string   `json:"cache_dir" mapstructure:"cache_dir"` // alert_system_autocert
+		Domains  []string `json:"domains" mapstructure:"domains"`     // Domains to request certificates for (required)
+		Email    string   `json:"email" mapstructure:"email"`         // Contact email for the ACME account (optional)
+		Enabled  bool     `json:"enabled" mapstructure:"enabled"`     // Serve TLS with certificates from Let's Encrypt
+		HTTPPort string   `json:"http_port" mapstructure:"http_port"` // 80 (HTTP-01 challenges and redirects to HTTPS)
+	}
+
+	// DiagnosticsConfig is the configuration for the diagnostic bundles written when a goroutine panics
+	DiagnosticsConfig struct {
+		Dir        string `json:"dir" mapstructure:"dir"`                 // alert_system_diagnostics
+		MaxBundles int    `json:"max_bundles" mapstructure:"max_bundles"` // 10 (the oldest are removed, negative disables the bundles)
+	}
+
+	// DiscordConfig is the configuration for the Discord notifications (disabled if the webhook URL is empty)
+	DiscordConfig struct {
+		Events      []string `json:"events" mapstructure:"events"`             // [alert.enforced, node.healthy, node.unhealthy]
+		MinSeverity string   `json:"min_severity" mapstructure:"min_severity"` // info (info, warning or critical)
+		Username    string   `json:"username" mapstructure:"username"`         // "" (overrides the webhook name if set)
+		WebhookURL  string   `json:"webhook_url" mapstructure:"webhook_url"`   // "" (channel webhook URL, keep it secret)
+	}
+
+	// EmailConfig is the configuration for the email notifications over SMTP (disabled if the address is empty)
+	EmailConfig struct {
+		Address         string              `json:"address" mapstructure:"address"`                   // "" (SMTP host:port, e.g. smtp.example.com:587)
+		BodyTemplate    string              `json:"body_template" mapstructure:"body_template"`       // "" (Go template of the plain text body, the default template if empty)
+		Events          []string            `json:"events" mapstructure:"events"`                     // [alert.enforced, node.healthy, node.unhealthy]
+		From            string              `json:"from" mapstructure:"from"`                         // "" (sender address, required)
+		MinSeverity     string              `json:"min_severity" mapstructure:"min_severity"`         // info (info, warning or critical)
+		Password        string              `json:"password" mapstructure:"password"`                 // "" (PLAIN auth password)
+		Recipients      map[string][]string `json:"recipients" mapstructure:"recipients"`             // {} (severity to recipients, instead of the default recipients)
+		SubjectTemplate string              `json:"subject_template" mapstructure:"subject_template"` // "" (Go template of the subject, the default template if empty)
+		TLS             string              `json:"tls" mapstructure:"tls"`                           // starttls (starttls, tls for implicit TLS or none)
+		To              []string            `json:"to" mapstructure:"to"`                             // [] (default recipients)
+		Username        string              `json:"username" mapstructure:"username"`                 // "" (PLAIN auth username, no auth if empty)
+	}
+
+	// HeartbeatConfig is the configuration for the periodic heartbeat
+	HeartbeatConfig struct {
+		Interval time.Duration `json:"interval" mapstructure:"interval"` // 1m
+		URL      string        `json:"url" mapstructure:"url"`           // "" (dead man's switch URL, e.g. Healthchecks.io, <url>/fail is used if the node is unhealthy)
+	}
+
+	// HTTP2Config is the configuration for HTTP/2 on the web server
+	HTTP2Config struct {
+		Disabled     bool   `json:"disabled" mapstructure:"disabled"`           // false (HTTP/2 is negotiated over TLS unless disabled)
+		H2C          bool   `json:"h2c" mapstructure:"h2c"`                     // false (cleartext HTTP/2 for deployments behind a proxy)
+		MaxStreams   uint32 `json:"max_streams" mapstructure:"max_streams"`     // 250 (max concurrent streams per connection)
+		StreamBuffer int32  `json:"stream_buffer" mapstructure:"stream_buffer"` // 1048576 (1MB, max buffered request body per stream)
+	}
+
+	// KafkaConfig is the configuration for publishing the events to Kafka (disabled if there are no brokers)
+	KafkaConfig struct {
+		Brokers       []string `json:"brokers" mapstructure:"brokers"`               // [] (bootstrap brokers, host:port)
+		ClientID      string   `json:"client_id" mapstructure:"client_id"`           // alert-system
+		Events        []string `json:"events" mapstructure:"events"`                 // [] (every event if empty)
+		MinSeverity   string   `json:"min_severity" mapstructure:"min_severity"`     // info (info, warning or critical)
+		Password      string   `json:"password" mapstructure:"password"`             // "" (SASL password)
+		SASLMechanism string   `json:"sasl_mechanism" mapstructure:"sasl_mechanism"` // "" (PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512, no auth if empty)
+		TLS           bool     `json:"tls" mapstructure:"tls"`                       // false (connect to the brokers over TLS)
+		Topic         string   `json:"topic" mapstructure:"topic"`                   // alert-system
+		Username      string   `json:"username" mapstructure:"username"`             // "" (SASL username)
+	}
+
+	// MatrixConfig is the configuration for the Matrix notifications (disabled if the homeserver URL is empty)
+	MatrixConfig struct {
+		AccessToken   string   `json:"access_token" mapstructure:"access_token"`     // "" (access token of the bot user, required)
+		Events        []string `json:"events" mapstructure:"events"`                 // [alert.enforced, node.healthy, node.unhealthy]
+		HomeserverURL string   `json:"homeserver_url" mapstructure:"homeserver_url"` // "" (e.g. https://matrix.org)
+		MinSeverity   string   `json:"min_severity" mapstructure:"min_severity"`     // info (info, warning or critical)
+		RoomIDs       []string `json:"room_ids" mapstructure:"room_ids"`             // [] (room IDs !id:server the bot has joined, required)
+	}
+
+	// MQTTConfig is the configuration for publishing the events to an MQTT broker (disabled if the broker is empty)
+	MQTTConfig struct {
+		Broker      string   `json:"broker" mapstructure:"broker"`             // "" (host:port, e.g. localhost:1883)
+		ClientID    string   `json:"client_id" mapstructure:"client_id"`       // "" (alert-system-<random> if empty)
+		Events      []string `json:"events" mapstructure:"events"`             // [alert.enforced, node.healthy, node.unhealthy]
+		MinSeverity string   `json:"min_severity" mapstructure:"min_severity"` // info (info, warning or critical)
+		Password    string   `json:"password" mapstructure:"password"`         // "" (password of the user)
+		QoS         int      `json:"qos" mapstructure:"qos"`                   // 0 (0 at most once, 1 at least once or 2 exactly once)
+		Retain      bool     `json:"retain" mapstructure:"retain"`             // false (the broker keeps the last message of each topic for new subscribers)
+		TLS         bool     `json:"tls" mapstructure:"tls"`                   // false (connect to the broker over TLS)
+		Topic       string   `json:"topic" mapstructure:"topic"`               // alert-system (events are published on <topic>/<event>, e.g. alert-system/alert/enforced)
+		Username    string   `json:"username" mapstructure:"username"`         // "" (user, no auth if empty)
+	}
+
+	// NATSConfig is the configuration for publishing the events to NATS (disabled if there are no servers)
+	NATSConfig struct {
+		Events      []string `json:"events" mapstructure:"events"`             // [] (every event if empty)
+		JetStream   bool     `json:"jetstream" mapstructure:"jetstream"`       // false (wait for the stream acknowledgement, at-least-once)
+		MinSeverity string   `json:"min_severity" mapstructure:"min_severity"` // info (info, warning or critical)
+		Password    string   `json:"password" mapstructure:"password"`         // "" (user password)
+		Servers     []string `json:"servers" mapstructure:"servers"`           // [] (host:port)
+		Subject     string   `json:"subject" mapstructure:"subject"`           // alert-system (events are published on <subject>.<event>)
+		TLS         bool     `json:"tls" mapstructure:"tls"`                   // false (always used if the server requires it)
+		Token       string   `json:"token" mapstructure:"token"`               // "" (auth token)
+		Username    string   `json:"username" mapstructure:"username"`         // "" (user, no auth if empty)
+	}
+
+	// NotificationRuleConfig is a routing rule of the notifications (the conditions that are set must all match)
+	// The first rule that matches a notification decides if its channels are notified, whatever their own filters
+	NotificationRuleConfig struct {
+		Action      string            `json:"action" mapstructure:"action"`             // notify (notify or drop)
+		AlertTypes  []string          `json:"alert_types" mapstructure:"alert_types"`   // [] (any alert type, e.g. invalidate_block)
+		Channels    []string          `json:"channels" mapstructure:"channels"`         // [] (every channel)
+		Events      []string          `json:"events" mapstructure:"events"`             // [] (any event)
+		Fields      map[string]string `json:"fields" mapstructure:"fields"`             // {} (decoded alert fields, e.g. {"block_hash": "0000..."})
+		MinSeverity string            `json:"min_severity" mapstructure:"min_severity"` // info (info, warning or critical)
+		Name        string            `json:"name" mapstructure:"name"`                 // "" (logged when the rule drops a notification)
+		Peers       []string          `json:"peers" mapstructure:"peers"`               // [] (any peer the alert came from, or banned peer)
+	}
+
+	// NotificationsConfig is the configuration for the notification channels
+	NotificationsConfig struct {
+		Discord       DiscordConfig                `json:"discord" mapstructure:"discord"`               // Discord (webhook with rich embeds)
+		Email         EmailConfig                  `json:"email" mapstructure:"email"`                   // Email (SMTP)
+		Kafka         KafkaConfig                  `json:"kafka" mapstructure:"kafka"`                   // Kafka (producer of every event, for streaming pipelines)
+		Matrix        MatrixConfig                 `json:"matrix" mapstructure:"matrix"`                 // Matrix (bot user in the rooms)
+		MaxRetries    int                          `json:"max_retries" mapstructure:"max_retries"`       // 5
+		MQTT          MQTTConfig                   `json:"mqtt" mapstructure:"mqtt"`                     // MQTT (compact JSON for the lightweight consumers)
+		NATS          NATSConfig                   `json:"nats" mapstructure:"nats"`                     // NATS (publisher of every event, optionally JetStream)
+		QueueSize     int                          `json:"queue_size" mapstructure:"queue_size"`         // 100
+		PagerDuty     PagerDutyConfig              `json:"pagerduty" mapstructure:"pagerduty"`           // PagerDuty (Events API v2)
+		Rules         []NotificationRuleConfig     `json:"rules" mapstructure:"rules"`                   // [] (routing rules, the first matching rule of a channel decides)
+		RetryInterval time.Duration                `json:"retry_interval" mapstructure:"retry_interval"` // 10s (doubles each attempt)
+		Slack         SlackConfig                  `json:"slack" mapstructure:"slack"`                   // Slack (incoming webhook or bot token)
+		SNS           SNSConfig                    `json:"sns" mapstructure:"sns"`                       // AWS SNS topic (every event, for fan-out)
+		SQS           SQSConfig                    `json:"sqs" mapstructure:"sqs"`                       // AWS SQS queue (every event)
+		Telegram      TelegramConfig               `json:"telegram" mapstructure:"telegram"`             // Telegram (bot)
+		Templates     map[string]map[string]string `json:"templates" mapstructure:"templates"`           // {} (Go templates of the messages, by channel or default then by alert type or default)
+		Teranode      TeranodeConfig               `json:"teranode" mapstructure:"teranode"`             // Teranode (blob and notification services)
+		Twilio        TwilioConfig                 `json:"twilio" mapstructure:"twilio"`                 // Twilio (SMS of the critical notifications)
+	}
+
+	// AlertLocksConfig is the configuration for the alert locks (instances sharing a datastore and the same nodes)
+	AlertLocksConfig struct {
+		Enabled bool          `json:"enabled" mapstructure:"enabled"` // false (each instance enforces the alerts it receives)
+		TTL     time.Duration `json:"ttl" mapstructure:"ttl"`         // 5m (must be longer than the node actions of an alert)
+	}
+
+	// ChaosConfig is the configuration for the fault injection (testnets and CI, never on mainnet)
+	ChaosConfig struct {
+		Enabled           bool          `json:"enabled" mapstructure:"enabled"`                         // false
+		GossipDropPercent float64       `json:"gossip_drop_percent" mapstructure:"gossip_drop_percent"` // 0 (percent of the gossiped alerts dropped on receipt, 0-100)
+		RPCFailEvery      int           `json:"rpc_fail_every" mapstructure:"rpc_fail_every"`           // 0 (every Nth node RPC call fails)
+		Seed              int64         `json:"seed" mapstructure:"seed"`                               // 0 (random, set it to reproduce the dropped gossip of a run)
+		SyncDelay         time.Duration `json:"sync_delay" mapstructure:"sync_delay"`                   // 0 (delay before each sync response is sent to a peer)
+	}
+
+	// BudgetConfig is the configuration for the memory and goroutine budget (soft limits, warned once approached)
+	BudgetConfig struct {
+		Interval          time.Duration `json:"interval" mapstructure:"interval"`                       // 30s (between the usage samples)
+		MaxAPIHandlers    int           `json:"max_api_handlers" mapstructure:"max_api_handlers"`       // 256 (concurrent API requests, 503 over it, negative for no limit)
+		MaxStreamHandlers int           `json:"max_stream_handlers" mapstructure:"max_stream_handlers"` // 32 (concurrent P2P sync streams served, reset over it, negative for no limit)
+		MemoryLimitMB     int           `json:"memory_limit_mb" mapstructure:"memory_limit_mb"`         // 0 (soft memory limit of the Go runtime, GOMEMLIMIT wins, 0 for no limit)
+		WarnPercent       float64       `json:"warn_percent" mapstructure:"warn_percent"`               // 90 (percent of a limit used before a warning is logged)
+	}
+
+	// ClusterConfig is the configuration for the active/standby clustering (leader election with a datastore lease)
+	ClusterConfig struct {
+		Enabled       bool          `json:"enabled" mapstructure:"enabled"`               // false (a single instance, always the leader)
+		InstanceID    string        `json:"instance_id" mapstructure:"instance_id"`       // <hostname>-<pid> (the holder of the lease and the alert locks)
+		LeaseDuration time.Duration `json:"lease_duration" mapstructure:"lease_duration"` // 10s (a standby takes over once the lease of a failed leader expired)
+		Name          string        `json:"name" mapstructure:"name"`                     // alert_system (the instances with the same name and datastore form a cluster)
+		RenewInterval time.Duration `json:"renew_interval" mapstructure:"renew_interval"` // 3s (must be shorter than the lease duration)
+	}
+
+	// NodeMockConfig is the configuration for the node mock (local dev mode without a node, never on a real network)
+	NodeMockConfig struct {
+		Enabled bool                          `json:"enabled" mapstructure:"enabled"` // false (the RPC connections are real nodes)
+		Latency time.Duration                 `json:"latency" mapstructure:"latency"` // 0 (delay of every call of the mock)
+		Methods map[string][]NodeMockResponse `json:"methods" mapstructure:"methods"` // {} (scripted responses by method, e.g. invalidate_block, returned in order then the defaults)
+	}
+
+	// NodePoolConfig is the configuration for the failover between the nodes of the RPC connections
+	NodePoolConfig struct {
+		RetryInterval time.Duration `json:"retry_interval" mapstructure:"retry_interval"` // 30s (an unreachable node is skipped by the reads until it is tried again)
+	}
+
+	// NodeMockResponse is a scripted response of a node mock method
+	NodeMockResponse struct {
+		Delay time.Duration `json:"delay" mapstructure:"delay"` // 0 (added to the latency)
+		Error string        `json:"error" mapstructure:"error"` // "" (the error returned by the call, none if empty)
+	}
+
+	// InstanceConfig is the configuration for the PID file and the single-instance lock
+	InstanceConfig struct {
+		DisableLock bool   `json:"disable_lock" mapstructure:"disable_lock"` // false (a second instance with the same lock file fails to start)
+		LockFile    string `json:"lock_file" mapstructure:"lock_file"`       // <p2p.private_key_path>.lock (one instance per identity)
+		PIDFile     string `json:"pid_file" mapstructure:"pid_file"`         // "" (no PID file)
+	}
+
+	// OutboxConfig is the configuration for replaying the alert events of the outbox
+	OutboxConfig struct {
+		BatchSize   int           `json:"batch_size" mapstructure:"batch_size"`     // 100 (events replayed per run)
+		Interval    time.Duration `json:"interval" mapstructure:"interval"`         // 1m
+		MaxAttempts int           `json:"max_attempts" mapstructure:"max_attempts"` // 10 (the event is marked failed after)
+		MinAge      time.Duration `json:"min_age" mapstructure:"min_age"`           // 30s (younger events are being published)
+	}
+
+	// PagerDutyConfig is the configuration for the PagerDuty incidents (disabled if the routing key is empty)
+	PagerDutyConfig struct {
+		Events      []string `json:"events" mapstructure:"events"`             // [alert.enforced, node.healthy, node.unhealthy]
+		MinSeverity string   `json:"min_severity" mapstructure:"min_severity"` // critical (info, warning or critical, resolutions are always sent)
+		RoutingKey  string   `json:"routing_key" mapstructure:"routing_key"`   // "" (Events API v2 integration key)
+		URL         string   `json:"url" mapstructure:"url"`                   // https://events.pagerduty.com/v2/enqueue
+	}
+
+	// ReportingConfig is the configuration for reporting panics and error logs to Sentry
+	ReportingConfig struct {
+		DSN         string `json:"dsn" mapstructure:"dsn"`                 // Sentry DSN (disabled if empty)
+		Environment string `json:"environment" mapstructure:"environment"` // Defaults to the ALERT_SYSTEM_ENVIRONMENT
+	}
+
+	// ShutdownConfig is the deadlines of the shutdown stages, run in order: stop the intake, finish the alerts being
+	// processed, deliver the queued notifications, close the web server, the P2P server and the datastore
+	ShutdownConfig struct {
+		Alerts        time.Duration `json:"alerts" mapstructure:"alerts"`               // 30s (alerts being processed)
+		Datastore     time.Duration `json:"datastore" mapstructure:"datastore"`         // 5s (closing the datastore)
+		Notifications time.Duration `json:"notifications" mapstructure:"notifications"` // 10s (queued notifications and webhooks)
+		P2P           time.Duration `json:"p2p" mapstructure:"p2p"`                     // 10s (closing the P2P host and DHT)
+		Restart       time.Duration `json:"restart" mapstructure:"restart"`             // 2m (the new process being ready on a restart, SIGUSR2)
+	}
+
+	// SlowLogConfig is the thresholds for logging a warning on slow operations (0 disables the warning)
+	SlowLogConfig struct {
+		Datastore time.Duration `json:"datastore" mapstructure:"datastore"` // 0 (datastore queries and saves)
+		RPC       time.Duration `json:"rpc" mapstructure:"rpc"`             // 0 (node RPC calls)
+		Signature time.Duration `json:"signature" mapstructure:"signature"` // 0 (alert signature verification)
+		Sync      time.Duration `json:"sync" mapstructure:"sync"`           // 0 (sync rounds with a peer)
+	}
+
+	// SlackConfig is the configuration for the Slack notifications (disabled if neither the webhook URL nor the bot token is set)
+	SlackConfig struct {
+		BotToken    string            `json:"bot_token" mapstructure:"bot_token"`       // "" (xoxb- token, posted with chat.postMessage instead of the webhook)
+		Channel     string            `json:"channel" mapstructure:"channel"`           // "" (channel for the bot token)
+		Channels    map[string]string `json:"channels" mapstructure:"channels"`         // {} (severity to channel, or to incoming webhook URL without a bot token)
+		Events      []string          `json:"events" mapstructure:"events"`             // [alert.enforced, node.healthy, node.unhealthy]
+		MinSeverity string            `json:"min_severity" mapstructure:"min_severity"` // info (info, warning or critical)
+		Template    string            `json:"template" mapstructure:"template"`         // "" (Go template of the message, the default template if empty)
+		WebhookURL  string            `json:"webhook_url" mapstructure:"webhook_url"`   // "" (incoming webhook URL)
+	}
+
+	// StatsDConfig is the configuration for pushing the metrics to a StatsD or DogStatsD agent
+	StatsDConfig struct {
+		Address   string        `json:"address" mapstructure:"address"`     // "" (agent host:port over UDP, e.g. localhost:8125, disabled if empty)
+		DogStatsD bool          `json:"dogstatsd" mapstructure:"dogstatsd"` // false (labels are sent as DogStatsD tags, appended to the name otherwise)
+		Interval  time.Duration `json:"interval" mapstructure:"interval"`   // 10s
+		Prefix    string        `json:"prefix" mapstructure:"prefix"`       // "" (prefix for the metric names)
+	}
+
+	// SyslogConfig is the configuration for writing the logs to syslog (RFC5424)
+	SyslogConfig struct {
+		Address  string `json:"address" mapstructure:"address"`   // Remote syslog host:port (the local socket if empty)
+		Facility string `json:"facility" mapstructure:"facility"` // daemon (user, daemon or local0-local7)
+		Network  string `json:"network" mapstructure:"network"`   // udp if an address is set, unix otherwise (udp, tcp or unix)
+		Tag      string `json:"tag" mapstructure:"tag"`           // alert-system
+	}
+
+	// SNSConfig is the configuration for publishing the events to an AWS SNS topic (disabled if the topic ARN is empty)
+	// The credentials are resolved with the standard credential chain (environment, shared file, container or instance role)
+	SNSConfig struct {
+		Endpoint    string   `json:"endpoint" mapstructure:"endpoint"`         // "" (https://sns.<region>.amazonaws.com/ if empty, e.g. for LocalStack)
+		Events      []string `json:"events" mapstructure:"events"`             // [] (every event if empty)
+		MinSeverity string   `json:"min_severity" mapstructure:"min_severity"` // info (info, warning or critical)
+		Region      string   `json:"region" mapstructure:"region"`             // "" (region of the topic ARN if empty)
+		TopicARN    string   `json:"topic_arn" mapstructure:"topic_arn"`       // "" (e.g. arn:aws:sns:us-east-1:123456789012:alerts, .fifo topics are ordered by alert)
+	}
+
+	// SQSConfig is the configuration for sending the events to an AWS SQS queue (disabled if the queue URL is empty)
+	// The credentials are resolved with the standard credential chain (environment, shared file, container or instance role)
+	SQSConfig struct {
+		Events      []string `json:"events" mapstructure:"events"`             // [] (every event if empty)
+		MinSeverity string   `json:"min_severity" mapstructure:"min_severity"` // info (info, warning or critical)
+		QueueURL    string   `json:"queue_url" mapstructure:"queue_url"`       // "" (e.g. https://sqs.us-east-1.amazonaws.com/123456789012/alerts, .fifo queues are ordered by alert)
+		Region      string   `json:"region" mapstructure:"region"`             // "" (region of the queue URL if empty)
+	}
+
+	// TelegramConfig is the configuration for the Telegram notifications (disabled if the bot token is empty)
+	TelegramConfig struct {
+		BotToken    string   `json:"bot_token" mapstructure:"bot_token"`       // "" (token from @BotFather)
+		ChatIDs     []string `json:"chat_ids" mapstructure:"chat_ids"`         // [] (chat IDs or @channel usernames, required)
+		Events      []string `json:"events" mapstructure:"events"`             // [alert.enforced, node.healthy, node.unhealthy]
+		MinSeverity string   `json:"min_severity" mapstructure:"min_severity"` // info (info, warning or critical)
+		URL         string   `json:"url" mapstructure:"url"`                   // https://api.telegram.org (Bot API server)
+	}
+
+	// TeranodeConfig is the configuration for forwarding the validated alerts to Teranode (disabled if neither URL is set)
+	// The signed alert is stored in the blob service (keyed by its hash) and announced to the notification service
+	TeranodeConfig struct {
+		BlobURL         string   `json:"blob_url" mapstructure:"blob_url"`                 // "" (blob service, the alert is stored with PUT <blob_url>/blob/<hash>)
+		Events          []string `json:"events" mapstructure:"events"`                     // [alert.verified] (only the alert events are forwarded)
+		MinSeverity     string   `json:"min_severity" mapstructure:"min_severity"`         // info (info, warning or critical)
+		NotificationURL string   `json:"notification_url" mapstructure:"notification_url"` // "" (notification service, the alert is announced with a POST)
+		Token           string   `json:"token" mapstructure:"token"`                       // "" (sent as a bearer token if set)
+	}
+
+	// TracingConfig is the configuration for OpenTelemetry tracing (exported via OTLP/HTTP)
+	TracingConfig struct {
+		Enabled     bool    `json:"enabled" mapstructure:"enabled"`           // false
+		Endpoint    string  `json:"endpoint" mapstructure:"endpoint"`         // http://localhost:4318 (OTLP/HTTP collector, traces are posted to /v1/traces)
+		SampleRatio float64 `json:"sample_ratio" mapstructure:"sample_ratio"` // 1 (fraction of alerts traced)
+		ServiceName string  `json:"service_name" mapstructure:"service_name"` // alert-system
+	}
+
+	// TwilioConfig is the configuration for the Twilio SMS notifications (disabled if the account SID is empty)
+	// Only the critical notifications are sent by default, and each number is capped per hour
+	TwilioConfig struct {
+		AccountSID  string   `json:"account_sid" mapstructure:"account_sid"`   // "" (ACxxx)
+		AuthToken   string   `json:"auth_token" mapstructure:"auth_token"`     // "" (required)
+		Events      []string `json:"events" mapstructure:"events"`             // [alert.enforced, node.healthy, node.unhealthy]
+		From        string   `json:"from" mapstructure:"from"`                 // "" (E.164 number or messaging service SID MGxxx, required)
+		MaxPerHour  int      `json:"max_per_hour" mapstructure:"max_per_hour"` // 5 (SMS per number, the next notifications are dropped)
+		MinSeverity string   `json:"min_severity" mapstructure:"min_severity"` // critical (info, warning or critical, resolutions are always sent)
+		To          []string `json:"to" mapstructure:"to"`                     // [] (E.164 numbers, required)
+		URL         string   `json:"url" mapstructure:"url"`                   // https://api.twilio.com
+	}
+
+	// WebhookConfig is the configuration for delivering events to registered webhooks
+	WebhookConfig struct {
+		MaxAge        time.Duration `json:"max_age" mapstructure:"max_age"`               // 1h (no retry once the delivery is older)
+		MaxRetries    int           `json:"max_retries" mapstructure:"max_retries"`       // 5
+		QueueSize     int           `json:"queue_size" mapstructure:"queue_size"`         // 100
+		RetryInterval time.Duration `json:"retry_interval" mapstructure:"retry_interval"` // 10s (doubles each attempt)
+		Workers       int           `json:"workers" mapstructure:"workers"`               // 2
 	}
 
 	// WebServerConfig is a configuration for the web HTTP Server
 	WebServerConfig struct {
-		IdleTimeout  time.Duration `json:"idle_timeout" mapstructure:"idle_timeout"`   // 60s
-		Port         string        `json:"port" mapstructure:"port"`                   // 3000
-		ReadTimeout  time.Duration `json:"read_timeout" mapstructure:"read_timeout"`   // 15s
-		WriteTimeout time.Duration `json:"write_timeout" mapstructure:"write_timeout"` // 15s
+		AdminAllowlist     []string       `json:"admin_allowlist" mapstructure:"admin_allowlist"`         // [] (client IPs/CIDRs allowed to use the admin routes, all if empty)
+		AdminSocket        string         `json:"admin_socket" mapstructure:"admin_socket"`               // "" (Unix socket serving the API, admin routes without a token, disabled if empty)
+		AdminToken         string         `json:"admin_token" mapstructure:"admin_token"`                 // Bearer token for the admin API (admin routes are disabled if empty)
+		APIAllowlist       []string       `json:"api_allowlist" mapstructure:"api_allowlist"`             // [] (client IPs/CIDRs allowed to use the public routes, all if empty)
+		AutoCert           AutoCertConfig `json:"auto_cert" mapstructure:"auto_cert"`                     // Automatic TLS via ACME/Let's Encrypt
+		CompressMinBytes   int            `json:"compress_min_bytes" mapstructure:"compress_min_bytes"`   // 1024 (smaller responses are not compressed)
+		DisableCompression bool           `json:"disable_compression" mapstructure:"disable_compression"` // false (gzip responses if the client accepts it)
+		EnableDebug        bool           `json:"enable_debug" mapstructure:"enable_debug"`               // false (mount pprof, expvar and goroutine dump endpoints, admin token required)
+		EnableMetrics      bool           `json:"enable_metrics" mapstructure:"enable_metrics"`           // false (serve the Prometheus metrics on /metrics, api_allowlist applies)
+		EnableRPC          bool           `json:"enable_rpc" mapstructure:"enable_rpc"`                   // false (serve the JSON-RPC 2.0 service on POST / and /v1/rpc, api_allowlist applies)
+		HTTP2              HTTP2Config    `json:"http2" mapstructure:"http2"`                             // HTTP/2 and h2c
+		IdleTimeout        time.Duration  `json:"idle_timeout" mapstructure:"idle_timeout"`               // 60s
+		LegacySunset       string         `json:"legacy_sunset" mapstructure:"legacy_sunset"`             // "" (YYYY-MM-DD date the unversioned routes will be removed, sent in the Sunset header)
+		MaxBodyBytes       int64          `json:"max_body_bytes" mapstructure:"max_body_bytes"`           // 1048576 (1MB)
+		MaxHeaderBytes     int            `json:"max_header_bytes" mapstructure:"max_header_bytes"`       // 65536 (64KB)
+		Port               string         `json:"port" mapstructure:"port"`                               // 3000
+		PublicURL          string         `json:"public_url" mapstructure:"public_url"`                   // "" (external URL of the server for the feed links, the request host if empty)
+		ReadHeaderTimeout  time.Duration  `json:"read_header_timeout" mapstructure:"read_header_timeout"` // 5s
+		ReadTimeout        time.Duration  `json:"read_timeout" mapstructure:"read_timeout"`               // 15s
+		ReusePort          bool           `json:"reuse_port" mapstructure:"reuse_port"`                   // false (set SO_REUSEPORT, another process can listen on the port, ignored on Windows)
+		ShutdownTimeout    time.Duration  `json:"shutdown_timeout" mapstructure:"shutdown_timeout"`       // 5s (grace period for draining in-flight requests)
+		TrustedProxies     []string       `json:"trusted_proxies" mapstructure:"trusted_proxies"`         // [] (proxy IPs/CIDRs trusted to set X-Forwarded-For)
+		WriteTimeout       time.Duration  `json:"write_timeout" mapstructure:"write_timeout"`             // 15s
 	}
 )
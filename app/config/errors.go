@@ -6,14 +6,31 @@ import (
 
 // Configuration errors
 var (
-	ErrDatastoreRequired    = errors.New("datastore is required and was not loaded")
-	ErrDatastoreUnsupported = errors.New("unsupported datastore engine")
-	ErrInvalidEnvironment   = errors.New("invalid environment")
-	ErrNoP2PIP              = errors.New("no p2p_ip defined")
-	ErrNoP2PPort            = errors.New("no p2p_port defined")
-	ErrNoRPCHost            = errors.New("no rpc_host defined")
-	ErrNoRPCPassword        = errors.New("no rpc_password defined")
-	ErrNoRPCUser            = errors.New("no rpc_user defined")
-	ErrNoRPCConnections     = errors.New("no rpc connections configured")
-	ErrNoGenesisKeys        = errors.New("no genesis keys configured")
+	ErrAutoCertNoDomains     = errors.New("auto_cert is enabled but no domains are configured")
+	ErrChaosOnMainnet        = errors.New("chaos fault injection cannot be enabled on mainnet")
+	ErrDatastoreRequired     = errors.New("datastore is required and was not loaded")
+	ErrDatastoreUnsupported  = errors.New("unsupported datastore engine")
+	ErrEventLogUnsupported   = errors.New("log_output eventlog is only supported on Windows")
+	ErrInvalidAllowlist      = errors.New("allowlists and trusted_proxies must be IP addresses or CIDR ranges")
+	ErrInvalidAuditOutput    = errors.New("audit output must be file or datastore")
+	ErrInvalidChaos          = errors.New("chaos gossip_drop_percent must be between 0 and 100, rpc_fail_every and sync_delay positive")
+	ErrInvalidClusterLease   = errors.New("cluster renew_interval must be shorter than the lease_duration")
+	ErrInvalidEnvironment    = errors.New("invalid environment")
+	ErrInvalidLogLevel       = errors.New("log_level and log_levels must be debug, info, warn or error")
+	ErrInvalidLogFormat      = errors.New("log_format must be text or json")
+	ErrInvalidLogOutput      = errors.New("log_output must be stdout, file, syslog, journald or eventlog")
+	ErrInvalidLegacySunset   = errors.New("legacy_sunset must be a YYYY-MM-DD date")
+	ErrInvalidNodeMockMethod = errors.New("node_mock methods must be node methods (e.g. invalidate_block)")
+	ErrInvalidSampleRatio    = errors.New("tracing sample_ratio must be between 0 and 1")
+	ErrInvalidLogFacility    = errors.New("log_syslog facility must be user, daemon or local0-local7")
+	ErrInvalidSyslogNetwork  = errors.New("log_syslog network must be udp or tcp (with an address) or unix")
+	ErrNoP2PIP               = errors.New("no p2p_ip defined")
+	ErrNoP2PPort             = errors.New("no p2p_port defined")
+	ErrNoRPCHost             = errors.New("no rpc_host defined")
+	ErrNoRPCPassword         = errors.New("no rpc_password defined")
+	ErrNoRPCUser             = errors.New("no rpc_user defined")
+	ErrNoRPCConnections      = errors.New("no rpc connections configured")
+	ErrNoGenesisKeys         = errors.New("no genesis keys configured")
+	ErrNoHealthyNode         = errors.New("no node of the rpc connections is reachable")
+	ErrUnsupportedConfigType = errors.New("config type must be json, yaml, toml, hcl, ini, env or properties")
 )
@@ -6,14 +6,28 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
-	"log"
 	"net"
 	"net/http"
+	"net/netip"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
-
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/budget"
+	"github.com/bitcoin-sv/alert-system/app/buildinfo"
+	"github.com/bitcoin-sv/alert-system/app/chaos"
+	"github.com/bitcoin-sv/alert-system/app/clock"
+	"github.com/bitcoin-sv/alert-system/app/config/mocks"
+	"github.com/bitcoin-sv/alert-system/app/devnet"
+	"github.com/bitcoin-sv/alert-system/app/metrics"
+	"github.com/bitcoin-sv/alert-system/app/reporting"
+	"github.com/bitcoin-sv/alert-system/app/sigcache"
+	"github.com/mitchellh/mapstructure"
 	"github.com/mrz1836/go-datastore"
 	"github.com/spf13/viper"
 )
@@ -32,10 +46,21 @@ func isValidEnvironment(testEnv string) bool {
 	return false
 }
 
+// EnvironmentFile will return the embedded configuration file of the environment (its defaults, e.g. the
+// genesis keys and P2P topic of the network)
+func EnvironmentFile(environment string) ([]byte, error) {
+	if !isValidEnvironment(environment) {
+		return nil, ErrInvalidEnvironment
+	}
+	return envDir.ReadFile("envs/" + strings.ToLower(environment) + ".json")
+}
+
 // LoadDependencies will load the configuration and services
 // models is a list of models to auto-migrate when the datastore is created
-// if testing is true, the node will be mocked
-func LoadDependencies(ctx context.Context, models []interface{}, isTesting bool) (_appConfig *Config, err error) {
+// if testing is true (or node_mock is enabled), the node will be mocked
+// opts are the services used instead of the ones built from the config (see WithNode, WithLogger...)
+func LoadDependencies(ctx context.Context, models []interface{}, isTesting bool,
+	opts ...ServiceOptions) (_appConfig *Config, err error) {
 
 	// Load the config file
 	_appConfig, err = LoadConfigFile()
@@ -43,50 +68,188 @@ func LoadDependencies(ctx context.Context, models []interface{}, isTesting bool)
 		return nil, err
 	}
 
-	// Require at least one RPC connection
-	if len(_appConfig.RPCConnections) == 0 {
-		return nil, ErrNoRPCConnections
+	// Check the required settings (the RPC connections are not used with an injected node)
+	if err = _appConfig.validate(newServices(opts).Node == nil); err != nil {
+		return nil, err
+	}
+
+	// Load the services
+	if err = _appConfig.LoadServices(ctx, models, isTesting, opts...); err != nil {
+		return nil, err
 	}
+	return _appConfig, nil
+}
+
+// LoadServices will load the node, datastore and other services of a valid config (see ValidateConfigFile and New)
+// models is a list of models to auto-migrate when the datastore is created
+// if testing is true (or node_mock is enabled), the node will be mocked
+// opts are the services used instead of the ones built from the config (see WithNode, WithLogger...)
+func (c *Config) LoadServices(ctx context.Context, models []interface{}, isTesting bool,
+	opts ...ServiceOptions) (err error) {
+	injected := newServices(opts)
 
-	// Require list of genesis keys
-	if len(_appConfig.GenesisKeys) == 0 {
-		return nil, ErrNoGenesisKeys
+	// Use the injected logger (the log settings of the config are ignored)
+	if injected.Log != nil {
+		c.Services.Log = injected.Log
 	}
 
-	// Ensure the P2P configuration is valid
-	if err = requireP2P(_appConfig); err != nil {
-		return nil, err
+	// Generate the throwaway genesis keys of the devnet (the node is mocked)
+	if c.Devnet {
+		if c.Services.Devnet, err = devnet.NewSigner(); err != nil {
+			return err
+		}
+		c.GenesisKeys = c.Services.Devnet.PublicKeys
+		c.NodeMock.Enabled = true
 	}
 
 	// Set the node config (either a real node or a mock node)
-	if !isTesting {
-		// todo support multiple nodes (this is an example)
-		for i := range _appConfig.RPCConnections {
-			_appConfig.Services.Node = NewNodeConfig(
-				_appConfig.RPCConnections[i].User,
-				_appConfig.RPCConnections[i].Password,
-				_appConfig.RPCConnections[i].Host,
+	c.Services.Nodes = make([]NodeInterface, 0, len(c.RPCConnections))
+	for i := range c.RPCConnections {
+		if !isTesting && !c.NodeMock.Enabled {
+			c.Services.Node = NewNodeConfig(
+				c.RPCConnections[i].User,
+				c.RPCConnections[i].Password,
+				c.RPCConnections[i].Host,
 			)
-		}
-	} else {
-		for i := range _appConfig.RPCConnections {
-			_appConfig.Services.Node = NewNodeMock(
-				_appConfig.RPCConnections[i].User,
-				_appConfig.RPCConnections[i].Password,
-				_appConfig.RPCConnections[i].Host,
+		} else {
+			c.Services.Node = NewNodeMock(
+				c.RPCConnections[i].User,
+				c.RPCConnections[i].Password,
+				c.RPCConnections[i].Host,
 			)
+			c.NodeMock.script(c.Services.Node)
+		}
+		c.Services.Nodes = append(c.Services.Nodes, c.Services.Node)
+	}
+
+	// Use the injected node instead (the RPC connections are not used)
+	if injected.Node != nil {
+		c.Services.Node = injected.Node
+		c.Services.Nodes = []NodeInterface{injected.Node}
+	}
+
+	// Load an HTTP client (unless injected)
+	c.Services.HTTPClient = injected.HTTPClient
+	if c.Services.HTTPClient == nil {
+		c.Services.HTTPClient = http.DefaultClient
+	}
+
+	// Use the real clock (unless injected)
+	c.Services.Clock = injected.Clock
+	if c.Services.Clock == nil {
+		c.Services.Clock = clock.New()
+	}
+
+	// Cache the signature verification results (re-gossiped duplicates are not verified again)
+	if c.SignatureCacheSize == 0 {
+		c.SignatureCacheSize = DefaultSignatureCacheSize
+	}
+	c.Services.Signatures = sigcache.New(c.SignatureCacheSize)
+
+	// Cap the concurrent handlers (protects a node running on the same host)
+	if c.Budget.MaxAPIHandlers == 0 {
+		c.Budget.MaxAPIHandlers = DefaultBudgetMaxAPIHandlers
+	}
+	if c.Budget.MaxStreamHandlers == 0 {
+		c.Budget.MaxStreamHandlers = DefaultBudgetMaxStreamHandlers
+	}
+	c.Services.APIHandlers = budget.NewLimiter(budget.ResourceAPIHandlers, c.Budget.MaxAPIHandlers)
+	c.Services.StreamHandlers = budget.NewLimiter(budget.ResourceStreamHandlers, c.Budget.MaxStreamHandlers)
+
+	// Report the panics and error logs (if a DSN is set)
+	if len(c.Reporting.DSN) > 0 {
+		var reporter *reporting.Sentry
+		if reporter, err = reporting.NewSentry(reporting.SentryOptions{
+			DSN:         c.Reporting.DSN,
+			Environment: c.Reporting.Environment,
+			Release:     buildinfo.Get().Version,
+		}); err != nil {
+			return err
+		}
+		c.Services.Reporter = reporter
+		c.Services.Log = newReportingLogger(c.Services.Log, reporter)
+	}
+
+	// Drop the repeated log messages (summarized once the window ends)
+	if c.LogDedup.Window == 0 {
+		c.LogDedup.Window = DefaultLogDedupWindow
+	}
+	if c.LogDedup.Burst <= 0 {
+		c.LogDedup.Burst = DefaultLogDedupBurst
+	}
+	c.Services.Log = newDedupLogger(c.Services.Log, c.LogDedup)
+
+	// Log the slow node RPC calls (if a threshold is set)
+	for _, node := range c.Services.Nodes {
+		if n, ok := node.(*Node); ok {
+			n.logger = c.Services.Log
+			n.slowRPC = c.SlowLog.RPC
 		}
 	}
 
-	// Load an HTTP client
-	_appConfig.Services.HTTPClient = http.DefaultClient
+	// Inject the faults (the node RPC calls fail through the wrapped nodes)
+	if c.Chaos.Enabled {
+		c.Services.Chaos = chaos.New(chaos.Options{
+			Clock:             c.Services.Clock,
+			GossipDropPercent: c.Chaos.GossipDropPercent,
+			RPCFailEvery:      c.Chaos.RPCFailEvery,
+			Seed:              c.Chaos.Seed,
+			SyncDelay:         c.Chaos.SyncDelay,
+		})
+		for i, node := range c.Services.Nodes {
+			c.Services.Nodes[i] = newChaosNode(node, c.Services.Chaos)
+		}
+		if len(c.Services.Nodes) > 0 {
+			c.Services.Node = c.Services.Nodes[len(c.Services.Nodes)-1]
+		}
+	}
+
+	// Fail over between the nodes (the alerts are executed against all the nodes)
+	if len(c.Services.Nodes) > 1 {
+		c.Services.Node = NewNodePool(c.Services.Nodes, c.NodePool.RetryInterval, c.Services.Clock, c.Services.Log)
+	}
+
+	// Load the datastore service (unless injected)
+	if injected.Datastore != nil {
+		c.Services.Datastore = injected.Datastore
+		return nil
+	}
+	return c.loadDatastore(ctx, models)
+}
+
+// ValidateConfigFile will load the config file and check the settings required to run the alert system
+// (the node, datastore and other services are not loaded)
+func ValidateConfigFile() (_appConfig *Config, err error) {
 
-	// Load the datastore service
-	if err = _appConfig.loadDatastore(ctx, models); err != nil {
+	// Load the config file
+	_appConfig, err = LoadConfigFile()
+	if err != nil {
 		return nil, err
 	}
 
-	return
+	// Check the required settings
+	if err = _appConfig.validate(true); err != nil {
+		return nil, err
+	}
+	return _appConfig, nil
+}
+
+// validate will check the settings required to run the alert system
+// requireRPC is false if the node is injected (see WithNode)
+func (c *Config) validate(requireRPC bool) error {
+
+	// Require at least one RPC connection
+	if requireRPC && len(c.RPCConnections) == 0 {
+		return ErrNoRPCConnections
+	}
+
+	// Require list of genesis keys (generated at startup on the devnet)
+	if len(c.GenesisKeys) == 0 && !c.Devnet {
+		return ErrNoGenesisKeys
+	}
+
+	// Ensure the P2P configuration is valid
+	return requireP2P(c)
 }
 
 // requireP2P will ensure the P2P configuration is valid
@@ -110,6 +273,11 @@ func requireP2P(_appConfig *Config) error {
 		}
 	}
 
+	// Lock the identity (one instance per private key)
+	if len(_appConfig.Instance.LockFile) == 0 {
+		_appConfig.Instance.LockFile = _appConfig.P2P.PrivateKeyPath + ".lock"
+	}
+
 	// Load bitcoin configuration if specified
 	if len(_appConfig.BitcoinConfigPath) > 0 {
 		if err := _appConfig.loadBitcoinConfiguration(); err != nil {
@@ -122,6 +290,16 @@ func requireP2P(_appConfig *Config) error {
 		_appConfig.P2P.PeerDiscoveryInterval = DefaultPeerDiscoveryInterval
 	}
 
+	// Load the size of the alert queue
+	if _appConfig.P2P.AlertQueueSize <= 0 {
+		_appConfig.P2P.AlertQueueSize = DefaultAlertQueueSize
+	}
+
+	// Load the number of the alerts synced first at startup
+	if _appConfig.P2P.FastSyncAlerts == 0 {
+		_appConfig.P2P.FastSyncAlerts = DefaultFastSyncAlerts
+	}
+
 	// Load the p2p ip (local, ip address or domain name)
 	// todo better validation of what is a valid IP, domain name or local address
 	if len(_appConfig.P2P.IP) < 5 {
@@ -140,17 +318,7 @@ func requireP2P(_appConfig *Config) error {
 func LoadConfigFile() (_appConfig *Config, err error) {
 
 	// Start the configuration struct
-	_appConfig = &Config{
-		Datastore: DatastoreConfig{
-			SQLite:   &datastore.SQLiteConfig{},
-			SQLRead:  &datastore.SQLConfig{},
-			SQLWrite: &datastore.SQLConfig{},
-		},
-		P2P:            P2PConfig{},
-		Services:       Services{},
-		WebServer:      WebServerConfig{},
-		RPCConnections: make([]RPCConfig, 0),
-	}
+	_appConfig = newConfig()
 
 	// Check the environment we are running
 	environment := os.Getenv(EnvironmentKey)
@@ -181,14 +349,18 @@ func LoadConfigFile() (_appConfig *Config, err error) {
 		return nil, err
 	}
 
-	// Set the configuration type
-	viper.SetConfigType("json")
-
 	// Do we have a custom config file? (use this instead of the environment file)
 	customConfigFileWithPath := os.Getenv(EnvironmentCustomFilePath)
 	if len(customConfigFileWithPath) > 0 {
 		var b []byte
 
+		// Set the configuration type (ALERT_SYSTEM_CONFIG_TYPE or the file extension)
+		var configType string
+		if configType, err = configFileType(customConfigFileWithPath); err != nil {
+			return nil, err
+		}
+		viper.SetConfigType(configType)
+
 		// Read the file
 		if b, err = os.ReadFile(customConfigFileWithPath); err != nil { //nolint:gosec // This is a custom file path
 			return nil, err
@@ -199,6 +371,9 @@ func LoadConfigFile() (_appConfig *Config, err error) {
 			return nil, err
 		}
 	} else {
+		// The embedded environment files are JSON
+		viper.SetConfigType(ConfigTypeJSON)
+
 		// Loop through the various environment files
 		for _, file := range files {
 			if file.Name() == environment+".json" {
@@ -213,36 +388,281 @@ func LoadConfigFile() (_appConfig *Config, err error) {
 		}
 	}
 
-	// Unmarshal into values struct
-	if err = viper.Unmarshal(&_appConfig); err != nil {
+	// Unmarshal into values struct (with the default hooks of viper, and the HCL blocks as maps)
+	if err = viper.Unmarshal(&_appConfig, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+		hclBlockHookFunc,
+	))); err != nil {
 		err = fmt.Errorf("error loading viper values: %w", err)
 		return nil, err
 	}
+	_appConfig.Environment = environment
 
-	// Load the logger service (ExtendedLogger meets the LoggerInterface)
-	writer := os.Stdout
-	if _appConfig.LogOutputFile != "" {
-		writer, err = os.OpenFile(_appConfig.LogOutputFile, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
-		if err != nil {
-			return nil, err
+	// Set the defaults and check the settings
+	if err = _appConfig.prepare(); err != nil {
+		return nil, err
+	}
+
+	// Log the configuration that was detected and where it was loaded from
+	_appConfig.Services.Log.Debug("loaded configuration from: " + viper.ConfigFileUsed())
+
+	return
+}
+
+// configFileType will return the type of the custom config file, from ALERT_SYSTEM_CONFIG_TYPE or the extension
+// of the file (json, yaml, toml, hcl...), JSON if the file has no extension
+func configFileType(path string) (string, error) {
+	configType := os.Getenv(EnvironmentCustomFileType)
+	if len(configType) == 0 {
+		configType = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	configType = strings.ToLower(configType)
+	if len(configType) == 0 {
+		return ConfigTypeJSON, nil
+	}
+	for _, supported := range viper.SupportedExts {
+		if configType == supported {
+			return configType, nil
 		}
 	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedConfigType, configType)
+}
 
-	logger := log.New(writer, "bitcoin-alert-system: ", log.LstdFlags)
-	_appConfig.Services.Log = &ExtendedLogger{
-		Logger: logger,
-		writer: writer,
+// hclBlockHookFunc will decode an HCL block (read by viper as a list with one map) into a struct or a map
+func hclBlockHookFunc(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.Slice || (to.Kind() != reflect.Struct && to.Kind() != reflect.Map) {
+		return data, nil
+	}
+	if blocks, ok := data.([]map[string]interface{}); ok && len(blocks) == 1 {
+		return blocks[0], nil
+	}
+	return data, nil
+}
+
+// newConfig will return the configuration struct with its nested settings allocated
+func newConfig() *Config {
+	return &Config{
+		Datastore: DatastoreConfig{
+			SQLite:   &datastore.SQLiteConfig{},
+			SQLRead:  &datastore.SQLConfig{},
+			SQLWrite: &datastore.SQLConfig{},
+		},
+		P2P:            P2PConfig{},
+		Services:       Services{},
+		WebServer:      WebServerConfig{},
+		RPCConnections: make([]RPCConfig, 0),
+	}
+}
+
+// prepare will load the logger, set the defaults and check the settings of a loaded (or built) config
+func (c *Config) prepare() (err error) {
+	environment := c.Environment
+
+	// Load the logger service (ExtendedLogger and JSONLogger meet the LoggerInterface)
+	// Set the log output (the log output file is rotated when it reaches the max size)
+	var writer io.WriteCloser
+	if writer, err = c.logWriter(); err != nil {
+		return err
+	}
+
+	// Set the log level (and per-module overrides)
+	if len(c.LogLevel) == 0 {
+		c.LogLevel = DefaultLogLevel
+	}
+	var level int
+	if level, err = ParseLogLevel(c.LogLevel); err != nil {
+		return err
+	}
+	var moduleLevels map[string]int // The datastore override toggles the SQL debug logs
+	if moduleLevels, err = parseModuleLogLevels(c.LogLevels); err != nil {
+		return err
+	}
+
+	if datastoreLevel, ok := moduleLevels[LogModuleDatastore]; ok {
+		c.Datastore.Debug = datastoreLevel == LogLevelDebug
+	}
+
+	switch c.LogFormat {
+	case LogFormatJSON:
+		c.Services.Log = NewJSONLogger(writer, level, moduleLevels)
+	case "", LogFormatText:
+		c.LogFormat = LogFormatText
+		c.Services.Log = NewExtendedLogger(writer, level, moduleLevels)
+	default:
+		return ErrInvalidLogFormat
 	}
 
 	// Set default alert processing interval if it doesn't exist
-	if _appConfig.AlertProcessingInterval <= 0 {
-		_appConfig.AlertProcessingInterval = DefaultAlertProcessingInterval
+	if c.AlertProcessingInterval <= 0 {
+		c.AlertProcessingInterval = DefaultAlertProcessingInterval
 	}
 
-	// Log the configuration that was detected and where it was loaded from
-	_appConfig.Services.Log.Debug("loaded configuration from: " + viper.ConfigFileUsed())
+	// Set the outbox replay defaults if they don't exist
+	if c.Outbox.BatchSize <= 0 {
+		c.Outbox.BatchSize = DefaultOutboxBatchSize
+	}
+	if c.Outbox.Interval <= 0 {
+		c.Outbox.Interval = DefaultOutboxInterval
+	}
+	if c.Outbox.MaxAttempts <= 0 {
+		c.Outbox.MaxAttempts = DefaultOutboxMaxAttempts
+	}
+	if c.Outbox.MinAge <= 0 {
+		c.Outbox.MinAge = DefaultOutboxMinAge
+	}
 
-	return
+	// Set the node failover retry interval if it doesn't exist
+	if c.NodePool.RetryInterval <= 0 {
+		c.NodePool.RetryInterval = DefaultNodeRetryInterval
+	}
+
+	// Set default heartbeat interval if it doesn't exist
+	if c.Heartbeat.Interval <= 0 {
+		c.Heartbeat.Interval = DefaultHeartbeatInterval
+	}
+
+	// Set the diagnostic bundle defaults if they don't exist
+	if len(c.Diagnostics.Dir) == 0 {
+		c.Diagnostics.Dir = DefaultDiagnosticsDir
+	}
+	if c.Diagnostics.MaxBundles == 0 {
+		c.Diagnostics.MaxBundles = DefaultDiagnosticsMaxBundles
+	}
+
+	// Set default StatsD push interval if it doesn't exist
+	if len(c.StatsD.Address) > 0 && c.StatsD.Interval <= 0 {
+		c.StatsD.Interval = metrics.DefaultStatsDInterval
+	}
+
+	// Set the web server timeouts and limits (safe defaults if they don't exist)
+	c.WebServer.setDefaults()
+
+	// Set the shutdown stage deadlines if they don't exist
+	c.Shutdown.setDefaults()
+
+	// Set the tracing defaults if enabled
+	if c.Tracing.Enabled {
+		if len(c.Tracing.Endpoint) == 0 {
+			c.Tracing.Endpoint = DefaultTracingEndpoint
+		}
+		if len(c.Tracing.ServiceName) == 0 {
+			c.Tracing.ServiceName = DefaultTracingServiceName
+		}
+		if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+			return ErrInvalidSampleRatio
+		} else if c.Tracing.SampleRatio == 0 {
+			c.Tracing.SampleRatio = 1
+		}
+	}
+
+	// Set the audit log defaults if enabled
+	if c.Audit.Enabled {
+		if len(c.Audit.Output) == 0 {
+			c.Audit.Output = AuditOutputFile
+		}
+		if c.Audit.Output != AuditOutputFile && c.Audit.Output != AuditOutputDatastore {
+			return ErrInvalidAuditOutput
+		}
+		if len(c.Audit.File) == 0 {
+			c.Audit.File = DefaultAuditFile
+		}
+	}
+
+	// Check the scripted methods of the node mock (local dev mode)
+	if c.NodeMock.Enabled {
+		for method := range c.NodeMock.Methods {
+			if !mocks.IsMethod(method) {
+				return fmt.Errorf("%w: %s", ErrInvalidNodeMockMethod, method)
+			}
+		}
+	}
+
+	// Check the fault injection (never on mainnet)
+	if c.Chaos.Enabled {
+		if err = c.Chaos.validate(environment); err != nil {
+			return err
+		}
+	}
+
+	// Set the profiling watchdog defaults if enabled
+	if c.Profiling.Enabled {
+		c.Profiling.setDefaults()
+	}
+
+	// Set the cluster defaults if enabled (the instance ID is also the holder of the alert locks)
+	if c.Cluster.Enabled || c.AlertLocks.Enabled {
+		if err = c.Cluster.setDefaults(); err != nil {
+			return err
+		}
+	}
+	if c.AlertLocks.Enabled && c.AlertLocks.TTL <= 0 {
+		c.AlertLocks.TTL = DefaultAlertLockTTL
+	}
+
+	// Tag the reported errors with the environment (if not set)
+	if len(c.Reporting.Environment) == 0 {
+		c.Reporting.Environment = environment
+	}
+
+	// Validate the IP allowlists and trusted proxies (if set)
+	for _, networks := range [][]string{
+		c.WebServer.AdminAllowlist, c.WebServer.APIAllowlist, c.WebServer.TrustedProxies,
+	} {
+		if err = validateNetworks(networks); err != nil {
+			return err
+		}
+	}
+
+	// Validate the legacy routes sunset date (if set)
+	if len(c.WebServer.LegacySunset) > 0 {
+		if _, err = time.Parse(LegacySunsetLayout, c.WebServer.LegacySunset); err != nil {
+			return ErrInvalidLegacySunset
+		}
+	}
+
+	// Set the auto cert defaults if enabled
+	if c.WebServer.AutoCert.Enabled {
+		if len(c.WebServer.AutoCert.Domains) == 0 {
+			return ErrAutoCertNoDomains
+		}
+		if len(c.WebServer.AutoCert.CacheDir) == 0 {
+			c.WebServer.AutoCert.CacheDir = DefaultAutoCertCacheDir
+		}
+		if len(c.WebServer.AutoCert.HTTPPort) == 0 {
+			c.WebServer.AutoCert.HTTPPort = DefaultAutoCertHTTPPort
+		}
+	}
+
+	// Set the webhook delivery defaults if they don't exist
+	if c.Webhooks.MaxAge <= 0 {
+		c.Webhooks.MaxAge = DefaultWebhookMaxAge
+	}
+	if c.Webhooks.MaxRetries <= 0 {
+		c.Webhooks.MaxRetries = DefaultWebhookMaxRetries
+	}
+	if c.Webhooks.QueueSize <= 0 {
+		c.Webhooks.QueueSize = DefaultWebhookQueueSize
+	}
+	if c.Webhooks.RetryInterval <= 0 {
+		c.Webhooks.RetryInterval = DefaultWebhookRetryInterval
+	}
+	if c.Webhooks.Workers <= 0 {
+		c.Webhooks.Workers = DefaultWebhookWorkers
+	}
+
+	// Set the notification delivery defaults if they don't exist
+	if c.Notifications.MaxRetries <= 0 {
+		c.Notifications.MaxRetries = DefaultNotificationMaxRetries
+	}
+	if c.Notifications.QueueSize <= 0 {
+		c.Notifications.QueueSize = DefaultNotificationQueueSize
+	}
+	if c.Notifications.RetryInterval <= 0 {
+		c.Notifications.RetryInterval = DefaultNotificationRetryInterval
+	}
+
+	return nil
 }
 
 // createPrivateKeyDirectory will create the private key directory
@@ -341,3 +761,165 @@ func (c *Config) CloseAll(ctx context.Context) {
 		c.Services.Datastore = nil
 	}
 }
+
+// logWriter will open the log output (stdout, the rotated log file, syslog or journald)
+// The output defaults to the file if a log output file is set (stdout otherwise)
+func (c *Config) logWriter() (io.WriteCloser, error) {
+	if len(c.LogOutput) == 0 {
+		c.LogOutput = LogOutputStdout
+		if len(c.LogOutputFile) > 0 {
+			c.LogOutput = LogOutputFile
+		}
+	}
+	if len(c.LogSyslog.Tag) == 0 {
+		c.LogSyslog.Tag = DefaultSyslogTag
+	}
+
+	switch c.LogOutput {
+	case LogOutputStdout:
+		return os.Stdout, nil
+	case LogOutputFile:
+		if len(c.LogOutputFile) == 0 {
+			return nil, ErrInvalidLogOutput
+		}
+		if c.LogRotation.MaxSizeMB <= 0 {
+			c.LogRotation.MaxSizeMB = DefaultLogMaxSizeMB
+		}
+		return newRotatingFile(c.LogOutputFile, c.LogRotation)
+	case LogOutputSyslog:
+		if len(c.LogSyslog.Facility) == 0 {
+			c.LogSyslog.Facility = DefaultSyslogFacility
+		}
+		switch c.LogSyslog.Network {
+		case "":
+			c.LogSyslog.Network = SyslogNetworkUnix
+			if len(c.LogSyslog.Address) > 0 {
+				c.LogSyslog.Network = SyslogNetworkUDP
+			}
+		case SyslogNetworkTCP, SyslogNetworkUDP:
+			if len(c.LogSyslog.Address) == 0 {
+				return nil, ErrInvalidSyslogNetwork
+			}
+		case SyslogNetworkUnix:
+		default:
+			return nil, ErrInvalidSyslogNetwork
+		}
+		return newSyslogWriter(c.LogSyslog)
+	case LogOutputJournald:
+		return newJournaldWriter(c.LogSyslog.Tag)
+	case LogOutputEventLog:
+		return newEventLogWriter(c.LogSyslog.Tag)
+	default:
+		return nil, ErrInvalidLogOutput
+	}
+}
+
+// setDefaults will set safe defaults for any web server timeouts and limits that are not set
+func (w *WebServerConfig) setDefaults() {
+	if w.CompressMinBytes <= 0 {
+		w.CompressMinBytes = DefaultServerCompressMinBytes
+	}
+	if w.HTTP2.MaxStreams == 0 {
+		w.HTTP2.MaxStreams = DefaultServerHTTP2MaxStreams
+	}
+	if w.HTTP2.StreamBuffer <= 0 {
+		w.HTTP2.StreamBuffer = DefaultServerHTTP2StreamBuffer
+	}
+	if w.IdleTimeout <= 0 {
+		w.IdleTimeout = DefaultServerIdleTimeout
+	}
+	if w.MaxBodyBytes <= 0 {
+		w.MaxBodyBytes = DefaultServerMaxBodyBytes
+	}
+	if w.MaxHeaderBytes <= 0 {
+		w.MaxHeaderBytes = DefaultServerMaxHeaderBytes
+	}
+	if w.ReadHeaderTimeout <= 0 {
+		w.ReadHeaderTimeout = DefaultServerReadHeaderTimeout
+	}
+	if w.ReadTimeout <= 0 {
+		w.ReadTimeout = DefaultServerReadTimeout
+	}
+	if w.ShutdownTimeout <= 0 {
+		w.ShutdownTimeout = DefaultServerShutdown
+	}
+	if w.WriteTimeout <= 0 {
+		w.WriteTimeout = DefaultServerWriteTimeout
+	}
+}
+
+// setDefaults will set the deadlines of the shutdown stages that are not set
+func (s *ShutdownConfig) setDefaults() {
+	if s.Alerts <= 0 {
+		s.Alerts = DefaultShutdownAlerts
+	}
+	if s.Datastore <= 0 {
+		s.Datastore = DefaultShutdownDatastore
+	}
+	if s.Notifications <= 0 {
+		s.Notifications = DefaultShutdownNotifications
+	}
+	if s.P2P <= 0 {
+		s.P2P = DefaultShutdownP2P
+	}
+	if s.Restart <= 0 {
+		s.Restart = DefaultShutdownRestart
+	}
+}
+
+// setDefaults will set the profiling watchdog settings that are not set (the interval and the CPU profile duration
+// default in the profiling package)
+func (p *ProfilingConfig) setDefaults() {
+	if p.CPUPercent == 0 {
+		p.CPUPercent = DefaultProfilingCPUPercent
+	}
+	if len(p.Dir) == 0 {
+		p.Dir = DefaultProfilingDir
+	}
+	if p.MaxProfiles <= 0 {
+		p.MaxProfiles = DefaultProfilingMaxProfiles
+	}
+	if p.MemoryMB == 0 {
+		p.MemoryMB = DefaultProfilingMemoryMB
+	}
+	if p.Sustained <= 0 {
+		p.Sustained = DefaultProfilingSustained
+	}
+}
+
+// setDefaults will set the cluster settings that are not set (the lease must be renewed before it expires)
+func (c *ClusterConfig) setDefaults() error {
+	if len(c.InstanceID) == 0 {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return err
+		}
+		c.InstanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	if c.LeaseDuration <= 0 {
+		c.LeaseDuration = DefaultClusterLeaseDuration
+	}
+	if len(c.Name) == 0 {
+		c.Name = DefaultClusterName
+	}
+	if c.RenewInterval <= 0 {
+		c.RenewInterval = DefaultClusterRenewInterval
+	}
+	if c.RenewInterval >= c.LeaseDuration {
+		return ErrInvalidClusterLease
+	}
+	return nil
+}
+
+// validateNetworks will validate that each entry is an IP address or a CIDR range
+func validateNetworks(networks []string) error {
+	for _, network := range networks {
+		if _, err := netip.ParsePrefix(network); err == nil {
+			continue
+		}
+		if _, err := netip.ParseAddr(network); err != nil {
+			return ErrInvalidAllowlist
+		}
+	}
+	return nil
+}
@@ -2,10 +2,13 @@ package config
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/bitcoin-sv/alert-system/app/config/mocks"
 	"github.com/mrz1836/go-datastore"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -37,6 +40,27 @@ func TestLoadConfig_Success(t *testing.T) {
 	})
 }
 
+// TestLoadConfig_Devnet tests the devnet generates its genesis keys and mocks the node
+func TestLoadConfig_Devnet(t *testing.T) {
+	err := os.Setenv(EnvironmentKey, EnvironmentDevnet)
+	require.NoError(t, err)
+	defer func() {
+		_ = os.Setenv(EnvironmentKey, EnvironmentTest)
+	}()
+
+	var c *Config
+	c, err = LoadDependencies(context.Background(), nil, false)
+	require.NoError(t, err)
+	defer c.CloseAll(context.Background())
+
+	require.NotNil(t, c.Services.Devnet)
+	assert.True(t, c.Devnet)
+	assert.Len(t, c.GenesisKeys, 5)
+	assert.Equal(t, c.Services.Devnet.PublicKeys, c.GenesisKeys)
+	assert.IsType(t, &mocks.Node{}, c.Services.Node)
+	assert.Equal(t, "alert_system_devnet", c.P2P.TopicName)
+}
+
 // TestLoadConfigFile tests the method LoadConfigFile()
 func TestLoadConfigFile(t *testing.T) {
 
@@ -218,5 +242,250 @@ func TestIsValidEnvironment(t *testing.T) {
 
 		valid = isValidEnvironment(EnvironmentProduction)
 		assert.True(t, valid)
+
+		valid = isValidEnvironment(EnvironmentDevnet)
+		assert.True(t, valid)
+
+		valid = isValidEnvironment(EnvironmentSimulation)
+		assert.True(t, valid)
+	})
+}
+
+// TestEnvironmentFile tests the method EnvironmentFile()
+func TestEnvironmentFile(t *testing.T) {
+	t.Run("valid env", func(t *testing.T) {
+		file, err := EnvironmentFile("MAINNET")
+		require.NoError(t, err)
+		assert.Contains(t, string(file), `"environment": "mainnet"`)
+	})
+
+	t.Run("unknown env", func(t *testing.T) {
+		_, err := EnvironmentFile("unknown")
+		require.ErrorIs(t, err, ErrInvalidEnvironment)
+	})
+}
+
+// TestConfigFileType tests the method configFileType()
+func TestConfigFileType(t *testing.T) {
+	t.Run("from the extension", func(t *testing.T) {
+		for path, expected := range map[string]string{
+			"config.json": ConfigTypeJSON,
+			"config.YAML": ConfigTypeYAML,
+			"config.yml":  "yml",
+			"config.toml": ConfigTypeTOML,
+			"config.hcl":  ConfigTypeHCL,
+			"config":      ConfigTypeJSON,
+		} {
+			configType, err := configFileType(path)
+			require.NoError(t, err, path)
+			assert.Equal(t, expected, configType, path)
+		}
+	})
+
+	t.Run("from the environment variable", func(t *testing.T) {
+		t.Setenv(EnvironmentCustomFileType, "YAML")
+		configType, err := configFileType("/etc/alert-system/config.conf")
+		require.NoError(t, err)
+		assert.Equal(t, ConfigTypeYAML, configType)
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		_, err := configFileType("config.xml")
+		require.ErrorIs(t, err, ErrUnsupportedConfigType)
+
+		t.Setenv(EnvironmentCustomFileType, "xml")
+		_, err = configFileType("config.json")
+		require.ErrorIs(t, err, ErrUnsupportedConfigType)
+	})
+}
+
+// TestLoadConfigFile_Types tests loading the custom config files of each type
+func TestLoadConfigFile_Types(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": `
+genesis_keys:
+  - key-1
+  - key-2
+log_level: warn
+p2p:
+  port: "9906"
+rpc_connections:
+  - host: http://node1:8332
+    user: user
+  - host: http://node2:8332
+    user: user
+web_server:
+  read_timeout: 20s
+`,
+		"config.toml": `
+genesis_keys = ["key-1", "key-2"]
+log_level = "warn"
+
+[p2p]
+port = "9906"
+
+[[rpc_connections]]
+host = "http://node1:8332"
+user = "user"
+
+[[rpc_connections]]
+host = "http://node2:8332"
+user = "user"
+
+[web_server]
+read_timeout = "20s"
+`,
+		"config.hcl": `
+genesis_keys = ["key-1", "key-2"]
+log_level = "warn"
+p2p {
+  port = "9906"
+}
+rpc_connections {
+  host = "http://node1:8332"
+  user = "user"
+}
+rpc_connections {
+  host = "http://node2:8332"
+  user = "user"
+}
+web_server {
+  read_timeout = "20s"
+}
+`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+			t.Setenv(EnvironmentKey, EnvironmentTest)
+			t.Setenv(EnvironmentCustomFilePath, path)
+
+			c, err := LoadConfigFile()
+			require.NoError(t, err)
+			assert.Equal(t, []string{"key-1", "key-2"}, c.GenesisKeys)
+			assert.Equal(t, "warn", c.LogLevel)
+			assert.Equal(t, "9906", c.P2P.Port)
+			require.Len(t, c.RPCConnections, 2)
+			assert.Equal(t, "http://node2:8332", c.RPCConnections[1].Host)
+			assert.Equal(t, 20*time.Second, c.WebServer.ReadTimeout)
+		})
+	}
+
+	t.Run("type from the environment variable", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "alert-system.conf")
+		require.NoError(t, os.WriteFile(path, []byte(files["config.yaml"]), 0o600))
+		t.Setenv(EnvironmentKey, EnvironmentTest)
+		t.Setenv(EnvironmentCustomFilePath, path)
+		t.Setenv(EnvironmentCustomFileType, ConfigTypeYAML)
+
+		c, err := LoadConfigFile()
+		require.NoError(t, err)
+		assert.Equal(t, "9906", c.P2P.Port)
+	})
+
+	t.Run("embedded environment file with a config type", func(t *testing.T) {
+		t.Setenv(EnvironmentKey, EnvironmentTest)
+		t.Setenv(EnvironmentCustomFileType, ConfigTypeYAML)
+
+		c, err := LoadConfigFile()
+		require.NoError(t, err)
+		assert.NotEmpty(t, c.GenesisKeys)
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.xml")
+		require.NoError(t, os.WriteFile(path, []byte("<config/>"), 0o600))
+		t.Setenv(EnvironmentKey, EnvironmentTest)
+		t.Setenv(EnvironmentCustomFilePath, path)
+
+		_, err := LoadConfigFile()
+		require.ErrorIs(t, err, ErrUnsupportedConfigType)
+	})
+}
+
+// TestWebServerConfig_setDefaults tests the method setDefaults()
+func TestWebServerConfig_setDefaults(t *testing.T) {
+	t.Run("empty config gets safe defaults", func(t *testing.T) {
+		w := &WebServerConfig{}
+		w.setDefaults()
+		assert.Equal(t, DefaultServerCompressMinBytes, w.CompressMinBytes)
+		assert.Equal(t, DefaultServerHTTP2MaxStreams, w.HTTP2.MaxStreams)
+		assert.Equal(t, DefaultServerHTTP2StreamBuffer, w.HTTP2.StreamBuffer)
+		assert.Equal(t, DefaultServerIdleTimeout, w.IdleTimeout)
+		assert.Equal(t, DefaultServerMaxBodyBytes, w.MaxBodyBytes)
+		assert.Equal(t, DefaultServerMaxHeaderBytes, w.MaxHeaderBytes)
+		assert.Equal(t, DefaultServerReadHeaderTimeout, w.ReadHeaderTimeout)
+		assert.Equal(t, DefaultServerReadTimeout, w.ReadTimeout)
+		assert.Equal(t, DefaultServerShutdown, w.ShutdownTimeout)
+		assert.Equal(t, DefaultServerWriteTimeout, w.WriteTimeout)
+	})
+
+	t.Run("configured values are kept", func(t *testing.T) {
+		w := &WebServerConfig{
+			MaxHeaderBytes:    1024,
+			ReadHeaderTimeout: 2 * time.Second,
+		}
+		w.setDefaults()
+		assert.Equal(t, 1024, w.MaxHeaderBytes)
+		assert.Equal(t, 2*time.Second, w.ReadHeaderTimeout)
 	})
 }
+
+// TestShutdownConfig_setDefaults tests the method setDefaults()
+func TestShutdownConfig_setDefaults(t *testing.T) {
+	s := &ShutdownConfig{Alerts: time.Minute}
+	s.setDefaults()
+	assert.Equal(t, time.Minute, s.Alerts)
+	assert.Equal(t, DefaultShutdownDatastore, s.Datastore)
+	assert.Equal(t, DefaultShutdownNotifications, s.Notifications)
+	assert.Equal(t, DefaultShutdownP2P, s.P2P)
+	assert.Equal(t, DefaultShutdownRestart, s.Restart)
+}
+
+// TestClusterConfig_setDefaults tests the method setDefaults()
+func TestClusterConfig_setDefaults(t *testing.T) {
+	t.Run("empty config gets defaults", func(t *testing.T) {
+		c := &ClusterConfig{Enabled: true}
+		require.NoError(t, c.setDefaults())
+		hostname, err := os.Hostname()
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("%s-%d", hostname, os.Getpid()), c.InstanceID)
+		assert.Equal(t, DefaultClusterLeaseDuration, c.LeaseDuration)
+		assert.Equal(t, DefaultClusterName, c.Name)
+		assert.Equal(t, DefaultClusterRenewInterval, c.RenewInterval)
+	})
+
+	t.Run("configured values are kept", func(t *testing.T) {
+		c := &ClusterConfig{InstanceID: "node-a", LeaseDuration: 30 * time.Second, Name: "eu", RenewInterval: 5 * time.Second}
+		require.NoError(t, c.setDefaults())
+		assert.Equal(t, "node-a", c.InstanceID)
+		assert.Equal(t, 30*time.Second, c.LeaseDuration)
+		assert.Equal(t, "eu", c.Name)
+		assert.Equal(t, 5*time.Second, c.RenewInterval)
+	})
+
+	t.Run("lease renewed after it expired", func(t *testing.T) {
+		c := &ClusterConfig{LeaseDuration: 5 * time.Second, RenewInterval: 5 * time.Second}
+		require.ErrorIs(t, c.setDefaults(), ErrInvalidClusterLease)
+	})
+}
+
+// TestValidateNetworks tests the method validateNetworks()
+func TestValidateNetworks(t *testing.T) {
+	require.NoError(t, validateNetworks(nil))
+	require.NoError(t, validateNetworks([]string{"10.0.0.1", "10.8.0.0/16", "::1", "fd00::/8"}))
+	require.ErrorIs(t, validateNetworks([]string{"10.0.0.1", "vpn.example.com"}), ErrInvalidAllowlist)
+	require.ErrorIs(t, validateNetworks([]string{"10.0.0.0/33"}), ErrInvalidAllowlist)
+}
+
+// TestProfilingConfig_setDefaults tests the method setDefaults()
+func TestProfilingConfig_setDefaults(t *testing.T) {
+	p := &ProfilingConfig{Enabled: true, MemoryMB: -1}
+	p.setDefaults()
+	assert.InDelta(t, DefaultProfilingCPUPercent, p.CPUPercent, 0)
+	assert.Equal(t, DefaultProfilingDir, p.Dir)
+	assert.Equal(t, DefaultProfilingMaxProfiles, p.MaxProfiles)
+	assert.Equal(t, -1, p.MemoryMB, "memory ignored")
+	assert.Equal(t, DefaultProfilingSustained, p.Sustained)
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package config
+
+import "io"
+
+// newEventLogWriter will fail, the event log is only available on Windows
+func newEventLogWriter(_ string) (io.WriteCloser, error) {
+	return nil, ErrEventLogUnsupported
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestEventLogWriter will test the event log output is refused outside Windows
+func TestEventLogWriter(t *testing.T) {
+	_, err := (&Config{LogOutput: LogOutputEventLog}).logWriter()
+	require.ErrorIs(t, err, ErrEventLogUnsupported)
+}
@@ -0,0 +1,54 @@
+//go:build windows
+
+package config
+
+import (
+	"strings"
+
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// eventLogID is the event ID of the logs (the messages are the logs, event IDs are not used to tell them apart)
+const eventLogID = 1
+
+// eventLogWriter writes the logs to the Windows event log
+type eventLogWriter struct {
+	log *eventlog.Log
+}
+
+// newEventLogWriter will open the event log source (registered by the service install command)
+func newEventLogWriter(source string) (*eventLogWriter, error) {
+	log, err := eventlog.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	return &eventLogWriter{log: log}, nil
+}
+
+// writeLevel will write the log with the event type for the level (error, warning or information)
+func (w *eventLogWriter) writeLevel(level string, p []byte) (int, error) {
+	message := strings.TrimRight(string(p), "\n")
+	var err error
+	switch level {
+	case logLevelError, logLevelFatal, logLevelPanic:
+		err = w.log.Error(eventLogID, message)
+	case logLevelWarn:
+		err = w.log.Warning(eventLogID, message)
+	default:
+		err = w.log.Info(eventLogID, message)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Write will write the log as information
+func (w *eventLogWriter) Write(p []byte) (int, error) {
+	return w.writeLevel(logLevelInfo, p)
+}
+
+// Close will close the event log source
+func (w *eventLogWriter) Close() error {
+	return w.log.Close()
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// journaldSocket is the systemd journal socket (native protocol)
+const journaldSocket = "/run/systemd/journal/socket"
+
+// journaldWriter writes the logs to the systemd journal (native protocol)
+type journaldWriter struct {
+	conn       *net.UnixConn
+	identifier string
+	mu         sync.Mutex
+	socket     *net.UnixAddr
+}
+
+// newJournaldWriter will connect to the systemd journal
+func newJournaldWriter(identifier string) (*journaldWriter, error) {
+	return newJournaldWriterWithSocket(identifier, journaldSocket)
+}
+
+// newJournaldWriterWithSocket will connect to the journal socket
+func newJournaldWriterWithSocket(identifier, socket string) (*journaldWriter, error) {
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	w := &journaldWriter{
+		conn:       conn,
+		identifier: identifier,
+		socket:     &net.UnixAddr{Name: socket, Net: "unixgram"},
+	}
+
+	// Ensure the journal is running (an empty datagram is ignored by journald)
+	if _, err = conn.WriteToUnix(nil, w.socket); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// writeLevel will write the log with the journal priority (syslog severity) for the level
+func (w *journaldWriter) writeLevel(level string, p []byte) (int, error) {
+	severity, ok := syslogSeverities[level]
+	if !ok {
+		severity = syslogSeverities[logLevelInfo]
+	}
+	buf := new(bytes.Buffer)
+	writeJournaldField(buf, "PRIORITY", strconv.Itoa(severity))
+	writeJournaldField(buf, "SYSLOG_IDENTIFIER", w.identifier)
+	writeJournaldField(buf, "MESSAGE", strings.TrimRight(string(p), "\n"))
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.conn.WriteToUnix(buf.Bytes(), w.socket); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Write will write the log as informational
+func (w *journaldWriter) Write(p []byte) (int, error) {
+	return w.writeLevel(logLevelInfo, p)
+}
+
+// Close will close the journal connection
+func (w *journaldWriter) Close() error {
+	return w.conn.Close()
+}
+
+// writeJournaldField will write the field (values with a newline use the binary length-prefixed format)
+func writeJournaldField(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(key)
+	if !strings.Contains(value, "\n") {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
@@ -0,0 +1,62 @@
+package config
+
+import "strings"
+
+// Log levels (messages below the configured level are not logged)
+const (
+	LogLevelDebug = iota // Everything (default if not set on the logger)
+	LogLevelInfo         // Info, warnings and errors (default)
+	LogLevelWarn         // Warnings and errors
+	LogLevelError        // Errors only
+)
+
+// LogModuleDatastore is the module name for the datastore (debug turns on the SQL logs)
+const LogModuleDatastore = "datastore"
+
+// logLevelNames are the config values for the log levels
+var logLevelNames = map[string]int{
+	logLevelDebug: LogLevelDebug,
+	logLevelError: LogLevelError,
+	logLevelInfo:  LogLevelInfo,
+	logLevelWarn:  LogLevelWarn,
+	"warning":     LogLevelWarn,
+}
+
+// ParseLogLevel will parse the log level name (debug, info, warn or error)
+func ParseLogLevel(name string) (int, error) {
+	level, ok := logLevelNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return 0, ErrInvalidLogLevel
+	}
+	return level, nil
+}
+
+// parseModuleLogLevels will parse the per-module log level overrides
+func parseModuleLogLevels(modules map[string]string) (map[string]int, error) {
+	levels := make(map[string]int, len(modules))
+	for module, name := range modules {
+		level, err := ParseLogLevel(name)
+		if err != nil {
+			return nil, err
+		}
+		levels[strings.ToLower(module)] = level
+	}
+	return levels, nil
+}
+
+// logLevels is the log level with the per-module overrides
+type logLevels struct {
+	level   int
+	modules map[string]int
+}
+
+// forModule will return the log level for the module (the fallback if there is no override)
+func (l *logLevels) forModule(module string, fallback int) int {
+	if l == nil {
+		return fallback
+	}
+	if level, ok := l.modules[strings.ToLower(module)]; ok {
+		return level
+	}
+	return fallback
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Log rotation settings
+const (
+	logBackupTimeFormat = "2006-01-02T15-04-05.000" // Timestamp added to rotated log files
+	logCompressSuffix   = ".gz"                     // Suffix of compressed rotated log files
+	megabyte            = 1024 * 1024
+)
+
+// rotatingFile is a log file that is rotated when it reaches the max size
+// Rotated files are renamed with a timestamp (app-2006-01-02T15-04-05.000.log), optionally gzipped,
+// and removed when they are older than the max age or there are more than the max backups
+type rotatingFile struct {
+	conf     LogRotationConfig
+	file     *os.File
+	mu       sync.Mutex
+	path     string
+	size     int64
+	waiter   sync.WaitGroup
+	millLock sync.Mutex
+}
+
+// newRotatingFile will open (or create) the log file for appending
+func newRotatingFile(path string, conf LogRotationConfig) (*rotatingFile, error) {
+	r := &rotatingFile{conf: conf, path: path}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open will open the log file and get its current size
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	var info os.FileInfo
+	if info, err = file.Stat(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// Write will write to the log file, rotating it first if the write would exceed the max size
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.size > 0 && r.size+int64(len(p)) > int64(r.conf.MaxSizeMB)*megabyte {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close will close the log file (waiting for any compression or cleanup to finish)
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	err := r.file.Close()
+	r.mu.Unlock()
+	r.waiter.Wait()
+	return err
+}
+
+// rotate will rename the current file, open a new one and clean up the backups in the background
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(r.path, r.backupName(time.Now())); err != nil {
+		return err
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+	r.waiter.Add(1)
+	go func() {
+		defer r.waiter.Done()
+		r.mill()
+	}()
+	return nil
+}
+
+// backupName will return the name of the rotated file for the time
+func (r *rotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(r.path)
+	return strings.TrimSuffix(r.path, ext) + "-" + t.UTC().Format(logBackupTimeFormat) + ext
+}
+
+// logBackup is a rotated log file
+type logBackup struct {
+	path      string
+	timestamp time.Time
+}
+
+// backups will return the rotated log files (newest first)
+func (r *rotatingFile) backups() ([]logBackup, error) {
+	dir := filepath.Dir(r.path)
+	ext := filepath.Ext(r.path)
+	prefix := strings.TrimSuffix(filepath.Base(r.path), ext) + "-"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	backups := make([]logBackup, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), logCompressSuffix), ext)
+		timestamp, parseErr := time.Parse(logBackupTimeFormat, stamp)
+		if parseErr != nil {
+			continue
+		}
+		backups = append(backups, logBackup{path: filepath.Join(dir, name), timestamp: timestamp})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].timestamp.After(backups[j].timestamp)
+	})
+	return backups, nil
+}
+
+// mill will remove the expired backups and compress the remaining ones (if enabled)
+func (r *rotatingFile) mill() {
+	r.millLock.Lock()
+	defer r.millLock.Unlock()
+
+	backups, err := r.backups()
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-r.conf.MaxAge)
+	for i, backup := range backups {
+		if (r.conf.MaxBackups > 0 && i >= r.conf.MaxBackups) || (r.conf.MaxAge > 0 && backup.timestamp.Before(cutoff)) {
+			_ = os.Remove(backup.path)
+			continue
+		}
+		if r.conf.Compress && !strings.HasSuffix(backup.path, logCompressSuffix) {
+			_ = compressLogFile(backup.path)
+		}
+	}
+}
+
+// compressLogFile will gzip the file and remove the original
+func compressLogFile(path string) (err error) {
+	var src *os.File
+	if src, err = os.Open(path); err != nil { //nolint:gosec // path is a rotated log file
+		return err
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+
+	var dst *os.File
+	if dst, err = os.OpenFile(path+logCompressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600); err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path + logCompressSuffix)
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRotatingFile will test the log file rotation
+func TestRotatingFile(t *testing.T) {
+	t.Run("file is rotated at the max size", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "alert_system.log")
+		r, err := newRotatingFile(path, LogRotationConfig{MaxSizeMB: 1})
+		require.NoError(t, err)
+
+		line := []byte(strings.Repeat("a", 1023) + "\n")
+		for i := 0; i < 1025; i++ {
+			_, err = r.Write(line)
+			require.NoError(t, err)
+		}
+		require.NoError(t, r.Close())
+
+		backups, err := r.backups()
+		require.NoError(t, err)
+		require.Len(t, backups, 1)
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1024), info.Size())
+	})
+
+	t.Run("existing size is kept when reopened", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "alert_system.log")
+		require.NoError(t, os.WriteFile(path, []byte("existing\n"), 0600))
+		r, err := newRotatingFile(path, LogRotationConfig{MaxSizeMB: 1})
+		require.NoError(t, err)
+		assert.Equal(t, int64(9), r.size)
+		require.NoError(t, r.Close())
+	})
+
+	t.Run("backups are compressed and limited", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "alert_system.log")
+		r, err := newRotatingFile(path, LogRotationConfig{Compress: true, MaxBackups: 2, MaxSizeMB: 1})
+		require.NoError(t, err)
+
+		// Older rotated files
+		now := time.Now()
+		for i := 1; i <= 3; i++ {
+			require.NoError(t, os.WriteFile(r.backupName(now.Add(-time.Duration(i)*time.Hour)), []byte("old\n"), 0600))
+		}
+		r.mill()
+		require.NoError(t, r.Close())
+
+		backups, err := r.backups()
+		require.NoError(t, err)
+		require.Len(t, backups, 2)
+		for _, backup := range backups {
+			assert.True(t, strings.HasSuffix(backup.path, logCompressSuffix))
+		}
+
+		f, err := os.Open(backups[0].path)
+		require.NoError(t, err)
+		defer func() {
+			_ = f.Close()
+		}()
+		gz, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		b, err := io.ReadAll(gz)
+		require.NoError(t, err)
+		assert.Equal(t, "old\n", string(b))
+	})
+
+	t.Run("expired backups are removed", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "alert_system.log")
+		r, err := newRotatingFile(path, LogRotationConfig{MaxAge: 24 * time.Hour, MaxSizeMB: 1})
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(r.backupName(time.Now().Add(-48*time.Hour)), []byte("old\n"), 0600))
+		require.NoError(t, os.WriteFile(r.backupName(time.Now().Add(-time.Hour)), []byte("new\n"), 0600))
+		r.mill()
+		require.NoError(t, r.Close())
+
+		backups, err := r.backups()
+		require.NoError(t, err)
+		require.Len(t, backups, 1)
+	})
+}
@@ -0,0 +1,18 @@
+package config
+
+import "time"
+
+// LogSlow will log a warning if the operation took longer than the threshold (disabled if zero)
+// The warning includes the duration (duration_ms field), e.g. "slow node rpc getbestblockhash: took 1.2s (threshold 500ms)"
+func LogSlow(logger LoggerInterface, threshold time.Duration, start time.Time, format string, args ...interface{}) {
+	if threshold <= 0 || logger == nil {
+		return
+	}
+	elapsed := time.Since(start)
+	if elapsed < threshold {
+		return
+	}
+	WithField(logger, LogFieldDuration, elapsed.Milliseconds()).Warnf(
+		"slow "+format+": took %s (threshold %s)", append(args, elapsed.Round(time.Millisecond), threshold)...,
+	)
+}
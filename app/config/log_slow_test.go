@@ -0,0 +1,40 @@
+package config
+
+import (
+	"bytes"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLogSlow will test logging the slow operations
+func TestLogSlow(t *testing.T) {
+	t.Parallel()
+
+	newLogger := func(buf *bytes.Buffer) LoggerInterface {
+		return &ExtendedLogger{Logger: log.New(buf, "", 0), levels: &logLevels{}, logLevel: LogLevelDebug}
+	}
+
+	t.Run("slow operation is logged", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		LogSlow(newLogger(buf), time.Millisecond, time.Now().Add(-50*time.Millisecond), "node rpc %s", "getinfo")
+		assert.Contains(t, buf.String(), "duration_ms=")
+		assert.Contains(t, buf.String(), "slow node rpc getinfo: took ")
+		assert.Contains(t, buf.String(), "(threshold 1ms)")
+	})
+
+	t.Run("fast operation is not logged", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		LogSlow(newLogger(buf), time.Minute, time.Now(), "node rpc %s", "getinfo")
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("disabled threshold", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		LogSlow(newLogger(buf), 0, time.Now().Add(-time.Hour), "node rpc %s", "getinfo")
+		assert.Empty(t, buf.String())
+		LogSlow(nil, time.Millisecond, time.Now().Add(-time.Hour), "node rpc %s", "getinfo")
+	})
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Log outputs
+const (
+	LogOutputEventLog = "eventlog" // Write to the Windows event log (the source is the log_syslog tag)
+	LogOutputFile     = "file"     // Write to the log_output_file (rotated)
+	LogOutputJournald = "journald" // Write to the systemd journal (native protocol)
+	LogOutputStdout   = "stdout"   // Write to stdout (default)
+	LogOutputSyslog   = "syslog"   // Write to the local or a remote syslog (RFC5424)
+)
+
+// Syslog networks
+const (
+	SyslogNetworkTCP  = "tcp"  // Remote syslog over TCP (octet counted frames, RFC6587)
+	SyslogNetworkUDP  = "udp"  // Remote syslog over UDP (default if an address is set)
+	SyslogNetworkUnix = "unix" // Local syslog socket (default if no address is set)
+)
+
+// Syslog settings
+const (
+	DefaultSyslogFacility = "daemon"         // Default syslog facility
+	DefaultSyslogTag      = "alert-system"   // Default syslog tag (app name and journald identifier)
+	syslogTimeFormat      = time.RFC3339Nano // RFC5424 timestamp
+	syslogVersion         = 1                // RFC5424 version
+)
+
+// syslogFacilities are the config values for the syslog facilities
+var syslogFacilities = map[string]int{
+	"daemon": 3,
+	"local0": 16,
+	"local1": 17,
+	"local2": 18,
+	"local3": 19,
+	"local4": 20,
+	"local5": 21,
+	"local6": 22,
+	"local7": 23,
+	"user":   1,
+}
+
+// syslogSockets are the local syslog sockets (the first that accepts a connection is used)
+var syslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// syslogSeverities are the syslog severities for the log levels
+var syslogSeverities = map[string]int{
+	logLevelDebug: 7, // Debug
+	logLevelError: 3, // Error
+	logLevelFatal: 2, // Critical
+	logLevelInfo:  6, // Informational
+	logLevelPanic: 2, // Critical
+	logLevelWarn:  4, // Warning
+}
+
+// levelWriter is a log writer that receives the level of each log (syslog and journald)
+// Loggers write the message without the timestamp and prefix (the writer adds its own)
+type levelWriter interface {
+	writeLevel(level string, p []byte) (int, error)
+}
+
+// syslogWriter writes the logs to the local or a remote syslog (RFC5424)
+type syslogWriter struct {
+	address  string
+	conn     net.Conn
+	facility int
+	hostname string
+	mu       sync.Mutex
+	network  string
+	pid      int
+	stream   bool // Local stream socket (newline terminated messages)
+	tag      string
+}
+
+// newSyslogWriter will connect to the syslog (the local socket if no address is set)
+func newSyslogWriter(conf SyslogConfig) (*syslogWriter, error) {
+	facility, ok := syslogFacilities[strings.ToLower(conf.Facility)]
+	if !ok {
+		return nil, ErrInvalidLogFacility
+	}
+	hostname, _ := os.Hostname()
+	w := &syslogWriter{
+		address:  conf.Address,
+		facility: facility,
+		hostname: hostname,
+		network:  conf.Network,
+		pid:      os.Getpid(),
+		tag:      conf.Tag,
+	}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// connect will (re)connect to the syslog
+func (w *syslogWriter) connect() (err error) {
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	if w.network != SyslogNetworkUnix || len(w.address) > 0 {
+		network := w.network
+		if network == SyslogNetworkUnix {
+			network = "unixgram"
+		}
+		w.conn, err = net.Dial(network, w.address)
+		return err
+	}
+	for _, socket := range syslogSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			if w.conn, err = net.Dial(network, socket); err == nil {
+				w.stream = network == "unix"
+				return nil
+			}
+		}
+	}
+	return err
+}
+
+// format will format the RFC5424 message (<PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD MSG)
+func (w *syslogWriter) format(level string, p []byte) []byte {
+	severity, ok := syslogSeverities[level]
+	if !ok {
+		severity = syslogSeverities[logLevelInfo]
+	}
+	msg := fmt.Sprintf("<%d>%d %s %s %s %d - - %s",
+		w.facility*8+severity, syslogVersion, time.Now().Format(syslogTimeFormat),
+		nilValue(w.hostname), nilValue(w.tag), w.pid, strings.TrimRight(string(p), "\n"),
+	)
+	if w.network == SyslogNetworkTCP {
+		return []byte(fmt.Sprintf("%d %s", len(msg), msg))
+	} else if w.stream {
+		return []byte(msg + "\n")
+	}
+	return []byte(msg)
+}
+
+// writeLevel will write the log with the syslog severity for the level (reconnecting once on failure)
+func (w *syslogWriter) writeLevel(level string, p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	msg := w.format(level, p)
+	if w.conn != nil {
+		if _, err := w.conn.Write(msg); err == nil {
+			return len(p), nil
+		}
+	}
+	if err := w.connect(); err != nil {
+		return 0, err
+	}
+	if _, err := w.conn.Write(msg); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Write will write the log as informational
+func (w *syslogWriter) Write(p []byte) (int, error) {
+	return w.writeLevel(logLevelInfo, p)
+}
+
+// Close will close the syslog connection
+func (w *syslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// nilValue will return the RFC5424 nil value (-) for an empty header field
+func nilValue(value string) string {
+	if len(value) == 0 {
+		return "-"
+	}
+	return value
+}
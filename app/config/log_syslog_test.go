@@ -0,0 +1,175 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readPacket will read one datagram from the connection
+func readPacket(t *testing.T, conn net.PacketConn) string {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 65536)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	return string(buf[:n])
+}
+
+// TestSyslogWriter will test writing the logs to a remote syslog
+func TestSyslogWriter(t *testing.T) {
+	t.Run("udp", func(t *testing.T) {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		var w *syslogWriter
+		w, err = newSyslogWriter(SyslogConfig{
+			Address: conn.LocalAddr().String(), Facility: "local0", Network: SyslogNetworkUDP, Tag: "alert-test",
+		})
+		require.NoError(t, err)
+		defer func() {
+			_ = w.Close()
+		}()
+
+		logger := WithField(NewExtendedLogger(w, LogLevelInfo, nil), LogFieldModule, "p2p")
+		logger.Errorf("failed to read alert %d", 7)
+
+		msg := readPacket(t, conn)
+		assert.True(t, strings.HasPrefix(msg, "<131>1 "), msg) // local0 (16) * 8 + error (3)
+		assert.True(t, strings.HasSuffix(msg, " alert-test "+strconv.Itoa(os.Getpid())+" - - module=p2p failed to read alert 7"), msg)
+		assert.NotContains(t, msg, "\033")
+
+		logger.Debugf("not logged")
+		logger.Warnf("warning")
+		assert.True(t, strings.HasPrefix(readPacket(t, conn), "<132>1 ")) // local0 (16) * 8 + warning (4)
+	})
+
+	t.Run("tcp frames are octet counted", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer func() {
+			_ = ln.Close()
+		}()
+
+		var w *syslogWriter
+		w, err = newSyslogWriter(SyslogConfig{
+			Address: ln.Addr().String(), Facility: DefaultSyslogFacility, Network: SyslogNetworkTCP, Tag: DefaultSyslogTag,
+		})
+		require.NoError(t, err)
+		defer func() {
+			_ = w.Close()
+		}()
+
+		var conn net.Conn
+		conn, err = ln.Accept()
+		require.NoError(t, err)
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		NewJSONLogger(w, LogLevelInfo, nil).Info("started")
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		reader := bufio.NewReader(conn)
+		var length string
+		length, err = reader.ReadString(' ')
+		require.NoError(t, err)
+		var n int
+		n, err = strconv.Atoi(strings.TrimSpace(length))
+		require.NoError(t, err)
+		msg := make([]byte, n)
+		_, err = reader.Read(msg)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(msg), "<30>1 "), string(msg)) // daemon (3) * 8 + info (6)
+		assert.Contains(t, string(msg), `"msg":"started"`)
+	})
+
+	t.Run("invalid facility", func(t *testing.T) {
+		_, err := newSyslogWriter(SyslogConfig{Address: "127.0.0.1:514", Facility: "kern", Network: SyslogNetworkUDP})
+		require.ErrorIs(t, err, ErrInvalidLogFacility)
+	})
+}
+
+// TestJournaldWriter will test writing the logs to the journal socket
+func TestJournaldWriter(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	var w *journaldWriter
+	w, err = newJournaldWriterWithSocket("alert-test", socket)
+	require.NoError(t, err)
+	defer func() {
+		_ = w.Close()
+	}()
+	assert.Empty(t, readPacket(t, conn)) // Connection check
+
+	logger := NewExtendedLogger(w, LogLevelInfo, nil)
+	logger.Warnf("peer %s is slow", "abc")
+	assert.Equal(t, "PRIORITY=4\nSYSLOG_IDENTIFIER=alert-test\nMESSAGE=peer abc is slow\n", readPacket(t, conn))
+
+	logger.Error("line one\nline two")
+	expected := new(bytes.Buffer)
+	expected.WriteString("PRIORITY=3\nSYSLOG_IDENTIFIER=alert-test\nMESSAGE\n")
+	require.NoError(t, binary.Write(expected, binary.LittleEndian, uint64(len("line one\nline two"))))
+	expected.WriteString("line one\nline two\n")
+	assert.Equal(t, expected.String(), readPacket(t, conn))
+}
+
+// TestLogWriter will test selecting the log output
+func TestLogWriter(t *testing.T) {
+	t.Run("defaults to stdout", func(t *testing.T) {
+		c := &Config{}
+		w, err := c.logWriter()
+		require.NoError(t, err)
+		assert.Equal(t, os.Stdout, w)
+		assert.Equal(t, LogOutputStdout, c.LogOutput)
+	})
+
+	t.Run("defaults to the file if set", func(t *testing.T) {
+		c := &Config{LogOutputFile: filepath.Join(t.TempDir(), "alert.log")}
+		w, err := c.logWriter()
+		require.NoError(t, err)
+		assert.Equal(t, LogOutputFile, c.LogOutput)
+		require.NoError(t, w.Close())
+	})
+
+	t.Run("invalid outputs", func(t *testing.T) {
+		_, err := (&Config{LogOutput: "kafka"}).logWriter()
+		require.ErrorIs(t, err, ErrInvalidLogOutput)
+
+		_, err = (&Config{LogOutput: LogOutputFile}).logWriter()
+		require.ErrorIs(t, err, ErrInvalidLogOutput)
+
+		_, err = (&Config{LogOutput: LogOutputSyslog, LogSyslog: SyslogConfig{Network: SyslogNetworkTCP}}).logWriter()
+		require.ErrorIs(t, err, ErrInvalidSyslogNetwork)
+
+		_, err = (&Config{LogOutput: LogOutputSyslog, LogSyslog: SyslogConfig{Network: "http"}}).logWriter()
+		require.ErrorIs(t, err, ErrInvalidSyslogNetwork)
+	})
+
+	t.Run("remote syslog defaults to udp", func(t *testing.T) {
+		c := &Config{LogOutput: LogOutputSyslog, LogSyslog: SyslogConfig{Address: "127.0.0.1:514"}}
+		w, err := c.logWriter()
+		require.NoError(t, err)
+		assert.Equal(t, SyslogNetworkUDP, c.LogSyslog.Network)
+		assert.Equal(t, DefaultSyslogFacility, c.LogSyslog.Facility)
+		assert.Equal(t, DefaultSyslogTag, c.LogSyslog.Tag)
+		require.NoError(t, w.Close())
+	})
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
 )
@@ -32,8 +33,45 @@ type LoggerInterface interface {
 // ExtendedLogger is the extended logger to satisfy the LoggerInterface
 type ExtendedLogger struct {
 	*log.Logger
+	levels   *logLevels
 	logLevel int
-	writer   *os.File
+	prefix   string
+	writer   io.WriteCloser
+}
+
+// NewExtendedLogger will create a new text logger with the log level (and per-module overrides)
+func NewExtendedLogger(writer io.WriteCloser, level int, moduleLevels map[string]int) *ExtendedLogger {
+	return &ExtendedLogger{
+		Logger:   log.New(writer, "bitcoin-alert-system: ", log.LstdFlags),
+		levels:   &logLevels{level: level, modules: moduleLevels},
+		logLevel: level,
+		writer:   writer,
+	}
+}
+
+// withField will return a copy of the logger with the field prefix (and the module log level)
+func (es *ExtendedLogger) withField(key string, value interface{}) LoggerInterface {
+	l := *es
+	l.prefix += fmt.Sprintf("%s=%v ", key, value)
+	if key == LogFieldModule {
+		l.logLevel = es.levels.forModule(fmt.Sprint(value), es.logLevel)
+	}
+	return &l
+}
+
+// enabled will return true if the level is logged
+func (es *ExtendedLogger) enabled(level int) bool {
+	return level >= es.logLevel
+}
+
+// output will write the log message (decorated with the format on the console)
+// Syslog and journald writers receive the plain message with the level
+func (es *ExtendedLogger) output(level, decoration, format string, v ...interface{}) {
+	if lw, ok := es.writer.(levelWriter); ok {
+		_, _ = lw.writeLevel(level, []byte(fmt.Sprintf(es.prefix+format, v...)))
+		return
+	}
+	es.Logger.Printf(fmt.Sprintf(decoration, es.prefix+format), v...)
 }
 
 // CloseWriter close the log writer
@@ -43,42 +81,70 @@ func (es *ExtendedLogger) CloseWriter() error {
 
 // Printf will print the log message to the console
 func (es *ExtendedLogger) Printf(format string, v ...interface{}) {
-	es.Logger.Printf(format, v...)
+	if es.enabled(LogLevelInfo) {
+		es.output(logLevelInfo, "%s", format, v...)
+	}
 }
 
 // Debugf will print debug messages to the console
 func (es *ExtendedLogger) Debugf(format string, v ...interface{}) {
-	es.Logger.Printf(fmt.Sprintf("\033[1;34m| DEBUG | %s\033[0m", format), v...)
+	if es.enabled(LogLevelDebug) {
+		es.output(logLevelDebug, "\033[1;34m| DEBUG | %s\033[0m", format, v...)
+	}
 }
 
 // Debug will print debug messages to the console
 func (es *ExtendedLogger) Debug(v ...interface{}) {
-	es.Logger.Printf("%v", v...)
+	if es.enabled(LogLevelDebug) {
+		es.output(logLevelDebug, "%s", "%v", v...)
+	}
 }
 
 // Error will print debug messages to the console
 func (es *ExtendedLogger) Error(v ...interface{}) {
-	es.Logger.Printf("%v", v...)
+	if es.enabled(LogLevelError) {
+		es.output(logLevelError, "%s", "%v", v...)
+	}
 }
 
 // Errorf will print debug messages to the console
 func (es *ExtendedLogger) Errorf(format string, v ...interface{}) {
-	es.Logger.Printf(fmt.Sprintf("\033[1;31m| ERROR |: %s\033[0m", format), v...)
+	if es.enabled(LogLevelError) {
+		es.output(logLevelError, "\033[1;31m| ERROR |: %s\033[0m", format, v...)
+	}
 }
 
 // ErrorWithStack will print debug messages to the console
 func (es *ExtendedLogger) ErrorWithStack(format string, v ...interface{}) {
-	es.Logger.Printf(format, v...)
+	if es.enabled(LogLevelError) {
+		es.output(logLevelError, "%s", format, v...)
+	}
+}
+
+// Fatal will print the fatal message and exit
+func (es *ExtendedLogger) Fatal(v ...interface{}) {
+	es.output(logLevelFatal, "%s", "%v", v...)
+	os.Exit(1)
+}
+
+// Fatalf will print the fatal message and exit
+func (es *ExtendedLogger) Fatalf(format string, v ...interface{}) {
+	es.output(logLevelFatal, "%s", format, v...)
+	os.Exit(1)
 }
 
 // Info will print info messages to the console
 func (es *ExtendedLogger) Info(v ...interface{}) {
-	es.Logger.Printf("%v", v...)
+	if es.enabled(LogLevelInfo) {
+		es.output(logLevelInfo, "%s", "%v", v...)
+	}
 }
 
 // Infof will print info messages to the console
 func (es *ExtendedLogger) Infof(format string, v ...interface{}) {
-	es.Logger.Printf(fmt.Sprintf("\033[1;32m| INFO  | %s\033[0m", format), v...)
+	if es.enabled(LogLevelInfo) {
+		es.output(logLevelInfo, "\033[1;32m| INFO  | %s\033[0m", format, v...)
+	}
 }
 
 // LogLevel returns the logging level
@@ -86,12 +152,30 @@ func (es *ExtendedLogger) LogLevel() int {
 	return es.logLevel
 }
 
+// Panic will print the panic message and panic
+func (es *ExtendedLogger) Panic(v ...interface{}) {
+	msg := fmt.Sprint(v...)
+	es.output(logLevelPanic, "%s", "%s", msg)
+	panic(msg)
+}
+
+// Panicf will print the panic message and panic
+func (es *ExtendedLogger) Panicf(format string, v ...interface{}) {
+	msg := fmt.Sprintf(format, v...)
+	es.output(logLevelPanic, "%s", "%s", msg)
+	panic(msg)
+}
+
 // Warn will print warning messages to the console
 func (es *ExtendedLogger) Warn(v ...interface{}) {
-	es.Logger.Printf("%v", v...)
+	if es.enabled(LogLevelWarn) {
+		es.output(logLevelWarn, "%s", "%v", v...)
+	}
 }
 
 // Warnf will print warning messages to the console
 func (es *ExtendedLogger) Warnf(format string, v ...interface{}) {
-	es.Logger.Printf(format, v...)
+	if es.enabled(LogLevelWarn) {
+		es.output(logLevelWarn, "%s", format, v...)
+	}
 }
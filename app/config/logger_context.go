@@ -0,0 +1,32 @@
+package config
+
+import "context"
+
+// loggerContextKey is the context key for the logger
+type loggerContextKey struct{}
+
+// ContextWithLogger will return a copy of the context carrying the logger
+// Use it to pass a child logger (with the module, peer_id, alert_sequence fields, etc.) down the call chain
+func ContextWithLogger(ctx context.Context, logger LoggerInterface) context.Context {
+	if logger == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// LoggerFromContext will return the logger carried by the context (the fallback if none is set)
+func LoggerFromContext(ctx context.Context, fallback LoggerInterface) LoggerInterface {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerContextKey{}).(LoggerInterface); ok {
+			return logger
+		}
+	}
+	return fallback
+}
+
+// ContextWithField will add the field to the logger carried by the context (the fallback if none is set)
+// Returns the context carrying the child logger and the child logger
+func ContextWithField(ctx context.Context, fallback LoggerInterface, key string, value interface{}) (context.Context, LoggerInterface) {
+	logger := WithField(LoggerFromContext(ctx, fallback), key, value)
+	return ContextWithLogger(ctx, logger), logger
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoggerFromContext will test passing the child loggers through the context
+func TestLoggerFromContext(t *testing.T) {
+	t.Run("fallback if no logger is set", func(t *testing.T) {
+		fallback := newTestJSONLogger(new(bytes.Buffer))
+		assert.Equal(t, fallback, LoggerFromContext(context.Background(), fallback))
+		assert.Nil(t, LoggerFromContext(context.Background(), nil))
+		assert.Equal(t, context.Background(), ContextWithLogger(context.Background(), nil))
+	})
+
+	t.Run("fields are carried by the context", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		fallback := newTestJSONLogger(buf)
+		ctx, _ := ContextWithField(context.Background(), fallback, LogFieldModule, "p2p")
+		ctx, logger := ContextWithField(ctx, fallback, LogFieldPeerID, "peer")
+		ctx, _ = ContextWithField(ctx, fallback, LogFieldAlertSequence, uint32(7))
+		assert.NotEqual(t, logger, LoggerFromContext(ctx, fallback))
+
+		LoggerFromContext(ctx, fallback).Infof("alert processed")
+		entry := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		assert.Equal(t, "alert processed", entry["msg"])
+		assert.Equal(t, "p2p", entry[LogFieldModule])
+		assert.Equal(t, "peer", entry[LogFieldPeerID])
+		assert.Equal(t, float64(7), entry[LogFieldAlertSequence])
+	})
+}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/metrics"
+)
+
+// maxLogDedupEntries is the max repeated messages tracked at once (messages over the max are always logged)
+const maxLogDedupEntries = 10000
+
+// logDedupEntry is a message repeated within the window
+type logDedupEntry struct {
+	count      int              // Times the message was logged within the window
+	emit       func(msg string) // Writes the summary (at the level and with the fields of the message)
+	message    string           // Formatted message
+	start      time.Time        // Start of the window
+	suppressed int              // Times the message was dropped within the window
+}
+
+// logDedup tracks the repeated messages of a logger (shared by its child loggers)
+type logDedup struct {
+	burst     int
+	closeOnce sync.Once
+	done      chan struct{}
+	entries   map[string]*logDedupEntry
+	mu        sync.Mutex
+	now       func() time.Time
+	sample    int
+	window    time.Duration
+}
+
+// newLogDedup will create the dedup state (call flushLoop to write the summaries periodically)
+func newLogDedup(conf LogDedupConfig) *logDedup {
+	return &logDedup{
+		burst:   conf.Burst,
+		done:    make(chan struct{}),
+		entries: make(map[string]*logDedupEntry),
+		now:     time.Now,
+		sample:  conf.Sample,
+		window:  conf.Window,
+	}
+}
+
+// allow will return true if the message is logged
+// The first burst repeats within the window are logged, after that only every sample-th repeat
+func (d *logDedup) allow(key, message string, emit func(msg string)) bool {
+	now := d.now()
+	d.mu.Lock()
+	e, ok := d.entries[key]
+	if !ok || now.Sub(e.start) >= d.window {
+		if !ok && len(d.entries) >= maxLogDedupEntries {
+			d.mu.Unlock()
+			return true
+		}
+		d.entries[key] = &logDedupEntry{count: 1, emit: emit, message: message, start: now}
+		d.mu.Unlock()
+
+		// The previous window ended before the flush loop wrote its summary
+		if ok && e.suppressed > 0 {
+			e.emit(d.summary(e))
+		}
+		return true
+	}
+	e.count++
+	allowed := e.count <= d.burst || (d.sample > 0 && (e.count-d.burst)%d.sample == 0)
+	if !allowed {
+		e.suppressed++
+	}
+	d.mu.Unlock()
+	return allowed
+}
+
+// flush will write the summaries of the windows that ended (all of them if force is set)
+func (d *logDedup) flush(force bool) {
+	now := d.now()
+	summaries := make([]func(), 0)
+	d.mu.Lock()
+	for key, e := range d.entries {
+		if !force && now.Sub(e.start) < d.window {
+			continue
+		}
+		if e.suppressed > 0 {
+			emit, summary := e.emit, d.summary(e)
+			summaries = append(summaries, func() { emit(summary) })
+		}
+		delete(d.entries, key)
+	}
+	d.mu.Unlock()
+
+	// Write outside the lock (the summary is logged through the dedup logger's parent)
+	for _, summary := range summaries {
+		summary()
+	}
+}
+
+// summary will return the summary of the dropped repeats
+func (d *logDedup) summary(e *logDedupEntry) string {
+	return fmt.Sprintf("%s (repeated %d more times in the last %s)", e.message, e.suppressed, d.window)
+}
+
+// flushLoop will write the summaries until the dedup is closed
+func (d *logDedup) flushLoop() {
+	ticker := time.NewTicker(d.window / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+			d.flush(false)
+		}
+	}
+}
+
+// close will stop the flush loop and write the pending summaries
+func (d *logDedup) close() {
+	d.closeOnce.Do(func() {
+		close(d.done)
+		d.flush(true)
+	})
+}
+
+// dedupLogger drops the repeats of a message logged more than the burst within the window
+// and logs a "repeated N more times" summary once the window ends (fatal and panic logs are never dropped)
+type dedupLogger struct {
+	LoggerInterface
+	dedup  *logDedup
+	fields string // Fields of the logger (part of the message key)
+}
+
+// newDedupLogger will wrap the logger to drop the repeated messages (disabled if the window is not positive)
+func newDedupLogger(logger LoggerInterface, conf LogDedupConfig) LoggerInterface {
+	if conf.Window <= 0 {
+		return logger
+	}
+	if conf.Burst <= 0 {
+		conf.Burst = 1
+	}
+	d := newLogDedup(conf)
+	go d.flushLoop()
+	return &dedupLogger{LoggerInterface: logger, dedup: d}
+}
+
+// withField will add the field to the logger (child loggers share the repeated messages)
+func (l *dedupLogger) withField(key string, value interface{}) LoggerInterface {
+	return &dedupLogger{
+		LoggerInterface: WithField(l.LoggerInterface, key, value),
+		dedup:           l.dedup,
+		fields:          l.fields + fmt.Sprintf("%s=%v ", key, value),
+	}
+}
+
+// allow will return true if the message is logged at the level
+func (l *dedupLogger) allow(level, message string, log func(format string, args ...interface{})) bool {
+	if l.dedup.allow(level+"|"+l.fields+message, message, func(summary string) { log("%s", summary) }) {
+		return true
+	}
+	metrics.LogsSuppressed.WithLabelValues(level).Inc()
+	return false
+}
+
+// CloseWriter will write the pending summaries and close the log writer
+func (l *dedupLogger) CloseWriter() error {
+	l.dedup.close()
+	return l.LoggerInterface.CloseWriter()
+}
+
+// Debug will log the debug message (unless it is a dropped repeat)
+func (l *dedupLogger) Debug(args ...interface{}) {
+	if l.LogLevel() <= LogLevelDebug && l.allow(logLevelDebug, fmt.Sprint(args...), l.LoggerInterface.Debugf) {
+		l.LoggerInterface.Debug(args...)
+	}
+}
+
+// Debugf will log the debug message (unless it is a dropped repeat)
+func (l *dedupLogger) Debugf(msg string, args ...interface{}) {
+	if l.LogLevel() <= LogLevelDebug && l.allow(logLevelDebug, fmt.Sprintf(msg, args...), l.LoggerInterface.Debugf) {
+		l.LoggerInterface.Debugf(msg, args...)
+	}
+}
+
+// Error will log the error (unless it is a dropped repeat)
+func (l *dedupLogger) Error(args ...interface{}) {
+	if l.allow(logLevelError, fmt.Sprint(args...), l.LoggerInterface.Errorf) {
+		l.LoggerInterface.Error(args...)
+	}
+}
+
+// Errorf will log the error (unless it is a dropped repeat)
+func (l *dedupLogger) Errorf(msg string, args ...interface{}) {
+	if l.allow(logLevelError, fmt.Sprintf(msg, args...), l.LoggerInterface.Errorf) {
+		l.LoggerInterface.Errorf(msg, args...)
+	}
+}
+
+// ErrorWithStack will log the error with the stack (unless it is a dropped repeat)
+func (l *dedupLogger) ErrorWithStack(msg string, args ...interface{}) {
+	if l.allow(logLevelError, fmt.Sprintf(msg, args...), l.LoggerInterface.Errorf) {
+		l.LoggerInterface.ErrorWithStack(msg, args...)
+	}
+}
+
+// Info will log the info message (unless it is a dropped repeat)
+func (l *dedupLogger) Info(args ...interface{}) {
+	if l.LogLevel() <= LogLevelInfo && l.allow(logLevelInfo, fmt.Sprint(args...), l.LoggerInterface.Infof) {
+		l.LoggerInterface.Info(args...)
+	}
+}
+
+// Infof will log the info message (unless it is a dropped repeat)
+func (l *dedupLogger) Infof(msg string, args ...interface{}) {
+	if l.LogLevel() <= LogLevelInfo && l.allow(logLevelInfo, fmt.Sprintf(msg, args...), l.LoggerInterface.Infof) {
+		l.LoggerInterface.Infof(msg, args...)
+	}
+}
+
+// Warn will log the warning (unless it is a dropped repeat)
+func (l *dedupLogger) Warn(args ...interface{}) {
+	if l.LogLevel() <= LogLevelWarn && l.allow(logLevelWarn, fmt.Sprint(args...), l.LoggerInterface.Warnf) {
+		l.LoggerInterface.Warn(args...)
+	}
+}
+
+// Warnf will log the warning (unless it is a dropped repeat)
+func (l *dedupLogger) Warnf(msg string, args ...interface{}) {
+	if l.LogLevel() <= LogLevelWarn && l.allow(logLevelWarn, fmt.Sprintf(msg, args...), l.LoggerInterface.Warnf) {
+		l.LoggerInterface.Warnf(msg, args...)
+	}
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDedupLogger will create a dedup logger (without the flush loop) writing JSON to the buffer
+func newTestDedupLogger(buf *bytes.Buffer, conf LogDedupConfig, now *time.Time) *dedupLogger {
+	d := newLogDedup(conf)
+	d.now = func() time.Time { return *now }
+	return &dedupLogger{LoggerInterface: newTestJSONLogger(buf), dedup: d}
+}
+
+// logEntries will return the logged entries
+func logEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	entries := make([]map[string]interface{}, 0)
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if len(line) == 0 {
+			continue
+		}
+		entry := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// TestDedupLogger will test dropping the repeated log messages
+func TestDedupLogger(t *testing.T) {
+	t.Run("repeats over the burst are summarized", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		now := time.Now()
+		logger := newTestDedupLogger(buf, LogDedupConfig{Burst: 2, Window: time.Minute}, &now)
+		peer := WithField(logger, LogFieldPeerID, "peer")
+		before := testutil.ToFloat64(metrics.LogsSuppressed.WithLabelValues(logLevelWarn))
+
+		for i := 0; i < 10; i++ {
+			peer.Warnf("invalid signature from %s", "peer")
+		}
+		peer.Warnf("invalid signature from %s", "other")
+		require.Len(t, logEntries(t, buf), 3)
+		assert.Equal(t, before+8, testutil.ToFloat64(metrics.LogsSuppressed.WithLabelValues(logLevelWarn)))
+
+		// The window has not ended
+		logger.dedup.flush(false)
+		require.Len(t, logEntries(t, buf), 3)
+
+		// The summary is written with the level and fields of the message
+		now = now.Add(time.Minute)
+		logger.dedup.flush(false)
+		entries := logEntries(t, buf)
+		require.Len(t, entries, 4)
+		assert.Equal(t, "invalid signature from peer (repeated 8 more times in the last 1m0s)", entries[3]["msg"])
+		assert.Equal(t, logLevelWarn, entries[3]["level"])
+		assert.Equal(t, "peer", entries[3][LogFieldPeerID])
+
+		// A new window logs the message again
+		peer.Warnf("invalid signature from %s", "peer")
+		require.Len(t, logEntries(t, buf), 5)
+	})
+
+	t.Run("fields and levels are deduplicated separately", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		now := time.Now()
+		logger := newTestDedupLogger(buf, LogDedupConfig{Burst: 1, Window: time.Minute}, &now)
+		for i := 0; i < 3; i++ {
+			WithField(logger, LogFieldPeerID, "a").Error("failed")
+			WithField(logger, LogFieldPeerID, "b").Error("failed")
+			logger.Warn("failed")
+		}
+		require.Len(t, logEntries(t, buf), 3)
+	})
+
+	t.Run("repeats are sampled", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		now := time.Now()
+		logger := newTestDedupLogger(buf, LogDedupConfig{Burst: 1, Sample: 3, Window: time.Minute}, &now)
+		for i := 0; i < 10; i++ {
+			logger.Infof("peer connected")
+		}
+
+		// The first, then every third repeat (4, 7 and 10)
+		require.Len(t, logEntries(t, buf), 4)
+	})
+
+	t.Run("summary is written when the next window starts", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		now := time.Now()
+		logger := newTestDedupLogger(buf, LogDedupConfig{Burst: 1, Window: time.Minute}, &now)
+		logger.Errorf("node is down")
+		logger.Errorf("node is down")
+		now = now.Add(2 * time.Minute)
+		logger.Errorf("node is down")
+
+		entries := logEntries(t, buf)
+		require.Len(t, entries, 3)
+		assert.Equal(t, "node is down (repeated 1 more times in the last 1m0s)", entries[1]["msg"])
+		assert.Equal(t, "node is down", entries[2]["msg"])
+	})
+
+	t.Run("pending summaries are written on close", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		now := time.Now()
+		logger := newTestDedupLogger(buf, LogDedupConfig{Burst: 1, Window: time.Minute}, &now)
+		logger.Warn("slow rpc")
+		logger.Warn("slow rpc")
+		require.NoError(t, logger.CloseWriter())
+		entries := logEntries(t, buf)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "slow rpc (repeated 1 more times in the last 1m0s)", entries[1]["msg"])
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		logger := newTestJSONLogger(buf)
+		assert.Equal(t, logger, newDedupLogger(logger, LogDedupConfig{Window: -1}))
+	})
+}
@@ -0,0 +1,233 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Log formats
+const (
+	LogFormatJSON = "json" // One JSON object per line (Loki/ELK)
+	LogFormatText = "text" // Human readable (default)
+)
+
+// Structured log fields
+const (
+	LogFieldAlertSequence = "alert_sequence" // Alert sequence number
+	LogFieldDuration      = "duration_ms"    // Duration of a slow operation (milliseconds)
+	LogFieldModule        = "module"         // Application module (p2p, api, webhook, etc.)
+	LogFieldPeerID        = "peer_id"        // Libp2p peer ID
+	LogFieldRequestID     = "request_id"     // API request (correlation) ID
+)
+
+// Log levels (written in the level field)
+const (
+	logLevelDebug = "debug"
+	logLevelError = "error"
+	logLevelFatal = "fatal"
+	logLevelInfo  = "info"
+	logLevelPanic = "panic"
+	logLevelWarn  = "warn"
+)
+
+// fieldLogger is a logger that can carry structured fields
+type fieldLogger interface {
+	withField(key string, value interface{}) LoggerInterface
+}
+
+// WithField will return a logger that adds the field to every log
+// JSON loggers write the field as a JSON property, other loggers prefix the message (key=value)
+func WithField(logger LoggerInterface, key string, value interface{}) LoggerInterface {
+	if logger == nil {
+		return nil
+	}
+	if l, ok := logger.(fieldLogger); ok {
+		return l.withField(key, value)
+	}
+	return &prefixLogger{LoggerInterface: logger, prefix: fmt.Sprintf("%s=%v ", key, value)}
+}
+
+// prefixLogger prefixes the messages with the fields (text format)
+type prefixLogger struct {
+	LoggerInterface
+	prefix string
+}
+
+// withField will add another field to the prefix
+func (l *prefixLogger) withField(key string, value interface{}) LoggerInterface {
+	return &prefixLogger{LoggerInterface: l.LoggerInterface, prefix: l.prefix + fmt.Sprintf("%s=%v ", key, value)}
+}
+
+// Debugf will log the debug message with the fields
+func (l *prefixLogger) Debugf(msg string, args ...interface{}) {
+	l.LoggerInterface.Debugf(l.prefix+msg, args...)
+}
+
+// Errorf will log the error message with the fields
+func (l *prefixLogger) Errorf(msg string, args ...interface{}) {
+	l.LoggerInterface.Errorf(l.prefix+msg, args...)
+}
+
+// Infof will log the info message with the fields
+func (l *prefixLogger) Infof(msg string, args ...interface{}) {
+	l.LoggerInterface.Infof(l.prefix+msg, args...)
+}
+
+// Warnf will log the warning message with the fields
+func (l *prefixLogger) Warnf(msg string, args ...interface{}) {
+	l.LoggerInterface.Warnf(l.prefix+msg, args...)
+}
+
+// JSONLogger writes one JSON object per log (time, level, msg and the structured fields)
+type JSONLogger struct {
+	fields   map[string]interface{}
+	levels   *logLevels
+	logLevel int
+	mu       *sync.Mutex
+	out      io.Writer
+	writer   io.WriteCloser
+}
+
+// NewJSONLogger will create a new JSON logger writing to the writer with the log level (and per-module overrides)
+func NewJSONLogger(writer io.WriteCloser, level int, moduleLevels map[string]int) *JSONLogger {
+	return &JSONLogger{
+		fields:   make(map[string]interface{}),
+		levels:   &logLevels{level: level, modules: moduleLevels},
+		logLevel: level,
+		mu:       new(sync.Mutex),
+		out:      writer,
+		writer:   writer,
+	}
+}
+
+// withField will return a copy of the logger with the field (and the module log level)
+func (l *JSONLogger) withField(key string, value interface{}) LoggerInterface {
+	fields := make(map[string]interface{}, len(l.fields)+1)
+	for k, v := range l.fields {
+		fields[k] = v
+	}
+	fields[key] = value
+	logLevel := l.logLevel
+	if key == LogFieldModule {
+		logLevel = l.levels.forModule(fmt.Sprint(value), l.logLevel)
+	}
+	return &JSONLogger{fields: fields, levels: l.levels, logLevel: logLevel, mu: l.mu, out: l.out, writer: l.writer}
+}
+
+// write will write the log entry (if the level is logged, fatal and panic are always logged)
+func (l *JSONLogger) write(level, msg string) {
+	if min, ok := logLevelNames[level]; ok && min < l.logLevel {
+		return
+	}
+	entry := make(map[string]interface{}, len(l.fields)+3)
+	for k, v := range l.fields {
+		entry[k] = v
+	}
+	entry["level"] = level
+	entry["msg"] = msg
+	entry["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+	b, err := json.Marshal(entry)
+	if err != nil {
+		b, _ = json.Marshal(map[string]string{"level": logLevelError, "msg": "failed to encode log: " + err.Error()})
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if lw, ok := l.out.(levelWriter); ok {
+		_, _ = lw.writeLevel(level, b)
+		return
+	}
+	_, _ = l.out.Write(append(b, '\n'))
+}
+
+// CloseWriter close the log writer
+func (l *JSONLogger) CloseWriter() error {
+	if l.writer == nil {
+		return nil
+	}
+	return l.writer.Close()
+}
+
+// Debug will log a debug message
+func (l *JSONLogger) Debug(args ...interface{}) {
+	l.write(logLevelDebug, fmt.Sprint(args...))
+}
+
+// Debugf will log a debug message
+func (l *JSONLogger) Debugf(msg string, args ...interface{}) {
+	l.write(logLevelDebug, fmt.Sprintf(msg, args...))
+}
+
+// Error will log an error message
+func (l *JSONLogger) Error(args ...interface{}) {
+	l.write(logLevelError, fmt.Sprint(args...))
+}
+
+// ErrorWithStack will log an error message
+func (l *JSONLogger) ErrorWithStack(msg string, args ...interface{}) {
+	l.write(logLevelError, fmt.Sprintf(msg, args...))
+}
+
+// Errorf will log an error message
+func (l *JSONLogger) Errorf(msg string, args ...interface{}) {
+	l.write(logLevelError, fmt.Sprintf(msg, args...))
+}
+
+// Fatal will log a fatal message and exit
+func (l *JSONLogger) Fatal(args ...interface{}) {
+	l.write(logLevelFatal, fmt.Sprint(args...))
+	os.Exit(1)
+}
+
+// Fatalf will log a fatal message and exit
+func (l *JSONLogger) Fatalf(msg string, args ...interface{}) {
+	l.write(logLevelFatal, fmt.Sprintf(msg, args...))
+	os.Exit(1)
+}
+
+// Info will log an info message
+func (l *JSONLogger) Info(args ...interface{}) {
+	l.write(logLevelInfo, fmt.Sprint(args...))
+}
+
+// Infof will log an info message
+func (l *JSONLogger) Infof(msg string, args ...interface{}) {
+	l.write(logLevelInfo, fmt.Sprintf(msg, args...))
+}
+
+// LogLevel returns the logging level
+func (l *JSONLogger) LogLevel() int {
+	return l.logLevel
+}
+
+// Panic will log a panic message and panic
+func (l *JSONLogger) Panic(args ...interface{}) {
+	msg := fmt.Sprint(args...)
+	l.write(logLevelPanic, msg)
+	panic(msg)
+}
+
+// Panicf will log a panic message and panic
+func (l *JSONLogger) Panicf(msg string, args ...interface{}) {
+	msg = fmt.Sprintf(msg, args...)
+	l.write(logLevelPanic, msg)
+	panic(msg)
+}
+
+// Printf will log an info message (used by go-api-router)
+func (l *JSONLogger) Printf(format string, v ...interface{}) {
+	l.write(logLevelInfo, fmt.Sprintf(format, v...))
+}
+
+// Warn will log a warning message
+func (l *JSONLogger) Warn(args ...interface{}) {
+	l.write(logLevelWarn, fmt.Sprint(args...))
+}
+
+// Warnf will log a warning message
+func (l *JSONLogger) Warnf(msg string, args ...interface{}) {
+	l.write(logLevelWarn, fmt.Sprintf(msg, args...))
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestJSONLogger will create a JSON logger writing to the buffer
+func newTestJSONLogger(buf *bytes.Buffer) *JSONLogger {
+	return &JSONLogger{fields: make(map[string]interface{}), mu: new(sync.Mutex), out: buf}
+}
+
+// TestJSONLogger will test the JSON logger output
+func TestJSONLogger(t *testing.T) {
+	t.Run("fields are written", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		logger := WithField(WithField(newTestJSONLogger(buf), LogFieldModule, "p2p"), LogFieldAlertSequence, uint32(7))
+		logger.Errorf("failed to read alert %d", 7)
+
+		entry := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		assert.Equal(t, "error", entry["level"])
+		assert.Equal(t, "failed to read alert 7", entry["msg"])
+		assert.Equal(t, "p2p", entry[LogFieldModule])
+		assert.Equal(t, float64(7), entry[LogFieldAlertSequence])
+		assert.NotEmpty(t, entry["time"])
+	})
+
+	t.Run("one entry per line", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		logger := newTestJSONLogger(buf)
+		logger.Info("one")
+		logger.Warnf("two %s", "lines")
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[1], `"level":"warn"`)
+	})
+
+	t.Run("fields do not leak to the parent logger", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		parent := newTestJSONLogger(buf)
+		_ = WithField(parent, LogFieldPeerID, "12D3KooW")
+		parent.Info("no fields")
+		assert.NotContains(t, buf.String(), LogFieldPeerID)
+	})
+}
+
+// TestWithField will test the method WithField() with the text logger
+func TestWithField(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := WithField(&ExtendedLogger{Logger: log.New(buf, "", 0)}, LogFieldRequestID, "abc-123")
+	logger = WithField(logger, LogFieldPeerID, "12D3KooW")
+	logger.Warnf("peer %s", "banned")
+	assert.Equal(t, "request_id=abc-123 peer_id=12D3KooW peer banned\n", buf.String())
+}
+
+// TestLogLevels will test the log level and module overrides
+func TestLogLevels(t *testing.T) {
+	t.Run("text logger", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		logger := &ExtendedLogger{
+			Logger:   log.New(buf, "", 0),
+			levels:   &logLevels{level: LogLevelWarn, modules: map[string]int{"p2p": LogLevelDebug}},
+			logLevel: LogLevelWarn,
+		}
+		logger.Info("hidden")
+		logger.Warn("shown")
+		WithField(logger, LogFieldModule, "webserver").Info("hidden")
+		WithField(logger, LogFieldModule, "p2p").Debug("debug")
+		assert.Equal(t, "shown\nmodule=p2p debug\n", buf.String())
+	})
+
+	t.Run("json logger", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		logger := newTestJSONLogger(buf)
+		logger.logLevel = LogLevelError
+		logger.levels = &logLevels{level: LogLevelError, modules: map[string]int{"webhook": LogLevelInfo}}
+		logger.Warn("hidden")
+		WithField(logger, LogFieldModule, "webhook").Info("shown")
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 1)
+		assert.Contains(t, lines[0], `"msg":"shown"`)
+	})
+}
+
+// TestParseLogLevel will test the method ParseLogLevel()
+func TestParseLogLevel(t *testing.T) {
+	level, err := ParseLogLevel("DEBUG")
+	require.NoError(t, err)
+	assert.Equal(t, LogLevelDebug, level)
+
+	level, err = ParseLogLevel("warning")
+	require.NoError(t, err)
+	assert.Equal(t, LogLevelWarn, level)
+
+	_, err = ParseLogLevel("verbose")
+	require.ErrorIs(t, err, ErrInvalidLogLevel)
+
+	_, err = parseModuleLogLevels(map[string]string{"p2p": "loud"})
+	require.ErrorIs(t, err, ErrInvalidLogLevel)
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/reporting"
+)
+
+// reportingLogger reports the error (and fatal/panic) logs with the structured fields as tags
+type reportingLogger struct {
+	LoggerInterface
+	reporter reporting.Reporter
+	tags     map[string]string
+}
+
+// newReportingLogger will wrap the logger to report the error logs to the reporter
+func newReportingLogger(logger LoggerInterface, reporter reporting.Reporter) LoggerInterface {
+	return &reportingLogger{LoggerInterface: logger, reporter: reporter, tags: make(map[string]string)}
+}
+
+// withField will add the field to the logger and the reported tags
+func (l *reportingLogger) withField(key string, value interface{}) LoggerInterface {
+	tags := make(map[string]string, len(l.tags)+1)
+	for k, v := range l.tags {
+		tags[k] = v
+	}
+	tags[key] = fmt.Sprint(value)
+	return &reportingLogger{LoggerInterface: WithField(l.LoggerInterface, key, value), reporter: l.reporter, tags: tags}
+}
+
+// capture will report the log message
+func (l *reportingLogger) capture(level, message string, stack bool) {
+	event := &reporting.Event{
+		Level:     level,
+		Message:   message,
+		Tags:      l.tags,
+		Timestamp: time.Now().UTC(),
+	}
+	if stack {
+		event.Stack = string(debug.Stack())
+	}
+	l.reporter.Capture(event)
+}
+
+// Error will log and report the error
+func (l *reportingLogger) Error(args ...interface{}) {
+	l.capture(reporting.LevelError, fmt.Sprint(args...), false)
+	l.LoggerInterface.Error(args...)
+}
+
+// Errorf will log and report the error
+func (l *reportingLogger) Errorf(msg string, args ...interface{}) {
+	l.capture(reporting.LevelError, fmt.Sprintf(msg, args...), false)
+	l.LoggerInterface.Errorf(msg, args...)
+}
+
+// ErrorWithStack will log and report the error (with the stack trace)
+func (l *reportingLogger) ErrorWithStack(msg string, args ...interface{}) {
+	l.capture(reporting.LevelError, fmt.Sprintf(msg, args...), true)
+	l.LoggerInterface.ErrorWithStack(msg, args...)
+}
+
+// Fatal will report the error (waiting for it to be sent) and then log and exit
+func (l *reportingLogger) Fatal(args ...interface{}) {
+	l.capture(reporting.LevelFatal, fmt.Sprint(args...), true)
+	reporting.Flush(l.reporter)
+	l.LoggerInterface.Fatal(args...)
+}
+
+// Fatalf will report the error (waiting for it to be sent) and then log and exit
+func (l *reportingLogger) Fatalf(msg string, args ...interface{}) {
+	l.capture(reporting.LevelFatal, fmt.Sprintf(msg, args...), true)
+	reporting.Flush(l.reporter)
+	l.LoggerInterface.Fatalf(msg, args...)
+}
+
+// Panic will report the error (waiting for it to be sent) and then log and panic
+func (l *reportingLogger) Panic(args ...interface{}) {
+	l.capture(reporting.LevelFatal, fmt.Sprint(args...), true)
+	reporting.Flush(l.reporter)
+	l.LoggerInterface.Panic(args...)
+}
+
+// Panicf will report the error (waiting for it to be sent) and then log and panic
+func (l *reportingLogger) Panicf(msg string, args ...interface{}) {
+	l.capture(reporting.LevelFatal, fmt.Sprintf(msg, args...), true)
+	reporting.Flush(l.reporter)
+	l.LoggerInterface.Panicf(msg, args...)
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/reporting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testReporter records the captured events
+type testReporter struct {
+	events []*reporting.Event
+}
+
+// Capture will record the event
+func (r *testReporter) Capture(event *reporting.Event) {
+	r.events = append(r.events, event)
+}
+
+// Flush does nothing (events are recorded immediately)
+func (r *testReporter) Flush(_ context.Context) error {
+	return nil
+}
+
+// TestReportingLogger will test reporting the error logs
+func TestReportingLogger(t *testing.T) {
+	t.Run("errors are reported with the fields", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		reporter := &testReporter{}
+		logger := newReportingLogger(newTestJSONLogger(buf), reporter)
+		logger = WithField(WithField(logger, LogFieldPeerID, "peer"), LogFieldAlertSequence, uint32(7))
+		logger.Errorf("failed to process alert %d", 7)
+
+		require.Len(t, reporter.events, 1)
+		assert.Equal(t, reporting.LevelError, reporter.events[0].Level)
+		assert.Equal(t, "failed to process alert 7", reporter.events[0].Message)
+		assert.Equal(t, map[string]string{LogFieldPeerID: "peer", LogFieldAlertSequence: "7"}, reporter.events[0].Tags)
+		assert.Contains(t, buf.String(), "failed to process alert 7")
+	})
+
+	t.Run("other levels are not reported", func(t *testing.T) {
+		reporter := &testReporter{}
+		logger := newReportingLogger(newTestJSONLogger(new(bytes.Buffer)), reporter)
+		logger.Info("info")
+		logger.Warnf("warn %d", 1)
+		logger.Debug("debug")
+		assert.Empty(t, reporter.events)
+	})
+
+	t.Run("panics are reported with the stack", func(t *testing.T) {
+		reporter := &testReporter{}
+		logger := newReportingLogger(newTestJSONLogger(new(bytes.Buffer)), reporter)
+		assert.Panics(t, func() {
+			logger.Panicf("bad state %s", "here")
+		})
+		require.Len(t, reporter.events, 1)
+		assert.Equal(t, reporting.LevelFatal, reporter.events[0].Level)
+		assert.NotEmpty(t, reporter.events[0].Stack)
+	})
+}
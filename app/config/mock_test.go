@@ -2,8 +2,10 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/bitcoin-sv/alert-system/app/config/mocks"
 	"github.com/stretchr/testify/require"
@@ -59,3 +61,74 @@ func TestInvalidateBlock(t *testing.T) {
 	err := mockNode.InvalidateBlock(ctx, "expected_hash")
 	require.NoError(t, err)
 }
+
+// TestNode_On tests the scripted responses are returned in order, then the Func
+func TestNode_On(t *testing.T) {
+	mockNode := &mocks.Node{
+		InvalidateBlockFunc: func(_ context.Context, _ string) error {
+			return nil
+		},
+	}
+	errNode := errors.New("node unavailable")
+	mockNode.On(mocks.MethodInvalidateBlock, mocks.Response{Err: errNode}, mocks.Response{Err: errNode})
+	mockNode.On(mocks.MethodBestBlockHash, mocks.Response{Result: "scripted_hash"})
+
+	ctx := context.Background()
+	require.ErrorIs(t, mockNode.InvalidateBlock(ctx, "hash"), errNode)
+	require.ErrorIs(t, mockNode.InvalidateBlock(ctx, "hash"), errNode)
+	require.NoError(t, mockNode.InvalidateBlock(ctx, "hash"))
+
+	hash, err := mockNode.BestBlockHash(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "scripted_hash", hash)
+}
+
+// TestNode_SetLatency tests the latency of the calls (the context error if it is done first)
+func TestNode_SetLatency(t *testing.T) {
+	mockNode := (&mocks.Node{}).SetLatency(mocks.MethodBanPeer, 20*time.Millisecond)
+
+	start := time.Now()
+	require.NoError(t, mockNode.BanPeer(context.Background(), "peer"))
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	mockNode.On(mocks.MethodBanPeer, mocks.Response{Delay: time.Minute})
+	require.ErrorIs(t, mockNode.BanPeer(ctx, "peer"), context.DeadlineExceeded)
+}
+
+// TestNode_Calls tests the calls are recorded (and cleared by Reset)
+func TestNode_Calls(t *testing.T) {
+	mockNode := &mocks.Node{}
+	ctx := context.Background()
+	require.NoError(t, mockNode.BanPeer(ctx, "peer_1"))
+	require.NoError(t, mockNode.UnbanPeer(ctx, "peer_1"))
+	require.NoError(t, mockNode.BanPeer(ctx, "peer_2"))
+
+	calls := mockNode.Calls(mocks.MethodBanPeer)
+	require.Len(t, calls, 2)
+	require.Equal(t, []interface{}{"peer_2"}, calls[1].Args)
+	require.Len(t, mockNode.Calls(""), 3)
+
+	mockNode.On(mocks.MethodBanPeer, mocks.Response{Err: errors.New("failed")})
+	mockNode.Reset()
+	require.Empty(t, mockNode.Calls(""))
+	require.NoError(t, mockNode.BanPeer(ctx, "peer_1"))
+}
+
+// TestNodeMockConfig_script tests the node mock of the local dev mode is scripted from the config
+func TestNodeMockConfig_script(t *testing.T) {
+	mockNode := &mocks.Node{}
+	NodeMockConfig{
+		Enabled: true,
+		Methods: map[string][]NodeMockResponse{
+			mocks.MethodInvalidateBlock: {{Error: "timeout"}, {}},
+		},
+	}.script(mockNode)
+
+	ctx := context.Background()
+	require.EqualError(t, mockNode.InvalidateBlock(ctx, "hash"), "timeout")
+	require.NoError(t, mockNode.InvalidateBlock(ctx, "hash"))
+	require.True(t, mocks.IsMethod(mocks.MethodInvalidateBlock))
+	require.False(t, mocks.IsMethod("invalidateblock"))
+}
@@ -8,6 +8,8 @@ import (
 )
 
 // Node is a mock type for the SVNode interface
+// Each method returns its next scripted response (see On), otherwise it calls its Func if set, otherwise it returns
+// its default. Every call is recorded (see Calls), the latencies delay the calls (see SetLatency)
 type Node struct {
 	// Fields
 	RPCHost     string
@@ -17,11 +19,18 @@ type Node struct {
 	// Functions
 	BanPeerFunc                               func(ctx context.Context, peer string) error
 	BestBlockHashFunc                         func(ctx context.Context) (string, error)
+	BlockCountFunc                            func(ctx context.Context) (uint32, error)
+	InActiveChainFunc                         func(ctx context.Context, hash string) (bool, error)
 	InvalidateBlockFunc                       func(ctx context.Context, hash string) error
+	ListBannedFunc                            func(ctx context.Context) ([]*models.BannedSubnet, error)
+	NetworkInfoFunc                           func(ctx context.Context) (*models.NetworkInfo, error)
+	QueryBlacklistedFundsFunc                 func(ctx context.Context) ([]models.Fund, error)
 	UnbanPeerFunc                             func(ctx context.Context, peer string) error
 	AddToConsensusBlacklistFunc               func(ctx context.Context, funds []models.Fund) (*models.AddToConsensusBlacklistResponse, error)
 	AddToConfiscationTransactionWhitelistFunc func(ctx context.Context, tx []models.ConfiscationTransactionDetails) (*models.AddToConfiscationTransactionWhitelistResponse, error)
-	// Add additional fields if needed to track calls or results
+
+	// Scripted responses, latencies and recorded calls (see On, SetLatency and Calls)
+	script script
 }
 
 // GetRPCUser will return the RPCUser
@@ -41,6 +50,9 @@ func (n *Node) GetRPCHost() string {
 
 // BanPeer will call the BanPeerFunc if not nil, otherwise return nil
 func (n *Node) BanPeer(ctx context.Context, peer string) error {
+	if r, ok, err := n.next(ctx, MethodBanPeer, peer); err != nil || ok {
+		return firstError(err, r.Err)
+	}
 	if n.BanPeerFunc != nil {
 		return n.BanPeerFunc(ctx, peer)
 	}
@@ -50,22 +62,104 @@ func (n *Node) BanPeer(ctx context.Context, peer string) error {
 
 // BestBlockHash will call the BestBlockHashFunc
 func (n *Node) BestBlockHash(ctx context.Context) (string, error) {
+	if r, ok, err := n.next(ctx, MethodBestBlockHash); err != nil {
+		return "", err
+	} else if ok {
+		result, _ := r.Result.(string)
+		return result, r.Err
+	}
 	if n.BestBlockHashFunc != nil {
 		return n.BestBlockHashFunc(ctx)
 	}
 	return "", nil
 }
 
+// BlockCount will call the BlockCountFunc
+func (n *Node) BlockCount(ctx context.Context) (uint32, error) {
+	if r, ok, err := n.next(ctx, MethodBlockCount); err != nil {
+		return 0, err
+	} else if ok {
+		result, _ := r.Result.(uint32)
+		return result, r.Err
+	}
+	if n.BlockCountFunc != nil {
+		return n.BlockCountFunc(ctx)
+	}
+	return 0, nil
+}
+
+// InActiveChain will call the InActiveChainFunc if not nil, otherwise return true
+func (n *Node) InActiveChain(ctx context.Context, hash string) (bool, error) {
+	if r, ok, err := n.next(ctx, MethodInActiveChain, hash); err != nil {
+		return false, err
+	} else if ok {
+		result, _ := r.Result.(bool)
+		return result, r.Err
+	}
+	if n.InActiveChainFunc != nil {
+		return n.InActiveChainFunc(ctx, hash)
+	}
+	return true, nil
+}
+
 // InvalidateBlock will call the InvalidateBlockFunc if not nil, otherwise return nil
 func (n *Node) InvalidateBlock(ctx context.Context, hash string) error {
+	if r, ok, err := n.next(ctx, MethodInvalidateBlock, hash); err != nil || ok {
+		return firstError(err, r.Err)
+	}
 	if n.InvalidateBlockFunc != nil {
 		return n.InvalidateBlockFunc(ctx, hash)
 	}
 	return nil
 }
 
+// ListBanned will call the ListBannedFunc if not nil, otherwise return nil
+func (n *Node) ListBanned(ctx context.Context) ([]*models.BannedSubnet, error) {
+	if r, ok, err := n.next(ctx, MethodListBanned); err != nil {
+		return nil, err
+	} else if ok {
+		result, _ := r.Result.([]*models.BannedSubnet)
+		return result, r.Err
+	}
+	if n.ListBannedFunc != nil {
+		return n.ListBannedFunc(ctx)
+	}
+	return nil, nil
+}
+
+// NetworkInfo will call the NetworkInfoFunc if not nil, otherwise return nil
+func (n *Node) NetworkInfo(ctx context.Context) (*models.NetworkInfo, error) {
+	if r, ok, err := n.next(ctx, MethodNetworkInfo); err != nil {
+		return nil, err
+	} else if ok {
+		result, _ := r.Result.(*models.NetworkInfo)
+		return result, r.Err
+	}
+	if n.NetworkInfoFunc != nil {
+		return n.NetworkInfoFunc(ctx)
+	}
+	return nil, nil
+}
+
+// QueryBlacklistedFunds will call the QueryBlacklistedFundsFunc if not nil, otherwise return nil
+func (n *Node) QueryBlacklistedFunds(ctx context.Context) ([]models.Fund, error) {
+	if r, ok, err := n.next(ctx, MethodQueryBlacklistedFunds); err != nil {
+		return nil, err
+	} else if ok {
+		result, _ := r.Result.([]models.Fund)
+		return result, r.Err
+	}
+	if n.QueryBlacklistedFundsFunc != nil {
+		return n.QueryBlacklistedFundsFunc(ctx)
+	}
+	return nil, nil
+}
+
 // UnbanPeer will call the UnbanPeerFunc if not nil, otherwise return nil
 func (n *Node) UnbanPeer(ctx context.Context, peer string) error {
+	if r, ok, err := n.next(ctx, MethodUnbanPeer, peer); err != nil || ok {
+		return firstError(err, r.Err)
+	}
 	if n.UnbanPeerFunc != nil {
 		return n.UnbanPeerFunc(ctx, peer)
 	}
@@ -74,6 +168,12 @@ func (n *Node) UnbanPeer(ctx context.Context, peer string) error {
 
 // AddToConsensusBlacklist will call the AddToConsensusBlacklistFunc if not nil, otherwise return nil
 func (n *Node) AddToConsensusBlacklist(ctx context.Context, funds []models.Fund) (*models.AddToConsensusBlacklistResponse, error) {
+	if r, ok, err := n.next(ctx, MethodAddToConsensusBlacklist, funds); err != nil {
+		return nil, err
+	} else if ok {
+		result, _ := r.Result.(*models.AddToConsensusBlacklistResponse)
+		return result, r.Err
+	}
 	if n.AddToConsensusBlacklistFunc != nil {
 		return n.AddToConsensusBlacklistFunc(ctx, funds)
 	}
@@ -82,8 +182,24 @@ func (n *Node) AddToConsensusBlacklist(ctx context.Context, funds []models.Fund)
 
 // AddToConfiscationTransactionWhitelist will call the AddToConfiscationTransactionWhitelistFunc if not nil, otherwise return nil
 func (n *Node) AddToConfiscationTransactionWhitelist(ctx context.Context, tx []models.ConfiscationTransactionDetails) (*models.AddToConfiscationTransactionWhitelistResponse, error) {
+	if r, ok, err := n.next(ctx, MethodAddToConfiscationTransactionWhitelist, tx); err != nil {
+		return nil, err
+	} else if ok {
+		result, _ := r.Result.(*models.AddToConfiscationTransactionWhitelistResponse)
+		return result, r.Err
+	}
 	if n.AddToConfiscationTransactionWhitelistFunc != nil {
 		return n.AddToConfiscationTransactionWhitelistFunc(ctx, tx)
 	}
 	return nil, nil
 }
+
+// firstError will return the first error that is not nil
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
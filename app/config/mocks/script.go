@@ -0,0 +1,145 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Methods of the node (the names used to script the responses and in the recorded calls)
+const (
+	MethodAddToConfiscationTransactionWhitelist = "add_to_confiscation_transaction_whitelist"
+	MethodAddToConsensusBlacklist               = "add_to_consensus_blacklist"
+	MethodBanPeer                               = "ban_peer"
+	MethodBestBlockHash                         = "best_block_hash"
+	MethodBlockCount                            = "block_count"
+	MethodInActiveChain                         = "in_active_chain"
+	MethodInvalidateBlock                       = "invalidate_block"
+	MethodListBanned                            = "list_banned"
+	MethodNetworkInfo                           = "network_info"
+	MethodQueryBlacklistedFunds                 = "query_blacklisted_funds"
+	MethodUnbanPeer                             = "unban_peer"
+)
+
+// methods are the methods of the node (see IsMethod)
+var methods = []string{
+	MethodAddToConfiscationTransactionWhitelist,
+	MethodAddToConsensusBlacklist,
+	MethodBanPeer,
+	MethodBestBlockHash,
+	MethodBlockCount,
+	MethodInActiveChain,
+	MethodInvalidateBlock,
+	MethodListBanned,
+	MethodNetworkInfo,
+	MethodQueryBlacklistedFunds,
+	MethodUnbanPeer,
+}
+
+// Methods will return the methods of the node
+func Methods() []string {
+	return append([]string(nil), methods...)
+}
+
+// IsMethod will return true if the method is a method of the node (e.g. invalidate_block)
+func IsMethod(method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// Response is a scripted response of a node method
+type Response struct {
+	Delay  time.Duration // Time before the method returns (the context error if it is done first)
+	Err    error         // Error returned by the method
+	Result interface{}   // Result of the method (its type, e.g. string for best_block_hash, nil for the zero value)
+}
+
+// Call is a call of a node method recorded by the mock
+type Call struct {
+	Args   []interface{} // Arguments after the context
+	At     time.Time
+	Method string
+}
+
+// script is the scripted responses and the recorded calls of the mock
+type script struct {
+	calls     []Call
+	latencies map[string]time.Duration
+	mu        sync.Mutex
+	responses map[string][]Response
+}
+
+// On will script the responses of the method, returned in order by its next calls (failure sequences, e.g. two
+// errors then a success). Once they are used the method calls its Func (or returns its default)
+func (n *Node) On(method string, responses ...Response) *Node {
+	n.script.mu.Lock()
+	defer n.script.mu.Unlock()
+	if n.script.responses == nil {
+		n.script.responses = make(map[string][]Response)
+	}
+	n.script.responses[method] = append(n.script.responses[method], responses...)
+	return n
+}
+
+// SetLatency will delay every call of the method (added to the delay of a scripted response)
+func (n *Node) SetLatency(method string, latency time.Duration) *Node {
+	n.script.mu.Lock()
+	defer n.script.mu.Unlock()
+	if n.script.latencies == nil {
+		n.script.latencies = make(map[string]time.Duration)
+	}
+	n.script.latencies[method] = latency
+	return n
+}
+
+// Calls will return the recorded calls (of the method, all the methods if empty)
+func (n *Node) Calls(method string) []Call {
+	n.script.mu.Lock()
+	defer n.script.mu.Unlock()
+	calls := make([]Call, 0, len(n.script.calls))
+	for _, call := range n.script.calls {
+		if len(method) == 0 || call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset will clear the scripted responses, the latencies and the recorded calls
+func (n *Node) Reset() {
+	n.script.mu.Lock()
+	defer n.script.mu.Unlock()
+	n.script.calls = nil
+	n.script.latencies = nil
+	n.script.responses = nil
+}
+
+// next will record the call and wait for its latency, returns the next scripted response of the method (false if
+// none is left) or the context error if it is done while waiting
+func (n *Node) next(ctx context.Context, method string, args ...interface{}) (Response, bool, error) {
+	n.script.mu.Lock()
+	n.script.calls = append(n.script.calls, Call{Args: args, At: time.Now(), Method: method})
+	delay := n.script.latencies[method]
+	response, ok := Response{}, len(n.script.responses[method]) > 0
+	if ok {
+		response = n.script.responses[method][0]
+		n.script.responses[method] = n.script.responses[method][1:]
+		delay += response.Delay
+	}
+	n.script.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return Response{}, false, ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return response, ok, nil
+}
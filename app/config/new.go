@@ -0,0 +1,163 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mrz1836/go-datastore"
+)
+
+// Options allow functional options to be supplied to New (the settings of the config built in code)
+type Options func(c *Config)
+
+// network is the network settings of an embedded environment file (see WithEnvironment)
+type network struct {
+	GenesisKeys []string `json:"genesis_keys"`
+	P2P         struct {
+		AlertSystemProtocolID string `json:"alert_system_protocol_id"`
+		BootstrapPeer         string `json:"bootstrap_peer"`
+		TopicName             string `json:"topic_name"`
+	} `json:"p2p"`
+}
+
+// New will build a valid config in code, without the environment variables, the config files or the global viper
+// state (tests and embedders can build many configs concurrently), then LoadServices loads its services
+//
+// The network settings left empty (genesis keys, P2P protocol ID, topic and bootstrap peer) are the ones of the
+// environment (mainnet unless WithEnvironment is set). The datastore is an in-memory SQLite unless
+// WithSQLite or WithDatastore is set (the alerts are synced from the peers again at each start)
+func New(opts ...Options) (*Config, error) {
+	c := newConfig()
+	c.Environment = EnvironmentMainnet
+	c.Datastore.AutoMigrate = true
+	c.Datastore.Engine = datastore.SQLite
+	c.Datastore.TablePrefix = DatabasePrefix
+	c.P2P.IP = DefaultP2PIP
+	c.P2P.Port = DefaultP2PPort
+	c.WebServer.Port = DefaultServerPort
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// Fill the network settings of the environment
+	c.Environment = strings.ToLower(c.Environment)
+	if err := c.loadNetwork(); err != nil {
+		return nil, err
+	}
+
+	// Set the defaults and check the settings (the same as a config file)
+	if err := c.prepare(); err != nil {
+		return nil, err
+	}
+	if err := c.validate(true); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// loadNetwork will set the network settings left empty to the ones of the embedded environment file
+func (c *Config) loadNetwork() error {
+	file, err := EnvironmentFile(c.Environment)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, c.Environment)
+	}
+	var n network
+	if err = json.Unmarshal(file, &n); err != nil {
+		return err
+	}
+	if len(c.GenesisKeys) == 0 && !c.Devnet {
+		c.GenesisKeys = n.GenesisKeys
+	}
+	if len(c.P2P.AlertSystemProtocolID) == 0 {
+		c.P2P.AlertSystemProtocolID = n.P2P.AlertSystemProtocolID
+	}
+	if len(c.P2P.BootstrapPeer) == 0 {
+		c.P2P.BootstrapPeer = n.P2P.BootstrapPeer
+	}
+	if len(c.P2P.TopicName) == 0 {
+		c.P2P.TopicName = n.P2P.TopicName
+	}
+	return nil
+}
+
+// WithEnvironment will set the environment (the network of the default genesis keys and P2P topic)
+func WithEnvironment(environment string) Options {
+	return func(c *Config) {
+		c.Environment = environment
+	}
+}
+
+// WithGenesisKeys will set the public keys of the genesis alert (instead of the keys of the environment)
+func WithGenesisKeys(keys ...string) Options {
+	return func(c *Config) {
+		c.GenesisKeys = keys
+	}
+}
+
+// WithRPCConnection will add a node RPC connection (the alerts are executed against all the connections)
+func WithRPCConnection(host, user, password string) Options {
+	return func(c *Config) {
+		c.RPCConnections = append(c.RPCConnections, RPCConfig{Host: host, Password: password, User: user})
+	}
+}
+
+// WithNodeMock will replace the nodes with the scripted node mock (tests and local development)
+func WithNodeMock() Options {
+	return func(c *Config) {
+		c.NodeMock.Enabled = true
+	}
+}
+
+// WithSQLite will use a SQLite datastore ("" for in memory)
+func WithSQLite(databasePath string) Options {
+	return func(c *Config) {
+		c.Datastore.Engine = datastore.SQLite
+		c.Datastore.SQLite = &datastore.SQLiteConfig{DatabasePath: databasePath}
+	}
+}
+
+// WithDatastore will set the datastore (e.g. MySQL or Postgres, with its sql_read and sql_write connections)
+func WithDatastore(datastoreConfig DatastoreConfig) Options {
+	return func(c *Config) {
+		if datastoreConfig.SQLite == nil {
+			datastoreConfig.SQLite = &datastore.SQLiteConfig{}
+		}
+		if datastoreConfig.SQLRead == nil {
+			datastoreConfig.SQLRead = &datastore.SQLConfig{}
+		}
+		if datastoreConfig.SQLWrite == nil {
+			datastoreConfig.SQLWrite = &datastore.SQLConfig{}
+		}
+		c.Datastore = datastoreConfig
+	}
+}
+
+// WithP2P will set the P2P listen address and port
+func WithP2P(ip, port string) Options {
+	return func(c *Config) {
+		c.P2P.IP = ip
+		c.P2P.Port = port
+	}
+}
+
+// WithPrivateKeyPath will set the path of the P2P private key (created if missing)
+func WithPrivateKeyPath(path string) Options {
+	return func(c *Config) {
+		c.P2P.PrivateKeyPath = path
+	}
+}
+
+// WithWebServerPort will set the port of the web server
+func WithWebServerPort(port string) Options {
+	return func(c *Config) {
+		c.WebServer.Port = port
+	}
+}
+
+// WithLogLevel will set the min log level (debug, info, warn or error)
+func WithLogLevel(level string) Options {
+	return func(c *Config) {
+		c.LogLevel = level
+	}
+}
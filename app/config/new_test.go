@@ -0,0 +1,90 @@
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/config/mocks"
+	"github.com/mrz1836/go-datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testGenesisKey is a genesis key set by the options
+const testGenesisKey = "02a1589f2c8e1a4e7cbf28d4d6b676aa2f30811277883211027950e82a83eb2768"
+
+// TestNew will test the config built in code has the defaults of the network
+func TestNew(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "key")
+	c, err := New(WithRPCConnection("http://localhost:8332", "user", "pass"), WithPrivateKeyPath(keyPath))
+	require.NoError(t, err)
+
+	assert.Equal(t, EnvironmentMainnet, c.Environment)
+	assert.Len(t, c.GenesisKeys, 5)
+	assert.Equal(t, "/bitcoin/alert-system/1.0.0", c.P2P.AlertSystemProtocolID)
+	assert.Equal(t, "bitcoin_alert_system", c.P2P.TopicName)
+	assert.Equal(t, DefaultP2PIP, c.P2P.IP)
+	assert.Equal(t, DefaultP2PPort, c.P2P.Port)
+	assert.Equal(t, keyPath+".lock", c.Instance.LockFile)
+	assert.Equal(t, DefaultServerPort, c.WebServer.Port)
+	assert.Equal(t, DefaultServerIdleTimeout, c.WebServer.IdleTimeout)
+	assert.Equal(t, datastore.SQLite, c.Datastore.Engine)
+	assert.Empty(t, c.Datastore.SQLite.DatabasePath)
+	assert.Equal(t, []RPCConfig{{Host: "http://localhost:8332", Password: "pass", User: "user"}}, c.RPCConnections)
+	assert.NotNil(t, c.Services.Log)
+}
+
+// TestNew_Options will test the options override the defaults of the network, without the environment variables
+func TestNew_Options(t *testing.T) {
+	t.Setenv(EnvironmentKey, EnvironmentMainnet)
+	t.Setenv("ALERT_SYSTEM_P2P__PORT", "1234")
+
+	c, err := New(
+		WithEnvironment("TESTNET"),
+		WithGenesisKeys(testGenesisKey),
+		WithLogLevel(logLevelWarn),
+		WithNodeMock(),
+		WithP2P("127.0.0.1", "9999"),
+		WithPrivateKeyPath(filepath.Join(t.TempDir(), "key")),
+		WithRPCConnection("http://localhost:18332", "user", "pass"),
+		WithWebServerPort("3001"),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, EnvironmentTestnet, c.Environment)
+	assert.Equal(t, []string{testGenesisKey}, c.GenesisKeys)
+	assert.Equal(t, "bitcoin_alert_system_testnet", c.P2P.TopicName)
+	assert.Equal(t, "9999", c.P2P.Port)
+	assert.Equal(t, "3001", c.WebServer.Port)
+	assert.Equal(t, logLevelWarn, c.LogLevel)
+
+	// Load the services of the config
+	require.NoError(t, c.LoadServices(context.Background(), nil, false))
+	defer c.CloseAll(context.Background())
+	assert.IsType(t, &mocks.Node{}, c.Services.Node)
+	assert.NotNil(t, c.Services.Datastore)
+}
+
+// TestNew_Invalid will test an invalid config is not built
+func TestNew_Invalid(t *testing.T) {
+	keyPath := WithPrivateKeyPath(filepath.Join(t.TempDir(), "key"))
+	rpc := WithRPCConnection("http://localhost:8332", "user", "pass")
+
+	_, err := New(keyPath)
+	require.ErrorIs(t, err, ErrNoRPCConnections)
+
+	_, err = New(keyPath, rpc, WithEnvironment("unknown"))
+	require.ErrorIs(t, err, ErrInvalidEnvironment)
+
+	_, err = New(keyPath, rpc, WithP2P("", "9906"))
+	require.ErrorIs(t, err, ErrNoP2PIP)
+
+	_, err = New(keyPath, rpc, WithLogLevel("verbose"))
+	require.ErrorIs(t, err, ErrInvalidLogLevel)
+
+	_, err = New(keyPath, rpc, func(c *Config) {
+		c.Chaos.Enabled = true
+	})
+	require.ErrorIs(t, err, ErrChaosOnMainnet)
+}
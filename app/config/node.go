@@ -2,10 +2,14 @@ package config
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/libsv/go-bn/models"
 
 	"github.com/bitcoin-sv/alert-system/app/config/mocks"
+	"github.com/bitcoin-sv/alert-system/app/metrics"
+	"github.com/bitcoin-sv/alert-system/app/tracing"
 	"github.com/libsv/go-bn"
 )
 
@@ -13,10 +17,15 @@ import (
 type NodeInterface interface {
 	BanPeer(ctx context.Context, peer string) error
 	BestBlockHash(ctx context.Context) (string, error)
+	BlockCount(ctx context.Context) (uint32, error)
 	GetRPCHost() string
 	GetRPCPassword() string
 	GetRPCUser() string
+	InActiveChain(ctx context.Context, hash string) (bool, error)
 	InvalidateBlock(ctx context.Context, hash string) error
+	ListBanned(ctx context.Context) ([]*models.BannedSubnet, error)
+	NetworkInfo(ctx context.Context) (*models.NetworkInfo, error)
+	QueryBlacklistedFunds(ctx context.Context) ([]models.Fund, error)
 	UnbanPeer(ctx context.Context, peer string) error
 	AddToConsensusBlacklist(ctx context.Context, funds []models.Fund) (*models.AddToConsensusBlacklistResponse, error)
 	AddToConfiscationTransactionWhitelist(ctx context.Context, tx []models.ConfiscationTransactionDetails) (*models.AddToConfiscationTransactionWhitelistResponse, error)
@@ -40,6 +49,28 @@ func NewNodeMock(user, pass, host string) NodeInterface {
 	}
 }
 
+// script will script the latency and the responses of the node mock (local dev mode), nothing if it is disabled
+func (c NodeMockConfig) script(node NodeInterface) {
+	mock, ok := node.(*mocks.Node)
+	if !c.Enabled || !ok {
+		return
+	}
+	for method, responses := range c.Methods {
+		for _, response := range responses {
+			scripted := mocks.Response{Delay: response.Delay}
+			if len(response.Error) > 0 {
+				scripted.Err = errors.New(response.Error)
+			}
+			mock.On(method, scripted)
+		}
+	}
+	if c.Latency > 0 {
+		for _, method := range mocks.Methods() {
+			mock.SetLatency(method, c.Latency)
+		}
+	}
+}
+
 // GetRPCUser returns the RPC user
 func (n *Node) GetRPCUser() string {
 	return n.RPCUser
@@ -55,38 +86,140 @@ func (n *Node) GetRPCHost() string {
 	return n.RPCHost
 }
 
+// InActiveChain returns true if the block is in the active chain (false if it was invalidated, is on a fork or is unknown)
+func (n *Node) InActiveChain(ctx context.Context, hash string) (_ bool, err error) {
+	ctx, end := n.startRPC(ctx, "getblockheader")
+	defer func() {
+		end(err)
+	}()
+	var header struct {
+		Confirmations int64 `json:"confirmations"` // -1 if the block is not in the active chain
+	}
+	if err = n.call(ctx, "getblockheader", &header, hash, true); err != nil {
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) && rpcErr.Code == RPCErrorNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return header.Confirmations >= 0, nil
+}
+
 // InvalidateBlock invalidates a block
-func (n *Node) InvalidateBlock(ctx context.Context, hash string) error {
+func (n *Node) InvalidateBlock(ctx context.Context, hash string) (err error) {
+	ctx, end := n.startRPC(ctx, "invalidateblock")
+	defer func() {
+		end(err)
+	}()
 	c := bn.NewNodeClient(bn.WithCreds(n.RPCUser, n.RPCPassword), bn.WithHost(n.RPCHost))
 	return c.InvalidateBlock(ctx, hash)
 }
 
 // BanPeer bans a peer
-func (n *Node) BanPeer(ctx context.Context, peer string) error {
+func (n *Node) BanPeer(ctx context.Context, peer string) (err error) {
+	ctx, end := n.startRPC(ctx, "setban")
+	defer func() {
+		end(err)
+	}()
 	c := bn.NewNodeClient(bn.WithCreds(n.RPCUser, n.RPCPassword), bn.WithHost(n.RPCHost))
 	return c.SetBan(ctx, peer, bn.BanActionAdd, nil)
 }
 
 // BestBlockHash gets the best block hash
-func (n *Node) BestBlockHash(ctx context.Context) (string, error) {
+func (n *Node) BestBlockHash(ctx context.Context) (_ string, err error) {
+	ctx, end := n.startRPC(ctx, "getbestblockhash")
+	defer func() {
+		end(err)
+	}()
 	c := bn.NewNodeClient(bn.WithCreds(n.RPCUser, n.RPCPassword), bn.WithHost(n.RPCHost))
 	return c.BestBlockHash(ctx)
 }
 
+// BlockCount gets the current block height
+func (n *Node) BlockCount(ctx context.Context) (_ uint32, err error) {
+	ctx, end := n.startRPC(ctx, "getblockcount")
+	defer func() {
+		end(err)
+	}()
+	c := bn.NewNodeClient(bn.WithCreds(n.RPCUser, n.RPCPassword), bn.WithHost(n.RPCHost))
+	return c.BlockCount(ctx)
+}
+
+// ListBanned gets the list of banned peers (subnets)
+func (n *Node) ListBanned(ctx context.Context) (_ []*models.BannedSubnet, err error) {
+	ctx, end := n.startRPC(ctx, "listbanned")
+	defer func() {
+		end(err)
+	}()
+	c := bn.NewNodeClient(bn.WithCreds(n.RPCUser, n.RPCPassword), bn.WithHost(n.RPCHost))
+	return c.ListBanned(ctx)
+}
+
+// NetworkInfo gets the network info (version, connections, etc.)
+func (n *Node) NetworkInfo(ctx context.Context) (_ *models.NetworkInfo, err error) {
+	ctx, end := n.startRPC(ctx, "getnetworkinfo")
+	defer func() {
+		end(err)
+	}()
+	c := bn.NewNodeClient(bn.WithCreds(n.RPCUser, n.RPCPassword), bn.WithHost(n.RPCHost))
+	return c.NetworkInfo(ctx)
+}
+
+// QueryBlacklistedFunds gets the frozen funds (the consensus and policy blacklists)
+func (n *Node) QueryBlacklistedFunds(ctx context.Context) (_ []models.Fund, err error) {
+	ctx, end := n.startRPC(ctx, "queryBlacklistedFunds")
+	defer func() {
+		end(err)
+	}()
+	var result struct {
+		Funds []models.Fund `json:"funds"`
+	}
+	if err = n.call(ctx, "queryBlacklistedFunds", &result); err != nil {
+		return nil, err
+	}
+	return result.Funds, nil
+}
+
 // UnbanPeer unbans a peer
-func (n *Node) UnbanPeer(ctx context.Context, peer string) error {
+func (n *Node) UnbanPeer(ctx context.Context, peer string) (err error) {
+	ctx, end := n.startRPC(ctx, "setban")
+	defer func() {
+		end(err)
+	}()
 	c := bn.NewNodeClient(bn.WithCreds(n.RPCUser, n.RPCPassword), bn.WithHost(n.RPCHost))
 	return c.SetBan(ctx, peer, bn.BanActionRemove, nil)
 }
 
 // AddToConsensusBlacklist adds frozen utxos to blacklist
-func (n *Node) AddToConsensusBlacklist(ctx context.Context, funds []models.Fund) (*models.AddToConsensusBlacklistResponse, error) {
+func (n *Node) AddToConsensusBlacklist(ctx context.Context, funds []models.Fund) (_ *models.AddToConsensusBlacklistResponse, err error) {
+	ctx, end := n.startRPC(ctx, "addToConsensusBlacklist")
+	defer func() {
+		end(err)
+	}()
 	c := bn.NewNodeClient(bn.WithCreds(n.RPCUser, n.RPCPassword), bn.WithHost(n.RPCHost))
 	return c.AddToConsensusBlacklist(ctx, funds)
 }
 
 // AddToConfiscationTransactionWhitelist adds confiscation transactions to the whitelist
-func (n *Node) AddToConfiscationTransactionWhitelist(ctx context.Context, tx []models.ConfiscationTransactionDetails) (*models.AddToConfiscationTransactionWhitelistResponse, error) {
+func (n *Node) AddToConfiscationTransactionWhitelist(ctx context.Context, tx []models.ConfiscationTransactionDetails) (_ *models.AddToConfiscationTransactionWhitelistResponse, err error) {
+	ctx, end := n.startRPC(ctx, "addToConfiscationTxidWhitelist")
+	defer func() {
+		end(err)
+	}()
 	c := bn.NewNodeClient(bn.WithCreds(n.RPCUser, n.RPCPassword), bn.WithHost(n.RPCHost))
 	return c.AddToConfiscationTransactionWhitelist(ctx, tx)
 }
+
+// startRPC will start a span for the node RPC call, the returned func ends the span
+// and records the call metrics (called by the caller with the error)
+func (n *Node) startRPC(ctx context.Context, method string) (context.Context, func(err error)) {
+	start := time.Now()
+	ctx, span := tracing.Start(
+		ctx, tracing.SpanNodeRPC, tracing.AttrRPCMethod.String(method), tracing.AttrRPCHost.String(n.RPCHost),
+	)
+	return ctx, func(err error) {
+		metrics.ObserveRPC(ctx, n.RPCHost, method, start, err)
+		LogSlow(n.logger, n.slowRPC, start, "node rpc %s on %s", method, n.RPCHost)
+		tracing.End(span, err)
+	}
+}
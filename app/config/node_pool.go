@@ -0,0 +1,465 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/clock"
+	"github.com/libsv/go-bn/models"
+)
+
+// NodePool is the nodes of the RPC connections used as one node
+//
+// The reads are balanced between the healthy nodes (round-robin) and fail over to the next node if a node does not
+// respond, an unreachable node is skipped by the reads until the retry interval elapsed (or a health check reaches it).
+// The alert actions (ban peer, invalidate block...) are executed against all the reachable nodes. An unreachable node
+// (or one still catching up) does not fail the action: it is queued for the node, and replayed in order by the health
+// check (Run) once the node responds. The action fails if a node returns an error or no node is reachable, the alert
+// is then retried on all the nodes (the actions are idempotent). The queue is in memory, the reconcile command
+// compares a node restarted with the alerts.
+type NodePool struct {
+	clock         clock.Clock
+	logger        LoggerInterface
+	mu            sync.Mutex
+	next          int // Node of the next read (round-robin)
+	nodes         []*poolNode
+	replayed      ReplayedFunc // Called with the result of each missed action replayed (if set)
+	retryInterval time.Duration
+}
+
+// poolNode is a node of the pool and its health
+type poolNode struct {
+	catchingUp bool  // The missed actions are being replayed
+	err        error // Why the node is unreachable (nil if it is healthy)
+	missed     []missedAction
+	node       NodeInterface
+	retryAt    time.Time // When the reads try the unreachable node again
+}
+
+// nodeAction is an alert action executed against a node
+type nodeAction func(ctx context.Context, node NodeInterface) error
+
+// missedAction is an alert action queued for a node that missed it
+type missedAction struct {
+	action nodeAction
+	ref    interface{} // Reference of the action (see WithNodeActionRef)
+}
+
+// ReplayedFunc is called with the result of an alert action replayed on a node that missed it
+// (ref is the reference of the action, see WithNodeActionRef)
+type ReplayedFunc func(ctx context.Context, node NodeInterface, ref interface{}, err error) error
+
+// nodeActionRefKey is the context key of the reference of the alert action
+type nodeActionRefKey struct{}
+
+// WithNodeActionRef will return the context of an alert action with its reference (e.g. the alert), passed to the
+// ReplayedFunc once a node that missed the action replays it
+func WithNodeActionRef(ctx context.Context, ref interface{}) context.Context {
+	return context.WithValue(ctx, nodeActionRefKey{}, ref)
+}
+
+// NodeError is the error returned by a node of the pool for an alert action
+type NodeError struct {
+	Err  error  // Error returned by the node
+	Host string // RPC host of the node
+}
+
+// Error will return the error with the host of the node
+func (e *NodeError) Error() string {
+	return e.Host + ": " + e.Err.Error()
+}
+
+// Unwrap will return the error returned by the node
+func (e *NodeError) Unwrap() error {
+	return e.Err
+}
+
+// NewNodePool will create a pool of the nodes (all healthy until a call fails)
+func NewNodePool(nodes []NodeInterface, retryInterval time.Duration, clk clock.Clock, logger LoggerInterface) *NodePool {
+	p := &NodePool{
+		clock:         clk,
+		logger:        logger,
+		nodes:         make([]*poolNode, 0, len(nodes)),
+		retryInterval: retryInterval,
+	}
+	for _, node := range nodes {
+		p.nodes = append(p.nodes, &poolNode{node: node})
+	}
+	return p
+}
+
+// nodeFailed will return true if the node did not respond (an RPC error is returned by the node, and a context done
+// by the caller is not a failure of the node)
+func nodeFailed(err error) bool {
+	var rpcErr *RPCError
+	return err != nil && !errors.As(err, &rpcErr) &&
+		!errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// order will return the nodes for the next read: the healthy nodes (and the ones to retry) from the next node of
+// the round-robin, then the unreachable ones as a last resort
+func (p *NodePool) order() []*poolNode {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.clock.Now()
+	available := make([]*poolNode, 0, len(p.nodes))
+	var unreachable []*poolNode
+	for i := range p.nodes {
+		n := p.nodes[(p.next+i)%len(p.nodes)]
+		if n.err == nil || !now.Before(n.retryAt) {
+			available = append(available, n)
+		} else {
+			unreachable = append(unreachable, n)
+		}
+	}
+	if len(p.nodes) > 0 {
+		p.next = (p.next + 1) % len(p.nodes)
+	}
+	return append(available, unreachable...)
+}
+
+// update will update the health of the node with the result of a call
+func (p *NodePool) update(n *poolNode, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !nodeFailed(err) {
+		if n.err != nil {
+			p.logger.Infof("node %s is reachable again", n.node.GetRPCHost())
+		}
+		n.err = nil
+		return
+	}
+	if n.err == nil {
+		p.logger.Warnf("node %s is unreachable, failing over to the other nodes: %s", n.node.GetRPCHost(), err.Error())
+	}
+	n.err = err
+	n.retryAt = p.clock.Now().Add(p.retryInterval)
+}
+
+// read will call the nodes in order until one responds
+func (p *NodePool) read(call func(node NodeInterface) error) error {
+	err := ErrNoRPCConnections
+	for _, n := range p.order() {
+		err = call(n.node)
+		p.update(n, err)
+		if !nodeFailed(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// each will execute the action against all the reachable nodes, the errors are returned with the host of the node
+// (joined NodeError)
+// The action is queued for the unreachable nodes if it succeeded (a failed action is retried on all the nodes)
+func (p *NodePool) each(ctx context.Context, action nodeAction) error {
+	var behind []*poolNode
+	var errs []error
+	executed := 0
+	for _, n := range p.nodes {
+		if p.behind(n) {
+			behind = append(behind, n)
+			continue
+		}
+		err := action(ctx, n.node)
+		p.update(n, err)
+		if nodeFailed(err) {
+			behind = append(behind, n)
+		} else if err != nil {
+			errs = append(errs, &NodeError{Err: err, Host: n.node.GetRPCHost()})
+		} else {
+			executed++
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	} else if executed == 0 {
+		return ErrNoHealthyNode
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, n := range behind {
+		n.missed = append(n.missed, missedAction{action: action, ref: ctx.Value(nodeActionRefKey{})})
+		p.logger.Warnf("node %s is unreachable, %d alert actions are replayed once it responds",
+			n.node.GetRPCHost(), len(n.missed))
+	}
+	return nil
+}
+
+// behind will return true if the node is unreachable or still has missed actions to replay
+func (p *NodePool) behind(n *poolNode) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return n.err != nil || len(n.missed) > 0 || n.catchingUp
+}
+
+// catchUp will replay the actions the node missed (in order), until the node does not respond
+// An action returning an error is logged and dropped (the node responded, like a failed action of a single node)
+func (p *NodePool) catchUp(ctx context.Context, n *poolNode) {
+	p.mu.Lock()
+	if n.catchingUp || len(n.missed) == 0 {
+		p.mu.Unlock()
+		return
+	}
+	n.catchingUp = true
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		n.catchingUp = false
+		p.mu.Unlock()
+	}()
+
+	for {
+		p.mu.Lock()
+		if len(n.missed) == 0 {
+			p.mu.Unlock()
+			p.logger.Infof("node %s caught up on the missed alert actions", n.node.GetRPCHost())
+			return
+		}
+		missed, replayed := n.missed[0], p.replayed
+		p.mu.Unlock()
+
+		err := missed.action(ctx, n.node)
+		p.update(n, err)
+		if nodeFailed(err) {
+			return
+		} else if err != nil {
+			p.logger.Errorf("failed to replay a missed alert action on node %s: %s", n.node.GetRPCHost(), err.Error())
+		}
+		if replayed != nil {
+			if recordErr := replayed(ctx, n.node, missed.ref, err); recordErr != nil {
+				p.logger.Errorf("failed to record a replayed alert action on node %s: %s",
+					n.node.GetRPCHost(), recordErr.Error())
+			}
+		}
+		p.mu.Lock()
+		n.missed = n.missed[1:]
+		p.mu.Unlock()
+	}
+}
+
+// Check will check each node responds (the health of the nodes is updated), it fails if no node responds
+// A node that responds replays the alert actions it missed
+func (p *NodePool) Check(ctx context.Context) (string, error) {
+	healthy := 0
+	errs := make([]error, 0, len(p.nodes))
+	for _, n := range p.nodes {
+		_, err := n.node.BestBlockHash(ctx)
+		p.update(n, err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.node.GetRPCHost(), err))
+			continue
+		}
+		healthy++
+		p.catchUp(ctx, n)
+	}
+	if healthy == 0 {
+		return "", fmt.Errorf("%w: %w", ErrNoHealthyNode, errors.Join(errs...))
+	}
+	return strconv.Itoa(healthy) + "/" + strconv.Itoa(len(p.nodes)) + " nodes reachable", nil
+}
+
+// Run will check the nodes at each retry interval until the context is done or the quit channel is signaled
+// (an unreachable node is detected, and catches up on the missed alert actions, without waiting for a call)
+func (p *NodePool) Run(ctx context.Context, quit <-chan bool) {
+	ticker := p.clock.NewTicker(p.retryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			if _, err := p.Check(ctx); err != nil {
+				p.logger.Errorf("node health check failed: %s", err.Error())
+			}
+		case <-quit:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// OnReplayed will set the function called with the result of each missed alert action replayed on a node
+func (p *NodePool) OnReplayed(fn ReplayedFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.replayed = fn
+}
+
+// Missed will return the number of the alert actions the node missed (replayed once it responds)
+func (p *NodePool) Missed(node NodeInterface) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, n := range p.nodes {
+		if n.node == node {
+			return len(n.missed)
+		}
+	}
+	return 0
+}
+
+// Err will return why the node is unreachable (nil if it responded to the last call)
+func (p *NodePool) Err(node NodeInterface) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, n := range p.nodes {
+		if n.node == node {
+			return n.err
+		}
+	}
+	return nil
+}
+
+// Healthy will return true if the node is not marked unreachable by a failed call
+func (p *NodePool) Healthy(node NodeInterface) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, n := range p.nodes {
+		if n.node == node {
+			return n.err == nil
+		}
+	}
+	return false
+}
+
+// Nodes will return the nodes of the pool
+func (p *NodePool) Nodes() []NodeInterface {
+	nodes := make([]NodeInterface, 0, len(p.nodes))
+	for _, n := range p.nodes {
+		nodes = append(nodes, n.node)
+	}
+	return nodes
+}
+
+// GetRPCHost returns the RPC hosts of the nodes (comma separated)
+func (p *NodePool) GetRPCHost() string {
+	hosts := make([]string, 0, len(p.nodes))
+	for _, n := range p.nodes {
+		hosts = append(hosts, n.node.GetRPCHost())
+	}
+	return strings.Join(hosts, ",")
+}
+
+// GetRPCPassword returns the RPC password of the first node
+func (p *NodePool) GetRPCPassword() string {
+	if len(p.nodes) == 0 {
+		return ""
+	}
+	return p.nodes[0].node.GetRPCPassword()
+}
+
+// GetRPCUser returns the RPC user of the first node
+func (p *NodePool) GetRPCUser() string {
+	if len(p.nodes) == 0 {
+		return ""
+	}
+	return p.nodes[0].node.GetRPCUser()
+}
+
+// BanPeer bans a peer on all the nodes
+func (p *NodePool) BanPeer(ctx context.Context, peer string) error {
+	return p.each(ctx, func(ctx context.Context, node NodeInterface) error {
+		return node.BanPeer(ctx, peer)
+	})
+}
+
+// BestBlockHash gets the best block hash (of the next healthy node)
+func (p *NodePool) BestBlockHash(ctx context.Context) (hash string, err error) {
+	err = p.read(func(node NodeInterface) (nodeErr error) {
+		hash, nodeErr = node.BestBlockHash(ctx)
+		return
+	})
+	return
+}
+
+// BlockCount gets the current block height (of the next healthy node)
+func (p *NodePool) BlockCount(ctx context.Context) (count uint32, err error) {
+	err = p.read(func(node NodeInterface) (nodeErr error) {
+		count, nodeErr = node.BlockCount(ctx)
+		return
+	})
+	return
+}
+
+// InActiveChain checks if the block is in the active chain (of the next healthy node)
+func (p *NodePool) InActiveChain(ctx context.Context, hash string) (active bool, err error) {
+	err = p.read(func(node NodeInterface) (nodeErr error) {
+		active, nodeErr = node.InActiveChain(ctx, hash)
+		return
+	})
+	return
+}
+
+// InvalidateBlock invalidates a block on all the nodes
+func (p *NodePool) InvalidateBlock(ctx context.Context, hash string) error {
+	return p.each(ctx, func(ctx context.Context, node NodeInterface) error {
+		return node.InvalidateBlock(ctx, hash)
+	})
+}
+
+// ListBanned gets the list of banned peers (of the next healthy node)
+func (p *NodePool) ListBanned(ctx context.Context) (banned []*models.BannedSubnet, err error) {
+	err = p.read(func(node NodeInterface) (nodeErr error) {
+		banned, nodeErr = node.ListBanned(ctx)
+		return
+	})
+	return
+}
+
+// NetworkInfo gets the network info (of the next healthy node)
+func (p *NodePool) NetworkInfo(ctx context.Context) (info *models.NetworkInfo, err error) {
+	err = p.read(func(node NodeInterface) (nodeErr error) {
+		info, nodeErr = node.NetworkInfo(ctx)
+		return
+	})
+	return
+}
+
+// QueryBlacklistedFunds gets the frozen funds (of the next healthy node)
+func (p *NodePool) QueryBlacklistedFunds(ctx context.Context) (funds []models.Fund, err error) {
+	err = p.read(func(node NodeInterface) (nodeErr error) {
+		funds, nodeErr = node.QueryBlacklistedFunds(ctx)
+		return
+	})
+	return
+}
+
+// UnbanPeer unbans a peer on all the nodes
+func (p *NodePool) UnbanPeer(ctx context.Context, peer string) error {
+	return p.each(ctx, func(ctx context.Context, node NodeInterface) error {
+		return node.UnbanPeer(ctx, peer)
+	})
+}
+
+// AddToConsensusBlacklist adds frozen utxos to the blacklist of all the nodes (the response of the first node
+// that succeeded)
+func (p *NodePool) AddToConsensusBlacklist(ctx context.Context,
+	funds []models.Fund) (res *models.AddToConsensusBlacklistResponse, err error) {
+	err = p.each(ctx, func(ctx context.Context, node NodeInterface) error {
+		nodeRes, nodeErr := node.AddToConsensusBlacklist(ctx, funds)
+		if nodeErr == nil && res == nil {
+			res = nodeRes
+		}
+		return nodeErr
+	})
+	return
+}
+
+// AddToConfiscationTransactionWhitelist adds confiscation transactions to the whitelist of all the nodes (the
+// response of the first node that succeeded)
+func (p *NodePool) AddToConfiscationTransactionWhitelist(ctx context.Context,
+	tx []models.ConfiscationTransactionDetails) (res *models.AddToConfiscationTransactionWhitelistResponse, err error) {
+	err = p.each(ctx, func(ctx context.Context, node NodeInterface) error {
+		nodeRes, nodeErr := node.AddToConfiscationTransactionWhitelist(ctx, tx)
+		if nodeErr == nil && res == nil {
+			res = nodeRes
+		}
+		return nodeErr
+	})
+	return
+}
@@ -0,0 +1,278 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/clock"
+	"github.com/bitcoin-sv/alert-system/app/config/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errNodeDown is the error of a node that does not respond
+var errNodeDown = errors.New("connection refused")
+
+// newTestNodePool will create a pool of mock nodes returning their host as the best block hash
+func newTestNodePool(hosts ...string) (*NodePool, []*mocks.Node, *clock.Mock) {
+	nodes := make([]NodeInterface, 0, len(hosts))
+	mockNodes := make([]*mocks.Node, 0, len(hosts))
+	for _, host := range hosts {
+		node := &mocks.Node{RPCHost: host}
+		node.BestBlockHashFunc = func(context.Context) (string, error) {
+			return node.RPCHost, nil
+		}
+		nodes = append(nodes, node)
+		mockNodes = append(mockNodes, node)
+	}
+	mockClock := clock.NewMock(time.Unix(1700000000, 0))
+	logger := NewExtendedLogger(os.Stdout, LogLevelError, nil)
+	return NewNodePool(nodes, time.Minute, mockClock, logger), mockNodes, mockClock
+}
+
+// TestNodePool_Read will test the reads are balanced between the nodes (round-robin)
+func TestNodePool_Read(t *testing.T) {
+	pool, _, _ := newTestNodePool("node1", "node2")
+	ctx := context.Background()
+
+	for _, expected := range []string{"node1", "node2", "node1"} {
+		hash, err := pool.BestBlockHash(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, expected, hash)
+	}
+}
+
+// TestNodePool_Failover will test the reads fail over to the next node, the unreachable node is retried later
+func TestNodePool_Failover(t *testing.T) {
+	pool, nodes, mockClock := newTestNodePool("node1", "node2")
+	ctx := context.Background()
+	nodes[0].BestBlockHashFunc = func(context.Context) (string, error) {
+		return "", errNodeDown
+	}
+
+	hash, err := pool.BestBlockHash(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "node2", hash)
+	assert.False(t, pool.Healthy(nodes[0]))
+
+	// The unreachable node is skipped until the retry interval elapsed
+	hash, err = pool.BestBlockHash(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "node2", hash)
+	assert.Len(t, nodes[0].Calls(mocks.MethodBestBlockHash), 1)
+
+	// The node responds again once it is retried
+	nodes[0].BestBlockHashFunc = nil
+	mockClock.Advance(time.Minute)
+	_, err = pool.BestBlockHash(ctx)
+	require.NoError(t, err)
+	assert.Len(t, nodes[0].Calls(mocks.MethodBestBlockHash), 2)
+	assert.True(t, pool.Healthy(nodes[0]))
+
+	// All nodes unreachable
+	for _, node := range nodes {
+		node.BestBlockHashFunc = func(context.Context) (string, error) {
+			return "", errNodeDown
+		}
+	}
+	_, err = pool.BestBlockHash(ctx)
+	require.ErrorIs(t, err, errNodeDown)
+}
+
+// TestNodePool_RPCError will test an error returned by the node does not fail over
+func TestNodePool_RPCError(t *testing.T) {
+	pool, nodes, _ := newTestNodePool("node1", "node2")
+	rpcErr := &RPCError{Code: RPCErrorNotFound, Message: "block not found"}
+	nodes[0].InActiveChainFunc = func(context.Context, string) (bool, error) {
+		return false, rpcErr
+	}
+
+	_, err := pool.InActiveChain(context.Background(), "hash")
+	require.ErrorIs(t, err, rpcErr)
+	assert.True(t, pool.Healthy(nodes[0]))
+	assert.Empty(t, nodes[1].Calls(mocks.MethodInActiveChain))
+}
+
+// TestNodePool_Actions will test the alert actions are executed against all the nodes
+func TestNodePool_Actions(t *testing.T) {
+	pool, nodes, _ := newTestNodePool("node1", "node2")
+	ctx := context.Background()
+
+	require.NoError(t, pool.BanPeer(ctx, "127.0.0.1"))
+	for _, node := range nodes {
+		assert.Len(t, node.Calls(mocks.MethodBanPeer), 1)
+	}
+
+	// An error returned by a node fails the action (the other nodes still execute it)
+	rpcErr := &RPCError{Code: -8, Message: "block not found"}
+	nodes[0].InvalidateBlockFunc = func(context.Context, string) error {
+		return rpcErr
+	}
+	err := pool.InvalidateBlock(ctx, "hash")
+	require.ErrorIs(t, err, rpcErr)
+	assert.Contains(t, err.Error(), "node1")
+	var nodeErr *NodeError
+	require.ErrorAs(t, err, &nodeErr)
+	assert.Equal(t, "node1", nodeErr.Host)
+	assert.Len(t, nodes[1].Calls(mocks.MethodInvalidateBlock), 1)
+	assert.True(t, pool.Healthy(nodes[0]))
+	assert.Zero(t, pool.Missed(nodes[0]))
+}
+
+// TestNodePool_MissedActions will test an unreachable node does not fail the actions, it replays them once it responds
+func TestNodePool_MissedActions(t *testing.T) {
+	pool, nodes, _ := newTestNodePool("node1", "node2")
+	ctx := context.Background()
+	down := func(context.Context) (string, error) {
+		return "", errNodeDown
+	}
+	nodes[0].BestBlockHashFunc = down
+	nodes[0].InvalidateBlockFunc = func(context.Context, string) error {
+		return errNodeDown
+	}
+
+	// Executed on the reachable node, queued for the unreachable one (skipped once it is known unreachable)
+	require.NoError(t, pool.InvalidateBlock(ctx, "hash"))
+	require.NoError(t, pool.BanPeer(ctx, "127.0.0.1"))
+	assert.Len(t, nodes[1].Calls(mocks.MethodInvalidateBlock), 1)
+	assert.Len(t, nodes[1].Calls(mocks.MethodBanPeer), 1)
+	assert.Len(t, nodes[0].Calls(mocks.MethodInvalidateBlock), 1)
+	assert.Empty(t, nodes[0].Calls(mocks.MethodBanPeer))
+	assert.Equal(t, 2, pool.Missed(nodes[0]))
+	require.ErrorIs(t, pool.Err(nodes[0]), errNodeDown)
+	require.NoError(t, pool.Err(nodes[1]))
+
+	// Still unreachable
+	_, err := pool.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, pool.Missed(nodes[0]))
+
+	// Replayed in order once the node responds
+	nodes[0].BestBlockHashFunc = nil
+	nodes[0].InvalidateBlockFunc = nil
+	_, err = pool.Check(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, pool.Missed(nodes[0]))
+	assert.True(t, pool.Healthy(nodes[0]))
+	require.NoError(t, pool.Err(nodes[0]))
+	assert.Len(t, nodes[0].Calls(mocks.MethodInvalidateBlock), 2)
+	assert.Len(t, nodes[0].Calls(mocks.MethodBanPeer), 1)
+
+	// No node reachable, the action fails (and is not queued, the alert is retried)
+	for _, node := range nodes {
+		node.BanPeerFunc = func(context.Context, string) error {
+			return errNodeDown
+		}
+	}
+	require.ErrorIs(t, pool.BanPeer(ctx, "127.0.0.1"), ErrNoHealthyNode)
+	for _, node := range nodes {
+		assert.Zero(t, pool.Missed(node))
+	}
+}
+
+// TestNodePool_OnReplayed will test the result of each replayed action is passed with its reference and node
+func TestNodePool_OnReplayed(t *testing.T) {
+	pool, nodes, _ := newTestNodePool("node1", "node2")
+	ctx := context.Background()
+	nodes[0].BestBlockHashFunc = func(context.Context) (string, error) {
+		return "", errNodeDown
+	}
+	nodes[0].BanPeerFunc = func(context.Context, string) error {
+		return errNodeDown
+	}
+	type replay struct {
+		err  error
+		host string
+		ref  interface{}
+	}
+	var replays []replay
+	pool.OnReplayed(func(_ context.Context, node NodeInterface, ref interface{}, err error) error {
+		replays = append(replays, replay{err: err, host: node.GetRPCHost(), ref: ref})
+		return nil
+	})
+
+	// Queued with their reference for the unreachable node
+	require.NoError(t, pool.BanPeer(WithNodeActionRef(ctx, "alert 1"), "127.0.0.1"))
+	require.NoError(t, pool.UnbanPeer(WithNodeActionRef(ctx, "alert 2"), "127.0.0.1"))
+	require.Equal(t, 2, pool.Missed(nodes[0]))
+	assert.Empty(t, replays)
+
+	// Replayed in order once the node responds (an error returned by the node is passed)
+	rpcErr := &RPCError{Code: -1, Message: "unban failed"}
+	nodes[0].BestBlockHashFunc = nil
+	nodes[0].BanPeerFunc = nil
+	nodes[0].UnbanPeerFunc = func(context.Context, string) error {
+		return rpcErr
+	}
+	_, err := pool.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []replay{{host: "node1", ref: "alert 1"}, {err: rpcErr, host: "node1", ref: "alert 2"}}, replays)
+}
+
+// TestNodePool_Run will test the nodes are checked at each retry interval until stopped
+func TestNodePool_Run(t *testing.T) {
+	pool, nodes, mockClock := newTestNodePool("node1", "node2")
+	quit := make(chan bool)
+	done := make(chan bool)
+	go func() {
+		pool.Run(context.Background(), quit)
+		close(done)
+	}()
+
+	nodes[1].BestBlockHashFunc = func(context.Context) (string, error) {
+		return "", errNodeDown
+	}
+	assert.Eventually(t, func() bool {
+		mockClock.Advance(time.Minute)
+		return !pool.Healthy(nodes[1])
+	}, time.Second, 10*time.Millisecond)
+	close(quit)
+	<-done
+}
+
+// TestNodePool_Check will test the health check of each node
+func TestNodePool_Check(t *testing.T) {
+	pool, nodes, _ := newTestNodePool("node1", "node2")
+	ctx := context.Background()
+
+	summary, err := pool.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "2/2 nodes reachable", summary)
+
+	nodes[1].BestBlockHashFunc = func(context.Context) (string, error) {
+		return "", errNodeDown
+	}
+	summary, err = pool.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "1/2 nodes reachable", summary)
+	assert.False(t, pool.Healthy(nodes[1]))
+
+	nodes[0].BestBlockHashFunc = nodes[1].BestBlockHashFunc
+	_, err = pool.Check(ctx)
+	require.ErrorIs(t, err, ErrNoHealthyNode)
+	require.ErrorIs(t, err, errNodeDown)
+}
+
+// TestLoadServices_NodePool will test several RPC connections are loaded as a pool
+func TestLoadServices_NodePool(t *testing.T) {
+	c, err := New(
+		WithNodeMock(),
+		WithPrivateKeyPath(filepath.Join(t.TempDir(), "key")),
+		WithRPCConnection("http://node1:8332", "user", "pass"),
+		WithRPCConnection("http://node2:8332", "user", "pass"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultNodeRetryInterval, c.NodePool.RetryInterval)
+
+	require.NoError(t, c.LoadServices(context.Background(), nil, false))
+	defer c.CloseAll(context.Background())
+
+	pool, ok := c.Services.Node.(*NodePool)
+	require.True(t, ok)
+	assert.Equal(t, c.Services.Nodes, pool.Nodes())
+	assert.Equal(t, "http://node1:8332,http://node2:8332", pool.GetRPCHost())
+}
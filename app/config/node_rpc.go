@@ -0,0 +1,71 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// RPCErrorNotFound is the node error code of an unknown block, transaction or address
+const RPCErrorNotFound = -5
+
+// rpcRequest is a JSON-RPC request to the node (for the calls the node client does not have)
+type rpcRequest struct {
+	ID     string        `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// rpcResponse is the JSON-RPC response of the node
+type rpcResponse struct {
+	Error  *RPCError       `json:"error"`
+	Result json.RawMessage `json:"result"`
+}
+
+// RPCError is an error returned by the node
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error will return the error message
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("node rpc error %d: %s", e.Code, e.Message)
+}
+
+// call will send the JSON-RPC request to the node and decode the result
+func (n *Node) call(ctx context.Context, method string, result interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(&rpcRequest{ID: "alert-system", Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, n.RPCHost, bytes.NewReader(body)); err != nil {
+		return err
+	}
+	req.SetBasicAuth(n.RPCUser, n.RPCPassword)
+	req.Header.Set("Content-Type", "application/json")
+	var res *http.Response
+	if res, err = http.DefaultClient.Do(req); err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	// The node responds with an error status and the error in the body
+	response := &rpcResponse{}
+	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+		return fmt.Errorf("node rpc %s: %s", method, res.Status)
+	} else if response.Error != nil {
+		return response.Error
+	} else if result == nil {
+		return nil
+	}
+	return json.Unmarshal(response.Result, result)
+}
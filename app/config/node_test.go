@@ -1,9 +1,14 @@
 package config
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestNewNodeConfig creates a new NodeConfig struct
@@ -39,3 +44,63 @@ func TestNewNodeMock(t *testing.T) {
 		assert.Equal(t, "host", val)
 	})
 }
+
+// TestNode_InActiveChain will test the method InActiveChain()
+func TestNode_InActiveChain(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		user, pass, ok := req.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "user:pass", user+":"+pass)
+		request := &rpcRequest{}
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(request))
+		assert.Equal(t, "getblockheader", request.Method)
+		switch request.Params[0] {
+		case "active":
+			_, _ = w.Write([]byte(`{"result":{"confirmations":3},"error":null,"id":"alert-system"}`))
+		case "invalid":
+			_, _ = w.Write([]byte(`{"result":{"confirmations":-1},"error":null,"id":"alert-system"}`))
+		case "unknown":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"result":null,"error":{"code":-5,"message":"Block not found"},"id":"alert-system"}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+	node := NewNodeConfig("user", "pass", srv.URL)
+
+	active, err := node.InActiveChain(context.Background(), "active")
+	require.NoError(t, err)
+	assert.True(t, active)
+
+	active, err = node.InActiveChain(context.Background(), "invalid")
+	require.NoError(t, err)
+	assert.False(t, active)
+
+	active, err = node.InActiveChain(context.Background(), "unknown")
+	require.NoError(t, err)
+	assert.False(t, active)
+
+	_, err = node.InActiveChain(context.Background(), "denied")
+	require.Error(t, err)
+}
+
+// TestNode_QueryBlacklistedFunds will test the method QueryBlacklistedFunds()
+func TestNode_QueryBlacklistedFunds(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"funds":[{"txOut":{"txId":"aa","vout":1},` +
+			`"enforceAtHeight":[{"start":10,"stop":20}],"policyExpiresWithConsensus":false}]},"error":null}`))
+	}))
+	defer srv.Close()
+
+	funds, err := NewNodeConfig("user", "pass", srv.URL).QueryBlacklistedFunds(context.Background())
+	require.NoError(t, err)
+	require.Len(t, funds, 1)
+	assert.Equal(t, "aa", funds[0].TxOut.TxId)
+	assert.Equal(t, 1, funds[0].TxOut.Vout)
+	assert.Equal(t, 20, funds[0].EnforceAtHeight[0].Stop)
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"github.com/bitcoin-sv/alert-system/app/clock"
+	"github.com/mrz1836/go-datastore"
+)
+
+// ServiceOptions allow functional options to be supplied to LoadDependencies and LoadServices (the services are used
+// instead of the ones built from the config)
+type ServiceOptions func(s *Services)
+
+// WithClock will use the clock for the processing intervals, timers and ban expiry (e.g. a clock.Mock)
+func WithClock(c clock.Clock) ServiceOptions {
+	return func(s *Services) {
+		s.Clock = c
+	}
+}
+
+// WithDatastoreClient will use the datastore client instead of the datastore config (the models are not
+// auto-migrated, the caller owns the schema)
+func WithDatastoreClient(client datastore.ClientInterface) ServiceOptions {
+	return func(s *Services) {
+		s.Datastore = client
+	}
+}
+
+// WithHTTPClient will use the HTTP client for the outgoing requests (webhooks, notifications and error reporting)
+func WithHTTPClient(client HTTPInterface) ServiceOptions {
+	return func(s *Services) {
+		s.HTTPClient = client
+	}
+}
+
+// WithLogger will use the logger instead of the log_level, log_format and log_output settings (the error
+// reporting and the log deduplication still wrap it)
+func WithLogger(logger LoggerInterface) ServiceOptions {
+	return func(s *Services) {
+		s.Log = logger
+	}
+}
+
+// WithNode will execute the alerts against the node instead of the RPC connections (which are not required)
+func WithNode(node NodeInterface) ServiceOptions {
+	return func(s *Services) {
+		s.Node = node
+	}
+}
+
+// newServices will return the services set by the options (nil for the ones built from the config)
+func newServices(opts []ServiceOptions) (s Services) {
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return
+}
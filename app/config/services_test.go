@@ -0,0 +1,77 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/clock"
+	"github.com/bitcoin-sv/alert-system/app/config/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoadServices_Options will test the injected services are used instead of the ones built from the config
+func TestLoadServices_Options(t *testing.T) {
+	c, err := New(
+		WithPrivateKeyPath(filepath.Join(t.TempDir(), "key")),
+		WithRPCConnection("http://localhost:8332", "user", "pass"),
+	)
+	require.NoError(t, err)
+
+	node := &mocks.Node{}
+	logger := NewExtendedLogger(os.Stdout, LogLevelInfo, nil)
+	httpClient := &http.Client{Timeout: time.Second}
+	mockClock := clock.NewMock(time.Unix(1700000000, 0))
+
+	require.NoError(t, c.LoadServices(context.Background(), nil, false,
+		WithClock(mockClock),
+		WithHTTPClient(httpClient),
+		WithLogger(logger),
+		WithNode(node),
+	))
+	defer c.CloseAll(context.Background())
+
+	assert.Same(t, node, c.Services.Node)
+	assert.Equal(t, []NodeInterface{node}, c.Services.Nodes)
+	assert.Same(t, httpClient, c.Services.HTTPClient)
+	assert.Same(t, mockClock, c.Services.Clock)
+
+	// The injected logger is still wrapped by the log deduplication
+	dedup, ok := c.Services.Log.(*dedupLogger)
+	require.True(t, ok)
+	assert.Same(t, logger, dedup.LoggerInterface)
+}
+
+// TestLoadServices_Defaults will test the services are built from the config without options
+func TestLoadServices_Defaults(t *testing.T) {
+	c, err := New(
+		WithPrivateKeyPath(filepath.Join(t.TempDir(), "key")),
+		WithRPCConnection("http://localhost:8332", "user", "pass"),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, c.LoadServices(context.Background(), nil, false))
+	defer c.CloseAll(context.Background())
+
+	assert.IsType(t, &Node{}, c.Services.Node)
+	assert.Len(t, c.Services.Nodes, 1)
+	assert.Equal(t, http.DefaultClient, c.Services.HTTPClient)
+	assert.Equal(t, clock.New(), c.Services.Clock)
+}
+
+// TestConfig_validate will test the RPC connections are not required with an injected node
+func TestConfig_validate(t *testing.T) {
+	c, err := New(
+		WithPrivateKeyPath(filepath.Join(t.TempDir(), "key")),
+		WithRPCConnection("http://localhost:8332", "user", "pass"),
+	)
+	require.NoError(t, err)
+
+	c.RPCConnections = nil
+	require.ErrorIs(t, c.validate(true), ErrNoRPCConnections)
+	require.NoError(t, c.validate(false))
+}
@@ -0,0 +1,78 @@
+// Package devnet is the local development network of the alert system: throwaway genesis keys generated at startup
+// and the signing of test alerts with them, so the alert API can be integrated against without the real key holders
+package devnet
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/bitcoinschema/go-bitcoin"
+)
+
+// KeyCount is the number of genesis keys generated (the same as the real networks)
+const KeyCount = 5
+
+// Signatures is the number of signatures of an alert (signed by the first keys)
+const Signatures = 3
+
+// alertVersion is the version of the signed alerts
+const alertVersion = 1
+
+// ErrNoKeys is returned when the signer has fewer keys than the signatures of an alert
+var ErrNoKeys = errors.New("devnet signer needs at least 3 private keys")
+
+// Signer is the throwaway genesis keys of the devnet and the signing of the test alerts
+// It is locked while an alert is signed and submitted, so the sequences are given in order
+type Signer struct {
+	sync.Mutex
+	PrivateKeys []string // Hex private keys (throwaway, never use them on a real network)
+	PublicKeys  []string // Compressed hex public keys (the genesis keys of the devnet)
+}
+
+// NewSigner will generate the throwaway genesis keys
+func NewSigner() (*Signer, error) {
+	s := &Signer{}
+	for i := 0; i < KeyCount; i++ {
+		privateKey, err := bitcoin.CreatePrivateKeyString()
+		if err != nil {
+			return nil, err
+		}
+		var publicKey string
+		if publicKey, err = bitcoin.PubKeyFromPrivateKeyString(privateKey, true); err != nil {
+			return nil, err
+		}
+		s.PrivateKeys = append(s.PrivateKeys, privateKey)
+		s.PublicKeys = append(s.PublicKeys, publicKey)
+	}
+	return s, nil
+}
+
+// Sign will return the raw alert (the wire format gossiped by the peers) signed with the first keys
+func (s *Signer) Sign(sequence, alertType uint32, message []byte) ([]byte, error) {
+	if len(s.PrivateKeys) < Signatures {
+		return nil, ErrNoKeys
+	}
+	return NewAlert(sequence, alertType, message, s.PrivateKeys[:Signatures])
+}
+
+// NewAlert will return the raw alert signed with the private keys (hex)
+func NewAlert(sequence, alertType uint32, message []byte, privateKeys []string) ([]byte, error) {
+	var data []byte
+	data = binary.LittleEndian.AppendUint32(data, alertVersion)
+	data = binary.LittleEndian.AppendUint32(data, sequence)
+	data = binary.LittleEndian.AppendUint64(data, uint64(time.Now().Unix()))
+	data = binary.LittleEndian.AppendUint32(data, alertType)
+	data = append(data, message...)
+
+	sigs, err := utils.SignWithKeys(data, privateKeys)
+	if err != nil {
+		return nil, err
+	}
+	for _, sig := range sigs {
+		data = append(data, sig...)
+	}
+	return data, nil
+}
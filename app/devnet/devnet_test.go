@@ -0,0 +1,61 @@
+package devnet
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/bitcoinschema/go-bitcoin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewSigner will test the throwaway genesis keys are generated
+func TestNewSigner(t *testing.T) {
+	s, err := NewSigner()
+	require.NoError(t, err)
+	require.Len(t, s.PrivateKeys, KeyCount)
+	require.Len(t, s.PublicKeys, KeyCount)
+
+	for i, privateKey := range s.PrivateKeys {
+		publicKey, err := bitcoin.PubKeyFromPrivateKeyString(privateKey, true)
+		require.NoError(t, err)
+		assert.Equal(t, s.PublicKeys[i], publicKey)
+	}
+
+	other, err := NewSigner()
+	require.NoError(t, err)
+	assert.NotEqual(t, s.PublicKeys, other.PublicKeys)
+}
+
+// TestSigner_Sign will test the alert is signed by the first keys
+func TestSigner_Sign(t *testing.T) {
+	s, err := NewSigner()
+	require.NoError(t, err)
+
+	message := []byte{4, 't', 'e', 's', 't'}
+	raw, err := s.Sign(7, 1, message)
+	require.NoError(t, err)
+	require.Len(t, raw, 20+len(message)+Signatures*65)
+
+	data := raw[:20+len(message)]
+	assert.Equal(t, uint32(alertVersion), binary.LittleEndian.Uint32(data[0:4]))
+	assert.Equal(t, uint32(7), binary.LittleEndian.Uint32(data[4:8]))
+	assert.Equal(t, uint32(1), binary.LittleEndian.Uint32(data[16:20]))
+	assert.Equal(t, message, data[20:])
+
+	for i := 0; i < Signatures; i++ {
+		sig := raw[len(data)+i*65 : len(data)+(i+1)*65]
+		pub, err := bitcoin.PubKeyFromString(s.PublicKeys[i])
+		require.NoError(t, err)
+		address, err := bitcoin.GetAddressFromPubKey(pub, true)
+		require.NoError(t, err)
+		require.NoError(t, bitcoin.VerifyMessage(address.String(), base64.StdEncoding.EncodeToString(sig), hex.EncodeToString(data)))
+	}
+
+	t.Run("not enough keys", func(t *testing.T) {
+		_, err := (&Signer{PrivateKeys: s.PrivateKeys[:2]}).Sign(1, 1, message)
+		require.ErrorIs(t, err, ErrNoKeys)
+	})
+}
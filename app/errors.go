@@ -0,0 +1,18 @@
+package app
+
+import "errors"
+
+// API errors
+var (
+	ErrAdminDisabled          = errors.New("admin api is disabled, no admin token configured")
+	ErrAuditDisabled          = errors.New("audit log is not enabled")
+	ErrBodyTooLarge           = errors.New("request body is too large")
+	ErrInternal               = errors.New("internal error, the request was not completed")
+	ErrIPNotAllowed           = errors.New("client ip address is not allowed")
+	ErrP2PNotRunning          = errors.New("p2p server is not running")
+	ErrRequestInvalid         = errors.New("request is invalid")
+	ErrTooManyRequests        = errors.New("too many concurrent requests, retry later")
+	ErrUnauthorized           = errors.New("missing or invalid admin token")
+	ErrUnsupportedAPIVersion  = errors.New("requested api version is not supported")
+	ErrUnsupportedContentType = errors.New("content type is not supported, use application/json or a form")
+)
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// Conditional request headers
+const (
+	HeaderCacheControl = "Cache-Control" // Pollers must revalidate (no-cache) before using a cached response
+	HeaderETag         = "ETag"          // Entity tag of the response
+	HeaderIfNoneMatch  = "If-None-Match" // Entity tags the client already has
+)
+
+// ETag will return a weak entity tag for the parts (weak, the body can be gzipped or not)
+func ETag(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// NotModified will set the ETag (and revalidation) headers and return true if the client already
+// has the entity (If-None-Match), writing a 304 response without a body
+func NotModified(w http.ResponseWriter, req *http.Request, etag string) bool {
+	w.Header().Set(HeaderETag, etag)
+	w.Header().Set(HeaderCacheControl, "no-cache")
+	if !matchesETag(req.Header.Get(HeaderIfNoneMatch), etag) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// matchesETag will return true if the If-None-Match header matches the entity tag (weak comparison)
+func matchesETag(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestETag will test the method ETag()
+func TestETag(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, ETag("alerts", "42"), ETag("alerts", "42"))
+	assert.NotEqual(t, ETag("alerts", "42"), ETag("alerts", "43"))
+	assert.Regexp(t, `^W/"[0-9a-f]{16}"$`, ETag("alerts", "42"))
+}
+
+// TestNotModified will test the method NotModified()
+func TestNotModified(t *testing.T) {
+	t.Parallel()
+
+	etag := ETag("alerts", "42")
+
+	t.Run("no If-None-Match", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		assert.False(t, NotModified(w, req, etag))
+		assert.Equal(t, etag, w.Header().Get(HeaderETag))
+		assert.Equal(t, "no-cache", w.Header().Get(HeaderCacheControl))
+	})
+
+	t.Run("matching tag", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderIfNoneMatch, `"other", `+etag)
+		assert.True(t, NotModified(w, req, etag))
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("strong form of the weak tag matches", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderIfNoneMatch, etag[2:])
+		assert.True(t, NotModified(w, req, etag))
+	})
+
+	t.Run("wildcard", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderIfNoneMatch, "*")
+		assert.True(t, NotModified(w, req, etag))
+	})
+
+	t.Run("stale tag", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderIfNoneMatch, ETag("alerts", "41"))
+		assert.False(t, NotModified(w, req, etag))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,161 @@
+// Package events is the in-process event bus of the alert system
+// The alert processing publishes what happened (alerts received and enforced, peers banned, node errors)
+// and the cross-cutting concerns (metrics, audit, webhooks, notifications and embedders) subscribe to it
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/models"
+)
+
+// Type is the type of event
+type Type string
+
+// Event types
+const (
+	AlertEnforced Type = "alert.enforced" // Alert action was executed against the node (Err is set if it failed)
+	AlertReceived Type = "alert.received" // New alert was received from a peer (before the signatures are verified)
+	AlertVerified Type = "alert.verified" // Alert signatures and sequence were verified (before it is enforced)
+	NodeHealthy   Type = "node.healthy"   // Node responds again after being unhealthy (heartbeat)
+	NodeUnhealthy Type = "node.unhealthy" // Node returned an error for an alert action or the heartbeat
+	PeerBanned    Type = "peer.banned"    // Peer was banned (P2P and optionally the node)
+	PeerUnbanned  Type = "peer.unbanned"  // Peer ban was lifted (manually or expired)
+)
+
+// Sources of an alert event
+const (
+	SourceGossip = "gossip" // Received on the pubsub topic
+	SourceRetry  = "retry"  // Unprocessed alert retried by the alert processing cron
+	SourceSync   = "sync"   // Synced from a peer
+)
+
+// Event is an event published on the bus
+type Event struct {
+	Alert    *models.AlertMessage // Alert (alert events)
+	Ban      *models.PeerBan      // Peer ban (peer events)
+	Err      error                // Node error (if the alert action failed)
+	ID       string               // Outbox dedup key (enforced alerts, the same if the event is replayed)
+	Node     string               // Node RPC host (alert and node events)
+	PeerID   string               // Peer the alert was received from, or the banned peer
+	Replayed bool                 // Replayed from the outbox (it may have been published before a crash)
+	Source   string               // Where the alert came from (gossip, sync or retry)
+	Time     time.Time            // When the event occurred (set on publish if empty)
+	Type     Type                 // Event type
+}
+
+// Handler handles the events (called synchronously, slow work should be queued)
+type Handler func(ctx context.Context, event *Event)
+
+// subscriber is a handler subscribed to some (or all) event types
+type subscriber struct {
+	handler Handler
+	id      uint64
+	types   map[Type]bool
+}
+
+// Bus is the in-process event bus
+type Bus struct {
+	mu          sync.RWMutex
+	nextID      uint64
+	subscribers []*subscriber
+}
+
+// NewBus will create a new event bus
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe will call the handler for the event types (all events if no types are given)
+// The returned func removes the subscription
+func (b *Bus) Subscribe(handler Handler, types ...Type) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	s := &subscriber{handler: handler, id: b.nextID}
+	if len(types) > 0 {
+		s.types = make(map[Type]bool, len(types))
+		for _, t := range types {
+			s.types[t] = true
+		}
+	}
+	b.subscribers = append(b.subscribers, s)
+	return func() {
+		b.unsubscribe(s.id)
+	}
+}
+
+// unsubscribe will remove the subscriber
+func (b *Bus) unsubscribe(id uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, s := range b.subscribers {
+		if s.id == id {
+			b.subscribers = append(b.subscribers[:i:i], b.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Publish will call the subscribed handlers in the order they subscribed
+// A nil bus publishes nothing
+func (b *Bus) Publish(ctx context.Context, event *Event) {
+	if b == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	b.mu.RLock()
+	subscribers := b.subscribers
+	b.mu.RUnlock()
+	for _, s := range subscribers {
+		if s.types == nil || s.types[event.Type] {
+			s.handler(ctx, event)
+		}
+	}
+}
+
+// DefaultDedupSize is the number of recently handled event IDs remembered by a Dedup
+const DefaultDedupSize = 1000
+
+// Dedup is the bounded set of the recently handled event IDs
+// An outbox event replayed in the same process (e.g. it failed to be marked delivered) is handled once
+type Dedup struct {
+	ids   map[string]struct{}
+	mu    sync.Mutex
+	next  int      // Position of the oldest ID in the ring (replaced by the next ID)
+	order []string // Ring of the remembered IDs
+}
+
+// NewDedup will create a set remembering the last size event IDs (at least one)
+func NewDedup(size int) *Dedup {
+	if size < 1 {
+		size = 1
+	}
+	return &Dedup{ids: make(map[string]struct{}, size), order: make([]string, 0, size)}
+}
+
+// First will remember the event ID and return true if it was not handled yet
+// Events without an ID (not saved in the outbox) are always handled
+func (d *Dedup) First(id string) bool {
+	if len(id) == 0 {
+		return true
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.ids[id]; ok {
+		return false
+	}
+	d.ids[id] = struct{}{}
+	if len(d.order) < cap(d.order) {
+		d.order = append(d.order, id)
+		return true
+	}
+	delete(d.ids, d.order[d.next])
+	d.order[d.next] = id
+	d.next = (d.next + 1) % len(d.order)
+	return true
+}
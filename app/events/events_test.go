@@ -0,0 +1,98 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBus will test publishing and subscribing to events
+func TestBus(t *testing.T) {
+	t.Run("subscribers receive their event types in order", func(t *testing.T) {
+		bus := NewBus()
+		received := make([]string, 0)
+		bus.Subscribe(func(_ context.Context, e *Event) {
+			received = append(received, "all:"+string(e.Type))
+		})
+		bus.Subscribe(func(_ context.Context, e *Event) {
+			received = append(received, "peers:"+string(e.Type))
+		}, PeerBanned, PeerUnbanned)
+
+		bus.Publish(context.Background(), &Event{Type: AlertReceived})
+		bus.Publish(context.Background(), &Event{Type: PeerBanned, PeerID: "peer"})
+		assert.Equal(t, []string{"all:alert.received", "all:peer.banned", "peers:peer.banned"}, received)
+	})
+
+	t.Run("time is set on publish", func(t *testing.T) {
+		bus := NewBus()
+		var event *Event
+		bus.Subscribe(func(_ context.Context, e *Event) {
+			event = e
+		})
+		bus.Publish(context.Background(), &Event{Type: NodeUnhealthy})
+		require.NotNil(t, event)
+		assert.False(t, event.Time.IsZero())
+	})
+
+	t.Run("unsubscribe", func(t *testing.T) {
+		bus := NewBus()
+		count := 0
+		unsubscribe := bus.Subscribe(func(_ context.Context, _ *Event) {
+			count++
+		})
+		other := 0
+		bus.Subscribe(func(_ context.Context, _ *Event) {
+			other++
+		})
+		bus.Publish(context.Background(), &Event{Type: AlertEnforced})
+		unsubscribe()
+		unsubscribe()
+		bus.Publish(context.Background(), &Event{Type: AlertEnforced})
+		assert.Equal(t, 1, count)
+		assert.Equal(t, 2, other)
+	})
+
+	t.Run("nil bus publishes nothing", func(t *testing.T) {
+		var bus *Bus
+		bus.Publish(context.Background(), &Event{Type: AlertEnforced})
+	})
+
+	t.Run("concurrent publish and subscribe", func(t *testing.T) {
+		bus := NewBus()
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				unsubscribe := bus.Subscribe(func(_ context.Context, _ *Event) {})
+				unsubscribe()
+			}()
+			go func() {
+				defer wg.Done()
+				bus.Publish(context.Background(), &Event{Type: AlertReceived})
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+// TestDedup will test remembering the recently handled event IDs
+func TestDedup(t *testing.T) {
+	d := NewDedup(2)
+	assert.True(t, d.First("a"))
+	assert.False(t, d.First("a"))
+	assert.True(t, d.First(""))
+	assert.True(t, d.First(""))
+
+	// The oldest ID is forgotten once full
+	assert.True(t, d.First("b"))
+	assert.True(t, d.First("c"))
+	assert.False(t, d.First("b"))
+	assert.False(t, d.First("c"))
+	assert.True(t, d.First("a"))
+	assert.False(t, d.First("c"))
+	assert.True(t, d.First("b"))
+}
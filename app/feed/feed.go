@@ -0,0 +1,217 @@
+// Package feed renders the recent alerts as RSS 2.0 and Atom 1.0 feeds
+// Newsreaders, monitoring tools and status pages can follow the alerts without a custom integration
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/models"
+)
+
+// DefaultSize is the number of alerts in a feed (if no limit is requested)
+const DefaultSize = 50
+
+// Content types of the feeds
+const (
+	ContentTypeAtom = "application/atom+xml; charset=utf-8"
+	ContentTypeRSS  = "application/rss+xml; charset=utf-8"
+)
+
+// Title is the title of the feeds
+const Title = "Bitcoin SV Alert System"
+
+// Item is an alert in the feed
+type Item struct {
+	AlertType string    // Alert type name (the item category)
+	Hash      string    // Alert hash
+	Link      string    // URL of the alert in the API
+	Published time.Time // Alert timestamp
+	Sequence  uint32    // Alert sequence number
+	Summary   string    // Decoded alert message
+	Title     string    // e.g. Alert 42 (Informational)
+}
+
+// Feed is the recent alerts (newest first)
+type Feed struct {
+	Items   []*Item
+	Link    string    // URL of the web server
+	Self    string    // URL of the feed
+	Updated time.Time // Time of the newest alert (or now if there are no alerts)
+}
+
+// NewItem will create the item of the alert (links to the alert under the base URL)
+// The summary is empty if the alert cannot be decoded
+func NewItem(alert *models.AlertMessage, baseURL string) *Item {
+	item := &Item{
+		Link:      strings.TrimSuffix(baseURL, "/") + "/v1/alert/" + strconv.FormatUint(uint64(alert.SequenceNumber), 10),
+		Published: alert.CreatedAt.UTC(),
+		Sequence:  alert.SequenceNumber,
+	}
+	if alert.ReadRaw() == nil {
+		if alert.Timestamp() > 0 {
+			item.Published = time.Unix(int64(alert.Timestamp()), 0).UTC()
+		}
+		if am := alert.ProcessAlertMessage(); am != nil && am.Read(alert.GetRawMessage()) == nil {
+			item.Summary = am.MessageString()
+		}
+	}
+	item.AlertType = alert.GetAlertType().Name()
+	item.Hash = alert.Hash
+	item.Title = fmt.Sprintf("Alert %d (%s)", item.Sequence, item.AlertType)
+	return item
+}
+
+// New will create the feed of the alerts (newest first)
+func New(alerts []*models.AlertMessage, baseURL, self string) *Feed {
+	f := &Feed{Link: baseURL, Self: self, Updated: time.Now().UTC()}
+	for _, alert := range alerts {
+		f.Items = append(f.Items, NewItem(alert, baseURL))
+	}
+	if len(f.Items) > 0 && !f.Items[0].Published.IsZero() {
+		f.Updated = f.Items[0].Published
+	}
+	return f
+}
+
+// rss is an RSS 2.0 document
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+	Version string     `xml:"version,attr"`
+}
+
+// rssChannel is the channel of an RSS document
+type rssChannel struct {
+	AtomLink      rssAtomLink `xml:"atom:link"` // Self link (recommended by the RSS validators)
+	Description   string      `xml:"description"`
+	Items         []*rssItem  `xml:"item"`
+	LastBuildDate string      `xml:"lastBuildDate"`
+	Link          string      `xml:"link"`
+	Title         string      `xml:"title"`
+}
+
+// rssAtomLink is the self link of an RSS channel
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// rssItem is an item of an RSS channel
+type rssItem struct {
+	Category    string  `xml:"category"`
+	Description string  `xml:"description,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+	Link        string  `xml:"link"`
+	PubDate     string  `xml:"pubDate"`
+	Title       string  `xml:"title"`
+}
+
+// rssGUID is the unique ID of an RSS item (the alert hash)
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RSS will render the feed as RSS 2.0
+func (f *Feed) RSS() ([]byte, error) {
+	doc := &rss{
+		Atom: "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			AtomLink:      rssAtomLink{Href: f.Self, Rel: "self", Type: "application/rss+xml"},
+			Description:   "Alerts published on the Bitcoin SV alert network",
+			LastBuildDate: f.Updated.Format(time.RFC1123Z),
+			Link:          f.Link,
+			Title:         Title,
+		},
+		Version: "2.0",
+	}
+	for _, item := range f.Items {
+		doc.Channel.Items = append(doc.Channel.Items, &rssItem{
+			Category:    item.AlertType,
+			Description: item.Summary,
+			GUID:        rssGUID{Value: guid(item)},
+			Link:        item.Link,
+			PubDate:     item.Published.Format(time.RFC1123Z),
+			Title:       item.Title,
+		})
+	}
+	return marshal(doc)
+}
+
+// atomFeed is an Atom 1.0 document
+type atomFeed struct {
+	XMLName xml.Name     `xml:"http://www.w3.org/2005/Atom feed"`
+	Entries []*atomEntry `xml:"entry"`
+	ID      string       `xml:"id"`
+	Links   []*atomLink  `xml:"link"`
+	Title   string       `xml:"title"`
+	Updated string       `xml:"updated"`
+}
+
+// atomLink is a link of an Atom feed or entry
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+// atomCategory is the category of an Atom entry
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// atomEntry is an entry of an Atom feed
+type atomEntry struct {
+	Category  atomCategory `xml:"category"`
+	ID        string       `xml:"id"`
+	Link      atomLink     `xml:"link"`
+	Published string       `xml:"published"`
+	Summary   string       `xml:"summary,omitempty"`
+	Title     string       `xml:"title"`
+	Updated   string       `xml:"updated"`
+}
+
+// Atom will render the feed as Atom 1.0
+func (f *Feed) Atom() ([]byte, error) {
+	doc := &atomFeed{
+		ID:      f.Self,
+		Links:   []*atomLink{{Href: f.Self, Rel: "self"}, {Href: f.Link, Rel: "alternate"}},
+		Title:   Title,
+		Updated: f.Updated.Format(time.RFC3339),
+	}
+	for _, item := range f.Items {
+		published := item.Published.Format(time.RFC3339)
+		doc.Entries = append(doc.Entries, &atomEntry{
+			Category:  atomCategory{Term: item.AlertType},
+			ID:        guid(item),
+			Link:      atomLink{Href: item.Link},
+			Published: published,
+			Summary:   item.Summary,
+			Title:     item.Title,
+			Updated:   published, // Alerts never change
+		})
+	}
+	return marshal(doc)
+}
+
+// guid will return the permanent ID of the item (the same on every node, unlike the link)
+func guid(item *Item) string {
+	if len(item.Hash) > 0 {
+		return "urn:bsv-alert:" + item.Hash
+	}
+	return "urn:bsv-alert:sequence:" + strconv.FormatUint(uint64(item.Sequence), 10)
+}
+
+// marshal will encode the document with the XML header
+func marshal(doc interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,103 @@
+package feed
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAlertRaw is a signed informational alert (sequence 21, "This is a test")
+const testAlertRaw = "01000000150000005247bd6500000000010000000e546869732069732061207465737420bd1521c60845302ca088f8626ce77cef64e65b21f09de1cd2aa466e774421d61310141628fa14478af8c8134540b08149db916085f8d61c0277b8b9f1473c0161fb79c0667e48af7fefcdb963673c5a03546f7885ece9b4d2fb44138eee3c53ed055a575872fc3f93afad934abd77038d5f546df639259e9b5192bdcedc036f6b61f51312c120d76e5031709a9b03dc52ef4e8198eb4591703d5c2a56cc2c1960e5c1aeb792acbd68d3c0bd2f3000345a0d6b979a276068ef24ffafd33c22eba01ef"
+
+// newTestFeed will create the feed of the test alert
+func newTestFeed(t *testing.T) *Feed {
+	f := New([]*models.AlertMessage{{Raw: testAlertRaw, SequenceNumber: 21}}, "https://alerts.example.com", "https://alerts.example.com/v1/alerts/feed.rss")
+	require.Len(t, f.Items, 1)
+	return f
+}
+
+// TestNewItem will test the method NewItem()
+func TestNewItem(t *testing.T) {
+	t.Parallel()
+
+	t.Run("decoded alert", func(t *testing.T) {
+		item := newTestFeed(t).Items[0]
+		assert.Equal(t, "Alert 21 (Informational)", item.Title)
+		assert.Equal(t, "Informational", item.AlertType)
+		assert.Equal(t, "https://alerts.example.com/v1/alert/21", item.Link)
+		assert.Contains(t, item.Summary, "This is a test")
+		assert.Len(t, item.Hash, 64)
+		assert.Equal(t, time.Unix(0x65bd4752, 0).UTC(), item.Published)
+	})
+
+	t.Run("undecodable alert", func(t *testing.T) {
+		created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		alert := &models.AlertMessage{Raw: "00", SequenceNumber: 7}
+		alert.CreatedAt = created
+		item := NewItem(alert, "https://alerts.example.com/")
+		assert.Equal(t, "https://alerts.example.com/v1/alert/7", item.Link)
+		assert.Empty(t, item.Summary)
+		assert.Equal(t, created, item.Published)
+		assert.Equal(t, "urn:bsv-alert:sequence:7", guid(item))
+	})
+}
+
+// TestFeed_RSS will test the method RSS()
+func TestFeed_RSS(t *testing.T) {
+	t.Parallel()
+
+	f := newTestFeed(t)
+	body, err := f.RSS()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(body), xml.Header))
+
+	doc := &rss{}
+	require.NoError(t, xml.Unmarshal(body, doc))
+	assert.Equal(t, "2.0", doc.Version)
+	assert.Equal(t, Title, doc.Channel.Title)
+	require.Len(t, doc.Channel.Items, 1)
+	item := doc.Channel.Items[0]
+	assert.Equal(t, "Alert 21 (Informational)", item.Title)
+	assert.Equal(t, "Informational", item.Category)
+	assert.Equal(t, "urn:bsv-alert:"+f.Items[0].Hash, item.GUID.Value)
+	assert.False(t, item.GUID.IsPermaLink)
+	assert.Equal(t, f.Items[0].Published.Format(time.RFC1123Z), item.PubDate)
+	assert.Contains(t, string(body), `<atom:link href="https://alerts.example.com/v1/alerts/feed.rss" rel="self"`)
+}
+
+// TestFeed_Atom will test the method Atom()
+func TestFeed_Atom(t *testing.T) {
+	t.Parallel()
+
+	f := newTestFeed(t)
+	body, err := f.Atom()
+	require.NoError(t, err)
+
+	doc := &atomFeed{}
+	require.NoError(t, xml.Unmarshal(body, doc))
+	assert.Equal(t, "http://www.w3.org/2005/Atom", doc.XMLName.Space)
+	assert.Equal(t, f.Items[0].Published.Format(time.RFC3339), doc.Updated)
+	require.Len(t, doc.Entries, 1)
+	entry := doc.Entries[0]
+	assert.Equal(t, "Alert 21 (Informational)", entry.Title)
+	assert.Equal(t, "https://alerts.example.com/v1/alert/21", entry.Link.Href)
+	assert.Equal(t, "Informational", entry.Category.Term)
+	assert.Contains(t, entry.Summary, "This is a test")
+}
+
+// TestNew will test the feed without alerts
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	f := New(nil, "https://alerts.example.com", "https://alerts.example.com/v1/alerts/feed.atom")
+	assert.Empty(t, f.Items)
+	assert.WithinDuration(t, time.Now(), f.Updated, time.Minute)
+	body, err := f.Atom()
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "<entry>")
+}
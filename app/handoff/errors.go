@@ -0,0 +1,9 @@
+package handoff
+
+import "errors"
+
+// Errors for the handoff package
+var (
+	ErrNotReady           = errors.New("the new process was not ready")
+	ErrRestartUnsupported = errors.New("restarting with the listeners is not supported on this platform")
+)
@@ -0,0 +1,149 @@
+// Package handoff is the zero-downtime restart of the alert system (e.g. to upgrade the binary)
+// On a restart the running process starts the executable again with its listening sockets and instance lock as
+// inherited files, waits until the new process is ready, then shuts down gracefully: the listening ports are never
+// closed, so the API clients are served by one process or the other. The P2P listener is not inherited, libp2p sets
+// SO_REUSEPORT on it and the new process listens on the same port while the old one is still running.
+package handoff
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Environment variables of the process started by Restart
+const (
+	EnvFiles   = "ALERT_SYSTEM_HANDOFF_FILES"    // Inherited files (name=fd, comma separated)
+	EnvReadyFD = "ALERT_SYSTEM_HANDOFF_READY_FD" // Pipe to write to once ready (see Ready)
+)
+
+// The handoff state of the process (loaded once from the environment)
+var (
+	inherited map[string]*os.File             // Files inherited from the previous process (taken once)
+	listeners = map[string]*net.TCPListener{} // Listeners passed on a restart (by name)
+	loadOnce  sync.Once
+	mu        sync.Mutex
+	ready     *os.File // Pipe to the previous process (nil if not started by a restart)
+)
+
+// load will read the inherited files from the environment (the variables are removed, they are only
+// meant for this process and not the next one)
+func load() {
+	loadOnce.Do(func() {
+		inherited = make(map[string]*os.File)
+		for _, entry := range strings.Split(os.Getenv(EnvFiles), ",") {
+			name, fd, ok := strings.Cut(entry, "=")
+			if n, err := strconv.Atoi(fd); ok && err == nil && n > 2 {
+				inherited[name] = os.NewFile(uintptr(n), name)
+			}
+		}
+		if n, err := strconv.Atoi(os.Getenv(EnvReadyFD)); err == nil && n > 2 {
+			ready = os.NewFile(uintptr(n), "ready")
+		}
+		_ = os.Unsetenv(EnvFiles)
+		_ = os.Unsetenv(EnvReadyFD)
+	})
+}
+
+// Restarted will return true if the process was started by Restart (Ready must be called once it is serving)
+func Restarted() bool {
+	load()
+	mu.Lock()
+	defer mu.Unlock()
+	return ready != nil
+}
+
+// File will take the inherited file named name (nil if it was not inherited, or was already taken)
+func File(name string) *os.File {
+	load()
+	mu.Lock()
+	defer mu.Unlock()
+	file := inherited[name]
+	delete(inherited, name)
+	return file
+}
+
+// Listen will return the TCP listener named name, passed to the next process on a restart
+// It is the inherited socket if the process was started by a restart, or a new listener on the address
+// (with SO_REUSEPORT if reusePort is true, so another process can listen on the same address, ignored on Windows)
+func Listen(ctx context.Context, name, address string, reusePort bool) (net.Listener, error) {
+	var listener net.Listener
+	var err error
+	if file := File(name); file != nil {
+		listener, err = net.FileListener(file)
+		_ = file.Close() // The listener has its own copy
+		if err != nil {
+			return nil, fmt.Errorf("inherited %s listener: %w", name, err)
+		}
+	} else {
+		lc := net.ListenConfig{}
+		if reusePort {
+			lc.Control = controlReusePort
+		}
+		if listener, err = lc.Listen(ctx, "tcp", address); err != nil {
+			return nil, err
+		}
+	}
+
+	// Register the listener to pass it on a restart
+	if tcp, ok := listener.(*net.TCPListener); ok {
+		mu.Lock()
+		listeners[name] = tcp
+		mu.Unlock()
+	}
+	return listener, nil
+}
+
+// Ready will tell the previous process the new process is ready, so it shuts down (nothing if not restarted)
+func Ready() error {
+	load()
+	mu.Lock()
+	defer mu.Unlock()
+	if ready == nil {
+		return nil
+	}
+	_, err := ready.Write([]byte{1})
+	if closeErr := ready.Close(); err == nil {
+		err = closeErr
+	}
+	ready = nil
+	return err
+}
+
+// listenerFiles will return copies of the registered listeners (by name), the caller closes them
+func listenerFiles() (map[string]*os.File, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	files := make(map[string]*os.File, len(listeners))
+	for name, listener := range listeners {
+		file, err := listener.File()
+		if err != nil {
+			closeFiles(files)
+			return nil, fmt.Errorf("%s listener: %w", name, err)
+		}
+		files[name] = file
+	}
+	return files, nil
+}
+
+// closeFiles will close the files
+func closeFiles(files map[string]*os.File) {
+	for _, file := range files {
+		_ = file.Close()
+	}
+}
+
+// sortedNames will return the names of the files in order (the inherited file descriptors are stable)
+func sortedNames(files map[string]*os.File) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,120 @@
+//go:build !windows
+
+package handoff
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// NotifyRestart will relay the restart signal (SIGUSR2) to the channel
+func NotifyRestart(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGUSR2)
+}
+
+// Restart will start the executable again with the same arguments, passing the registered listeners and the files
+// (e.g. the instance lock), and wait until the new process is ready (see Ready) or the context is done
+// The new process is killed if it is not ready, the caller keeps running. The caller shuts down once it is ready.
+func Restart(ctx context.Context, files map[string]*os.File) (pid int, err error) {
+	var executable string
+	if executable, err = os.Executable(); err != nil {
+		return 0, err
+	}
+
+	// The listeners (copies, closed once passed) and the files of the caller
+	var passed map[string]*os.File
+	if passed, err = listenerFiles(); err != nil {
+		return 0, err
+	}
+	defer closeFiles(passed)
+	all := make(map[string]*os.File, len(passed)+len(files))
+	for name, file := range passed {
+		all[name] = file
+	}
+	for name, file := range files {
+		if file != nil {
+			all[name] = file
+		}
+	}
+
+	// The new process writes to the pipe once it is ready
+	var readyReader, readyWriter *os.File
+	if readyReader, readyWriter, err = os.Pipe(); err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = readyReader.Close()
+	}()
+
+	// Start the new process with the files (from fd 3, the stdin, stdout and stderr are shared)
+	cmd := exec.Command(executable, os.Args[1:]...) //nolint:gosec // Our own executable and arguments
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	entries := make([]string, 0, len(all))
+	for _, name := range sortedNames(all) {
+		cmd.ExtraFiles = append(cmd.ExtraFiles, all[name])
+		entries = append(entries, fmt.Sprintf("%s=%d", name, 2+len(cmd.ExtraFiles)))
+	}
+	cmd.ExtraFiles = append(cmd.ExtraFiles, readyWriter)
+	cmd.Env = append(environ(),
+		EnvFiles+"="+strings.Join(entries, ","),
+		fmt.Sprintf("%s=%d", EnvReadyFD, 2+len(cmd.ExtraFiles)),
+	)
+	err = cmd.Start()
+	_ = readyWriter.Close() // The new process has its own copy (EOF once it exits)
+	if err != nil {
+		return 0, err
+	}
+
+	// Wait for the new process to be ready
+	result := make(chan error, 1)
+	go func() {
+		b := make([]byte, 1)
+		if _, readErr := readyReader.Read(b); readErr != nil {
+			result <- fmt.Errorf("%w: exited before it was ready (%s)", ErrNotReady, readErr.Error())
+			return
+		}
+		result <- nil
+	}()
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = fmt.Errorf("%w: %s", ErrNotReady, ctx.Err().Error())
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return 0, err
+	}
+	pid = cmd.Process.Pid
+	_ = cmd.Process.Release()
+	return pid, nil
+}
+
+// environ will return the environment of the new process (the systemd watchdog is fed by the new process)
+func environ() []string {
+	env := make([]string, 0, len(os.Environ()))
+	for _, v := range os.Environ() {
+		if !strings.HasPrefix(v, "WATCHDOG_PID=") {
+			env = append(env, v)
+		}
+	}
+	return env
+}
+
+// controlReusePort will set SO_REUSEPORT on the socket
+func controlReusePort(_, _ string, c syscall.RawConn) error {
+	var err error
+	if controlErr := c.Control(func(fd uintptr) {
+		err = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1) //nolint:gosec // File descriptors fit in an int
+	}); controlErr != nil {
+		return controlErr
+	}
+	return err
+}
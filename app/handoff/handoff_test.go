@@ -0,0 +1,63 @@
+//go:build !windows
+
+package handoff
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandoff will test inheriting a listener and telling the previous process the new one is ready
+// (the environment is read once, so the whole handoff is tested in one test)
+func TestHandoff(t *testing.T) {
+	// The listener of the previous process and the ready pipe
+	previous, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() {
+		_ = previous.Close()
+	}()
+	file, err := previous.(*net.TCPListener).File()
+	require.NoError(t, err)
+	readyReader, readyWriter, err := os.Pipe()
+	require.NoError(t, err)
+	defer func() {
+		_ = readyReader.Close()
+	}()
+	t.Setenv(EnvFiles, fmt.Sprintf("web=%d", file.Fd()))
+	t.Setenv(EnvReadyFD, fmt.Sprintf("%d", readyWriter.Fd()))
+
+	// The inherited listener is on the same address, the environment is removed
+	assert.True(t, Restarted())
+	assert.Empty(t, os.Getenv(EnvFiles))
+	listener, err := Listen(context.Background(), "web", "127.0.0.1:1", false)
+	require.NoError(t, err)
+	assert.Equal(t, previous.Addr().String(), listener.Addr().String())
+	assert.Nil(t, File("web"))
+
+	// Registered to be passed on the next restart
+	files, err := listenerFiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web"}, sortedNames(files))
+	closeFiles(files)
+	require.NoError(t, listener.Close())
+
+	// Not inherited, a new listener is created
+	listener, err = Listen(context.Background(), "acme", "127.0.0.1:0", true)
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+
+	// Ready is written once
+	require.NoError(t, Ready())
+	b := make([]byte, 1)
+	_, err = readyReader.Read(b)
+	require.NoError(t, err)
+	assert.Equal(t, byte(1), b[0])
+	assert.False(t, Restarted())
+	require.NoError(t, Ready())
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package handoff
+
+import (
+	"context"
+	"os"
+	"syscall"
+)
+
+// NotifyRestart will do nothing (there is no restart signal on Windows)
+func NotifyRestart(_ chan<- os.Signal) {}
+
+// Restart is not supported on Windows (the sockets are not inherited)
+func Restart(_ context.Context, _ map[string]*os.File) (int, error) {
+	return 0, ErrRestartUnsupported
+}
+
+// controlReusePort will do nothing (SO_REUSEADDR on Windows lets another process steal the port)
+func controlReusePort(_, _ string, _ syscall.RawConn) error {
+	return nil
+}
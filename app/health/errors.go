@@ -0,0 +1,8 @@
+package health
+
+import "errors"
+
+// Errors for the health package
+var (
+	ErrUnhealthy = errors.New("unhealthy")
+)
@@ -0,0 +1,185 @@
+// Package health is the aggregated health of the alert system
+// Each subsystem (datastore, node, peers, sync) is checked and the results are combined into a single
+// status document, so /readyz, the status command, the heartbeat and the health metrics give the same answer
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/metrics"
+)
+
+// DefaultCheckTimeout is the max time a single check can take before it fails
+const DefaultCheckTimeout = 5 * time.Second
+
+// Health statuses
+const (
+	StatusDegraded  = "degraded"  // A non-critical check failed (the alert system still enforces alerts)
+	StatusOK        = "ok"        // All checks passed
+	StatusUnhealthy = "unhealthy" // A critical check failed
+)
+
+// Checker checks a subsystem
+type Checker struct {
+	Check    func(ctx context.Context) (string, error) // Returns a summary (e.g. "8 peers") or why the check failed
+	Critical bool                                      // The alert system is unhealthy if the check fails (degraded otherwise)
+	Name     string                                    // Check name (e.g. datastore)
+}
+
+// Check is the result of a checker
+type Check struct {
+	Critical   bool   `json:"critical"`
+	DurationMS int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+	Message    string `json:"message,omitempty"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+}
+
+// Report is the aggregated health of the alert system
+type Report struct {
+	Checks []*Check  `json:"checks"` // Ordered by name
+	Status string    `json:"status"` // Worst status of the checks
+	Time   time.Time `json:"time"`
+}
+
+// Healthy will return true unless a critical check failed (a degraded system is still healthy)
+func (r *Report) Healthy() bool {
+	return r.Status != StatusUnhealthy
+}
+
+// Err will return nil unless a critical check failed, then the error lists the failed critical checks
+func (r *Report) Err() error {
+	if r.Healthy() {
+		return nil
+	}
+	failed := make([]string, 0, len(r.Checks))
+	for _, c := range r.Checks {
+		if c.Status == StatusUnhealthy {
+			failed = append(failed, c.Name+": "+c.Error)
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrUnhealthy, strings.Join(failed, ", "))
+}
+
+// Check will return the result of the named check (nil if it was not checked)
+func (r *Report) Check(name string) *Check {
+	for _, c := range r.Checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// String will return the report as a summary (one line per check)
+func (r *Report) String() string {
+	lines := []string{"status: " + r.Status}
+	for _, c := range r.Checks {
+		line := fmt.Sprintf("  %-10s %-9s", c.Name, c.Status)
+		if len(c.Error) > 0 {
+			line += " " + c.Error
+		} else if len(c.Message) > 0 {
+			line += " " + c.Message
+		}
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Value will return the metric value of the status (1 ok, 0.5 degraded and 0 unhealthy)
+func Value(status string) float64 {
+	switch status {
+	case StatusOK:
+		return 1
+	case StatusDegraded:
+		return 0.5
+	}
+	return 0
+}
+
+// Service evaluates the checkers into a report
+type Service struct {
+	checkers []Checker
+	timeout  time.Duration
+}
+
+// NewService will create a new health service for the checkers (DefaultCheckTimeout if the timeout is 0)
+func NewService(timeout time.Duration, checkers ...Checker) *Service {
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+	return &Service{checkers: checkers, timeout: timeout}
+}
+
+// Check will run the checkers concurrently and return the report (the health metrics are set from the report)
+// A nil service has no checks and is ok
+func (s *Service) Check(ctx context.Context) *Report {
+	report := &Report{Checks: make([]*Check, 0), Status: StatusOK, Time: time.Now().UTC()}
+	if s == nil {
+		return report
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, checker := range s.checkers {
+		wg.Add(1)
+		go func(checker Checker) {
+			defer wg.Done()
+			c := s.run(ctx, checker)
+			mu.Lock()
+			report.Checks = append(report.Checks, c)
+			mu.Unlock()
+		}(checker)
+	}
+	wg.Wait()
+
+	sort.Slice(report.Checks, func(i, j int) bool {
+		return report.Checks[i].Name < report.Checks[j].Name
+	})
+	for _, c := range report.Checks {
+		if c.Status == StatusUnhealthy {
+			report.Status = StatusUnhealthy
+		} else if c.Status == StatusDegraded && report.Status == StatusOK {
+			report.Status = StatusDegraded
+		}
+		metrics.HealthChecks.WithLabelValues(c.Name).Set(Value(c.Status))
+	}
+	metrics.Health.Set(Value(report.Status))
+	return report
+}
+
+// run will run the checker with the timeout
+func (s *Service) run(ctx context.Context, checker Checker) (c *Check) {
+	c = &Check{Critical: checker.Critical, Name: checker.Name, Status: StatusOK}
+	start := time.Now()
+	defer func() {
+		c.DurationMS = time.Since(start).Milliseconds()
+		if r := recover(); r != nil {
+			c.Error = fmt.Sprintf("check panicked: %v", r)
+			c.Status = failedStatus(checker)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	var err error
+	if c.Message, err = checker.Check(ctx); err != nil {
+		c.Error = err.Error()
+		c.Status = failedStatus(checker)
+	}
+	return c
+}
+
+// failedStatus will return the status of a failed check
+func failedStatus(checker Checker) string {
+	if checker.Critical {
+		return StatusUnhealthy
+	}
+	return StatusDegraded
+}
@@ -0,0 +1,108 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errCheckFailed is the error returned by the failing checks
+var errCheckFailed = errors.New("check failed")
+
+// checker will return a checker with the result
+func checker(name string, critical bool, err error) Checker {
+	return Checker{Check: func(context.Context) (string, error) {
+		if err != nil {
+			return "", err
+		}
+		return name + " is fine", nil
+	}, Critical: critical, Name: name}
+}
+
+// TestService_Check will test aggregating the checks into a report
+func TestService_Check(t *testing.T) {
+	t.Run("all checks pass", func(t *testing.T) {
+		report := NewService(0, checker("node", true, nil), checker("datastore", true, nil)).Check(context.Background())
+		assert.Equal(t, StatusOK, report.Status)
+		assert.True(t, report.Healthy())
+		require.Len(t, report.Checks, 2)
+		assert.Equal(t, "datastore", report.Checks[0].Name)
+		assert.Equal(t, "datastore is fine", report.Checks[0].Message)
+		assert.Equal(t, float64(1), testutil.ToFloat64(metrics.Health))
+		assert.Equal(t, float64(1), testutil.ToFloat64(metrics.HealthChecks.WithLabelValues("node")))
+	})
+
+	t.Run("non-critical check failed", func(t *testing.T) {
+		report := NewService(0, checker("node", true, nil), checker("peers", false, errCheckFailed)).Check(context.Background())
+		assert.Equal(t, StatusDegraded, report.Status)
+		assert.True(t, report.Healthy())
+		assert.Equal(t, StatusDegraded, report.Check("peers").Status)
+		assert.Equal(t, errCheckFailed.Error(), report.Check("peers").Error)
+		assert.Equal(t, 0.5, testutil.ToFloat64(metrics.Health))
+	})
+
+	t.Run("critical check failed", func(t *testing.T) {
+		report := NewService(0, checker("node", true, errCheckFailed), checker("peers", false, errCheckFailed)).Check(context.Background())
+		assert.Equal(t, StatusUnhealthy, report.Status)
+		assert.False(t, report.Healthy())
+		assert.Equal(t, StatusUnhealthy, report.Check("node").Status)
+		assert.Equal(t, float64(0), testutil.ToFloat64(metrics.Health))
+		assert.Equal(t, float64(0), testutil.ToFloat64(metrics.HealthChecks.WithLabelValues("node")))
+	})
+
+	t.Run("check times out", func(t *testing.T) {
+		slow := Checker{Check: func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}, Critical: true, Name: "node"}
+		report := NewService(10*time.Millisecond, slow).Check(context.Background())
+		assert.Equal(t, StatusUnhealthy, report.Status)
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Check("node").Error)
+	})
+
+	t.Run("check panics", func(t *testing.T) {
+		panics := Checker{Check: func(context.Context) (string, error) {
+			panic("boom")
+		}, Name: "sync"}
+		report := NewService(0, panics).Check(context.Background())
+		assert.Equal(t, StatusDegraded, report.Status)
+		assert.Equal(t, "check panicked: boom", report.Check("sync").Error)
+	})
+
+	t.Run("nil service", func(t *testing.T) {
+		var s *Service
+		report := s.Check(context.Background())
+		assert.Equal(t, StatusOK, report.Status)
+		assert.Nil(t, report.Check("node"))
+	})
+}
+
+// TestReport_String will test the report summary
+func TestReport_String(t *testing.T) {
+	report := &Report{Checks: []*Check{
+		{Message: "localhost", Name: "node", Status: StatusOK},
+		{Error: "no connected peers", Name: "peers", Status: StatusDegraded},
+	}, Status: StatusDegraded}
+	assert.Equal(t, "status: degraded\n  node       ok        localhost\n  peers      degraded  no connected peers", report.String())
+}
+
+// TestReport_Err will test the error of an unhealthy report
+func TestReport_Err(t *testing.T) {
+	report := &Report{Checks: []*Check{
+		{Message: "localhost", Name: "node", Status: StatusOK},
+		{Error: "no connected peers", Name: "peers", Status: StatusDegraded},
+	}, Status: StatusDegraded}
+	require.NoError(t, report.Err())
+
+	report.Checks = append(report.Checks, &Check{Critical: true, Error: "database is locked", Name: "datastore", Status: StatusUnhealthy})
+	report.Status = StatusUnhealthy
+	err := report.Err()
+	require.ErrorIs(t, err, ErrUnhealthy)
+	assert.Equal(t, "unhealthy: datastore: database is locked", err.Error())
+}
@@ -0,0 +1,9 @@
+package heartbeat
+
+import "errors"
+
+// Heartbeat errors
+var (
+	ErrNodeUnhealthy = errors.New("node did not respond to the heartbeat")
+	ErrPostFailed    = errors.New("heartbeat post failed")
+)
@@ -0,0 +1,76 @@
+// Package heartbeat is the periodic heartbeat of the alert system (uptime, latest sequence, peers and node health)
+// The heartbeat is logged, exported as metrics and optionally posted to a dead man's switch
+// (Healthchecks.io style: the URL is pinged while healthy and <url>/fail is pinged when the node is unhealthy)
+package heartbeat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+)
+
+// DefaultPostTimeout is the max time to wait for the heartbeat URL to respond
+const DefaultPostTimeout = 10 * time.Second
+
+// failPath is appended to the heartbeat URL when the node is unhealthy
+const failPath = "/fail"
+
+// Heartbeat is the state of the alert system sent with each heartbeat
+type Heartbeat struct {
+	LatestSequence uint32    `json:"latest_sequence"`      // Latest alert sequence in the datastore
+	NodeError      string    `json:"node_error,omitempty"` // Why the node is unhealthy
+	NodeHealthy    bool      `json:"node_healthy"`         // True if the node responded to an RPC call
+	PeerCount      int       `json:"peer_count"`           // Connected peers
+	PeerID         string    `json:"peer_id"`              // Our peer ID
+	Time           time.Time `json:"time"`                 // When the heartbeat was taken
+	UptimeSeconds  int64     `json:"uptime_seconds"`       // Seconds since the alert system started
+}
+
+// String will return the heartbeat as a log line
+func (h *Heartbeat) String() string {
+	s := fmt.Sprintf(
+		"heartbeat: uptime %s, latest sequence %d, %d peers, node healthy: %t",
+		time.Duration(h.UptimeSeconds)*time.Second, h.LatestSequence, h.PeerCount, h.NodeHealthy,
+	)
+	if len(h.NodeError) > 0 {
+		s += " (" + h.NodeError + ")"
+	}
+	return s
+}
+
+// Post will post the heartbeat to the URL (or <url>/fail if the node is unhealthy)
+func Post(ctx context.Context, httpClient config.HTTPInterface, url string, h *Heartbeat) error {
+	body, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	if !h.NodeHealthy {
+		url = strings.TrimRight(url, "/") + failPath
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultPostTimeout)
+	defer cancel()
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body)); err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var res *http.Response
+	if res, err = httpClient.Do(req); err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: status code %d", ErrPostFailed, res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package heartbeat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPost will test posting the heartbeat to the heartbeat URL
+func TestPost(t *testing.T) {
+	t.Parallel()
+
+	var path string
+	var received *Heartbeat
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		path = req.URL.Path
+		received = new(Heartbeat)
+		_ = json.NewDecoder(req.Body).Decode(received)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	t.Run("healthy", func(t *testing.T) {
+		h := &Heartbeat{LatestSequence: 7, NodeHealthy: true, PeerCount: 3, Time: time.Now().UTC(), UptimeSeconds: 60}
+		require.NoError(t, Post(context.Background(), server.Client(), server.URL+"/ping/abc", h))
+		assert.Equal(t, "/ping/abc", path)
+		require.NotNil(t, received)
+		assert.Equal(t, uint32(7), received.LatestSequence)
+		assert.Equal(t, 3, received.PeerCount)
+	})
+
+	t.Run("unhealthy node pings the fail url", func(t *testing.T) {
+		h := &Heartbeat{NodeError: "connection refused"}
+		require.NoError(t, Post(context.Background(), server.Client(), server.URL+"/ping/abc/", h))
+		assert.Equal(t, "/ping/abc/fail", path)
+		assert.Equal(t, "connection refused", received.NodeError)
+	})
+
+	t.Run("unexpected status", func(t *testing.T) {
+		status = http.StatusNotFound
+		err := Post(context.Background(), server.Client(), server.URL, &Heartbeat{NodeHealthy: true})
+		require.ErrorIs(t, err, ErrPostFailed)
+	})
+}
+
+// TestHeartbeat_String will test the heartbeat log line
+func TestHeartbeat_String(t *testing.T) {
+	t.Parallel()
+
+	h := &Heartbeat{LatestSequence: 12, PeerCount: 4, NodeHealthy: true, UptimeSeconds: 3661}
+	assert.Equal(t, "heartbeat: uptime 1h1m1s, latest sequence 12, 4 peers, node healthy: true", h.String())
+
+	h = &Heartbeat{NodeError: "timeout"}
+	assert.Equal(t, "heartbeat: uptime 0s, latest sequence 0, 0 peers, node healthy: false (timeout)", h.String())
+}
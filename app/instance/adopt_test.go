@@ -0,0 +1,42 @@
+//go:build !windows
+
+package instance
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAdopt will test taking over the lock of the previous process on a restart
+func TestAdopt(t *testing.T) {
+	dir := t.TempDir()
+	lockFile, pidFile := filepath.Join(dir, "private_key.lock"), filepath.Join(dir, "alert-system.pid")
+	previous, err := Acquire(lockFile, pidFile)
+	require.NoError(t, err)
+
+	// The inherited descriptor (a copy, like the one passed to the new process)
+	fd, err := syscall.Dup(int(previous.File().Fd())) //nolint:gosec // File descriptors fit in an int
+	require.NoError(t, err)
+	g, err := Adopt(os.NewFile(uintptr(fd), lockFile), pidFile)
+	require.NoError(t, err)
+
+	// The previous process leaves the lock and the PID file
+	previous.Handoff()
+	require.NoError(t, previous.Release())
+	_, err = Acquire(lockFile, "")
+	require.ErrorIs(t, err, ErrLocked)
+	pid, err := ReadPID(pidFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), pid)
+
+	// Released by the new process
+	require.NoError(t, g.Release())
+	g, err = Acquire(lockFile, "")
+	require.NoError(t, err)
+	require.NoError(t, g.Release())
+}
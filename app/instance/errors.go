@@ -0,0 +1,8 @@
+package instance
+
+import "errors"
+
+// Errors for the instance package
+var (
+	ErrLocked = errors.New("another alert system instance is running with the same identity")
+)
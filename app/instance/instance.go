@@ -0,0 +1,152 @@
+// Package instance guards against running two alert systems with the same identity
+// An exclusive lock is held on a lock file (next to the P2P private key by default) for the life of the process,
+// so a second instance enforcing against the same node with the same peer ID fails to start.
+// The lock is released by the OS if the process dies, a stale lock file does not block a restart.
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// fileMode is the mode of the lock and PID files
+const fileMode = 0o644
+
+// Guard is the lock and PID file held by the running instance
+type Guard struct {
+	handedOff bool // The lock and PID file belong to the next process (see Handoff)
+	lock      *os.File
+	pidFile   string
+}
+
+// Acquire will lock the lock file and write the PID file (each is skipped if its path is empty)
+// ErrLocked is returned (with the PID of the other instance if known) if another instance holds the lock
+func Acquire(lockFile, pidFile string) (*Guard, error) {
+	g := &Guard{}
+	if len(lockFile) > 0 {
+		lock, err := os.OpenFile(lockFile, os.O_RDWR|os.O_CREATE, fileMode) //nolint:gosec // Configured path
+		if err != nil {
+			return nil, err
+		}
+		if err = lockFileExclusive(lock); err != nil {
+			_ = lock.Close()
+			if errors.Is(err, ErrLocked) {
+				return nil, lockedError(lockFile)
+			}
+			return nil, err
+		}
+
+		// Record our PID in the lock file (to tell which process holds it)
+		if err = writePID(lock); err != nil {
+			_ = lock.Close()
+			return nil, err
+		}
+		g.lock = lock
+	}
+	if err := g.writePIDFile(pidFile); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Adopt will take over the lock inherited from the previous process (a restart, see Guard.File) and write our PID
+// The lock is shared with the previous process until it releases its copy, so no other instance can start in between
+func Adopt(lock *os.File, pidFile string) (*Guard, error) {
+	// The inherited descriptor shares the lock of the previous process, locking it again succeeds
+	if err := lockFileExclusive(lock); err != nil {
+		_ = lock.Close()
+		return nil, err
+	} else if err = writePID(lock); err != nil {
+		_ = lock.Close()
+		return nil, err
+	}
+	g := &Guard{lock: lock}
+	if err := g.writePIDFile(pidFile); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// File will return the lock file to pass to the next process on a restart (nil if there is no lock)
+func (g *Guard) File() *os.File {
+	return g.lock
+}
+
+// Handoff will leave the lock and PID file to the next process (Release only closes our copy of the lock)
+func (g *Guard) Handoff() {
+	g.handedOff = true
+}
+
+// Release will remove the PID file and release the lock (the lock file is kept, it is not a signal)
+func (g *Guard) Release() error {
+	var err error
+	if g.handedOff {
+		g.pidFile = ""
+		if g.lock != nil {
+			err = g.lock.Close()
+			g.lock = nil
+		}
+		return err
+	}
+	if len(g.pidFile) > 0 {
+		if removeErr := os.Remove(g.pidFile); removeErr != nil && !errors.Is(removeErr, fs.ErrNotExist) {
+			err = removeErr
+		}
+		g.pidFile = ""
+	}
+	if g.lock != nil {
+		_ = g.lock.Truncate(0)
+		if closeErr := g.lock.Close(); closeErr != nil && err == nil { // Closing releases the lock
+			err = closeErr
+		}
+		g.lock = nil
+	}
+	return err
+}
+
+// writePIDFile will write our PID to the PID file (skipped if the path is empty, the guard is released on error)
+func (g *Guard) writePIDFile(pidFile string) error {
+	if len(pidFile) == 0 {
+		return nil
+	}
+	if err := os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())+"\n"), fileMode); err != nil {
+		_ = g.Release()
+		return err
+	}
+	g.pidFile = pidFile
+	return nil
+}
+
+// ReadPID will read the PID in a PID or lock file (zero if it is empty)
+func ReadPID(path string) (int, error) {
+	b, err := os.ReadFile(path) //nolint:gosec // Configured path
+	if err != nil {
+		return 0, err
+	}
+	text := strings.TrimSpace(string(b))
+	if len(text) == 0 {
+		return 0, nil
+	}
+	return strconv.Atoi(text)
+}
+
+// writePID will replace the content of the file with our PID
+func writePID(file *os.File) error {
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	return err
+}
+
+// lockedError will return ErrLocked with the PID of the instance holding the lock (if it can be read)
+func lockedError(lockFile string) error {
+	if pid, err := ReadPID(lockFile); err == nil && pid > 0 {
+		return fmt.Errorf("%w: %s is held by PID %d", ErrLocked, lockFile, pid)
+	}
+	return fmt.Errorf("%w: %s", ErrLocked, lockFile)
+}
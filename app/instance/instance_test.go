@@ -0,0 +1,63 @@
+package instance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAcquire will test the lock and the PID file
+func TestAcquire(t *testing.T) {
+	t.Run("lock and pid file", func(t *testing.T) {
+		dir := t.TempDir()
+		lockFile, pidFile := filepath.Join(dir, "private_key.lock"), filepath.Join(dir, "alert-system.pid")
+
+		g, err := Acquire(lockFile, pidFile)
+		require.NoError(t, err)
+		pid, err := ReadPID(pidFile)
+		require.NoError(t, err)
+		assert.Equal(t, os.Getpid(), pid)
+		pid, err = ReadPID(lockFile)
+		require.NoError(t, err)
+		assert.Equal(t, os.Getpid(), pid)
+
+		// A second instance cannot lock
+		_, err = Acquire(lockFile, "")
+		require.ErrorIs(t, err, ErrLocked)
+		assert.Contains(t, err.Error(), "held by PID")
+
+		// Released, the PID file is removed and the lock can be taken again
+		require.NoError(t, g.Release())
+		_, err = os.Stat(pidFile)
+		require.ErrorIs(t, err, os.ErrNotExist)
+		g, err = Acquire(lockFile, "")
+		require.NoError(t, err)
+		require.NoError(t, g.Release())
+	})
+
+	t.Run("nothing configured", func(t *testing.T) {
+		g, err := Acquire("", "")
+		require.NoError(t, err)
+		require.NoError(t, g.Release())
+	})
+
+	t.Run("invalid lock file", func(t *testing.T) {
+		_, err := Acquire(filepath.Join(t.TempDir(), "missing", "private_key.lock"), "")
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrLocked)
+	})
+
+	t.Run("invalid pid file releases the lock", func(t *testing.T) {
+		dir := t.TempDir()
+		lockFile := filepath.Join(dir, "private_key.lock")
+		_, err := Acquire(lockFile, filepath.Join(dir, "missing", "alert-system.pid"))
+		require.Error(t, err)
+
+		g, err := Acquire(lockFile, "")
+		require.NoError(t, err)
+		require.NoError(t, g.Release())
+	})
+}
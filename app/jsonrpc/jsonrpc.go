@@ -0,0 +1,238 @@
+// Package jsonrpc is a JSON-RPC 2.0 server, so the tooling built around bitcoind-style RPC can query the alert system
+// with the same client libraries (bitcoind 1.0 requests, positional or named params and batches are accepted)
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// Version is the JSON-RPC version of the responses
+const Version = "2.0"
+
+// Error codes (JSON-RPC 2.0, and bitcoind for the application errors)
+const (
+	CodeInternalError  = -32603 // Internal JSON-RPC error
+	CodeInvalidParams  = -32602 // Invalid method parameters
+	CodeInvalidRequest = -32600 // The JSON sent is not a valid request
+	CodeMethodNotFound = -32601 // The method does not exist
+	CodeNotFound       = -5     // The requested item was not found (RPC_INVALID_ADDRESS_OR_KEY in bitcoind)
+	CodeParseError     = -32700 // Invalid JSON
+)
+
+// Error is a JSON-RPC error
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error will return the error message
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message)
+}
+
+// NewError will create the error (returned by the methods to set the error code)
+func NewError(code int, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Request is a JSON-RPC request (a notification if the 2.0 request has no ID)
+type Request struct {
+	ID      json.RawMessage `json:"id"`
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// isNotification will return true if no response is expected (2.0 request without an ID)
+func (r *Request) isNotification() bool {
+	return r.JSONRPC == Version && r.ID == nil
+}
+
+// Response is a JSON-RPC response (a result or an error)
+type Response struct {
+	Error  *Error
+	ID     json.RawMessage
+	Result interface{}
+}
+
+// MarshalJSON will encode the result (even if null) or the error, as required by the specification
+func (r *Response) MarshalJSON() ([]byte, error) {
+	id := r.ID
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	if r.Error != nil {
+		return json.Marshal(&struct {
+			Error   *Error          `json:"error"`
+			ID      json.RawMessage `json:"id"`
+			JSONRPC string          `json:"jsonrpc"`
+		}{Error: r.Error, ID: id, JSONRPC: Version})
+	}
+	return json.Marshal(&struct {
+		ID      json.RawMessage `json:"id"`
+		JSONRPC string          `json:"jsonrpc"`
+		Result  interface{}     `json:"result"`
+	}{ID: id, JSONRPC: Version, Result: r.Result})
+}
+
+// Method is a JSON-RPC method (params is nil if none are given)
+// An *Error sets the error code, any other error is an internal error
+type Method func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// Server dispatches the JSON-RPC requests to the registered methods
+type Server struct {
+	methods map[string]Method
+}
+
+// NewServer will create the server without methods
+func NewServer() *Server {
+	return &Server{methods: make(map[string]Method)}
+}
+
+// Register will register the method (replacing any method with the same name)
+func (s *Server) Register(name string, method Method) {
+	s.methods[name] = method
+}
+
+// Methods will return the names of the registered methods (sorted)
+func (s *Server) Methods() []string {
+	names := make([]string, 0, len(s.methods))
+	for name := range s.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ServeHTTP will answer the request or batch of requests (POST only)
+// JSON-RPC errors are returned with a 200 status, a batch of notifications has no content
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "JSON-RPC requests must be posted", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body bytes.Buffer
+	if _, err := body.ReadFrom(req.Body); err != nil {
+		writeJSON(w, &Response{Error: NewError(CodeParseError, err.Error())})
+		return
+	}
+	res := s.Handle(req.Context(), body.Bytes())
+	if res == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, res)
+}
+
+// Handle will answer the encoded request or batch (nil if no response is expected)
+func (s *Server) Handle(ctx context.Context, body []byte) interface{} {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			return &Response{Error: NewError(CodeParseError, err.Error())}
+		} else if len(batch) == 0 {
+			return &Response{Error: NewError(CodeInvalidRequest, "empty batch")}
+		}
+		responses := make([]*Response, 0, len(batch))
+		for _, raw := range batch {
+			if res := s.handle(ctx, raw); res != nil {
+				responses = append(responses, res)
+			}
+		}
+		if len(responses) == 0 {
+			return nil
+		}
+		return responses
+	}
+	if res := s.handle(ctx, body); res != nil {
+		return res
+	}
+	return nil // Not a nil *Response (a non-nil interface)
+}
+
+// handle will answer a single request (nil for a notification)
+func (s *Server) handle(ctx context.Context, raw json.RawMessage) *Response {
+	r := &Request{}
+	if err := json.Unmarshal(raw, r); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return &Response{Error: NewError(CodeParseError, err.Error())}
+		}
+		return &Response{Error: NewError(CodeInvalidRequest, err.Error())}
+	}
+	if len(r.Method) == 0 {
+		return &Response{Error: NewError(CodeInvalidRequest, "method is required"), ID: r.ID}
+	}
+
+	res := &Response{ID: r.ID}
+	method, ok := s.methods[r.Method]
+	if !ok {
+		res.Error = NewError(CodeMethodNotFound, "method not found: "+r.Method)
+	} else if result, err := method(ctx, params(r.Params)); err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = NewError(CodeInternalError, err.Error())
+		}
+		res.Error = rpcErr
+	} else {
+		res.Result = result
+	}
+	if r.isNotification() {
+		return nil
+	}
+	return res
+}
+
+// params will return nil if no params are given (omitted or null)
+func params(raw json.RawMessage) json.RawMessage {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil
+	}
+	return raw
+}
+
+// Bind will decode the positional (array) or named (object) params into v
+// The names are the JSON names of the positional params, in order (missing params keep their value)
+func Bind(raw json.RawMessage, v interface{}, names ...string) error {
+	raw = params(raw)
+	if raw == nil {
+		return nil
+	}
+	if raw[0] == '[' {
+		var positional []json.RawMessage
+		if err := json.Unmarshal(raw, &positional); err != nil {
+			return NewError(CodeInvalidParams, err.Error())
+		} else if len(positional) > len(names) {
+			return NewError(CodeInvalidParams, fmt.Sprintf("expected at most %d params, got %d", len(names), len(positional)))
+		}
+		named := make(map[string]json.RawMessage, len(positional))
+		for i, param := range positional {
+			named[names[i]] = param
+		}
+		var err error
+		if raw, err = json.Marshal(named); err != nil {
+			return err
+		}
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return NewError(CodeInvalidParams, err.Error())
+	}
+	return nil
+}
+
+// writeJSON will write the response
+func writeJSON(w http.ResponseWriter, res interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,200 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer will create a server with test methods
+func newTestServer() *Server {
+	s := NewServer()
+	s.Register("echo", func(_ context.Context, params json.RawMessage) (interface{}, error) {
+		var p struct {
+			Count int    `json:"count"`
+			Text  string `json:"text"`
+		}
+		if err := Bind(params, &p, "text", "count"); err != nil {
+			return nil, err
+		}
+		return p, nil
+	})
+	s.Register("fail", func(_ context.Context, _ json.RawMessage) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+	s.Register("missing", func(_ context.Context, _ json.RawMessage) (interface{}, error) {
+		return nil, NewError(CodeNotFound, "not found")
+	})
+	s.Register("nothing", func(_ context.Context, _ json.RawMessage) (interface{}, error) {
+		return nil, nil
+	})
+	return s
+}
+
+// handle will handle the body and return the encoded response
+func handle(t *testing.T, s *Server, body string) string {
+	res := s.Handle(context.Background(), []byte(body))
+	if res == nil {
+		return ""
+	}
+	encoded, err := json.Marshal(res)
+	require.NoError(t, err)
+	return string(encoded)
+}
+
+// TestServer_Handle will test the method Handle()
+func TestServer_Handle(t *testing.T) {
+	s := newTestServer()
+
+	t.Run("positional params", func(t *testing.T) {
+		res := handle(t, s, `{"jsonrpc":"2.0","id":1,"method":"echo","params":["hi",2]}`)
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{"count":2,"text":"hi"}}`, res)
+	})
+
+	t.Run("named params", func(t *testing.T) {
+		res := handle(t, s, `{"jsonrpc":"2.0","id":"a","method":"echo","params":{"count":3}}`)
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":"a","result":{"count":3,"text":""}}`, res)
+	})
+
+	t.Run("bitcoind 1.0 request", func(t *testing.T) {
+		res := handle(t, s, `{"id":"curltest","method":"nothing","params":[]}`)
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":"curltest","result":null}`, res)
+	})
+
+	t.Run("too many params", func(t *testing.T) {
+		res := handle(t, s, `{"jsonrpc":"2.0","id":1,"method":"echo","params":["hi",2,3]}`)
+		assert.Contains(t, res, `"code":-32602`)
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		res := handle(t, s, `{"jsonrpc":"2.0","id":1,"method":"echo","params":[1]}`)
+		assert.Contains(t, res, `"code":-32602`)
+	})
+
+	t.Run("method not found", func(t *testing.T) {
+		res := handle(t, s, `{"jsonrpc":"2.0","id":1,"method":"unknown"}`)
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found: unknown"}}`, res)
+	})
+
+	t.Run("application error", func(t *testing.T) {
+		res := handle(t, s, `{"jsonrpc":"2.0","id":1,"method":"missing"}`)
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-5,"message":"not found"}}`, res)
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		res := handle(t, s, `{"jsonrpc":"2.0","id":1,"method":"fail"}`)
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32603,"message":"boom"}}`, res)
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		res := handle(t, s, `{"jsonrpc":"2.0",`)
+		assert.Contains(t, res, `"code":-32700`)
+		assert.Contains(t, res, `"id":null`)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		assert.Contains(t, handle(t, s, `{"jsonrpc":"2.0","id":1}`), `"code":-32600`)
+		assert.Contains(t, handle(t, s, `"echo"`), `"code":-32600`)
+		assert.Contains(t, handle(t, s, `[]`), `"code":-32600`)
+	})
+
+	t.Run("notification", func(t *testing.T) {
+		assert.Empty(t, handle(t, s, `{"jsonrpc":"2.0","method":"echo"}`))
+		assert.Empty(t, handle(t, s, `{"jsonrpc":"2.0","method":"fail"}`))
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		res := handle(t, s, `[
+			{"jsonrpc":"2.0","id":1,"method":"echo","params":["a"]},
+			{"jsonrpc":"2.0","method":"echo"},
+			{"jsonrpc":"2.0","id":2,"method":"unknown"}
+		]`)
+		var responses []map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal([]byte(res), &responses))
+		require.Len(t, responses, 2)
+		assert.Equal(t, "1", string(responses[0]["id"]))
+		assert.Contains(t, responses[0], "result")
+		assert.Equal(t, "2", string(responses[1]["id"]))
+		assert.Contains(t, responses[1], "error")
+	})
+
+	t.Run("batch of notifications", func(t *testing.T) {
+		assert.Empty(t, handle(t, s, `[{"jsonrpc":"2.0","method":"echo"},{"jsonrpc":"2.0","method":"nothing"}]`))
+	})
+}
+
+// TestServer_ServeHTTP will test the method ServeHTTP()
+func TestServer_ServeHTTP(t *testing.T) {
+	s := newTestServer()
+
+	t.Run("post", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"missing"}`))
+		s.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-5,"message":"not found"}}`, w.Body.String())
+	})
+
+	t.Run("notification", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"jsonrpc":"2.0","method":"echo"}`))
+		s.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("get", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, http.MethodPost, w.Header().Get("Allow"))
+	})
+}
+
+// TestServer_Methods will test the method Methods()
+func TestServer_Methods(t *testing.T) {
+	assert.Equal(t, []string{"echo", "fail", "missing", "nothing"}, newTestServer().Methods())
+}
+
+// TestBind will test the method Bind()
+func TestBind(t *testing.T) {
+	type params struct {
+		Limit  int    `json:"limit"`
+		Cursor string `json:"cursor"`
+	}
+
+	t.Run("no params", func(t *testing.T) {
+		p := params{Limit: 5}
+		require.NoError(t, Bind(nil, &p, "limit", "cursor"))
+		require.NoError(t, Bind(json.RawMessage(" null "), &p, "limit", "cursor"))
+		assert.Equal(t, params{Limit: 5}, p)
+	})
+
+	t.Run("positional", func(t *testing.T) {
+		var p params
+		require.NoError(t, Bind(json.RawMessage(` [10, "abc"]`), &p, "limit", "cursor"))
+		assert.Equal(t, params{Limit: 10, Cursor: "abc"}, p)
+	})
+
+	t.Run("named", func(t *testing.T) {
+		var p params
+		require.NoError(t, Bind(json.RawMessage(`{"cursor":"abc"}`), &p, "limit", "cursor"))
+		assert.Equal(t, params{Cursor: "abc"}, p)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var p params
+		err := Bind(json.RawMessage(`["abc"]`), &p, "limit", "cursor")
+		var rpcErr *Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, CodeInvalidParams, rpcErr.Code)
+	})
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ExemplarTraceID is the exemplar label linking a sample to its trace (Grafana's default)
+const ExemplarTraceID = "trace_id"
+
+// exemplar will return the exemplar labels for the sampled trace in the context (nil if none)
+func exemplar(ctx context.Context) prometheus.Labels {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() || !sc.IsSampled() {
+		return nil
+	}
+	return prometheus.Labels{ExemplarTraceID: sc.TraceID().String()}
+}
+
+// Inc will increment the counter, with the trace ID exemplar if the context has a sampled trace
+func Inc(ctx context.Context, c prometheus.Counter) {
+	if labels := exemplar(ctx); labels != nil {
+		if adder, ok := c.(prometheus.ExemplarAdder); ok {
+			adder.AddWithExemplar(1, labels)
+			return
+		}
+	}
+	c.Inc()
+}
+
+// Observe will observe the value, with the trace ID exemplar if the context has a sampled trace
+func Observe(ctx context.Context, o prometheus.Observer, value float64) {
+	if labels := exemplar(ctx); labels != nil {
+		if observer, ok := o.(prometheus.ExemplarObserver); ok {
+			observer.ObserveWithExemplar(value, labels)
+			return
+		}
+	}
+	o.Observe(value)
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+)
+
+// Label values for a peer or alert type that is not known (or is over the limit)
+const (
+	LabelOther   = "other"   // Peer is over the peer label limit
+	LabelUnknown = "unknown" // Alert type could not be read
+)
+
+// MaxPeerLabels is the max distinct peer_id label values (later peers are labeled "other")
+// Keeps the series count bounded if the node is flooded by short-lived peers
+const MaxPeerLabels = 50
+
+// labelLimiter bounds the distinct values of a label (first come, first served)
+type labelLimiter struct {
+	max    int
+	mu     sync.Mutex
+	values map[string]struct{}
+}
+
+// value will return the label value (or other if the limit is reached)
+func (l *labelLimiter) value(v string) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.values[v]; ok {
+		return v
+	} else if len(l.values) >= l.max {
+		return LabelOther
+	}
+	l.values[v] = struct{}{}
+	return v
+}
+
+// peerLabels are the peer_id label values
+var peerLabels = &labelLimiter{max: MaxPeerLabels, values: make(map[string]struct{})}
+
+// PeerLabel will return the peer_id label value for the peer (bounded to MaxPeerLabels peers)
+func PeerLabel(peerID string) string {
+	if len(peerID) == 0 {
+		return LabelUnknown
+	}
+	return peerLabels.value(peerID)
+}
+
+// AlertTypeLabel will return the alert_type label value for the alert type name (e.g. "Ban Peer" is ban_peer)
+func AlertTypeLabel(name string) string {
+	if len(name) == 0 {
+		return LabelUnknown
+	}
+	return strings.ReplaceAll(strings.ToLower(name), " ", "_")
+}
@@ -0,0 +1,335 @@
+// Package metrics defines the Prometheus metrics for the alert system
+// All metrics are named alert_system_<subsystem>_<name> and registered on the Registry,
+// which is served on /metrics when enable_metrics is set
+// Labels are bounded (peer_id is capped, alert_type and node are fixed sets) and samples taken
+// within a sampled trace carry the trace ID as an exemplar (OpenMetrics format)
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/buildinfo"
+	"github.com/mrz1836/go-datastore"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace is the prefix of all the metrics
+const Namespace = "alert_system"
+
+// Label values for the result of an operation
+const (
+	ResultDropped   = "dropped"   // Delivery was dropped (the queue is full or a rate cap is reached)
+	ResultDuplicate = "duplicate" // Alert was already saved
+	ResultError     = "error"     // Operation failed
+	ResultHit       = "hit"       // Found in the cache
+	ResultInvalid   = "invalid"   // Message or signature is not valid
+	ResultLocked    = "locked"    // Alert is enforced by another instance (alert lock)
+	ResultMiss      = "miss"      // Not found in the cache
+	ResultNotFound  = "not_found" // No record was found
+	ResultOK        = "ok"        // Operation succeeded
+	ResultRetry     = "retry"     // Delivery failed and will be retried
+	ResultStandby   = "standby"   // Alert was left to the cluster leader (this instance is a standby)
+)
+
+// Label values for the direction of a message
+const (
+	DirectionIn  = "in"  // Received from a peer
+	DirectionOut = "out" // Sent to a peer
+)
+
+// Label values for the arrival of a gossip message
+const (
+	ArrivalDuplicate = "duplicate" // Message was already received from another peer
+	ArrivalFirst     = "first"     // First copy of the message
+)
+
+// Label values for the priority of a queued alert message
+const (
+	PriorityCritical = "critical" // Consensus-critical alert (blocks, UTXOs or keys)
+	PriorityNormal   = "normal"   // Informational and peer alerts (and unreadable messages)
+)
+
+// Label values for the datastore operations
+const (
+	OperationGet     = "get"      // Get a single model
+	OperationGetMany = "get_many" // Get a list of models
+	OperationSave    = "save"     // Save a model (and child models)
+)
+
+// Registry is the registry for all the alert system metrics (and the Go runtime and process metrics)
+var Registry = prometheus.NewRegistry()
+
+// Metrics for each subsystem
+var (
+	AlertLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace, Subsystem: "alert", Name: "latency_seconds",
+		Help:    "Time from receiving an alert over gossip to the node action completing by alert type",
+		Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+	}, []string{"alert_type"})
+
+	AlertQueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace, Subsystem: "alert", Name: "queue_depth",
+		Help: "Gossiped alert messages waiting to be processed by priority",
+	}, []string{"priority"})
+
+	AlertQueueFull = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace, Subsystem: "alert", Name: "queue_full_total",
+		Help: "Times the pubsub reader waited for room in the full alert queue (backpressure)",
+	})
+
+	AlertPropagationDelay = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace, Subsystem: "alert", Name: "propagation_delay_seconds",
+		Help:    "Time from the alert timestamp to it being received over gossip by alert type",
+		Buckets: prometheus.ExponentialBuckets(0.5, 2, 12),
+	}, []string{"alert_type"})
+
+	BudgetLimit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace, Subsystem: "budget", Name: "limit",
+		Help: "Soft limit of the resource (memory bytes or concurrent handlers)",
+	}, []string{"resource"})
+
+	BudgetRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace, Subsystem: "budget", Name: "rejected_total",
+		Help: "Handlers rejected because the concurrent handlers of the resource are at the limit",
+	}, []string{"resource"})
+
+	BudgetUsed = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace, Subsystem: "budget", Name: "used",
+		Help: "Usage of the resource (memory bytes or concurrent handlers)",
+	}, []string{"resource"})
+
+	BuildInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace, Name: "build_info",
+		Help: "Build of the running alert system (always 1) by version, commit, Go version and features",
+	}, []string{"version", "commit", "go_version", "features"})
+
+	ClusterLeader = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace, Subsystem: "cluster", Name: "leader",
+		Help: "1 if this instance is the cluster leader (or clustering is disabled), 0 if it is a standby",
+	})
+
+	DatastoreQueries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace, Subsystem: "datastore", Name: "queries_total",
+		Help: "Datastore queries by operation and result",
+	}, []string{"operation", "result"})
+
+	DatastoreQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace, Subsystem: "datastore", Name: "query_duration_seconds",
+		Help: "Datastore query latency by operation", Buckets: prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	Events = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace, Subsystem: "events", Name: "published_total",
+		Help: "Events published on the internal event bus by type",
+	}, []string{"type"})
+
+	GossipArrivals = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace, Subsystem: "pubsub", Name: "arrivals_total",
+		Help: "Gossip messages received by peer and arrival (first copy or duplicate)",
+	}, []string{"peer_id", "arrival"})
+
+	GossipDuplicateDelay = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: Namespace, Subsystem: "pubsub", Name: "duplicate_delay_seconds",
+		Help:    "Time from the first copy of a gossip message to a duplicate arriving from another peer",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+	})
+
+	Health = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace, Name: "health",
+		Help: "Aggregated health of the alert system (1 ok, 0.5 degraded, 0 unhealthy)",
+	})
+
+	HealthChecks = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace, Subsystem: "health", Name: "check",
+		Help: "Health of each subsystem check by check (1 ok, 0.5 degraded, 0 unhealthy)",
+	}, []string{"check"})
+
+	HeartbeatPosts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace, Subsystem: "heartbeat", Name: "posts_total",
+		Help: "Heartbeats posted to the heartbeat URL by result",
+	}, []string{"result"})
+
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace, Subsystem: "http", Name: "requests_total",
+		Help: "HTTP requests by method, route and status code",
+	}, []string{"method", "route", "code"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace, Subsystem: "http", Name: "request_duration_seconds",
+		Help: "HTTP request latency by method and route", Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	LatestSequence = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace, Subsystem: "alert", Name: "latest_sequence",
+		Help: "Latest alert sequence number in the datastore",
+	})
+
+	LogsSuppressed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace, Subsystem: "log", Name: "suppressed_total",
+		Help: "Repeated log messages dropped by the log deduplication by level",
+	}, []string{"level"})
+
+	NodeUp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace, Subsystem: "node", Name: "up",
+		Help: "1 if the node responded to the last heartbeat RPC call, 0 otherwise",
+	})
+
+	NotificationDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace, Subsystem: "notification", Name: "deliveries_total",
+		Help: "Notification delivery attempts by channel and result",
+	}, []string{"channel", "result"})
+
+	Panics = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace, Name: "panics_total",
+		Help: "Panics recovered by goroutine (the goroutine is restarted or the message or request is dropped)",
+	}, []string{"goroutine"})
+
+	Peers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace, Subsystem: "p2p", Name: "peers",
+		Help: "Connected peers",
+	})
+
+	ProfileCaptures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace, Subsystem: "profiling", Name: "captures_total",
+		Help: "Profiles captured by the watchdog by reason (sustained cpu or memory pressure)",
+	}, []string{"reason"})
+
+	PubSubMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace, Subsystem: "pubsub", Name: "messages_total",
+		Help: "Alert messages received on the pubsub topic by peer, alert type and result",
+	}, []string{"topic", "peer_id", "alert_type", "result"})
+
+	RPCCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace, Subsystem: "rpc", Name: "calls_total",
+		Help: "Bitcoin node RPC calls by node, method and result",
+	}, []string{"node", "method", "result"})
+
+	RPCCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace, Subsystem: "rpc", Name: "call_duration_seconds",
+		Help: "Bitcoin node RPC call latency by node and method", Buckets: prometheus.DefBuckets,
+	}, []string{"node", "method"})
+
+	SignatureCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace, Subsystem: "alert", Name: "signature_cache_lookups_total",
+		Help: "Alert signature verification cache lookups by result (hit or miss)",
+	}, []string{"result"})
+
+	SignatureVerifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace, Subsystem: "alert", Name: "signature_verifications_total",
+		Help: "Alert signature verifications by alert type and result",
+	}, []string{"alert_type", "result"})
+
+	SyncMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace, Subsystem: "sync", Name: "messages_total",
+		Help: "Sync stream messages by direction and type",
+	}, []string{"direction", "type"})
+
+	SyncOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace, Subsystem: "sync", Name: "operations_total",
+		Help: "Syncs with a peer by peer and result",
+	}, []string{"peer_id", "result"})
+
+	SyncDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: Namespace, Subsystem: "sync", Name: "duration_seconds",
+		Help: "Sync with a peer latency", Buckets: prometheus.ExponentialBuckets(0.1, 2, 10),
+	})
+
+	Uptime = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace, Name: "uptime_seconds",
+		Help: "Seconds since the alert system started",
+	})
+
+	WebhookDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace, Subsystem: "webhook", Name: "deliveries_total",
+		Help: "Webhook delivery attempts by event and result",
+	}, []string{"event", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		AlertLatency,
+		AlertPropagationDelay,
+		AlertQueueDepth,
+		AlertQueueFull,
+		BudgetLimit,
+		BudgetRejected,
+		BudgetUsed,
+		BuildInfo,
+		ClusterLeader,
+		DatastoreQueries,
+		DatastoreQueryDuration,
+		Events,
+		GossipArrivals,
+		GossipDuplicateDelay,
+		Health,
+		HealthChecks,
+		HeartbeatPosts,
+		HTTPRequests,
+		HTTPRequestDuration,
+		LatestSequence,
+		LogsSuppressed,
+		NodeUp,
+		NotificationDeliveries,
+		Panics,
+		Peers,
+		ProfileCaptures,
+		PubSubMessages,
+		RPCCalls,
+		RPCCallDuration,
+		SignatureCacheLookups,
+		SignatureVerifications,
+		SyncDuration,
+		SyncMessages,
+		SyncOperations,
+		Uptime,
+		WebhookDeliveries,
+	)
+
+	// The build info never changes while running
+	info := buildinfo.Get()
+	BuildInfo.WithLabelValues(info.Version, info.Commit, info.GoVersion, strings.Join(info.Features, ",")).Set(1)
+}
+
+// Handler will return the handler for the metrics endpoint
+// The OpenMetrics format (with exemplars) is served if the scraper accepts it and
+// compression is left to the web server (responses are gzipped if the client accepts it)
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{DisableCompression: true, EnableOpenMetrics: true})
+}
+
+// Result will return the result label for the error (ok, not_found or error)
+func Result(err error) string {
+	if err == nil {
+		return ResultOK
+	} else if errors.Is(err, datastore.ErrNoResults) {
+		return ResultNotFound
+	}
+	return ResultError
+}
+
+// ObserveQuery will record the datastore query result and latency (deferred with the named error)
+func ObserveQuery(ctx context.Context, operation string, start time.Time, err *error) {
+	Inc(ctx, DatastoreQueries.WithLabelValues(operation, Result(*err)))
+	Observe(ctx, DatastoreQueryDuration.WithLabelValues(operation), time.Since(start).Seconds())
+}
+
+// ObserveRPC will record the node RPC call result and latency
+func ObserveRPC(ctx context.Context, node, method string, start time.Time, err error) {
+	Inc(ctx, RPCCalls.WithLabelValues(node, method, Result(err)))
+	Observe(ctx, RPCCallDuration.WithLabelValues(node, method), time.Since(start).Seconds())
+}
+
+// ObserveHTTPRequest will record the HTTP request status code and latency
+func ObserveHTTPRequest(ctx context.Context, method, route string, code int, start time.Time) {
+	Inc(ctx, HTTPRequests.WithLabelValues(method, route, strconv.Itoa(code)))
+	Observe(ctx, HTTPRequestDuration.WithLabelValues(method, route), time.Since(start).Seconds())
+}
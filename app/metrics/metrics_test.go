@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mrz1836/go-datastore"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestResult will test the result label for an error
+func TestResult(t *testing.T) {
+	assert.Equal(t, ResultOK, Result(nil))
+	assert.Equal(t, ResultNotFound, Result(datastore.ErrNoResults))
+	assert.Equal(t, ResultError, Result(errors.New("connection refused")))
+}
+
+// TestObserve will test recording the metrics
+func TestObserve(t *testing.T) {
+	t.Run("datastore query", func(t *testing.T) {
+		before := testutil.ToFloat64(DatastoreQueries.WithLabelValues(OperationGet, ResultNotFound))
+		err := datastore.ErrNoResults
+		ObserveQuery(context.Background(), OperationGet, time.Now(), &err)
+		assert.Equal(t, before+1, testutil.ToFloat64(DatastoreQueries.WithLabelValues(OperationGet, ResultNotFound)))
+	})
+
+	t.Run("rpc call", func(t *testing.T) {
+		before := testutil.ToFloat64(RPCCalls.WithLabelValues("localhost:8332", "getbestblockhash", ResultError))
+		ObserveRPC(context.Background(), "localhost:8332", "getbestblockhash", time.Now(), errors.New("timeout"))
+		assert.Equal(t, before+1, testutil.ToFloat64(RPCCalls.WithLabelValues("localhost:8332", "getbestblockhash", ResultError)))
+	})
+
+	t.Run("http request", func(t *testing.T) {
+		before := testutil.ToFloat64(HTTPRequests.WithLabelValues(http.MethodGet, "/v1/alerts", "200"))
+		ObserveHTTPRequest(context.Background(), http.MethodGet, "/v1/alerts", http.StatusOK, time.Now())
+		assert.Equal(t, before+1, testutil.ToFloat64(HTTPRequests.WithLabelValues(http.MethodGet, "/v1/alerts", "200")))
+	})
+}
+
+// TestHandler will test serving the metrics
+func TestHandler(t *testing.T) {
+	SyncOperations.WithLabelValues("peer", ResultOK).Inc()
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `alert_system_sync_operations_total{peer_id="peer",result="ok"}`)
+	assert.Contains(t, w.Body.String(), "go_goroutines")
+}
+
+// TestLabels will test the bounded label values
+func TestLabels(t *testing.T) {
+	t.Run("alert type", func(t *testing.T) {
+		assert.Equal(t, "ban_peer", AlertTypeLabel("Ban Peer"))
+		assert.Equal(t, "informational", AlertTypeLabel("Informational"))
+		assert.Equal(t, LabelUnknown, AlertTypeLabel(""))
+	})
+
+	t.Run("peers over the limit are other", func(t *testing.T) {
+		l := &labelLimiter{max: 2, values: make(map[string]struct{})}
+		assert.Equal(t, "a", l.value("a"))
+		assert.Equal(t, "b", l.value("b"))
+		assert.Equal(t, LabelOther, l.value("c"))
+		assert.Equal(t, "a", l.value("a"))
+		assert.Equal(t, LabelUnknown, PeerLabel(""))
+	})
+}
+
+// TestExemplar will test adding the trace ID exemplar
+func TestExemplar(t *testing.T) {
+	assert.Nil(t, exemplar(context.Background()))
+
+	traceID := trace.TraceID{0x01, 0x02}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID, SpanID: trace.SpanID{0x03}, TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	assert.Equal(t, traceID.String(), exemplar(ctx)[ExemplarTraceID])
+
+	// Exemplars are served in the OpenMetrics format
+	Inc(ctx, WebhookDeliveries.WithLabelValues("alert.test", ResultOK))
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `alert_system_webhook_deliveries_total{event="alert.test",result="ok"} 1.0 # {trace_id="`+traceID.String()+`"}`)
+
+	// Not sampled
+	sc = sc.WithTraceFlags(0)
+	assert.Nil(t, exemplar(trace.ContextWithSpanContext(context.Background(), sc)))
+}
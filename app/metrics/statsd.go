@@ -0,0 +1,268 @@
+package metrics
+
+import (
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// StatsD defaults
+const (
+	DefaultStatsDInterval = 10 * time.Second // Default interval between pushes
+	statsDMaxPacketSize   = 1432             // Max UDP payload (fits in a 1500 byte MTU)
+)
+
+// StatsDOptions are the options for the StatsD emitter
+type StatsDOptions struct {
+	Address   string              // host:port of the StatsD or DogStatsD agent (UDP)
+	DogStatsD bool                // Send the labels as DogStatsD tags (the label values are appended to the name otherwise)
+	Gatherer  prometheus.Gatherer // Metrics to push (the Registry if nil)
+	Interval  time.Duration       // Interval between pushes (DefaultStatsDInterval if 0)
+	OnError   func(err error)     // Called if a push fails (optional)
+	Prefix    string              // Prefix for the metric names (e.g. "bsv.")
+}
+
+// StatsD pushes the alert system metrics in the Registry to a StatsD or DogStatsD agent
+// Counters are sent as the increase since the last push, gauges as the current value and the
+// histograms as timings (the observations since the last push at their bucket upper bound,
+// using the sample rate for the count)
+type StatsD struct {
+	conn     net.Conn
+	counters map[string]float64
+	buckets  map[string][]uint64
+	done     chan struct{}
+	mu       sync.Mutex
+	opts     StatsDOptions
+	quit     chan struct{}
+	started  bool
+}
+
+// NewStatsD will create a new StatsD emitter, call Start() to push the metrics on the interval
+func NewStatsD(opts StatsDOptions) (*StatsD, error) {
+	if opts.Gatherer == nil {
+		opts.Gatherer = Registry
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultStatsDInterval
+	}
+	conn, err := net.Dial("udp", opts.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &StatsD{
+		buckets:  make(map[string][]uint64),
+		conn:     conn,
+		counters: make(map[string]float64),
+		done:     make(chan struct{}),
+		opts:     opts,
+		quit:     make(chan struct{}),
+	}, nil
+}
+
+// Start will push the metrics on the interval until Stop() is called
+func (s *StatsD) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.opts.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.Push(); err != nil && s.opts.OnError != nil {
+					s.opts.OnError(err)
+				}
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop will stop pushing, push the remaining metrics and close the connection
+func (s *StatsD) Stop() error {
+	s.mu.Lock()
+	started := s.started
+	s.mu.Unlock()
+	close(s.quit)
+	if started {
+		<-s.done
+	}
+	err := s.Push()
+	if closeErr := s.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Push will send the metrics to the agent
+func (s *StatsD) Push() error {
+	lines, err := s.lines()
+	if err != nil {
+		return err
+	}
+
+	// Batch the lines into packets
+	var packet []byte
+	for _, line := range lines {
+		if len(packet) > 0 && len(packet)+1+len(line) > statsDMaxPacketSize {
+			if _, err = s.conn.Write(packet); err != nil {
+				return err
+			}
+			packet = packet[:0]
+		}
+		if len(packet) > 0 {
+			packet = append(packet, '\n')
+		}
+		packet = append(packet, line...)
+	}
+	if len(packet) > 0 {
+		_, err = s.conn.Write(packet)
+	}
+	return err
+}
+
+// lines will gather the metrics and return the StatsD lines (the Go runtime and process metrics are not sent)
+func (s *StatsD) lines() ([]string, error) {
+	families, err := s.opts.Gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lines := make([]string, 0)
+	for _, family := range families {
+		if !strings.HasPrefix(family.GetName(), Namespace+"_") {
+			continue
+		}
+		// Histograms in seconds are sent as timings in milliseconds
+		familyName, unit, scale := family.GetName(), "|h", 1.0
+		if family.GetType() == dto.MetricType_HISTOGRAM && strings.HasSuffix(familyName, "_seconds") {
+			familyName, unit, scale = strings.TrimSuffix(familyName, "_seconds"), "|ms", 1000
+		}
+		for _, m := range family.GetMetric() {
+			name, tags := s.series(familyName, m.GetLabel())
+			key := name + tags
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				value := m.GetCounter().GetValue()
+				delta := value - s.counters[key]
+				if delta < 0 {
+					delta = value
+				}
+				s.counters[key] = value
+				if delta > 0 {
+					lines = append(lines, name+":"+formatFloat(delta)+"|c"+tags)
+				}
+			case dto.MetricType_GAUGE:
+				value := m.GetGauge().GetValue()
+				if value < 0 {
+					// A signed gauge value is an adjustment, reset it first
+					lines = append(lines, name+":0|g"+tags)
+				}
+				lines = append(lines, name+":"+formatFloat(value)+"|g"+tags)
+			case dto.MetricType_HISTOGRAM:
+				lines = append(lines, s.timings(name, unit, tags, key, scale, m.GetHistogram())...)
+			default:
+				continue
+			}
+		}
+	}
+	return lines, nil
+}
+
+// timings will return the timing lines for the histogram observations since the last push, must hold the lock
+func (s *StatsD) timings(name, unit, tags, key string, scale float64, h *dto.Histogram) []string {
+	buckets := h.GetBucket()
+	counts := make([]uint64, 0, len(buckets)+1)
+	for _, b := range buckets {
+		counts = append(counts, b.GetCumulativeCount())
+	}
+	counts = append(counts, h.GetSampleCount())
+	last := s.buckets[key]
+	if len(last) != len(counts) {
+		last = make([]uint64, len(counts))
+	}
+	s.buckets[key] = counts
+
+	// Observations in each bucket (the counts are cumulative), the +Inf bucket uses the highest bound
+	lines := make([]string, 0)
+	for i := range counts {
+		current, previous := counts[i], last[i]
+		if i > 0 {
+			current, previous = current-counts[i-1], previous-last[i-1]
+		}
+		observed := current - previous
+		if current < previous {
+			observed = current
+		}
+		if observed == 0 || len(buckets) == 0 {
+			continue
+		}
+		bound := buckets[len(buckets)-1].GetUpperBound()
+		if i < len(buckets) && !math.IsInf(buckets[i].GetUpperBound(), 1) {
+			bound = buckets[i].GetUpperBound()
+		}
+		line := name + ":" + formatFloat(bound*scale) + unit
+		if observed > 1 {
+			line += "|@" + formatFloat(1/float64(observed))
+		}
+		lines = append(lines, line+tags)
+	}
+	return lines
+}
+
+// series will return the metric name and the tags (DogStatsD) for the labels
+func (s *StatsD) series(name string, labels []*dto.LabelPair) (string, string) {
+	name = s.opts.Prefix + name
+	if len(labels) == 0 {
+		return name, ""
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].GetName() < labels[j].GetName()
+	})
+	if !s.opts.DogStatsD {
+		for _, l := range labels {
+			name += "." + sanitizeStatsD(l.GetValue())
+		}
+		return name, ""
+	}
+	tags := make([]string, 0, len(labels))
+	for _, l := range labels {
+		tags = append(tags, l.GetName()+":"+sanitizeStatsD(l.GetValue()))
+	}
+	return name, "|#" + strings.Join(tags, ",")
+}
+
+// sanitizeStatsD will replace the characters that are not safe in a StatsD name or tag
+func sanitizeStatsD(value string) string {
+	if len(value) == 0 {
+		return "none"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, value)
+}
+
+// formatFloat will format the value without trailing zeros
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
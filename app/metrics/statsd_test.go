@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStatsD will create a StatsD emitter for the registry that pushes to a local UDP listener
+func newTestStatsD(t *testing.T, registry *prometheus.Registry, dogStatsD bool) (*StatsD, net.PacketConn) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+	var s *StatsD
+	s, err = NewStatsD(StatsDOptions{
+		Address:   listener.LocalAddr().String(),
+		DogStatsD: dogStatsD,
+		Gatherer:  registry,
+		Interval:  time.Hour,
+		Prefix:    "test.",
+	})
+	require.NoError(t, err)
+	return s, listener
+}
+
+// readLines will read the next packet and return its lines (sorted)
+func readLines(t *testing.T, listener net.PacketConn) []string {
+	require.NoError(t, listener.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, statsDMaxPacketSize)
+	n, _, err := listener.ReadFrom(buf)
+	require.NoError(t, err)
+	lines := strings.Split(string(buf[:n]), "\n")
+	sort.Strings(lines)
+	return lines
+}
+
+// TestStatsD will test pushing the counters, gauges and timings
+func TestStatsD(t *testing.T) {
+	newRegistry := func() (*prometheus.Registry, *prometheus.CounterVec, prometheus.Gauge, prometheus.Histogram) {
+		counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace, Name: "messages_total", Help: "test",
+		}, []string{"peer_id", "result"})
+		gauge := prometheus.NewGauge(prometheus.GaugeOpts{Namespace: Namespace, Name: "peers", Help: "test"})
+		histogram := prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: Namespace, Name: "latency_seconds", Help: "test", Buckets: []float64{0.1, 1},
+		})
+		other := prometheus.NewCounter(prometheus.CounterOpts{Name: "go_other_total", Help: "test"})
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(counter, gauge, histogram, other)
+		other.Inc()
+		return registry, counter, gauge, histogram
+	}
+
+	t.Run("dogstatsd tags", func(t *testing.T) {
+		registry, counter, gauge, histogram := newRegistry()
+		s, listener := newTestStatsD(t, registry, true)
+
+		counter.WithLabelValues("peer:1", "ok").Add(3)
+		gauge.Set(4)
+		histogram.Observe(0.05)
+		histogram.Observe(0.5)
+		histogram.Observe(0.7)
+		histogram.Observe(5)
+		require.NoError(t, s.Push())
+		assert.Equal(t, []string{
+			"test.alert_system_latency:1000|ms",
+			"test.alert_system_latency:1000|ms|@0.5",
+			"test.alert_system_latency:100|ms",
+			"test.alert_system_messages_total:3|c|#peer_id:peer_1,result:ok",
+			"test.alert_system_peers:4|g",
+		}, readLines(t, listener))
+
+		// Only the increase since the last push is sent
+		counter.WithLabelValues("peer:1", "ok").Add(2)
+		histogram.Observe(0.05)
+		require.NoError(t, s.Push())
+		assert.Equal(t, []string{
+			"test.alert_system_latency:100|ms",
+			"test.alert_system_messages_total:2|c|#peer_id:peer_1,result:ok",
+			"test.alert_system_peers:4|g",
+		}, readLines(t, listener))
+		require.NoError(t, s.Stop())
+	})
+
+	t.Run("labels in the name", func(t *testing.T) {
+		registry, counter, gauge, _ := newRegistry()
+		s, listener := newTestStatsD(t, registry, false)
+
+		counter.WithLabelValues("", "error").Inc()
+		gauge.Set(-2)
+		require.NoError(t, s.Push())
+		assert.Equal(t, []string{
+			"test.alert_system_messages_total.none.error:1|c",
+			"test.alert_system_peers:-2|g",
+			"test.alert_system_peers:0|g",
+		}, readLines(t, listener))
+		require.NoError(t, s.Stop())
+	})
+
+	t.Run("lines are split into packets", func(t *testing.T) {
+		registry, counter, _, _ := newRegistry()
+		s, listener := newTestStatsD(t, registry, true)
+
+		for i := 0; i < 100; i++ {
+			counter.WithLabelValues(strings.Repeat("p", 20)+string(rune('a'+i%26))+string(rune('a'+i/26)), "ok").Inc()
+		}
+		require.NoError(t, s.Push())
+		lines := 0
+		for lines < 101 {
+			packet := readLines(t, listener)
+			lines += len(packet)
+		}
+		assert.Equal(t, 101, lines)
+		require.NoError(t, s.Stop())
+	})
+}
@@ -1,7 +1,15 @@
 package app
 
 import (
+	"crypto/subtle"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/audit"
 	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/metrics"
 	"github.com/julienschmidt/httprouter"
 	apirouter "github.com/mrz1836/go-api-router"
 )
@@ -11,11 +19,164 @@ func NewStack(conf *config.Config) (Action, *apirouter.InternalStack) {
 	return Action{Config: conf}, apirouter.NewStack()
 }
 
-// Request will process the request in the router
-// This is used for logging requests or not logging the requests from the router
+// Request will process the request in the router (the middleware chain runs in the order below)
 func (a *Action) Request(router *apirouter.Router, h httprouter.Handle) httprouter.Handle {
-	if a.Config.RequestLogging {
-		return router.Request(h)
+	next := router.RequestNoLogging(h)
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		start := time.Now()
+
+		// Request ID (X-Request-ID, propagated if provided) and a logger carrying it on the context
+		var info *requestInfo
+		req, info = withRequestInfo(w, req)
+		req = req.WithContext(config.ContextWithLogger(req.Context(), a.Logger(req)))
+
+		// HTTP metrics and the access log of every response, including the rejected requests below
+		recorder := &statusRecorder{ResponseWriter: w}
+		w = recorder
+		defer a.observeRequest(recorder, req, ps, info, start)
+
+		// Recover a panic in the handler (500) so the web server keeps serving
+		defer a.recoverRequest(w, req, info)
+
+		// Client IP allowlist of the route group (if set)
+		if !a.allowedIP(req) {
+			APIErrorResponse(w, req, http.StatusForbidden, ErrIPNotAllowed)
+			return
+		}
+
+		// Concurrent requests budget (503 over it)
+		if !a.Config.Services.APIHandlers.Acquire() {
+			w.Header().Set("Retry-After", "1")
+			APIErrorResponse(w, req, http.StatusServiceUnavailable, ErrTooManyRequests)
+			return
+		}
+		defer a.Config.Services.APIHandlers.Release()
+
+		// API version (X-API-Version or a versioned Accept media type)
+		version, err := negotiateAPIVersion(req)
+		if err != nil {
+			APIErrorResponse(w, req, http.StatusNotAcceptable, err)
+			return
+		}
+		w.Header().Set(HeaderAPIVersion, version)
+
+		// JSON or form body, limited to the max body size (413 if the declared length is larger)
+		if status, bodyErr := validateBody(req, a.Config.WebServer.MaxBodyBytes); bodyErr != nil {
+			APIErrorResponse(w, req, status, bodyErr)
+			return
+		}
+		if a.Config.WebServer.MaxBodyBytes > 0 && req.Body != nil {
+			req.Body = http.MaxBytesReader(w, req.Body, a.Config.WebServer.MaxBodyBytes)
+		}
+
+		// Gzip the response if the client accepts it (and compression is enabled)
+		if !a.Config.WebServer.DisableCompression && req.Method != http.MethodHead &&
+			acceptsGzip(req.Header.Get("Accept-Encoding")) {
+			cw := newCompressWriter(w, a.Config.WebServer.CompressMinBytes)
+			defer func() {
+				_ = cw.Close()
+			}()
+			w = cw
+		}
+
+		// Fire the request
+		next(w, req, ps)
+	}
+}
+
+// observeRequest will record the HTTP metrics and the structured access log (if request logging is enabled)
+func (a *Action) observeRequest(recorder *statusRecorder, req *http.Request, ps httprouter.Params, info *requestInfo,
+	start time.Time,
+) {
+	if recorder.status == 0 {
+		recorder.status = http.StatusOK
+	}
+	metrics.ObserveHTTPRequest(req.Context(), req.Method, routePattern(req.URL.Path, ps), recorder.status, start)
+	if !a.Config.RequestLogging {
+		return
+	}
+
+	principal := info.principal
+	if len(principal) == 0 {
+		principal = principalAnonymous
+	}
+	a.Config.Services.Log.Infof(
+		"access request_id=%s method=%s path=%s status=%d latency_ms=%d ip=%s principal=%s user_agent=%q",
+		info.id, req.Method, req.URL.Path, recorder.status, time.Since(start).Milliseconds(),
+		ClientIP(req, a.Config.WebServer.TrustedProxies), principal, req.UserAgent(),
+	)
+}
+
+// routePattern will return the route of the request with the params replaced by their names
+// (e.g. /v1/alert/:sequence) to keep the metrics label cardinality bounded
+func routePattern(path string, ps httprouter.Params) string {
+	for _, p := range ps {
+		if strings.HasPrefix(p.Value, "/") { // Catch-all param
+			path = strings.TrimSuffix(path, p.Value) + "/*" + p.Key
+			continue
+		}
+		path = strings.Replace(path, "/"+p.Value, "/:"+p.Key, 1)
+	}
+	return path
+}
+
+// RequireAdmin will require a valid admin token (Authorization: Bearer <token>) before calling the handler
+// Admin routes are disabled if no admin token is configured, requests received on the admin socket need no token
+func (a *Action) RequireAdmin(h httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		if FromAdminSocket(req) {
+			setPrincipal(req, principalSocket)
+			a.auditAdmin(req, audit.EventAdminRequest, nil)
+			h(w, req, ps)
+			return
+		} else if len(a.Config.WebServer.AdminToken) == 0 {
+			a.auditAdmin(req, audit.EventAdminDenied, ErrAdminDisabled)
+			APIErrorResponse(w, req, http.StatusForbidden, ErrAdminDisabled)
+			return
+		}
+		token := strings.TrimSpace(strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
+		if subtle.ConstantTimeCompare([]byte(token), []byte(a.Config.WebServer.AdminToken)) != 1 {
+			a.auditAdmin(req, audit.EventAdminDenied, ErrUnauthorized)
+			APIErrorResponse(w, req, http.StatusUnauthorized, ErrUnauthorized)
+			return
+		}
+		setPrincipal(req, principalAdmin)
+		a.auditAdmin(req, audit.EventAdminRequest, nil)
+		h(w, req, ps)
+	}
+}
+
+// auditAdmin will record the admin API call in the audit log (the actor is the client IP, or the admin socket)
+func (a *Action) auditAdmin(req *http.Request, eventType string, denied error) {
+	actor := ClientIP(req, a.Config.WebServer.TrustedProxies).String()
+	if FromAdminSocket(req) {
+		actor = principalSocket
+	}
+	details := map[string]string{"method": req.Method}
+	if requestID := GetRequestID(req.Context()); len(requestID) > 0 {
+		details["request_id"] = requestID
+	}
+	if denied != nil {
+		details["reason"] = denied.Error()
+	}
+	if err := a.Config.Services.Audit.Record(
+		req.Context(), eventType, actor, req.URL.Path, details,
+	); err != nil {
+		a.Config.Services.Log.Errorf("failed to record %s in the audit log: %s", eventType, err.Error())
+	}
+}
+
+// recoverRequest will recover a panic in the handler (the request fails with a 500), logging the stack
+// and writing a diagnostic bundle with the P2P server's supervisor (not recovered if the P2P server is not running)
+func (a *Action) recoverRequest(w http.ResponseWriter, req *http.Request, info *requestInfo) {
+	if a.P2P == nil {
+		return
+	}
+	if r := recover(); r != nil {
+		a.P2P.Supervisor().Panicked("http_request", r, debug.Stack(), map[string]string{
+			config.LogFieldRequestID: info.id,
+			"path":                   req.URL.Path,
+		})
+		APIErrorResponse(w, req, http.StatusInternalServerError, ErrInternal)
 	}
-	return router.RequestNoLogging(h)
 }
@@ -1,9 +1,16 @@
 package app
 
 import (
+	"bytes"
+	"context"
+	"log"
 	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"testing"
 
+	"github.com/bitcoin-sv/alert-system/app/audit"
+	"github.com/bitcoin-sv/alert-system/app/budget"
 	"github.com/bitcoin-sv/alert-system/app/config"
 	"github.com/julienschmidt/httprouter"
 	apirouter "github.com/mrz1836/go-api-router"
@@ -53,3 +60,195 @@ func TestAction_Request(t *testing.T) {
 		a.Request(router, testHandle)
 	})
 }
+
+// TestAction_RequireAdmin will test the method RequireAdmin()
+func TestAction_RequireAdmin(t *testing.T) {
+	t.Parallel()
+
+	t.Run("admin token not set", func(t *testing.T) {
+		dep := new(config.Config)
+		dep.WebServer = config.WebServerConfig{}
+		a, _ := NewStack(dep)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Authorization", "Bearer ")
+		a.RequireAdmin(testHandle)(w, req, nil)
+		require.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		dep := new(config.Config)
+		dep.WebServer = config.WebServerConfig{AdminToken: "secret"}
+		a, _ := NewStack(dep)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Authorization", "Bearer wrong")
+		a.RequireAdmin(testHandle)(w, req, nil)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("valid token", func(t *testing.T) {
+		dep := new(config.Config)
+		dep.WebServer = config.WebServerConfig{AdminToken: "secret"}
+		a, _ := NewStack(dep)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		a.RequireAdmin(testHandle)(w, req, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("admin socket", func(t *testing.T) {
+		dep := new(config.Config)
+		dep.WebServer = config.WebServerConfig{AdminAllowlist: []string{"10.0.0.1"}}
+		a, _ := NewStack(dep)
+		a.Allowlist = dep.WebServer.AdminAllowlist
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/admin/status", nil)
+		WithAdminSocket(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			require.True(t, FromAdminSocket(req))
+			require.True(t, a.allowedIP(req))
+			a.RequireAdmin(testHandle)(w, req, nil)
+		})).ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.False(t, FromAdminSocket(req))
+		require.False(t, a.allowedIP(req))
+	})
+
+	t.Run("admin calls are audited", func(t *testing.T) {
+		store, err := audit.NewFileStore(filepath.Join(t.TempDir(), "audit.log"))
+		require.NoError(t, err)
+		defer func() {
+			_ = store.Close()
+		}()
+		dep := new(config.Config)
+		dep.WebServer = config.WebServerConfig{AdminToken: "secret"}
+		dep.Services.Audit, err = audit.New(context.Background(), store)
+		require.NoError(t, err)
+		a, _ := NewStack(dep)
+
+		for _, token := range []string{"wrong", "secret"} {
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/sync", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			a.RequireAdmin(testHandle)(httptest.NewRecorder(), req, nil)
+		}
+
+		var entries []*audit.Entry
+		entries, err = store.Entries(context.Background())
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		require.Equal(t, audit.EventAdminDenied, entries[0].Type)
+		require.Equal(t, ErrUnauthorized.Error(), entries[0].Details["reason"])
+		require.Equal(t, audit.EventAdminRequest, entries[1].Type)
+		require.Equal(t, "/v1/admin/sync", entries[1].Subject)
+		require.Equal(t, "192.0.2.1", entries[1].Actor)
+		require.NoError(t, audit.Verify(entries))
+	})
+}
+
+// TestAction_Request_RequestID will test the request ID handling in Request()
+func TestAction_Request_RequestID(t *testing.T) {
+	t.Parallel()
+
+	newAction := func(logging bool) (Action, *bytes.Buffer) {
+		buf := new(bytes.Buffer)
+		dep := new(config.Config)
+		dep.RequestLogging = logging
+		dep.Services.Log = &config.ExtendedLogger{Logger: log.New(buf, "", 0)}
+		a, _ := NewStack(dep)
+		return a, buf
+	}
+
+	t.Run("generated request id", func(t *testing.T) {
+		a, _ := newAction(false)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		a.Request(apirouter.New(), testHandle)(w, req, nil)
+		require.Len(t, w.Header().Get(HeaderRequestID), 36)
+	})
+
+	t.Run("propagated request id", func(t *testing.T) {
+		a, _ := newAction(false)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderRequestID, "abc-123")
+		a.Request(apirouter.New(), testHandle)(w, req, nil)
+		require.Equal(t, "abc-123", w.Header().Get(HeaderRequestID))
+	})
+
+	t.Run("invalid request id is replaced", func(t *testing.T) {
+		a, _ := newAction(false)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderRequestID, "bad id")
+		a.Request(apirouter.New(), testHandle)(w, req, nil)
+		require.NotEqual(t, "bad id", w.Header().Get(HeaderRequestID))
+		require.Len(t, w.Header().Get(HeaderRequestID), 36)
+	})
+
+	t.Run("too many concurrent requests", func(t *testing.T) {
+		a, _ := newAction(false)
+		a.Config.Services.APIHandlers = budget.NewLimiter(budget.ResourceAPIHandlers, 1)
+		require.True(t, a.Config.Services.APIHandlers.Acquire())
+		w := httptest.NewRecorder()
+		a.Request(apirouter.New(), testHandle)(w, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		require.Equal(t, "1", w.Header().Get("Retry-After"))
+
+		a.Config.Services.APIHandlers.Release()
+		w = httptest.NewRecorder()
+		a.Request(apirouter.New(), testHandle)(w, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, 0, a.Config.Services.APIHandlers.InUse())
+	})
+
+	t.Run("access log", func(t *testing.T) {
+		a, buf := newAction(true)
+		a.Config.WebServer.AdminToken = "secret"
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.Header.Set(HeaderRequestID, "abc-123")
+		req.Header.Set("Authorization", "Bearer secret")
+		a.Request(apirouter.New(), a.RequireAdmin(testHandle))(w, req, nil)
+		require.Contains(t, buf.String(), "request_id=abc-123")
+		require.Contains(t, buf.String(), "path=/admin")
+		require.Contains(t, buf.String(), "status=200")
+		require.Contains(t, buf.String(), "principal=admin")
+	})
+
+	t.Run("access log of a rejected request", func(t *testing.T) {
+		a, buf := newAction(true)
+		a.Config.Services.APIHandlers = budget.NewLimiter(budget.ResourceAPIHandlers, 1)
+		require.True(t, a.Config.Services.APIHandlers.Acquire())
+		defer a.Config.Services.APIHandlers.Release()
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/alerts", nil)
+		req.Header.Set(HeaderRequestID, "abc-123")
+		a.Request(apirouter.New(), testHandle)(w, req, nil)
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		require.Contains(t, buf.String(), "request_id=abc-123")
+		require.Contains(t, buf.String(), "path=/v1/alerts")
+		require.Contains(t, buf.String(), "status=503")
+		require.Contains(t, buf.String(), "principal=anonymous")
+	})
+}
+
+// TestRoutePattern will test the method routePattern()
+func TestRoutePattern(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "/v1/alerts", routePattern("/v1/alerts", nil))
+	require.Equal(t, "/v1/alert/:sequence", routePattern("/v1/alert/12", httprouter.Params{
+		{Key: "sequence", Value: "12"},
+	}))
+	require.Equal(t, "/v1/admin/webhooks/:id", routePattern("/v1/admin/webhooks/3", httprouter.Params{
+		{Key: "id", Value: "3"},
+	}))
+	require.Equal(t, "/debug/pprof/*name", routePattern("/debug/pprof/heap", httprouter.Params{
+		{Key: "name", Value: "/heap"},
+	}))
+}
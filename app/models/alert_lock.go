@@ -0,0 +1,152 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/mrz1836/go-datastore"
+)
+
+// AlertLock is an object representing the enforcement lock of an alert sequence (the instances sharing the datastore)
+// The holder executes the node actions of the alert and releases the lock, the lock of a crashed holder expires
+type AlertLock struct {
+	// Base model
+	model.Model `bson:",inline"`
+
+	// Model specific fields
+	ID             uint64    `json:"id" toml:"id" yaml:"id" bson:"_id" gorm:"primaryKey;comment:This is a unique identifier"`
+	SequenceNumber uint32    `json:"sequence_number" toml:"sequence_number" yaml:"sequence_number" bson:"sequence_number" gorm:"<-;uniqueIndex;comment:This is the alert sequence number"`
+	Holder         string    `json:"holder" toml:"holder" yaml:"holder" bson:"holder" gorm:"<-;type:varchar(255);comment:This is the instance holding the lock (the last one enforcing the alert)"`
+	ExpiresAt      time.Time `json:"expires_at" toml:"expires_at" yaml:"expires_at" bson:"expires_at" gorm:"<-;comment:The time the lock expires if it is not released"`
+}
+
+// NewAlertLock creates a new alert lock
+func NewAlertLock(opts ...model.Options) *AlertLock {
+	return &AlertLock{
+		Model: *model.NewBaseModel(model.NameAlertLock, opts...),
+	}
+}
+
+// Name will get the name of the model
+func (m *AlertLock) Name() string {
+	return model.NameAlertLock.String()
+}
+
+// GetTableName will get the database table name of the model
+func (m *AlertLock) GetTableName() string {
+	return model.TableAlertLocks
+}
+
+// GetID will get the model ID
+func (m *AlertLock) GetID() uint64 {
+	return m.ID
+}
+
+// Display filter the model for display
+func (m *AlertLock) Display() interface{} {
+	return m
+}
+
+// Migrate will run model specific migrations on startup
+func (m *AlertLock) Migrate(client datastore.ClientInterface) error {
+	return client.IndexMetadata(client.GetTableName(model.TableAlertLocks), model.MetadataField)
+}
+
+// BeginSaveWithTx will start saving the model into the Datastore with the provided transaction
+func (m *AlertLock) BeginSaveWithTx(ctx context.Context, tx *datastore.Transaction) ([]model.BaseInterface, error) {
+	return model.BeginSaveWithTx(ctx, tx, m)
+}
+
+// Save will save the model into the Datastore
+func (m *AlertLock) Save(ctx context.Context) error {
+	return model.Save(ctx, m)
+}
+
+// IsHeldBy will return true if the lock is held by the instance and not expired
+func (m *AlertLock) IsHeldBy(holder string) bool {
+	return m.Holder == holder && m.ExpiresAt.After(m.Now())
+}
+
+// AcquireAlertLock will take the lock of the alert sequence if it is released, expired or already held by the holder
+// (created on the first attempt), false if another instance holds it
+// The update is a single conditional statement and the sequence is unique, only one instance can take the lock
+func AcquireAlertLock(ctx context.Context, sequenceNumber uint32, holder string, ttl time.Duration,
+	opts ...model.Options) (bool, error) {
+
+	// Take the lock if it is free
+	lock := NewAlertLock(opts...)
+	now := lock.Now()
+	ds := lock.Datastore()
+	if ds == nil {
+		return false, model.ErrMissingDatastore
+	}
+	result := ds.Raw("").Exec(
+		"UPDATE "+ds.GetTableName(model.TableAlertLocks)+" SET "+
+			utils.FieldHolder+" = ?, "+utils.FieldExpiresAt+" = ?, "+utils.FieldUpdatedAt+" = ? "+
+			"WHERE "+utils.FieldSequenceNumber+" = ? AND ("+utils.FieldHolder+" = ? OR "+utils.FieldExpiresAt+" < ?)",
+		holder, now.Add(ttl), now, sequenceNumber, holder, now,
+	)
+	if result.Error != nil {
+		return false, result.Error
+	} else if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	// Create the lock (another instance holds it if it exists, or if it created it first)
+	current, err := GetAlertLock(ctx, sequenceNumber, opts...)
+	if err != nil {
+		return false, err
+	} else if current != nil {
+		return current.IsHeldBy(holder), nil
+	}
+	lock.SequenceNumber = sequenceNumber
+	lock.Holder = holder
+	lock.ExpiresAt = now.Add(ttl)
+	if err = lock.Save(ctx); err != nil {
+		if current, _ = GetAlertLock(ctx, sequenceNumber, opts...); current != nil {
+			return current.IsHeldBy(holder), nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// ReleaseAlertLock will expire the lock of the alert sequence if the holder has it (the row is kept, the holder is the
+// last instance that enforced the alert)
+func ReleaseAlertLock(_ context.Context, sequenceNumber uint32, holder string, opts ...model.Options) error {
+	lock := NewAlertLock(opts...)
+	ds := lock.Datastore()
+	if ds == nil {
+		return model.ErrMissingDatastore
+	}
+	now := lock.Now()
+	return ds.Raw("").Exec(
+		"UPDATE "+ds.GetTableName(model.TableAlertLocks)+" SET "+
+			utils.FieldExpiresAt+" = ?, "+utils.FieldUpdatedAt+" = ? "+
+			"WHERE "+utils.FieldSequenceNumber+" = ? AND "+utils.FieldHolder+" = ?",
+		now, now, sequenceNumber, holder,
+	).Error
+}
+
+// GetAlertLock will get the lock of the alert sequence (if found)
+func GetAlertLock(ctx context.Context, sequenceNumber uint32, opts ...model.Options) (*AlertLock, error) {
+
+	// Get the record (from the write database, a replica may lag behind the other instances)
+	lock := NewAlertLock(opts...)
+	conditions := map[string]interface{}{
+		utils.FieldSequenceNumber: sequenceNumber,
+	}
+	if err := model.Get(
+		ctx, lock, conditions, model.DefaultDatabaseReadTimeout, true,
+	); err != nil {
+		if errors.Is(err, datastore.ErrNoResults) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return lock, nil
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/clock"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAlertLock will test the alert locks
+func (ts *TestSuite) TestAlertLock() {
+	ts.T().Run("success - no options, base model", func(t *testing.T) {
+		lock := NewAlertLock()
+		require.NotNil(t, lock)
+		assert.NotNil(t, lock.Logger())
+		assert.Equal(t, uint64(0), lock.GetID())
+		assert.Equal(t, model.NameAlertLock.String(), lock.Name())
+		assert.Equal(t, model.TableAlertLocks, lock.GetTableName())
+	})
+
+	ts.T().Run("success - held by one instance until released", func(t *testing.T) {
+		ctx := context.Background()
+		now := clock.NewMock(time.Now())
+		ts.Dependencies.Services.Clock = now
+		opts := model.WithAllDependencies(ts.Dependencies)
+
+		locked, err := AcquireAlertLock(ctx, 42, "node-a", time.Minute, opts)
+		require.NoError(t, err)
+		assert.True(t, locked)
+
+		// The other instance does not enforce the alert while it is locked
+		locked, err = AcquireAlertLock(ctx, 42, "node-b", time.Minute, opts)
+		require.NoError(t, err)
+		assert.False(t, locked)
+		locked, err = AcquireAlertLock(ctx, 43, "node-b", time.Minute, opts)
+		require.NoError(t, err)
+		assert.True(t, locked, "another sequence")
+
+		// Released by the holder, the other instance can take it
+		require.NoError(t, ReleaseAlertLock(ctx, 42, "node-b", opts), "not the holder, nothing is released")
+		require.NoError(t, ReleaseAlertLock(ctx, 42, "node-a", opts))
+		now.Advance(time.Millisecond)
+		locked, err = AcquireAlertLock(ctx, 42, "node-b", time.Minute, opts)
+		require.NoError(t, err)
+		assert.True(t, locked)
+
+		lock, err := GetAlertLock(ctx, 42, opts)
+		require.NoError(t, err)
+		require.NotNil(t, lock)
+		assert.True(t, lock.IsHeldBy("node-b"))
+	})
+
+	ts.T().Run("success - expired lock taken over", func(t *testing.T) {
+		ctx := context.Background()
+		now := clock.NewMock(time.Now())
+		ts.Dependencies.Services.Clock = now
+		opts := model.WithAllDependencies(ts.Dependencies)
+
+		locked, err := AcquireAlertLock(ctx, 50, "node-a", time.Minute, opts)
+		require.NoError(t, err)
+		assert.True(t, locked)
+		now.Advance(59 * time.Second)
+		locked, err = AcquireAlertLock(ctx, 50, "node-b", time.Minute, opts)
+		require.NoError(t, err)
+		assert.False(t, locked, "not expired yet")
+		now.Advance(2 * time.Second)
+		locked, err = AcquireAlertLock(ctx, 50, "node-b", time.Minute, opts)
+		require.NoError(t, err)
+		assert.True(t, locked)
+	})
+
+	ts.T().Run("not found", func(t *testing.T) {
+		lock, err := GetAlertLock(context.Background(), 999, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		assert.Nil(t, lock)
+	})
+}
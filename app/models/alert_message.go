@@ -2,16 +2,21 @@ package models
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/bitcoin-sv/alert-system/app/config"
 	"github.com/bitcoin-sv/alert-system/app/models/model"
 	"github.com/bitcoin-sv/alert-system/utils"
 	"github.com/bitcoinschema/go-bitcoin"
-	"github.com/bitcoinsv/bsvd/bsvec"
 	"github.com/bitcoinsv/bsvutil"
 	"github.com/libsv/go-bt/v2/chainhash"
 	"github.com/mrz1836/go-datastore"
@@ -33,6 +38,8 @@ type AlertMessage struct {
 	alertType  AlertType
 	data       []byte
 	message    []byte
+	outbox     []*OutboxEvent // Events saved with the alert (same transaction)
+	receivedAt time.Time
 	signatures [][]byte
 	timestamp  uint64
 	version    uint32
@@ -88,6 +95,33 @@ func (m *AlertMessage) Save(ctx context.Context) error {
 	return model.Save(ctx, m)
 }
 
+// ChildModels will return the search terms to save with a new alert (decoded from the payload)
+// and the outbox events not saved yet
+func (m *AlertMessage) ChildModels() []model.BaseInterface {
+	children := make([]model.BaseInterface, 0)
+	if m.IsNew() {
+		for _, term := range m.searchTerms() {
+			children = append(children, term)
+		}
+	}
+	for _, event := range m.outbox {
+		if event.IsNew() {
+			children = append(children, event)
+		}
+	}
+	return children
+}
+
+// AddOutboxEvent will add an event to save in the same transaction as the alert
+func (m *AlertMessage) AddOutboxEvent(event *OutboxEvent) {
+	m.outbox = append(m.outbox, event)
+}
+
+// OutboxEvents will return the events saved with the alert
+func (m *AlertMessage) OutboxEvents() []*OutboxEvent {
+	return m.outbox
+}
+
 // SetAlertType will set the alert type
 func (m *AlertMessage) SetAlertType(t AlertType) {
 	m.alertType = t
@@ -142,6 +176,8 @@ func (m *AlertMessage) SetSignatures(sigs [][]byte) {
 }
 
 // AreSignaturesValid checks if the signatures are valid
+// The result is cached by the alert hash, its signatures and the active keys (the same alert gossiped again is not
+// verified again, a change of keys verifies it again)
 func (m *AlertMessage) AreSignaturesValid(ctx context.Context) (bool, error) {
 	keys, err := GetActivePublicKey(ctx, nil, model.WithAllDependencies(m.Config()))
 	if err != nil {
@@ -150,42 +186,96 @@ func (m *AlertMessage) AreSignaturesValid(ctx context.Context) (bool, error) {
 		return false, fmt.Errorf("no active public keys found")
 	}
 
-	// Loop through all signatures
+	// Reuse the result of a previous verification
+	cache := m.Config().Services.Signatures
+	key := m.signatureCacheKey(keys)
+	if valid, ok := cache.Get(key); ok {
+		return valid, nil
+	}
+	var valid bool
+	if valid, err = m.verifySignatures(ctx, keys); err != nil {
+		return false, err
+	}
+	cache.Add(key, valid)
+	return valid, nil
+}
+
+// signatureCacheKey will return the verification cache key of the alert (its hash, then the hash of its signatures
+// and the keys they are verified with)
+func (m *AlertMessage) signatureCacheKey(keys []*PublicKey) string {
+	h := sha256.New()
 	for _, sig := range m.signatures {
-		b64Sig := base64.StdEncoding.EncodeToString(sig)
-		valid := false
+		_, _ = h.Write(sig)
+		_, _ = h.Write([]byte{0})
+	}
+	for _, key := range keys {
+		_, _ = h.Write([]byte(key.Key))
+		_, _ = h.Write([]byte{0})
+	}
+	return chainhash.DoubleHashH(m.data).String() + ":" + hex.EncodeToString(h.Sum(nil))
+}
 
-		// Loop through all keys
-		for _, key := range keys {
+// verifySignatures will check each signature is made by one of the keys
+// The signatures are verified concurrently by a pool of GOMAXPROCS workers (the remaining ones are skipped once a
+// signature is invalid)
+func (m *AlertMessage) verifySignatures(ctx context.Context, keys []*PublicKey) (bool, error) {
 
-			// Get the public key
-			var pub *bsvec.PublicKey
-			if pub, err = bitcoin.PubKeyFromString(key.Key); err != nil {
-				return false, err
-			}
+	// Get the address of each key (once for all the signatures)
+	addresses := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pub, err := bitcoin.PubKeyFromString(key.Key)
+		if err != nil {
+			return false, err
+		}
+		var addr *bsvutil.LegacyAddressPubKeyHash
+		if addr, err = bitcoin.GetAddressFromPubKey(pub, true); err != nil {
+			return false, err
+		} else if addr == nil {
+			return false, errors.New("failed to convert pub key to address")
+		}
+		addresses = append(addresses, addr.String())
+	}
 
-			// Get the address
-			var addr *bsvutil.LegacyAddressPubKeyHash
-			if addr, err = bitcoin.GetAddressFromPubKey(pub, true); err != nil {
-				return false, err
-			} else if addr == nil {
-				return false, errors.New("failed to convert pub key to address")
+	// Verify the signatures
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(m.signatures) {
+		workers = len(m.signatures)
+	}
+	message := hex.EncodeToString(m.data)
+	indexes := make(chan int, len(m.signatures))
+	for i := range m.signatures {
+		indexes <- i
+	}
+	close(indexes)
+	var invalid atomic.Bool
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if !invalid.Load() && !m.isSignedByAny(ctx, m.signatures[i], addresses, message) {
+					invalid.Store(true)
+				}
 			}
+		}()
+	}
+	wg.Wait()
 
-			// Verify the message
-			if err = bitcoin.VerifyMessage(addr.String(), b64Sig, hex.EncodeToString(m.data)); err != nil {
-				m.Config().Services.Log.Debugf("error verifying signature %x: %v", sig, err)
-				continue
-			}
-			valid = true
-			break
-		}
-		if !valid {
-			return false, nil
+	return !invalid.Load(), nil
+}
+
+// isSignedByAny will return true if the signature of the message is made by one of the addresses
+func (m *AlertMessage) isSignedByAny(ctx context.Context, sig []byte, addresses []string, message string) bool {
+	b64Sig := base64.StdEncoding.EncodeToString(sig)
+	for _, address := range addresses {
+		if err := bitcoin.VerifyMessage(address, b64Sig, message); err != nil {
+			config.LoggerFromContext(ctx, m.Config().Services.Log).Debugf("error verifying signature %x: %v", sig, err)
+			continue
 		}
+		return true
 	}
-
-	return true, nil
+	return false
 }
 
 // ProcessAlertMessage processes the alert message and converts to an alert message interface
@@ -249,6 +339,29 @@ func (m *AlertMessage) Timestamp() uint64 {
 	return m.timestamp
 }
 
+// SetReceivedAt sets when the message was received over gossip
+func (m *AlertMessage) SetReceivedAt(t time.Time) {
+	m.receivedAt = t
+}
+
+// ReceivedAt returns when the message was received over gossip (zero if it was synced or loaded)
+func (m *AlertMessage) ReceivedAt() time.Time {
+	return m.receivedAt
+}
+
+// PropagationDelay returns the time from the message timestamp (when it was created) to it being received
+// False is returned if the message has no timestamp or was not received over gossip
+func (m *AlertMessage) PropagationDelay() (time.Duration, bool) {
+	if m.timestamp == 0 || m.receivedAt.IsZero() {
+		return 0, false
+	}
+	delay := m.receivedAt.Sub(time.Unix(int64(m.timestamp), 0))
+	if delay < 0 { // Clock skew between the creator and this node
+		delay = 0
+	}
+	return delay, true
+}
+
 // ReadRaw sets the model fields based on the raw message
 func (m *AlertMessage) ReadRaw() error {
 	if len(m.GetRawMessage()) == 0 {
@@ -259,9 +372,9 @@ func (m *AlertMessage) ReadRaw() error {
 		m.SetRawMessage(ak)
 	}
 
-	if len(m.GetRawMessage()) < 16 {
+	if len(m.GetRawMessage()) < 20 {
 		// todo DETERMINE ACTUAL PROPER LENGTH
-		return fmt.Errorf("alert needs to be at least 16 bytes")
+		return fmt.Errorf("alert needs to be at least 20 bytes")
 	}
 	ak := m.GetRawMessage()
 	version := binary.LittleEndian.Uint32(ak[:4])
@@ -378,6 +491,67 @@ func GetLatestAlert(ctx context.Context, metadata *model.Metadata, opts ...model
 	return modelItems[0], nil
 }
 
+// GetFirstAlert will get the alert with the lowest sequence number
+func GetFirstAlert(ctx context.Context, metadata *model.Metadata, opts ...model.Options) (*AlertMessage, error) {
+
+	// Set the conditions
+	conditions := &map[string]interface{}{
+		utils.FieldDeletedAt: map[string]interface{}{ // IS NULL
+			utils.ExistsCondition: false,
+		},
+	}
+
+	// Set the query params
+	queryParams := &datastore.QueryParams{
+		Page:          1,
+		PageSize:      1,
+		OrderByField:  utils.FieldSequenceNumber,
+		SortDirection: utils.SortAscending,
+	}
+
+	// Get the record
+	modelItems := make([]*AlertMessage, 0)
+	if err := model.GetModelsByConditions(
+		ctx, model.NameAlertMessage, &modelItems, metadata, conditions, queryParams, opts...,
+	); err != nil {
+		return nil, err
+	} else if len(modelItems) == 0 {
+		return nil, nil
+	}
+
+	// Return the first item (only item)
+	return modelItems[0], nil
+}
+
+// GetRecentAlerts will get the most recent alerts (newest first, up to the limit)
+func GetRecentAlerts(ctx context.Context, limit int, metadata *model.Metadata, opts ...model.Options) ([]*AlertMessage, error) {
+
+	// Set the conditions
+	conditions := &map[string]interface{}{
+		utils.FieldDeletedAt: map[string]interface{}{ // IS NULL
+			utils.ExistsCondition: false,
+		},
+	}
+
+	// Set the query params
+	queryParams := &datastore.QueryParams{
+		Page:          1,
+		PageSize:      utils.PageSize(limit),
+		OrderByField:  utils.FieldSequenceNumber,
+		SortDirection: utils.SortDescending,
+	}
+
+	// Get the records
+	modelItems := make([]*AlertMessage, 0)
+	if err := model.GetModelsByConditions(
+		ctx, model.NameAlertMessage, &modelItems, metadata, conditions, queryParams, opts...,
+	); err != nil {
+		return nil, err
+	}
+
+	return modelItems, nil
+}
+
 // GetAllAlerts returns all alerts in the database
 func GetAllAlerts(ctx context.Context, metadata *model.Metadata, opts ...model.Options) ([]*AlertMessage, error) {
 	// Set the conditions
@@ -407,6 +581,158 @@ func GetAllAlerts(ctx context.Context, metadata *model.Metadata, opts ...model.O
 	return modelItems, nil
 }
 
+// GetAlertsPage will get a page of alerts after the cursor (ordered by sequence number)
+// The next cursor is nil if there are no more alerts
+func GetAlertsPage(ctx context.Context, cursor *utils.Cursor, limit int, metadata *model.Metadata,
+	opts ...model.Options) ([]*AlertMessage, *utils.Cursor, error) {
+
+	// Set the conditions
+	conditions := &map[string]interface{}{
+		utils.FieldDeletedAt: map[string]interface{}{ // IS NULL
+			utils.ExistsCondition: false,
+		},
+	}
+	if cursor != nil {
+		(*conditions)[utils.FieldSequenceNumber] = map[string]interface{}{
+			utils.GreaterThanCondition: cursor.Sequence,
+		}
+	}
+
+	// Set the query params (one extra record to detect the next page)
+	limit = utils.PageSize(limit)
+	queryParams := &datastore.QueryParams{
+		Page:          1,
+		PageSize:      limit + 1,
+		OrderByField:  utils.FieldSequenceNumber,
+		SortDirection: utils.SortAscending,
+	}
+
+	// Get the records
+	modelItems := make([]*AlertMessage, 0)
+	if err := model.GetModelsByConditions(
+		ctx, model.NameAlertMessage, &modelItems, metadata, conditions, queryParams, opts...,
+	); err != nil {
+		return nil, nil, err
+	} else if len(modelItems) <= limit {
+		return modelItems, nil, nil
+	}
+
+	// Set the cursor to the last alert in the page
+	modelItems = modelItems[:limit]
+	last := modelItems[limit-1]
+	return modelItems, &utils.Cursor{
+		Sequence:  uint64(last.SequenceNumber),
+		Timestamp: last.CreatedAt.Unix(),
+	}, nil
+}
+
+// GetAlertsInRange will get the alerts from and to the sequence numbers (inclusive, ordered by sequence number)
+func GetAlertsInRange(ctx context.Context, from, to uint32, metadata *model.Metadata,
+	opts ...model.Options) ([]*AlertMessage, error) {
+
+	// Set the conditions
+	conditions := &map[string]interface{}{
+		utils.FieldDeletedAt: map[string]interface{}{ // IS NULL
+			utils.ExistsCondition: false,
+		},
+		utils.FieldSequenceNumber: map[string]interface{}{
+			utils.GreaterOrEqualCondition:  from,
+			utils.LessThanOrEqualCondition: to,
+		},
+	}
+
+	// Set the query params
+	queryParams := &datastore.QueryParams{
+		OrderByField:  utils.FieldSequenceNumber,
+		SortDirection: utils.SortAscending,
+	}
+
+	// Get the records
+	modelItems := make([]*AlertMessage, 0)
+	if err := model.GetModelsByConditions(
+		ctx, model.NameAlertMessage, &modelItems, metadata, conditions, queryParams, opts...,
+	); err != nil {
+		return nil, err
+	}
+
+	return modelItems, nil
+}
+
+// CountAlertsInRange will count the alerts from and to the sequence numbers (inclusive)
+func CountAlertsInRange(ctx context.Context, from, to uint32, opts ...model.Options) (int64, error) {
+	return countModels(ctx, NewAlertMessage(opts...), map[string]interface{}{
+		utils.FieldDeletedAt: map[string]interface{}{ // IS NULL
+			utils.ExistsCondition: false,
+		},
+		utils.FieldSequenceNumber: map[string]interface{}{
+			utils.GreaterOrEqualCondition:  from,
+			utils.LessThanOrEqualCondition: to,
+		},
+	})
+}
+
+// GetMissingSequences will get the sequence numbers missing between the first and the latest saved alert (up to the limit)
+// The alerts are never loaded: the count of the alerts is compared with the range, and only the ranges with gaps
+// are counted again (see appendMissingSequences)
+func GetMissingSequences(ctx context.Context, limit int, opts ...model.Options) ([]uint32, error) {
+
+	// Get the range of the saved alerts
+	first, err := GetFirstAlert(ctx, nil, opts...)
+	if err != nil {
+		return nil, err
+	} else if first == nil {
+		return make([]uint32, 0), nil
+	}
+	var latest *AlertMessage
+	if latest, err = GetLatestAlert(ctx, nil, opts...); err != nil {
+		return nil, err
+	} else if latest == nil {
+		return make([]uint32, 0), nil
+	}
+	return appendMissingSequences(ctx, make([]uint32, 0), first.SequenceNumber, latest.SequenceNumber, limit, opts...)
+}
+
+// appendMissingSequences will append the sequence numbers missing from and to the sequence numbers (inclusive, up to
+// the limit). A range is skipped if it is full, the range is split in halves until each half is full or empty.
+func appendMissingSequences(ctx context.Context, missing []uint32, from, to uint32, limit int,
+	opts ...model.Options,
+) ([]uint32, error) {
+	if len(missing) >= limit {
+		return missing, nil
+	}
+	count, err := CountAlertsInRange(ctx, from, to, opts...)
+	if err != nil {
+		return nil, err
+	} else if count >= int64(to-from)+1 { // No gaps
+		return missing, nil
+	} else if count == 0 { // All missing
+		for seq := from; len(missing) < limit; seq++ {
+			missing = append(missing, seq)
+			if seq == to {
+				break
+			}
+		}
+		return missing, nil
+	}
+
+	// Some missing (the range has at least two sequences)
+	middle := from + (to-from)/2
+	if missing, err = appendMissingSequences(ctx, missing, from, middle, limit, opts...); err != nil {
+		return nil, err
+	}
+	return appendMissingSequences(ctx, missing, middle+1, to, limit, opts...)
+}
+
+// CountUnprocessedAlerts will count the alerts that weren't successfully processed
+func CountUnprocessedAlerts(ctx context.Context, opts ...model.Options) (int64, error) {
+	return countModels(ctx, NewAlertMessage(opts...), map[string]interface{}{
+		utils.FieldDeletedAt: map[string]interface{}{ // IS NULL
+			utils.ExistsCondition: false,
+		},
+		"processed": false,
+	})
+}
+
 // GetAllUnprocessedAlerts will get all alerts that weren't successfully processed
 func GetAllUnprocessedAlerts(ctx context.Context, metadata *model.Metadata, opts ...model.Options) ([]*AlertMessage, error) {
 
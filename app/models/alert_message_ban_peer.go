@@ -61,7 +61,7 @@ func (a *AlertMessageBanPeer) Read(alert []byte) error {
 
 // Do executes the alert
 func (a *AlertMessageBanPeer) Do(ctx context.Context) error {
-	return a.Config().Services.Node.BanPeer(ctx, string(a.Peer))
+	return a.Config().Services.Node.BanPeer(a.nodeContext(ctx), string(a.Peer))
 }
 
 // ToJSON is the alert in JSON format
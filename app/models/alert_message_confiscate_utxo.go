@@ -70,7 +70,7 @@ func (a *AlertMessageConfiscateTransaction) Read(raw []byte) error {
 
 // Do executes the alert
 func (a *AlertMessageConfiscateTransaction) Do(ctx context.Context) error {
-	res, err := a.Config().Services.Node.AddToConfiscationTransactionWhitelist(ctx, a.Transactions)
+	res, err := a.Config().Services.Node.AddToConfiscationTransactionWhitelist(a.nodeContext(ctx), a.Transactions)
 	if err != nil {
 		return err
 	}
@@ -84,7 +84,7 @@ func (a *AlertMessageFreezeUtxo) Read(raw []byte) error {
 
 // Do performs the message
 func (a *AlertMessageFreezeUtxo) Do(ctx context.Context) error {
-	_, err := a.Config().Services.Node.AddToConsensusBlacklist(ctx, a.Funds)
+	_, err := a.Config().Services.Node.AddToConsensusBlacklist(a.nodeContext(ctx), a.Funds)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/bitcoinschema/go-bitcoin"
+	"github.com/stretchr/testify/require"
+)
+
+// legacySignaturesType is the alert type ReadRaw reads 128 bytes of signatures for (one signature of 65 bytes is
+// kept, so the alert does not serialize back to the same bytes)
+const legacySignaturesType = 99
+
+// newFuzzConfig will return the dependencies of the fuzzed alerts (the logs are discarded)
+func newFuzzConfig() *config.Config {
+	return &config.Config{Services: config.Services{Log: &config.ExtendedLogger{Logger: log.New(io.Discard, "", 0)}}}
+}
+
+// newFuzzAlert will return the raw informational alert signed with the genesis keys (a valid seed of the fuzz targets)
+func newFuzzAlert(tb testing.TB, sequence uint32) []byte {
+	var data []byte
+	data = binary.LittleEndian.AppendUint32(data, 1)
+	data = binary.LittleEndian.AppendUint32(data, sequence)
+	data = binary.LittleEndian.AppendUint64(data, 1700000000)
+	data = binary.LittleEndian.AppendUint32(data, uint32(AlertTypeInformational))
+	data = append(data, append([]byte{10}, "fuzz alert"...)...)
+	sigs, err := utils.SignWithGenesis(data)
+	require.NoError(tb, err)
+	for _, sig := range sigs {
+		data = append(data, sig...)
+	}
+	return data
+}
+
+// FuzzNewAlertFromBytes will test the alerts received from the peers are parsed without panicking
+// A parsed alert serializes back to the same bytes, and its message is read by its type
+func FuzzNewAlertFromBytes(f *testing.F) {
+	valid := newFuzzAlert(f, 1)
+	f.Add(valid)
+	f.Add(valid[:20])
+	f.Add(valid[:len(valid)-1])
+	f.Add([]byte{})
+	f.Add(make([]byte, 19))
+	for _, alertType := range []AlertType{
+		AlertTypeBanPeer, AlertTypeConfiscateUtxo, AlertTypeFreezeUtxo, AlertTypeInvalidateBlock,
+		AlertTypeSetKeys, AlertTypeUnbanPeer, AlertTypeUnfreezeUtxo,
+	} {
+		typed := append([]byte(nil), valid...)
+		binary.LittleEndian.PutUint32(typed[16:20], uint32(alertType))
+		f.Add(typed)
+	}
+
+	conf := newFuzzConfig()
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		alert, err := NewAlertFromBytes(raw, model.WithAllDependencies(conf))
+		if err != nil {
+			return
+		}
+		if alert.GetAlertType() != legacySignaturesType {
+			require.Equal(t, raw, alert.Serialize())
+		}
+		if message := alert.ProcessAlertMessage(); message != nil {
+			_ = message.Read(alert.GetRawMessage())
+		}
+	})
+}
+
+// FuzzAlertSignatures will test the signatures received from the peers are verified without panicking
+// Only the signature made with the genesis key is valid
+func FuzzAlertSignatures(f *testing.F) {
+	valid := newFuzzAlert(f, 1)
+	signature := valid[len(valid)-195 : len(valid)-130]
+	f.Add(signature)
+	f.Add(signature[1:])
+	f.Add(append([]byte{signature[0] + 4}, signature[1:]...))
+	f.Add(make([]byte, 65))
+	f.Add([]byte{})
+
+	publicKey, err := bitcoin.PubKeyFromPrivateKeyString(utils.Key1, true)
+	require.NoError(f, err)
+	conf := newFuzzConfig()
+
+	f.Fuzz(func(t *testing.T, sig []byte) {
+		alert, err := NewAlertFromBytes(valid, model.WithAllDependencies(conf))
+		require.NoError(t, err)
+		alert.SetSignatures([][]byte{sig})
+
+		var ok bool
+		ok, err = alert.verifySignatures(context.Background(), []*PublicKey{{Key: publicKey}})
+		require.NoError(t, err)
+		if bytes.Equal(sig, signature) {
+			require.True(t, ok)
+		}
+	})
+}
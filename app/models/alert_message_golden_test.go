@@ -0,0 +1,201 @@
+package models
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// updateGolden will rewrite the golden files of the alert serialization (make golden)
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// goldenDir is the directory of the golden files (<alert>.hex is the wire format, <alert>.json its decoding)
+const goldenDir = "testdata/golden"
+
+// goldenTimestamp is the timestamp of the golden alerts (the signatures are deterministic, RFC6979)
+const goldenTimestamp = 1700000000
+
+// goldenMessage is the message of an alert type in the golden files
+type goldenMessage struct {
+	alertType AlertType
+	message   []byte
+	slug      string
+}
+
+// goldenSignatures is a signature arrangement in the golden files
+type goldenSignatures struct {
+	keys []string // Private keys signing the alert, in order
+	slug string
+}
+
+// goldenAlert is the JSON decoding of a golden alert
+type goldenAlert struct {
+	AlertType      AlertType       `json:"alert_type"`
+	AlertTypeName  string          `json:"alert_type_name"`
+	Hash           string          `json:"hash"`
+	Message        json.RawMessage `json:"message,omitempty"` // ToJSON of the alert type
+	SequenceNumber uint32          `json:"sequence_number"`
+	Signatures     []string        `json:"signatures"`
+	Timestamp      uint64          `json:"timestamp"`
+	Version        uint32          `json:"version"`
+}
+
+// goldenVarString will return the string prefixed with its var int length (shorter than 0xfd bytes)
+func goldenVarString(s string) []byte {
+	return append([]byte{byte(len(s))}, s...)
+}
+
+// goldenMessages will return a message of each alert type
+func goldenMessages(tb testing.TB) []goldenMessage {
+	fund := Fund{Vout: 1, EnforceAtHeightStart: 800000, EnforceAtHeightEnd: 900000, PolicyExpiresWithConsensus: true}
+	for i := range fund.TransactionOutID {
+		fund.TransactionOutID[i] = byte(i)
+	}
+
+	var confiscation []byte
+	confiscation = binary.LittleEndian.AppendUint64(confiscation, 850000)
+	confiscation = append(confiscation, goldenVarString("\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00")...)
+
+	var blockHash []byte
+	for i := 0; i < 32; i++ {
+		blockHash = append(blockHash, byte(0xff-i))
+	}
+
+	var keys []byte
+	for _, key := range []string{utils.MainKey1, utils.MainKey2, utils.MainKey3, utils.MainKey4, utils.MainKey5} {
+		b, err := hex.DecodeString(key)
+		require.NoError(tb, err)
+		keys = append(keys, b...)
+	}
+
+	return []goldenMessage{
+		{alertType: AlertTypeInformational, slug: "informational", message: goldenVarString("golden informational alert")},
+		{alertType: AlertTypeFreezeUtxo, slug: "freeze_utxo", message: fund.Serialize()},
+		{alertType: AlertTypeUnfreezeUtxo, slug: "unfreeze_utxo", message: fund.Serialize()},
+		{alertType: AlertTypeConfiscateUtxo, slug: "confiscate_utxo", message: confiscation},
+		{
+			alertType: AlertTypeBanPeer, slug: "ban_peer",
+			message: append(goldenVarString("127.0.0.1/24"), goldenVarString("golden ban")...),
+		},
+		{
+			alertType: AlertTypeUnbanPeer, slug: "unban_peer",
+			message: append(goldenVarString("127.0.0.1/24"), goldenVarString("golden unban")...),
+		},
+		{
+			alertType: AlertTypeInvalidateBlock, slug: "invalidate_block",
+			message: append(blockHash, goldenVarString("golden invalidation")...),
+		},
+		{alertType: AlertTypeSetKeys, slug: "set_keys", message: keys},
+	}
+}
+
+// goldenArrangements are the signature arrangements (3 of the 5 genesis keys, in any order)
+var goldenArrangements = []goldenSignatures{
+	{slug: "keys_1_2_3", keys: []string{utils.Key1, utils.Key2, utils.Key3}},
+	{slug: "keys_3_4_5", keys: []string{utils.Key3, utils.Key4, utils.Key5}},
+	{slug: "keys_5_3_1", keys: []string{utils.Key5, utils.Key3, utils.Key1}},
+}
+
+// newGoldenAlert will return the raw alert of the message signed with the keys
+func newGoldenAlert(tb testing.TB, sequence uint32, msg goldenMessage, keys []string) []byte {
+	var data []byte
+	data = binary.LittleEndian.AppendUint32(data, 1)
+	data = binary.LittleEndian.AppendUint32(data, sequence)
+	data = binary.LittleEndian.AppendUint64(data, goldenTimestamp)
+	data = binary.LittleEndian.AppendUint32(data, uint32(msg.alertType))
+	data = append(data, msg.message...)
+	sigs, err := utils.SignWithKeys(data, keys)
+	require.NoError(tb, err)
+	for _, sig := range sigs {
+		data = append(data, sig...)
+	}
+	return data
+}
+
+// decodeGoldenAlert will return the JSON decoding of the raw alert
+func decodeGoldenAlert(tb testing.TB, raw []byte) []byte {
+	alert, err := NewAlertFromBytes(raw, model.WithAllDependencies(newFuzzConfig()))
+	require.NoError(tb, err)
+
+	decoded := goldenAlert{
+		AlertType:      alert.GetAlertType(),
+		AlertTypeName:  alert.GetAlertType().Name(),
+		Hash:           alert.Hash,
+		SequenceNumber: alert.SequenceNumber,
+		Signatures:     []string{},
+		Timestamp:      alert.Timestamp(),
+		Version:        alert.Version(),
+	}
+	for _, sig := range alert.signatures {
+		decoded.Signatures = append(decoded.Signatures, hex.EncodeToString(sig))
+	}
+	if message := alert.ProcessAlertMessage(); message != nil {
+		require.NoError(tb, message.Read(alert.GetRawMessage()))
+		decoded.Message = message.ToJSON(context.Background())
+	}
+
+	data, err := json.MarshalIndent(decoded, "", "  ")
+	require.NoError(tb, err)
+	return append(data, '\n')
+}
+
+// checkGolden will compare the data with the golden file (rewritten with -update)
+func checkGolden(t *testing.T, name string, data []byte) []byte {
+	path := filepath.Join(goldenDir, name)
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll(goldenDir, 0o750))
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+	}
+	golden, err := os.ReadFile(path) //nolint:gosec // Test fixtures
+	require.NoError(t, err, "missing golden file, run: make golden")
+	assert.Equal(t, string(golden), string(data), "%s changed, run make golden if the change is intended", path)
+	return golden
+}
+
+// TestAlertMessage_Golden will test the wire format and the JSON decoding of each alert type and signature arrangement
+// against the golden files, so a change of the serialization is never accidental
+func TestAlertMessage_Golden(t *testing.T) {
+	var sequence uint32
+	for _, msg := range goldenMessages(t) {
+		for _, arrangement := range goldenArrangements {
+			sequence++
+			name := msg.slug + "_" + arrangement.slug
+			t.Run(name, func(t *testing.T) {
+				raw := newGoldenAlert(t, sequence, msg, arrangement.keys)
+				golden := checkGolden(t, name+".hex", []byte(hex.EncodeToString(raw)+"\n"))
+
+				// The golden bytes are parsed and serialized back to the same bytes
+				goldenRaw, err := hex.DecodeString(strings.TrimSpace(string(golden)))
+				require.NoError(t, err)
+				alert, err := NewAlertFromBytes(goldenRaw, model.WithAllDependencies(newFuzzConfig()))
+				require.NoError(t, err)
+				assert.Equal(t, goldenRaw, alert.Serialize())
+				assert.Len(t, alert.signatures, len(arrangement.keys))
+
+				checkGolden(t, name+".json", decodeGoldenAlert(t, goldenRaw))
+			})
+		}
+	}
+
+	// The legacy alerts have 128 bytes of signatures (one signature of 65 bytes is kept)
+	t.Run("legacy_signatures", func(t *testing.T) {
+		msg := goldenMessage{alertType: legacySignaturesType, message: goldenVarString("golden legacy alert")}
+		raw := newGoldenAlert(t, sequence+1, msg, []string{utils.Key1, utils.Key2})[:20+len(msg.message)+128]
+		golden := checkGolden(t, "legacy_signatures.hex", []byte(hex.EncodeToString(raw)+"\n"))
+
+		goldenRaw, err := hex.DecodeString(strings.TrimSpace(string(golden)))
+		require.NoError(t, err)
+		checkGolden(t, "legacy_signatures.json", decodeGoldenAlert(t, goldenRaw))
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/bitcoin-sv/alert-system/app/config"
 	"github.com/libsv/go-p2p/wire"
 )
 
@@ -45,8 +46,8 @@ func (a *AlertMessageInformational) Read(alert []byte) error {
 }
 
 // Do executes the alert
-func (a *AlertMessageInformational) Do(_ context.Context) error {
-	a.Config().Services.Log.Infof("[informational alert]: %s", a.Message)
+func (a *AlertMessageInformational) Do(ctx context.Context) error {
+	config.LoggerFromContext(ctx, a.Config().Services.Log).Infof("[informational alert]: %s", a.Message)
 	return nil
 }
 
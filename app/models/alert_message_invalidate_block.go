@@ -20,6 +20,9 @@ type AlertMessageInvalidateBlock struct {
 
 // Read reads the alert
 func (a *AlertMessageInvalidateBlock) Read(alert []byte) error {
+	if len(alert) < 32 {
+		return fmt.Errorf("invalidate block alert is less than 32 bytes, got %d bytes", len(alert))
+	}
 	blockHash, err := chainhash.NewHash(alert[:32])
 	if err != nil {
 		return err
@@ -49,7 +52,7 @@ func (a *AlertMessageInvalidateBlock) Read(alert []byte) error {
 
 // Do executes the alert
 func (a *AlertMessageInvalidateBlock) Do(ctx context.Context) error {
-	return a.Config().Services.Node.InvalidateBlock(ctx, a.BlockHash.String())
+	return a.Config().Services.Node.InvalidateBlock(a.nodeContext(ctx), a.BlockHash.String())
 }
 
 // ToJSON is the alert in JSON format
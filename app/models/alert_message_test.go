@@ -3,7 +3,9 @@ package models
 import (
 	"context"
 	"encoding/hex"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/bitcoin-sv/alert-system/app/models/model"
 	"github.com/stretchr/testify/assert"
@@ -108,6 +110,46 @@ func (ts *TestSuite) TestAlertMessage_GetLatestAlert() {
 	ts.Require().Equal(uint32(2), message.SequenceNumber)
 }
 
+// TestAlertMessage_GetRecentAlerts will test getting the most recent alerts
+func (ts *TestSuite) TestAlertMessage_GetRecentAlerts() {
+
+	// Create the alert messages
+	for i := uint32(1); i <= 3; i++ {
+		message := NewAlertMessage(model.WithAllDependencies(ts.Dependencies), model.New())
+		message.Hash = testAlertHash + strconv.FormatUint(uint64(i), 10)
+		message.Raw = testAlertRaw
+		message.SequenceNumber = i
+		ts.Require().NoError(message.Save(context.Background()))
+	}
+
+	// Get the two most recent (newest first)
+	alerts, err := GetRecentAlerts(context.Background(), 2, nil, model.WithAllDependencies(ts.Dependencies))
+	ts.Require().NoError(err)
+	ts.Require().Len(alerts, 2)
+	ts.Require().Equal(uint32(3), alerts[0].SequenceNumber)
+	ts.Require().Equal(uint32(2), alerts[1].SequenceNumber)
+}
+
+// TestAlertMessage_GetAlertsInRange will test getting the alerts between two sequence numbers
+func (ts *TestSuite) TestAlertMessage_GetAlertsInRange() {
+
+	// Create the alert messages
+	for i := uint32(1); i <= 4; i++ {
+		message := NewAlertMessage(model.WithAllDependencies(ts.Dependencies), model.New())
+		message.Hash = testAlertHash + strconv.FormatUint(uint64(i), 10)
+		message.Raw = testAlertRaw
+		message.SequenceNumber = i
+		ts.Require().NoError(message.Save(context.Background()))
+	}
+
+	// Get the alerts 2 to 3 (inclusive)
+	alerts, err := GetAlertsInRange(context.Background(), 2, 3, nil, model.WithAllDependencies(ts.Dependencies))
+	ts.Require().NoError(err)
+	ts.Require().Len(alerts, 2)
+	ts.Require().Equal(uint32(2), alerts[0].SequenceNumber)
+	ts.Require().Equal(uint32(3), alerts[1].SequenceNumber)
+}
+
 // TestAlertMessage_SerializeData will test serializing the data
 func (ts *TestSuite) TestAlertMessage_SerializeData() {
 	message := NewAlertMessage(model.WithAllDependencies(ts.Dependencies), model.New())
@@ -129,3 +171,125 @@ func (ts *TestSuite) TestAlertMessage_SerializeData() {
 	ts.Equal("0000000001000000000000000000000001000000", hex.EncodeToString(message.GetRawData()))
 	ts.Equal(AlertTypeInformational, message.GetAlertType())
 }
+
+// TestAlertMessage_GetMissingSequences will test the method GetMissingSequences()
+func (ts *TestSuite) TestAlertMessage_GetMissingSequences() {
+	ts.T().Run("success - no alerts", func(t *testing.T) {
+		missing, err := GetMissingSequences(context.Background(), 10, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		assert.Empty(t, missing)
+	})
+
+	ts.T().Run("success - gaps between saved alerts", func(t *testing.T) {
+		for _, seq := range []uint32{1, 4} {
+			message := NewAlertMessage(model.WithAllDependencies(ts.Dependencies), model.New())
+			message.Hash = testAlertHash
+			message.Raw = testAlertRaw
+			message.SequenceNumber = seq
+			require.NoError(t, message.Save(context.Background()))
+		}
+
+		missing, err := GetMissingSequences(context.Background(), 10, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		assert.Equal(t, []uint32{2, 3}, missing)
+
+		missing, err = GetMissingSequences(context.Background(), 1, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		assert.Equal(t, []uint32{2}, missing)
+
+		count, err := CountAlertsInRange(context.Background(), 1, 4, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	ts.T().Run("success - gaps filled", func(t *testing.T) {
+		for _, seq := range []uint32{2, 3} {
+			message := NewAlertMessage(model.WithAllDependencies(ts.Dependencies), model.New())
+			message.Hash = testAlertHash
+			message.Raw = testAlertRaw
+			message.SequenceNumber = seq
+			require.NoError(t, message.Save(context.Background()))
+		}
+
+		missing, err := GetMissingSequences(context.Background(), 10, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		assert.Empty(t, missing)
+	})
+
+	ts.T().Run("success - gaps in both halves of the range", func(t *testing.T) {
+		for _, seq := range []uint32{7, 8, 10} {
+			message := NewAlertMessage(model.WithAllDependencies(ts.Dependencies), model.New())
+			message.Hash = testAlertHash
+			message.Raw = testAlertRaw
+			message.SequenceNumber = seq
+			require.NoError(t, message.Save(context.Background()))
+		}
+
+		missing, err := GetMissingSequences(context.Background(), 10, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		assert.Equal(t, []uint32{5, 6, 9}, missing)
+
+		missing, err = GetMissingSequences(context.Background(), 2, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		assert.Equal(t, []uint32{5, 6}, missing)
+	})
+}
+
+// TestAlertMessage_PropagationDelay will test the method PropagationDelay()
+func TestAlertMessage_PropagationDelay(t *testing.T) {
+	t.Parallel()
+
+	t.Run("not received over gossip", func(t *testing.T) {
+		message := NewAlertMessage()
+		message.SetTimestamp(uint64(time.Now().Unix()))
+		_, ok := message.PropagationDelay()
+		assert.False(t, ok)
+	})
+
+	t.Run("no timestamp", func(t *testing.T) {
+		message := NewAlertMessage()
+		message.SetReceivedAt(time.Now())
+		_, ok := message.PropagationDelay()
+		assert.False(t, ok)
+	})
+
+	t.Run("delay since the timestamp", func(t *testing.T) {
+		message := NewAlertMessage()
+		receivedAt := time.Now()
+		message.SetTimestamp(uint64(receivedAt.Add(-90 * time.Second).Unix()))
+		message.SetReceivedAt(receivedAt)
+		delay, ok := message.PropagationDelay()
+		require.True(t, ok)
+		assert.InDelta(t, 90, delay.Seconds(), 1)
+	})
+
+	t.Run("timestamp in the future (clock skew)", func(t *testing.T) {
+		message := NewAlertMessage()
+		receivedAt := time.Now()
+		message.SetTimestamp(uint64(receivedAt.Add(time.Hour).Unix()))
+		message.SetReceivedAt(receivedAt)
+		delay, ok := message.PropagationDelay()
+		require.True(t, ok)
+		assert.Equal(t, time.Duration(0), delay)
+	})
+}
+
+// TestAlertMessage_signatureCacheKey will test the method signatureCacheKey()
+func TestAlertMessage_signatureCacheKey(t *testing.T) {
+	t.Parallel()
+
+	newMessage := func(sequence uint32, sigs ...[]byte) *AlertMessage {
+		message := NewAlertMessage()
+		message.SequenceNumber = sequence
+		message.SerializeData()
+		message.SetSignatures(sigs)
+		return message
+	}
+	keys := []*PublicKey{{Key: "key-a"}, {Key: "key-b"}}
+	key := newMessage(1, []byte("sig-a")).signatureCacheKey(keys)
+
+	assert.Equal(t, key, newMessage(1, []byte("sig-a")).signatureCacheKey(keys), "same alert, signatures and keys")
+	assert.NotEqual(t, key, newMessage(2, []byte("sig-a")).signatureCacheKey(keys), "another alert")
+	assert.NotEqual(t, key, newMessage(1, []byte("sig-b")).signatureCacheKey(keys), "other signatures")
+	assert.NotEqual(t, key, newMessage(1, []byte("sig-a")).signatureCacheKey(keys[:1]), "other keys")
+}
@@ -61,7 +61,7 @@ func (a *AlertMessageUnbanPeer) Read(alert []byte) error {
 
 // Do executes the alert
 func (a *AlertMessageUnbanPeer) Do(ctx context.Context) error {
-	return a.Config().Services.Node.UnbanPeer(ctx, string(a.Peer))
+	return a.Config().Services.Node.UnbanPeer(a.nodeContext(ctx), string(a.Peer))
 }
 
 // ToJSON is the alert in JSON format
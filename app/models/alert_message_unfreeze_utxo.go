@@ -62,7 +62,7 @@ func (a *AlertMessageUnfreezeUtxo) Read(raw []byte) error {
 
 // Do executes the message
 func (a *AlertMessageUnfreezeUtxo) Do(ctx context.Context) error {
-	_, err := a.Config().Services.Node.AddToConsensusBlacklist(ctx, a.Funds)
+	_, err := a.Config().Services.Node.AddToConsensusBlacklist(a.nodeContext(ctx), a.Funds)
 	if err != nil {
 		return err
 	}
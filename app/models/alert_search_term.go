@@ -0,0 +1,356 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"net"
+	"strings"
+	"unicode"
+
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/libsv/go-bt/v2/chainhash"
+	"github.com/libsv/go-p2p/wire"
+	"github.com/mrz1836/go-datastore"
+)
+
+// Searchable fields decoded from the alert payloads
+const (
+	SearchFieldBlockHash = "block_hash" // Invalidated block hash
+	SearchFieldPeer      = "peer"       // Banned or unbanned peer (as given, and the host without the port)
+	SearchFieldTxID      = "txid"       // Frozen, unfrozen or confiscated txid (and the inputs spent by a confiscation tx)
+	SearchFieldWord      = "word"       // Word in an informational message or ban/invalidation reason (lowercase)
+)
+
+// SearchFields is the list of all searchable fields
+var SearchFields = []string{
+	SearchFieldBlockHash,
+	SearchFieldPeer,
+	SearchFieldTxID,
+	SearchFieldWord,
+}
+
+// Limits for extracting words from free text
+const (
+	maxSearchWords      = 100 // Max words indexed per alert
+	minSearchWordLength = 3   // Shorter words are not indexed
+)
+
+// AlertSearchTerm is an object representing a searchable value decoded from an alert payload
+type AlertSearchTerm struct {
+	// Base model
+	model.Model `bson:",inline"`
+
+	// Model specific fields
+	ID             uint64 `json:"id" toml:"id" yaml:"id" bson:"_id" gorm:"primaryKey;comment:This is a unique identifier"`
+	AlertType      uint32 `json:"alert_type" toml:"alert_type" yaml:"alert_type" bson:"alert_type" gorm:"<-;type:int8;index;comment:This is the alert type"`
+	Field          string `json:"field" toml:"field" yaml:"field" bson:"field" gorm:"<-;type:varchar(32);index:idx_alert_search_terms_field_value,priority:1;comment:This is the searchable field"`
+	SequenceNumber uint32 `json:"sequence_number" toml:"sequence_number" yaml:"sequence_number" bson:"sequence_number" gorm:"<-;type:int8;index;comment:This is the alert sequence number"`
+	Value          string `json:"value" toml:"value" yaml:"value" bson:"value" gorm:"<-;type:varchar(255);index:idx_alert_search_terms_field_value,priority:2;comment:This is the searchable value"`
+}
+
+// NewAlertSearchTerm creates a new alert search term
+func NewAlertSearchTerm(opts ...model.Options) *AlertSearchTerm {
+	return &AlertSearchTerm{
+		Model: *model.NewBaseModel(model.NameAlertSearchTerm, opts...),
+	}
+}
+
+// Name will get the name of the model
+func (m *AlertSearchTerm) Name() string {
+	return model.NameAlertSearchTerm.String()
+}
+
+// GetTableName will get the database table name of the model
+func (m *AlertSearchTerm) GetTableName() string {
+	return model.TableAlertSearchTerms
+}
+
+// GetID will get the model ID
+func (m *AlertSearchTerm) GetID() uint64 {
+	return m.ID
+}
+
+// Display filter the model for display
+func (m *AlertSearchTerm) Display() interface{} {
+	return m
+}
+
+// Migrate will run model specific migrations on startup
+func (m *AlertSearchTerm) Migrate(client datastore.ClientInterface) error {
+	return client.IndexMetadata(client.GetTableName(model.TableAlertSearchTerms), model.MetadataField)
+}
+
+// BeginSaveWithTx will start saving the model into the Datastore with the provided transaction
+func (m *AlertSearchTerm) BeginSaveWithTx(ctx context.Context, tx *datastore.Transaction) ([]model.BaseInterface, error) {
+	return model.BeginSaveWithTx(ctx, tx, m)
+}
+
+// Save will save the model into the Datastore
+func (m *AlertSearchTerm) Save(ctx context.Context) error {
+	return model.Save(ctx, m)
+}
+
+// IsValidSearchField will return true if the field is searchable
+func IsValidSearchField(field string) bool {
+	for _, f := range SearchFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeSearchValue will normalize the value for the field (as it is stored in the index)
+func NormalizeSearchValue(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// searchTerms will decode the alert payload and return the searchable terms (unique per field and value)
+func (m *AlertMessage) searchTerms() []*AlertSearchTerm {
+	if len(m.GetRawMessage()) == 0 {
+		return nil
+	}
+	alert := m.ProcessAlertMessage()
+	if alert == nil || alert.Read(m.GetRawMessage()) != nil {
+		return nil
+	}
+
+	// Decode the searchable values from the payload
+	values := make(map[string][]string)
+	switch a := alert.(type) {
+	case *AlertMessageFreezeUtxo:
+		for _, fund := range a.Funds {
+			values[SearchFieldTxID] = append(values[SearchFieldTxID], fund.TxOut.TxId)
+		}
+	case *AlertMessageUnfreezeUtxo:
+		for _, fund := range a.Funds {
+			values[SearchFieldTxID] = append(values[SearchFieldTxID], fund.TxOut.TxId)
+		}
+	case *AlertMessageConfiscateTransaction:
+		for _, detail := range a.Transactions {
+			values[SearchFieldTxID] = append(values[SearchFieldTxID], confiscationTxIDs(detail.ConfiscationTransaction.Hex)...)
+		}
+	case *AlertMessageBanPeer:
+		values[SearchFieldPeer] = peerSearchValues(string(a.Peer))
+		values[SearchFieldWord] = searchWords(string(a.Reason))
+	case *AlertMessageUnbanPeer:
+		values[SearchFieldPeer] = peerSearchValues(string(a.Peer))
+		values[SearchFieldWord] = searchWords(string(a.Reason))
+	case *AlertMessageInvalidateBlock:
+		if a.BlockHash != nil {
+			values[SearchFieldBlockHash] = []string{a.BlockHash.String()}
+		}
+		values[SearchFieldWord] = searchWords(string(a.Reason))
+	case *AlertMessageInformational:
+		values[SearchFieldWord] = searchWords(string(a.Message))
+	}
+
+	// Create the terms (skipping duplicates)
+	terms := make([]*AlertSearchTerm, 0)
+	seen := make(map[string]bool)
+	for _, field := range SearchFields {
+		for _, value := range values[field] {
+			value = NormalizeSearchValue(value)
+			if len(value) == 0 || seen[field+":"+value] {
+				continue
+			}
+			seen[field+":"+value] = true
+			term := NewAlertSearchTerm(m.GetOptions(true)...)
+			term.AlertType = uint32(m.GetAlertType())
+			term.Field = field
+			term.SequenceNumber = m.SequenceNumber
+			term.Value = value
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// confiscationTxIDs will return the txid of the confiscation tx and the txids of the inputs it spends
+func confiscationTxIDs(txHex string) []string {
+	raw, err := hex.DecodeString(txHex)
+	if err != nil || len(raw) < 4 {
+		return nil
+	}
+	txIDs := []string{chainhash.DoubleHashH(raw).String()}
+
+	// Read the inputs (version, input count, then the outpoint, script and sequence of each input)
+	buf := bytes.NewReader(raw[4:])
+	count, err := wire.ReadVarInt(buf, 0)
+	if err != nil {
+		return txIDs
+	}
+	for i := uint64(0); i < count; i++ {
+		var prevTxID chainhash.Hash
+		if _, err = io.ReadFull(buf, prevTxID[:]); err != nil {
+			return txIDs
+		}
+		var scriptLength uint64
+		if _, err = buf.Seek(4, io.SeekCurrent); err != nil { // Skip the output index
+			return txIDs
+		} else if scriptLength, err = wire.ReadVarInt(buf, 0); err != nil {
+			return txIDs
+		} else if _, err = buf.Seek(int64(scriptLength)+4, io.SeekCurrent); err != nil { // Skip the script and sequence
+			return txIDs
+		}
+		txIDs = append(txIDs, prevTxID.String())
+	}
+	return txIDs
+}
+
+// peerSearchValues will return the peer as given and the host without the port (if any)
+func peerSearchValues(peer string) []string {
+	values := []string{peer}
+	if host, _, err := net.SplitHostPort(peer); err == nil {
+		values = append(values, host)
+	}
+	return values
+}
+
+// searchWords will split the text into lowercase words (letters and digits only)
+func searchWords(text string) []string {
+	words := make([]string, 0)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(word) < minSearchWordLength {
+			continue
+		}
+		words = append(words, word)
+		if len(words) == maxSearchWords {
+			break
+		}
+	}
+	return words
+}
+
+// IndexAlertHistory will index the searchable terms of any saved alerts that have not been indexed
+// (alerts saved before search was added), new alerts are indexed when they are saved
+func IndexAlertHistory(ctx context.Context, opts ...model.Options) error {
+
+	// Get all alerts
+	alerts, err := GetAllAlerts(ctx, nil, opts...)
+	if err != nil {
+		return err
+	}
+
+	// Index any alerts without terms
+	for _, alert := range alerts {
+		var indexed bool
+		if indexed, err = isAlertIndexed(ctx, alert.SequenceNumber, opts...); err != nil {
+			return err
+		} else if indexed {
+			continue
+		}
+		if err = alert.ReadRaw(); err != nil {
+			continue // Invalid alerts have nothing to index
+		}
+		for _, term := range alert.searchTerms() {
+			term.New()
+			if err = term.Save(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isAlertIndexed will return true if there are search terms for the alert
+func isAlertIndexed(ctx context.Context, sequenceNumber uint32, opts ...model.Options) (bool, error) {
+	conditions := &map[string]interface{}{
+		utils.FieldSequenceNumber: sequenceNumber,
+	}
+	queryParams := &datastore.QueryParams{
+		Page:     1,
+		PageSize: 1,
+	}
+	modelItems := make([]*AlertSearchTerm, 0)
+	if err := model.GetModelsByConditions(
+		ctx, model.NameAlertSearchTerm, &modelItems, nil, conditions, queryParams, opts...,
+	); err != nil {
+		return false, err
+	}
+	return len(modelItems) > 0, nil
+}
+
+// SearchAlerts will get a page of alerts with a term matching the field and value (ordered by sequence number)
+// Filters by alert type if the type is not zero, the next cursor is nil if there are no more alerts
+func SearchAlerts(ctx context.Context, field, value string, alertType AlertType, cursor *utils.Cursor,
+	limit int, opts ...model.Options) ([]*AlertMessage, *utils.Cursor, error) {
+
+	// Set the conditions
+	conditions := &map[string]interface{}{
+		utils.FieldSearchField: field,
+		utils.FieldSearchValue: NormalizeSearchValue(value),
+		utils.FieldDeletedAt: map[string]interface{}{ // IS NULL
+			utils.ExistsCondition: false,
+		},
+	}
+	if alertType > 0 {
+		(*conditions)[utils.FieldAlertType] = uint32(alertType)
+	}
+	if cursor != nil {
+		(*conditions)[utils.FieldSequenceNumber] = map[string]interface{}{
+			utils.GreaterThanCondition: cursor.Sequence,
+		}
+	}
+
+	// Set the query params (one extra record to detect the next page)
+	limit = utils.PageSize(limit)
+	queryParams := &datastore.QueryParams{
+		Page:          1,
+		PageSize:      limit + 1,
+		OrderByField:  utils.FieldSequenceNumber,
+		SortDirection: utils.SortAscending,
+	}
+
+	// Get the matching terms
+	terms := make([]*AlertSearchTerm, 0)
+	if err := model.GetModelsByConditions(
+		ctx, model.NameAlertSearchTerm, &terms, nil, conditions, queryParams, opts...,
+	); err != nil {
+		return nil, nil, err
+	} else if len(terms) == 0 {
+		return []*AlertMessage{}, nil, nil
+	}
+	hasMore := len(terms) > limit
+	if hasMore {
+		terms = terms[:limit]
+	}
+
+	// Get the alerts for the terms
+	sequenceNumbers := make([]uint32, 0, len(terms))
+	for _, term := range terms {
+		sequenceNumbers = append(sequenceNumbers, term.SequenceNumber)
+	}
+	alertConditions := &map[string]interface{}{
+		utils.FieldSequenceNumber: map[string]interface{}{
+			utils.InCondition: sequenceNumbers,
+		},
+		utils.FieldDeletedAt: map[string]interface{}{ // IS NULL
+			utils.ExistsCondition: false,
+		},
+	}
+	alerts := make([]*AlertMessage, 0, len(terms))
+	if err := model.GetModelsByConditions(
+		ctx, model.NameAlertMessage, &alerts, nil, alertConditions, &datastore.QueryParams{
+			OrderByField:  utils.FieldSequenceNumber,
+			SortDirection: utils.SortAscending,
+		}, opts...,
+	); err != nil {
+		return nil, nil, err
+	}
+	if !hasMore {
+		return alerts, nil, nil
+	}
+
+	// Set the cursor to the last matching term in the page
+	last := terms[len(terms)-1]
+	return alerts, &utils.Cursor{
+		Sequence:  uint64(last.SequenceNumber),
+		Timestamp: last.CreatedAt.Unix(),
+	}, nil
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/libsv/go-bt/v2/chainhash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSearchWords will test the method searchWords()
+func TestSearchWords(t *testing.T) {
+	assert.Equal(t, []string{"node", "operators", "upgrade", "version"}, searchWords("Node operators: upgrade to version 1.2!"))
+	assert.Empty(t, searchWords(""))
+	assert.Empty(t, searchWords("a b c"))
+}
+
+// TestPeerSearchValues will test the method peerSearchValues()
+func TestPeerSearchValues(t *testing.T) {
+	assert.Equal(t, []string{"127.0.0.1"}, peerSearchValues("127.0.0.1"))
+	assert.Equal(t, []string{"127.0.0.1:8333", "127.0.0.1"}, peerSearchValues("127.0.0.1:8333"))
+}
+
+// TestConfiscationTxIDs will test the method confiscationTxIDs()
+func TestConfiscationTxIDs(t *testing.T) {
+	t.Run("txid and spent inputs", func(t *testing.T) {
+		prevTxID := make([]byte, 32)
+		for i := range prevTxID {
+			prevTxID[i] = byte(i)
+		}
+
+		// version, 1 input (outpoint, empty script, sequence), 0 outputs, lock time
+		raw := []byte{0x01, 0x00, 0x00, 0x00, 0x01}
+		raw = append(raw, prevTxID...)
+		raw = append(raw, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00, 0x00)
+
+		prevHash, err := chainhash.NewHash(prevTxID)
+		require.NoError(t, err)
+
+		txIDs := confiscationTxIDs(hex.EncodeToString(raw))
+		require.Len(t, txIDs, 2)
+		assert.Equal(t, chainhash.DoubleHashH(raw).String(), txIDs[0])
+		assert.Equal(t, prevHash.String(), txIDs[1])
+	})
+
+	t.Run("invalid hex", func(t *testing.T) {
+		assert.Empty(t, confiscationTxIDs("not-hex"))
+	})
+}
+
+// TestSearchAlerts will test the method SearchAlerts()
+func (ts *TestSuite) TestSearchAlerts() {
+	ts.T().Run("success - informational alert is searchable by word", func(t *testing.T) {
+		message := "Scheduled maintenance window"
+		alert := NewAlertMessage(model.WithAllDependencies(ts.Dependencies), model.New())
+		alert.SetAlertType(AlertTypeInformational)
+		alert.SequenceNumber = 9000
+		alert.SetRawMessage(append([]byte{byte(len(message))}, message...))
+		require.NoError(t, alert.Save(context.Background()))
+
+		alerts, next, err := SearchAlerts(
+			context.Background(), SearchFieldWord, "Maintenance", 0, nil, 10, model.WithAllDependencies(ts.Dependencies),
+		)
+		require.NoError(t, err)
+		assert.Nil(t, next)
+		require.Len(t, alerts, 1)
+		assert.Equal(t, uint32(9000), alerts[0].SequenceNumber)
+	})
+
+	ts.T().Run("success - no matches", func(t *testing.T) {
+		alerts, next, err := SearchAlerts(
+			context.Background(), SearchFieldTxID, "unknown", 0, nil, 10, model.WithAllDependencies(ts.Dependencies),
+		)
+		require.NoError(t, err)
+		assert.Nil(t, next)
+		assert.Empty(t, alerts)
+	})
+}
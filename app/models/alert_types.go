@@ -26,6 +26,16 @@ func (a AlertType) Name() string {
 	return ""
 }
 
+// IsConsensusCritical returns true if the alert type changes what the node accepts (blocks, UTXOs or the keys
+// signing the next alerts), these alerts are processed before the informational and peer ones
+func (a AlertType) IsConsensusCritical() bool {
+	switch a {
+	case AlertTypeFreezeUtxo, AlertTypeUnfreezeUtxo, AlertTypeConfiscateUtxo, AlertTypeInvalidateBlock, AlertTypeSetKeys:
+		return true
+	}
+	return false
+}
+
 // AlertTypeInformational an alert type for informational alerts
 const AlertTypeInformational AlertType = 0x01
 
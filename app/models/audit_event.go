@@ -0,0 +1,178 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/audit"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/mrz1836/go-datastore"
+)
+
+// AuditEvent is an object representing an audit log entry (the ID is the sequence in the log)
+type AuditEvent struct {
+	// Base model
+	model.Model `bson:",inline"`
+
+	// Model specific fields
+	ID        uint64 `json:"id" toml:"id" yaml:"id" bson:"_id" gorm:"primaryKey;autoIncrement:false;comment:This is the sequence in the audit log"`
+	Actor     string `json:"actor" toml:"actor" yaml:"actor" bson:"actor" gorm:"<-:create;type:varchar(255);comment:This is who caused the event"`
+	Details   string `json:"details" toml:"details" yaml:"details" bson:"details" gorm:"<-:create;type:text;comment:This is the JSON encoded event details"`
+	EventType string `json:"event_type" toml:"event_type" yaml:"event_type" bson:"event_type" gorm:"<-:create;type:varchar(64);index;comment:This is the event type"`
+	Hash      string `json:"hash" toml:"hash" yaml:"hash" bson:"hash" gorm:"<-:create;type:char(64);comment:This is the hash of the entry"`
+	PrevHash  string `json:"prev_hash" toml:"prev_hash" yaml:"prev_hash" bson:"prev_hash" gorm:"<-:create;type:char(64);comment:This is the hash of the previous entry"`
+	Subject   string `json:"subject" toml:"subject" yaml:"subject" bson:"subject" gorm:"<-:create;type:text;comment:This is what the event is about"`
+	Timestamp string `json:"timestamp" toml:"timestamp" yaml:"timestamp" bson:"timestamp" gorm:"<-:create;type:varchar(64);comment:This is the hashed event time (RFC3339)"`
+}
+
+// NewAuditEvent creates a new audit event
+func NewAuditEvent(opts ...model.Options) *AuditEvent {
+	return &AuditEvent{
+		Model: *model.NewBaseModel(model.NameAuditEvent, opts...),
+	}
+}
+
+// Name will get the name of the model
+func (m *AuditEvent) Name() string {
+	return model.NameAuditEvent.String()
+}
+
+// GetTableName will get the database table name of the model
+func (m *AuditEvent) GetTableName() string {
+	return model.TableAuditEvents
+}
+
+// GetID will get the model ID
+func (m *AuditEvent) GetID() uint64 {
+	return m.ID
+}
+
+// Display filter the model for display
+func (m *AuditEvent) Display() interface{} {
+	return m
+}
+
+// Migrate will run model specific migrations on startup
+func (m *AuditEvent) Migrate(client datastore.ClientInterface) error {
+	return client.IndexMetadata(client.GetTableName(model.TableAuditEvents), model.MetadataField)
+}
+
+// BeginSaveWithTx will start saving the model into the Datastore with the provided transaction
+func (m *AuditEvent) BeginSaveWithTx(ctx context.Context, tx *datastore.Transaction) ([]model.BaseInterface, error) {
+	return model.BeginSaveWithTx(ctx, tx, m)
+}
+
+// Save will save the model into the Datastore
+func (m *AuditEvent) Save(ctx context.Context) error {
+	return model.Save(ctx, m)
+}
+
+// Entry will convert the audit event to the audit log entry
+func (m *AuditEvent) Entry() (*audit.Entry, error) {
+	entry := &audit.Entry{
+		Actor:    m.Actor,
+		Hash:     m.Hash,
+		PrevHash: m.PrevHash,
+		Sequence: m.ID,
+		Subject:  m.Subject,
+		Type:     m.EventType,
+	}
+	if len(m.Details) > 0 {
+		if err := json.Unmarshal([]byte(m.Details), &entry.Details); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	if entry.Time, err = time.Parse(time.RFC3339Nano, m.Timestamp); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// AuditStore is the audit log store for the datastore (the audit_events table)
+type AuditStore struct {
+	opts []model.Options
+}
+
+// NewAuditStore will create the audit log store for the datastore
+func NewAuditStore(opts ...model.Options) *AuditStore {
+	return &AuditStore{opts: opts}
+}
+
+// Append will save the entry as a new audit event
+func (s *AuditStore) Append(ctx context.Context, entry *audit.Entry) error {
+	event := NewAuditEvent(append(s.opts, model.New())...)
+	event.ID = entry.Sequence
+	event.Actor = entry.Actor
+	event.EventType = entry.Type
+	event.Hash = entry.Hash
+	event.PrevHash = entry.PrevHash
+	event.Subject = entry.Subject
+	event.Timestamp = entry.Time.Format(time.RFC3339Nano)
+	if len(entry.Details) > 0 {
+		details, err := json.Marshal(entry.Details)
+		if err != nil {
+			return err
+		}
+		event.Details = string(details)
+	}
+	return event.Save(ctx)
+}
+
+// Entries will get all the audit events (in order)
+func (s *AuditStore) Entries(ctx context.Context) ([]*audit.Entry, error) {
+	return s.getEntries(ctx, &datastore.QueryParams{
+		OrderByField:  utils.FieldID,
+		SortDirection: utils.SortAscending,
+	}, nil)
+}
+
+// Last will get the last audit event (nil if there are no events)
+func (s *AuditStore) Last(ctx context.Context) (*audit.Entry, error) {
+	entries, err := s.getEntries(ctx, &datastore.QueryParams{
+		Page:          1,
+		PageSize:      1,
+		OrderByField:  utils.FieldID,
+		SortDirection: utils.SortDescending,
+	}, nil)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return entries[0], nil
+}
+
+// Page will get up to limit audit events after the sequence (in order)
+func (s *AuditStore) Page(ctx context.Context, after uint64, limit int) ([]*audit.Entry, error) {
+	return s.getEntries(ctx, &datastore.QueryParams{
+		Page:          1,
+		PageSize:      limit,
+		OrderByField:  utils.FieldID,
+		SortDirection: utils.SortAscending,
+	}, &map[string]interface{}{
+		utils.FieldID: map[string]interface{}{
+			utils.GreaterThanCondition: after,
+		},
+	})
+}
+
+// getEntries will get the audit events and convert them to entries
+func (s *AuditStore) getEntries(ctx context.Context, queryParams *datastore.QueryParams,
+	conditions *map[string]interface{}) ([]*audit.Entry, error) {
+	modelItems := make([]*AuditEvent, 0)
+	if err := model.GetModelsByConditions(
+		ctx, model.NameAuditEvent, &modelItems, nil, conditions, queryParams, s.opts...,
+	); err != nil {
+		return nil, err
+	}
+	entries := make([]*audit.Entry, 0, len(modelItems))
+	for _, item := range modelItems {
+		entry, err := item.Entry()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/audit"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAuditStore will test the audit log in the datastore
+func (ts *TestSuite) TestAuditStore() {
+	ts.T().Run("success - no options, base model", func(t *testing.T) {
+		event := NewAuditEvent()
+		require.NotNil(t, event)
+		assert.Equal(t, uint64(0), event.GetID())
+		assert.Equal(t, model.NameAuditEvent.String(), event.Name())
+		assert.Equal(t, model.TableAuditEvents, event.GetTableName())
+	})
+
+	ts.T().Run("success - record, reload and verify", func(t *testing.T) {
+		ctx := context.Background()
+		store := NewAuditStore(model.WithAllDependencies(ts.Dependencies))
+
+		l, err := audit.New(ctx, store)
+		require.NoError(t, err)
+		require.NoError(t, l.Record(ctx, audit.EventKeyLoaded, audit.ActorSystem, "key", map[string]string{"generated": "true"}))
+		require.NoError(t, l.Record(ctx, audit.EventAlertEnforced, audit.ActorNetwork, "3", nil))
+
+		// Continue the chain from the datastore
+		l, err = audit.New(ctx, store)
+		require.NoError(t, err)
+		require.NoError(t, l.Record(ctx, audit.EventAdminRequest, "127.0.0.1", "/v1/admin/sync", nil))
+
+		var entries []*audit.Entry
+		entries, err = store.Entries(ctx)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		assert.Equal(t, "true", entries[0].Details["generated"])
+		assert.Equal(t, entries[1].Hash, entries[2].PrevHash)
+
+		var count int
+		count, err = l.Verify(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+
+		// Read the entries page by page
+		entries, err = store.Page(ctx, 0, 2)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, uint64(1), entries[0].Sequence)
+		entries, err = store.Page(ctx, entries[1].Sequence, 2)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, uint64(3), entries[0].Sequence)
+	})
+}
@@ -0,0 +1,153 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/mrz1836/go-datastore"
+)
+
+// ClusterLease is an object representing the leader lease of a cluster (the instances sharing the datastore)
+// The holder renews the lease before it expires, another instance takes it over once it expired
+type ClusterLease struct {
+	// Base model
+	model.Model `bson:",inline"`
+
+	// Model specific fields
+	ID        uint64    `json:"id" toml:"id" yaml:"id" bson:"_id" gorm:"primaryKey;comment:This is a unique identifier"`
+	Cluster   string    `json:"cluster" toml:"cluster" yaml:"cluster" bson:"cluster" gorm:"<-;type:varchar(64);uniqueIndex;comment:This is the cluster name"`
+	Holder    string    `json:"holder" toml:"holder" yaml:"holder" bson:"holder" gorm:"<-;type:varchar(255);comment:This is the instance holding the lease (the leader)"`
+	Term      int64     `json:"term" toml:"term" yaml:"term" bson:"term" gorm:"<-;comment:This is the leader term (incremented each time another instance takes the lease)"`
+	ExpiresAt time.Time `json:"expires_at" toml:"expires_at" yaml:"expires_at" bson:"expires_at" gorm:"<-;comment:The time the lease expires if it is not renewed"`
+}
+
+// NewClusterLease creates a new cluster lease
+func NewClusterLease(opts ...model.Options) *ClusterLease {
+	return &ClusterLease{
+		Model: *model.NewBaseModel(model.NameClusterLease, opts...),
+	}
+}
+
+// Name will get the name of the model
+func (m *ClusterLease) Name() string {
+	return model.NameClusterLease.String()
+}
+
+// GetTableName will get the database table name of the model
+func (m *ClusterLease) GetTableName() string {
+	return model.TableClusterLeases
+}
+
+// GetID will get the model ID
+func (m *ClusterLease) GetID() uint64 {
+	return m.ID
+}
+
+// Display filter the model for display
+func (m *ClusterLease) Display() interface{} {
+	return m
+}
+
+// Migrate will run model specific migrations on startup
+func (m *ClusterLease) Migrate(client datastore.ClientInterface) error {
+	return client.IndexMetadata(client.GetTableName(model.TableClusterLeases), model.MetadataField)
+}
+
+// BeginSaveWithTx will start saving the model into the Datastore with the provided transaction
+func (m *ClusterLease) BeginSaveWithTx(ctx context.Context, tx *datastore.Transaction) ([]model.BaseInterface, error) {
+	return model.BeginSaveWithTx(ctx, tx, m)
+}
+
+// Save will save the model into the Datastore
+func (m *ClusterLease) Save(ctx context.Context) error {
+	return model.Save(ctx, m)
+}
+
+// IsHeldBy will return true if the lease is held by the instance and not expired
+func (m *ClusterLease) IsHeldBy(holder string) bool {
+	return m.Holder == holder && m.ExpiresAt.After(m.Now())
+}
+
+// AcquireClusterLease will renew the lease of the cluster if the holder has it, take it over if it expired
+// (incrementing the term) or create it, then return the lease (held by another instance if not acquired)
+// The update is a single conditional statement, only one instance can take over an expired lease
+func AcquireClusterLease(ctx context.Context, name, holder string, duration time.Duration,
+	opts ...model.Options) (*ClusterLease, error) {
+
+	// Renew or take over the lease
+	lease := NewClusterLease(opts...)
+	now := lease.Now()
+	ds := lease.Datastore()
+	if ds == nil {
+		return nil, model.ErrMissingDatastore
+	}
+	table := ds.GetTableName(model.TableClusterLeases)
+	if err := ds.Raw("").Exec(
+		"UPDATE "+table+" SET "+
+			utils.FieldTerm+" = CASE WHEN "+utils.FieldHolder+" = ? THEN "+utils.FieldTerm+" ELSE "+utils.FieldTerm+" + 1 END, "+
+			utils.FieldHolder+" = ?, "+utils.FieldExpiresAt+" = ?, "+utils.FieldUpdatedAt+" = ? "+
+			"WHERE "+utils.FieldCluster+" = ? AND ("+utils.FieldHolder+" = ? OR "+utils.FieldExpiresAt+" < ?)",
+		holder, holder, now.Add(duration), now, name, holder, now,
+	).Error; err != nil {
+		return nil, err
+	}
+
+	// Get the lease (created on the first election, another instance may have created it first)
+	current, err := GetClusterLease(ctx, name, opts...)
+	if err != nil {
+		return nil, err
+	} else if current != nil {
+		return current, nil
+	}
+	lease.Cluster = name
+	lease.Holder = holder
+	lease.Term = 1
+	lease.ExpiresAt = now.Add(duration)
+	if err = lease.Save(ctx); err != nil {
+		if current, _ = GetClusterLease(ctx, name, opts...); current != nil {
+			return current, nil
+		}
+		return nil, err
+	}
+	return lease, nil
+}
+
+// ReleaseClusterLease will expire the lease of the cluster if the holder has it (a standby takes it over at its next
+// renewal instead of waiting for the lease to expire)
+func ReleaseClusterLease(_ context.Context, name, holder string, opts ...model.Options) error {
+	lease := NewClusterLease(opts...)
+	ds := lease.Datastore()
+	if ds == nil {
+		return model.ErrMissingDatastore
+	}
+	now := lease.Now()
+	return ds.Raw("").Exec(
+		"UPDATE "+ds.GetTableName(model.TableClusterLeases)+" SET "+
+			utils.FieldExpiresAt+" = ?, "+utils.FieldUpdatedAt+" = ? "+
+			"WHERE "+utils.FieldCluster+" = ? AND "+utils.FieldHolder+" = ?",
+		now, now, name, holder,
+	).Error
+}
+
+// GetClusterLease will get the lease of the cluster (if found)
+func GetClusterLease(ctx context.Context, name string, opts ...model.Options) (*ClusterLease, error) {
+
+	// Get the record (from the write database, a replica may lag behind the renewals)
+	lease := NewClusterLease(opts...)
+	conditions := map[string]interface{}{
+		utils.FieldCluster: name,
+	}
+	if err := model.Get(
+		ctx, lease, conditions, model.DefaultDatabaseReadTimeout, true,
+	); err != nil {
+		if errors.Is(err, datastore.ErrNoResults) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return lease, nil
+}
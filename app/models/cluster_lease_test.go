@@ -0,0 +1,61 @@
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/clock"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClusterLease will test the cluster leases
+func (ts *TestSuite) TestClusterLease() {
+	ts.T().Run("success - no options, base model", func(t *testing.T) {
+		lease := NewClusterLease()
+		require.NotNil(t, lease)
+		assert.NotNil(t, lease.Logger())
+		assert.Equal(t, uint64(0), lease.GetID())
+		assert.Equal(t, model.NameClusterLease.String(), lease.Name())
+		assert.Equal(t, model.TableClusterLeases, lease.GetTableName())
+	})
+
+	ts.T().Run("success - held by one instance, taken over once released", func(t *testing.T) {
+		ctx := context.Background()
+		now := clock.NewMock(time.Now())
+		ts.Dependencies.Services.Clock = now
+		opts := model.WithAllDependencies(ts.Dependencies)
+
+		lease, err := AcquireClusterLease(ctx, "test", "node-a", time.Minute, opts)
+		require.NoError(t, err)
+		assert.True(t, lease.IsHeldBy("node-a"))
+		assert.Equal(t, int64(1), lease.Term)
+
+		// The other instance stays a standby while the lease is renewed
+		lease, err = AcquireClusterLease(ctx, "test", "node-b", time.Minute, opts)
+		require.NoError(t, err)
+		assert.False(t, lease.IsHeldBy("node-b"))
+		assert.Equal(t, "node-a", lease.Holder)
+		lease, err = AcquireClusterLease(ctx, "test", "node-a", time.Minute, opts)
+		require.NoError(t, err)
+		assert.True(t, lease.IsHeldBy("node-a"))
+		assert.Equal(t, int64(1), lease.Term)
+
+		// Released by the leader (e.g. at shutdown), the standby takes it over in a new term
+		require.NoError(t, ReleaseClusterLease(ctx, "test", "node-b", opts), "not the holder, nothing is released")
+		require.NoError(t, ReleaseClusterLease(ctx, "test", "node-a", opts))
+		now.Advance(time.Millisecond)
+		lease, err = AcquireClusterLease(ctx, "test", "node-b", time.Minute, opts)
+		require.NoError(t, err)
+		assert.True(t, lease.IsHeldBy("node-b"))
+		assert.Equal(t, int64(2), lease.Term)
+	})
+
+	ts.T().Run("not found", func(t *testing.T) {
+		lease, err := GetClusterLease(context.Background(), "missing", model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		assert.Nil(t, lease)
+	})
+}
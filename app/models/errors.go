@@ -0,0 +1,12 @@
+package models
+
+import "errors"
+
+// Errors for the models package
+var (
+	ErrDropUnsupported     = errors.New("dropping the tables is not supported for this datastore engine")
+	ErrGenesisAlertExists  = errors.New("the genesis alert already exists")
+	ErrInvalidGenesisAlert = errors.New("invalid genesis alert")
+	ErrInvalidPublicKey    = errors.New("public key must be a compressed public key in hex (33 bytes)")
+	ErrNodeActionQueued    = errors.New("node is unreachable or catching up, the action is replayed once it responds")
+)
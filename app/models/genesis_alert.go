@@ -2,16 +2,25 @@ package models
 
 import (
 	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
 	"time"
 
 	"github.com/bitcoin-sv/alert-system/app/models/model"
 	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/bitcoinschema/go-bitcoin"
 )
 
+// genesisHeaderLength is the length of the genesis alert without the signatures (it has no message)
+const genesisHeaderLength = 20
+
+// genesisSignatureLength is the length of each signature of the genesis alert
+const genesisSignatureLength = 65
+
 // CreateGenesisAlert will create the genesis alert if it is not in the database
 // If it is in the database, it will do nothing
 func CreateGenesisAlert(ctx context.Context, opts ...model.Options) error {
-	newAlert := NewAlertMessage(opts...)
 	// Get the alert message by sequence number
 	m, err := GetAlertMessageBySequenceNumber(ctx, 0, opts...)
 	if err != nil {
@@ -20,29 +29,21 @@ func CreateGenesisAlert(ctx context.Context, opts ...model.Options) error {
 		return nil
 	}
 
-	// Create the array of keys
-	keys := newAlert.Config().GenesisKeys
-	var msg []byte
-
-	// Create the array of keys to save
-	keysToSave := make([]*PublicKey, 0, len(keys))
-
-	// Set the new flag
-	opts = append(opts, model.New())
-
-	// Loop through the keys
-	for _, key := range keys {
-		// Create the public key
-		k := NewPublicKey(opts...)
-		k.Key = key
-		k.Active = true
-		keysToSave = append(keysToSave, k)
+	// Sign the genesis alert with the genesis keys
+	var newAlert *AlertMessage
+	if newAlert, err = NewGenesisAlert(nil, opts...); err != nil {
+		return err
 	}
 
-	// Sync creating a new alert
+	// Save the keys and the alert
+	return saveGenesisAlert(ctx, newAlert, newAlert.Config().GenesisKeys, opts...)
+}
 
+// NewGenesisAlert will create the genesis alert (sequence 0, setting the keys) signed with the private keys
+// (hex or WIF), or with the genesis keys if there are none (the same alert every instance creates at startup)
+func NewGenesisAlert(privateKeys []string, opts ...model.Options) (*AlertMessage, error) {
+	newAlert := NewAlertMessage(opts...)
 	newAlert.SetAlertType(AlertTypeSetKeys)
-	newAlert.message = msg
 	newAlert.SequenceNumber = 0
 	newAlert.timestamp = uint64(time.Date(2923, time.November, 1, 1, 1, 1, 1, time.UTC).Unix())
 	newAlert.version = 1
@@ -52,20 +53,86 @@ func CreateGenesisAlert(ctx context.Context, opts ...model.Options) error {
 	newAlert.SerializeData()
 
 	// Sign the genesis alert
-	var sigs [][]byte
-	if sigs, err = utils.SignWithGenesis(newAlert.data); err != nil {
-		return err
+	keys := []string{utils.Key1, utils.Key2, utils.Key3}
+	if len(privateKeys) > 0 {
+		keys = make([]string, 0, len(privateKeys))
+		for _, key := range privateKeys {
+			if wif, err := bitcoin.WifToPrivateKeyString(key); err == nil {
+				key = wif
+			}
+			keys = append(keys, key)
+		}
+	}
+	sigs, err := utils.SignWithKeys(newAlert.data, keys)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidGenesisAlert, err.Error())
 	}
 	newAlert.signatures = sigs
+	_ = newAlert.Serialize()
+	return newAlert, nil
+}
 
-	// Save the keys
-	for i := range keysToSave {
-		if err = keysToSave[i].Save(ctx); err != nil {
-			return err
-		}
+// GenesisAlertFromBytes will read a pre-signed genesis alert (see NewGenesisAlert, the raw alert with its signatures)
+func GenesisAlertFromBytes(raw []byte, opts ...model.Options) (*AlertMessage, error) {
+	if len(raw) <= genesisHeaderLength || (len(raw)-genesisHeaderLength)%genesisSignatureLength != 0 {
+		return nil, fmt.Errorf("%w: expected %d bytes and %d byte signatures, got %d bytes",
+			ErrInvalidGenesisAlert, genesisHeaderLength, genesisSignatureLength, len(raw))
+	}
+	newAlert := NewAlertMessage(opts...)
+	newAlert.version = binary.LittleEndian.Uint32(raw[:4])
+	newAlert.SequenceNumber = binary.LittleEndian.Uint32(raw[4:8])
+	newAlert.timestamp = binary.LittleEndian.Uint64(raw[8:16])
+	newAlert.SetAlertType(AlertType(binary.LittleEndian.Uint32(raw[16:20])))
+	if newAlert.SequenceNumber != 0 || newAlert.GetAlertType() != AlertTypeSetKeys {
+		return nil, fmt.Errorf("%w: sequence %d of type %d is not the genesis alert",
+			ErrInvalidGenesisAlert, newAlert.SequenceNumber, newAlert.GetAlertType())
 	}
+	for sigs := raw[genesisHeaderLength:]; len(sigs) > 0; sigs = sigs[genesisSignatureLength:] {
+		newAlert.signatures = append(newAlert.signatures, sigs[:genesisSignatureLength])
+	}
+	newAlert.Processed = true
 	_ = newAlert.Serialize()
+	return newAlert, nil
+}
+
+// SaveGenesisAlert will save the genesis alert and the public keys it sets (hex, compressed)
+// ErrGenesisAlertExists is returned if the datastore already has a genesis alert
+func SaveGenesisAlert(ctx context.Context, genesis *AlertMessage, publicKeys []string, opts ...model.Options) error {
+	m, err := GetAlertMessageBySequenceNumber(ctx, 0, opts...)
+	if err != nil {
+		return err
+	} else if m != nil && len(m.Hash) > 0 {
+		return fmt.Errorf("%w: %s", ErrGenesisAlertExists, m.Hash)
+	}
+	if err = ValidatePublicKeys(publicKeys); err != nil {
+		return err
+	}
+	return saveGenesisAlert(ctx, genesis, publicKeys, opts...)
+}
 
-	// Save the alert
-	return newAlert.Save(ctx)
+// ValidatePublicKeys will validate that the keys are compressed public keys in hex
+func ValidatePublicKeys(publicKeys []string) error {
+	if len(publicKeys) == 0 {
+		return fmt.Errorf("%w: no public keys", ErrInvalidPublicKey)
+	}
+	for _, key := range publicKeys {
+		if b, err := hex.DecodeString(key); err != nil || len(b) != 33 {
+			return fmt.Errorf("%w: %q", ErrInvalidPublicKey, key)
+		}
+	}
+	return nil
+}
+
+// saveGenesisAlert will save the public keys (active) and the genesis alert
+func saveGenesisAlert(ctx context.Context, genesis *AlertMessage, publicKeys []string, opts ...model.Options) error {
+	opts = append(opts, model.New())
+	for _, key := range publicKeys {
+		k := NewPublicKey(opts...)
+		k.Key = key
+		k.Active = true
+		if err := k.Save(ctx); err != nil {
+			return err
+		}
+	}
+	return genesis.Save(ctx)
 }
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/bitcoinschema/go-bitcoin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewGenesisAlert will test creating the genesis alert with the genesis keys or other keys
+func TestNewGenesisAlert(t *testing.T) {
+	t.Parallel()
+
+	t.Run("genesis keys", func(t *testing.T) {
+		genesis, err := NewGenesisAlert(nil)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(0), genesis.SequenceNumber)
+		assert.Equal(t, AlertTypeSetKeys, genesis.GetAlertType())
+		assert.True(t, genesis.Processed)
+		assert.Len(t, genesis.signatures, 3)
+
+		// The same alert every time (every instance creates it at startup)
+		same, err := NewGenesisAlert([]string{utils.Key1, utils.Key2, utils.Key3})
+		require.NoError(t, err)
+		assert.Equal(t, genesis.Hash, same.Hash)
+		assert.Equal(t, genesis.Raw, same.Raw)
+	})
+
+	t.Run("wif keys", func(t *testing.T) {
+		wif, err := bitcoin.PrivateKeyToWifString(utils.Key4)
+		require.NoError(t, err)
+		fromWif, err := NewGenesisAlert([]string{wif})
+		require.NoError(t, err)
+		fromHex, err := NewGenesisAlert([]string{utils.Key4})
+		require.NoError(t, err)
+		assert.Equal(t, fromHex.Raw, fromWif.Raw)
+		assert.Len(t, fromWif.signatures, 1)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		_, err := NewGenesisAlert([]string{"not-a-key"})
+		require.ErrorIs(t, err, ErrInvalidGenesisAlert)
+	})
+}
+
+// TestGenesisAlertFromBytes will test reading a pre-signed genesis alert
+func TestGenesisAlertFromBytes(t *testing.T) {
+	t.Parallel()
+
+	genesis, err := NewGenesisAlert([]string{utils.Key4, utils.Key5})
+	require.NoError(t, err)
+	raw := genesis.Serialize()
+
+	t.Run("round trip", func(t *testing.T) {
+		read, err := GenesisAlertFromBytes(raw)
+		require.NoError(t, err)
+		assert.Equal(t, genesis.Hash, read.Hash)
+		assert.Equal(t, genesis.Raw, read.Raw)
+		assert.Equal(t, genesis.signatures, read.signatures)
+		assert.True(t, read.Processed)
+	})
+
+	t.Run("invalid length", func(t *testing.T) {
+		_, err := GenesisAlertFromBytes(raw[:len(raw)-1])
+		require.ErrorIs(t, err, ErrInvalidGenesisAlert)
+		_, err = GenesisAlertFromBytes(raw[:genesisHeaderLength])
+		require.ErrorIs(t, err, ErrInvalidGenesisAlert)
+	})
+
+	t.Run("not the genesis alert", func(t *testing.T) {
+		other := append([]byte{}, raw...)
+		other[4] = 1 // Sequence number
+		_, err := GenesisAlertFromBytes(other)
+		require.ErrorIs(t, err, ErrInvalidGenesisAlert)
+	})
+}
+
+// TestValidatePublicKeys will test validating the public keys set by the genesis alert
+func TestValidatePublicKeys(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidatePublicKeys([]string{utils.MainKey1, utils.MainKey2}))
+	require.ErrorIs(t, ValidatePublicKeys(nil), ErrInvalidPublicKey)
+	require.ErrorIs(t, ValidatePublicKeys([]string{utils.MainKey1, "key12345"}), ErrInvalidPublicKey)
+	require.ErrorIs(t, ValidatePublicKeys([]string{utils.Key1}), ErrInvalidPublicKey) // A private key
+}
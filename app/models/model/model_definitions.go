@@ -10,14 +10,34 @@ const (
 
 // All base models
 const (
-	NameAlertMessage Name = "alert_message" // AlertMessage is the alert message model
-	NameEmpty        Name = "empty"         // Empty model (base model without a name set)
-	NamePublicKey    Name = "public_key"    // PublicKey is the public key model
+	NameAlertLock       Name = "alert_lock"        // AlertLock is the alert enforcement lock model
+	NameAlertMessage    Name = "alert_message"     // AlertMessage is the alert message model
+	NameAlertSearchTerm Name = "alert_search_term" // AlertSearchTerm is the alert search term model
+	NameAuditEvent      Name = "audit_event"       // AuditEvent is the audit log entry model
+	NameClusterLease    Name = "cluster_lease"     // ClusterLease is the cluster leader lease model
+	NameEmpty           Name = "empty"             // Empty model (base model without a name set)
+	NameNodeAction      Name = "node_action"       // NodeAction is the node action model
+	NameOutboxEvent     Name = "outbox_event"      // OutboxEvent is the outbox event model
+	NamePeerBan         Name = "peer_ban"          // PeerBan is the peer ban model
+	NamePublicKey       Name = "public_key"        // PublicKey is the public key model
+	NameWebhook         Name = "webhook"           // Webhook is the registered webhook model
+	NameWebhookAttempt  Name = "webhook_attempt"   // WebhookAttempt is the webhook delivery attempt model
+	NameWebhookDelivery Name = "webhook_delivery"  // WebhookDelivery is the webhook delivery model
 )
 
 // All base model table names
 const (
-	TableAlertMessages = "alert_messages" // TableAlertMessages is the alert message table
-	TableEmpty         = "empty"          // TableEmpty is the empty placeholder table
-	TablePublicKeys    = "public_keys"    // TablePublicKeys is the public key table
+	TableAlertLocks        = "alert_locks"        // TableAlertLocks is the alert enforcement lock table
+	TableAlertMessages     = "alert_messages"     // TableAlertMessages is the alert message table
+	TableAlertSearchTerms  = "alert_search_terms" // TableAlertSearchTerms is the alert search term table
+	TableAuditEvents       = "audit_events"       // TableAuditEvents is the audit log table
+	TableClusterLeases     = "cluster_leases"     // TableClusterLeases is the cluster leader lease table
+	TableEmpty             = "empty"              // TableEmpty is the empty placeholder table
+	TableNodeActions       = "node_actions"       // TableNodeActions is the node action table
+	TableOutboxEvents      = "outbox_events"      // TableOutboxEvents is the outbox of the alert events
+	TablePeerBans          = "peer_bans"          // TablePeerBans is the peer ban table
+	TablePublicKeys        = "public_keys"        // TablePublicKeys is the public key table
+	TableWebhookAttempts   = "webhook_attempts"   // TableWebhookAttempts is the webhook delivery attempt table
+	TableWebhookDeliveries = "webhook_deliveries" // TableWebhookDeliveries is the webhook delivery table
+	TableWebhooks          = "webhooks"           // TableWebhooks is the registered webhook table
 )
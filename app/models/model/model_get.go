@@ -5,6 +5,8 @@ import (
 	"errors"
 	"time"
 
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/metrics"
 	"github.com/mrz1836/go-datastore"
 )
 
@@ -15,11 +17,17 @@ func Get(
 	conditions map[string]interface{},
 	timeout time.Duration,
 	forceWriteDB bool,
-) error {
+) (err error) {
 
+	// Check for datastore
+	if model.Datastore() == nil {
+		return ErrMissingDatastore
+	}
 	if timeout == 0 {
 		timeout = DefaultDatabaseReadTimeout
 	}
+	defer metrics.ObserveQuery(ctx, metrics.OperationGet, time.Now(), &err)
+	defer logSlowQuery(model.Config(), model.Name(), metrics.OperationGet, time.Now())
 
 	// Attempt to Get the model (by model fields & given conditions)
 	return model.Datastore().GetModel(ctx, model, conditions, timeout, forceWriteDB)
@@ -33,7 +41,9 @@ func GetModels(
 	conditions map[string]interface{},
 	queryParams *datastore.QueryParams,
 	timeout time.Duration,
-) error {
+) (err error) {
+	defer metrics.ObserveQuery(ctx, metrics.OperationGetMany, time.Now(), &err)
+
 	// Attempt to Get the model (by model fields & given conditions)
 	return datastore.GetModels(ctx, models, conditions, queryParams, nil, timeout)
 }
@@ -88,8 +98,10 @@ func GetModelsByConditions(ctx context.Context, modelName Name, modelItems inter
 	}
 
 	// Get the records
+	m := NewBaseModel(modelName, opts...)
+	defer logSlowQuery(m.Config(), modelName.String(), metrics.OperationGetMany, time.Now())
 	if err := GetModels(
-		ctx, NewBaseModel(modelName, opts...).Datastore(),
+		ctx, m.Datastore(),
 		modelItems, dbConditions, queryParams, DefaultDatabaseReadTimeout,
 	); err != nil {
 		if errors.Is(err, datastore.ErrNoResults) {
@@ -173,3 +185,10 @@ func getModelCountByConditions(ctx context.Context, modelName Name, model interf
 	return count, nil
 }
 */
+
+// logSlowQuery will log a warning if the datastore query took longer than the slow_log threshold
+func logSlowQuery(conf *config.Config, name, operation string, start time.Time) {
+	if conf != nil {
+		config.LogSlow(conf.Services.Log, conf.SlowLog.Datastore, start, "datastore %s of %s", operation, name)
+	}
+}
@@ -168,13 +168,18 @@ func (m *Model) SetOptions(opts ...Options) {
 // SetRecordTime will set the record timestamps (created is true for a new record)
 func (m *Model) SetRecordTime(created bool) {
 	if created {
-		m.CreatedAt = time.Now().UTC()
-		m.UpdatedAt = time.Now().UTC() // Override the default so it's UTC
+		m.CreatedAt = m.Now()
+		m.UpdatedAt = m.Now() // Override the default so it's UTC
 	} else {
-		m.UpdatedAt = time.Now().UTC()
+		m.UpdatedAt = m.Now()
 	}
 }
 
+// Now will return the current UTC time of the clock of the dependencies (expiration of the locks and bans)
+func (m *Model) Now() time.Time {
+	return m.dependencies.Clock().Now().UTC()
+}
+
 // UpdateMetadata will update the metadata on the model
 // any key set to nil will be removed, other keys updated or added
 func (m *Model) UpdateMetadata(metadata Metadata) {
@@ -3,7 +3,9 @@ package model
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/bitcoin-sv/alert-system/app/metrics"
 	"github.com/mrz1836/go-datastore"
 	"github.com/pkg/errors"
 )
@@ -16,6 +18,8 @@ func Save(ctx context.Context, model BaseInterface) (err error) {
 	if ds == nil {
 		return ErrMissingDatastore
 	}
+	defer metrics.ObserveQuery(ctx, metrics.OperationSave, time.Now(), &err)
+	defer logSlowQuery(model.Config(), model.Name(), metrics.OperationSave, time.Now())
 
 	// Create new Datastore transaction
 	// NOTE: we need this to be in a callback context for Mongo
@@ -8,14 +8,64 @@ var (
 	// BaseModels is the list of models for loading the engine and AutoMigration (defaults)
 	BaseModels = []interface{}{
 
+		// AlertLock - used for locking the enforcement of an alert (instances sharing the datastore)
+		&AlertLock{
+			Model: *model.NewBaseModel(model.NameAlertLock),
+		},
+
 		// AlertMessage - used for alert messages
 		&AlertMessage{
 			Model: *model.NewBaseModel(model.NameAlertMessage),
 		},
 
+		// AlertSearchTerm - used for searching the decoded alert payloads
+		&AlertSearchTerm{
+			Model: *model.NewBaseModel(model.NameAlertSearchTerm),
+		},
+
+		// AuditEvent - used for the audit log (if the datastore output is used)
+		&AuditEvent{
+			Model: *model.NewBaseModel(model.NameAuditEvent),
+		},
+
+		// ClusterLease - used for the leader election of the clustered instances
+		&ClusterLease{
+			Model: *model.NewBaseModel(model.NameClusterLease),
+		},
+
+		// NodeAction - used for recording alert actions executed against the node
+		&NodeAction{
+			Model: *model.NewBaseModel(model.NameNodeAction),
+		},
+
+		// OutboxEvent - used for the outbox of the alert events (saved with the alert, replayed if not delivered)
+		&OutboxEvent{
+			Model: *model.NewBaseModel(model.NameOutboxEvent),
+		},
+
+		// PeerBan - used for manual peer bans
+		&PeerBan{
+			Model: *model.NewBaseModel(model.NamePeerBan),
+		},
+
 		// PublicKey - used for public keys
 		&PublicKey{
 			Model: *model.NewBaseModel(model.NamePublicKey),
 		},
+
+		// Webhook - used for registered webhooks
+		&Webhook{
+			Model: *model.NewBaseModel(model.NameWebhook),
+		},
+
+		// WebhookAttempt - used for recording each attempt of the webhook deliveries
+		&WebhookAttempt{
+			Model: *model.NewBaseModel(model.NameWebhookAttempt),
+		},
+
+		// WebhookDelivery - used for recording the deliveries to the registered webhooks
+		&WebhookDelivery{
+			Model: *model.NewBaseModel(model.NameWebhookDelivery),
+		},
 	}
 )
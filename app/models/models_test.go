@@ -2,17 +2,21 @@ package models
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"testing"
 
 	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/testutil/containers"
 	"github.com/stretchr/testify/suite"
 )
 
 // TestSuite is for testing the entire package using real/mocked services
 type TestSuite struct {
-	Dependencies *config.Config // App config and services (dependencies)
-	suite.Suite                 // Extends the suite.Suite package
+	Container    *containers.Container // Datastore container (nil for the in-memory sqlite datastore)
+	Dependencies *config.Config        // App config and services (dependencies)
+	suite.Suite                        // Extends the suite.Suite package
+	tests        int                   // Tests run (each has its own tables in the container)
 }
 
 // SetupSuite runs at the start of the suite
@@ -22,6 +26,11 @@ func (ts *TestSuite) SetupSuite() {
 	err := os.Setenv(config.EnvironmentKey, config.EnvironmentTest)
 	ts.Require().NoError(err)
 
+	// Start the datastore container (if ALERT_SYSTEM_TEST_DATASTORE is set)
+	ts.Container, err = containers.StartFromEnv(context.Background())
+	ts.Require().NoError(err)
+	ts.setDatastoreEnv()
+
 	// Load the configuration
 	ts.Dependencies, err = config.LoadDependencies(context.Background(), BaseModels, true)
 	ts.Require().NoError(err)
@@ -34,6 +43,20 @@ func (ts *TestSuite) TearDownSuite() {
 	if ts.Dependencies != nil {
 		ts.Dependencies.CloseAll(context.Background())
 	}
+
+	// Remove the datastore container
+	if ts.Container != nil {
+		ts.Container.Unsetenv()
+		ts.Require().NoError(ts.Container.Stop(context.Background()))
+	}
+}
+
+// setDatastoreEnv will point the test config at new tables of the datastore container (if any)
+func (ts *TestSuite) setDatastoreEnv() {
+	if ts.Container != nil {
+		ts.tests++
+		ts.Require().NoError(ts.Container.Setenv(fmt.Sprintf("alert_system_test_%d", ts.tests)))
+	}
 }
 
 // SetupTest runs before each test
@@ -42,6 +65,7 @@ func (ts *TestSuite) SetupTest() {
 	// Set the env to test
 	err := os.Setenv(config.EnvironmentKey, config.EnvironmentTest)
 	ts.Require().NoError(err)
+	ts.setDatastoreEnv()
 
 	// Load the services
 	ts.Dependencies, err = config.LoadDependencies(context.Background(), BaseModels, true)
@@ -0,0 +1,264 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/mrz1836/go-datastore"
+)
+
+// NodeAction is an object representing an alert action executed against a node
+type NodeAction struct {
+	// Base model
+	model.Model `bson:",inline"`
+
+	// Model specific fields
+	ID             uint64 `json:"id" toml:"id" yaml:"id" bson:"_id" gorm:"primaryKey;comment:This is a unique identifier"`
+	AlertType      uint32 `json:"alert_type" toml:"alert_type" yaml:"alert_type" bson:"alert_type" gorm:"<-;type:int8;comment:This is the alert type"`
+	Error          string `json:"error" toml:"error" yaml:"error" bson:"error" gorm:"<-;type:text;comment:This is the error returned by the node (if any)"`
+	LatencyMS      int64  `json:"latency_ms" toml:"latency_ms" yaml:"latency_ms" bson:"latency_ms" gorm:"<-;type:int8;comment:This is the time from receiving the alert over gossip to the action completing"`
+	PropagationMS  int64  `json:"propagation_ms" toml:"propagation_ms" yaml:"propagation_ms" bson:"propagation_ms" gorm:"<-;type:int8;comment:This is the time from the alert timestamp to it being received over gossip"`
+	RPCHost        string `json:"rpc_host" toml:"rpc_host" yaml:"rpc_host" bson:"rpc_host" gorm:"<-;type:varchar(255);index;comment:This is the RPC host of the node"`
+	SequenceNumber uint32 `json:"sequence_number" toml:"sequence_number" yaml:"sequence_number" bson:"sequence_number" gorm:"<-;type:int8;index;comment:This is the alert sequence number"`
+	Success        bool   `json:"success" toml:"success" yaml:"success" bson:"success" gorm:"<-;type:boolean;comment:This determines if the action was successful"`
+}
+
+// NewNodeAction creates a new node action
+func NewNodeAction(opts ...model.Options) *NodeAction {
+	return &NodeAction{
+		Model: *model.NewBaseModel(model.NameNodeAction, opts...),
+	}
+}
+
+// Name will get the name of the model
+func (m *NodeAction) Name() string {
+	return model.NameNodeAction.String()
+}
+
+// GetTableName will get the database table name of the model
+func (m *NodeAction) GetTableName() string {
+	return model.TableNodeActions
+}
+
+// GetID will get the model ID
+func (m *NodeAction) GetID() uint64 {
+	return m.ID
+}
+
+// Display filter the model for display
+func (m *NodeAction) Display() interface{} {
+	return m
+}
+
+// Migrate will run model specific migrations on startup
+func (m *NodeAction) Migrate(client datastore.ClientInterface) error {
+	return client.IndexMetadata(client.GetTableName(model.TableNodeActions), model.MetadataField)
+}
+
+// BeginSaveWithTx will start saving the model into the Datastore with the provided transaction
+func (m *NodeAction) BeginSaveWithTx(ctx context.Context, tx *datastore.Transaction) ([]model.BaseInterface, error) {
+	return model.BeginSaveWithTx(ctx, tx, m)
+}
+
+// Save will save the model into the Datastore
+func (m *NodeAction) Save(ctx context.Context) error {
+	return model.Save(ctx, m)
+}
+
+// RecordNodeAction will record the result of executing the alert against the node
+// With a node pool, one action is recorded per node (with the RPC host of the node and the error it returned)
+func RecordNodeAction(ctx context.Context, alert *AlertMessage, actionErr error, opts ...model.Options) ([]*NodeAction, error) {
+	conf := NewNodeAction(opts...).Config()
+	var pool *config.NodePool
+	if conf != nil && conf.Services.Node != nil {
+		pool, _ = conf.Services.Node.(*config.NodePool)
+	}
+	if pool == nil {
+		var rpcHost string
+		if conf != nil && conf.Services.Node != nil {
+			rpcHost = conf.Services.Node.GetRPCHost()
+		}
+		action, err := saveNodeAction(ctx, alert, rpcHost, actionErr, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return []*NodeAction{action}, nil
+	}
+
+	nodeErrs := nodeErrors(actionErr)
+	actions := make([]*NodeAction, 0, len(pool.Nodes()))
+	for _, node := range pool.Nodes() {
+		action, err := saveNodeAction(ctx, alert, node.GetRPCHost(), poolNodeError(pool, node, nodeErrs, actionErr), opts...)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// RecordReplayedNodeActions will record the result of the alert actions replayed on the nodes of the pool that
+// missed them (with the RPC host of the node)
+func RecordReplayedNodeActions(pool *config.NodePool, opts ...model.Options) {
+	pool.OnReplayed(func(ctx context.Context, node config.NodeInterface, ref interface{}, actionErr error) error {
+		alert, ok := ref.(*AlertMessage)
+		if !ok {
+			return nil
+		}
+		_, err := saveNodeAction(ctx, alert, node.GetRPCHost(), actionErr, opts...)
+		return err
+	})
+}
+
+// nodeContext will return the context of the node actions of the alert (the alert is the reference of the actions
+// replayed on the nodes of a pool that missed them)
+func (m *AlertMessage) nodeContext(ctx context.Context) context.Context {
+	return config.WithNodeActionRef(ctx, m)
+}
+
+// saveNodeAction will save the result of executing the alert against the node of the RPC host
+func saveNodeAction(ctx context.Context, alert *AlertMessage, rpcHost string, actionErr error,
+	opts ...model.Options) (*NodeAction, error) {
+	action := NewNodeAction(append(opts, model.New())...)
+	action.AlertType = uint32(alert.GetAlertType())
+	action.RPCHost = rpcHost
+	action.SequenceNumber = alert.SequenceNumber
+	action.Success = actionErr == nil
+	if actionErr != nil {
+		action.Error = actionErr.Error()
+	}
+	if receivedAt := alert.ReceivedAt(); !receivedAt.IsZero() {
+		action.LatencyMS = time.Since(receivedAt).Milliseconds()
+	}
+	if delay, ok := alert.PropagationDelay(); ok {
+		action.PropagationMS = delay.Milliseconds()
+	}
+	if err := action.Save(ctx); err != nil {
+		return nil, err
+	}
+	return action, nil
+}
+
+// nodeErrors will return the errors returned by the nodes of a pool for the action (by RPC host)
+func nodeErrors(actionErr error) map[string]error {
+	errs := make(map[string]error)
+	var collect func(err error)
+	collect = func(err error) {
+		var joined interface{ Unwrap() []error }
+		var nodeErr *config.NodeError
+		if errors.As(err, &joined) {
+			for _, e := range joined.Unwrap() {
+				collect(e)
+			}
+		} else if errors.As(err, &nodeErr) {
+			errs[nodeErr.Host] = nodeErr.Err
+		}
+	}
+	collect(actionErr)
+	return errs
+}
+
+// poolNodeError will return the result of the action on a node of the pool
+// A node returning an error has its own error, an unreachable (or catching up) node had the action queued if it
+// succeeded on the other nodes, and a node without error succeeded even if another node failed
+func poolNodeError(pool *config.NodePool, node config.NodeInterface, nodeErrs map[string]error, actionErr error) error {
+	if err, ok := nodeErrs[node.GetRPCHost()]; ok {
+		return err
+	} else if err = pool.Err(node); err != nil {
+		if actionErr == nil {
+			return fmt.Errorf("%w: %w", ErrNodeActionQueued, err)
+		}
+		return err
+	} else if actionErr == nil && pool.Missed(node) > 0 {
+		return ErrNodeActionQueued
+	} else if len(nodeErrs) > 0 {
+		return nil
+	}
+	return actionErr
+}
+
+// GetLatestNodeAction will get the latest action executed against the given node (if found)
+func GetLatestNodeAction(ctx context.Context, rpcHost string, metadata *model.Metadata, opts ...model.Options) (*NodeAction, error) {
+
+	// Set the conditions
+	conditions := &map[string]interface{}{
+		utils.FieldRPCHost: rpcHost,
+		utils.FieldDeletedAt: map[string]interface{}{ // IS NULL
+			utils.ExistsCondition: false,
+		},
+	}
+
+	// Set the query params
+	queryParams := &datastore.QueryParams{
+		Page:          1,
+		PageSize:      1,
+		OrderByField:  utils.FieldID,
+		SortDirection: utils.SortDescending,
+	}
+
+	// Get the record
+	modelItems := make([]*NodeAction, 0)
+	if err := model.GetModelsByConditions(
+		ctx, model.NameNodeAction, &modelItems, metadata, conditions, queryParams, opts...,
+	); err != nil {
+		return nil, err
+	} else if len(modelItems) == 0 {
+		return nil, nil
+	}
+
+	// Return the first item (only item)
+	return modelItems[0], nil
+}
+
+// GetNodeActionsPage will get a page of node actions after the cursor (ordered by ID), optionally for a single node
+// The next cursor is nil if there are no more actions
+func GetNodeActionsPage(ctx context.Context, rpcHost string, cursor *utils.Cursor, limit int,
+	metadata *model.Metadata, opts ...model.Options) ([]*NodeAction, *utils.Cursor, error) {
+
+	// Set the conditions
+	conditions := &map[string]interface{}{
+		utils.FieldDeletedAt: map[string]interface{}{ // IS NULL
+			utils.ExistsCondition: false,
+		},
+	}
+	if len(rpcHost) > 0 {
+		(*conditions)[utils.FieldRPCHost] = rpcHost
+	}
+	if cursor != nil {
+		(*conditions)[utils.FieldID] = map[string]interface{}{
+			utils.GreaterThanCondition: cursor.Sequence,
+		}
+	}
+
+	// Set the query params (one extra record to detect the next page)
+	limit = utils.PageSize(limit)
+	queryParams := &datastore.QueryParams{
+		Page:          1,
+		PageSize:      limit + 1,
+		OrderByField:  utils.FieldID,
+		SortDirection: utils.SortAscending,
+	}
+
+	// Get the records
+	modelItems := make([]*NodeAction, 0)
+	if err := model.GetModelsByConditions(
+		ctx, model.NameNodeAction, &modelItems, metadata, conditions, queryParams, opts...,
+	); err != nil {
+		return nil, nil, err
+	} else if len(modelItems) <= limit {
+		return modelItems, nil, nil
+	}
+
+	// Set the cursor to the last action in the page
+	modelItems = modelItems[:limit]
+	last := modelItems[limit-1]
+	return modelItems, &utils.Cursor{
+		Sequence:  last.ID,
+		Timestamp: last.CreatedAt.Unix(),
+	}, nil
+}
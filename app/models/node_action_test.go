@@ -0,0 +1,281 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/clock"
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/config/mocks"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/libsv/go-bt/v2/chainhash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNodeAction will test node actions
+func (ts *TestSuite) TestNodeAction() {
+	ts.T().Run("success - no options, base model", func(t *testing.T) {
+		action := NewNodeAction()
+		require.NotNil(t, action)
+		assert.NotNil(t, action.Logger())
+		assert.Equal(t, uint64(0), action.GetID())
+		assert.Equal(t, model.NameNodeAction.String(), action.Name())
+		assert.Equal(t, model.TableNodeActions, action.GetTableName())
+	})
+
+	ts.T().Run("success - record actions and get latest", func(t *testing.T) {
+		alert := NewAlertMessage(model.WithAllDependencies(ts.Dependencies))
+		alert.SetAlertType(AlertTypeBanPeer)
+		alert.SequenceNumber = 1
+
+		actions, err := RecordNodeAction(context.Background(), alert, nil, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		require.Len(t, actions, 1)
+		action := actions[0]
+		assert.True(t, action.Success)
+		assert.Equal(t, uint32(AlertTypeBanPeer), action.AlertType)
+		assert.Equal(t, ts.Dependencies.Services.Node.GetRPCHost(), action.RPCHost)
+
+		alert.SequenceNumber = 2
+		actions, err = RecordNodeAction(context.Background(), alert, errors.New("node error"), model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		require.Len(t, actions, 1)
+		action = actions[0]
+		assert.False(t, action.Success)
+		assert.Equal(t, "node error", action.Error)
+
+		var latest *NodeAction
+		latest, err = GetLatestNodeAction(
+			context.Background(), ts.Dependencies.Services.Node.GetRPCHost(), nil, model.WithAllDependencies(ts.Dependencies),
+		)
+		require.NoError(t, err)
+		require.NotNil(t, latest)
+		assert.Equal(t, uint32(2), latest.SequenceNumber)
+	})
+
+	ts.T().Run("success - no actions for unknown host", func(t *testing.T) {
+		latest, err := GetLatestNodeAction(
+			context.Background(), "unknown-host:8332", nil, model.WithAllDependencies(ts.Dependencies),
+		)
+		require.NoError(t, err)
+		assert.Nil(t, latest)
+	})
+
+	ts.T().Run("success - page through actions with a cursor", func(t *testing.T) {
+		alert := NewAlertMessage(model.WithAllDependencies(ts.Dependencies))
+		alert.SetAlertType(AlertTypeBanPeer)
+		for i := uint32(10); i < 13; i++ {
+			alert.SequenceNumber = i
+			_, err := RecordNodeAction(context.Background(), alert, nil, model.WithAllDependencies(ts.Dependencies))
+			require.NoError(t, err)
+		}
+
+		host := ts.Dependencies.Services.Node.GetRPCHost()
+		actions, next, err := GetNodeActionsPage(context.Background(), host, nil, 2, nil, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		require.Len(t, actions, 2)
+		require.NotNil(t, next)
+		assert.Equal(t, actions[1].ID, next.Sequence)
+
+		var more []*NodeAction
+		more, next, err = GetNodeActionsPage(context.Background(), host, next, 100, nil, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		assert.Nil(t, next)
+		require.NotEmpty(t, more)
+		assert.Greater(t, more[0].ID, actions[1].ID)
+	})
+
+	ts.T().Run("success - one action per node of a pool", func(t *testing.T) {
+		node := ts.Dependencies.Services.Node
+		defer func() {
+			ts.Dependencies.Services.Node = node
+		}()
+		pool, nodes := newTestNodePool("node1:8332", "node2:8332")
+		ts.Dependencies.Services.Node = pool
+		nodes[1].BanPeerFunc = func(context.Context, string) error {
+			return &config.RPCError{Code: -23, Message: "already banned"}
+		}
+
+		alert := NewAlertMessage(model.WithAllDependencies(ts.Dependencies))
+		alert.SetAlertType(AlertTypeBanPeer)
+		alert.SequenceNumber = 20
+		actionErr := pool.BanPeer(context.Background(), "127.0.0.1")
+		require.Error(t, actionErr)
+		actions, err := RecordNodeAction(context.Background(), alert, actionErr, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		require.Len(t, actions, 2)
+		assert.Equal(t, "node1:8332", actions[0].RPCHost)
+		assert.True(t, actions[0].Success)
+		assert.Equal(t, "node2:8332", actions[1].RPCHost)
+		assert.False(t, actions[1].Success)
+		assert.Contains(t, actions[1].Error, "already banned")
+
+		// The latest action of each node is found by its own host
+		for _, action := range actions {
+			var latest *NodeAction
+			latest, err = GetLatestNodeAction(context.Background(), action.RPCHost, nil, model.WithAllDependencies(ts.Dependencies))
+			require.NoError(t, err)
+			require.NotNil(t, latest)
+			assert.Equal(t, action.ID, latest.ID)
+		}
+	})
+
+	ts.T().Run("success - replayed action recorded for the recovered node", func(t *testing.T) {
+		node := ts.Dependencies.Services.Node
+		defer func() {
+			ts.Dependencies.Services.Node = node
+		}()
+		pool, nodes := newTestNodePool("node3:8332", "node4:8332")
+		ts.Dependencies.Services.Node = pool
+		RecordReplayedNodeActions(pool, model.WithAllDependencies(ts.Dependencies))
+		down := errors.New("connection refused")
+		nodes[1].BanPeerFunc = func(context.Context, string) error {
+			return down
+		}
+		nodes[1].BestBlockHashFunc = func(context.Context) (string, error) {
+			return "", down
+		}
+
+		// Queued for the unreachable node
+		alert := NewAlertMessage(model.WithAllDependencies(ts.Dependencies))
+		alert.SetAlertType(AlertTypeBanPeer)
+		alert.SequenceNumber = 21
+		banPeer := &AlertMessageBanPeer{AlertMessage: *alert, Peer: []byte("127.0.0.1")}
+		actionErr := banPeer.Do(context.Background())
+		require.NoError(t, actionErr)
+		actions, err := RecordNodeAction(context.Background(), alert, actionErr, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		require.Len(t, actions, 2)
+		assert.False(t, actions[1].Success)
+		assert.Contains(t, actions[1].Error, ErrNodeActionQueued.Error())
+
+		// Recorded for the node once it replays the action
+		nodes[1].BanPeerFunc = nil
+		nodes[1].BestBlockHashFunc = nil
+		_, err = pool.Check(context.Background())
+		require.NoError(t, err)
+		var latest *NodeAction
+		latest, err = GetLatestNodeAction(context.Background(), "node4:8332", nil, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		require.NotNil(t, latest)
+		assert.Greater(t, latest.ID, actions[1].ID)
+		assert.Equal(t, uint32(21), latest.SequenceNumber)
+		assert.True(t, latest.Success)
+	})
+}
+
+// newTestNodePool will create a pool of mock nodes
+func newTestNodePool(hosts ...string) (*config.NodePool, []*mocks.Node) {
+	nodes := make([]config.NodeInterface, 0, len(hosts))
+	mockNodes := make([]*mocks.Node, 0, len(hosts))
+	for _, host := range hosts {
+		node := &mocks.Node{RPCHost: host}
+		nodes = append(nodes, node)
+		mockNodes = append(mockNodes, node)
+	}
+	logger := config.NewExtendedLogger(os.Stdout, config.LogLevelError, nil)
+	return config.NewNodePool(nodes, time.Minute, clock.NewMock(time.Unix(1700000000, 0)), logger), mockNodes
+}
+
+// TestAlertMessage_nodeContext will test the alert is the reference of the actions replayed on a pool node
+func TestAlertMessage_nodeContext(t *testing.T) {
+	t.Parallel()
+
+	pool, nodes := newTestNodePool("node1", "node2")
+	nodes[1].InvalidateBlockFunc = func(context.Context, string) error {
+		return errors.New("connection refused")
+	}
+	var refs []interface{}
+	pool.OnReplayed(func(_ context.Context, node config.NodeInterface, ref interface{}, err error) error {
+		assert.Equal(t, "node2", node.GetRPCHost())
+		require.NoError(t, err)
+		refs = append(refs, ref)
+		return nil
+	})
+
+	alert := NewAlertMessage(model.WithAllDependencies(&config.Config{Services: config.Services{Node: pool}}))
+	alert.SetAlertType(AlertTypeInvalidateBlock)
+	alert.SequenceNumber = 7
+	invalidate := &AlertMessageInvalidateBlock{AlertMessage: *alert, BlockHash: &chainhash.Hash{}}
+	require.NoError(t, invalidate.Do(context.Background()))
+	require.Equal(t, 1, pool.Missed(nodes[1]))
+
+	nodes[1].InvalidateBlockFunc = nil
+	_, err := pool.Check(context.Background())
+	require.NoError(t, err)
+	require.Len(t, refs, 1)
+	replayed, ok := refs[0].(*AlertMessage)
+	require.True(t, ok)
+	assert.Equal(t, uint32(7), replayed.SequenceNumber)
+	assert.Equal(t, AlertTypeInvalidateBlock, replayed.GetAlertType())
+}
+
+// TestPoolNodeError will test the result of an action on each node of a pool
+func TestPoolNodeError(t *testing.T) {
+	t.Parallel()
+
+	errDown := errors.New("connection refused")
+	rpcErr := &config.RPCError{Code: -8, Message: "block not found"}
+	ctx := context.Background()
+
+	t.Run("succeeded on all the nodes", func(t *testing.T) {
+		pool, nodes := newTestNodePool("node1", "node2")
+		actionErr := pool.InvalidateBlock(ctx, "hash")
+		require.NoError(t, actionErr)
+		nodeErrs := nodeErrors(actionErr)
+		for _, node := range nodes {
+			require.NoError(t, poolNodeError(pool, node, nodeErrs, actionErr))
+		}
+	})
+
+	t.Run("failed on a node", func(t *testing.T) {
+		pool, nodes := newTestNodePool("node1", "node2")
+		nodes[0].InvalidateBlockFunc = func(context.Context, string) error {
+			return rpcErr
+		}
+		actionErr := pool.InvalidateBlock(ctx, "hash")
+		require.Error(t, actionErr)
+		nodeErrs := nodeErrors(actionErr)
+		require.Equal(t, rpcErr, poolNodeError(pool, nodes[0], nodeErrs, actionErr))
+		require.NoError(t, poolNodeError(pool, nodes[1], nodeErrs, actionErr))
+	})
+
+	t.Run("queued for an unreachable node", func(t *testing.T) {
+		pool, nodes := newTestNodePool("node1", "node2")
+		nodes[1].InvalidateBlockFunc = func(context.Context, string) error {
+			return errDown
+		}
+		actionErr := pool.InvalidateBlock(ctx, "hash")
+		require.NoError(t, actionErr)
+		nodeErrs := nodeErrors(actionErr)
+		require.NoError(t, poolNodeError(pool, nodes[0], nodeErrs, actionErr))
+		err := poolNodeError(pool, nodes[1], nodeErrs, actionErr)
+		require.ErrorIs(t, err, ErrNodeActionQueued)
+		require.ErrorIs(t, err, errDown)
+	})
+
+	t.Run("no node reachable", func(t *testing.T) {
+		pool, nodes := newTestNodePool("node1", "node2")
+		for _, node := range nodes {
+			node.InvalidateBlockFunc = func(context.Context, string) error {
+				return errDown
+			}
+		}
+		actionErr := pool.InvalidateBlock(ctx, "hash")
+		require.ErrorIs(t, actionErr, config.ErrNoHealthyNode)
+		nodeErrs := nodeErrors(actionErr)
+		for _, node := range nodes {
+			require.ErrorIs(t, poolNodeError(pool, node, nodeErrs, actionErr), errDown)
+		}
+	})
+
+	t.Run("alert error", func(t *testing.T) {
+		pool, nodes := newTestNodePool("node1")
+		actionErr := errors.New("invalid alert")
+		require.Equal(t, actionErr, poolNodeError(pool, nodes[0], nodeErrors(actionErr), actionErr))
+	})
+}
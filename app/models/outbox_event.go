@@ -0,0 +1,183 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/mrz1836/go-datastore"
+)
+
+// Outbox event statuses
+const (
+	OutboxStatusDelivered = "delivered" // Published to the subscribers (notifications, webhooks, ...)
+	OutboxStatusFailed    = "failed"    // Gave up after the max replay attempts
+	OutboxStatusPending   = "pending"   // Saved with the alert, not published yet
+)
+
+// OutboxEvent is an object representing an alert event saved in the same transaction as the alert
+// (the outbox), it is replayed if the process stopped before the event was published
+type OutboxEvent struct {
+	// Base model
+	model.Model `bson:",inline"`
+
+	// Model specific fields
+	ID             uint64 `json:"id" toml:"id" yaml:"id" bson:"_id" gorm:"primaryKey;comment:This is a unique identifier"`
+	Attempts       int    `json:"attempts" toml:"attempts" yaml:"attempts" bson:"attempts" gorm:"<-;type:int8;comment:This is the number of replay attempts"`
+	DedupKey       string `json:"dedup_key" toml:"dedup_key" yaml:"dedup_key" bson:"dedup_key" gorm:"<-;type:varchar(128);uniqueIndex;comment:This is the unique key of the event (the same for every replay)"`
+	Error          string `json:"error" toml:"error" yaml:"error" bson:"error" gorm:"<-;type:text;comment:This is the error of the alert action (if it failed)"`
+	Event          string `json:"event" toml:"event" yaml:"event" bson:"event" gorm:"<-;type:varchar(64);comment:This is the event type"`
+	Node           string `json:"node" toml:"node" yaml:"node" bson:"node" gorm:"<-;type:varchar(255);comment:This is the node RPC host"`
+	PeerID         string `json:"peer_id" toml:"peer_id" yaml:"peer_id" bson:"peer_id" gorm:"<-;type:varchar(128);comment:This is the peer the alert was received from"`
+	SequenceNumber uint32 `json:"sequence_number" toml:"sequence_number" yaml:"sequence_number" bson:"sequence_number" gorm:"<-;type:int8;index;comment:This is the alert sequence number"`
+	Source         string `json:"source" toml:"source" yaml:"source" bson:"source" gorm:"<-;type:varchar(16);comment:This is where the alert came from (gossip, sync or retry)"`
+	Status         string `json:"status" toml:"status" yaml:"status" bson:"status" gorm:"<-;type:varchar(16);index;comment:This is the outbox status"`
+}
+
+// NewOutboxEvent creates a new outbox event
+func NewOutboxEvent(opts ...model.Options) *OutboxEvent {
+	return &OutboxEvent{
+		Model: *model.NewBaseModel(model.NameOutboxEvent, opts...),
+	}
+}
+
+// NewAlertOutboxEvent creates a pending outbox event for the alert (the dedup key is set from the event)
+func NewAlertOutboxEvent(alert *AlertMessage, event, source, peerID, node string, actionErr error,
+	opts ...model.Options) *OutboxEvent {
+	o := NewOutboxEvent(opts...)
+	o.DedupKey = OutboxDedupKey(event, alert.SequenceNumber, source, time.Now())
+	o.Event = event
+	o.Node = node
+	o.PeerID = peerID
+	o.SequenceNumber = alert.SequenceNumber
+	o.Source = source
+	o.Status = OutboxStatusPending
+	if actionErr != nil {
+		o.Error = actionErr.Error()
+	}
+	return o
+}
+
+// OutboxDedupKey will return the unique key of an alert event (e.g. alert.enforced:42:gossip:1700000000000000000)
+func OutboxDedupKey(event string, sequenceNumber uint32, source string, at time.Time) string {
+	return fmt.Sprintf("%s:%d:%s:%d", event, sequenceNumber, source, at.UnixNano())
+}
+
+// Name will get the name of the model
+func (m *OutboxEvent) Name() string {
+	return model.NameOutboxEvent.String()
+}
+
+// GetTableName will get the database table name of the model
+func (m *OutboxEvent) GetTableName() string {
+	return model.TableOutboxEvents
+}
+
+// GetID will get the model ID
+func (m *OutboxEvent) GetID() uint64 {
+	return m.ID
+}
+
+// Display filter the model for display
+func (m *OutboxEvent) Display() interface{} {
+	return m
+}
+
+// Migrate will run model specific migrations on startup
+func (m *OutboxEvent) Migrate(client datastore.ClientInterface) error {
+	return client.IndexMetadata(client.GetTableName(model.TableOutboxEvents), model.MetadataField)
+}
+
+// BeginSaveWithTx will start saving the model into the Datastore with the provided transaction
+func (m *OutboxEvent) BeginSaveWithTx(ctx context.Context, tx *datastore.Transaction) ([]model.BaseInterface, error) {
+	return model.BeginSaveWithTx(ctx, tx, m)
+}
+
+// Save will save the model into the Datastore
+func (m *OutboxEvent) Save(ctx context.Context) error {
+	return model.Save(ctx, m)
+}
+
+// ActionError will return the error of the alert action (nil if it succeeded)
+func (m *OutboxEvent) ActionError() error {
+	if len(m.Error) == 0 {
+		return nil
+	}
+	return errors.New(m.Error)
+}
+
+// MarkDelivered will record the event as published (it is not replayed)
+func (m *OutboxEvent) MarkDelivered(ctx context.Context) error {
+	m.Status = OutboxStatusDelivered
+	return m.Save(ctx)
+}
+
+// GetOutboxEventByDedupKey will get the outbox event by its dedup key (if found)
+func GetOutboxEventByDedupKey(ctx context.Context, key string, opts ...model.Options) (*OutboxEvent, error) {
+
+	// Get the record
+	event := NewOutboxEvent(opts...)
+	conditions := map[string]interface{}{
+		utils.FieldDedupKey: key,
+		utils.FieldDeletedAt: map[string]interface{}{ // IS NULL
+			utils.ExistsCondition: false,
+		},
+	}
+	if err := model.Get(
+		ctx, event, conditions, model.DefaultDatabaseReadTimeout, true,
+	); err != nil {
+		if errors.Is(err, datastore.ErrNoResults) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// CountPendingOutboxEvents will count the outbox events not published yet
+func CountPendingOutboxEvents(ctx context.Context, opts ...model.Options) (int64, error) {
+	return countModels(ctx, NewOutboxEvent(opts...), map[string]interface{}{
+		utils.FieldDeletedAt: map[string]interface{}{ // IS NULL
+			utils.ExistsCondition: false,
+		},
+		utils.FieldStatus: OutboxStatusPending,
+	})
+}
+
+// GetPendingOutboxEvents will get the pending outbox events created before the time (oldest first)
+func GetPendingOutboxEvents(ctx context.Context, before time.Time, limit int, metadata *model.Metadata,
+	opts ...model.Options) ([]*OutboxEvent, error) {
+
+	// Set the conditions
+	conditions := &map[string]interface{}{
+		utils.FieldCreatedAt: map[string]interface{}{
+			utils.LessThanOrEqualCondition: before.UTC(),
+		},
+		utils.FieldDeletedAt: map[string]interface{}{ // IS NULL
+			utils.ExistsCondition: false,
+		},
+		utils.FieldStatus: OutboxStatusPending,
+	}
+
+	// Set the query params
+	queryParams := &datastore.QueryParams{
+		Page:          1,
+		PageSize:      limit,
+		OrderByField:  utils.FieldID,
+		SortDirection: utils.SortAscending,
+	}
+
+	// Get the records
+	modelItems := make([]*OutboxEvent, 0)
+	if err := model.GetModelsByConditions(
+		ctx, model.NameOutboxEvent, &modelItems, metadata, conditions, queryParams, opts...,
+	); err != nil {
+		return nil, err
+	}
+
+	return modelItems, nil
+}
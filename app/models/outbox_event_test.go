@@ -0,0 +1,76 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOutboxEvent will test the outbox events
+func (ts *TestSuite) TestOutboxEvent() {
+	ts.T().Run("success - no options, base model", func(t *testing.T) {
+		event := NewOutboxEvent()
+		require.NotNil(t, event)
+		assert.NotNil(t, event.Logger())
+		assert.Equal(t, uint64(0), event.GetID())
+		assert.Equal(t, model.NameOutboxEvent.String(), event.Name())
+		assert.Equal(t, model.TableOutboxEvents, event.GetTableName())
+	})
+
+	ts.T().Run("success - new alert outbox event", func(t *testing.T) {
+		alert := NewAlertMessage()
+		alert.SequenceNumber = 42
+		event := NewAlertOutboxEvent(alert, "alert.enforced", "gossip", "peer", "localhost:8332", errors.New("node error"))
+		assert.Equal(t, OutboxStatusPending, event.Status)
+		assert.Equal(t, uint32(42), event.SequenceNumber)
+		assert.Contains(t, event.DedupKey, "alert.enforced:42:gossip:")
+		require.EqualError(t, event.ActionError(), "node error")
+
+		event = NewAlertOutboxEvent(alert, "alert.enforced", "sync", "", "", nil)
+		require.NoError(t, event.ActionError())
+		assert.Equal(t, "alert.enforced:1:retry:1700000000000000000", OutboxDedupKey("alert.enforced", 1, "retry", time.Unix(1700000000, 0)))
+	})
+
+	ts.T().Run("success - saved with the alert and replayed until delivered", func(t *testing.T) {
+		alert := NewAlertMessage(model.WithAllDependencies(ts.Dependencies), model.New())
+		alert.SequenceNumber = 7
+		alert.Hash = "hash"
+		event := NewAlertOutboxEvent(alert, "alert.enforced", "gossip", "peer", "", nil, model.WithAllDependencies(ts.Dependencies), model.New())
+		alert.AddOutboxEvent(event)
+		assert.Len(t, alert.ChildModels(), 1)
+		require.NoError(t, alert.Save(context.Background()))
+		assert.False(t, event.IsNew())
+		assert.NotZero(t, event.GetID())
+		assert.Empty(t, alert.ChildModels(), "the saved event is not saved again")
+
+		found, err := GetOutboxEventByDedupKey(context.Background(), event.DedupKey, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "peer", found.PeerID)
+
+		// Only the events older than the min age are pending for a replay
+		pending, err := GetPendingOutboxEvents(context.Background(), time.Now().Add(-time.Hour), 10, nil, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+		pending, err = GetPendingOutboxEvents(context.Background(), time.Now().Add(time.Second), 10, nil, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		assert.Equal(t, event.DedupKey, pending[0].DedupKey)
+
+		require.NoError(t, pending[0].MarkDelivered(context.Background()))
+		pending, err = GetPendingOutboxEvents(context.Background(), time.Now().Add(time.Second), 10, nil, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+	})
+
+	ts.T().Run("success - unknown dedup key", func(t *testing.T) {
+		found, err := GetOutboxEventByDedupKey(context.Background(), "alert.enforced:0:gossip:0", model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+}
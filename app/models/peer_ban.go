@@ -0,0 +1,134 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/mrz1836/go-datastore"
+)
+
+// PeerBan is an object representing a manual ban of a peer (P2P and optionally the node)
+type PeerBan struct {
+	// Base model
+	model.Model `bson:",inline"`
+
+	// Model specific fields
+	ID          uint64     `json:"id" toml:"id" yaml:"id" bson:"_id" gorm:"primaryKey;comment:This is a unique identifier"`
+	PeerID      string     `json:"peer_id" toml:"peer_id" yaml:"peer_id" bson:"peer_id" gorm:"<-;type:varchar(128);index;comment:This is the libp2p peer ID"`
+	NodeAddress string     `json:"node_address" toml:"node_address" yaml:"node_address" bson:"node_address" gorm:"<-;type:varchar(64);comment:This is the address banned on the node (setban)"`
+	Reason      string     `json:"reason" toml:"reason" yaml:"reason" bson:"reason" gorm:"<-;type:text;comment:This is the reason for the ban"`
+	Duration    int64      `json:"duration" toml:"duration" yaml:"duration" bson:"duration" gorm:"<-;comment:This is the ban duration in seconds (0 is permanent)"`
+	ExpiresAt   *time.Time `json:"expires_at" toml:"expires_at" yaml:"expires_at" bson:"expires_at,omitempty" gorm:"comment:The time the ban expires (empty is permanent)"`
+	Active      bool       `json:"active" toml:"active" yaml:"active" bson:"active" gorm:"<-;type:boolean;index;comment:This is the active flag"`
+}
+
+// NewPeerBan creates a new peer ban
+func NewPeerBan(opts ...model.Options) *PeerBan {
+	return &PeerBan{
+		Model: *model.NewBaseModel(model.NamePeerBan, opts...),
+	}
+}
+
+// Name will get the name of the model
+func (m *PeerBan) Name() string {
+	return model.NamePeerBan.String()
+}
+
+// GetTableName will get the database table name of the model
+func (m *PeerBan) GetTableName() string {
+	return model.TablePeerBans
+}
+
+// GetID will get the model ID
+func (m *PeerBan) GetID() uint64 {
+	return m.ID
+}
+
+// Display filter the model for display
+func (m *PeerBan) Display() interface{} {
+	return m
+}
+
+// Migrate will run model specific migrations on startup
+func (m *PeerBan) Migrate(client datastore.ClientInterface) error {
+	return client.IndexMetadata(client.GetTableName(model.TablePeerBans), model.MetadataField)
+}
+
+// BeginSaveWithTx will start saving the model into the Datastore with the provided transaction
+func (m *PeerBan) BeginSaveWithTx(ctx context.Context, tx *datastore.Transaction) ([]model.BaseInterface, error) {
+	return model.BeginSaveWithTx(ctx, tx, m)
+}
+
+// Save will save the model into the Datastore
+func (m *PeerBan) Save(ctx context.Context) error {
+	return model.Save(ctx, m)
+}
+
+// SetDuration will set the duration and expiration of the ban (zero is a permanent ban)
+func (m *PeerBan) SetDuration(duration time.Duration) {
+	if duration <= 0 {
+		m.Duration = 0
+		m.ExpiresAt = nil
+		return
+	}
+	expiresAt := m.Now().Add(duration)
+	m.Duration = int64(duration / time.Second)
+	m.ExpiresAt = &expiresAt
+}
+
+// IsExpired will return true if the ban has an expiration in the past
+func (m *PeerBan) IsExpired() bool {
+	return m.ExpiresAt != nil && m.ExpiresAt.Before(m.Now())
+}
+
+// GetActivePeerBan will get the active ban for the given peer (if found)
+func GetActivePeerBan(ctx context.Context, peerID string, opts ...model.Options) (*PeerBan, error) {
+
+	// Get the record
+	ban := NewPeerBan(opts...)
+	conditions := map[string]interface{}{
+		utils.FieldPeerID: peerID,
+		utils.FieldActive: true,
+	}
+	if err := model.Get(
+		ctx, ban, conditions, model.DefaultDatabaseReadTimeout, true,
+	); err != nil {
+		if errors.Is(err, datastore.ErrNoResults) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return ban, nil
+}
+
+// GetActivePeerBans will get all the active peer bans
+func GetActivePeerBans(ctx context.Context, metadata *model.Metadata, opts ...model.Options) ([]*PeerBan, error) {
+
+	// Set the conditions
+	conditions := &map[string]interface{}{
+		utils.FieldActive: true, // Active flag is true
+		utils.FieldDeletedAt: map[string]interface{}{ // IS NULL
+			utils.ExistsCondition: false,
+		},
+	}
+
+	// Set the query params
+	queryParams := &datastore.QueryParams{
+		OrderByField:  utils.FieldID,
+		SortDirection: utils.SortAscending,
+	}
+
+	// Get the records
+	modelItems := make([]*PeerBan, 0)
+	if err := model.GetModelsByConditions(
+		ctx, model.NamePeerBan, &modelItems, metadata, conditions, queryParams, opts...,
+	); err != nil {
+		return nil, err
+	}
+
+	return modelItems, nil
+}
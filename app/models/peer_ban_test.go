@@ -0,0 +1,39 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/clock"
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPeerBan_SetDuration will test the ban expires once its duration elapsed on the clock
+func TestPeerBan_SetDuration(t *testing.T) {
+	now := clock.NewMock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	conf := &config.Config{}
+	conf.Services.Clock = now
+
+	t.Run("temporary ban", func(t *testing.T) {
+		ban := NewPeerBan(model.WithAllDependencies(conf))
+		ban.SetDuration(time.Hour)
+		assert.Equal(t, int64(3600), ban.Duration)
+		require.NotNil(t, ban.ExpiresAt)
+		assert.Equal(t, now.Now().Add(time.Hour), *ban.ExpiresAt)
+		assert.False(t, ban.IsExpired())
+
+		now.Advance(time.Hour + time.Second)
+		assert.True(t, ban.IsExpired())
+	})
+
+	t.Run("permanent ban", func(t *testing.T) {
+		ban := NewPeerBan(model.WithAllDependencies(conf))
+		ban.SetDuration(0)
+		assert.Nil(t, ban.ExpiresAt)
+		now.Advance(24 * 365 * time.Hour)
+		assert.False(t, ban.IsExpired())
+	})
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/mrz1836/go-datastore"
+)
+
+// SchemaTable is the migration status of the table of a base model
+type SchemaTable struct {
+	Error  string `json:"error,omitempty"` // Why the table could not be read (missing or not reachable)
+	Exists bool   `json:"exists"`          // The table exists (it could be read)
+	Model  string `json:"model"`           // Model name (e.g. alert_message)
+	Rows   int64  `json:"rows"`            // Number of rows in the table
+	Table  string `json:"table"`           // Table name (with the configured prefix)
+}
+
+// GetSchemaStatus will return the status of the tables of the base models (in the BaseModels order)
+// Nothing is created, a missing table is reported as not existing
+func GetSchemaStatus(ctx context.Context, client datastore.ClientInterface) []*SchemaTable {
+	tables := make([]*SchemaTable, 0, len(BaseModels))
+	for _, m := range BaseModels {
+		baseModel := m.(model.BaseInterface)
+		table := &SchemaTable{Model: baseModel.Name(), Table: client.GetTableName(baseModel.GetTableName())}
+		count, err := client.GetModelCount(ctx, m, map[string]interface{}{}, model.DefaultDatabaseReadTimeout)
+		if err != nil && !errors.Is(err, datastore.ErrNoResults) {
+			table.Error = err.Error()
+		} else {
+			table.Exists, table.Rows = true, count
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+// countModels will count the models matching the conditions (no results is a count of 0)
+func countModels(ctx context.Context, m model.BaseInterface, conditions map[string]interface{}) (int64, error) {
+	count, err := m.Datastore().GetModelCount(ctx, m, conditions, model.DefaultDatabaseReadTimeout)
+	if err != nil && !errors.Is(err, datastore.ErrNoResults) {
+		return 0, err
+	}
+	return count, nil
+}
+
+// DropSchema will drop the tables of the base models (in the reverse BaseModels order)
+// All the data is lost, it is the down migration of AutoMigrateDatabase
+func DropSchema(client datastore.ClientInterface) error {
+	if client.Engine() == datastore.MongoDB || client.Engine() == datastore.Empty {
+		return ErrDropUnsupported
+	}
+	for i := len(BaseModels) - 1; i >= 0; i-- {
+		table := client.GetTableName(BaseModels[i].(model.BaseInterface).GetTableName())
+		if err := client.Execute("DROP TABLE IF EXISTS " + table).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
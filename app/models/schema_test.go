@@ -0,0 +1,36 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSchema will test the schema status and the down migration
+func (ts *TestSuite) TestSchema() {
+	ts.T().Run("success - migrated tables exist", func(t *testing.T) {
+		tables := GetSchemaStatus(context.Background(), ts.Dependencies.Services.Datastore)
+		require.Len(t, tables, len(BaseModels))
+		for _, table := range tables {
+			assert.True(t, table.Exists, table.Table)
+			assert.Empty(t, table.Error)
+		}
+		assert.Equal(t, "alert_message", tables[0].Model)
+	})
+
+	ts.T().Run("success - dropped tables are missing", func(t *testing.T) {
+		require.NoError(t, DropSchema(ts.Dependencies.Services.Datastore))
+		for _, table := range GetSchemaStatus(context.Background(), ts.Dependencies.Services.Datastore) {
+			assert.False(t, table.Exists, table.Table)
+			assert.NotEmpty(t, table.Error)
+		}
+
+		// Migrating again creates the tables
+		require.NoError(t, ts.Dependencies.Services.Datastore.AutoMigrateDatabase(context.Background(), BaseModels...))
+		for _, table := range GetSchemaStatus(context.Background(), ts.Dependencies.Services.Datastore) {
+			assert.True(t, table.Exists, table.Table)
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/mrz1836/go-datastore"
+)
+
+// Webhook is an object representing a registered webhook (receives alert events)
+type Webhook struct {
+	// Base model
+	model.Model `bson:",inline"`
+
+	// Model specific fields
+	ID     uint64 `json:"id" toml:"id" yaml:"id" bson:"_id" gorm:"primaryKey;comment:This is a unique identifier"`
+	URL    string `json:"url" toml:"url" yaml:"url" bson:"url" gorm:"<-;type:text;comment:This is the URL to deliver events to"`
+	Events string `json:"events" toml:"events" yaml:"events" bson:"events" gorm:"<-;type:text;comment:This is the comma separated event filter (empty is all events)"`
+	Secret string `json:"secret" toml:"secret" yaml:"secret" bson:"secret" gorm:"<-;type:text;comment:This is the shared secret for HMAC signing"`
+	Active bool   `json:"active" toml:"active" yaml:"active" bson:"active" gorm:"<-;type:boolean;index;comment:This is the active flag"`
+}
+
+// NewWebhook creates a new webhook
+func NewWebhook(opts ...model.Options) *Webhook {
+	return &Webhook{
+		Model: *model.NewBaseModel(model.NameWebhook, opts...),
+	}
+}
+
+// Name will get the name of the model
+func (m *Webhook) Name() string {
+	return model.NameWebhook.String()
+}
+
+// GetTableName will get the database table name of the model
+func (m *Webhook) GetTableName() string {
+	return model.TableWebhooks
+}
+
+// GetID will get the model ID
+func (m *Webhook) GetID() uint64 {
+	return m.ID
+}
+
+// Display filter the model for display
+func (m *Webhook) Display() interface{} {
+	return m
+}
+
+// Migrate will run model specific migrations on startup
+func (m *Webhook) Migrate(client datastore.ClientInterface) error {
+	return client.IndexMetadata(client.GetTableName(model.TableWebhooks), model.MetadataField)
+}
+
+// BeginSaveWithTx will start saving the model into the Datastore with the provided transaction
+func (m *Webhook) BeginSaveWithTx(ctx context.Context, tx *datastore.Transaction) ([]model.BaseInterface, error) {
+	return model.BeginSaveWithTx(ctx, tx, m)
+}
+
+// Save will save the model into the Datastore
+func (m *Webhook) Save(ctx context.Context) error {
+	return model.Save(ctx, m)
+}
+
+// Delete will mark the webhook as deleted (and inactive)
+func (m *Webhook) Delete(ctx context.Context) error {
+	m.Active = false
+	m.DeletedAt.Valid = true
+	m.DeletedAt.Time = time.Now().UTC()
+	return m.Save(ctx)
+}
+
+// SetEvents will set the event filter (empty is all events)
+func (m *Webhook) SetEvents(events []string) {
+	filtered := make([]string, 0, len(events))
+	for _, event := range events {
+		if event = strings.TrimSpace(event); len(event) > 0 {
+			filtered = append(filtered, event)
+		}
+	}
+	m.Events = strings.Join(filtered, ",")
+}
+
+// GetEvents will get the event filter
+func (m *Webhook) GetEvents() []string {
+	if len(m.Events) == 0 {
+		return []string{}
+	}
+	return strings.Split(m.Events, ",")
+}
+
+// HasEvent will return true if the webhook is subscribed to the event
+func (m *Webhook) HasEvent(event string) bool {
+	if len(m.Events) == 0 {
+		return true
+	}
+	for _, e := range m.GetEvents() {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// GetWebhookByID will get the webhook by ID (if found)
+func GetWebhookByID(ctx context.Context, id uint64, opts ...model.Options) (*Webhook, error) {
+
+	// Get the record
+	webhook := NewWebhook(opts...)
+	conditions := map[string]interface{}{
+		utils.FieldID: id,
+		utils.FieldDeletedAt: map[string]interface{}{ // IS NULL
+			utils.ExistsCondition: false,
+		},
+	}
+	if err := model.Get(
+		ctx, webhook, conditions, model.DefaultDatabaseReadTimeout, true,
+	); err != nil {
+		if errors.Is(err, datastore.ErrNoResults) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// GetWebhooks will get all the webhooks (not deleted)
+func GetWebhooks(ctx context.Context, metadata *model.Metadata, opts ...model.Options) ([]*Webhook, error) {
+	return getWebhooksByConditions(ctx, metadata, map[string]interface{}{}, opts...)
+}
+
+// GetActiveWebhooks will get all the active webhooks
+func GetActiveWebhooks(ctx context.Context, metadata *model.Metadata, opts ...model.Options) ([]*Webhook, error) {
+	return getWebhooksByConditions(ctx, metadata, map[string]interface{}{
+		utils.FieldActive: true, // Active flag is true
+	}, opts...)
+}
+
+// getWebhooksByConditions will get the webhooks (not deleted) with the given conditions
+func getWebhooksByConditions(ctx context.Context, metadata *model.Metadata,
+	conditions map[string]interface{}, opts ...model.Options) ([]*Webhook, error) {
+
+	// Set the conditions
+	conditions[utils.FieldDeletedAt] = map[string]interface{}{ // IS NULL
+		utils.ExistsCondition: false,
+	}
+
+	// Set the query params
+	queryParams := &datastore.QueryParams{
+		OrderByField:  utils.FieldID,
+		SortDirection: utils.SortAscending,
+	}
+
+	// Get the records
+	modelItems := make([]*Webhook, 0)
+	if err := model.GetModelsByConditions(
+		ctx, model.NameWebhook, &modelItems, metadata, &conditions, queryParams, opts...,
+	); err != nil {
+		return nil, err
+	}
+
+	return modelItems, nil
+}
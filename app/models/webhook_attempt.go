@@ -0,0 +1,96 @@
+package models
+
+import (
+	"context"
+
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/mrz1836/go-datastore"
+)
+
+// WebhookAttempt is an object representing an attempt of a webhook delivery (the delivery attempts log)
+type WebhookAttempt struct {
+	// Base model
+	model.Model `bson:",inline"`
+
+	// Model specific fields
+	ID         uint64 `json:"id" toml:"id" yaml:"id" bson:"_id" gorm:"primaryKey;comment:This is a unique identifier"`
+	Attempt    int    `json:"attempt" toml:"attempt" yaml:"attempt" bson:"attempt" gorm:"<-;type:int8;comment:This is the attempt number (1 for the first attempt)"`
+	DeliveryID uint64 `json:"delivery_id" toml:"delivery_id" yaml:"delivery_id" bson:"delivery_id" gorm:"<-;type:int8;index;comment:This is the webhook delivery"`
+	Duration   int64  `json:"duration" toml:"duration" yaml:"duration" bson:"duration" gorm:"<-;type:int8;comment:This is the duration of the attempt in milliseconds"`
+	Error      string `json:"error" toml:"error" yaml:"error" bson:"error" gorm:"<-;type:text;comment:This is the error of the attempt (if any)"`
+	StatusCode int    `json:"status_code" toml:"status_code" yaml:"status_code" bson:"status_code" gorm:"<-;type:int8;comment:This is the HTTP status of the attempt (0 if there was no response)"`
+	Timestamp  int64  `json:"timestamp" toml:"timestamp" yaml:"timestamp" bson:"timestamp" gorm:"<-;type:int8;comment:This is the signed timestamp sent with the attempt"`
+	WebhookID  uint64 `json:"webhook_id" toml:"webhook_id" yaml:"webhook_id" bson:"webhook_id" gorm:"<-;type:int8;comment:This is the registered webhook"`
+}
+
+// NewWebhookAttempt creates a new webhook delivery attempt
+func NewWebhookAttempt(opts ...model.Options) *WebhookAttempt {
+	return &WebhookAttempt{
+		Model: *model.NewBaseModel(model.NameWebhookAttempt, opts...),
+	}
+}
+
+// Name will get the name of the model
+func (m *WebhookAttempt) Name() string {
+	return model.NameWebhookAttempt.String()
+}
+
+// GetTableName will get the database table name of the model
+func (m *WebhookAttempt) GetTableName() string {
+	return model.TableWebhookAttempts
+}
+
+// GetID will get the model ID
+func (m *WebhookAttempt) GetID() uint64 {
+	return m.ID
+}
+
+// Display filter the model for display
+func (m *WebhookAttempt) Display() interface{} {
+	return m
+}
+
+// Migrate will run model specific migrations on startup
+func (m *WebhookAttempt) Migrate(client datastore.ClientInterface) error {
+	return client.IndexMetadata(client.GetTableName(model.TableWebhookAttempts), model.MetadataField)
+}
+
+// BeginSaveWithTx will start saving the model into the Datastore with the provided transaction
+func (m *WebhookAttempt) BeginSaveWithTx(ctx context.Context, tx *datastore.Transaction) ([]model.BaseInterface, error) {
+	return model.BeginSaveWithTx(ctx, tx, m)
+}
+
+// Save will save the model into the Datastore
+func (m *WebhookAttempt) Save(ctx context.Context) error {
+	return model.Save(ctx, m)
+}
+
+// GetWebhookAttempts will get the attempts of the webhook delivery (oldest first)
+func GetWebhookAttempts(ctx context.Context, deliveryID uint64, metadata *model.Metadata,
+	opts ...model.Options) ([]*WebhookAttempt, error) {
+
+	// Set the conditions
+	conditions := &map[string]interface{}{
+		utils.FieldDeliveryID: deliveryID,
+		utils.FieldDeletedAt: map[string]interface{}{ // IS NULL
+			utils.ExistsCondition: false,
+		},
+	}
+
+	// Set the query params
+	queryParams := &datastore.QueryParams{
+		OrderByField:  utils.FieldID,
+		SortDirection: utils.SortAscending,
+	}
+
+	// Get the records
+	modelItems := make([]*WebhookAttempt, 0)
+	if err := model.GetModelsByConditions(
+		ctx, model.NameWebhookAttempt, &modelItems, metadata, conditions, queryParams, opts...,
+	); err != nil {
+		return nil, err
+	}
+
+	return modelItems, nil
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWebhookAttempt will test webhook delivery attempts
+func (ts *TestSuite) TestWebhookAttempt() {
+	ts.T().Run("success - no options, base model", func(t *testing.T) {
+		attempt := NewWebhookAttempt()
+		require.NotNil(t, attempt)
+		assert.NotNil(t, attempt.Logger())
+		assert.Equal(t, uint64(0), attempt.GetID())
+		assert.Equal(t, model.NameWebhookAttempt.String(), attempt.Name())
+		assert.Equal(t, model.TableWebhookAttempts, attempt.GetTableName())
+	})
+
+	ts.T().Run("success - attempts of a delivery", func(t *testing.T) {
+		delivery := NewWebhookDelivery(model.WithAllDependencies(ts.Dependencies), model.New())
+		delivery.Event = "alert.processed"
+		delivery.IdempotencyKey = "key"
+		delivery.Status = DeliveryStatusDelivered
+		delivery.WebhookID = 9
+		require.NoError(t, delivery.Save(context.Background()))
+
+		for i, statusCode := range []int{503, 200} {
+			attempt := NewWebhookAttempt(model.WithAllDependencies(ts.Dependencies), model.New())
+			attempt.Attempt = i + 1
+			attempt.DeliveryID = delivery.ID
+			attempt.StatusCode = statusCode
+			attempt.WebhookID = 9
+			require.NoError(t, attempt.Save(context.Background()))
+		}
+
+		found, err := GetWebhookDeliveryByID(context.Background(), 9, delivery.ID, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "key", found.IdempotencyKey)
+
+		found, err = GetWebhookDeliveryByID(context.Background(), 10, delivery.ID, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		assert.Nil(t, found)
+
+		attempts, err := GetWebhookAttempts(context.Background(), delivery.ID, nil, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		require.Len(t, attempts, 2)
+		assert.Equal(t, 1, attempts[0].Attempt)
+		assert.Equal(t, 503, attempts[0].StatusCode)
+		assert.Equal(t, 200, attempts[1].StatusCode)
+	})
+}
@@ -0,0 +1,172 @@
+package models
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/mrz1836/go-datastore"
+)
+
+// Webhook delivery statuses
+const (
+	DeliveryStatusAbandoned = "abandoned" // Dropped when the dispatcher stopped (queued or a retry was scheduled)
+	DeliveryStatusDelivered = "delivered" // Delivered (the webhook responded with a 2xx status)
+	DeliveryStatusFailed    = "failed"    // Gave up after the max retries
+	DeliveryStatusPending   = "pending"   // Queued, not attempted yet
+	DeliveryStatusRetrying  = "retrying"  // Last attempt failed, a retry is scheduled
+)
+
+// WebhookDelivery is an object representing the delivery of an event to a registered webhook
+type WebhookDelivery struct {
+	// Base model
+	model.Model `bson:",inline"`
+
+	// Model specific fields
+	ID             uint64 `json:"id" toml:"id" yaml:"id" bson:"_id" gorm:"primaryKey;comment:This is a unique identifier"`
+	Attempts       int    `json:"attempts" toml:"attempts" yaml:"attempts" bson:"attempts" gorm:"<-;type:int8;comment:This is the number of delivery attempts"`
+	Error          string `json:"error" toml:"error" yaml:"error" bson:"error" gorm:"<-;type:text;comment:This is the error of the last attempt (if any)"`
+	Event          string `json:"event" toml:"event" yaml:"event" bson:"event" gorm:"<-;type:varchar(64);comment:This is the delivered event"`
+	IdempotencyKey string `json:"idempotency_key" toml:"idempotency_key" yaml:"idempotency_key" bson:"idempotency_key" gorm:"<-;type:varchar(64);index;comment:This is the idempotency key sent with every attempt"`
+	SequenceNumber uint32 `json:"sequence_number" toml:"sequence_number" yaml:"sequence_number" bson:"sequence_number" gorm:"<-;type:int8;comment:This is the alert sequence number"`
+	Status         string `json:"status" toml:"status" yaml:"status" bson:"status" gorm:"<-;type:varchar(16);comment:This is the delivery status"`
+	StatusCode     int    `json:"status_code" toml:"status_code" yaml:"status_code" bson:"status_code" gorm:"<-;type:int8;comment:This is the HTTP status of the last attempt (0 if there was no response)"`
+	WebhookID      uint64 `json:"webhook_id" toml:"webhook_id" yaml:"webhook_id" bson:"webhook_id" gorm:"<-;type:int8;index;comment:This is the registered webhook"`
+}
+
+// NewWebhookDelivery creates a new webhook delivery
+func NewWebhookDelivery(opts ...model.Options) *WebhookDelivery {
+	return &WebhookDelivery{
+		Model: *model.NewBaseModel(model.NameWebhookDelivery, opts...),
+	}
+}
+
+// Name will get the name of the model
+func (m *WebhookDelivery) Name() string {
+	return model.NameWebhookDelivery.String()
+}
+
+// GetTableName will get the database table name of the model
+func (m *WebhookDelivery) GetTableName() string {
+	return model.TableWebhookDeliveries
+}
+
+// GetID will get the model ID
+func (m *WebhookDelivery) GetID() uint64 {
+	return m.ID
+}
+
+// Display filter the model for display
+func (m *WebhookDelivery) Display() interface{} {
+	return m
+}
+
+// Migrate will run model specific migrations on startup
+func (m *WebhookDelivery) Migrate(client datastore.ClientInterface) error {
+	return client.IndexMetadata(client.GetTableName(model.TableWebhookDeliveries), model.MetadataField)
+}
+
+// BeginSaveWithTx will start saving the model into the Datastore with the provided transaction
+func (m *WebhookDelivery) BeginSaveWithTx(ctx context.Context, tx *datastore.Transaction) ([]model.BaseInterface, error) {
+	return model.BeginSaveWithTx(ctx, tx, m)
+}
+
+// Save will save the model into the Datastore
+func (m *WebhookDelivery) Save(ctx context.Context) error {
+	return model.Save(ctx, m)
+}
+
+// GetWebhookDeliveryByID will get the delivery to the webhook by ID (if found)
+func GetWebhookDeliveryByID(ctx context.Context, webhookID, id uint64, opts ...model.Options) (*WebhookDelivery, error) {
+
+	// Get the record
+	delivery := NewWebhookDelivery(opts...)
+	conditions := map[string]interface{}{
+		utils.FieldID:        id,
+		utils.FieldWebhookID: webhookID,
+		utils.FieldDeletedAt: map[string]interface{}{ // IS NULL
+			utils.ExistsCondition: false,
+		},
+	}
+	if err := model.Get(
+		ctx, delivery, conditions, model.DefaultDatabaseReadTimeout, true,
+	); err != nil {
+		if errors.Is(err, datastore.ErrNoResults) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// GetWebhookDeliveryByKey will get the delivery to the webhook by its idempotency key (if found)
+func GetWebhookDeliveryByKey(ctx context.Context, webhookID uint64, key string, opts ...model.Options) (*WebhookDelivery, error) {
+
+	// Get the record
+	delivery := NewWebhookDelivery(opts...)
+	conditions := map[string]interface{}{
+		utils.FieldIdempotencyKey: key,
+		utils.FieldWebhookID:      webhookID,
+		utils.FieldDeletedAt: map[string]interface{}{ // IS NULL
+			utils.ExistsCondition: false,
+		},
+	}
+	if err := model.Get(
+		ctx, delivery, conditions, model.DefaultDatabaseReadTimeout, true,
+	); err != nil {
+		if errors.Is(err, datastore.ErrNoResults) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// GetWebhookDeliveriesPage will get a page of the deliveries to the webhook after the cursor (ordered by ID)
+// The next cursor is nil if there are no more deliveries
+func GetWebhookDeliveriesPage(ctx context.Context, webhookID uint64, cursor *utils.Cursor, limit int,
+	metadata *model.Metadata, opts ...model.Options) ([]*WebhookDelivery, *utils.Cursor, error) {
+
+	// Set the conditions
+	conditions := &map[string]interface{}{
+		utils.FieldWebhookID: webhookID,
+		utils.FieldDeletedAt: map[string]interface{}{ // IS NULL
+			utils.ExistsCondition: false,
+		},
+	}
+	if cursor != nil {
+		(*conditions)[utils.FieldID] = map[string]interface{}{
+			utils.GreaterThanCondition: cursor.Sequence,
+		}
+	}
+
+	// Set the query params (one extra record to detect the next page)
+	limit = utils.PageSize(limit)
+	queryParams := &datastore.QueryParams{
+		Page:          1,
+		PageSize:      limit + 1,
+		OrderByField:  utils.FieldID,
+		SortDirection: utils.SortAscending,
+	}
+
+	// Get the records
+	modelItems := make([]*WebhookDelivery, 0)
+	if err := model.GetModelsByConditions(
+		ctx, model.NameWebhookDelivery, &modelItems, metadata, conditions, queryParams, opts...,
+	); err != nil {
+		return nil, nil, err
+	} else if len(modelItems) <= limit {
+		return modelItems, nil, nil
+	}
+
+	// Set the cursor to the last delivery in the page
+	modelItems = modelItems[:limit]
+	last := modelItems[limit-1]
+	return modelItems, &utils.Cursor{
+		Sequence:  last.ID,
+		Timestamp: last.CreatedAt.Unix(),
+	}, nil
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWebhookDelivery will test webhook deliveries
+func (ts *TestSuite) TestWebhookDelivery() {
+	ts.T().Run("success - no options, base model", func(t *testing.T) {
+		delivery := NewWebhookDelivery()
+		require.NotNil(t, delivery)
+		assert.NotNil(t, delivery.Logger())
+		assert.Equal(t, uint64(0), delivery.GetID())
+		assert.Equal(t, model.NameWebhookDelivery.String(), delivery.Name())
+		assert.Equal(t, model.TableWebhookDeliveries, delivery.GetTableName())
+	})
+
+	ts.T().Run("success - page through the deliveries of a webhook", func(t *testing.T) {
+		for i := uint32(1); i <= 3; i++ {
+			delivery := NewWebhookDelivery(model.WithAllDependencies(ts.Dependencies), model.New())
+			delivery.Event = "alert.processed"
+			delivery.SequenceNumber = i
+			delivery.Status = DeliveryStatusPending
+			delivery.WebhookID = 7
+			require.NoError(t, delivery.Save(context.Background()))
+		}
+		other := NewWebhookDelivery(model.WithAllDependencies(ts.Dependencies), model.New())
+		other.Status = DeliveryStatusDelivered
+		other.WebhookID = 8
+		require.NoError(t, other.Save(context.Background()))
+
+		deliveries, next, err := GetWebhookDeliveriesPage(context.Background(), 7, nil, 2, nil, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		require.Len(t, deliveries, 2)
+		require.NotNil(t, next)
+		assert.Equal(t, uint32(1), deliveries[0].SequenceNumber)
+
+		var more []*WebhookDelivery
+		more, next, err = GetWebhookDeliveriesPage(context.Background(), 7, next, 100, nil, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		assert.Nil(t, next)
+		require.Len(t, more, 1)
+		assert.Equal(t, uint32(3), more[0].SequenceNumber)
+	})
+	ts.T().Run("success - get a delivery by its idempotency key", func(t *testing.T) {
+		delivery := NewWebhookDelivery(model.WithAllDependencies(ts.Dependencies), model.New())
+		delivery.IdempotencyKey = "replayed-key"
+		delivery.Status = DeliveryStatusRetrying
+		delivery.WebhookID = 9
+		require.NoError(t, delivery.Save(context.Background()))
+
+		found, err := GetWebhookDeliveryByKey(context.Background(), 9, "replayed-key", model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, DeliveryStatusRetrying, found.Status)
+
+		// Another webhook
+		found, err = GetWebhookDeliveryByKey(context.Background(), 10, "replayed-key", model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWebhook will test webhooks
+func (ts *TestSuite) TestWebhook() {
+	ts.T().Run("success - no options, base model", func(t *testing.T) {
+		hook := NewWebhook()
+		require.NotNil(t, hook)
+		assert.NotNil(t, hook.Logger())
+		assert.Equal(t, uint64(0), hook.GetID())
+		assert.Equal(t, model.NameWebhook.String(), hook.Name())
+		assert.Equal(t, model.TableWebhooks, hook.GetTableName())
+	})
+
+	ts.T().Run("success - event filter", func(t *testing.T) {
+		hook := NewWebhook()
+		assert.Empty(t, hook.GetEvents())
+		assert.True(t, hook.HasEvent("alert.processed"))
+
+		hook.SetEvents([]string{" alert.processed ", ""})
+		assert.Equal(t, []string{"alert.processed"}, hook.GetEvents())
+		assert.True(t, hook.HasEvent("alert.processed"))
+		assert.False(t, hook.HasEvent("alert.failed"))
+	})
+
+	ts.T().Run("success - create, get and delete", func(t *testing.T) {
+		hook := NewWebhook(model.WithAllDependencies(ts.Dependencies), model.New())
+		hook.URL = "https://example.com/hook"
+		hook.Secret = "secret"
+		hook.Active = true
+
+		err := hook.Save(context.Background())
+		require.NoError(t, err)
+		require.NotEqual(t, uint64(0), hook.GetID())
+
+		var found *Webhook
+		found, err = GetWebhookByID(context.Background(), hook.GetID(), model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, hook.URL, found.URL)
+
+		var active []*Webhook
+		active, err = GetActiveWebhooks(context.Background(), nil, model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		assert.Len(t, active, 1)
+
+		err = found.Delete(context.Background())
+		require.NoError(t, err)
+
+		found, err = GetWebhookByID(context.Background(), hook.GetID(), model.WithAllDependencies(ts.Dependencies))
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+}
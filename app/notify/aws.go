@@ -0,0 +1,182 @@
+package notify
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	awssns "github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	awssqs "github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/aws/smithy-go"
+	"github.com/bitcoin-sv/alert-system/app/config"
+)
+
+// loadAWSConfig will load the SDK config with the default credential chain (environment, shared files, web
+// identity, container or instance role) and the region of the config or the resource, else of the environment
+// The SDK HTTP client is used (the AWS_CA_BUNDLE and proxy of the environment)
+func loadAWSConfig(ctx context.Context, region string, optFns ...func(*awsconfig.LoadOptions) error) (aws.Config, error) {
+	options := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithRetryMaxAttempts(1), // Retried by the notification service
+	}
+	if len(region) > 0 {
+		options = append(options, awsconfig.WithRegion(region))
+	}
+	awsConf, err := awsconfig.LoadDefaultConfig(ctx, append(options, optFns...)...)
+	if err != nil {
+		return awsConf, err
+	} else if len(awsConf.Region) == 0 {
+		return awsConf, ErrAWSNoRegion
+	}
+	return awsConf, nil
+}
+
+// awsError will return the error of the request (with the AWS error code of the service)
+func awsError(service string, err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return fmt.Errorf("%w: %s %s: %s", ErrAWSRequest, service, apiErr.ErrorCode(), apiErr.ErrorMessage())
+	}
+	return err
+}
+
+// awsDedupID will return the FIFO deduplication ID of the notification (max 128 characters)
+func awsDedupID(n *Notification) string {
+	hash := sha256.Sum256([]byte(messageID(n)))
+	return hex.EncodeToString(hash[:])
+}
+
+// sns publishes the events to an SNS topic
+type sns struct {
+	client   *awssns.Client
+	fifo     bool
+	topicARN string
+}
+
+// newSNS will create the SNS channel (the region is the region of the topic ARN unless configured)
+func newSNS(ctx context.Context, conf config.SNSConfig, optFns ...func(*awsconfig.LoadOptions) error) (*sns, error) {
+	// arn:<partition>:sns:<region>:<account>:<topic>
+	parts := strings.Split(conf.TopicARN, ":")
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "sns" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSNSTopicARN, conf.TopicARN)
+	}
+	region := conf.Region
+	if len(region) == 0 {
+		region = parts[3]
+	}
+	awsConf, err := loadAWSConfig(ctx, region, optFns...)
+	if err != nil {
+		return nil, err
+	}
+	return &sns{
+		client: awssns.NewFromConfig(awsConf, func(o *awssns.Options) {
+			if len(conf.Endpoint) > 0 {
+				o.BaseEndpoint = aws.String(conf.Endpoint)
+			}
+		}),
+		fifo:     strings.HasSuffix(conf.TopicARN, ".fifo"),
+		topicARN: conf.TopicARN,
+	}, nil
+}
+
+// Name will return the name of the channel
+func (s *sns) Name() string {
+	return "sns"
+}
+
+// Send will publish the notification to the topic (with the event and severity as message attributes)
+func (s *sns) Send(ctx context.Context, n *Notification) error {
+	message, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	subject := n.Title
+	if len(subject) > 100 { // Max length of an SNS subject (for the email subscriptions)
+		subject = subject[:97] + "..."
+	}
+	input := &awssns.PublishInput{
+		Message: aws.String(string(message)),
+		MessageAttributes: map[string]snstypes.MessageAttributeValue{
+			"event":    {DataType: aws.String("String"), StringValue: aws.String(string(n.Event))},
+			"severity": {DataType: aws.String("String"), StringValue: aws.String(n.Severity.String())},
+		},
+		Subject:  aws.String(subject),
+		TopicArn: aws.String(s.topicARN),
+	}
+	if s.fifo {
+		input.MessageGroupId = aws.String(string(orderingKey(n)))
+		input.MessageDeduplicationId = aws.String(awsDedupID(n))
+	}
+	if _, err = s.client.Publish(ctx, input); err != nil {
+		return awsError("sns", err)
+	}
+	return nil
+}
+
+// sqs sends the events to an SQS queue
+type sqs struct {
+	client   *awssqs.Client
+	fifo     bool
+	queueURL string
+}
+
+// newSQS will create the SQS channel (the requests are sent to the host of the queue URL)
+func newSQS(ctx context.Context, conf config.SQSConfig, optFns ...func(*awsconfig.LoadOptions) error) (*sqs, error) {
+	// https://sqs.<region>.amazonaws.com/<account>/<queue>
+	queueURL, err := url.Parse(conf.QueueURL)
+	if err != nil || len(queueURL.Host) == 0 || strings.Count(strings.Trim(queueURL.Path, "/"), "/") != 1 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSQSQueueURL, conf.QueueURL)
+	}
+	region := conf.Region
+	if hostParts := strings.Split(queueURL.Hostname(), "."); len(region) == 0 && len(hostParts) > 2 && hostParts[0] == "sqs" {
+		region = hostParts[1]
+	}
+	var awsConf aws.Config
+	if awsConf, err = loadAWSConfig(ctx, region, optFns...); err != nil {
+		return nil, err
+	}
+	return &sqs{
+		client: awssqs.NewFromConfig(awsConf, func(o *awssqs.Options) {
+			o.BaseEndpoint = aws.String(queueURL.Scheme + "://" + queueURL.Host)
+		}),
+		fifo:     strings.HasSuffix(queueURL.Path, ".fifo"),
+		queueURL: conf.QueueURL,
+	}, nil
+}
+
+// Name will return the name of the channel
+func (s *sqs) Name() string {
+	return "sqs"
+}
+
+// Send will send the notification to the queue (with the event and severity as message attributes)
+func (s *sqs) Send(ctx context.Context, n *Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	input := &awssqs.SendMessageInput{
+		MessageAttributes: map[string]sqstypes.MessageAttributeValue{
+			"event":    {DataType: aws.String("String"), StringValue: aws.String(string(n.Event))},
+			"severity": {DataType: aws.String("String"), StringValue: aws.String(n.Severity.String())},
+		},
+		MessageBody: aws.String(string(body)),
+		QueueUrl:    aws.String(s.queueURL),
+	}
+	if s.fifo {
+		input.MessageGroupId = aws.String(string(orderingKey(n)))
+		input.MessageDeduplicationId = aws.String(awsDedupID(n))
+	}
+	if _, err = s.client.SendMessage(ctx, input); err != nil {
+		return awsError("sqs", err)
+	}
+	return nil
+}
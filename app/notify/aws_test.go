@@ -0,0 +1,172 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAWSOptions are the static credentials of the tests (the shared files of the environment are not used)
+var testAWSOptions = []func(*awsconfig.LoadOptions) error{
+	awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider("AKIDEXAMPLE", "secret", "")),
+	awsconfig.WithSharedConfigFiles([]string{}),
+	awsconfig.WithSharedCredentialsFiles([]string{}),
+}
+
+// newAWSServer will start a fake AWS endpoint answering with the status and body
+func newAWSServer(t *testing.T, status int, body string, check func(req *http.Request)) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if check != nil {
+			check(req)
+		}
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// TestNewSNS will test the method newSNS()
+func TestNewSNS(t *testing.T) {
+	t.Parallel()
+
+	s, err := newSNS(context.Background(), config.SNSConfig{TopicARN: "arn:aws:sns:eu-west-1:123456789012:alerts.fifo"}, testAWSOptions...)
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", s.client.Options().Region)
+	assert.Nil(t, s.client.Options().BaseEndpoint)
+	assert.True(t, s.fifo)
+
+	// LocalStack (the configured region and endpoint)
+	s, err = newSNS(context.Background(), config.SNSConfig{
+		Endpoint: "http://localhost:4566", Region: "us-east-1", TopicARN: "arn:aws:sns:eu-west-1:000000000000:alerts",
+	}, testAWSOptions...)
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-1", s.client.Options().Region)
+	assert.Equal(t, "http://localhost:4566", aws.ToString(s.client.Options().BaseEndpoint))
+	assert.False(t, s.fifo)
+
+	_, err = newSNS(context.Background(), config.SNSConfig{TopicARN: "alerts"}, testAWSOptions...)
+	require.ErrorIs(t, err, ErrInvalidSNSTopicARN)
+}
+
+// TestSNS_Send will test the method Send()
+func TestSNS_Send(t *testing.T) {
+	t.Parallel()
+
+	n := &Notification{Event: events.AlertEnforced, Sequence: 42, Time: time.Now(), Title: "Alert 42 (Informational) enforced on the node"}
+
+	t.Run("publish", func(t *testing.T) {
+		srv := newAWSServer(t, http.StatusOK, "<PublishResponse><PublishResult><MessageId>id</MessageId></PublishResult></PublishResponse>",
+			func(req *http.Request) {
+				assert.True(t, strings.HasPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"))
+				assert.Contains(t, req.Header.Get("Authorization"), "/us-east-1/sns/aws4_request")
+				body, _ := io.ReadAll(req.Body)
+				form, _ := url.ParseQuery(string(body))
+				assert.Equal(t, "Publish", form.Get("Action"))
+				assert.Equal(t, "arn:aws:sns:us-east-1:123456789012:alerts.fifo", form.Get("TopicArn"))
+				assert.Equal(t, "event", form.Get("MessageAttributes.entry.1.Name"))
+				assert.Equal(t, "alert.enforced", form.Get("MessageAttributes.entry.1.Value.StringValue"))
+				assert.Equal(t, "42", form.Get("MessageGroupId"))
+				assert.Len(t, form.Get("MessageDeduplicationId"), 64)
+				assert.Contains(t, form.Get("Message"), `"sequence":42`)
+			})
+		s, err := newSNS(context.Background(), config.SNSConfig{
+			Endpoint: srv.URL, TopicARN: "arn:aws:sns:us-east-1:123456789012:alerts.fifo",
+		}, testAWSOptions...)
+		require.NoError(t, err)
+		require.NoError(t, s.Send(context.Background(), n))
+	})
+
+	t.Run("aws error", func(t *testing.T) {
+		srv := newAWSServer(t, http.StatusForbidden, `<ErrorResponse><Error><Type>Sender</Type><Code>AuthorizationError</Code>`+
+			`<Message>not authorized to perform SNS:Publish</Message></Error></ErrorResponse>`, nil)
+		s, err := newSNS(context.Background(), config.SNSConfig{
+			Endpoint: srv.URL, TopicARN: "arn:aws:sns:us-east-1:123456789012:alerts",
+		}, testAWSOptions...)
+		require.NoError(t, err)
+		err = s.Send(context.Background(), n)
+		require.ErrorIs(t, err, ErrAWSRequest)
+		assert.Contains(t, err.Error(), "AuthorizationError")
+	})
+}
+
+// TestNewSQS will test the method newSQS() (not parallel, the region of the environment is cleared)
+func TestNewSQS(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+
+	s, err := newSQS(context.Background(), config.SQSConfig{QueueURL: "https://sqs.ap-southeast-2.amazonaws.com/123456789012/alerts"}, testAWSOptions...)
+	require.NoError(t, err)
+	assert.Equal(t, "ap-southeast-2", s.client.Options().Region)
+	assert.Equal(t, "https://sqs.ap-southeast-2.amazonaws.com", aws.ToString(s.client.Options().BaseEndpoint))
+	assert.False(t, s.fifo)
+
+	// LocalStack (the region of the environment)
+	t.Setenv("AWS_REGION", "us-east-1")
+	s, err = newSQS(context.Background(), config.SQSConfig{QueueURL: "http://localhost:4566/000000000000/alerts"}, testAWSOptions...)
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-1", s.client.Options().Region)
+	assert.Equal(t, "http://localhost:4566", aws.ToString(s.client.Options().BaseEndpoint))
+
+	t.Setenv("AWS_REGION", "")
+	_, err = newSQS(context.Background(), config.SQSConfig{QueueURL: "http://localhost:4566/000000000000/alerts"}, testAWSOptions...)
+	require.ErrorIs(t, err, ErrAWSNoRegion)
+	_, err = newSQS(context.Background(), config.SQSConfig{QueueURL: "alerts"}, testAWSOptions...)
+	require.ErrorIs(t, err, ErrInvalidSQSQueueURL)
+}
+
+// TestSQS_Send will test the method Send()
+func TestSQS_Send(t *testing.T) {
+	t.Parallel()
+
+	n := &Notification{Event: events.NodeUnhealthy, Node: "localhost:8332", Severity: SeverityCritical, Time: time.Now(), Title: "Node is unhealthy"}
+
+	t.Run("send message", func(t *testing.T) {
+		var queueURL string
+		srv := newAWSServer(t, http.StatusOK, `{"MessageId":"id"}`, func(req *http.Request) {
+			assert.Equal(t, "AmazonSQS.SendMessage", req.Header.Get("X-Amz-Target"))
+			assert.Contains(t, req.Header.Get("Authorization"), "/us-east-1/sqs/aws4_request")
+			msg := &struct {
+				MessageAttributes map[string]struct {
+					StringValue string `json:"StringValue"`
+				} `json:"MessageAttributes"`
+				MessageBody    string `json:"MessageBody"`
+				MessageGroupID string `json:"MessageGroupId"`
+				QueueURL       string `json:"QueueUrl"`
+			}{}
+			assert.NoError(t, json.NewDecoder(req.Body).Decode(msg))
+			assert.Equal(t, queueURL, msg.QueueURL)
+			assert.Equal(t, "critical", msg.MessageAttributes["severity"].StringValue)
+			assert.Equal(t, "alert-system/node/localhost:8332", msg.MessageGroupID)
+			assert.Contains(t, msg.MessageBody, `"event":"node.unhealthy"`)
+		})
+		queueURL = srv.URL + "/123456789012/alerts.fifo"
+		s, err := newSQS(context.Background(), config.SQSConfig{QueueURL: queueURL, Region: "us-east-1"}, testAWSOptions...)
+		require.NoError(t, err)
+		require.NoError(t, s.Send(context.Background(), n))
+	})
+
+	t.Run("aws error", func(t *testing.T) {
+		srv := newAWSServer(t, http.StatusBadRequest,
+			`{"__type":"com.amazonaws.sqs#QueueDoesNotExist","message":"The specified queue does not exist."}`, nil)
+		s, err := newSQS(context.Background(), config.SQSConfig{QueueURL: srv.URL + "/123456789012/alerts", Region: "us-east-1"}, testAWSOptions...)
+		require.NoError(t, err)
+		err = s.Send(context.Background(), n)
+		require.ErrorIs(t, err, ErrAWSRequest)
+		assert.Contains(t, err.Error(), "QueueDoesNotExist")
+	})
+}
@@ -0,0 +1,158 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+)
+
+// Discord embed limits
+const (
+	maxDiscordDescription = 4096
+	maxDiscordFieldValue  = 1024
+	maxDiscordTitle       = 256
+)
+
+// discordColors are the embed colors of the severities
+var discordColors = map[Severity]int{
+	SeverityCritical: 0xa30200,
+	SeverityInfo:     0x2eb886,
+	SeverityWarning:  0xdaa038,
+}
+
+// discordMessage is the message posted to a Discord webhook
+type discordMessage struct {
+	Embeds   []*discordEmbed `json:"embeds"`
+	Username string          `json:"username,omitempty"`
+}
+
+// discordEmbed is the rich embed of the notification
+type discordEmbed struct {
+	Color       int                  `json:"color"`
+	Description string               `json:"description,omitempty"`
+	Fields      []*discordEmbedField `json:"fields,omitempty"`
+	Footer      *discordEmbedFooter  `json:"footer,omitempty"`
+	Timestamp   string               `json:"timestamp"`
+	Title       string               `json:"title"`
+}
+
+// discordEmbedField is a name and value shown in the embed
+type discordEmbedField struct {
+	Inline bool   `json:"inline"`
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+}
+
+// discordEmbedFooter is the footer of the embed
+type discordEmbedFooter struct {
+	Text string `json:"text"`
+}
+
+// discord posts the notifications to a Discord webhook
+type discord struct {
+	httpClient config.HTTPInterface
+	username   string
+	webhookURL string
+}
+
+// newDiscord will create the Discord channel
+func newDiscord(conf config.DiscordConfig, httpClient config.HTTPInterface) *discord {
+	return &discord{httpClient: httpClient, username: conf.Username, webhookURL: conf.WebhookURL}
+}
+
+// Name will return the name of the channel
+func (d *discord) Name() string {
+	return "discord"
+}
+
+// Send will post the notification as a rich embed
+func (d *discord) Send(ctx context.Context, n *Notification) error {
+	body, err := json.Marshal(&discordMessage{Embeds: []*discordEmbed{newDiscordEmbed(n)}, Username: d.username})
+	if err != nil {
+		return err
+	}
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, d.webhookURL, bytes.NewReader(body)); err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var res *http.Response
+	if res, err = d.httpClient.Do(req); err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) { // Drop the URL (it has the webhook token)
+			err = urlErr.Err
+		}
+		return fmt.Errorf("discord: %w", err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: discord status code %d", ErrSendFailed, res.StatusCode)
+	}
+	return nil
+}
+
+// newDiscordEmbed will create the embed (alert type, sequence, decoded summary and the node action outcome)
+func newDiscordEmbed(n *Notification) *discordEmbed {
+	embed := &discordEmbed{
+		Color:       discordColors[n.Severity],
+		Description: truncate(n.Summary, maxDiscordDescription),
+		Footer:      &discordEmbedFooter{Text: "alert-system " + n.Severity.String()},
+		Timestamp:   n.Time.UTC().Format(time.RFC3339),
+		Title:       truncate(n.Title, maxDiscordTitle),
+	}
+	if len(n.Message) > 0 {
+		embed.Description = truncate(n.Message, maxDiscordDescription)
+	}
+	addField := func(name, value string, inline bool) {
+		if len(value) > 0 {
+			embed.Fields = append(embed.Fields, &discordEmbedField{Inline: inline, Name: name, Value: truncate(value, maxDiscordFieldValue)})
+		}
+	}
+	if len(n.AlertName) > 0 {
+		addField("Alert type", n.AlertName, true)
+		addField("Sequence", strconv.FormatUint(uint64(n.Sequence), 10), true)
+	}
+	addField("Outcome", outcome(n), true)
+	addField("Error", n.Error, false)
+	addField("Node", n.Node, true)
+	addField("Peer", n.PeerID, false)
+	return embed
+}
+
+// outcome will return the outcome of the event (the node action result for the enforced alerts)
+func outcome(n *Notification) string {
+	switch n.Event {
+	case events.AlertReceived, events.AlertVerified:
+		return "Pending"
+	case events.AlertEnforced:
+		if len(n.Error) > 0 {
+			return "Failed"
+		}
+		return "Enforced"
+	case events.NodeHealthy:
+		return "Healthy"
+	case events.NodeUnhealthy:
+		return "Unhealthy"
+	}
+	return ""
+}
+
+// truncate will cut the text to the max length (with an ellipsis)
+func truncate(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	return text[:max-3] + "..."
+}
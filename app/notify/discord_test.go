@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewDiscordEmbed will test the method newDiscordEmbed()
+func TestNewDiscordEmbed(t *testing.T) {
+	t.Parallel()
+
+	t.Run("failed alert", func(t *testing.T) {
+		embed := newDiscordEmbed(&Notification{
+			AlertName: "Informational", Error: "rpc error", Event: events.AlertEnforced, Node: "localhost:8332",
+			Sequence: 42, Severity: SeverityCritical, Summary: "Informational: hello",
+			Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Title: "Alert 42 (Informational) failed on the node",
+		})
+		assert.Equal(t, "Alert 42 (Informational) failed on the node", embed.Title)
+		assert.Equal(t, "Informational: hello", embed.Description)
+		assert.Equal(t, discordColors[SeverityCritical], embed.Color)
+		assert.Equal(t, "2024-01-02T03:04:05Z", embed.Timestamp)
+		fields := make(map[string]string)
+		for _, f := range embed.Fields {
+			fields[f.Name] = f.Value
+		}
+		assert.Equal(t, map[string]string{
+			"Alert type": "Informational", "Error": "rpc error", "Node": "localhost:8332", "Outcome": "Failed", "Sequence": "42",
+		}, fields)
+	})
+
+	t.Run("node event has no alert fields", func(t *testing.T) {
+		embed := newDiscordEmbed(&Notification{Event: events.NodeHealthy, Resolved: true, Title: "Node is healthy again"})
+		require.Len(t, embed.Fields, 1)
+		assert.Equal(t, "Outcome", embed.Fields[0].Name)
+		assert.Equal(t, "Healthy", embed.Fields[0].Value)
+	})
+
+	t.Run("long summary is truncated", func(t *testing.T) {
+		embed := newDiscordEmbed(&Notification{Event: events.AlertReceived, Summary: strings.Repeat("a", 5000)})
+		assert.Len(t, embed.Description, maxDiscordDescription)
+		assert.True(t, strings.HasSuffix(embed.Description, "..."))
+	})
+}
+
+// TestDiscord_Send will test the method Send()
+func TestDiscord_Send(t *testing.T) {
+	t.Parallel()
+
+	n := &Notification{AlertName: "Informational", Event: events.AlertEnforced, Sequence: 42, Title: "Alert 42 (Informational) enforced on the node"}
+
+	t.Run("rich embed", func(t *testing.T) {
+		d := newDiscord(config.DiscordConfig{Username: "Alert System", WebhookURL: "https://discord.com/api/webhooks/1/token"}, &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				msg := &discordMessage{}
+				require.NoError(t, json.NewDecoder(req.Body).Decode(msg))
+				assert.Equal(t, "Alert System", msg.Username)
+				require.Len(t, msg.Embeds, 1)
+				assert.Equal(t, "Alert 42 (Informational) enforced on the node", msg.Embeds[0].Title)
+				return newResponse(http.StatusNoContent, ""), nil
+			},
+		})
+		require.NoError(t, d.Send(context.Background(), n))
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		d := newDiscord(config.DiscordConfig{WebhookURL: "https://discord.com/api/webhooks/1/token"}, &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return newResponse(http.StatusTooManyRequests, `{"retry_after":1.5}`), nil
+			},
+		})
+		require.ErrorIs(t, d.Send(context.Background(), n), ErrSendFailed)
+	})
+
+	t.Run("the webhook token is not in the errors", func(t *testing.T) {
+		d := newDiscord(config.DiscordConfig{WebhookURL: "http://127.0.0.1:0/api/webhooks/1/secret-token"}, http.DefaultClient)
+		err := d.Send(context.Background(), n)
+		require.Error(t, err)
+		assert.False(t, strings.Contains(err.Error(), "secret-token"))
+	})
+
+	t.Run("http client error", func(t *testing.T) {
+		d := newDiscord(config.DiscordConfig{WebhookURL: "https://discord.com/api/webhooks/1/token"}, &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return nil, errors.New("HTTP client error")
+			},
+		})
+		require.Error(t, d.Send(context.Background(), n))
+	})
+}
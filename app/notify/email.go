@@ -0,0 +1,222 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+)
+
+// Email TLS modes
+const (
+	EmailTLSNone     = "none"     // Plain SMTP (auth is only allowed to localhost)
+	EmailTLSStartTLS = "starttls" // Upgrade with STARTTLS (port 587)
+	EmailTLSImplicit = "tls"      // Implicit TLS (port 465)
+)
+
+// DefaultEmailSubjectTemplate is the default email subject (the notification is the template data)
+const DefaultEmailSubjectTemplate = "[alert-system] [{{.Severity}}] {{.Title}}"
+
+// DefaultEmailBodyTemplate is the default email body (plain text)
+const DefaultEmailBodyTemplate = `{{.Title}}
+{{if .Summary}}
+{{.Summary}}
+{{end}}{{if .Error}}
+Error: {{.Error}}
+{{end}}
+Event:    {{.Event}}
+Severity: {{.Severity}}
+{{- if .Sequence}}
+Sequence: {{.Sequence}}{{end}}
+{{- if .Source}}
+Source:   {{.Source}}{{end}}
+{{- if .PeerID}}
+Peer:     {{.PeerID}}{{end}}
+{{- if .Node}}
+Node:     {{.Node}}{{end}}
+Time:     {{.Time.Format "2006-01-02T15:04:05Z07:00"}}
+`
+
+// email sends the notifications over SMTP
+type email struct {
+	address    string
+	body       *template.Template
+	from       string
+	host       string
+	password   string
+	recipients map[Severity][]string // Recipients per severity
+	subject    *template.Template
+	tlsMode    string
+	to         []string // Default recipients
+	username   string
+}
+
+// newEmail will create the email channel
+func newEmail(conf config.EmailConfig) (*email, error) {
+	e := &email{
+		address:    conf.Address,
+		from:       conf.From,
+		password:   conf.Password,
+		recipients: make(map[Severity][]string, len(conf.Recipients)),
+		tlsMode:    strings.ToLower(conf.TLS),
+		to:         conf.To,
+		username:   conf.Username,
+	}
+	var err error
+	if e.host, _, err = net.SplitHostPort(conf.Address); err != nil {
+		return nil, fmt.Errorf("email address: %w", err)
+	}
+	switch e.tlsMode {
+	case "":
+		e.tlsMode = EmailTLSStartTLS
+	case EmailTLSImplicit, EmailTLSNone, EmailTLSStartTLS:
+	default:
+		return nil, ErrInvalidEmailTLS
+	}
+	if len(e.from) == 0 {
+		return nil, ErrEmailNoSender
+	}
+
+	// Send the severities to their own recipients
+	for name, recipients := range conf.Recipients {
+		var severity Severity
+		if severity, err = ParseSeverity(name); err != nil {
+			return nil, fmt.Errorf("email: %w", err)
+		}
+		e.recipients[severity] = recipients
+	}
+	if len(e.to) == 0 && len(e.recipients) == 0 {
+		return nil, ErrEmailNoRecipients
+	}
+
+	// Parse the subject and body templates
+	subject, body := conf.SubjectTemplate, conf.BodyTemplate
+	if len(subject) == 0 {
+		subject = DefaultEmailSubjectTemplate
+	}
+	if len(body) == 0 {
+		body = DefaultEmailBodyTemplate
+	}
+	if e.subject, err = template.New("email_subject").Parse(subject); err != nil {
+		return nil, fmt.Errorf("email subject template: %w", err)
+	}
+	if e.body, err = template.New("email_body").Parse(body); err != nil {
+		return nil, fmt.Errorf("email body template: %w", err)
+	}
+	return e, nil
+}
+
+// Name will return the name of the channel
+func (e *email) Name() string {
+	return "email"
+}
+
+// Send will email the notification to the recipients of its severity
+func (e *email) Send(ctx context.Context, n *Notification) error {
+	to := e.to
+	if recipients, ok := e.recipients[n.Severity]; ok {
+		to = recipients
+	}
+	if len(to) == 0 {
+		return nil // Severity is not routed
+	}
+	msg, err := e.message(n, to)
+	if err != nil {
+		return err
+	}
+
+	// Connect (implicit TLS or plain) and stop the session when the context is done
+	dialer := &net.Dialer{}
+	var conn net.Conn
+	if e.tlsMode == EmailTLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{MinVersion: tls.VersionTLS12, ServerName: e.host}}).DialContext(ctx, "tcp", e.address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", e.address)
+	}
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	var c *smtp.Client
+	if c, err = smtp.NewClient(conn, e.host); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer func() {
+		_ = c.Close()
+	}()
+
+	// Upgrade and authenticate
+	if e.tlsMode == EmailTLSStartTLS {
+		if err = c.StartTLS(&tls.Config{MinVersion: tls.VersionTLS12, ServerName: e.host}); err != nil {
+			return err
+		}
+	}
+	if len(e.username) > 0 {
+		if err = c.Auth(smtp.PlainAuth("", e.username, e.password, e.host)); err != nil {
+			return err
+		}
+	}
+
+	// Send the message
+	if err = c.Mail(e.from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err = c.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// message will render the email (headers and a quoted-printable plain text body)
+func (e *email) message(n *Notification, to []string) ([]byte, error) {
+	var subject, body strings.Builder
+	if err := e.subject.Execute(&subject, n); err != nil {
+		return nil, err
+	}
+	if len(n.Message) > 0 {
+		body.WriteString(n.Message)
+	} else if err := e.body.Execute(&body, n); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	msg.WriteString("From: " + e.from + "\r\n")
+	msg.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	msg.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", strings.TrimSpace(subject.String())) + "\r\n")
+	msg.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	w := quotedprintable.NewWriter(&msg)
+	if _, err := w.Write([]byte(body.String())); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return msg.Bytes(), nil
+}
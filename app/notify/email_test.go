@@ -0,0 +1,145 @@
+package notify
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpSession is a message received by the test SMTP server
+type smtpSession struct {
+	data       string
+	from       string
+	recipients []string
+}
+
+// startSMTPServer will start a plain SMTP server accepting a single message
+func startSMTPServer(t *testing.T) (string, <-chan *smtpSession) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+
+	sessions := make(chan *smtpSession, 1)
+	go func() {
+		conn, acceptErr := l.Accept()
+		if acceptErr != nil {
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		tp := textproto.NewConn(conn)
+		session := &smtpSession{}
+		_ = tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, readErr := tp.ReadLine()
+			if readErr != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); cmd {
+			case "EHLO", "HELO":
+				_ = tp.PrintfLine("250 localhost")
+			case "MAIL":
+				session.from = line
+				_ = tp.PrintfLine("250 OK")
+			case "RCPT":
+				session.recipients = append(session.recipients, line)
+				_ = tp.PrintfLine("250 OK")
+			case "DATA":
+				_ = tp.PrintfLine("354 Go ahead")
+				data, _ := tp.ReadDotLines()
+				session.data = strings.Join(data, "\n")
+				_ = tp.PrintfLine("250 OK")
+			case "QUIT":
+				_ = tp.PrintfLine("221 Bye")
+				sessions <- session
+				return
+			default:
+				_ = tp.PrintfLine("502 Not implemented")
+			}
+		}
+	}()
+	return l.Addr().String(), sessions
+}
+
+// TestNewEmail will test the method newEmail()
+func TestNewEmail(t *testing.T) {
+	t.Parallel()
+
+	valid := config.EmailConfig{Address: "smtp.example.com:587", From: "alerts@example.com", To: []string{"ops@example.com"}}
+	e, err := newEmail(valid)
+	require.NoError(t, err)
+	assert.Equal(t, EmailTLSStartTLS, e.tlsMode)
+	assert.Equal(t, "smtp.example.com", e.host)
+
+	invalid := valid
+	invalid.Address = "smtp.example.com"
+	_, err = newEmail(invalid)
+	require.Error(t, err)
+
+	invalid = valid
+	invalid.TLS = "ssl"
+	_, err = newEmail(invalid)
+	require.ErrorIs(t, err, ErrInvalidEmailTLS)
+
+	invalid = valid
+	invalid.From = ""
+	_, err = newEmail(invalid)
+	require.ErrorIs(t, err, ErrEmailNoSender)
+
+	invalid = valid
+	invalid.To = nil
+	_, err = newEmail(invalid)
+	require.ErrorIs(t, err, ErrEmailNoRecipients)
+
+	invalid = valid
+	invalid.Recipients = map[string][]string{"urgent": {"oncall@example.com"}}
+	_, err = newEmail(invalid)
+	require.ErrorIs(t, err, ErrInvalidSeverity)
+}
+
+// TestEmail_Send will test the method Send()
+func TestEmail_Send(t *testing.T) {
+	t.Parallel()
+
+	address, sessions := startSMTPServer(t)
+	e, err := newEmail(config.EmailConfig{
+		Address:    address,
+		From:       "alerts@example.com",
+		Recipients: map[string][]string{"critical": {"oncall@example.com", "cto@example.com"}},
+		TLS:        EmailTLSNone,
+		To:         []string{"ops@example.com"},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, e.Send(ctx, &Notification{
+		Error:    "rpc error",
+		Event:    "alert.enforced",
+		Sequence: 42,
+		Severity: SeverityCritical,
+		Summary:  "Informational: hello",
+		Time:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Title:    "Alert 42 (Informational) failed on the node",
+	}))
+
+	session := <-sessions
+	assert.Equal(t, "MAIL FROM:<alerts@example.com>", session.from)
+	assert.Equal(t, []string{"RCPT TO:<oncall@example.com>", "RCPT TO:<cto@example.com>"}, session.recipients)
+	assert.Contains(t, session.data, "To: oncall@example.com, cto@example.com")
+	assert.Contains(t, session.data, "Subject: [alert-system] [critical] Alert 42 (Informational) failed on the node")
+	assert.Contains(t, session.data, "Informational: hello")
+	assert.Contains(t, session.data, "Error: rpc error")
+	assert.Contains(t, session.data, "Sequence: 42")
+	assert.Contains(t, session.data, "Time:     2024-01-02T03:04:05Z")
+}
@@ -0,0 +1,36 @@
+package notify
+
+import "errors"
+
+// Notification errors
+var (
+	ErrAWSNoRegion         = errors.New("aws region is required (config, resource or AWS_REGION)")
+	ErrAWSRequest          = errors.New("aws request failed")
+	ErrEmailNoRecipients   = errors.New("email requires recipients (to or per severity recipients)")
+	ErrEmailNoSender       = errors.New("email requires a from address")
+	ErrInvalidEvent        = errors.New("notification event must be alert.received, alert.verified, alert.enforced, node.healthy, node.unhealthy, peer.banned or peer.unbanned")
+	ErrInvalidEmailTLS     = errors.New("email tls must be starttls, tls or none")
+	ErrInvalidKafkaSASL    = errors.New("kafka sasl_mechanism must be PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512")
+	ErrInvalidTemplate     = errors.New("notification template is invalid")
+	ErrInvalidMQTTQoS      = errors.New("mqtt qos must be 0, 1 or 2")
+	ErrInvalidSNSTopicARN  = errors.New("sns topic_arn must be arn:<partition>:sns:<region>:<account>:<topic>")
+	ErrInvalidSQSQueueURL  = errors.New("sqs queue_url must be https://sqs.<region>.amazonaws.com/<account>/<queue>")
+	ErrInvalidRule         = errors.New("notification rule is invalid")
+	ErrInvalidSeverity     = errors.New("notification severity must be info, warning or critical")
+	ErrKafkaBroker         = errors.New("kafka broker error")
+	ErrKafkaSASL           = errors.New("kafka sasl authentication failed")
+	ErrMatrixNoAccessToken = errors.New("matrix homeserver_url requires an access_token")
+	ErrMatrixNoRooms       = errors.New("matrix homeserver_url requires room_ids")
+	ErrMQTTConnect         = errors.New("mqtt connection refused")
+	ErrNATSJetStream       = errors.New("nats jetstream publish failed")
+	ErrNATSServer          = errors.New("nats server error")
+	ErrNoNotifier          = errors.New("notification route requires a notifier")
+	ErrQueueFull           = errors.New("notification queue is full")
+	ErrRateLimited         = errors.New("notification rate cap reached")
+	ErrSendFailed          = errors.New("notification send failed")
+	ErrSlackNoChannel      = errors.New("slack bot_token requires a channel")
+	ErrTelegramNoChats     = errors.New("telegram bot_token requires chat_ids")
+	ErrTwilioNoAuthToken   = errors.New("twilio account_sid requires an auth_token")
+	ErrTwilioNoRecipients  = errors.New("twilio account_sid requires to numbers")
+	ErrTwilioNoSender      = errors.New("twilio account_sid requires a from number or messaging service")
+)
@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// DefaultKafkaClientID is the client ID of the producer (shown in the broker logs and quotas)
+const DefaultKafkaClientID = "alert-system"
+
+// DefaultKafkaTopic is the topic the events are published to
+const DefaultKafkaTopic = "alert-system"
+
+// Kafka SASL mechanisms
+const (
+	KafkaSASLPlain       = "PLAIN"
+	KafkaSASLScramSHA256 = "SCRAM-SHA-256"
+	KafkaSASLScramSHA512 = "SCRAM-SHA-512"
+)
+
+// kafka publishes the events to a Kafka topic (a JSON record keyed by the alert sequence)
+type kafka struct {
+	topic  string
+	writer *kafkago.Writer
+}
+
+// newKafka will create the Kafka channel (the producer connects to the brokers on the first event)
+func newKafka(conf config.KafkaConfig) (*kafka, error) {
+	mechanism, err := kafkaSASL(conf)
+	if err != nil {
+		return nil, err
+	}
+	transport := &kafkago.Transport{
+		ClientID: conf.ClientID,
+		SASL:     mechanism,
+	}
+	if len(transport.ClientID) == 0 {
+		transport.ClientID = DefaultKafkaClientID
+	}
+	if conf.TLS {
+		transport.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	topic := conf.Topic
+	if len(topic) == 0 {
+		topic = DefaultKafkaTopic
+	}
+	return &kafka{
+		topic: topic,
+		writer: &kafkago.Writer{
+			Addr:                   kafkago.TCP(conf.Brokers...),
+			AllowAutoTopicCreation: true,                       // If enabled on the brokers
+			Balancer:               &kafkago.Murmur2Balancer{}, // Same partition as the Java client for the key
+			BatchSize:              1,                          // Sent in order, one at a time
+			MaxAttempts:            1,                          // Retried by the notification service
+			RequiredAcks:           kafkago.RequireAll,
+			Topic:                  topic,
+			Transport:              transport,
+		},
+	}, nil
+}
+
+// kafkaSASL will return the SASL mechanism of the config (nil if there is no authentication)
+func kafkaSASL(conf config.KafkaConfig) (sasl.Mechanism, error) {
+	switch strings.ToUpper(conf.SASLMechanism) {
+	case "":
+		return nil, nil
+	case KafkaSASLPlain:
+		return plain.Mechanism{Username: conf.Username, Password: conf.Password}, nil
+	case KafkaSASLScramSHA256:
+		return scram.Mechanism(scram.SHA256, conf.Username, conf.Password)
+	case KafkaSASLScramSHA512:
+		return scram.Mechanism(scram.SHA512, conf.Username, conf.Password)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrInvalidKafkaSASL, conf.SASLMechanism)
+}
+
+// Name will return the name of the channel
+func (k *kafka) Name() string {
+	return "kafka"
+}
+
+// Send will publish the notification to the partition of its key (acknowledged by all the in-sync replicas)
+func (k *kafka) Send(ctx context.Context, n *Notification) error {
+	msg, err := kafkaMessage(n)
+	if err != nil {
+		return err
+	}
+	if err = k.writer.WriteMessages(ctx, msg); err != nil {
+		var brokerErr kafkago.Error
+		if errors.Is(err, kafkago.SASLAuthenticationFailed) {
+			return fmt.Errorf("%w: %w", ErrKafkaSASL, err)
+		} else if errors.As(err, &brokerErr) {
+			return fmt.Errorf("%w: kafka topic %s: %w", ErrKafkaBroker, k.topic, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// Close will close the producer connections
+func (k *kafka) Close() error {
+	return k.writer.Close()
+}
+
+// kafkaMessage will return the record of the notification (keyed by the ordering key, with the event and severity
+// headers)
+func kafkaMessage(n *Notification) (kafkago.Message, error) {
+	value, err := json.Marshal(n)
+	if err != nil {
+		return kafkago.Message{}, err
+	}
+	return kafkago.Message{
+		Headers: []kafkago.Header{
+			{Key: "event", Value: []byte(n.Event)},
+			{Key: "severity", Value: []byte(n.Severity.String())},
+		},
+		Key:   orderingKey(n),
+		Time:  n.Time,
+		Value: value,
+	}, nil
+}
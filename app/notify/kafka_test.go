@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewKafka will test the method newKafka()
+func TestNewKafka(t *testing.T) {
+	t.Parallel()
+
+	k, err := newKafka(config.KafkaConfig{Brokers: []string{"localhost:9092"}, SASLMechanism: "scram-sha-512"})
+	require.NoError(t, err)
+	assert.Equal(t, "SCRAM-SHA-512", k.writer.Transport.(*kafkago.Transport).SASL.Name())
+	assert.Equal(t, DefaultKafkaTopic, k.writer.Topic)
+	assert.Equal(t, DefaultKafkaClientID, k.writer.Transport.(*kafkago.Transport).ClientID)
+	assert.Equal(t, kafkago.RequireAll, k.writer.RequiredAcks)
+	assert.Nil(t, k.writer.Transport.(*kafkago.Transport).TLS)
+
+	k, err = newKafka(config.KafkaConfig{
+		Brokers: []string{"localhost:9092"}, ClientID: "node-1", Password: "secret", SASLMechanism: KafkaSASLPlain,
+		TLS: true, Topic: "alerts", Username: "user",
+	})
+	require.NoError(t, err)
+	transport := k.writer.Transport.(*kafkago.Transport)
+	assert.Equal(t, plain.Mechanism{Username: "user", Password: "secret"}, transport.SASL)
+	assert.Equal(t, "node-1", transport.ClientID)
+	assert.NotNil(t, transport.TLS)
+	assert.Equal(t, "alerts", k.writer.Topic)
+	require.NoError(t, k.Close())
+
+	_, err = newKafka(config.KafkaConfig{Brokers: []string{"localhost:9092"}, SASLMechanism: "GSSAPI"})
+	require.ErrorIs(t, err, ErrInvalidKafkaSASL)
+}
+
+// TestKafkaMessage will test the method kafkaMessage()
+func TestKafkaMessage(t *testing.T) {
+	t.Parallel()
+
+	n := &Notification{
+		AlertName: "Informational", Event: events.AlertVerified, Sequence: 21, Severity: SeverityInfo,
+		Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Title: "Alert 21 (Informational) verified",
+	}
+	msg, err := kafkaMessage(n)
+	require.NoError(t, err)
+	assert.Equal(t, "21", string(msg.Key))
+	assert.Equal(t, n.Time, msg.Time)
+	assert.Equal(t, []kafkago.Header{
+		{Key: "event", Value: []byte("alert.verified")},
+		{Key: "severity", Value: []byte("info")},
+	}, msg.Headers)
+	published := &Notification{}
+	require.NoError(t, json.Unmarshal(msg.Value, published))
+	assert.Equal(t, *n, *published)
+
+	// Same partition as the Java client (murmur2 of "21" is -973932308)
+	assert.Equal(t, 3, (&kafkago.Murmur2Balancer{}).Balance(msg, 0, 1, 2, 3, 4, 5, 6))
+}
+
+// TestKafka_Send will test the method Send()
+func TestKafka_Send(t *testing.T) {
+	t.Parallel()
+
+	t.Run("node events are keyed by node", func(t *testing.T) {
+		assert.Equal(t, "alert-system/node/localhost:8332", string(orderingKey(&Notification{Event: events.NodeUnhealthy, Node: "localhost:8332"})))
+	})
+
+	t.Run("brokers are not available", func(t *testing.T) {
+		k, err := newKafka(config.KafkaConfig{Brokers: []string{"127.0.0.1:1"}})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = k.Close()
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		require.Error(t, k.Send(ctx, &Notification{Event: events.AlertVerified, Sequence: 42}))
+	})
+}
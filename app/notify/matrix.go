@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+)
+
+// matrixMessage is an m.room.message event (plain text body and HTML formatted body)
+type matrixMessage struct {
+	Body          string `json:"body"`
+	Format        string `json:"format"`
+	FormattedBody string `json:"formatted_body"`
+	MsgType       string `json:"msgtype"`
+}
+
+// matrixError is an error of the client-server API
+type matrixError struct {
+	ErrCode string `json:"errcode"`
+	Error   string `json:"error"`
+}
+
+// matrix sends the notifications to a Matrix room as a bot user
+type matrix struct {
+	accessToken string
+	httpClient  config.HTTPInterface
+	roomID      string
+	url         string // Send URL of the room (without the transaction ID)
+}
+
+// newMatrix will create a Matrix channel for each room (retried separately)
+func newMatrix(conf config.MatrixConfig, httpClient config.HTTPInterface) ([]*matrix, error) {
+	if len(conf.AccessToken) == 0 {
+		return nil, ErrMatrixNoAccessToken
+	} else if len(conf.RoomIDs) == 0 {
+		return nil, ErrMatrixNoRooms
+	}
+	rooms := make([]*matrix, 0, len(conf.RoomIDs))
+	for _, roomID := range conf.RoomIDs {
+		rooms = append(rooms, &matrix{
+			accessToken: conf.AccessToken,
+			httpClient:  httpClient,
+			roomID:      roomID,
+			url: strings.TrimRight(conf.HomeserverURL, "/") + "/_matrix/client/v3/rooms/" +
+				url.PathEscape(roomID) + "/send/m.room.message/",
+		})
+	}
+	return rooms, nil
+}
+
+// Name will return the name of the channel
+func (m *matrix) Name() string {
+	return "matrix"
+}
+
+// Send will send the notification to the room
+// The transaction ID is derived from the notification, the homeserver ignores the retries of a delivered message
+func (m *matrix) Send(ctx context.Context, n *Notification) error {
+	formatted := "<b>[" + n.Severity.String() + "] " + html.EscapeString(n.Title) + "</b>"
+	if len(n.Error) > 0 {
+		formatted += "<br>Error: <code>" + html.EscapeString(n.Error) + "</code>"
+	}
+	if len(n.PeerID) > 0 {
+		formatted += "<br>Peer: <code>" + html.EscapeString(n.PeerID) + "</code>"
+	}
+	if len(n.Summary) > 0 {
+		formatted += "<br>" + html.EscapeString(n.Summary)
+	}
+
+	msg := &matrixMessage{Body: n.Text(), Format: "org.matrix.custom.html", FormattedBody: formatted, MsgType: "m.text"}
+	if len(n.Message) > 0 { // The template renders the HTML body
+		msg.Body, msg.FormattedBody = n.Message, n.Message
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	txnID := sha256.Sum256([]byte(m.roomID + "/" + messageID(n)))
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(
+		ctx, http.MethodPut, m.url+hex.EncodeToString(txnID[:16]), bytes.NewReader(body),
+	); err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+m.accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	var res *http.Response
+	if res, err = m.httpClient.Do(req); err != nil {
+		return fmt.Errorf("matrix room %s: %w", m.roomID, err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	// The client-server API reports the errors in the body
+	result := &matrixError{}
+	if err = json.NewDecoder(io.LimitReader(res.Body, 1<<16)).Decode(result); err != nil || len(result.ErrCode) == 0 {
+		return fmt.Errorf("%w: matrix room %s: status code %d", ErrSendFailed, m.roomID, res.StatusCode)
+	}
+	return fmt.Errorf("%w: matrix room %s: %s: %s", ErrSendFailed, m.roomID, result.ErrCode, result.Error)
+}
@@ -0,0 +1,103 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewMatrix will test the method newMatrix()
+func TestNewMatrix(t *testing.T) {
+	t.Parallel()
+
+	_, err := newMatrix(config.MatrixConfig{HomeserverURL: "https://matrix.org", RoomIDs: []string{"!abc:matrix.org"}}, nil)
+	require.ErrorIs(t, err, ErrMatrixNoAccessToken)
+
+	_, err = newMatrix(config.MatrixConfig{AccessToken: "secret", HomeserverURL: "https://matrix.org"}, nil)
+	require.ErrorIs(t, err, ErrMatrixNoRooms)
+
+	rooms, err := newMatrix(config.MatrixConfig{
+		AccessToken: "secret", HomeserverURL: "https://matrix.org/", RoomIDs: []string{"!abc:matrix.org", "!def:example.com"},
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, rooms, 2)
+	assert.Equal(t, "https://matrix.org/_matrix/client/v3/rooms/%21abc:matrix.org/send/m.room.message/", rooms[0].url)
+	assert.Equal(t, "!def:example.com", rooms[1].roomID)
+}
+
+// TestMatrix_Send will test the method Send()
+func TestMatrix_Send(t *testing.T) {
+	t.Parallel()
+
+	conf := config.MatrixConfig{AccessToken: "secret", HomeserverURL: "https://matrix.org", RoomIDs: []string{"!abc:matrix.org"}}
+	n := &Notification{
+		Error: "rpc <error>", Severity: SeverityCritical, Summary: "Informational: hello",
+		Time: time.Unix(1700000000, 0), Title: "Alert 42 (Informational) failed on the node",
+	}
+
+	t.Run("html message", func(t *testing.T) {
+		var paths []string
+		rooms, err := newMatrix(conf, &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, http.MethodPut, req.Method)
+				assert.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+				paths = append(paths, req.URL.EscapedPath())
+				msg := &matrixMessage{}
+				require.NoError(t, json.NewDecoder(req.Body).Decode(msg))
+				assert.Equal(t, "m.text", msg.MsgType)
+				assert.Equal(t, "org.matrix.custom.html", msg.Format)
+				assert.Equal(t, n.Text(), msg.Body)
+				assert.Equal(t, "<b>[critical] Alert 42 (Informational) failed on the node</b><br>Error: <code>rpc &lt;error&gt;</code><br>Informational: hello", msg.FormattedBody)
+				return newResponse(http.StatusOK, `{"event_id":"$abc"}`), nil
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, rooms[0].Send(context.Background(), n))
+		require.NoError(t, rooms[0].Send(context.Background(), n))
+		require.Len(t, paths, 2)
+		assert.True(t, strings.HasPrefix(paths[0], "/_matrix/client/v3/rooms/%21abc:matrix.org/send/m.room.message/"))
+		assert.Equal(t, paths[0], paths[1], "a retry must reuse the transaction ID")
+	})
+
+	t.Run("homeserver error", func(t *testing.T) {
+		rooms, err := newMatrix(conf, &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return newResponse(http.StatusForbidden, `{"errcode":"M_FORBIDDEN","error":"not in room"}`), nil
+			},
+		})
+		require.NoError(t, err)
+		err = rooms[0].Send(context.Background(), n)
+		require.ErrorIs(t, err, ErrSendFailed)
+		assert.Contains(t, err.Error(), "M_FORBIDDEN: not in room")
+	})
+
+	t.Run("unexpected response", func(t *testing.T) {
+		rooms, err := newMatrix(conf, &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return newResponse(http.StatusBadGateway, "bad gateway"), nil
+			},
+		})
+		require.NoError(t, err)
+		err = rooms[0].Send(context.Background(), n)
+		require.ErrorIs(t, err, ErrSendFailed)
+		assert.Contains(t, err.Error(), "status code 502")
+	})
+
+	t.Run("http client error", func(t *testing.T) {
+		rooms, err := newMatrix(conf, &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return nil, errors.New("HTTP client error")
+			},
+		})
+		require.NoError(t, err)
+		require.Error(t, rooms[0].Send(context.Background(), n))
+	})
+}
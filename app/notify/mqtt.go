@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/eclipse/paho.mqtt.golang/packets"
+)
+
+// DefaultMQTTTopic is the topic prefix of the events (published on <topic>/<event>, e.g. alert-system/alert/enforced)
+const DefaultMQTTTopic = "alert-system"
+
+// mqttKeepAlive is the keep alive of the connection
+const mqttKeepAlive = 60 * time.Second
+
+// mqttMessage is the compact JSON published (small enough for the embedded devices)
+type mqttMessage struct {
+	AlertType string `json:"type,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Event     string `json:"event"`
+	Node      string `json:"node,omitempty"`
+	PeerID    string `json:"peer,omitempty"`
+	Resolved  bool   `json:"resolved,omitempty"`
+	Sequence  uint32 `json:"seq,omitempty"`
+	Severity  string `json:"severity"`
+	Summary   string `json:"summary,omitempty"`
+	Time      int64  `json:"ts"` // Unix time
+}
+
+// mqtt publishes the events to an MQTT broker
+type mqtt struct {
+	client paho.Client
+	mu     sync.Mutex
+	qos    byte
+	retain bool
+	topic  string
+}
+
+// newMQTT will create the MQTT channel (connected on the first event, and again after a connection loss)
+func newMQTT(conf config.MQTTConfig) (*mqtt, error) {
+	if conf.QoS < 0 || conf.QoS > 2 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidMQTTQoS, conf.QoS)
+	}
+	m := &mqtt{
+		qos:    byte(conf.QoS),
+		retain: conf.Retain,
+		topic:  strings.TrimSuffix(conf.Topic, "/"),
+	}
+	if len(m.topic) == 0 {
+		m.topic = DefaultMQTTTopic
+	}
+
+	clientID := conf.ClientID
+	if len(clientID) == 0 { // Unique, a broker disconnects the previous client with the same ID
+		suffix := make([]byte, 6)
+		_, _ = rand.Read(suffix)
+		clientID = "alert-system-" + hex.EncodeToString(suffix)
+	}
+	options := paho.NewClientOptions().
+		SetAutoReconnect(false). // Reconnected by the next attempt of the notification service
+		SetCleanSession(true).
+		SetClientID(clientID).
+		SetKeepAlive(mqttKeepAlive).
+		SetPassword(conf.Password).
+		SetUsername(conf.Username)
+	if conf.TLS {
+		options.AddBroker("ssl://" + conf.Broker).SetTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12})
+	} else {
+		options.AddBroker("tcp://" + conf.Broker)
+	}
+	m.client = paho.NewClient(options)
+	return m, nil
+}
+
+// Name will return the name of the channel
+func (m *mqtt) Name() string {
+	return "mqtt"
+}
+
+// Send will publish the notification on the topic of its event (acknowledged as required by the QoS)
+func (m *mqtt) Send(ctx context.Context, n *Notification) error {
+	payload, err := json.Marshal(&mqttMessage{
+		AlertType: n.AlertName,
+		Error:     n.Error,
+		Event:     string(n.Event),
+		Node:      n.Node,
+		PeerID:    n.PeerID,
+		Resolved:  n.Resolved,
+		Sequence:  n.Sequence,
+		Severity:  n.Severity.String(),
+		Summary:   n.Summary,
+		Time:      n.Time.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err = m.connect(ctx); err != nil {
+		return err
+	}
+	topic := m.topic + "/" + strings.ReplaceAll(string(n.Event), ".", "/")
+	return mqttWait(ctx, m.client.Publish(topic, m.qos, m.retain, payload))
+}
+
+// connect will connect to the broker if the client is not connected (CONNECT with a clean session, then CONNACK)
+func (m *mqtt) connect(ctx context.Context) error {
+	if m.client.IsConnectionOpen() {
+		return nil
+	}
+	token := m.client.Connect()
+	if err := mqttWait(ctx, token); err != nil {
+		if code := token.(*paho.ConnectToken).ReturnCode(); code != packets.Accepted && code < packets.ErrNetworkError {
+			return fmt.Errorf("%w: %w (return code %d)", ErrMQTTConnect, err, code)
+		}
+		return err
+	}
+	return nil
+}
+
+// Close will disconnect from the broker
+func (m *mqtt) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.client.IsConnected() {
+		m.client.Disconnect(uint(time.Second / time.Millisecond))
+	}
+	return nil
+}
+
+// mqttWait will wait for the token to complete (or the context to be done)
+func mqttWait(ctx context.Context, token paho.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,155 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// publishedHook records the messages published to the broker
+type publishedHook struct {
+	mochi.HookBase
+	published chan packets.Packet
+}
+
+// ID will return the ID of the hook
+func (h *publishedHook) ID() string {
+	return "published"
+}
+
+// Provides will return true for the OnPublish hook
+func (h *publishedHook) Provides(b byte) bool {
+	return bytes.Contains([]byte{mochi.OnPublish}, []byte{b})
+}
+
+// OnPublish will record the message
+func (h *publishedHook) OnPublish(_ *mochi.Client, pk packets.Packet) (packets.Packet, error) {
+	h.published <- pk
+	return pk, nil
+}
+
+// newTestMQTTBroker will start an embedded broker accepting the user (and return its address and published messages)
+func newTestMQTTBroker(t *testing.T, username, password string) (string, chan packets.Packet) {
+	broker := mochi.New(&mochi.Options{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	require.NoError(t, broker.AddHook(new(auth.Hook), &auth.Options{
+		Ledger: &auth.Ledger{Auth: auth.AuthRules{{Username: auth.RString(username), Password: auth.RString(password), Allow: true}}},
+	}))
+	hook := &publishedHook{published: make(chan packets.Packet, 10)}
+	require.NoError(t, broker.AddHook(hook, nil))
+	listener := listeners.NewTCP("tcp", "127.0.0.1:0", nil)
+	require.NoError(t, broker.AddListener(listener))
+	go func() {
+		_ = broker.Serve()
+	}()
+	t.Cleanup(func() {
+		_ = broker.Close()
+	})
+	return listener.Address(), hook.published
+}
+
+// TestNewMQTT will test the method newMQTT()
+func TestNewMQTT(t *testing.T) {
+	t.Parallel()
+
+	m, err := newMQTT(config.MQTTConfig{Broker: "localhost:1883", Topic: "bsv/"})
+	require.NoError(t, err)
+	assert.Equal(t, "bsv", m.topic)
+	options := m.client.OptionsReader()
+	assert.Regexp(t, "^alert-system-[0-9a-f]{12}$", options.ClientID())
+	assert.Equal(t, "tcp://localhost:1883", options.Servers()[0].String())
+
+	m, err = newMQTT(config.MQTTConfig{Broker: "localhost:8883", ClientID: "node-1", TLS: true})
+	require.NoError(t, err)
+	options = m.client.OptionsReader()
+	assert.Equal(t, "node-1", options.ClientID())
+	assert.Equal(t, "ssl://localhost:8883", options.Servers()[0].String())
+	assert.NotNil(t, options.TLSConfig())
+
+	_, err = newMQTT(config.MQTTConfig{Broker: "localhost:1883", QoS: 3})
+	require.ErrorIs(t, err, ErrInvalidMQTTQoS)
+}
+
+// TestMQTT_Send will test the method Send()
+func TestMQTT_Send(t *testing.T) {
+	t.Parallel()
+
+	n := &Notification{
+		AlertName: "Informational", Event: events.AlertEnforced, Sequence: 42, Severity: SeverityInfo, Summary: "Informational: hello",
+		Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Title: "Alert 42 (Informational) enforced on the node",
+	}
+	ctx := func(t *testing.T) context.Context {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		t.Cleanup(cancel)
+		return ctx
+	}
+	newChannel := func(t *testing.T, conf config.MQTTConfig) *mqtt {
+		m, err := newMQTT(conf)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = m.Close()
+		})
+		return m
+	}
+
+	for _, qos := range []int{0, 1, 2} {
+		qos := qos
+		t.Run("qos "+strconv.Itoa(qos), func(t *testing.T) {
+			address, published := newTestMQTTBroker(t, "alerts", "secret")
+			m := newChannel(t, config.MQTTConfig{Broker: address, Password: "secret", QoS: qos, Retain: true, Username: "alerts"})
+			require.NoError(t, m.Send(ctx(t), n))
+
+			pk := <-published
+			assert.Equal(t, "alert-system/alert/enforced", pk.TopicName)
+			assert.Equal(t, byte(qos), pk.FixedHeader.Qos)
+			assert.True(t, pk.FixedHeader.Retain)
+			message := &mqttMessage{}
+			require.NoError(t, json.Unmarshal(pk.Payload, message))
+			assert.Equal(t, mqttMessage{
+				AlertType: "Informational", Event: "alert.enforced", Sequence: 42, Severity: "info",
+				Summary: "Informational: hello", Time: 1704164645,
+			}, *message)
+
+			// The connection is reused
+			require.NoError(t, m.Send(ctx(t), n))
+			<-published
+		})
+	}
+
+	t.Run("connection refused", func(t *testing.T) {
+		address, _ := newTestMQTTBroker(t, "alerts", "secret")
+		m := newChannel(t, config.MQTTConfig{Broker: address, Password: "secret", Username: "other"})
+		err := m.Send(ctx(t), n)
+		require.ErrorIs(t, err, ErrMQTTConnect)
+		assert.Contains(t, err.Error(), "return code")
+	})
+
+	t.Run("broker is not available", func(t *testing.T) {
+		m := newChannel(t, config.MQTTConfig{Broker: "127.0.0.1:1"})
+		require.Error(t, m.Send(ctx(t), n))
+	})
+
+	t.Run("reconnected after a connection loss", func(t *testing.T) {
+		address, published := newTestMQTTBroker(t, "alerts", "secret")
+		m := newChannel(t, config.MQTTConfig{Broker: address, Password: "secret", QoS: 1, Username: "alerts"})
+		require.NoError(t, m.Send(ctx(t), n))
+		<-published
+		require.NoError(t, m.Close())
+
+		require.NoError(t, m.Send(ctx(t), n))
+		<-published
+	})
+}
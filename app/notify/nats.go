@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	natsgo "github.com/nats-io/nats.go"
+)
+
+// DefaultNATSSubject is the subject prefix of the events (published on <subject>.<event>, e.g. alert-system.alert.enforced)
+const DefaultNATSSubject = "alert-system"
+
+// nats publishes the events to NATS subjects (optionally acknowledged by JetStream)
+type nats struct {
+	conn      *natsgo.Conn
+	jetStream bool
+	mu        sync.Mutex
+	options   []natsgo.Option
+	servers   string
+	subject   string
+}
+
+// newNATS will create the NATS channel (connected on the first event)
+func newNATS(conf config.NATSConfig) *nats {
+	n := &nats{
+		jetStream: conf.JetStream,
+		options: []natsgo.Option{
+			natsgo.Name("alert-system"),
+			natsgo.ReconnectBufSize(-1), // Publishing fails while reconnecting (retried by the notification service)
+		},
+		servers: strings.Join(conf.Servers, ","),
+		subject: strings.TrimSuffix(conf.Subject, "."),
+	}
+	if len(n.subject) == 0 {
+		n.subject = DefaultNATSSubject
+	}
+	if len(conf.Username) > 0 {
+		n.options = append(n.options, natsgo.UserInfo(conf.Username, conf.Password))
+	}
+	if len(conf.Token) > 0 {
+		n.options = append(n.options, natsgo.Token(conf.Token))
+	}
+	if conf.TLS {
+		n.options = append(n.options, natsgo.Secure(&tls.Config{MinVersion: tls.VersionTLS12}))
+	}
+	return n
+}
+
+// Name will return the name of the channel
+func (n *nats) Name() string {
+	return "nats"
+}
+
+// Send will publish the notification on the subject of its event
+// With JetStream, the publish is acknowledged once the stream stored it (at-least-once, the retries are
+// deduplicated by the message ID), otherwise the connection is flushed and the errors of the server are returned
+func (n *nats) Send(ctx context.Context, notification *Notification) error {
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	var conn *natsgo.Conn
+	if conn, err = n.connect(); err != nil {
+		return err
+	}
+
+	msg := natsgo.NewMsg(n.subject + "." + string(notification.Event))
+	msg.Data = payload
+	msg.Header.Set("Alert-System-Event", string(notification.Event))
+	msg.Header.Set("Alert-System-Severity", notification.Severity.String())
+	msg.Header.Set(natsgo.MsgIdHdr, messageID(notification))
+	if n.jetStream {
+		return n.publishJetStream(ctx, conn, msg)
+	}
+
+	lastErr := conn.LastError()
+	if err = conn.PublishMsg(msg); err != nil {
+		return fmt.Errorf("%w: %w", ErrNATSServer, err)
+	}
+	if _, ok := ctx.Deadline(); ok {
+		err = conn.FlushWithContext(ctx)
+	} else {
+		err = conn.FlushTimeout(DefaultSendTimeout)
+	}
+	if err != nil {
+		return err
+	}
+
+	// A permissions violation of the publish is only reported as the last error (before the PONG of the flush)
+	if err = conn.LastError(); err != nil && err != lastErr { //nolint:errorlint // Each violation is a new error
+		return fmt.Errorf("%w: %w", ErrNATSServer, err)
+	}
+	return nil
+}
+
+// publishJetStream will publish the message and wait for the acknowledgement of the stream
+func (n *nats) publishJetStream(ctx context.Context, conn *natsgo.Conn, msg *natsgo.Msg) error {
+	js, err := conn.JetStream()
+	if err != nil {
+		return err
+	}
+	if _, err = js.PublishMsg(msg, natsgo.Context(ctx)); err != nil {
+		if errors.Is(err, natsgo.ErrNoStreamResponse) || errors.Is(err, natsgo.ErrNoResponders) {
+			return fmt.Errorf("%w: no stream for the subject %s", ErrNATSJetStream, msg.Subject)
+		}
+		return fmt.Errorf("%w: %w", ErrNATSJetStream, err)
+	}
+	return nil
+}
+
+// connect will return the connection (connecting to the first available server if it is closed)
+func (n *nats) connect() (*natsgo.Conn, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.conn != nil && !n.conn.IsClosed() {
+		return n.conn, nil
+	}
+	conn, err := natsgo.Connect(n.servers, n.options...)
+	if err != nil {
+		if errors.Is(err, natsgo.ErrAuthorization) {
+			return nil, fmt.Errorf("%w: %w", ErrNATSServer, err)
+		}
+		return nil, fmt.Errorf("nats servers are not available: %w", err)
+	}
+	n.conn = conn
+	return conn, nil
+}
+
+// Close will close the connection
+func (n *nats) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.conn != nil {
+		n.conn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/nats-io/nats-server/v2/server"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestNATSServer will start an embedded NATS server with JetStream (no auth if there are no users)
+func newTestNATSServer(t *testing.T, users ...*server.User) *server.Server {
+	s, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		JetStream: true,
+		NoLog:     true,
+		NoSigs:    true,
+		Port:      -1,
+		StoreDir:  t.TempDir(),
+		Users:     users,
+	})
+	require.NoError(t, err)
+	go s.Start()
+	require.True(t, s.ReadyForConnections(5*time.Second))
+	t.Cleanup(s.Shutdown)
+	return s
+}
+
+// natsAddress will return the host:port of the server
+func natsAddress(s *server.Server) string {
+	return s.Addr().String()
+}
+
+// natsClient will connect a client to the server
+func natsClient(t *testing.T, s *server.Server, options ...natsgo.Option) *natsgo.Conn {
+	conn, err := natsgo.Connect(s.ClientURL(), options...)
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+	return conn
+}
+
+// TestNATS_Send will test the method Send()
+func TestNATS_Send(t *testing.T) {
+	t.Parallel()
+
+	n := &Notification{
+		AlertName: "Informational", Event: events.AlertEnforced, Sequence: 42, Severity: SeverityInfo,
+		Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Title: "Alert 42 (Informational) enforced on the node",
+	}
+	ctx := func(t *testing.T) context.Context {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		t.Cleanup(cancel)
+		return ctx
+	}
+	newChannel := func(t *testing.T, conf config.NATSConfig) *nats {
+		c := newNATS(conf)
+		t.Cleanup(func() {
+			_ = c.Close()
+		})
+		return c
+	}
+
+	t.Run("publish on the event subject", func(t *testing.T) {
+		s := newTestNATSServer(t, &server.User{Username: "alerts", Password: "secret"})
+		sub, err := natsClient(t, s, natsgo.UserInfo("alerts", "secret")).SubscribeSync("bsv.>")
+		require.NoError(t, err)
+
+		c := newChannel(t, config.NATSConfig{
+			Password: "secret", Servers: []string{"127.0.0.1:1", natsAddress(s)}, Subject: "bsv.", Username: "alerts",
+		})
+		require.NoError(t, c.Send(ctx(t), n))
+
+		published, err := sub.NextMsg(5 * time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, "bsv.alert.enforced", published.Subject)
+		assert.Equal(t, "alert.enforced", published.Header.Get("Alert-System-Event"))
+		assert.Equal(t, "info", published.Header.Get("Alert-System-Severity"))
+		assert.Equal(t, "alert-system/alert/42/alert.enforced/1704164645000000000", published.Header.Get(natsgo.MsgIdHdr))
+		decoded := &Notification{}
+		require.NoError(t, json.Unmarshal(published.Data, decoded))
+		assert.Equal(t, *n, *decoded)
+
+		// The connection is reused
+		require.NoError(t, c.Send(ctx(t), n))
+		assert.Equal(t, uint64(2), c.conn.Stats().OutMsgs)
+	})
+
+	t.Run("jetstream acknowledgement", func(t *testing.T) {
+		s := newTestNATSServer(t)
+		js, err := natsClient(t, s).JetStream()
+		require.NoError(t, err)
+		_, err = js.AddStream(&natsgo.StreamConfig{
+			Discard: natsgo.DiscardNew, MaxMsgs: 1, Name: "ALERTS", Subjects: []string{"alert-system.alert.>"},
+		})
+		require.NoError(t, err)
+
+		// The retries are deduplicated by the message ID
+		c := newChannel(t, config.NATSConfig{JetStream: true, Servers: []string{natsAddress(s)}})
+		require.NoError(t, c.Send(ctx(t), n))
+		require.NoError(t, c.Send(ctx(t), n))
+		info, err := js.StreamInfo("ALERTS")
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), info.State.Msgs)
+
+		// The stream is full
+		next := *n
+		next.Sequence = 43
+		err = c.Send(ctx(t), &next)
+		require.ErrorIs(t, err, ErrNATSJetStream)
+		assert.Contains(t, err.Error(), "maximum messages exceeded")
+
+		err = c.Send(ctx(t), &Notification{Event: events.NodeUnhealthy, Node: "localhost:8332"})
+		require.ErrorIs(t, err, ErrNATSJetStream)
+		assert.Contains(t, err.Error(), "no stream")
+	})
+
+	t.Run("server errors", func(t *testing.T) {
+		s := newTestNATSServer(t,
+			&server.User{Username: "alerts"},
+			&server.User{
+				Username:    "readonly",
+				Permissions: &server.Permissions{Publish: &server.SubjectPermission{Deny: []string{">"}}},
+			},
+		)
+		c := newChannel(t, config.NATSConfig{Servers: []string{natsAddress(s)}, Username: "readonly"})
+		err := c.Send(ctx(t), n)
+		require.ErrorIs(t, err, ErrNATSServer)
+		assert.Contains(t, err.Error(), "Permissions Violation")
+
+		c = newChannel(t, config.NATSConfig{Servers: []string{natsAddress(s)}, Username: "other"})
+		require.ErrorIs(t, c.Send(ctx(t), n), ErrNATSServer)
+	})
+
+	t.Run("servers are not available", func(t *testing.T) {
+		c := newChannel(t, config.NATSConfig{Servers: []string{"127.0.0.1:1"}})
+		require.Error(t, c.Send(ctx(t), n))
+	})
+}
+
+// TestNATS_Close will test the method Close()
+func TestNATS_Close(t *testing.T) {
+	t.Parallel()
+
+	s := newTestNATSServer(t)
+	c := newNATS(config.NATSConfig{Servers: []string{natsAddress(s)}})
+	require.NoError(t, c.Close())
+
+	require.NoError(t, c.Send(context.Background(), &Notification{Event: events.AlertEnforced, Sequence: 1}))
+	require.NoError(t, c.Close())
+	assert.True(t, c.conn.IsClosed())
+}
@@ -0,0 +1,228 @@
+// Package notify sends human-readable notifications of the alert and node events to the channels
+// the operators already watch (Slack, ...)
+// The events are filtered per channel (by event and severity), queued and delivered with retries
+// Embedders can add their own channels with Register (a Notifier and its routes)
+package notify
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/bitcoin-sv/alert-system/app/models"
+)
+
+// Severity is the severity of a notification
+type Severity int
+
+// Severities (in increasing order)
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+// Severity names (used in the config)
+const (
+	severityCritical = "critical"
+	severityInfo     = "info"
+	severityWarning  = "warning"
+)
+
+// String will return the name of the severity
+func (s Severity) String() string {
+	switch s {
+	case SeverityCritical:
+		return severityCritical
+	case SeverityWarning:
+		return severityWarning
+	default:
+		return severityInfo
+	}
+}
+
+// MarshalText will encode the severity as its name
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText will decode the severity from its name
+func (s *Severity) UnmarshalText(text []byte) error {
+	severity, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = severity
+	return nil
+}
+
+// ParseSeverity will parse the severity name (info if empty)
+func ParseSeverity(name string) (Severity, error) {
+	switch strings.ToLower(name) {
+	case "", severityInfo:
+		return SeverityInfo, nil
+	case severityWarning:
+		return SeverityWarning, nil
+	case severityCritical:
+		return SeverityCritical, nil
+	}
+	return SeverityInfo, fmt.Errorf("%w: %s", ErrInvalidSeverity, name)
+}
+
+// AlertSeverity will return the severity of the alert type
+// Alerts that change the consensus (invalidate block, confiscation) are critical
+func AlertSeverity(alertType models.AlertType) Severity {
+	switch alertType {
+	case models.AlertTypeConfiscateUtxo, models.AlertTypeInvalidateBlock:
+		return SeverityCritical
+	case models.AlertTypeBanPeer, models.AlertTypeFreezeUtxo, models.AlertTypeSetKeys,
+		models.AlertTypeUnbanPeer, models.AlertTypeUnfreezeUtxo:
+		return SeverityWarning
+	default:
+		return SeverityInfo
+	}
+}
+
+// DefaultEvents are the events notified if a channel does not list its events
+var DefaultEvents = []events.Type{
+	events.AlertEnforced,
+	events.NodeHealthy,
+	events.NodeUnhealthy,
+}
+
+// DefaultStreamEvents are the events published to the streaming channels (Kafka, NATS, ...)
+// if they do not list their events (every event)
+var DefaultStreamEvents = []string{
+	string(events.AlertReceived),
+	string(events.AlertVerified),
+	string(events.AlertEnforced),
+	string(events.NodeHealthy),
+	string(events.NodeUnhealthy),
+	string(events.PeerBanned),
+	string(events.PeerUnbanned),
+}
+
+// Notification is an event to notify (with its human-readable title and summary)
+type Notification struct {
+	AlertName string      `json:"alert_name,omitempty"` // Alert type name (e.g. Invalidate Block)
+	AlertType uint32      `json:"alert_type,omitempty"` // Alert type
+	Error     string      `json:"error,omitempty"`      // Why the alert action failed or the node is unhealthy
+	Event     events.Type `json:"event"`                // Event type
+	Hash      string      `json:"hash,omitempty"`       // Alert hash
+	ID        string      `json:"id,omitempty"`         // Event ID (the outbox dedup key, the same if the event is replayed)
+	Message   string      `json:"message,omitempty"`    // Message rendered from the templates (the channel formats its own if empty)
+	Node      string      `json:"node,omitempty"`       // Node RPC host
+	Payload   interface{} `json:"-"`                    // Decoded alert message (e.g. *models.AlertMessageInvalidateBlock), for the templates
+	PeerID    string      `json:"peer_id,omitempty"`    // Peer the alert was received from, or the banned peer
+	Raw       string      `json:"-"`                    // Signed alert (hex), forwarded as is (Teranode)
+	Resolved  bool        `json:"resolved,omitempty"`   // Clears an earlier notification (node is healthy again, retried alert was enforced)
+	Sequence  uint32      `json:"sequence,omitempty"`   // Alert sequence number
+	Severity  Severity    `json:"severity"`             // Severity (critical alerts and node failures page the operators)
+	Source    string      `json:"source,omitempty"`     // Where the alert came from (gossip or retry)
+	Summary   string      `json:"summary,omitempty"`    // Decoded alert message
+	Time      time.Time   `json:"time"`                 // When the event occurred
+	Title     string      `json:"title"`                // One line description of the event
+}
+
+// NewNotification will create the notification of the event (nil if the event is not notified)
+// Synced alerts (history caught up from a peer) and failed retries (already notified) are not notified
+func NewNotification(e *events.Event) *Notification {
+	if e.Source == events.SourceSync || (e.Source == events.SourceRetry && e.Err != nil) {
+		return nil
+	}
+
+	n := &Notification{
+		Event:    e.Type,
+		ID:       e.ID,
+		Node:     e.Node,
+		PeerID:   e.PeerID,
+		Severity: SeverityInfo,
+		Source:   e.Source,
+		Time:     e.Time,
+	}
+	if e.Err != nil {
+		n.Error = e.Err.Error()
+	}
+	if n.Time.IsZero() {
+		n.Time = time.Now().UTC()
+	}
+
+	// Describe the alert (decoded message and severity of its type)
+	if e.Alert != nil {
+		n.AlertName = e.Alert.GetAlertType().Name()
+		n.AlertType = uint32(e.Alert.GetAlertType())
+		n.Hash = e.Alert.Hash
+		n.Raw = e.Alert.Raw
+		n.Sequence = e.Alert.SequenceNumber
+		n.Severity = AlertSeverity(e.Alert.GetAlertType())
+		if am := e.Alert.ProcessAlertMessage(); am != nil && am.Read(e.Alert.GetRawMessage()) == nil {
+			n.Payload = am
+			n.Summary = am.MessageString()
+		}
+	}
+
+	switch e.Type {
+	case events.AlertReceived:
+		n.Title = fmt.Sprintf("Alert %d (%s) received", n.Sequence, n.AlertName)
+	case events.AlertVerified:
+		n.Title = fmt.Sprintf("Alert %d (%s) verified", n.Sequence, n.AlertName)
+	case events.AlertEnforced:
+		n.Title = fmt.Sprintf("Alert %d (%s) enforced on the node", n.Sequence, n.AlertName)
+		n.Resolved = e.Source == events.SourceRetry
+		if e.Err != nil {
+			n.Title = fmt.Sprintf("Alert %d (%s) failed on the node", n.Sequence, n.AlertName)
+			n.Severity = SeverityCritical
+		}
+	case events.NodeHealthy:
+		n.Title = "Node is healthy again"
+		n.Resolved = true
+	case events.NodeUnhealthy:
+		n.Title = "Node is unhealthy"
+		n.Severity = SeverityCritical
+	case events.PeerBanned:
+		n.Title = "Peer banned"
+		n.Severity = SeverityWarning
+	case events.PeerUnbanned:
+		n.Title = "Peer unbanned"
+	default:
+		n.Title = string(e.Type)
+	}
+	if e.Ban != nil {
+		n.PeerID = e.Ban.PeerID
+		n.Title += " " + e.Ban.PeerID
+	}
+	return n
+}
+
+// Text will return the notification as plain text (title, summary and error)
+func (n *Notification) Text() string {
+	text := "[" + n.Severity.String() + "] " + n.Title
+	if len(n.Summary) > 0 {
+		text += "\n" + n.Summary
+	}
+	if len(n.Error) > 0 {
+		text += "\nError: " + n.Error
+	}
+	return text
+}
+
+// orderingKey will return the key that orders the events of a stream (Kafka partition key, FIFO group)
+// The events of an alert share its sequence, the node and peer events share their node or peer
+func orderingKey(n *Notification) []byte {
+	switch n.Event {
+	case events.AlertEnforced, events.AlertReceived, events.AlertVerified:
+		return []byte(strconv.FormatUint(uint64(n.Sequence), 10))
+	}
+	return []byte(DedupKey(n))
+}
+
+// messageID will return the unique ID of the notification (the same for its retries and, if it has an event ID,
+// for the replays of the event, to deduplicate them)
+func messageID(n *Notification) string {
+	if len(n.ID) > 0 {
+		return DedupKey(n) + "/" + string(n.Event) + "/" + n.ID
+	}
+	return DedupKey(n) + "/" + string(n.Event) + "/" + strconv.FormatInt(n.Time.UnixNano(), 10)
+}
@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAlert will create an informational alert with the message
+func newTestAlert(sequence uint32, message string) *models.AlertMessage {
+	a := &models.AlertMessage{SequenceNumber: sequence}
+	a.SetAlertType(models.AlertTypeInformational)
+	a.SetRawMessage(append([]byte{byte(len(message))}, message...))
+	return a
+}
+
+// TestParseSeverity will test the method ParseSeverity()
+func TestParseSeverity(t *testing.T) {
+	t.Parallel()
+
+	for name, expected := range map[string]Severity{
+		"":         SeverityInfo,
+		"info":     SeverityInfo,
+		"WARNING":  SeverityWarning,
+		"critical": SeverityCritical,
+	} {
+		severity, err := ParseSeverity(name)
+		require.NoError(t, err)
+		assert.Equal(t, expected, severity)
+	}
+
+	_, err := ParseSeverity("urgent")
+	require.ErrorIs(t, err, ErrInvalidSeverity)
+}
+
+// TestAlertSeverity will test the method AlertSeverity()
+func TestAlertSeverity(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, SeverityCritical, AlertSeverity(models.AlertTypeInvalidateBlock))
+	assert.Equal(t, SeverityCritical, AlertSeverity(models.AlertTypeConfiscateUtxo))
+	assert.Equal(t, SeverityWarning, AlertSeverity(models.AlertTypeFreezeUtxo))
+	assert.Equal(t, SeverityInfo, AlertSeverity(models.AlertTypeInformational))
+}
+
+// TestNewNotification will test the method NewNotification()
+func TestNewNotification(t *testing.T) {
+	t.Parallel()
+
+	t.Run("enforced alert", func(t *testing.T) {
+		at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		n := NewNotification(&events.Event{
+			Alert: newTestAlert(42, "hello"), Node: "localhost:8332", PeerID: "peer",
+			Source: events.SourceGossip, Time: at, Type: events.AlertEnforced,
+		})
+		require.NotNil(t, n)
+		assert.Equal(t, "Alert 42 (Informational) enforced on the node", n.Title)
+		assert.Equal(t, "Informational: hello", n.Summary)
+		assert.Equal(t, SeverityInfo, n.Severity)
+		assert.Equal(t, at, n.Time)
+		assert.Equal(t, "[info] Alert 42 (Informational) enforced on the node\nInformational: hello", n.Text())
+	})
+
+	t.Run("failed alert is critical", func(t *testing.T) {
+		n := NewNotification(&events.Event{
+			Alert: newTestAlert(42, "hello"), Err: errors.New("rpc error"), Source: events.SourceGossip, Type: events.AlertEnforced,
+		})
+		require.NotNil(t, n)
+		assert.Equal(t, "Alert 42 (Informational) failed on the node", n.Title)
+		assert.Equal(t, SeverityCritical, n.Severity)
+		assert.Equal(t, "rpc error", n.Error)
+		assert.False(t, n.Time.IsZero())
+	})
+
+	t.Run("retried alert resolves the failure", func(t *testing.T) {
+		n := NewNotification(&events.Event{Alert: newTestAlert(42, "hello"), Source: events.SourceRetry, Type: events.AlertEnforced})
+		require.NotNil(t, n)
+		assert.True(t, n.Resolved)
+	})
+
+	t.Run("peer ban", func(t *testing.T) {
+		n := NewNotification(&events.Event{Ban: &models.PeerBan{PeerID: "bad-peer"}, Type: events.PeerBanned})
+		require.NotNil(t, n)
+		assert.Equal(t, "Peer banned bad-peer", n.Title)
+		assert.Equal(t, "bad-peer", n.PeerID)
+		assert.Equal(t, SeverityWarning, n.Severity)
+	})
+
+	t.Run("synced alerts and failed retries are not notified", func(t *testing.T) {
+		assert.Nil(t, NewNotification(&events.Event{
+			Alert: newTestAlert(1, "old"), Source: events.SourceSync, Type: events.AlertEnforced,
+		}))
+		assert.Nil(t, NewNotification(&events.Event{
+			Alert: newTestAlert(1, "old"), Err: errors.New("rpc error"), Source: events.SourceRetry, Type: events.AlertEnforced,
+		}))
+	})
+}
@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+)
+
+// DefaultPagerDutyURL is the PagerDuty Events API v2 endpoint
+const DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDuty event actions
+const (
+	pagerDutyResolve = "resolve"
+	pagerDutyTrigger = "trigger"
+)
+
+// maxPagerDutySummary is the max length of the incident summary
+const maxPagerDutySummary = 1024
+
+// pagerDutySeverities are the PagerDuty severities of the notification severities
+var pagerDutySeverities = map[Severity]string{
+	SeverityCritical: "critical",
+	SeverityInfo:     "info",
+	SeverityWarning:  "warning",
+}
+
+// pagerDutyEvent is an event of the Events API v2
+type pagerDutyEvent struct {
+	DedupKey    string            `json:"dedup_key"`
+	EventAction string            `json:"event_action"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"` // Only to trigger
+	RoutingKey  string            `json:"routing_key"`
+}
+
+// pagerDutyPayload is the incident details
+type pagerDutyPayload struct {
+	Class         string        `json:"class,omitempty"`
+	Component     string        `json:"component"`
+	CustomDetails *Notification `json:"custom_details"`
+	Group         string        `json:"group"`
+	Severity      string        `json:"severity"`
+	Source        string        `json:"source"`
+	Summary       string        `json:"summary"`
+	Timestamp     string        `json:"timestamp"`
+}
+
+// pagerDuty triggers (and resolves) PagerDuty incidents
+type pagerDuty struct {
+	httpClient config.HTTPInterface
+	routingKey string
+	url        string
+}
+
+// newPagerDuty will create the PagerDuty channel
+func newPagerDuty(conf config.PagerDutyConfig, httpClient config.HTTPInterface) *pagerDuty {
+	p := &pagerDuty{
+		httpClient: httpClient,
+		routingKey: conf.RoutingKey,
+		url:        conf.URL,
+	}
+	if len(p.url) == 0 {
+		p.url = DefaultPagerDutyURL
+	}
+	return p
+}
+
+// Name will return the name of the channel
+func (p *pagerDuty) Name() string {
+	return "pagerduty"
+}
+
+// DedupKey will return the PagerDuty dedup key of the notification
+// The same condition (an alert, a node or a peer) is deduplicated into one incident
+func DedupKey(n *Notification) string {
+	switch n.Event {
+	case events.NodeHealthy, events.NodeUnhealthy:
+		return "alert-system/node/" + n.Node
+	case events.PeerBanned, events.PeerUnbanned:
+		return "alert-system/peer/" + n.PeerID
+	}
+	return "alert-system/alert/" + strconv.FormatUint(uint64(n.Sequence), 10)
+}
+
+// Send will trigger the incident (or resolve it if the condition cleared)
+func (p *pagerDuty) Send(ctx context.Context, n *Notification) error {
+	event := &pagerDutyEvent{
+		DedupKey:    DedupKey(n),
+		EventAction: pagerDutyResolve,
+		RoutingKey:  p.routingKey,
+	}
+	if !n.Resolved {
+		event.EventAction = pagerDutyTrigger
+		source := n.Node
+		if len(source) == 0 {
+			source = "alert-system"
+		}
+		summary := n.Title
+		if len(n.Error) > 0 {
+			summary += ": " + n.Error
+		}
+		if len(n.Message) > 0 {
+			summary = n.Message
+		}
+		if len(summary) > maxPagerDutySummary {
+			summary = summary[:maxPagerDutySummary]
+		}
+		event.Payload = &pagerDutyPayload{
+			Class:         n.AlertName,
+			Component:     "alert-system",
+			CustomDetails: n,
+			Group:         string(n.Event),
+			Severity:      pagerDutySeverities[n.Severity],
+			Source:        source,
+			Summary:       summary,
+			Timestamp:     n.Time.UTC().Format(time.RFC3339),
+		}
+	}
+
+	// Post the event
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body)); err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var res *http.Response
+	if res, err = p.httpClient.Do(req); err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: pagerduty status code %d", ErrSendFailed, res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDedupKey will test the method DedupKey()
+func TestDedupKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "alert-system/alert/42", DedupKey(&Notification{Event: events.AlertEnforced, Sequence: 42}))
+	assert.Equal(t, "alert-system/node/localhost:8332", DedupKey(&Notification{Event: events.NodeUnhealthy, Node: "localhost:8332"}))
+	assert.Equal(t, DedupKey(&Notification{Event: events.NodeUnhealthy, Node: "n"}), DedupKey(&Notification{Event: events.NodeHealthy, Node: "n"}))
+	assert.Equal(t, "alert-system/peer/bad-peer", DedupKey(&Notification{Event: events.PeerBanned, PeerID: "bad-peer"}))
+}
+
+// TestPagerDuty_Send will test the method Send()
+func TestPagerDuty_Send(t *testing.T) {
+	t.Parallel()
+
+	readEvent := func(t *testing.T, req *http.Request) *pagerDutyEvent {
+		event := &pagerDutyEvent{}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(event))
+		return event
+	}
+
+	t.Run("trigger", func(t *testing.T) {
+		p := newPagerDuty(config.PagerDutyConfig{RoutingKey: "routing-key"}, &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, DefaultPagerDutyURL, req.URL.String())
+			event := readEvent(t, req)
+			assert.Equal(t, "routing-key", event.RoutingKey)
+			assert.Equal(t, pagerDutyTrigger, event.EventAction)
+			assert.Equal(t, "alert-system/node/localhost:8332", event.DedupKey)
+			require.NotNil(t, event.Payload)
+			assert.Equal(t, "critical", event.Payload.Severity)
+			assert.Equal(t, "localhost:8332", event.Payload.Source)
+			assert.Equal(t, "Node is unhealthy: node did not respond", event.Payload.Summary)
+			assert.Equal(t, "2024-01-02T03:04:05Z", event.Payload.Timestamp)
+			return newResponse(http.StatusAccepted, `{"status":"success"}`), nil
+		}})
+		require.NoError(t, p.Send(context.Background(), &Notification{
+			Error: "node did not respond", Event: events.NodeUnhealthy, Node: "localhost:8332", Severity: SeverityCritical,
+			Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Title: "Node is unhealthy",
+		}))
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		p := newPagerDuty(config.PagerDutyConfig{RoutingKey: "routing-key", URL: "https://events.eu.pagerduty.com/v2/enqueue"}, &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, "https://events.eu.pagerduty.com/v2/enqueue", req.URL.String())
+				event := readEvent(t, req)
+				assert.Equal(t, pagerDutyResolve, event.EventAction)
+				assert.Equal(t, "alert-system/alert/42", event.DedupKey)
+				assert.Nil(t, event.Payload)
+				return newResponse(http.StatusAccepted, `{"status":"success"}`), nil
+			},
+		})
+		require.NoError(t, p.Send(context.Background(), &Notification{Event: events.AlertEnforced, Resolved: true, Sequence: 42}))
+	})
+
+	t.Run("invalid event", func(t *testing.T) {
+		p := newPagerDuty(config.PagerDutyConfig{RoutingKey: "routing-key"}, &mockHTTPClient{doFunc: func(_ *http.Request) (*http.Response, error) {
+			return newResponse(http.StatusBadRequest, `{"status":"invalid event"}`), nil
+		}})
+		require.ErrorIs(t, p.Send(context.Background(), &Notification{Event: events.AlertEnforced}), ErrSendFailed)
+	})
+}
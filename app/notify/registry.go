@@ -0,0 +1,285 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+)
+
+// Notifier sends the notifications to a destination (Slack, ...)
+// The built-in channels and the channels registered by an embedder share the queue, retries, filtering and metrics
+// Send is called by a single worker, it must return an error to be retried (with the same notification)
+// A notifier holding a connection can implement io.Closer, it is closed with the service (see Service.Close)
+type Notifier interface {
+	Name() string // Name of the channel (metrics label and logs)
+	Send(ctx context.Context, n *Notification) error
+}
+
+// Route is a notifier with the events and min severity it is notified of
+type Route struct {
+	Events      []string // Events notified (DefaultEvents if empty)
+	MinSeverity string   // Min severity notified (info if empty, resolutions are always notified)
+	Notifier    Notifier
+}
+
+// Factory creates the routes of a channel from the config (none if the channel is not configured)
+type Factory func(conf *config.Config) ([]*Route, error)
+
+// factories are the registered channels (by name)
+var (
+	factories   = make(map[string]Factory)
+	factoriesMu sync.RWMutex
+)
+
+// init will register the built-in channels
+func init() {
+	Register("discord", discordRoutes)
+	Register("email", emailRoutes)
+	Register("kafka", kafkaRoutes)
+	Register("matrix", matrixRoutes)
+	Register("mqtt", mqttRoutes)
+	Register("nats", natsRoutes)
+	Register("pagerduty", pagerDutyRoutes)
+	Register("slack", slackRoutes)
+	Register("sns", snsRoutes)
+	Register("sqs", sqsRoutes)
+	Register("telegram", telegramRoutes)
+	Register("teranode", teranodeRoutes)
+	Register("twilio", twilioRoutes)
+}
+
+// Register will register the channel factory, used by New() to create the routes of the channel
+// It must be called before the server is created (e.g. in an init function of the embedder)
+// It panics if the factory is nil or the name is already registered
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if factory == nil {
+		panic("notify: Register factory is nil")
+	}
+	if _, ok := factories[name]; ok {
+		panic("notify: Register called twice for channel " + name)
+	}
+	factories[name] = factory
+}
+
+// Channels will return the names of the registered channels (sorted)
+func Channels() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// routes will create the routes of every registered channel (in the order of the channel names)
+func routes(conf *config.Config) ([]*Route, error) {
+	var all []*Route
+	for _, name := range Channels() {
+		factoriesMu.RLock()
+		factory := factories[name]
+		factoriesMu.RUnlock()
+		channelRoutes, err := factory(conf)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		all = append(all, channelRoutes...)
+	}
+	return all, nil
+}
+
+// streamEvents will return the events of a streaming channel (every event if none are given)
+func streamEvents(eventNames []string) []string {
+	if len(eventNames) == 0 {
+		return DefaultStreamEvents
+	}
+	return eventNames
+}
+
+// discordRoutes will create the Discord route (webhook)
+func discordRoutes(conf *config.Config) ([]*Route, error) {
+	discordConf := conf.Notifications.Discord
+	if len(discordConf.WebhookURL) == 0 {
+		return nil, nil
+	}
+	return []*Route{{
+		Events: discordConf.Events, MinSeverity: discordConf.MinSeverity, Notifier: newDiscord(discordConf, conf.Services.HTTPClient),
+	}}, nil
+}
+
+// emailRoutes will create the email route (SMTP)
+func emailRoutes(conf *config.Config) ([]*Route, error) {
+	emailConf := conf.Notifications.Email
+	if len(emailConf.Address) == 0 {
+		return nil, nil
+	}
+	c, err := newEmail(emailConf)
+	if err != nil {
+		return nil, err
+	}
+	return []*Route{{Events: emailConf.Events, MinSeverity: emailConf.MinSeverity, Notifier: c}}, nil
+}
+
+// kafkaRoutes will create the Kafka route (every event by default)
+func kafkaRoutes(conf *config.Config) ([]*Route, error) {
+	kafkaConf := conf.Notifications.Kafka
+	if len(kafkaConf.Brokers) == 0 {
+		return nil, nil
+	}
+	c, err := newKafka(kafkaConf)
+	if err != nil {
+		return nil, err
+	}
+	return []*Route{{Events: streamEvents(kafkaConf.Events), MinSeverity: kafkaConf.MinSeverity, Notifier: c}}, nil
+}
+
+// matrixRoutes will create a Matrix route per room (retried separately)
+func matrixRoutes(conf *config.Config) ([]*Route, error) {
+	matrixConf := conf.Notifications.Matrix
+	if len(matrixConf.HomeserverURL) == 0 {
+		return nil, nil
+	}
+	rooms, err := newMatrix(matrixConf, conf.Services.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+	roomRoutes := make([]*Route, 0, len(rooms))
+	for _, c := range rooms {
+		roomRoutes = append(roomRoutes, &Route{Events: matrixConf.Events, MinSeverity: matrixConf.MinSeverity, Notifier: c})
+	}
+	return roomRoutes, nil
+}
+
+// mqttRoutes will create the MQTT route
+func mqttRoutes(conf *config.Config) ([]*Route, error) {
+	mqttConf := conf.Notifications.MQTT
+	if len(mqttConf.Broker) == 0 {
+		return nil, nil
+	}
+	c, err := newMQTT(mqttConf)
+	if err != nil {
+		return nil, err
+	}
+	return []*Route{{Events: mqttConf.Events, MinSeverity: mqttConf.MinSeverity, Notifier: c}}, nil
+}
+
+// natsRoutes will create the NATS route (every event by default)
+func natsRoutes(conf *config.Config) ([]*Route, error) {
+	natsConf := conf.Notifications.NATS
+	if len(natsConf.Servers) == 0 {
+		return nil, nil
+	}
+	return []*Route{{Events: streamEvents(natsConf.Events), MinSeverity: natsConf.MinSeverity, Notifier: newNATS(natsConf)}}, nil
+}
+
+// pagerDutyRoutes will create the PagerDuty route (only the critical notifications trigger incidents by default)
+func pagerDutyRoutes(conf *config.Config) ([]*Route, error) {
+	pdConf := conf.Notifications.PagerDuty
+	if len(pdConf.RoutingKey) == 0 {
+		return nil, nil
+	}
+	minSeverity := pdConf.MinSeverity
+	if len(minSeverity) == 0 {
+		minSeverity = severityCritical
+	}
+	return []*Route{{Events: pdConf.Events, MinSeverity: minSeverity, Notifier: newPagerDuty(pdConf, conf.Services.HTTPClient)}}, nil
+}
+
+// slackRoutes will create the Slack route (incoming webhook or bot token)
+func slackRoutes(conf *config.Config) ([]*Route, error) {
+	slackConf := conf.Notifications.Slack
+	if len(slackConf.WebhookURL) == 0 && len(slackConf.BotToken) == 0 {
+		return nil, nil
+	}
+	c, err := newSlack(slackConf, conf.Services.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+	return []*Route{{Events: slackConf.Events, MinSeverity: slackConf.MinSeverity, Notifier: c}}, nil
+}
+
+// snsRoutes will create the AWS SNS route (every event by default)
+func snsRoutes(conf *config.Config) ([]*Route, error) {
+	snsConf := conf.Notifications.SNS
+	if len(snsConf.TopicARN) == 0 {
+		return nil, nil
+	}
+	c, err := newSNS(context.Background(), snsConf)
+	if err != nil {
+		return nil, err
+	}
+	return []*Route{{Events: streamEvents(snsConf.Events), MinSeverity: snsConf.MinSeverity, Notifier: c}}, nil
+}
+
+// sqsRoutes will create the AWS SQS route (every event by default)
+func sqsRoutes(conf *config.Config) ([]*Route, error) {
+	sqsConf := conf.Notifications.SQS
+	if len(sqsConf.QueueURL) == 0 {
+		return nil, nil
+	}
+	c, err := newSQS(context.Background(), sqsConf)
+	if err != nil {
+		return nil, err
+	}
+	return []*Route{{Events: streamEvents(sqsConf.Events), MinSeverity: sqsConf.MinSeverity, Notifier: c}}, nil
+}
+
+// telegramRoutes will create a Telegram route per chat (retried separately)
+func telegramRoutes(conf *config.Config) ([]*Route, error) {
+	telegramConf := conf.Notifications.Telegram
+	if len(telegramConf.BotToken) == 0 {
+		return nil, nil
+	}
+	chats, err := newTelegram(telegramConf, conf.Services.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+	chatRoutes := make([]*Route, 0, len(chats))
+	for _, c := range chats {
+		chatRoutes = append(chatRoutes, &Route{Events: telegramConf.Events, MinSeverity: telegramConf.MinSeverity, Notifier: c})
+	}
+	return chatRoutes, nil
+}
+
+// teranodeRoutes will create the Teranode route (the validated alerts by default)
+func teranodeRoutes(conf *config.Config) ([]*Route, error) {
+	teranodeConf := conf.Notifications.Teranode
+	if len(teranodeConf.BlobURL) == 0 && len(teranodeConf.NotificationURL) == 0 {
+		return nil, nil
+	}
+	eventNames := teranodeConf.Events
+	if len(eventNames) == 0 {
+		eventNames = []string{string(events.AlertVerified)}
+	}
+	return []*Route{{
+		Events: eventNames, MinSeverity: teranodeConf.MinSeverity, Notifier: newTeranode(teranodeConf, conf.Services.HTTPClient),
+	}}, nil
+}
+
+// twilioRoutes will create a Twilio route per phone number (only the critical notifications are sent by default)
+func twilioRoutes(conf *config.Config) ([]*Route, error) {
+	twilioConf := conf.Notifications.Twilio
+	if len(twilioConf.AccountSID) == 0 {
+		return nil, nil
+	}
+	numbers, err := newTwilio(twilioConf, conf.Services.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+	minSeverity := twilioConf.MinSeverity
+	if len(minSeverity) == 0 {
+		minSeverity = severityCritical
+	}
+	numberRoutes := make([]*Route, 0, len(numbers))
+	for _, c := range numbers {
+		numberRoutes = append(numberRoutes, &Route{Events: twilioConf.Events, MinSeverity: minSeverity, Notifier: c})
+	}
+	return numberRoutes, nil
+}
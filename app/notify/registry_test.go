@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRegister will test registering a channel (only used by New() with its own config)
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	registered := &config.Config{
+		Services: config.Services{Log: config.NewExtendedLogger(nopWriteCloser{io.Discard}, config.LogLevelError, nil)},
+	}
+	failing := &config.Config{Services: registered.Services}
+	c := &testChannel{sent: make(chan *Notification, 1)}
+	Register("test-registry", func(conf *config.Config) ([]*Route, error) {
+		switch conf {
+		case registered:
+			return []*Route{{MinSeverity: "critical", Notifier: c}}, nil
+		case failing:
+			return nil, errors.New("bad config")
+		}
+		return nil, nil
+	})
+	assert.Contains(t, Channels(), "test-registry")
+	assert.Contains(t, Channels(), "slack")
+
+	s, err := New(registered)
+	require.NoError(t, err)
+	require.Len(t, s.routes, 1)
+	assert.Equal(t, c, s.routes[0].notifier)
+	assert.Equal(t, SeverityCritical, s.routes[0].minSeverity)
+	assert.Len(t, s.routes[0].events, len(DefaultEvents))
+
+	_, err = New(failing)
+	require.EqualError(t, err, "test-registry: bad config")
+
+	assert.Panics(t, func() {
+		Register("test-registry", func(*config.Config) ([]*Route, error) { return nil, nil })
+	})
+	assert.Panics(t, func() {
+		Register("test-registry-nil", nil)
+	})
+}
+
+// TestNewRoute will test the method newRoute()
+func TestNewRoute(t *testing.T) {
+	t.Parallel()
+
+	_, err := newRoute(&Route{})
+	require.ErrorIs(t, err, ErrNoNotifier)
+
+	_, err = newRoute(&Route{Events: []string{"alert.unknown"}, Notifier: &testChannel{}})
+	require.ErrorIs(t, err, ErrInvalidEvent)
+
+	r, err := newRoute(&Route{Events: []string{"peer.banned"}, MinSeverity: "warning", Notifier: &testChannel{}})
+	require.NoError(t, err)
+	assert.Len(t, r.events, 1)
+	assert.Equal(t, SeverityWarning, r.minSeverity)
+}
@@ -0,0 +1,187 @@
+package notify
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/bitcoin-sv/alert-system/app/models"
+)
+
+// Rule actions
+const (
+	RuleDrop   = "drop"   // The channels of the rule are not notified
+	RuleNotify = "notify" // The channels of the rule are notified (whatever their events and min severity)
+)
+
+// rule is a routing rule (parsed), the conditions that are set must all match
+type rule struct {
+	alertTypes  map[string]bool
+	channels    map[string]bool // Every channel if empty
+	events      map[events.Type]bool
+	fields      map[string]string
+	minSeverity Severity
+	name        string
+	notify      bool
+	peers       map[string]bool
+}
+
+// newRules will parse the routing rules (in order)
+func newRules(conf []config.NotificationRuleConfig) ([]*rule, error) {
+	channels := make(map[string]bool)
+	for _, name := range Channels() {
+		channels[name] = true
+	}
+	alertTypes := alertTypeKeys()
+
+	rules := make([]*rule, 0, len(conf))
+	for i, c := range conf {
+		name := c.Name
+		if len(name) == 0 {
+			name = fmt.Sprintf("rule %d", i+1)
+		}
+		r := &rule{
+			alertTypes: make(map[string]bool, len(c.AlertTypes)),
+			channels:   make(map[string]bool, len(c.Channels)),
+			events:     make(map[events.Type]bool, len(c.Events)),
+			fields:     c.Fields,
+			name:       name,
+			peers:      make(map[string]bool, len(c.Peers)),
+		}
+		switch c.Action {
+		case "", RuleNotify:
+			r.notify = true
+		case RuleDrop:
+		default:
+			return nil, fmt.Errorf("%w: %s: action %s", ErrInvalidRule, name, c.Action)
+		}
+		var err error
+		if r.minSeverity, err = ParseSeverity(c.MinSeverity); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		for _, alertType := range c.AlertTypes {
+			if !alertTypes[alertType] {
+				return nil, fmt.Errorf("%w: %s: unknown alert type %s", ErrInvalidRule, name, alertType)
+			}
+			r.alertTypes[alertType] = true
+		}
+		for _, channel := range c.Channels {
+			if !channels[channel] {
+				return nil, fmt.Errorf("%w: %s: unknown channel %s", ErrInvalidRule, name, channel)
+			}
+			r.channels[channel] = true
+		}
+		for _, e := range c.Events {
+			if !isValidEvent(events.Type(e)) {
+				return nil, fmt.Errorf("%s: %w: %s", name, ErrInvalidEvent, e)
+			}
+			r.events[events.Type(e)] = true
+		}
+		for _, peer := range c.Peers {
+			r.peers[peer] = true
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// appliesTo will return true if the rule routes the channel
+func (r *rule) appliesTo(channel string) bool {
+	return len(r.channels) == 0 || r.channels[channel]
+}
+
+// matches will return true if the notification matches every condition of the rule
+func (r *rule) matches(n *Notification) bool {
+	if len(r.events) > 0 && !r.events[n.Event] {
+		return false
+	} else if n.Severity < r.minSeverity && !n.Resolved {
+		return false
+	} else if len(r.peers) > 0 && !r.peers[n.PeerID] {
+		return false
+	}
+	if len(r.alertTypes) > 0 {
+		if n.AlertType == 0 || !r.alertTypes[AlertTypeKey(models.AlertType(n.AlertType))] {
+			return false
+		}
+	}
+	for name, expected := range r.fields {
+		if value, ok := payloadField(n.Payload, name); !ok || !strings.EqualFold(value, expected) {
+			return false
+		}
+	}
+	return true
+}
+
+// firstRule will return the first rule routing the channel that matches the notification (nil if none)
+func firstRule(rules []*rule, channel string, n *Notification) *rule {
+	for _, r := range rules {
+		if r.appliesTo(channel) && r.matches(n) {
+			return r
+		}
+	}
+	return nil
+}
+
+// payloadField will return a field of the decoded alert as text (by its JSON or snake case name)
+// Bytes are returned as text if printable, otherwise as hex (like hashes and keys)
+func payloadField(payload interface{}, name string) (string, bool) {
+	v := reflect.Indirect(reflect.ValueOf(payload))
+	if !v.IsValid() || v.Kind() != reflect.Struct {
+		return "", false
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Anonymous || !field.IsExported() { // Skip the embedded alert
+			continue
+		}
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName != name && snakeCase(field.Name) != name {
+			continue
+		}
+		return fieldText(v.Field(i)), true
+	}
+	return "", false
+}
+
+// fieldText will format the field value
+func fieldText(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return ""
+	}
+	if stringer, ok := v.Interface().(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	switch {
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		b := v.Bytes()
+		if utf8.Valid(b) && strings.IndexFunc(string(b), func(r rune) bool { return !unicode.IsPrint(r) }) == -1 {
+			return string(b)
+		}
+		return hex.EncodeToString(b)
+	case v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8:
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return hex.EncodeToString(b)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// snakeCase will convert the field name to snake case (e.g. BlockHash to block_hash)
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,155 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/libsv/go-bt/v2/chainhash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewRules will test the method newRules()
+func TestNewRules(t *testing.T) {
+	t.Parallel()
+
+	rules, err := newRules([]config.NotificationRuleConfig{{}, {Action: RuleDrop, Name: "drop"}})
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "rule 1", rules[0].name)
+	assert.True(t, rules[0].notify)
+	assert.False(t, rules[1].notify)
+
+	for name, c := range map[string]config.NotificationRuleConfig{
+		"action":     {Action: "page"},
+		"alert type": {AlertTypes: []string{"freeze_utxo"}},
+		"channel":    {Channels: []string{"irc"}},
+	} {
+		_, err = newRules([]config.NotificationRuleConfig{c})
+		require.ErrorIs(t, err, ErrInvalidRule, name)
+	}
+	_, err = newRules([]config.NotificationRuleConfig{{Events: []string{"alert.unknown"}}})
+	require.ErrorIs(t, err, ErrInvalidEvent)
+	_, err = newRules([]config.NotificationRuleConfig{{MinSeverity: "urgent"}})
+	require.ErrorIs(t, err, ErrInvalidSeverity)
+}
+
+// TestRule_matches will test the method matches()
+func TestRule_matches(t *testing.T) {
+	t.Parallel()
+
+	hash, err := chainhash.NewHashFromStr("00000000000000000a1b2c")
+	require.NoError(t, err)
+	invalidate := &Notification{
+		AlertType: uint32(models.AlertTypeInvalidateBlock),
+		Event:     events.AlertEnforced,
+		Payload:   &models.AlertMessageInvalidateBlock{BlockHash: hash, Reason: []byte("double spend")},
+		PeerID:    "peer-a",
+		Severity:  SeverityCritical,
+	}
+	informational := NewNotification(&events.Event{Alert: newTestAlert(1, "hello"), PeerID: "peer-b", Type: events.AlertVerified})
+	node := &Notification{Event: events.NodeHealthy, Resolved: true}
+
+	tests := []struct {
+		name     string
+		rule     config.NotificationRuleConfig
+		n        *Notification
+		expected bool
+	}{
+		{"no conditions", config.NotificationRuleConfig{}, node, true},
+		{"event", config.NotificationRuleConfig{Events: []string{"alert.verified"}}, informational, true},
+		{"other event", config.NotificationRuleConfig{Events: []string{"alert.verified"}}, invalidate, false},
+		{"severity", config.NotificationRuleConfig{MinSeverity: "critical"}, invalidate, true},
+		{"below the severity", config.NotificationRuleConfig{MinSeverity: "critical"}, informational, false},
+		{"resolution", config.NotificationRuleConfig{MinSeverity: "critical"}, node, true},
+		{"alert type", config.NotificationRuleConfig{AlertTypes: []string{"invalidate_block"}}, invalidate, true},
+		{"other alert type", config.NotificationRuleConfig{AlertTypes: []string{"invalidate_block"}}, informational, false},
+		{"no alert", config.NotificationRuleConfig{AlertTypes: []string{"invalidate_block"}}, node, false},
+		{"peer", config.NotificationRuleConfig{Peers: []string{"peer-a", "peer-c"}}, invalidate, true},
+		{"other peer", config.NotificationRuleConfig{Peers: []string{"peer-a"}}, informational, false},
+		{"text field", config.NotificationRuleConfig{Fields: map[string]string{"reason": "Double Spend"}}, invalidate, true},
+		{"hash field", config.NotificationRuleConfig{Fields: map[string]string{"block_hash": hash.String()}}, invalidate, true},
+		{"snake case field", config.NotificationRuleConfig{Fields: map[string]string{"message": "hello"}}, informational, true},
+		{"other field value", config.NotificationRuleConfig{Fields: map[string]string{"message": "bye"}}, informational, false},
+		{"unknown field", config.NotificationRuleConfig{Fields: map[string]string{"sequence_number": "1"}}, informational, false},
+		{"no payload", config.NotificationRuleConfig{Fields: map[string]string{"message": "hello"}}, node, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rules, err := newRules([]config.NotificationRuleConfig{test.rule})
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, rules[0].matches(test.n))
+		})
+	}
+}
+
+// TestPayloadField will test the method payloadField()
+func TestPayloadField(t *testing.T) {
+	t.Parallel()
+
+	payload := &models.AlertMessageBanPeer{Peer: []byte("10.0.0.1"), Reason: []byte{0x00, 0xff}}
+	value, ok := payloadField(payload, "peer")
+	assert.True(t, ok)
+	assert.Equal(t, "10.0.0.1", value)
+	value, ok = payloadField(payload, "reason")
+	assert.True(t, ok)
+	assert.Equal(t, "00ff", value)
+	value, ok = payloadField(payload, "peer_length")
+	assert.True(t, ok)
+	assert.Equal(t, "0", value)
+
+	_, ok = payloadField(nil, "peer")
+	assert.False(t, ok)
+	_, ok = payloadField("text", "peer")
+	assert.False(t, ok)
+
+	keys := &models.AlertMessageSetKeys{Keys: [][33]byte{{0x02}}}
+	_, ok = payloadField(keys, "keys")
+	assert.True(t, ok)
+	assert.Equal(t, "block_hash", snakeCase("BlockHash"))
+}
+
+// TestService_routed will test the routing rules override the channel filters
+func TestService_routed(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	var err error
+	s.rules, err = newRules([]config.NotificationRuleConfig{
+		{Action: RuleDrop, Name: "quiet peer", Peers: []string{"peer-test"}},
+		{AlertTypes: []string{"informational"}, Events: []string{"alert.verified"}},
+	})
+	require.NoError(t, err)
+	c := &testChannel{sent: make(chan *Notification, 10)}
+	require.NoError(t, s.addRoute(&Route{MinSeverity: "warning", Notifier: c}))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+	defer s.Stop()
+
+	// Dropped by the first rule (the channel is notified of the unhealthy nodes)
+	s.Notify(&Notification{Event: events.NodeUnhealthy, PeerID: "peer-test", Severity: SeverityCritical})
+
+	// Notified by the second rule (not an event of the channel and below its min severity)
+	s.Notify(NewNotification(&events.Event{Alert: newTestAlert(7, "hello"), Type: events.AlertVerified}))
+	select {
+	case n := <-c.sent:
+		assert.Equal(t, uint32(7), n.Sequence)
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification was not sent")
+	}
+
+	// No rule matches, the channel filters apply
+	s.Notify(&Notification{Event: events.NodeUnhealthy, Severity: SeverityCritical, Title: "Node is unhealthy"})
+	select {
+	case n := <-c.sent:
+		assert.Equal(t, events.NodeUnhealthy, n.Event)
+		assert.Empty(t, n.PeerID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification was not sent")
+	}
+}
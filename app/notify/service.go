@@ -0,0 +1,326 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/bitcoin-sv/alert-system/app/metrics"
+	"github.com/bitcoin-sv/alert-system/app/reporting"
+)
+
+// DefaultSendTimeout is the max time to wait for a channel to accept a notification
+const DefaultSendTimeout = 10 * time.Second
+
+// route is a notifier with the events and min severity it is notified of (parsed)
+type route struct {
+	events      map[events.Type]bool
+	minSeverity Severity
+	notifier    Notifier
+}
+
+// newRoute will parse the route (the default events if none are given)
+func newRoute(r *Route) (*route, error) {
+	if r.Notifier == nil {
+		return nil, ErrNoNotifier
+	}
+	parsed := &route{events: make(map[events.Type]bool), notifier: r.Notifier}
+	var err error
+	if parsed.minSeverity, err = ParseSeverity(r.MinSeverity); err != nil {
+		return nil, fmt.Errorf("%s: %w", r.Notifier.Name(), err)
+	}
+	if len(r.Events) == 0 {
+		for _, e := range DefaultEvents {
+			parsed.events[e] = true
+		}
+		return parsed, nil
+	}
+	for _, name := range r.Events {
+		if !isValidEvent(events.Type(name)) {
+			return nil, fmt.Errorf("%s: %w: %s", r.Notifier.Name(), ErrInvalidEvent, name)
+		}
+		parsed.events[events.Type(name)] = true
+	}
+	return parsed, nil
+}
+
+// matches will return true if the channel is notified of the notification
+// Resolutions are always notified (the channel may have been notified of what they clear)
+func (r *route) matches(n *Notification) bool {
+	return r.events[n.Event] && (n.Severity >= r.minSeverity || n.Resolved)
+}
+
+// isValidEvent will return true if the event can be notified
+func isValidEvent(e events.Type) bool {
+	switch e {
+	case events.AlertEnforced, events.AlertReceived, events.AlertVerified,
+		events.NodeHealthy, events.NodeUnhealthy, events.PeerBanned, events.PeerUnbanned:
+		return true
+	}
+	return false
+}
+
+// delivery is a notification to send to a channel
+type delivery struct {
+	attempt      int
+	notification *Notification
+	route        *route
+}
+
+// Service sends the notifications to the configured channels (with retries)
+type Service struct {
+	closed    sync.Once
+	config    *config.Config
+	handled   *events.Dedup // Events already notified (a replayed outbox event is notified once)
+	logger    config.LoggerInterface
+	mu        sync.Mutex
+	queue     chan *delivery
+	quit      chan struct{}
+	routes    []*route
+	rules     []*rule
+	stop      sync.Once
+	templates *Templates
+	unhealthy map[string]bool // Nodes notified as unhealthy (until they are healthy again)
+	wg        sync.WaitGroup
+}
+
+// New will create the notification service with the configured channels (see Register)
+// An invalid channel config (severity, event or template) is returned as an error
+func New(conf *config.Config) (*Service, error) {
+	s := &Service{
+		config:    conf,
+		handled:   events.NewDedup(events.DefaultDedupSize),
+		logger:    config.WithField(conf.Services.Log, config.LogFieldModule, "notify"),
+		queue:     make(chan *delivery, conf.Notifications.QueueSize),
+		quit:      make(chan struct{}),
+		unhealthy: make(map[string]bool),
+	}
+
+	// The message templates (by channel and alert type) and the routing rules
+	var err error
+	if s.templates, err = NewTemplates(conf.Notifications.Templates); err != nil {
+		return nil, err
+	} else if s.rules, err = newRules(conf.Notifications.Rules); err != nil {
+		return nil, err
+	}
+
+	// The registered channels (built-in and embedder channels)
+	var channelRoutes []*Route
+	if channelRoutes, err = routes(conf); err != nil {
+		return nil, err
+	}
+	for _, r := range channelRoutes {
+		if err = s.addRoute(r); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// addRoute will add the route to the notified routes
+func (s *Service) addRoute(r *Route) error {
+	parsed, err := newRoute(r)
+	if err != nil {
+		return err
+	}
+	s.routes = append(s.routes, parsed)
+	return nil
+}
+
+// Enabled will return true if any channel is configured
+func (s *Service) Enabled() bool {
+	return len(s.routes) > 0
+}
+
+// Subscribe will notify the events published on the bus (if any channel is configured)
+func (s *Service) Subscribe(bus *events.Bus) {
+	if s.Enabled() {
+		bus.Subscribe(s.handle)
+	}
+}
+
+// handle will notify the event published on the bus
+// An unhealthy node is notified once (not at every heartbeat) until it is healthy again, and a replayed
+// outbox event once (the channels deduplicating by message ID also drop a replay after a restart)
+func (s *Service) handle(_ context.Context, e *events.Event) {
+	n := NewNotification(e)
+	if n == nil || !s.handled.First(n.ID) {
+		return
+	}
+	switch n.Event {
+	case events.NodeUnhealthy:
+		s.mu.Lock()
+		notified := s.unhealthy[n.Node]
+		s.unhealthy[n.Node] = true
+		s.mu.Unlock()
+		if notified {
+			return
+		}
+	case events.NodeHealthy:
+		s.mu.Lock()
+		delete(s.unhealthy, n.Node)
+		s.mu.Unlock()
+	}
+	s.Notify(n)
+}
+
+// Notify will queue the notification for the channels notified of its event and severity (or routed by a rule)
+func (s *Service) Notify(n *Notification) {
+	for _, r := range s.routes {
+		if s.routed(r, n) {
+			s.enqueue(&delivery{notification: s.render(r, n), route: r})
+		}
+	}
+}
+
+// routed will return true if the notification is sent to the channel
+// The first matching rule of the channel decides, otherwise the events and min severity of the channel
+func (s *Service) routed(r *route, n *Notification) bool {
+	name := r.notifier.Name()
+	if matched := firstRule(s.rules, name, n); matched != nil {
+		if !matched.notify {
+			s.logger.Debugf("%s notification to %s dropped by %s", n.Event, name, matched.name)
+		}
+		return matched.notify
+	}
+	return r.matches(n)
+}
+
+// render will return the notification with the message of the channel template (if any)
+// A template that fails is logged and the channel formats its own message
+func (s *Service) render(r *route, n *Notification) *Notification {
+	if s.templates == nil {
+		return n
+	}
+	name := r.notifier.Name()
+	message, ok, err := s.templates.Render(name, n)
+	if err != nil {
+		s.logger.Errorf("%s template of %s notification: %s", name, n.Event, err.Error())
+		return n
+	} else if !ok {
+		return n
+	}
+	rendered := *n
+	rendered.Message = message
+	return &rendered
+}
+
+// Start will start the delivery worker (a single worker keeps the notifications in order)
+func (s *Service) Start(ctx context.Context) {
+	if !s.Enabled() {
+		return
+	}
+	s.wg.Add(1)
+	go s.worker(ctx)
+}
+
+// Stop will stop the delivery worker (pending notifications are dropped)
+func (s *Service) Stop() {
+	s.stop.Do(func() {
+		close(s.quit)
+	})
+	s.wg.Wait()
+}
+
+// Close will stop the delivery worker and close the channels holding a connection (see Notifier)
+func (s *Service) Close() (err error) {
+	s.Stop()
+	s.closed.Do(func() {
+		var errs []error
+		for _, r := range s.routes {
+			if closer, ok := r.notifier.(io.Closer); ok {
+				if closeErr := closer.Close(); closeErr != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", r.notifier.Name(), closeErr))
+				}
+			}
+		}
+		err = errors.Join(errs...)
+	})
+	return
+}
+
+// Flush will stop the delivery worker and send the queued notifications once (the retries are dropped)
+// until the queue is empty or the context is done
+func (s *Service) Flush(ctx context.Context) error {
+	s.Stop()
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%w: %d notifications not sent", err, len(s.queue))
+		}
+		select {
+		case del := <-s.queue:
+			s.process(ctx, del)
+		default:
+			return nil
+		}
+	}
+}
+
+// enqueue will add the delivery to the queue (dropped if the queue is full or the workers are stopped)
+func (s *Service) enqueue(del *delivery) {
+	select {
+	case <-s.quit:
+		return
+	default:
+	}
+	select {
+	case s.queue <- del:
+	case <-s.quit:
+	default:
+		metrics.NotificationDeliveries.WithLabelValues(del.route.notifier.Name(), metrics.ResultDropped).Inc()
+		s.logger.Errorf("%s: dropping %s notification to %s", ErrQueueFull.Error(), del.notification.Event, del.route.notifier.Name())
+	}
+}
+
+// worker will send the queued notifications until stopped
+func (s *Service) worker(ctx context.Context) {
+	defer s.wg.Done()
+	defer reporting.Recover(s.config.Services.Reporter, map[string]string{config.LogFieldModule: "notify"})
+	for {
+		select {
+		case del := <-s.queue:
+			s.process(ctx, del)
+		case <-s.quit:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// process will send the notification and schedule a retry on failure
+func (s *Service) process(ctx context.Context, del *delivery) {
+	name := del.route.notifier.Name()
+	sendCtx, cancel := context.WithTimeout(ctx, DefaultSendTimeout)
+	err := del.route.notifier.Send(sendCtx, del.notification)
+	cancel()
+	if err == nil {
+		metrics.NotificationDeliveries.WithLabelValues(name, metrics.ResultOK).Inc()
+		return
+	} else if errors.Is(err, ErrRateLimited) { // Not retried (the cap would drop the retries too)
+		metrics.NotificationDeliveries.WithLabelValues(name, metrics.ResultDropped).Inc()
+		s.logger.Warnf("dropping %s notification to %s: %s", del.notification.Event, name, err.Error())
+		return
+	}
+
+	// Give up after the max retries
+	del.attempt++
+	if del.attempt > s.config.Notifications.MaxRetries {
+		metrics.NotificationDeliveries.WithLabelValues(name, metrics.ResultError).Inc()
+		s.logger.Errorf("giving up on %s notification to %s after %d attempts: %s", del.notification.Event, name, del.attempt, err.Error())
+		return
+	}
+
+	// Retry with a backoff (doubles each attempt)
+	backoff := s.config.Notifications.RetryInterval * time.Duration(1<<(del.attempt-1))
+	metrics.NotificationDeliveries.WithLabelValues(name, metrics.ResultRetry).Inc()
+	s.logger.Debugf("retrying %s notification to %s in %s: %s", del.notification.Event, name, backoff.String(), err.Error())
+	time.AfterFunc(backoff, func() {
+		s.enqueue(del)
+	})
+}
@@ -0,0 +1,267 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nopWriteCloser discards the log output
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close will do nothing
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// testChannel records the notifications (failing the first attempts)
+type testChannel struct {
+	failures int
+	mu       sync.Mutex
+	sent     chan *Notification
+}
+
+// Name will return the name of the channel
+func (c *testChannel) Name() string {
+	return "test"
+}
+
+// Send will record the notification (or fail)
+func (c *testChannel) Send(_ context.Context, n *Notification) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failures > 0 {
+		c.failures--
+		return errors.New("channel is down")
+	}
+	c.sent <- n
+	return nil
+}
+
+// newTestService will create a service without channels
+func newTestService(t *testing.T) *Service {
+	conf := &config.Config{
+		Notifications: config.NotificationsConfig{MaxRetries: 2, QueueSize: 10, RetryInterval: time.Millisecond},
+		Services:      config.Services{Log: config.NewExtendedLogger(nopWriteCloser{io.Discard}, config.LogLevelError, nil)},
+	}
+	s, err := New(conf)
+	require.NoError(t, err)
+	assert.False(t, s.Enabled())
+	return s
+}
+
+// TestNew will test the method New()
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{
+		Services: config.Services{Log: config.NewExtendedLogger(nopWriteCloser{io.Discard}, config.LogLevelError, nil)},
+	}
+	conf.Notifications.Slack = config.SlackConfig{WebhookURL: "https://hooks.slack.com/x"}
+	s, err := New(conf)
+	require.NoError(t, err)
+	assert.True(t, s.Enabled())
+
+	conf.Notifications.Slack.Events = []string{"alert.unknown"}
+	_, err = New(conf)
+	require.ErrorIs(t, err, ErrInvalidEvent)
+
+	conf.Notifications.Slack.Events = nil
+	conf.Notifications.Slack.MinSeverity = "urgent"
+	_, err = New(conf)
+	require.ErrorIs(t, err, ErrInvalidSeverity)
+}
+
+// TestService_Notify will test filtering, sending and retrying the notifications
+func TestService_Notify(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	c := &testChannel{failures: 2, sent: make(chan *Notification, 10)}
+	require.NoError(t, s.addRoute(&Route{MinSeverity: "warning", Notifier: c}))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+	defer s.Stop()
+
+	// Filtered by event and severity
+	bus := events.NewBus()
+	s.Subscribe(bus)
+	bus.Publish(ctx, &events.Event{Alert: newTestAlert(1, "info"), Source: events.SourceGossip, Type: events.AlertEnforced})
+	bus.Publish(ctx, &events.Event{Type: events.PeerBanned})
+	bus.Publish(ctx, &events.Event{Err: errors.New("rpc error"), Type: events.NodeUnhealthy})
+
+	// Sent after the retries
+	select {
+	case n := <-c.sent:
+		assert.Equal(t, events.NodeUnhealthy, n.Event)
+		assert.Equal(t, "rpc error", n.Error)
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification was not sent")
+	}
+	select {
+	case n := <-c.sent:
+		t.Fatalf("unexpected notification %s", n.Title)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The unhealthy node is notified once, then the resolution (below the min severity)
+	bus.Publish(ctx, &events.Event{Err: errors.New("rpc error"), Type: events.NodeUnhealthy})
+	bus.Publish(ctx, &events.Event{Type: events.NodeHealthy})
+	select {
+	case n := <-c.sent:
+		assert.Equal(t, events.NodeHealthy, n.Event)
+		assert.True(t, n.Resolved)
+	case <-time.After(5 * time.Second):
+		t.Fatal("resolution was not sent")
+	}
+}
+
+// TestService_ReplayedEvent will test that a replayed outbox event is notified once (with the same message ID)
+func TestService_ReplayedEvent(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	c := &testChannel{sent: make(chan *Notification, 10)}
+	require.NoError(t, s.addRoute(&Route{Notifier: c}))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.Start(ctx)
+	defer s.Stop()
+
+	bus := events.NewBus()
+	s.Subscribe(bus)
+	event := events.Event{Alert: newTestAlert(1, "info"), ID: "alert.enforced:1:gossip:1", Source: events.SourceGossip, Type: events.AlertEnforced}
+	first, replayed := event, event
+	replayed.Replayed = true
+	replayed.Time = time.Now().Add(time.Minute)
+	bus.Publish(ctx, &first)
+	bus.Publish(ctx, &replayed)
+
+	select {
+	case n := <-c.sent:
+		assert.Equal(t, event.ID, n.ID)
+		assert.Equal(t, messageID(NewNotification(&first)), messageID(NewNotification(&replayed)))
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification was not sent")
+	}
+	select {
+	case n := <-c.sent:
+		t.Fatalf("replayed event was notified again: %s", n.Title)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// rateLimitedChannel counts the attempts (always past its rate cap)
+type rateLimitedChannel struct {
+	attempts atomic.Int32
+}
+
+// Name will return the name of the channel
+func (c *rateLimitedChannel) Name() string {
+	return "rate-limited"
+}
+
+// Send will count the attempt and fail
+func (c *rateLimitedChannel) Send(_ context.Context, _ *Notification) error {
+	c.attempts.Add(1)
+	return fmt.Errorf("%w: test", ErrRateLimited)
+}
+
+// TestService_process will test that the rate limited notifications are not retried
+func TestService_process(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	c := &rateLimitedChannel{}
+	r, err := newRoute(&Route{Notifier: c})
+	require.NoError(t, err)
+
+	s.process(context.Background(), &delivery{notification: &Notification{Event: events.NodeUnhealthy}, route: r})
+	assert.Empty(t, s.queue)
+	time.Sleep(20 * time.Millisecond) // Longer than the retry interval
+	assert.Empty(t, s.queue)
+	assert.Equal(t, int32(1), c.attempts.Load())
+}
+
+// TestService_Flush will test sending the queued notifications at shutdown
+func TestService_Flush(t *testing.T) {
+	t.Parallel()
+
+	t.Run("queued notifications are sent", func(t *testing.T) {
+		s := newTestService(t)
+		c := &testChannel{failures: 1, sent: make(chan *Notification, 10)}
+		r, err := newRoute(&Route{Notifier: c})
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			s.enqueue(&delivery{notification: &Notification{Event: events.AlertEnforced}, route: r})
+		}
+
+		// The failed notification is not retried after the flush
+		require.NoError(t, s.Flush(context.Background()))
+		assert.Len(t, c.sent, 2)
+		assert.Empty(t, s.queue)
+		s.enqueue(&delivery{notification: &Notification{Event: events.AlertEnforced}, route: r})
+		assert.Empty(t, s.queue)
+	})
+
+	t.Run("context is done", func(t *testing.T) {
+		s := newTestService(t)
+		r, err := newRoute(&Route{Notifier: &testChannel{sent: make(chan *Notification, 10)}})
+		require.NoError(t, err)
+		s.enqueue(&delivery{notification: &Notification{Event: events.AlertEnforced}, route: r})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err = s.Flush(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Contains(t, err.Error(), "1 notifications not sent")
+	})
+}
+
+// closingChannel is a channel holding a connection
+type closingChannel struct {
+	testChannel
+	closed atomic.Int32
+	err    error
+}
+
+// Close will record the close
+func (c *closingChannel) Close() error {
+	c.closed.Add(1)
+	return c.err
+}
+
+// TestService_Close will test closing the channels holding a connection
+func TestService_Close(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	ok := &closingChannel{}
+	failing := &closingChannel{err: errors.New("connection reset")}
+	for _, c := range []Notifier{ok, &testChannel{}, failing} {
+		require.NoError(t, s.addRoute(&Route{Notifier: c}))
+	}
+
+	err := s.Close()
+	require.Error(t, err)
+	assert.Equal(t, "test: connection reset", err.Error())
+	assert.Equal(t, int32(1), ok.closed.Load())
+
+	// The channels are closed once
+	require.NoError(t, s.Close())
+	assert.Equal(t, int32(1), ok.closed.Load())
+	assert.Equal(t, int32(1), failing.closed.Load())
+}
@@ -0,0 +1,175 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+)
+
+// slackPostMessageURL is the Slack Web API method used with a bot token
+const slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+
+// DefaultSlackTemplate is the default Slack message (mrkdwn, the notification is the template data)
+const DefaultSlackTemplate = "*{{.Title}}*" +
+	"{{if .Summary}}\n{{.Summary}}{{end}}" +
+	"{{if .Error}}\nError: `{{.Error}}`{{end}}" +
+	"{{if .PeerID}}\nPeer: `{{.PeerID}}`{{end}}"
+
+// slackColors are the attachment colors of the severities
+var slackColors = map[Severity]string{
+	SeverityCritical: "#a30200",
+	SeverityInfo:     "#2eb886",
+	SeverityWarning:  "#daa038",
+}
+
+// slackMessage is the message posted to an incoming webhook or chat.postMessage
+type slackMessage struct {
+	Attachments []*slackAttachment `json:"attachments"`
+	Channel     string             `json:"channel,omitempty"` // Only with a bot token
+	Text        string             `json:"text"`              // Fallback for the notifications
+}
+
+// slackAttachment is the message body (colored by severity)
+type slackAttachment struct {
+	Color    string   `json:"color"`
+	Footer   string   `json:"footer,omitempty"`
+	MrkdwnIn []string `json:"mrkdwn_in"`
+	Text     string   `json:"text"`
+	Ts       int64    `json:"ts"`
+}
+
+// slackResponse is the response of the Slack Web API
+type slackResponse struct {
+	Error string `json:"error"`
+	OK    bool   `json:"ok"`
+}
+
+// slack posts the notifications to Slack (incoming webhook or bot token)
+type slack struct {
+	apiURL      string
+	botToken    string
+	channels    map[Severity]string // Destination per severity
+	destination string              // Channel (bot token) or incoming webhook URL
+	httpClient  config.HTTPInterface
+	template    *template.Template
+}
+
+// newSlack will create the Slack channel
+// With a bot token the destinations are channels, otherwise they are incoming webhook URLs
+func newSlack(conf config.SlackConfig, httpClient config.HTTPInterface) (*slack, error) {
+	s := &slack{
+		apiURL:      slackPostMessageURL,
+		botToken:    conf.BotToken,
+		channels:    make(map[Severity]string, len(conf.Channels)),
+		destination: conf.WebhookURL,
+		httpClient:  httpClient,
+	}
+	if len(s.botToken) > 0 {
+		s.destination = conf.Channel
+	}
+
+	// Route the severities to their own channel
+	for name, destination := range conf.Channels {
+		severity, err := ParseSeverity(name)
+		if err != nil {
+			return nil, fmt.Errorf("slack: %w", err)
+		}
+		s.channels[severity] = destination
+	}
+	if len(s.botToken) > 0 && len(s.destination) == 0 && len(s.channels) == 0 {
+		return nil, ErrSlackNoChannel
+	}
+
+	// Parse the message template
+	text := conf.Template
+	if len(text) == 0 {
+		text = DefaultSlackTemplate
+	}
+	var err error
+	if s.template, err = template.New("slack").Parse(text); err != nil {
+		return nil, fmt.Errorf("slack template: %w", err)
+	}
+	return s, nil
+}
+
+// Name will return the name of the channel
+func (s *slack) Name() string {
+	return "slack"
+}
+
+// Send will post the notification to the channel of its severity
+func (s *slack) Send(ctx context.Context, n *Notification) error {
+	destination := s.destination
+	if d, ok := s.channels[n.Severity]; ok {
+		destination = d
+	}
+	if len(destination) == 0 {
+		return nil // Severity is not routed
+	}
+
+	// Render the message
+	var text strings.Builder
+	if len(n.Message) > 0 {
+		text.WriteString(n.Message)
+	} else if err := s.template.Execute(&text, n); err != nil {
+		return err
+	}
+	msg := &slackMessage{
+		Attachments: []*slackAttachment{{
+			Color:    slackColors[n.Severity],
+			Footer:   "alert-system " + n.Node,
+			MrkdwnIn: []string{"text"},
+			Text:     text.String(),
+			Ts:       n.Time.Unix(),
+		}},
+		Text: "[" + n.Severity.String() + "] " + n.Title,
+	}
+
+	// Post to the incoming webhook, or to chat.postMessage with the bot token
+	url := destination
+	if len(s.botToken) > 0 {
+		msg.Channel = destination
+		url = s.apiURL
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body)); err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if len(s.botToken) > 0 {
+		req.Header.Set("Authorization", "Bearer "+s.botToken)
+	}
+
+	var res *http.Response
+	if res, err = s.httpClient.Do(req); err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: slack status code %d", ErrSendFailed, res.StatusCode)
+	}
+
+	// The Web API reports the errors in the body
+	if len(s.botToken) > 0 {
+		result := &slackResponse{}
+		if err = json.NewDecoder(io.LimitReader(res.Body, 1<<16)).Decode(result); err != nil {
+			return err
+		} else if !result.OK {
+			return fmt.Errorf("%w: slack error %s", ErrSendFailed, result.Error)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockHTTPClient is a mock HTTP client for testing purposes
+type mockHTTPClient struct {
+	doFunc func(req *http.Request) (*http.Response, error)
+}
+
+// Do is the mock HTTP client Do function
+func (c *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return c.doFunc(req)
+}
+
+// newResponse will create a response with the status and body
+func newResponse(status int, body string) *http.Response {
+	return &http.Response{Body: io.NopCloser(strings.NewReader(body)), StatusCode: status}
+}
+
+// readSlackMessage will decode the posted Slack message
+func readSlackMessage(t *testing.T, req *http.Request) *slackMessage {
+	msg := &slackMessage{}
+	require.NoError(t, json.NewDecoder(req.Body).Decode(msg))
+	return msg
+}
+
+// TestNewSlack will test the method newSlack()
+func TestNewSlack(t *testing.T) {
+	t.Parallel()
+
+	t.Run("bot token requires a channel", func(t *testing.T) {
+		_, err := newSlack(config.SlackConfig{BotToken: "xoxb-token"}, nil)
+		require.ErrorIs(t, err, ErrSlackNoChannel)
+	})
+
+	t.Run("invalid severity", func(t *testing.T) {
+		_, err := newSlack(config.SlackConfig{
+			Channels: map[string]string{"urgent": "https://hooks.slack.com/x"}, WebhookURL: "https://hooks.slack.com/y",
+		}, nil)
+		require.ErrorIs(t, err, ErrInvalidSeverity)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		_, err := newSlack(config.SlackConfig{Template: "{{.Title", WebhookURL: "https://hooks.slack.com/y"}, nil)
+		require.Error(t, err)
+	})
+}
+
+// TestSlack_Send will test the method Send()
+func TestSlack_Send(t *testing.T) {
+	t.Parallel()
+
+	n := &Notification{Severity: SeverityCritical, Summary: "Informational: hello", Title: "Alert 42 (Informational) failed on the node"}
+
+	t.Run("incoming webhook routed by severity", func(t *testing.T) {
+		s, err := newSlack(config.SlackConfig{
+			Channels:   map[string]string{"critical": "https://hooks.slack.com/critical"},
+			WebhookURL: "https://hooks.slack.com/default",
+		}, &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "https://hooks.slack.com/critical", req.URL.String())
+			assert.Empty(t, req.Header.Get("Authorization"))
+			msg := readSlackMessage(t, req)
+			assert.Empty(t, msg.Channel)
+			assert.Equal(t, "[critical] Alert 42 (Informational) failed on the node", msg.Text)
+			require.Len(t, msg.Attachments, 1)
+			assert.Equal(t, slackColors[SeverityCritical], msg.Attachments[0].Color)
+			assert.Equal(t, "*Alert 42 (Informational) failed on the node*\nInformational: hello", msg.Attachments[0].Text)
+			return newResponse(http.StatusOK, "ok"), nil
+		}})
+		require.NoError(t, err)
+		require.NoError(t, s.Send(context.Background(), n))
+	})
+
+	t.Run("bot token with a template", func(t *testing.T) {
+		s, err := newSlack(config.SlackConfig{
+			BotToken: "xoxb-token", Channel: "#alerts", Template: "{{.Severity}}: {{.Title}}",
+		}, &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, slackPostMessageURL, req.URL.String())
+			assert.Equal(t, "Bearer xoxb-token", req.Header.Get("Authorization"))
+			msg := readSlackMessage(t, req)
+			assert.Equal(t, "#alerts", msg.Channel)
+			assert.Equal(t, "critical: Alert 42 (Informational) failed on the node", msg.Attachments[0].Text)
+			return newResponse(http.StatusOK, `{"ok":true}`), nil
+		}})
+		require.NoError(t, err)
+		require.NoError(t, s.Send(context.Background(), n))
+	})
+
+	t.Run("web api error", func(t *testing.T) {
+		s, err := newSlack(config.SlackConfig{BotToken: "xoxb-token", Channel: "#alerts"}, &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return newResponse(http.StatusOK, `{"ok":false,"error":"channel_not_found"}`), nil
+			},
+		})
+		require.NoError(t, err)
+		err = s.Send(context.Background(), n)
+		require.ErrorIs(t, err, ErrSendFailed)
+		assert.Contains(t, err.Error(), "channel_not_found")
+	})
+
+	t.Run("webhook error status", func(t *testing.T) {
+		s, err := newSlack(config.SlackConfig{WebhookURL: "https://hooks.slack.com/default"}, &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return newResponse(http.StatusNotFound, "no_team"), nil
+			},
+		})
+		require.NoError(t, err)
+		require.ErrorIs(t, s.Send(context.Background(), n), ErrSendFailed)
+	})
+
+	t.Run("http client error", func(t *testing.T) {
+		s, err := newSlack(config.SlackConfig{WebhookURL: "https://hooks.slack.com/default"}, &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return nil, errors.New("HTTP client error")
+			},
+		})
+		require.NoError(t, err)
+		require.Error(t, s.Send(context.Background(), n))
+	})
+}
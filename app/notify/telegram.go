@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+)
+
+// DefaultTelegramURL is the Telegram Bot API endpoint
+const DefaultTelegramURL = "https://api.telegram.org"
+
+// maxTelegramText is the max length of a Telegram message
+const maxTelegramText = 4096
+
+// telegramMessage is the sendMessage request
+type telegramMessage struct {
+	ChatID                string `json:"chat_id"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview"`
+	ParseMode             string `json:"parse_mode"`
+	Text                  string `json:"text"`
+}
+
+// telegramResponse is the response of the Bot API
+type telegramResponse struct {
+	Description string `json:"description"`
+	OK          bool   `json:"ok"`
+}
+
+// telegram sends the notifications to a Telegram chat with a bot
+type telegram struct {
+	chatID     string
+	httpClient config.HTTPInterface
+	url        string // sendMessage URL (includes the bot token)
+}
+
+// newTelegram will create a Telegram channel for each chat (retried separately)
+func newTelegram(conf config.TelegramConfig, httpClient config.HTTPInterface) ([]*telegram, error) {
+	if len(conf.ChatIDs) == 0 {
+		return nil, ErrTelegramNoChats
+	}
+	baseURL := conf.URL
+	if len(baseURL) == 0 {
+		baseURL = DefaultTelegramURL
+	}
+	chats := make([]*telegram, 0, len(conf.ChatIDs))
+	for _, chatID := range conf.ChatIDs {
+		chats = append(chats, &telegram{
+			chatID:     chatID,
+			httpClient: httpClient,
+			url:        strings.TrimRight(baseURL, "/") + "/bot" + conf.BotToken + "/sendMessage",
+		})
+	}
+	return chats, nil
+}
+
+// Name will return the name of the channel
+func (t *telegram) Name() string {
+	return "telegram"
+}
+
+// Send will send the notification to the chat (HTML formatted)
+func (t *telegram) Send(ctx context.Context, n *Notification) error {
+	text := "<b>[" + n.Severity.String() + "] " + html.EscapeString(n.Title) + "</b>"
+	if len(n.Error) > 0 {
+		text += "\nError: <code>" + html.EscapeString(n.Error) + "</code>"
+	}
+	if len(n.PeerID) > 0 {
+		text += "\nPeer: <code>" + html.EscapeString(n.PeerID) + "</code>"
+	}
+	if len(n.Summary) > 0 {
+		summary := n.Summary // Truncated to fit the max message length
+		if room := maxTelegramText - len(text) - 64; len(html.EscapeString(summary)) > room && room > 0 {
+			summary = summary[:room/6] + "..."
+		}
+		text += "\n" + html.EscapeString(summary)
+	}
+	if len(n.Message) > 0 { // The template renders the HTML message
+		text = truncate(n.Message, maxTelegramText)
+	}
+
+	body, err := json.Marshal(&telegramMessage{
+		ChatID: t.chatID, DisableWebPagePreview: true, ParseMode: "HTML", Text: text,
+	})
+	if err != nil {
+		return err
+	}
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body)); err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var res *http.Response
+	if res, err = t.httpClient.Do(req); err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) { // Drop the URL (it has the bot token)
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram chat %s: %w", t.chatID, err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	// The Bot API reports the errors in the body
+	result := &telegramResponse{}
+	if err = json.NewDecoder(io.LimitReader(res.Body, 1<<16)).Decode(result); err != nil {
+		return fmt.Errorf("%w: telegram status code %d", ErrSendFailed, res.StatusCode)
+	} else if !result.OK {
+		return fmt.Errorf("%w: telegram chat %s: %s", ErrSendFailed, t.chatID, result.Description)
+	}
+	return nil
+}
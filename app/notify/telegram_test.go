@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewTelegram will test the method newTelegram()
+func TestNewTelegram(t *testing.T) {
+	t.Parallel()
+
+	_, err := newTelegram(config.TelegramConfig{BotToken: "123:abc"}, nil)
+	require.ErrorIs(t, err, ErrTelegramNoChats)
+
+	chats, err := newTelegram(config.TelegramConfig{BotToken: "123:abc", ChatIDs: []string{"-100123", "@alerts"}}, nil)
+	require.NoError(t, err)
+	require.Len(t, chats, 2)
+	assert.Equal(t, "https://api.telegram.org/bot123:abc/sendMessage", chats[0].url)
+	assert.Equal(t, "@alerts", chats[1].chatID)
+}
+
+// TestTelegram_Send will test the method Send()
+func TestTelegram_Send(t *testing.T) {
+	t.Parallel()
+
+	n := &Notification{Error: "rpc <error>", Severity: SeverityCritical, Summary: "Informational: hello", Title: "Alert 42 (Informational) failed on the node"}
+
+	t.Run("html message", func(t *testing.T) {
+		chats, err := newTelegram(config.TelegramConfig{BotToken: "123:abc", ChatIDs: []string{"-100123"}}, &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				msg := &telegramMessage{}
+				require.NoError(t, json.NewDecoder(req.Body).Decode(msg))
+				assert.Equal(t, "-100123", msg.ChatID)
+				assert.Equal(t, "HTML", msg.ParseMode)
+				assert.Equal(t, "<b>[critical] Alert 42 (Informational) failed on the node</b>\nError: <code>rpc &lt;error&gt;</code>\nInformational: hello", msg.Text)
+				return newResponse(http.StatusOK, `{"ok":true}`), nil
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, chats[0].Send(context.Background(), n))
+	})
+
+	t.Run("bot api error", func(t *testing.T) {
+		chats, err := newTelegram(config.TelegramConfig{BotToken: "123:abc", ChatIDs: []string{"-100123"}}, &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return newResponse(http.StatusBadRequest, `{"ok":false,"description":"Bad Request: chat not found"}`), nil
+			},
+		})
+		require.NoError(t, err)
+		err = chats[0].Send(context.Background(), n)
+		require.ErrorIs(t, err, ErrSendFailed)
+		assert.Contains(t, err.Error(), "chat not found")
+	})
+
+	t.Run("the bot token is not in the errors", func(t *testing.T) {
+		chats, err := newTelegram(config.TelegramConfig{BotToken: "123:abc", ChatIDs: []string{"-100123"}}, http.DefaultClient)
+		require.NoError(t, err)
+		chats[0].url = "http://127.0.0.1:0/bot123:abc/sendMessage"
+		err = chats[0].Send(context.Background(), n)
+		require.Error(t, err)
+		assert.False(t, strings.Contains(err.Error(), "123:abc"))
+	})
+
+	t.Run("http client error", func(t *testing.T) {
+		chats, err := newTelegram(config.TelegramConfig{BotToken: "123:abc", ChatIDs: []string{"-100123"}}, &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return nil, errors.New("HTTP client error")
+			},
+		})
+		require.NoError(t, err)
+		require.Error(t, chats[0].Send(context.Background(), n))
+	})
+}
@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/bitcoin-sv/alert-system/app/models"
+)
+
+// TemplateDefault is the template key matching every channel or every alert type
+const TemplateDefault = "default"
+
+// templateFuncs are the functions available to the message templates (besides the text/template built-ins)
+var templateFuncs = template.FuncMap{
+	"hex":   hexString,
+	"lower": strings.ToLower,
+	"str":   func(b []byte) string { return string(b) },
+	"trunc": func(max int, text string) string { return truncate(text, max) }, // {{.Summary | trunc 100}}
+	"upper": strings.ToUpper,
+}
+
+// AlertTypeKey will return the template key of the alert type (e.g. invalidate_block)
+func AlertTypeKey(alertType models.AlertType) string {
+	return strings.ReplaceAll(strings.ToLower(alertType.Name()), " ", "_")
+}
+
+// alertTypeKeys will return the template keys of the alert types
+func alertTypeKeys() map[string]bool {
+	keys := make(map[string]bool)
+	for alertType := models.AlertTypeInformational; alertType <= models.AlertTypeSetKeys; alertType++ {
+		keys[AlertTypeKey(alertType)] = true
+	}
+	return keys
+}
+
+// Templates renders the messages of the notifications from the configured Go templates
+// The templates are keyed by channel (or default) then by alert type (or default), the notification is the data
+// The decoded alert is .Payload (e.g. {{.Payload.BlockHash}} for an invalidate block alert)
+type Templates struct {
+	templates map[string]map[string]*template.Template
+}
+
+// NewTemplates will parse the templates (keyed by channel name then by alert type key)
+func NewTemplates(conf map[string]map[string]string) (*Templates, error) {
+	channels := map[string]bool{TemplateDefault: true}
+	for _, name := range Channels() {
+		channels[name] = true
+	}
+	alertTypes := alertTypeKeys()
+	alertTypes[TemplateDefault] = true
+
+	t := &Templates{templates: make(map[string]map[string]*template.Template, len(conf))}
+	for channel, byType := range conf {
+		if !channels[channel] {
+			return nil, fmt.Errorf("%w: unknown channel %s", ErrInvalidTemplate, channel)
+		}
+		t.templates[channel] = make(map[string]*template.Template, len(byType))
+		for alertType, text := range byType {
+			if !alertTypes[alertType] {
+				return nil, fmt.Errorf("%w: unknown alert type %s (%s)", ErrInvalidTemplate, alertType, channel)
+			}
+			parsed, err := template.New(channel + "." + alertType).Funcs(templateFuncs).Parse(text)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s.%s: %s", ErrInvalidTemplate, channel, alertType, err.Error())
+			}
+			t.templates[channel][alertType] = parsed
+		}
+	}
+	return t, nil
+}
+
+// lookup will return the most specific template of the channel and notification (nil if none is configured)
+// The order is channel and alert type, channel, default channel and alert type, then default channel
+func (t *Templates) lookup(channel string, n *Notification) *template.Template {
+	alertType := TemplateDefault
+	if n.AlertType > 0 {
+		alertType = AlertTypeKey(models.AlertType(n.AlertType))
+	}
+	for _, c := range []string{channel, TemplateDefault} {
+		if byType, ok := t.templates[c]; ok {
+			if tmpl, found := byType[alertType]; found {
+				return tmpl
+			} else if tmpl, found = byType[TemplateDefault]; found {
+				return tmpl
+			}
+		}
+	}
+	return nil
+}
+
+// Render will render the message of the notification for the channel
+// Returns false if no template is configured (the channel formats its own message)
+func (t *Templates) Render(channel string, n *Notification) (string, bool, error) {
+	tmpl := t.lookup(channel, n)
+	if tmpl == nil {
+		return "", false, nil
+	}
+	var message strings.Builder
+	if err := tmpl.Execute(&message, n); err != nil {
+		return "", false, err
+	}
+	return strings.TrimSpace(message.String()), true, nil
+}
+
+// hexString will encode the bytes (byte slices and arrays, or a hash) as hex
+func hexString(v interface{}) string {
+	switch b := v.(type) {
+	case []byte:
+		return hex.EncodeToString(b)
+	case [32]byte:
+		return hex.EncodeToString(b[:])
+	case [33]byte:
+		return hex.EncodeToString(b[:])
+	case fmt.Stringer:
+		return b.String()
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAlertTypeKey will test the method AlertTypeKey()
+func TestAlertTypeKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "informational", AlertTypeKey(models.AlertTypeInformational))
+	assert.Equal(t, "invalidate_block", AlertTypeKey(models.AlertTypeInvalidateBlock))
+	assert.Equal(t, "set_keys", AlertTypeKey(models.AlertTypeSetKeys))
+	assert.Len(t, alertTypeKeys(), 8)
+}
+
+// TestNewTemplates will test the method NewTemplates()
+func TestNewTemplates(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewTemplates(nil)
+	require.NoError(t, err)
+
+	_, err = NewTemplates(map[string]map[string]string{"irc": {"default": "{{.Title}}"}})
+	require.ErrorIs(t, err, ErrInvalidTemplate)
+
+	_, err = NewTemplates(map[string]map[string]string{"slack": {"freeze_utxo": "{{.Title}}"}})
+	require.ErrorIs(t, err, ErrInvalidTemplate)
+
+	_, err = NewTemplates(map[string]map[string]string{"slack": {"default": "{{.Title"}})
+	require.ErrorIs(t, err, ErrInvalidTemplate)
+}
+
+// TestTemplates_Render will test the method Render()
+func TestTemplates_Render(t *testing.T) {
+	t.Parallel()
+
+	templates, err := NewTemplates(map[string]map[string]string{
+		"default":  {"default": "{{.Title}}", "informational": "Info: {{.Summary}}"},
+		"slack":    {"default": "*{{.Title}}*"},
+		"telegram": {"informational": "{{.Payload.Message | str | upper}} ({{.Sequence}})"},
+	})
+	require.NoError(t, err)
+
+	alert := NewNotification(&events.Event{Alert: newTestAlert(42, "hello"), Source: events.SourceGossip, Type: events.AlertEnforced})
+	node := NewNotification(&events.Event{Type: events.NodeUnhealthy})
+
+	tests := []struct {
+		channel  string
+		n        *Notification
+		expected string
+	}{
+		{"telegram", alert, "HELLO (42)"},                                   // Channel and alert type
+		{"slack", alert, "*Alert 42 (Informational) enforced on the node*"}, // Channel
+		{"discord", alert, "Info: Informational: hello"},                    // Default channel and alert type
+		{"telegram", node, "Node is unhealthy"},                             // Default channel
+	}
+	for _, test := range tests {
+		message, ok, renderErr := templates.Render(test.channel, test.n)
+		require.NoError(t, renderErr)
+		assert.True(t, ok)
+		assert.Equal(t, test.expected, message, test.channel)
+	}
+
+	// No template
+	templates, err = NewTemplates(map[string]map[string]string{"slack": {"informational": "{{.Title}}"}})
+	require.NoError(t, err)
+	_, ok, err := templates.Render("slack", node)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Template functions
+	templates, err = NewTemplates(map[string]map[string]string{
+		"default": {"default": `{{.Title | trunc 8}} {{hex .Payload.Message}}`},
+	})
+	require.NoError(t, err)
+	message, _, err := templates.Render("slack", alert)
+	require.NoError(t, err)
+	assert.Equal(t, "Alert... 68656c6c6f", message)
+}
+
+// TestService_render will test the notifications are rendered per channel
+func TestService_render(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	s.templates = &Templates{templates: map[string]map[string]*template.Template{
+		"test": {"default": template.Must(template.New("test").Parse("{{.Title}}!"))},
+	}}
+	require.NoError(t, s.addRoute(&Route{Notifier: &testChannel{}}))
+	require.NoError(t, s.addRoute(&Route{Notifier: &rateLimitedChannel{}}))
+
+	n := &Notification{Event: events.NodeUnhealthy, Title: "Node is unhealthy", Time: time.Now()}
+	rendered := s.render(s.routes[0], n)
+	assert.Equal(t, "Node is unhealthy!", rendered.Message)
+	assert.Empty(t, n.Message, "the notification is shared by the channels")
+	assert.Same(t, n, s.render(s.routes[1], n))
+
+	// A failing template falls back to the channel message
+	s.templates.templates["test"]["default"] = template.Must(template.New("test").Parse("{{.Payload.Missing}}"))
+	failing := &Notification{Payload: 1}
+	assert.Same(t, failing, s.render(s.routes[0], failing))
+}
@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+)
+
+// teranodeNotificationType is the type of the notifications announcing an alert
+const teranodeNotificationType = "alert"
+
+// teranodeNotification announces an alert to the Teranode notification service
+// The signed alert is fetched from the blob service (base URL) by its hash
+type teranodeNotification struct {
+	AlertType uint32 `json:"alertType"`
+	BaseURL   string `json:"baseUrl,omitempty"`
+	Event     string `json:"event"`
+	Hash      string `json:"hash"`
+	PeerID    string `json:"peerId,omitempty"`
+	Sequence  uint32 `json:"sequence"`
+	Timestamp string `json:"timestamp"`
+	Type      string `json:"type"`
+}
+
+// teranode forwards the alerts to the Teranode blob and notification services
+type teranode struct {
+	blobURL         string
+	httpClient      config.HTTPInterface
+	notificationURL string
+	token           string
+}
+
+// newTeranode will create the Teranode channel
+func newTeranode(conf config.TeranodeConfig, httpClient config.HTTPInterface) *teranode {
+	return &teranode{
+		blobURL:         strings.TrimSuffix(conf.BlobURL, "/"),
+		httpClient:      httpClient,
+		notificationURL: conf.NotificationURL,
+		token:           conf.Token,
+	}
+}
+
+// Name will return the name of the channel
+func (t *teranode) Name() string {
+	return "teranode"
+}
+
+// Send will store the signed alert in the blob service, then announce it to the notification service
+// The blob is keyed by the alert hash, a retry overwrites it with the same alert
+// Node and peer events are not forwarded (Teranode only consumes the alerts)
+func (t *teranode) Send(ctx context.Context, n *Notification) error {
+	if len(n.Hash) == 0 || len(n.Raw) == 0 {
+		return nil
+	}
+
+	if len(t.blobURL) > 0 {
+		raw, err := hex.DecodeString(n.Raw)
+		if err != nil {
+			return err
+		}
+		blobURL := t.blobURL + "/blob/" + url.PathEscape(n.Hash) + "?fileType=alert"
+		if err = t.do(ctx, http.MethodPut, blobURL, "application/octet-stream", raw); err != nil {
+			return fmt.Errorf("teranode blob: %w", err)
+		}
+	}
+
+	if len(t.notificationURL) > 0 {
+		body, err := json.Marshal(&teranodeNotification{
+			AlertType: n.AlertType,
+			BaseURL:   t.blobURL,
+			Event:     string(n.Event),
+			Hash:      n.Hash,
+			PeerID:    n.PeerID,
+			Sequence:  n.Sequence,
+			Timestamp: n.Time.UTC().Format(time.RFC3339),
+			Type:      teranodeNotificationType,
+		})
+		if err != nil {
+			return err
+		}
+		if err = t.do(ctx, http.MethodPost, t.notificationURL, "application/json", body); err != nil {
+			return fmt.Errorf("teranode notification: %w", err)
+		}
+	}
+	return nil
+}
+
+// do will send the request to a Teranode service
+func (t *teranode) do(ctx context.Context, method, requestURL, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if len(t.token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+
+	var res *http.Response
+	if res, err = t.httpClient.Do(req); err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("%w: status code %d: %s", ErrSendFailed, res.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
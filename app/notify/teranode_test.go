@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTeranode_Send will test the method Send()
+func TestTeranode_Send(t *testing.T) {
+	t.Parallel()
+
+	n := &Notification{
+		AlertType: 1, Event: events.AlertVerified, Hash: "abcd", PeerID: "peer", Raw: "0102ff", Sequence: 42,
+		Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	t.Run("blob then notification", func(t *testing.T) {
+		var requests []*http.Request
+		var bodies [][]byte
+		c := newTeranode(config.TeranodeConfig{
+			BlobURL: "http://teranode:8090/", NotificationURL: "http://teranode:8091/notify", Token: "secret",
+		}, &mockHTTPClient{doFunc: func(req *http.Request) (*http.Response, error) {
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			requests = append(requests, req)
+			bodies = append(bodies, body)
+			return newResponse(http.StatusOK, ""), nil
+		}})
+		require.NoError(t, c.Send(context.Background(), n))
+		require.Len(t, requests, 2)
+
+		assert.Equal(t, http.MethodPut, requests[0].Method)
+		assert.Equal(t, "http://teranode:8090/blob/abcd?fileType=alert", requests[0].URL.String())
+		assert.Equal(t, "Bearer secret", requests[0].Header.Get("Authorization"))
+		assert.Equal(t, []byte{0x01, 0x02, 0xff}, bodies[0])
+
+		assert.Equal(t, http.MethodPost, requests[1].Method)
+		assert.Equal(t, "http://teranode:8091/notify", requests[1].URL.String())
+		msg := &teranodeNotification{}
+		require.NoError(t, json.Unmarshal(bodies[1], msg))
+		assert.Equal(t, teranodeNotification{
+			AlertType: 1, BaseURL: "http://teranode:8090", Event: "alert.verified", Hash: "abcd", PeerID: "peer",
+			Sequence: 42, Timestamp: "2024-01-02T03:04:05Z", Type: "alert",
+		}, *msg)
+	})
+
+	t.Run("node events are not forwarded", func(t *testing.T) {
+		c := newTeranode(config.TeranodeConfig{NotificationURL: "http://teranode/notify"}, &mockHTTPClient{
+			doFunc: func(*http.Request) (*http.Response, error) {
+				t.Fatal("unexpected request")
+				return nil, nil
+			},
+		})
+		require.NoError(t, c.Send(context.Background(), &Notification{Event: events.NodeUnhealthy, Node: "localhost:8332"}))
+	})
+
+	t.Run("blob service error", func(t *testing.T) {
+		c := newTeranode(config.TeranodeConfig{BlobURL: "http://teranode"}, &mockHTTPClient{
+			doFunc: func(*http.Request) (*http.Response, error) {
+				return newResponse(http.StatusServiceUnavailable, "store is down\n"), nil
+			},
+		})
+		err := c.Send(context.Background(), n)
+		require.ErrorIs(t, err, ErrSendFailed)
+		assert.Equal(t, "teranode blob: notification send failed: status code 503: store is down", err.Error())
+	})
+}
+
+// TestTeranodeRoutes will test the default events of the Teranode route
+func TestTeranodeRoutes(t *testing.T) {
+	t.Parallel()
+
+	r, err := teranodeRoutes(&config.Config{})
+	require.NoError(t, err)
+	assert.Empty(t, r)
+
+	conf := &config.Config{}
+	conf.Notifications.Teranode = config.TeranodeConfig{BlobURL: "http://teranode"}
+	r, err = teranodeRoutes(conf)
+	require.NoError(t, err)
+	require.Len(t, r, 1)
+	assert.Equal(t, []string{"alert.verified"}, r[0].Events)
+}
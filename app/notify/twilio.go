@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+)
+
+// DefaultTwilioURL is the Twilio REST API endpoint
+const DefaultTwilioURL = "https://api.twilio.com"
+
+// DefaultTwilioMaxPerHour is the max number of SMS sent to a number per hour
+const DefaultTwilioMaxPerHour = 5
+
+// maxTwilioBody is the max length of an SMS body (Twilio splits it in segments)
+const maxTwilioBody = 1600
+
+// twilioError is an error of the REST API
+type twilioError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// rateCap limits the number of messages sent in a sliding window
+type rateCap struct {
+	max    int
+	mu     sync.Mutex
+	now    func() time.Time
+	sent   []time.Time // Times of the messages sent in the window (oldest first)
+	window time.Duration
+}
+
+// allow will return true (and count the message) if the cap is not reached
+func (c *rateCap) allow() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for len(c.sent) > 0 && now.Sub(c.sent[0]) >= c.window {
+		c.sent = c.sent[1:]
+	}
+	if len(c.sent) >= c.max {
+		return false
+	}
+	c.sent = append(c.sent, now)
+	return true
+}
+
+// twilio sends the notifications by SMS to a phone number
+type twilio struct {
+	accountSID string
+	authToken  string
+	cap        *rateCap
+	from       string
+	httpClient config.HTTPInterface
+	to         string
+	url        string // Messages URL of the account
+}
+
+// newTwilio will create a Twilio channel for each phone number (retried and capped separately)
+func newTwilio(conf config.TwilioConfig, httpClient config.HTTPInterface) ([]*twilio, error) {
+	if len(conf.AuthToken) == 0 {
+		return nil, ErrTwilioNoAuthToken
+	} else if len(conf.From) == 0 {
+		return nil, ErrTwilioNoSender
+	} else if len(conf.To) == 0 {
+		return nil, ErrTwilioNoRecipients
+	}
+	baseURL := conf.URL
+	if len(baseURL) == 0 {
+		baseURL = DefaultTwilioURL
+	}
+	maxPerHour := conf.MaxPerHour
+	if maxPerHour <= 0 {
+		maxPerHour = DefaultTwilioMaxPerHour
+	}
+	numbers := make([]*twilio, 0, len(conf.To))
+	for _, to := range conf.To {
+		numbers = append(numbers, &twilio{
+			accountSID: conf.AccountSID,
+			authToken:  conf.AuthToken,
+			cap:        &rateCap{max: maxPerHour, now: time.Now, window: time.Hour},
+			from:       conf.From,
+			httpClient: httpClient,
+			to:         to,
+			url: strings.TrimRight(baseURL, "/") + "/2010-04-01/Accounts/" +
+				url.PathEscape(conf.AccountSID) + "/Messages.json",
+		})
+	}
+	return numbers, nil
+}
+
+// Name will return the name of the channel
+func (t *twilio) Name() string {
+	return "twilio"
+}
+
+// Send will send the notification by SMS (plain text)
+// Past the hourly cap the notification is dropped (ErrRateLimited), a flood of alerts must not flood the phone
+func (t *twilio) Send(ctx context.Context, n *Notification) error {
+	if !t.cap.allow() {
+		return fmt.Errorf("%w: twilio %s", ErrRateLimited, t.to)
+	}
+
+	body := n.Text()
+	if len(n.Message) > 0 {
+		body = n.Message
+	}
+	form := url.Values{}
+	form.Set("Body", truncate(body, maxTwilioBody))
+	form.Set("To", t.to)
+	if strings.HasPrefix(t.from, "MG") { // Messaging service SID instead of a phone number
+		form.Set("MessagingServiceSid", t.from)
+	} else {
+		form.Set("From", t.from)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(t.accountSID, t.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var res *http.Response
+	if res, err = t.httpClient.Do(req); err != nil {
+		return fmt.Errorf("twilio %s: %w", t.to, err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusMultipleChoices {
+		return nil
+	}
+
+	// The REST API reports the errors in the body
+	result := &twilioError{}
+	if err = json.NewDecoder(io.LimitReader(res.Body, 1<<16)).Decode(result); err != nil || result.Code == 0 {
+		return fmt.Errorf("%w: twilio %s: status code %d", ErrSendFailed, t.to, res.StatusCode)
+	}
+	return fmt.Errorf("%w: twilio %s: %d: %s", ErrSendFailed, t.to, result.Code, result.Message)
+}
@@ -0,0 +1,169 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTwilioConfig is a valid Twilio config
+var testTwilioConfig = config.TwilioConfig{
+	AccountSID: "AC123", AuthToken: "secret", From: "+15550001111", To: []string{"+15550002222", "+15550003333"},
+}
+
+// TestNewTwilio will test the method newTwilio()
+func TestNewTwilio(t *testing.T) {
+	t.Parallel()
+
+	_, err := newTwilio(config.TwilioConfig{AccountSID: "AC123", From: "+15550001111", To: []string{"+15550002222"}}, nil)
+	require.ErrorIs(t, err, ErrTwilioNoAuthToken)
+
+	_, err = newTwilio(config.TwilioConfig{AccountSID: "AC123", AuthToken: "secret", To: []string{"+15550002222"}}, nil)
+	require.ErrorIs(t, err, ErrTwilioNoSender)
+
+	_, err = newTwilio(config.TwilioConfig{AccountSID: "AC123", AuthToken: "secret", From: "+15550001111"}, nil)
+	require.ErrorIs(t, err, ErrTwilioNoRecipients)
+
+	numbers, err := newTwilio(testTwilioConfig, nil)
+	require.NoError(t, err)
+	require.Len(t, numbers, 2)
+	assert.Equal(t, "https://api.twilio.com/2010-04-01/Accounts/AC123/Messages.json", numbers[0].url)
+	assert.Equal(t, "+15550003333", numbers[1].to)
+	assert.Equal(t, DefaultTwilioMaxPerHour, numbers[0].cap.max)
+	assert.NotSame(t, numbers[0].cap, numbers[1].cap)
+}
+
+// TestTwilioRoutes will test the method twilioRoutes()
+func TestTwilioRoutes(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{}
+	routes, err := twilioRoutes(conf)
+	require.NoError(t, err)
+	assert.Empty(t, routes)
+
+	conf.Notifications.Twilio = testTwilioConfig
+	routes, err = twilioRoutes(conf)
+	require.NoError(t, err)
+	require.Len(t, routes, 2)
+	assert.Equal(t, severityCritical, routes[0].MinSeverity)
+}
+
+// TestTwilio_Send will test the method Send()
+func TestTwilio_Send(t *testing.T) {
+	t.Parallel()
+
+	n := &Notification{Severity: SeverityCritical, Summary: "Invalidate Block: 0000abc", Title: "Alert 42 (Invalidate Block) enforced on the node"}
+
+	t.Run("sms", func(t *testing.T) {
+		numbers, err := newTwilio(testTwilioConfig, &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, http.MethodPost, req.Method)
+				user, password, ok := req.BasicAuth()
+				assert.True(t, ok)
+				assert.Equal(t, "AC123", user)
+				assert.Equal(t, "secret", password)
+				require.NoError(t, req.ParseForm())
+				assert.Equal(t, n.Text(), req.PostForm.Get("Body"))
+				assert.Equal(t, "+15550001111", req.PostForm.Get("From"))
+				assert.Equal(t, "+15550002222", req.PostForm.Get("To"))
+				return newResponse(http.StatusCreated, `{"sid":"SM123","status":"queued"}`), nil
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, numbers[0].Send(context.Background(), n))
+	})
+
+	t.Run("messaging service", func(t *testing.T) {
+		conf := testTwilioConfig
+		conf.From = "MG123"
+		numbers, err := newTwilio(conf, &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				require.NoError(t, req.ParseForm())
+				assert.Equal(t, url.Values{"Body": {n.Text()}, "MessagingServiceSid": {"MG123"}, "To": {"+15550002222"}}, req.PostForm)
+				return newResponse(http.StatusCreated, `{}`), nil
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, numbers[0].Send(context.Background(), n))
+	})
+
+	t.Run("template message", func(t *testing.T) {
+		numbers, err := newTwilio(testTwilioConfig, &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				require.NoError(t, req.ParseForm())
+				assert.Equal(t, "Block invalidated", req.PostForm.Get("Body"))
+				return newResponse(http.StatusCreated, `{}`), nil
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, numbers[0].Send(context.Background(), &Notification{Message: "Block invalidated", Title: "Alert"}))
+	})
+
+	t.Run("long body", func(t *testing.T) {
+		numbers, err := newTwilio(testTwilioConfig, &mockHTTPClient{
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				require.NoError(t, req.ParseForm())
+				assert.Len(t, req.PostForm.Get("Body"), maxTwilioBody)
+				return newResponse(http.StatusCreated, `{}`), nil
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, numbers[0].Send(context.Background(), &Notification{Summary: strings.Repeat("a", 2000), Title: "Alert"}))
+	})
+
+	t.Run("rate cap", func(t *testing.T) {
+		conf := testTwilioConfig
+		conf.MaxPerHour = 2
+		var sent int
+		numbers, err := newTwilio(conf, &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				sent++
+				return newResponse(http.StatusCreated, `{}`), nil
+			},
+		})
+		require.NoError(t, err)
+		now := time.Unix(1700000000, 0)
+		numbers[0].cap.now = func() time.Time { return now }
+
+		require.NoError(t, numbers[0].Send(context.Background(), n))
+		require.NoError(t, numbers[0].Send(context.Background(), n))
+		require.ErrorIs(t, numbers[0].Send(context.Background(), n), ErrRateLimited)
+		require.NoError(t, numbers[1].Send(context.Background(), n), "each number has its own cap")
+		assert.Equal(t, 3, sent)
+
+		now = now.Add(time.Hour)
+		require.NoError(t, numbers[0].Send(context.Background(), n))
+		assert.Equal(t, 4, sent)
+	})
+
+	t.Run("api error", func(t *testing.T) {
+		numbers, err := newTwilio(testTwilioConfig, &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return newResponse(http.StatusBadRequest, `{"code":21211,"message":"The 'To' number is not a valid phone number.","status":400}`), nil
+			},
+		})
+		require.NoError(t, err)
+		err = numbers[0].Send(context.Background(), n)
+		require.ErrorIs(t, err, ErrSendFailed)
+		assert.Contains(t, err.Error(), "21211")
+	})
+
+	t.Run("http client error", func(t *testing.T) {
+		numbers, err := newTwilio(testTwilioConfig, &mockHTTPClient{
+			doFunc: func(_ *http.Request) (*http.Response, error) {
+				return nil, errors.New("HTTP client error")
+			},
+		})
+		require.NoError(t, err)
+		require.Error(t, numbers[0].Send(context.Background(), n))
+	})
+}
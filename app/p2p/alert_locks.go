@@ -0,0 +1,41 @@
+package p2p
+
+import (
+	"context"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+)
+
+// lockAlert will take the lock of the alert sequence before its node actions (if the alert locks are enabled)
+// It returns false if another instance sharing the datastore enforces the alert, or if the lock could not be taken
+// (the alert is synced again later). The release is called once the alert is saved, the caller checks under the lock
+// whether another instance already saved it
+func lockAlert(ctx context.Context, conf *config.Config, logger config.LoggerInterface,
+	sequenceNumber uint32) (release func(), locked bool) {
+
+	if !conf.AlertLocks.Enabled {
+		return func() {}, true
+	}
+	holder := conf.Cluster.InstanceID
+	locked, err := models.AcquireAlertLock(
+		ctx, sequenceNumber, holder, conf.AlertLocks.TTL, model.WithAllDependencies(conf),
+	)
+	if err != nil {
+		logger.Errorf("failed to lock alert %d: %s", sequenceNumber, err.Error())
+		return nil, false
+	} else if !locked {
+		logger.Infof("alert %d is enforced by another instance", sequenceNumber)
+		return nil, false
+	}
+	return func() {
+		// Released even if the context is done (the alert is saved)
+		if err = models.ReleaseAlertLock(
+			context.WithoutCancel(ctx), sequenceNumber, holder, model.WithAllDependencies(conf),
+		); err != nil {
+			logger.Warnf("failed to release the lock of alert %d (expires in %s): %s",
+				sequenceNumber, conf.AlertLocks.TTL, err.Error())
+		}
+	}, true
+}
@@ -0,0 +1,184 @@
+package p2p
+
+import (
+	"context"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// BanPeer will optionally ban the address on the Bitcoin node, record the ban in the datastore, then block the peer
+// in the connection gater and close any open connections (the peer is only blocked once the ban is recorded, so it
+// can always be lifted by UnbanPeer, and the node ban is lifted again if the record fails)
+func (s *Server) BanPeer(ctx context.Context, peerID peer.ID, nodeAddress, reason string,
+	duration time.Duration) (*models.PeerBan, error) {
+
+	// Don't ban ourselves
+	if peerID == s.host.ID() {
+		return nil, ErrCannotBanSelf
+	}
+
+	// Use the existing ban if found (re-banning updates the reason and duration)
+	ban, err := s.store.GetActivePeerBan(ctx, peerID.String())
+	if err != nil {
+		return nil, err
+	} else if ban == nil {
+		ban = models.NewPeerBan(model.WithAllDependencies(s.config), model.New())
+		ban.PeerID = peerID.String()
+	}
+	ban.Active = true
+	ban.Reason = reason
+	ban.SetDuration(duration)
+
+	// Ban the address on the node (if requested)
+	previousAddress := ban.NodeAddress
+	if len(nodeAddress) > 0 {
+		if err = s.config.Services.Node.BanPeer(ctx, nodeAddress); err != nil {
+			return nil, err
+		}
+		ban.NodeAddress = nodeAddress
+	}
+
+	// Save the ban (lifting the node ban of a new address, the peer would be banned on the node without a record)
+	if err = s.store.SavePeerBan(ctx, ban); err != nil {
+		if ban.NodeAddress != previousAddress {
+			if unbanErr := s.config.Services.Node.UnbanPeer(ctx, ban.NodeAddress); unbanErr != nil {
+				s.logger.Errorf("failed to roll back the node ban of %s for peer %s: %s",
+					ban.NodeAddress, peerID.String(), unbanErr.Error())
+			}
+		}
+		return nil, err
+	}
+
+	// Lift the node ban of the previous address (re-banned with another address)
+	if len(previousAddress) > 0 && previousAddress != ban.NodeAddress {
+		if err = s.config.Services.Node.UnbanPeer(ctx, previousAddress); err != nil {
+			s.logger.Errorf("failed to lift the node ban of the previous address %s of peer %s: %s",
+				previousAddress, peerID.String(), err.Error())
+		}
+	}
+
+	// Block the peer and drop any connections (the ban is recorded, the expiry cron blocks it if this fails)
+	if err = s.gater.BlockPeer(peerID); err != nil {
+		return nil, err
+	}
+	if err = s.host.Network().ClosePeer(peerID); err != nil {
+		s.logger.Debugf("failed to close connections to banned peer %s: %s", peerID.String(), err.Error())
+	}
+
+	s.logger.Infof("banned peer %s; reason [%s]", peerID.String(), reason)
+	s.events.Publish(ctx, &events.Event{Ban: ban, PeerID: peerID.String(), Type: events.PeerBanned})
+	return ban, nil
+}
+
+// UnbanPeer will lift an active ban on a peer (including the node ban if one was set)
+func (s *Server) UnbanPeer(ctx context.Context, peerID peer.ID) (*models.PeerBan, error) {
+
+	// Get the active ban
+	ban, err := s.store.GetActivePeerBan(ctx, peerID.String())
+	if err != nil {
+		return nil, err
+	} else if ban == nil {
+		return nil, ErrPeerNotBanned
+	}
+
+	if err = s.liftPeerBan(ctx, ban); err != nil {
+		return nil, err
+	}
+	return ban, nil
+}
+
+// BannedPeers will return the peers that are currently blocked in the connection gater
+func (s *Server) BannedPeers() []peer.ID {
+	return s.gater.ListBlockedPeers()
+}
+
+// liftPeerBan will unblock the peer, remove the node ban and mark the ban as inactive
+func (s *Server) liftPeerBan(ctx context.Context, ban *models.PeerBan) error {
+	peerID, err := peer.Decode(ban.PeerID)
+	if err != nil {
+		return err
+	}
+	if err = s.gater.UnblockPeer(peerID); err != nil {
+		return err
+	}
+	if len(ban.NodeAddress) > 0 {
+		if err = s.config.Services.Node.UnbanPeer(ctx, ban.NodeAddress); err != nil {
+			return err
+		}
+	}
+	ban.Active = false
+	if err = s.store.SavePeerBan(ctx, ban); err != nil {
+		s.restorePeerBan(ctx, peerID, ban)
+		return err
+	}
+
+	s.logger.Infof("unbanned peer %s", ban.PeerID)
+	s.events.Publish(ctx, &events.Event{Ban: ban, PeerID: ban.PeerID, Type: events.PeerUnbanned})
+	return nil
+}
+
+// restorePeerBan will block the peer and ban the node address again after the lifted ban failed to save (the ban
+// is still active in the datastore)
+func (s *Server) restorePeerBan(ctx context.Context, peerID peer.ID, ban *models.PeerBan) {
+	ban.Active = true
+	if len(ban.NodeAddress) > 0 {
+		if err := s.config.Services.Node.BanPeer(ctx, ban.NodeAddress); err != nil {
+			s.logger.Errorf("failed to roll back the node unban of %s for peer %s: %s",
+				ban.NodeAddress, ban.PeerID, err.Error())
+		}
+	}
+	if err := s.gater.BlockPeer(peerID); err != nil {
+		s.logger.Errorf("failed to block peer %s again: %s", ban.PeerID, err.Error())
+	}
+}
+
+// loadPeerBans will load all active bans into the connection gater (lifting any expired bans)
+func (s *Server) loadPeerBans(ctx context.Context) error {
+	bans, err := s.store.ListActivePeerBans(ctx)
+	if err != nil {
+		return err
+	}
+	for _, ban := range bans {
+		ban.SetOptions(model.WithAllDependencies(s.config))
+		if ban.IsExpired() {
+			if err = s.liftPeerBan(ctx, ban); err != nil {
+				s.logger.Errorf("failed to lift expired ban for peer %s: %s", ban.PeerID, err.Error())
+			}
+			continue
+		}
+		var peerID peer.ID
+		if peerID, err = peer.Decode(ban.PeerID); err != nil {
+			s.logger.Errorf("invalid peer ID in ban %d: %s", ban.ID, err.Error())
+			continue
+		}
+		if err = s.gater.BlockPeer(peerID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunPeerBanExpiryCron starts a cron job to lift any expired peer bans
+func (s *Server) RunPeerBanExpiryCron(ctx context.Context) chan bool {
+	ticker := s.config.Clock().NewTicker(config.DefaultPeerBanExpiryInterval)
+	quit := make(chan bool, 1)
+	s.supervisor.Go(ctx, "peer_ban_expiry", func(ctx context.Context) {
+		for {
+			select {
+			case <-ticker.C():
+				if err := s.loadPeerBans(ctx); err != nil {
+					s.logger.Errorf("error checking peer bans: %v", err.Error())
+				}
+			case <-quit:
+				ticker.Stop()
+				return
+			}
+		}
+	})
+	return quit
+}
@@ -0,0 +1,161 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/config/mocks"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/store"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/p2p/net/conngater"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBanServer will return a server banning the peers on the node mock (with an in-memory store) and a
+// connected peer
+func newTestBanServer(t *testing.T, node *mocks.Node) (*Server, peer.ID) {
+	mn, err := mocknet.FullMeshConnected(2)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = mn.Close()
+	})
+	var gater *conngater.BasicConnectionGater
+	gater, err = conngater.NewBasicConnectionGater(nil)
+	require.NoError(t, err)
+
+	conf := &config.Config{}
+	conf.Services.Log = &config.ExtendedLogger{Logger: log.New(io.Discard, "", 0)}
+	conf.Services.Node = node
+	return &Server{
+		config: conf,
+		events: events.NewBus(),
+		gater:  gater,
+		host:   mn.Hosts()[0],
+		logger: conf.Services.Log,
+		store:  store.NewMemory(),
+	}, mn.Hosts()[1].ID()
+}
+
+// failingBanStore is an in-memory store failing to save the peer bans once fail is set
+type failingBanStore struct {
+	store.AlertStore
+	fail bool
+}
+
+// SavePeerBan will return an error once fail is set
+func (f *failingBanStore) SavePeerBan(ctx context.Context, ban *models.PeerBan) error {
+	if f.fail {
+		return errors.New("datastore unavailable")
+	}
+	return f.AlertStore.SavePeerBan(ctx, ban)
+}
+
+// TestServer_BanPeer will test banning and unbanning a peer
+func TestServer_BanPeer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("node ban fails, the peer is not blocked", func(t *testing.T) {
+		node := &mocks.Node{BanPeerFunc: func(context.Context, string) error {
+			return errors.New("node unreachable")
+		}}
+		s, peerID := newTestBanServer(t, node)
+
+		_, err := s.BanPeer(ctx, peerID, "10.0.0.1", "test", 0)
+		require.Error(t, err)
+		assert.Empty(t, s.BannedPeers())
+		ban, err := s.store.GetActivePeerBan(ctx, peerID.String())
+		require.NoError(t, err)
+		assert.Nil(t, ban)
+	})
+
+	t.Run("banned, then unbanned", func(t *testing.T) {
+		s, peerID := newTestBanServer(t, &mocks.Node{})
+
+		ban, err := s.BanPeer(ctx, peerID, "", "test", 0)
+		require.NoError(t, err)
+		assert.True(t, ban.Active)
+		assert.Contains(t, s.BannedPeers(), peerID)
+
+		_, err = s.UnbanPeer(ctx, peerID)
+		require.NoError(t, err)
+		assert.Empty(t, s.BannedPeers())
+	})
+
+	t.Run("re-banned with another node address", func(t *testing.T) {
+		var unbanned []string
+		node := &mocks.Node{UnbanPeerFunc: func(_ context.Context, address string) error {
+			unbanned = append(unbanned, address)
+			return nil
+		}}
+		s, peerID := newTestBanServer(t, node)
+
+		_, err := s.BanPeer(ctx, peerID, "10.0.0.1", "test", 0)
+		require.NoError(t, err)
+		ban, err := s.BanPeer(ctx, peerID, "10.0.0.2", "test", 0)
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.0.2", ban.NodeAddress)
+		assert.Equal(t, []string{"10.0.0.1"}, unbanned)
+
+		// Same address, nothing lifted
+		_, err = s.BanPeer(ctx, peerID, "10.0.0.2", "test", 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.1"}, unbanned)
+	})
+
+	t.Run("saving the ban fails, the node ban is rolled back", func(t *testing.T) {
+		var unbanned []string
+		node := &mocks.Node{UnbanPeerFunc: func(_ context.Context, address string) error {
+			unbanned = append(unbanned, address)
+			return nil
+		}}
+		s, peerID := newTestBanServer(t, node)
+		failing := &failingBanStore{AlertStore: s.store}
+		s.store = failing
+
+		_, err := s.BanPeer(ctx, peerID, "10.0.0.1", "test", 0)
+		require.NoError(t, err)
+
+		// Re-banned with the same address, the recorded node ban is kept
+		failing.fail = true
+		_, err = s.BanPeer(ctx, peerID, "10.0.0.1", "test", 0)
+		require.Error(t, err)
+		assert.Empty(t, unbanned)
+
+		// Re-banned with another address, only the new node ban is lifted
+		_, err = s.BanPeer(ctx, peerID, "10.0.0.2", "test", 0)
+		require.Error(t, err)
+		assert.Equal(t, []string{"10.0.0.2"}, unbanned)
+	})
+
+	t.Run("saving the lifted ban fails, the peer stays banned", func(t *testing.T) {
+		var banned []string
+		node := &mocks.Node{BanPeerFunc: func(_ context.Context, address string) error {
+			banned = append(banned, address)
+			return nil
+		}}
+		s, peerID := newTestBanServer(t, node)
+		failing := &failingBanStore{AlertStore: s.store}
+		s.store = failing
+
+		_, err := s.BanPeer(ctx, peerID, "10.0.0.1", "test", 0)
+		require.NoError(t, err)
+
+		failing.fail = true
+		_, err = s.UnbanPeer(ctx, peerID)
+		require.Error(t, err)
+		assert.Contains(t, s.BannedPeers(), peerID)
+		assert.Equal(t, []string{"10.0.0.1", "10.0.0.1"}, banned)
+		ban, err := s.store.GetActivePeerBan(ctx, peerID.String())
+		require.NoError(t, err)
+		require.NotNil(t, ban)
+		assert.True(t, ban.Active)
+	})
+}
@@ -0,0 +1,51 @@
+package p2p
+
+import (
+	"context"
+
+	"github.com/bitcoin-sv/alert-system/app/cluster"
+)
+
+// IsLeader will return true if this instance enforces the alerts (always true if clustering is disabled)
+// A standby keeps its peers and serves the API, the leader processes, syncs and publishes the alerts
+func (s *Server) IsLeader() bool {
+	return s.cluster.IsLeader()
+}
+
+// ClusterState will return the role of this instance in the cluster (nil if clustering is disabled)
+func (s *Server) ClusterState() *cluster.State {
+	if s.cluster == nil {
+		return nil
+	}
+	return s.cluster.State()
+}
+
+// leadershipChanged will take over the alerts when this instance becomes the cluster leader
+func (s *Server) leadershipChanged(ctx context.Context, state *cluster.State) {
+	if state.Role != cluster.RoleLeader {
+		return
+	}
+	s.supervisor.Go(ctx, "cluster_takeover", s.takeOver)
+}
+
+// takeOver will retry the alerts the previous leader did not process, then sync with the connected peers
+// (the alerts gossiped while no instance was leading are only received from the peers)
+func (s *Server) takeOver(ctx context.Context) {
+	if err := s.processAlerts(ctx); err != nil {
+		s.logger.Errorf("error processing alerts after the takeover: %s", err.Error())
+	}
+	if !s.inflight.begin() {
+		return
+	}
+	defer s.inflight.end()
+	for _, peerID := range s.host.Network().Peers() {
+		if ctx.Err() != nil || !s.IsLeader() {
+			return
+		}
+		if latestSequence, err := s.syncPeer(ctx, peerID, nil); err != nil {
+			s.logger.Debugf("failed to sync with %s after the takeover: %s", peerID.String(), err.Error())
+		} else {
+			s.logger.Infof("synced up to %d from peer %s after the takeover", latestSequence, peerID.String())
+		}
+	}
+}
@@ -2,10 +2,13 @@ package p2p
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/peer"
 
 	"github.com/multiformats/go-multiaddr"
@@ -19,7 +22,7 @@ import (
 // DHT, so that the bootstrapping node of the DHT can go down without
 // inhibiting future peer discovery.
 func (s *Server) initDHT(ctx context.Context) (*dht.IpfsDHT, error) {
-	logger := s.config.Services.Log
+	logger := s.logger
 	var options []dht.Option
 	options = append(options, dht.Mode(dht.ModeAutoServer))
 
@@ -33,14 +36,9 @@ func (s *Server) initDHT(ctx context.Context) (*dht.IpfsDHT, error) {
 	}
 
 	// Append the bootstrap nodes
-	peers := dht.DefaultBootstrapPeers
-	if s.config.P2P.BootstrapPeer != "" {
-		// Connect to the chosen ipfs nodes
-		var pubPeer multiaddr.Multiaddr
-		if pubPeer, err = multiaddr.NewMultiaddr(s.config.P2P.BootstrapPeer); err != nil {
-			return nil, err
-		}
-		peers = append(peers, pubPeer)
+	var peers []multiaddr.Multiaddr
+	if peers, err = BootstrapPeers(s.config); err != nil {
+		return nil, err
 	}
 
 	// Connect to the chosen ipfs nodes
@@ -74,3 +72,60 @@ func (s *Server) initDHT(ctx context.Context) (*dht.IpfsDHT, error) {
 
 	return kademliaDHT, nil
 }
+
+// BootstrapPeers will return the bootstrap peers (the public DHT peers and the configured bootstrap peer)
+func BootstrapPeers(conf *config.Config) ([]multiaddr.Multiaddr, error) {
+	peers := append([]multiaddr.Multiaddr{}, dht.DefaultBootstrapPeers...)
+	if conf.P2P.BootstrapPeer != "" {
+		pubPeer, err := multiaddr.NewMultiaddr(conf.P2P.BootstrapPeer)
+		if err != nil {
+			return nil, err
+		}
+		peers = append(peers, pubPeer)
+	}
+	return peers, nil
+}
+
+// DialBootstrapPeers will connect to the bootstrap peers once and return how many were reached
+// A throwaway identity is used and nothing is listened on, so it can run next to a running alert system
+func DialBootstrapPeers(ctx context.Context, conf *config.Config) (connected, total int, err error) {
+	var peers []multiaddr.Multiaddr
+	if peers, err = BootstrapPeers(conf); err != nil {
+		return 0, 0, err
+	}
+	var h host.Host
+	if h, err = libp2p.New(libp2p.NoListenAddrs); err != nil {
+		return 0, len(peers), err
+	}
+	defer func() {
+		_ = h.Close()
+	}()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var lastErr error
+	for _, peerAddr := range peers {
+		var peerInfo *peer.AddrInfo
+		if peerInfo, err = peer.AddrInfoFromP2pAddr(peerAddr); err != nil {
+			return 0, len(peers), err
+		}
+		wg.Add(1)
+		go func(peerInfo *peer.AddrInfo) {
+			defer wg.Done()
+			connectErr := h.Connect(ctx, *peerInfo)
+			mu.Lock()
+			defer mu.Unlock()
+			if connectErr != nil {
+				lastErr = connectErr
+				return
+			}
+			connected++
+		}(peerInfo)
+	}
+	wg.Wait()
+
+	if connected == 0 {
+		return 0, len(peers), fmt.Errorf("%w: %v", ErrBootstrapUnreachable, lastErr)
+	}
+	return connected, len(peers), nil
+}
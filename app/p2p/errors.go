@@ -4,9 +4,23 @@ import "errors"
 
 // Errors for the p2p package
 var (
+	ErrAlertLocked             = errors.New("alert is being enforced by another instance")
+	ErrAlertNotSaved           = errors.New("alert was not saved, see the logs for the reason")
 	ErrAlertNotFoundBySequence = errors.New("failed to find alert by sequence in datastore")
 	ErrAlertNotLatest          = errors.New("failed to find latest alert datastore")
+	ErrAlertsInFlight          = errors.New("alerts still being processed")
+	ErrBootstrapUnreachable    = errors.New("none of the bootstrap peers could be reached")
+	ErrCannotBanSelf           = errors.New("cannot ban our own peer ID")
+	ErrIntakeStopped           = errors.New("alert intake is stopped, the alert system is shutting down")
 	ErrInvalidAlerts           = errors.New("peer is sending invalid alerts")
+	ErrInvalidPrivateKey       = errors.New("invalid private key")
+	ErrNoConnectedPeers        = errors.New("no connected peers to sync with")
+	ErrNoPrivateKey            = errors.New("private key not found, create it with the keygen command")
+	ErrNotSynced               = errors.New("not synced with the peers")
+	ErrPeerNotBanned           = errors.New("peer is not banned")
+	ErrPeerNotConnected        = errors.New("peer is not connected")
+	ErrStandby                 = errors.New("this instance is a cluster standby, the leader syncs and processes the alerts")
 	ErrSyncFiveBytes           = errors.New("sync message is less than 5 bytes, not valid")
+	ErrSyncJobRunning          = errors.New("a sync job is already running")
 	ErrSyncMessageByte         = errors.New("sync message needs at least a byte")
 )
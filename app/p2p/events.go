@@ -0,0 +1,128 @@
+package p2p
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/bitcoin-sv/alert-system/app/audit"
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/bitcoin-sv/alert-system/app/metrics"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/app/webhook"
+)
+
+// subscribe will subscribe the metrics, audit log, webhooks and notifications to the event bus
+func (s *Server) subscribe() {
+	s.events.Subscribe(func(ctx context.Context, e *events.Event) {
+		metrics.Inc(ctx, metrics.Events.WithLabelValues(string(e.Type)))
+	})
+	s.events.Subscribe(s.auditAlert, events.AlertEnforced)
+	s.events.Subscribe(s.deliverWebhooks, events.AlertReceived, events.AlertVerified, events.AlertEnforced)
+	s.notifier.Subscribe(s.events)
+}
+
+// Events will return the event bus (embedders can subscribe to the alert, peer and node events)
+func (s *Server) Events() *events.Bus {
+	return s.events
+}
+
+// queueEnforced will add the enforced event to the outbox of the alert (saved in the same transaction as the alert)
+func queueEnforced(conf *config.Config, alert *models.AlertMessage, source, peerID string, actionErr error) {
+	alert.AddOutboxEvent(models.NewAlertOutboxEvent(
+		alert, string(events.AlertEnforced), source, peerID, nodeHost(conf), actionErr,
+		model.WithAllDependencies(conf), model.New(),
+	))
+}
+
+// publishEnforced will publish the result of enforcing the alert (and a node event if the node failed)
+// The outbox events saved with the alert are marked delivered once published
+func publishEnforced(ctx context.Context, bus *events.Bus, conf *config.Config, alert *models.AlertMessage,
+	source, peerID string, actionErr error) {
+	node := nodeHost(conf)
+	e := &events.Event{
+		Alert: alert, Err: actionErr, Node: node, PeerID: peerID, Source: source, Type: events.AlertEnforced,
+	}
+	saved := make([]*models.OutboxEvent, 0)
+	for _, o := range alert.OutboxEvents() {
+		if !o.IsNew() && o.Status == models.OutboxStatusPending {
+			e.ID = o.DedupKey
+			saved = append(saved, o)
+		}
+	}
+	bus.Publish(ctx, e)
+	if actionErr != nil {
+		bus.Publish(ctx, &events.Event{
+			Alert: alert, Err: actionErr, Node: node, Source: source, Type: events.NodeUnhealthy,
+		})
+	}
+	for _, o := range saved {
+		if err := o.MarkDelivered(ctx); err != nil {
+			conf.Services.Log.Errorf("failed to mark outbox event %s delivered: %s", o.DedupKey, err.Error())
+		}
+	}
+}
+
+// nodeHost will return the RPC host of the node (empty if there is no node)
+func nodeHost(conf *config.Config) string {
+	if conf.Services.Node == nil {
+		return ""
+	}
+	return conf.Services.Node.GetRPCHost()
+}
+
+// auditAlert will record the alert action executed against the node in the audit log
+func (s *Server) auditAlert(ctx context.Context, e *events.Event) {
+	details := map[string]string{
+		"alert_type": strconv.FormatUint(uint64(e.Alert.GetAlertType()), 10),
+		"hash":       e.Alert.Hash,
+		"source":     e.Source,
+		"success":    strconv.FormatBool(e.Err == nil),
+	}
+	if e.Err != nil {
+		details["error"] = e.Err.Error()
+	}
+	if err := s.config.Services.Audit.Record(
+		ctx, audit.EventAlertEnforced, audit.ActorNetwork, strconv.FormatUint(uint64(e.Alert.SequenceNumber), 10), details,
+	); err != nil {
+		s.logger.Errorf("failed to record alert %d in the audit log: %s", e.Alert.SequenceNumber, err.Error())
+	}
+}
+
+// deliverWebhooks will post the alert to the alert webhook URL and queue it for the registered webhooks
+// Alerts received over gossip are delivered at each stage (received, verified, processed or failed),
+// retried alerts only once processed and synced alerts are not delivered
+func (s *Server) deliverWebhooks(ctx context.Context, e *events.Event) {
+	if e.Source == events.SourceSync || (e.Source == events.SourceRetry && e.Err != nil) {
+		return
+	}
+
+	// Map the bus event to the webhook event
+	var event string
+	switch e.Type {
+	case events.AlertReceived:
+		event = webhook.EventAlertReceived
+	case events.AlertVerified:
+		event = webhook.EventAlertVerified
+	case events.AlertEnforced:
+		event = webhook.EventAlertProcessed
+		if e.Err != nil {
+			event = webhook.EventAlertFailed
+		}
+
+		// Send the webhook (only the enforced alerts)
+		if e.Source == events.SourceGossip && len(s.config.AlertWebhookURL) > 0 {
+			if err := webhook.PostAlert(ctx, s.config.Services.HTTPClient, s.config.AlertWebhookURL, e.Alert); err != nil {
+				s.logger.Errorf("error processing webhook request: %s", err.Error())
+			}
+		}
+	default:
+		return
+	}
+
+	// Deliver to the registered webhooks
+	if err := s.webhooks.Dispatch(ctx, event, e.Alert, e.ID); err != nil {
+		s.logger.Errorf("failed to dispatch %s webhooks for alert %d: %s", event, e.Alert.SequenceNumber, err.Error())
+	}
+}
@@ -0,0 +1,106 @@
+package p2p
+
+import (
+	"context"
+	"sync"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// fastSyncTracker tracks the fast sync of the startup: the latest alerts are synced (and enforced) first so the node
+// is protected within seconds, the older alerts are backfilled in the background. They were enforced before the older
+// alerts, so the consensus-critical ones are enforced again once no alert is missing (the state of an in-order sync).
+// Each alert is verified against the keys like in the in-order sync, no other checksum of the alerts is exchanged
+// (the sync protocol is unchanged, the peers of the older versions serve the fast sync). A set_keys alert, or an
+// alert not signed by the active keys (rotated by a skipped alert), falls back to the in-order sync of the missing
+// alerts, so the keys are never rotated out of order.
+type fastSyncTracker struct {
+	from  uint32 // First alert of the fast sync to enforce again (0 if none)
+	mu    sync.Mutex
+	to    uint32 // Last alert of the fast sync to enforce again
+	tried bool   // Only the first sync after startup is fast
+}
+
+// alerts will return the number of the latest alerts to sync first (0 syncs them all in order)
+func (f *fastSyncTracker) alerts(conf *config.Config) uint32 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.tried || conf.P2P.FastSyncAlerts <= 0 {
+		return 0
+	}
+	f.tried = true
+	return uint32(conf.P2P.FastSyncAlerts)
+}
+
+// synced will record the alerts synced by the fast sync (enforced again once the older ones are backfilled)
+func (f *fastSyncTracker) synced(from, to uint32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.from, f.to = from, to
+}
+
+// take will return the alerts of the fast sync to enforce again and forget them (0 if none)
+func (f *fastSyncTracker) take() (from, to uint32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	from, to = f.from, f.to
+	f.from, f.to = 0, 0
+	return
+}
+
+// backfill will sync the alerts older than the fast sync from the peer (the next syncs resume it if it fails)
+func (s *Server) backfill(ctx context.Context, peerID peer.ID) {
+	if _, err := s.syncPeer(ctx, peerID, nil); err != nil {
+		s.logger.Errorf("failed to backfill the alerts from peer %s, resumed by the next sync: %s", peerID.String(), err.Error())
+	}
+}
+
+// finishFastSync will enforce again the consensus-critical alerts of the fast sync once no older alert is missing
+func (s *Server) finishFastSync(ctx context.Context) {
+	from, to := s.fastSync.take()
+	if from == 0 {
+		return
+	}
+	missing, err := s.store.ListMissing(ctx, 1)
+	if err != nil || len(missing) > 0 {
+		s.fastSync.synced(from, to) // Not backfilled yet
+		return
+	}
+	s.logger.Infof("alerts backfilled, enforcing again the consensus-critical alerts %d to %d", from, to)
+	for sequence := from; sequence <= to; sequence++ {
+		if err = s.reenforceAlert(ctx, sequence); err != nil {
+			s.logger.Errorf("failed to enforce again alert %d: %s", sequence, err.Error())
+		}
+	}
+}
+
+// reenforceAlert will execute again the action of the saved alert if it is consensus-critical
+func (s *Server) reenforceAlert(ctx context.Context, sequence uint32) error {
+	alert, err := s.store.GetBySequence(ctx, sequence)
+	if err != nil || alert == nil || !alert.Processed {
+		return err // Not processed alerts are retried by the alert processing cron
+	}
+	if err = alert.ReadRaw(); err != nil {
+		return err
+	}
+	alert.SerializeData()
+	ak := alert.ProcessAlertMessage()
+	if ak == nil || !alert.GetAlertType().IsConsensusCritical() {
+		return nil
+	}
+	if err = ak.Read(alert.GetRawMessage()); err != nil {
+		return err
+	}
+	alertCtx, logger := config.ContextWithField(ctx, s.logger, config.LogFieldAlertSequence, sequence)
+
+	// Lock the alert (another instance sharing the datastore may be processing it)
+	release, locked := lockAlert(alertCtx, s.config, logger, sequence)
+	if !locked {
+		return nil
+	}
+	defer release()
+	actionErr := ak.Do(alertCtx)
+	s.recordNodeAction(ctx, alert, actionErr)
+	return actionErr
+}
@@ -0,0 +1,104 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/health"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+)
+
+// Health check names
+const (
+	HealthCheckDatastore = "datastore" // Latest alert can be read (critical)
+	HealthCheckNode      = "node"      // Node responds to RPC calls (critical)
+	HealthCheckPeers     = "peers"     // Connected to at least one peer
+	HealthCheckSync      = "sync"      // Not behind the peers and no missing alerts
+)
+
+// Health will check the subsystems and return the aggregated health
+func (s *Server) Health(ctx context.Context) *health.Report {
+	return s.health.Check(ctx)
+}
+
+// healthCheckers will return the checkers for the subsystems
+func (s *Server) healthCheckers() []health.Checker {
+	return []health.Checker{
+		{Check: s.checkDatastore, Critical: true, Name: HealthCheckDatastore},
+		{Check: s.checkNode, Critical: true, Name: HealthCheckNode},
+		{Check: s.checkPeers, Name: HealthCheckPeers},
+		{Check: s.checkSync, Name: HealthCheckSync},
+	}
+}
+
+// checkDatastore will check the latest alert can be read
+func (s *Server) checkDatastore(ctx context.Context) (string, error) {
+	alert, err := s.store.GetLatest(ctx)
+	if err != nil {
+		return "", err
+	} else if alert == nil {
+		return "", ErrAlertNotLatest
+	}
+	return "latest sequence " + strconv.FormatUint(uint64(alert.SequenceNumber), 10), nil
+}
+
+// checkNode will check the node responds to an RPC call (each node of a pool, at least one must respond)
+func (s *Server) checkNode(ctx context.Context) (string, error) {
+	if pool, ok := s.config.Services.Node.(*config.NodePool); ok {
+		return pool.Check(ctx)
+	}
+	if _, err := s.config.Services.Node.BestBlockHash(ctx); err != nil {
+		return "", err
+	}
+	return s.config.Services.Node.GetRPCHost(), nil
+}
+
+// RunNodeHealthCron starts the health check of the nodes of the RPC connections at each retry interval, an
+// unreachable node catches up on the alert actions it missed once it responds, each replayed action is recorded for
+// the node (nil if there is a single node)
+func (s *Server) RunNodeHealthCron(ctx context.Context) chan bool {
+	pool, ok := s.config.Services.Node.(*config.NodePool)
+	if !ok {
+		return nil
+	}
+	models.RecordReplayedNodeActions(pool, model.WithAllDependencies(s.config))
+	quit := make(chan bool, 1)
+	s.supervisor.Go(ctx, "node_health", func(ctx context.Context) {
+		pool.Run(ctx, quit)
+	})
+	return quit
+}
+
+// checkPeers will check we are connected to at least one peer
+func (s *Server) checkPeers(_ context.Context) (string, error) {
+	count := len(s.host.Network().Peers())
+	if count == 0 {
+		return "", ErrNoConnectedPeers
+	}
+	return strconv.Itoa(count) + " peers", nil
+}
+
+// checkSync will check we are not behind the best peer and are not missing any alerts
+func (s *Server) checkSync(ctx context.Context) (string, error) {
+	latest, err := s.store.GetLatest(ctx)
+	if err != nil {
+		return "", err
+	}
+	var sequence uint32
+	if latest != nil {
+		sequence = latest.SequenceNumber
+	}
+	if best := s.SyncState().BestSequence; best > sequence {
+		return "", fmt.Errorf("%w: %d alerts behind peer sequence %d", ErrNotSynced, best-sequence, best)
+	}
+	var gaps []uint32
+	if gaps, err = s.store.ListMissing(ctx, 1); err != nil {
+		return "", err
+	} else if len(gaps) > 0 {
+		return "", fmt.Errorf("%w: missing sequence %d", ErrNotSynced, gaps[0])
+	}
+	return "synced to sequence " + strconv.FormatUint(uint64(sequence), 10), nil
+}
@@ -0,0 +1,92 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/bitcoin-sv/alert-system/app/health"
+	"github.com/bitcoin-sv/alert-system/app/heartbeat"
+	"github.com/bitcoin-sv/alert-system/app/metrics"
+)
+
+// RunHeartbeatCron starts a cron job to log, export and post (if a URL is set) the heartbeat
+func (s *Server) RunHeartbeatCron(ctx context.Context) chan bool {
+	ticker := time.NewTicker(s.config.Heartbeat.Interval)
+	quit := make(chan bool, 1)
+	s.supervisor.Go(ctx, "heartbeat", func(ctx context.Context) {
+		for {
+			select {
+			case <-ticker.C:
+				s.beat(ctx)
+			case <-quit:
+				ticker.Stop()
+				return
+			}
+		}
+	})
+	return quit
+}
+
+// Heartbeat will return the current heartbeat (uptime, latest sequence, peers and node health)
+// The node health is the node check of the aggregated health (which also refreshes the health metrics)
+func (s *Server) Heartbeat(ctx context.Context) *heartbeat.Heartbeat {
+	return s.heartbeat(ctx, s.Health(ctx))
+}
+
+// heartbeat will return the current heartbeat with the node health of the report
+func (s *Server) heartbeat(ctx context.Context, report *health.Report) *heartbeat.Heartbeat {
+	h := &heartbeat.Heartbeat{
+		PeerCount:     len(s.host.Network().Peers()),
+		PeerID:        s.host.ID().String(),
+		Time:          time.Now().UTC(),
+		UptimeSeconds: int64(time.Since(s.startedAt).Seconds()),
+	}
+
+	// Get the latest alert
+	if alert, err := s.store.GetLatest(ctx); err != nil {
+		s.logger.Errorf("heartbeat failed to get the latest alert: %s", err.Error())
+	} else if alert != nil {
+		h.LatestSequence = alert.SequenceNumber
+	}
+
+	// Check the node responds
+	if check := report.Check(HealthCheckNode); check != nil && check.Status != health.StatusOK {
+		h.NodeError = check.Error
+	} else {
+		h.NodeHealthy = true
+	}
+	return h
+}
+
+// beat will take the heartbeat, log it, export it as metrics and post it to the heartbeat URL (if set)
+func (s *Server) beat(ctx context.Context) {
+	h := s.Heartbeat(ctx)
+	metrics.LatestSequence.Set(float64(h.LatestSequence))
+	metrics.Peers.Set(float64(h.PeerCount))
+	metrics.Uptime.Set(float64(h.UptimeSeconds))
+	if h.NodeHealthy {
+		metrics.NodeUp.Set(1)
+		s.logger.Info(h.String())
+		if s.nodeUnhealthy {
+			s.events.Publish(ctx, &events.Event{Node: s.config.Services.Node.GetRPCHost(), Type: events.NodeHealthy})
+		}
+	} else {
+		metrics.NodeUp.Set(0)
+		s.logger.Warn(h.String())
+		s.events.Publish(ctx, &events.Event{
+			Err: fmt.Errorf("%w: %s", heartbeat.ErrNodeUnhealthy, h.NodeError), Node: s.config.Services.Node.GetRPCHost(), Type: events.NodeUnhealthy,
+		})
+	}
+	s.nodeUnhealthy = !h.NodeHealthy
+
+	// Ping the dead man's switch
+	if len(s.config.Heartbeat.URL) > 0 {
+		err := heartbeat.Post(ctx, s.config.Services.HTTPClient, s.config.Heartbeat.URL, h)
+		metrics.HeartbeatPosts.WithLabelValues(metrics.Result(err)).Inc()
+		if err != nil {
+			s.logger.Errorf("failed to post heartbeat: %s", err.Error())
+		}
+	}
+}
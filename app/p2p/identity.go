@@ -0,0 +1,199 @@
+package p2p
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// keyFileMode is the mode of the private key files (readable by the owner only)
+const keyFileMode = 0o600
+
+// keyBackupLayout is the time layout of the private key backups
+const keyBackupLayout = "20060102T150405Z"
+
+// GeneratePrivateKey generates a private key and stores it in `private_key` file
+func GeneratePrivateKey(filePath string) (*crypto.PrivKey, error) {
+	// Generate a new key pair
+	privateKey, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save private key to a file
+	if err = writePrivateKey(filePath, privateKey); err != nil {
+		return nil, err
+	}
+
+	return &privateKey, nil
+}
+
+// ReadPrivateKey reads a private key from `private_key` file
+func ReadPrivateKey(filePath string) (*crypto.PrivKey, error) {
+	// Read private key from a file
+	privateBytes, err := os.ReadFile(filePath) //nolint:gosec // This is a local private key
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal the private key bytes into a key
+	var privateKey crypto.PrivKey
+	if privateKey, err = crypto.UnmarshalPrivateKey(privateBytes); err != nil {
+		return nil, err
+	}
+
+	return &privateKey, nil
+}
+
+// LoadPrivateKey will read the private key, or generate it if the file does not exist and generate is true
+// (generated is true if the key was generated)
+func LoadPrivateKey(filePath string, generate bool) (privateKey crypto.PrivKey, generated bool, err error) {
+	var pk *crypto.PrivKey
+	if pk, err = ReadPrivateKey(filePath); err == nil {
+		return *pk, false, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, false, err
+	} else if !generate {
+		return nil, false, fmt.Errorf("%w: %s", ErrNoPrivateKey, filePath)
+	}
+	if pk, err = GeneratePrivateKey(filePath); err != nil {
+		return nil, false, err
+	}
+	return *pk, true, nil
+}
+
+// RotatePrivateKey will back up the private key (if any) and replace it with a new key
+// The backup path is empty if there was no key
+func RotatePrivateKey(filePath string) (privateKey crypto.PrivKey, backup string, err error) {
+	if privateKey, _, err = crypto.GenerateEd25519Key(rand.Reader); err != nil {
+		return nil, "", err
+	}
+	if backup, err = replacePrivateKey(filePath, privateKey); err != nil {
+		return nil, "", err
+	}
+	return privateKey, backup, nil
+}
+
+// ImportPrivateKey will back up the private key (if any) and replace it with the imported key
+// The key is the key file (protobuf) or its base64 encoding (see EncodePrivateKey)
+func ImportPrivateKey(filePath string, key []byte) (privateKey crypto.PrivKey, backup string, err error) {
+	if privateKey, err = ParsePrivateKey(key); err != nil {
+		return nil, "", err
+	}
+	if backup, err = replacePrivateKey(filePath, privateKey); err != nil {
+		return nil, "", err
+	}
+	return privateKey, backup, nil
+}
+
+// ParsePrivateKey will parse the key file (protobuf) or its base64 encoding
+func ParsePrivateKey(key []byte) (crypto.PrivKey, error) {
+	if privateKey, err := crypto.UnmarshalPrivateKey(key); err == nil {
+		return privateKey, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(key)))
+	if err != nil {
+		return nil, ErrInvalidPrivateKey
+	}
+	var privateKey crypto.PrivKey
+	if privateKey, err = crypto.UnmarshalPrivateKey(decoded); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPrivateKey, err.Error())
+	}
+	return privateKey, nil
+}
+
+// EncodePrivateKey will encode the private key in base64 (to export it, e.g. to a secrets manager)
+func EncodePrivateKey(privateKey crypto.PrivKey) (string, error) {
+	privateBytes, err := crypto.MarshalPrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(privateBytes), nil
+}
+
+// PeerAddresses will return the multiaddrs the peer is reachable on (listen and broadcast addresses)
+func PeerAddresses(conf *config.Config, peerID peer.ID) []string {
+	addresses := []string{fmt.Sprintf("/ip4/%s/tcp/%s/p2p/%s", conf.P2P.IP, conf.P2P.Port, peerID.String())}
+	if len(conf.P2P.BroadcastIP) > 0 && conf.P2P.BroadcastIP != conf.P2P.IP {
+		addresses = append(addresses, fmt.Sprintf("/ip4/%s/tcp/%s/p2p/%s", conf.P2P.BroadcastIP, conf.P2P.Port, peerID.String()))
+	}
+	return addresses
+}
+
+// replacePrivateKey will back up the private key file (if any) and write the new key
+func replacePrivateKey(filePath string, privateKey crypto.PrivKey) (backup string, err error) {
+	var existing []byte
+	if existing, err = os.ReadFile(filePath); err == nil { //nolint:gosec // This is a local private key
+		if backup, err = backupPrivateKey(filePath, existing); err != nil {
+			return "", err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	return backup, writePrivateKey(filePath, privateKey)
+}
+
+// backupPrivateKey will write the key to a new backup file (<path>.<time>.bak, or <path>.<time>.<n>.bak)
+func backupPrivateKey(filePath string, key []byte) (string, error) {
+	stamp := time.Now().UTC().Format(keyBackupLayout)
+	backup := fmt.Sprintf("%s.%s.bak", filePath, stamp)
+	for n := 1; ; n++ {
+		err := writeNewFile(backup, key)
+		if err == nil {
+			return backup, nil
+		} else if !errors.Is(err, fs.ErrExist) {
+			return "", err
+		}
+		backup = fmt.Sprintf("%s.%s.%d.bak", filePath, stamp, n)
+	}
+}
+
+// writePrivateKey will write the private key file (replaced atomically, the key is never half written)
+func writePrivateKey(filePath string, privateKey crypto.PrivKey) error {
+	privateBytes, err := crypto.MarshalPrivateKey(privateKey)
+	if err != nil {
+		return err
+	}
+	var tmp *os.File
+	if tmp, err = os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*.tmp"); err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err = tmp.Write(privateBytes); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Chmod(keyFileMode); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
+
+// writeNewFile will write the file (fails if it exists)
+func writeNewFile(filePath string, data []byte) error {
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, keyFileMode) //nolint:gosec // This is a local private key
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
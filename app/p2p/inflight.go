@@ -0,0 +1,68 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// inflightTracker counts the alerts being processed (gossip, sync and retries), so the shutdown can stop taking
+// new alerts and wait for the current ones to be enforced and saved, never leaving an alert half processed
+type inflightTracker struct {
+	mu      sync.Mutex
+	count   int
+	closed  bool
+	drained chan struct{} // Closed once closed and no alert is being processed
+}
+
+// newInflightTracker will return a tracker taking alerts
+func newInflightTracker() *inflightTracker {
+	return &inflightTracker{drained: make(chan struct{})}
+}
+
+// begin will count an alert being processed (false if the tracker is closed, the alert must not be processed)
+func (t *inflightTracker) begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return false
+	}
+	t.count++
+	return true
+}
+
+// end will count an alert as done
+func (t *inflightTracker) end() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.count--
+	if t.closed && t.count == 0 {
+		close(t.drained)
+	}
+}
+
+// close will stop taking alerts (begin returns false)
+func (t *inflightTracker) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	t.closed = true
+	if t.count == 0 {
+		close(t.drained)
+	}
+}
+
+// wait will close the tracker and wait until no alert is being processed (or the context is done)
+func (t *inflightTracker) wait(ctx context.Context) error {
+	t.close()
+	select {
+	case <-t.drained:
+		return nil
+	case <-ctx.Done():
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return fmt.Errorf("%w: %d alerts", ErrAlertsInFlight, t.count)
+	}
+}
@@ -0,0 +1,95 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+)
+
+// RunOutboxCron starts a cron job to replay the outbox events that were saved with an alert but not published
+// (e.g. the process stopped between saving the alert and notifying), so no alert event is lost
+func (s *Server) RunOutboxCron(ctx context.Context) chan bool {
+	ticker := s.config.Clock().NewTicker(s.config.Outbox.Interval)
+	quit := make(chan bool, 1)
+	s.supervisor.Go(ctx, "outbox", func(ctx context.Context) {
+		for {
+			select {
+			case <-ticker.C():
+				if err := s.replayOutbox(ctx); err != nil {
+					s.logger.Errorf("error replaying the outbox: %s", err.Error())
+				}
+			case <-quit:
+				ticker.Stop()
+				return
+			}
+		}
+	})
+	return quit
+}
+
+// replayOutbox will publish the pending outbox events older than the min age (younger events are being published)
+// Only the cluster leader publishes, a standby would publish the events a second time
+func (s *Server) replayOutbox(ctx context.Context) error {
+	if !s.IsLeader() || !s.inflight.begin() {
+		return nil
+	}
+	defer s.inflight.end()
+
+	pending, err := models.GetPendingOutboxEvents(
+		ctx, s.config.Clock().Now().Add(-s.config.Outbox.MinAge), s.config.Outbox.BatchSize, nil, model.WithAllDependencies(s.config),
+	)
+	if err != nil {
+		return err
+	} else if len(pending) == 0 {
+		return nil
+	}
+	s.logger.Infof("replaying %d outbox events", len(pending))
+	for _, o := range pending {
+		o.Attempts++
+		if err = s.replayOutboxEvent(ctx, o); err != nil {
+			if o.Attempts < s.config.Outbox.MaxAttempts {
+				s.logger.Warnf("failed to replay outbox event %s (attempt %d): %s", o.DedupKey, o.Attempts, err.Error())
+			} else {
+				s.logger.Errorf("giving up on outbox event %s after %d attempts: %s", o.DedupKey, o.Attempts, err.Error())
+				o.Status = models.OutboxStatusFailed
+			}
+			if err = o.Save(ctx); err != nil {
+				s.logger.Errorf("failed to save outbox event %s: %s", o.DedupKey, err.Error())
+			}
+			continue
+		}
+		if err = o.MarkDelivered(ctx); err != nil {
+			s.logger.Errorf("failed to mark outbox event %s delivered: %s", o.DedupKey, err.Error())
+		}
+	}
+	return nil
+}
+
+// replayOutboxEvent will publish the outbox event with the saved alert
+// The node event is not replayed, the node health may have changed since (the heartbeat reports it)
+func (s *Server) replayOutboxEvent(ctx context.Context, o *models.OutboxEvent) error {
+	alert, err := s.store.GetBySequence(ctx, o.SequenceNumber)
+	if err != nil {
+		return err
+	} else if alert == nil {
+		return fmt.Errorf("%w: %d", ErrAlertNotFoundBySequence, o.SequenceNumber)
+	}
+	if err = alert.ReadRaw(); err != nil {
+		return err
+	}
+	s.events.Publish(ctx, &events.Event{
+		Alert:    alert,
+		Err:      o.ActionError(),
+		ID:       o.DedupKey,
+		Node:     o.Node,
+		PeerID:   o.PeerID,
+		Replayed: true,
+		Source:   o.Source,
+		Time:     o.CreatedAt,
+		Type:     events.Type(o.Event),
+	})
+	return nil
+}
@@ -0,0 +1,249 @@
+package p2p
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/clock"
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// Peer sync statuses
+const (
+	PeerSyncStatusUnknown = "unknown" // No sync has been attempted with the peer
+	PeerSyncStatusSyncing = "syncing" // A sync is in progress with the peer
+	PeerSyncStatusSynced  = "synced"  // The last sync with the peer was successful
+	PeerSyncStatusFailed  = "failed"  // The last sync with the peer failed
+)
+
+// Peer reputation adjustments
+const (
+	reputationInvalidMessage = -10 // Peer relayed an invalid alert message
+	reputationMaxScore       = 100 // Maximum reputation score
+	reputationMinScore       = -100
+	reputationSyncFailed     = -1 // Failed to sync with the peer
+	reputationSyncSuccess    = 1  // Synced with the peer
+	reputationValidMessage   = 1  // Peer relayed a valid alert message
+)
+
+// Peerstore keys (set by the identify service)
+const (
+	peerstoreAgentVersion    = "AgentVersion"
+	peerstoreProtocolVersion = "ProtocolVersion"
+)
+
+// PeerInfo is the information about a connected peer
+type PeerInfo struct {
+	AgentVersion    string     `json:"agent_version"`
+	Addresses       []string   `json:"addresses"`
+	ID              string     `json:"id"`
+	LastMessageAt   *time.Time `json:"last_message_at"`
+	LastSyncedAt    *time.Time `json:"last_synced_at"`
+	LatestSequence  uint32     `json:"latest_sequence"`
+	ProtocolVersion string     `json:"protocol_version"`
+	Protocols       []string   `json:"protocols"`
+	Reputation      int        `json:"reputation"`
+	SyncStatus      string     `json:"sync_status"`
+}
+
+// peerState is the tracked state of a peer
+type peerState struct {
+	lastMessageAt  *time.Time
+	lastSyncedAt   *time.Time
+	latestSequence uint32
+	reputation     int
+	syncStatus     string
+	updatedAt      time.Time // Last time the state changed (a disconnected peer is pruned after the TTL)
+}
+
+// peerTracker tracks the state of peers (messages, reputation and sync status)
+type peerTracker struct {
+	sync.RWMutex
+	clock clock.Clock // Source of the update times and the TTL comparison
+	peers map[peer.ID]*peerState
+}
+
+// newPeerTracker will create a new peer tracker using the clock
+func newPeerTracker(clk clock.Clock) *peerTracker {
+	return &peerTracker{clock: clk, peers: make(map[peer.ID]*peerState)}
+}
+
+// get will return the peer state (creating it if needed) and mark it updated, must hold the lock
+func (t *peerTracker) get(peerID peer.ID) *peerState {
+	state, ok := t.peers[peerID]
+	if !ok {
+		state = &peerState{syncStatus: PeerSyncStatusUnknown}
+		t.peers[peerID] = state
+	}
+	state.updatedAt = t.clock.Now()
+	return state
+}
+
+// prune will remove the state of the disconnected peers not updated within the TTL and return how many were removed
+func (t *peerTracker) prune(ttl time.Duration, connected func(peer.ID) bool) int {
+	t.Lock()
+	defer t.Unlock()
+	pruned := 0
+	for peerID, state := range t.peers {
+		if t.clock.Since(state.updatedAt) > ttl && !connected(peerID) {
+			delete(t.peers, peerID)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+// adjustReputation will adjust the reputation score of the peer within the bounds, must hold the lock
+func (s *peerState) adjustReputation(delta int) {
+	s.reputation += delta
+	if s.reputation > reputationMaxScore {
+		s.reputation = reputationMaxScore
+	} else if s.reputation < reputationMinScore {
+		s.reputation = reputationMinScore
+	}
+}
+
+// messageReceived will record a message from the peer
+func (t *peerTracker) messageReceived(peerID peer.ID, valid bool) {
+	t.Lock()
+	defer t.Unlock()
+	state := t.get(peerID)
+	now := t.clock.Now().UTC()
+	state.lastMessageAt = &now
+	if valid {
+		state.adjustReputation(reputationValidMessage)
+	} else {
+		state.adjustReputation(reputationInvalidMessage)
+	}
+}
+
+// syncStarted will record the start of a sync with the peer
+func (t *peerTracker) syncStarted(peerID peer.ID) {
+	t.Lock()
+	defer t.Unlock()
+	t.get(peerID).syncStatus = PeerSyncStatusSyncing
+}
+
+// syncFinished will record the result of a sync with the peer
+func (t *peerTracker) syncFinished(peerID peer.ID, latestSequence uint32, err error) {
+	t.Lock()
+	defer t.Unlock()
+	state := t.get(peerID)
+	now := t.clock.Now().UTC()
+	state.lastMessageAt = &now
+	if err != nil {
+		state.syncStatus = PeerSyncStatusFailed
+		state.adjustReputation(reputationSyncFailed)
+		return
+	}
+	state.lastSyncedAt = &now
+	state.syncStatus = PeerSyncStatusSynced
+	state.latestSequence = latestSequence
+	state.adjustReputation(reputationSyncSuccess)
+}
+
+// sequenceSeen will record a valid alert sequence relayed by the peer (the peer has at least this sequence)
+func (t *peerTracker) sequenceSeen(peerID peer.ID, sequence uint32) {
+	t.Lock()
+	defer t.Unlock()
+	if state := t.get(peerID); sequence > state.latestSequence {
+		state.latestSequence = sequence
+	}
+}
+
+// RunPeerStatePruneCron starts a cron job to remove the tracked state of the peers disconnected for longer than
+// the TTL (a peer reconnecting within it keeps its reputation)
+func (s *Server) RunPeerStatePruneCron(ctx context.Context) chan bool {
+	ticker := s.config.Clock().NewTicker(config.DefaultPeerStateTTL)
+	quit := make(chan bool, 1)
+	s.supervisor.Go(ctx, "peer_state_prune", func(_ context.Context) {
+		for {
+			select {
+			case <-ticker.C():
+				if pruned := s.peers.prune(config.DefaultPeerStateTTL, func(peerID peer.ID) bool {
+					return s.host.Network().Connectedness(peerID) == network.Connected
+				}); pruned > 0 {
+					s.logger.Debugf("removed the state of %d disconnected peers", pruned)
+				}
+			case <-quit:
+				ticker.Stop()
+				return
+			}
+		}
+	})
+	return quit
+}
+
+// SyncState is the sync state observed from the peers
+type SyncState struct {
+	BestPeerID   string     // Peer with the best (highest) sequence
+	BestSequence uint32     // Best (highest) sequence observed from any peer
+	LastSyncedAt *time.Time // Last successful sync with any peer
+}
+
+// SyncState will return the best sequence observed from the peers and the last successful sync time
+func (s *Server) SyncState() *SyncState {
+	s.peers.RLock()
+	defer s.peers.RUnlock()
+
+	state := &SyncState{}
+	for peerID, p := range s.peers.peers {
+		if p.latestSequence > state.BestSequence {
+			state.BestPeerID = peerID.String()
+			state.BestSequence = p.latestSequence
+		}
+		if p.lastSyncedAt != nil && (state.LastSyncedAt == nil || p.lastSyncedAt.After(*state.LastSyncedAt)) {
+			state.LastSyncedAt = p.lastSyncedAt
+		}
+	}
+	return state
+}
+
+// Peers will return the currently connected peers and their tracked state
+func (s *Server) Peers() []*PeerInfo {
+	connected := s.host.Network().Peers()
+	peers := make([]*PeerInfo, 0, len(connected))
+
+	s.peers.RLock()
+	defer s.peers.RUnlock()
+
+	for _, peerID := range connected {
+		info := &PeerInfo{
+			ID:         peerID.String(),
+			SyncStatus: PeerSyncStatusUnknown,
+		}
+
+		// Addresses of the open connections
+		for _, conn := range s.host.Network().ConnsToPeer(peerID) {
+			info.Addresses = append(info.Addresses, conn.RemoteMultiaddr().String())
+		}
+
+		// Versions and protocols (from the identify service)
+		if v, err := s.host.Peerstore().Get(peerID, peerstoreProtocolVersion); err == nil {
+			info.ProtocolVersion, _ = v.(string)
+		}
+		if v, err := s.host.Peerstore().Get(peerID, peerstoreAgentVersion); err == nil {
+			info.AgentVersion, _ = v.(string)
+		}
+		if protocols, err := s.host.Peerstore().GetProtocols(peerID); err == nil {
+			for _, p := range protocols {
+				info.Protocols = append(info.Protocols, string(p))
+			}
+		}
+
+		// Tracked state
+		if state, ok := s.peers.peers[peerID]; ok {
+			info.LastMessageAt = state.lastMessageAt
+			info.LastSyncedAt = state.lastSyncedAt
+			info.LatestSequence = state.latestSequence
+			info.Reputation = state.reputation
+			info.SyncStatus = state.syncStatus
+		}
+
+		peers = append(peers, info)
+	}
+	return peers
+}
@@ -0,0 +1,38 @@
+package p2p
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/clock"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPeerTracker_Prune will test removing the state of the disconnected peers after the TTL
+func TestPeerTracker_Prune(t *testing.T) {
+	t.Parallel()
+
+	const ttl = time.Hour
+	clk := clock.NewMock(time.Unix(1700000000, 0))
+	tracker := newPeerTracker(clk)
+	tracker.messageReceived("connected", true)
+	tracker.messageReceived("disconnected", true)
+	clk.Advance(2 * ttl)
+	tracker.syncFinished("recent", 5, errors.New("sync failed"))
+	connected := func(peerID peer.ID) bool {
+		return peerID == "connected"
+	}
+
+	// Only the disconnected peer past the TTL is removed
+	assert.Equal(t, 1, tracker.prune(ttl, connected))
+	assert.Contains(t, tracker.peers, peer.ID("connected"))
+	assert.Contains(t, tracker.peers, peer.ID("recent"))
+	assert.NotContains(t, tracker.peers, peer.ID("disconnected"))
+
+	// An update keeps the state of a disconnected peer
+	tracker.sequenceSeen("connected", 7)
+	assert.Equal(t, 0, tracker.prune(ttl, func(peer.ID) bool { return false }))
+	assert.Equal(t, uint32(7), tracker.peers["connected"].latestSequence)
+}
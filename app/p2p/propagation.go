@@ -0,0 +1,252 @@
+package p2p
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/metrics"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// Propagation tracking limits
+const (
+	maxPropagationArrivals = 50   // Max duplicate arrivals kept per message
+	maxPropagationMessages = 100  // Max messages kept for the report (oldest are dropped)
+	maxPropagationPeers    = 1000 // Max peers with propagation stats (new peers are not tracked once full)
+)
+
+// PropagationReport is how the gossip messages propagated to this node
+// Peers that rarely deliver a message first and whose duplicates arrive long after the first copy
+// are poorly connected to the rest of the mesh
+type PropagationReport struct {
+	Duplicates uint64                `json:"duplicates"` // Duplicate copies received (all peers)
+	FirstSeen  uint64                `json:"first_seen"` // Messages received (first copies)
+	Messages   []*MessagePropagation `json:"messages"`   // Recent messages (newest first)
+	Peers      []*PeerPropagation    `json:"peers"`      // Stats by peer (ordered by peer ID)
+}
+
+// MessagePropagation is how a single gossip message propagated to this node
+type MessagePropagation struct {
+	Arrivals    []*PropagationArrival `json:"arrivals"` // Duplicate arrivals in order (capped)
+	Duplicates  int                   `json:"duplicates"`
+	FirstPeerID string                `json:"first_peer_id"` // Peer that delivered the first copy
+	FirstSeenAt time.Time             `json:"first_seen_at"`
+	MessageID   string                `json:"message_id"`
+	Sequence    uint32                `json:"sequence,omitempty"` // Alert sequence (once the alert is read)
+	SpreadMS    int64                 `json:"spread_ms"`          // Time from the first copy to the last duplicate
+	Topic       string                `json:"topic"`
+}
+
+// PropagationArrival is a duplicate copy of a message received from a peer
+type PropagationArrival struct {
+	DeltaMS int64  `json:"delta_ms"` // Time after the first copy
+	PeerID  string `json:"peer_id"`
+}
+
+// PeerPropagation is the propagation stats of a peer
+type PeerPropagation struct {
+	AvgDeltaMS int64  `json:"avg_delta_ms"` // Average time the duplicates arrived after the first copy
+	Duplicates uint64 `json:"duplicates"`   // Duplicate copies received from the peer
+	FirstSeen  uint64 `json:"first_seen"`   // Messages the peer delivered first
+	MaxDeltaMS int64  `json:"max_delta_ms"`
+	PeerID     string `json:"peer_id"`
+}
+
+// peerPropagation is the tracked propagation of a peer
+type peerPropagation struct {
+	duplicates uint64
+	firstSeen  uint64
+	maxDelta   time.Duration
+	totalDelta time.Duration
+}
+
+// propagationTracker tracks the first and duplicate arrivals of the gossip messages
+// It is a pubsub raw tracer, the tracer methods are called by the pubsub event loop and must not block
+type propagationTracker struct {
+	sync.RWMutex
+	duplicates uint64
+	firstSeen  uint64
+	messages   map[string]*MessagePropagation
+	order      []string
+	peers      map[peer.ID]*peerPropagation
+}
+
+// newPropagationTracker will create a new propagation tracker
+func newPropagationTracker() *propagationTracker {
+	return &propagationTracker{
+		messages: make(map[string]*MessagePropagation),
+		peers:    make(map[peer.ID]*peerPropagation),
+	}
+}
+
+// peer will return the peer propagation (creating it if there is room), must hold the lock
+func (t *propagationTracker) peer(peerID peer.ID) *peerPropagation {
+	p, ok := t.peers[peerID]
+	if !ok && len(t.peers) < maxPropagationPeers {
+		p = &peerPropagation{}
+		t.peers[peerID] = p
+	}
+	return p
+}
+
+// ValidateMessage is called when the first copy of a message enters validation
+func (t *propagationTracker) ValidateMessage(msg *pubsub.Message) {
+	if msg.Local {
+		return
+	}
+	metrics.Inc(context.Background(), metrics.GossipArrivals.WithLabelValues(
+		metrics.PeerLabel(msg.ReceivedFrom.String()), metrics.ArrivalFirst,
+	))
+
+	t.Lock()
+	defer t.Unlock()
+	t.firstSeen++
+	if p := t.peer(msg.ReceivedFrom); p != nil {
+		p.firstSeen++
+	}
+	if _, ok := t.messages[msg.ID]; ok {
+		return
+	}
+	if len(t.order) >= maxPropagationMessages {
+		delete(t.messages, t.order[0])
+		t.order = t.order[1:]
+	}
+	t.messages[msg.ID] = &MessagePropagation{
+		Arrivals:    make([]*PropagationArrival, 0),
+		FirstPeerID: msg.ReceivedFrom.String(),
+		FirstSeenAt: time.Now().UTC(),
+		MessageID:   msg.ID,
+		Topic:       msg.GetTopic(),
+	}
+	t.order = append(t.order, msg.ID)
+}
+
+// DuplicateMessage is called when a copy of a message that was already received is dropped
+func (t *propagationTracker) DuplicateMessage(msg *pubsub.Message) {
+	if msg.Local {
+		return
+	}
+	metrics.Inc(context.Background(), metrics.GossipArrivals.WithLabelValues(
+		metrics.PeerLabel(msg.ReceivedFrom.String()), metrics.ArrivalDuplicate,
+	))
+
+	t.Lock()
+	defer t.Unlock()
+	t.duplicates++
+	m, ok := t.messages[msg.ID]
+	if !ok {
+		// Our own message or one that was dropped from the report
+		return
+	}
+	delta := time.Since(m.FirstSeenAt)
+	metrics.GossipDuplicateDelay.Observe(delta.Seconds())
+	m.Duplicates++
+	m.SpreadMS = delta.Milliseconds()
+	if len(m.Arrivals) < maxPropagationArrivals {
+		m.Arrivals = append(m.Arrivals, &PropagationArrival{DeltaMS: delta.Milliseconds(), PeerID: msg.ReceivedFrom.String()})
+	}
+	if p := t.peer(msg.ReceivedFrom); p != nil {
+		p.duplicates++
+		p.totalDelta += delta
+		if delta > p.maxDelta {
+			p.maxDelta = delta
+		}
+	}
+}
+
+// setSequence will link the message to the alert sequence number
+func (t *propagationTracker) setSequence(messageID string, sequence uint32) {
+	t.Lock()
+	defer t.Unlock()
+	if m, ok := t.messages[messageID]; ok {
+		m.Sequence = sequence
+	}
+}
+
+// report will return a copy of the tracked propagation
+func (t *propagationTracker) report() *PropagationReport {
+	t.RLock()
+	defer t.RUnlock()
+
+	report := &PropagationReport{
+		Duplicates: t.duplicates,
+		FirstSeen:  t.firstSeen,
+		Messages:   make([]*MessagePropagation, 0, len(t.order)),
+		Peers:      make([]*PeerPropagation, 0, len(t.peers)),
+	}
+	for i := len(t.order) - 1; i >= 0; i-- {
+		m := *t.messages[t.order[i]]
+		m.Arrivals = make([]*PropagationArrival, 0, len(t.messages[t.order[i]].Arrivals))
+		for _, a := range t.messages[t.order[i]].Arrivals {
+			arrival := *a
+			m.Arrivals = append(m.Arrivals, &arrival)
+		}
+		report.Messages = append(report.Messages, &m)
+	}
+	for peerID, p := range t.peers {
+		stats := &PeerPropagation{
+			Duplicates: p.duplicates,
+			FirstSeen:  p.firstSeen,
+			MaxDeltaMS: p.maxDelta.Milliseconds(),
+			PeerID:     peerID.String(),
+		}
+		if p.duplicates > 0 {
+			stats.AvgDeltaMS = (p.totalDelta / time.Duration(p.duplicates)).Milliseconds()
+		}
+		report.Peers = append(report.Peers, stats)
+	}
+	sort.Slice(report.Peers, func(i, j int) bool {
+		return report.Peers[i].PeerID < report.Peers[j].PeerID
+	})
+	return report
+}
+
+// The rest of the pubsub raw tracer is not used
+
+// AddPeer is called when a peer is added to pubsub
+func (t *propagationTracker) AddPeer(peer.ID, protocol.ID) {}
+
+// RemovePeer is called when a peer is removed from pubsub
+func (t *propagationTracker) RemovePeer(peer.ID) {}
+
+// Join is called when a topic is joined
+func (t *propagationTracker) Join(string) {}
+
+// Leave is called when a topic is left
+func (t *propagationTracker) Leave(string) {}
+
+// Graft is called when a peer is grafted on the mesh
+func (t *propagationTracker) Graft(peer.ID, string) {}
+
+// Prune is called when a peer is pruned from the mesh
+func (t *propagationTracker) Prune(peer.ID, string) {}
+
+// DeliverMessage is called when a message is delivered to the subscribers
+func (t *propagationTracker) DeliverMessage(*pubsub.Message) {}
+
+// RejectMessage is called when a message is rejected or ignored
+func (t *propagationTracker) RejectMessage(*pubsub.Message, string) {}
+
+// ThrottlePeer is called when a peer is throttled by the peer gater
+func (t *propagationTracker) ThrottlePeer(peer.ID) {}
+
+// RecvRPC is called when an RPC is received
+func (t *propagationTracker) RecvRPC(*pubsub.RPC) {}
+
+// SendRPC is called when an RPC is sent
+func (t *propagationTracker) SendRPC(*pubsub.RPC, peer.ID) {}
+
+// DropRPC is called when an outbound RPC is dropped
+func (t *propagationTracker) DropRPC(*pubsub.RPC, peer.ID) {}
+
+// UndeliverableMessage is called when a message is dropped because the subscriber is too slow
+func (t *propagationTracker) UndeliverableMessage(*pubsub.Message) {}
+
+// Propagation will return the gossip propagation report (first and duplicate arrivals by message and peer)
+func (s *Server) Propagation() *PropagationReport {
+	return s.propagation.report()
+}
@@ -0,0 +1,141 @@
+package p2p
+
+import (
+	"context"
+	"sync"
+
+	"github.com/bitcoin-sv/alert-system/app/metrics"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+)
+
+// queuedMessage is a gossiped alert message waiting to be processed
+type queuedMessage struct {
+	msg      *pubsub.Message
+	priority string
+	sequence uint32 // Sequence number of the alert (0 if unreadable)
+	topic    string
+}
+
+// alertQueue is the bounded queue between the pubsub readers and the alert processing
+// The consensus-critical alerts are processed first. Once the queue is full the readers wait (they stop reading the
+// subscription, pubsub drops the messages it cannot deliver), a flood of messages cannot grow the memory.
+// An alert processed before its predecessor is enforced and saved as-is (the predecessor is not checked), so a
+// critical alert only jumps ahead if no alert with a lower sequence is queued, and set_keys never does (the alerts
+// queued before it are signed by the keys it replaces).
+type alertQueue struct {
+	critical chan *queuedMessage
+	mu       sync.Mutex
+	normal   chan *queuedMessage
+	queued   map[uint32]int // Sequence numbers of the alerts queued as normal
+	slots    chan struct{}  // Taken by each queued message (the total is bounded, not each priority)
+}
+
+// newAlertQueue will create a queue of up to size messages
+func newAlertQueue(size int) *alertQueue {
+	return &alertQueue{
+		critical: make(chan *queuedMessage, size),
+		normal:   make(chan *queuedMessage, size),
+		queued:   make(map[uint32]int),
+		slots:    make(chan struct{}, size),
+	}
+}
+
+// push will queue the message, waiting for room while the queue is full (false if the context is done first)
+func (q *alertQueue) push(ctx context.Context, topic string, msg *pubsub.Message) bool {
+	select {
+	case q.slots <- struct{}{}:
+	default:
+		metrics.AlertQueueFull.Inc()
+		select {
+		case q.slots <- struct{}{}:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	m := &queuedMessage{msg: msg, topic: topic}
+	m.priority, m.sequence = messagePriority(msg)
+
+	// Kept in order behind the alerts with a lower sequence
+	q.mu.Lock()
+	if m.priority == metrics.PriorityCritical && q.queuedBefore(m.sequence) {
+		m.priority = metrics.PriorityNormal
+	}
+	if m.priority == metrics.PriorityNormal && m.sequence > 0 {
+		q.queued[m.sequence]++
+	}
+	q.mu.Unlock()
+
+	metrics.AlertQueueDepth.WithLabelValues(m.priority).Inc()
+	if m.priority == metrics.PriorityCritical {
+		q.critical <- m
+	} else {
+		q.normal <- m
+	}
+	return true
+}
+
+// queuedBefore will return true if an alert with a lower sequence is queued as normal (locked by the caller)
+func (q *alertQueue) queuedBefore(sequence uint32) bool {
+	for queued := range q.queued {
+		if queued < sequence {
+			return true
+		}
+	}
+	return false
+}
+
+// pop will return the next message (a critical one if any is queued), nil once the context is done
+func (q *alertQueue) pop(ctx context.Context) *queuedMessage {
+	var m *queuedMessage
+	select {
+	case m = <-q.critical:
+	default:
+		select {
+		case m = <-q.critical:
+		case m = <-q.normal:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	<-q.slots
+	if m.priority == metrics.PriorityNormal && m.sequence > 0 {
+		q.mu.Lock()
+		if q.queued[m.sequence]--; q.queued[m.sequence] <= 0 {
+			delete(q.queued, m.sequence)
+		}
+		q.mu.Unlock()
+	}
+	metrics.AlertQueueDepth.WithLabelValues(m.priority).Dec()
+	return m
+}
+
+// depth will return the number of queued messages
+func (q *alertQueue) depth() int {
+	return len(q.slots)
+}
+
+// messagePriority will return the priority and sequence of the gossiped alert message from its header (the
+// signatures are verified when it is processed, an unreadable message is rejected then)
+// A set_keys alert is never critical, it is processed in the order it was received
+func messagePriority(msg *pubsub.Message) (string, uint32) {
+	ak, err := models.NewAlertFromBytes(msg.Data)
+	if err != nil {
+		return metrics.PriorityNormal, 0
+	}
+	if ak.GetAlertType().IsConsensusCritical() && ak.GetAlertType() != models.AlertTypeSetKeys {
+		return metrics.PriorityCritical, ak.SequenceNumber
+	}
+	return metrics.PriorityNormal, ak.SequenceNumber
+}
+
+// processQueue will process the queued alert messages one at a time (in the priority order) until the context is done
+func (s *Server) processQueue(ctx context.Context) {
+	for {
+		m := s.queue.pop(ctx)
+		if m == nil {
+			return
+		}
+		s.handleAlertMessage(ctx, m.topic, m.msg)
+	}
+}
@@ -0,0 +1,71 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/devnet"
+	"github.com/bitcoin-sv/alert-system/app/metrics"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/utils"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newQueueMessage will create a gossiped alert message of the type and sequence
+func newQueueMessage(t *testing.T, sequence uint32, alertType models.AlertType, message []byte) *pubsub.Message {
+	data, err := devnet.NewAlert(sequence, uint32(alertType), message, []string{utils.Key1, utils.Key2, utils.Key3})
+	require.NoError(t, err)
+	return &pubsub.Message{Message: &pb.Message{Data: data}}
+}
+
+// popSequences will pop the queued messages and return their sequences (in the processing order)
+func popSequences(q *alertQueue) []uint32 {
+	sequences := make([]uint32, 0)
+	for q.depth() > 0 {
+		sequences = append(sequences, q.pop(context.Background()).sequence)
+	}
+	return sequences
+}
+
+// TestAlertQueue will test the priority and the order of the queued alerts
+func TestAlertQueue(t *testing.T) {
+	ctx := context.Background()
+	informational := append([]byte{4}, []byte("test")...)
+	invalidateBlock := bytes.Repeat([]byte{1}, 32)
+	setKeys := bytes.Repeat([]byte{2}, 165)
+
+	t.Run("critical alert first", func(t *testing.T) {
+		q := newAlertQueue(10)
+		require.True(t, q.push(ctx, "topic", newQueueMessage(t, 5, models.AlertTypeInformational, informational)))
+		require.True(t, q.push(ctx, "topic", newQueueMessage(t, 3, models.AlertTypeInvalidateBlock, invalidateBlock)))
+		assert.Equal(t, []uint32{3, 5}, popSequences(q))
+	})
+
+	t.Run("critical alert behind a lower sequence", func(t *testing.T) {
+		q := newAlertQueue(10)
+		require.True(t, q.push(ctx, "topic", newQueueMessage(t, 3, models.AlertTypeInformational, informational)))
+		require.True(t, q.push(ctx, "topic", newQueueMessage(t, 4, models.AlertTypeInvalidateBlock, invalidateBlock)))
+		assert.Equal(t, []uint32{3, 4}, popSequences(q))
+	})
+
+	t.Run("set keys in sequence order", func(t *testing.T) {
+		q := newAlertQueue(10)
+		require.True(t, q.push(ctx, "topic", newQueueMessage(t, 3, models.AlertTypeInformational, informational)))
+		require.True(t, q.push(ctx, "topic", newQueueMessage(t, 4, models.AlertTypeSetKeys, setKeys)))
+		assert.Equal(t, []uint32{3, 4}, popSequences(q))
+		assert.Empty(t, q.queued)
+	})
+
+	t.Run("set keys is not critical", func(t *testing.T) {
+		priority, sequence := messagePriority(newQueueMessage(t, 4, models.AlertTypeSetKeys, setKeys))
+		assert.Equal(t, metrics.PriorityNormal, priority)
+		assert.Equal(t, uint32(4), sequence)
+
+		priority, _ = messagePriority(&pubsub.Message{Message: &pb.Message{Data: []byte{1}}})
+		assert.Equal(t, metrics.PriorityNormal, priority)
+	})
+}
@@ -2,10 +2,9 @@ package p2p
 
 import (
 	"context"
-	"crypto/rand"
 	"errors"
 	"fmt"
-	"os"
+	"strconv"
 	"time"
 
 	maddr "github.com/multiformats/go-multiaddr"
@@ -14,9 +13,18 @@ import (
 
 	dht "github.com/libp2p/go-libp2p-kad-dht"
 
+	"github.com/bitcoin-sv/alert-system/app/audit"
+	"github.com/bitcoin-sv/alert-system/app/cluster"
 	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/bitcoin-sv/alert-system/app/health"
+	"github.com/bitcoin-sv/alert-system/app/metrics"
 	"github.com/bitcoin-sv/alert-system/app/models"
 	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/app/notify"
+	"github.com/bitcoin-sv/alert-system/app/store"
+	"github.com/bitcoin-sv/alert-system/app/supervisor"
+	"github.com/bitcoin-sv/alert-system/app/tracing"
 	"github.com/bitcoin-sv/alert-system/app/webhook"
 	"github.com/libp2p/go-libp2p"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
@@ -27,6 +35,7 @@ import (
 	"github.com/libp2p/go-libp2p/core/protocol"
 	drouting "github.com/libp2p/go-libp2p/p2p/discovery/routing"
 	dutil "github.com/libp2p/go-libp2p/p2p/discovery/util"
+	"github.com/libp2p/go-libp2p/p2p/net/conngater"
 	"github.com/mrz1836/go-datastore"
 )
 
@@ -37,24 +46,54 @@ import (
 
 // ServerOptions are the options for the server
 type ServerOptions struct {
-	Config     *config.Config
-	TopicNames []string
+	Config           *config.Config
+	DisableDiscovery bool                   // The DHT and the peer discovery are not started, the peers are connected by the caller
+	Events           *events.Bus            // Event bus to publish to (a new bus if nil)
+	Host             host.Host              // Host to use instead of listening on the P2P IP and port (e.g. an in-memory host), the bans are not enforced on its connections
+	Store            store.AlertStore       // Storage of the alerts and peer bans (the datastore of the config if nil)
+	Supervisor       *supervisor.Supervisor // Recovers the panics in the background jobs (a new supervisor if nil)
+	TopicNames       []string
 }
 
 // Server is the P2P server
 type Server struct {
 	// alertKeyTopicName string
 	connected                     bool
+	cluster                       *cluster.Elector // Leader election (nil if clustering is disabled)
 	config                        *config.Config
+	disableDiscovery              bool // The peers are connected by the caller (no DHT or peer discovery)
 	host                          host.Host
+	logger                        config.LoggerInterface
 	privateKey                    *crypto.PrivKey
 	subscriptions                 map[string]*pubsub.Subscription
 	topicNames                    []string
 	topics                        map[string]*pubsub.Topic
+	events                        *events.Bus
+	fastSync                      fastSyncTracker // Startup fast sync (the latest alerts first, the older ones backfilled)
+	notifier                      *notify.Service
+	webhooks                      *webhook.Dispatcher
 	dht                           *dht.IpfsDHT
+	gater                         *conngater.BasicConnectionGater
+	health                        *health.Service
+	inflight                      *inflightTracker   // Alerts being processed (waited for at shutdown)
+	intake                        context.Context    // Done once the alert intake is stopped (cancels the syncs)
+	stopIntake                    context.CancelFunc // Cancels the intake context
+	nodeUnhealthy                 bool               // Node was unhealthy at the last heartbeat
+	peers                         *peerTracker
+	propagation                   *propagationTracker
+	queue                         *alertQueue // Gossiped alert messages waiting to be processed
+	syncJobs                      *syncJobTracker
 	quitAlertProcessingChannel    chan bool
+	quitHeartbeatChannel          chan bool
+	quitNodeHealthChannel         chan bool
+	quitOutboxChannel             chan bool
+	quitPeerBanExpiryChannel      chan bool
 	quitPeerDiscoveryChannel      chan bool
 	quitPeerInitializationChannel chan bool
+	quitPeerStatePruneChannel     chan bool
+	startedAt                     time.Time
+	store                         store.AlertStore
+	supervisor                    *supervisor.Supervisor
 	//peers         []peer.AddrInfo
 }
 
@@ -65,21 +104,108 @@ func NewServer(o ServerOptions) (*Server, error) {
 
 	o.Config.Services.Log.Debug("creating P2P service")
 
-	// Attempt to read the private key from the file
-	pk, err := readPrivateKey(o.Config.P2P.PrivateKeyPath)
+	// Create the notification channels (fails on an invalid channel config)
+	notifier, err := notify.New(o.Config)
 	if err != nil {
+		return nil, err
+	}
 
-		// If the file doesn't exist, generate a new private key
-		if pk, err = generatePrivateKey(o.Config.P2P.PrivateKeyPath); err != nil {
+	// Create the connection gater (used for banning peers)
+	var gater *conngater.BasicConnectionGater
+	if gater, err = conngater.NewBasicConnectionGater(nil); err != nil {
+		return nil, err
+	}
+
+	// Create a new host (unless one is set)
+	h, pk := o.Host, crypto.PrivKey(nil)
+	if h == nil {
+		if h, pk, err = newHost(o.Config, gater); err != nil {
 			return nil, err
 		}
+	} else {
+		pk = h.Peerstore().PrivKey(h.ID())
+	}
+
+	// Print out the peer ID and addresses
+	o.Config.Services.Log.Debugf("peer ID: %s", h.ID().String())
+	o.Config.Services.Log.Info("connect to me on:")
+	for _, addr := range h.Addrs() {
+		o.Config.Services.Log.Infof(" %s/p2p/%s", addr, h.ID().String())
+	}
+
+	// Use a new event bus and supervisor if none are set
+	if o.Events == nil {
+		o.Events = events.NewBus()
+	}
+	if o.Supervisor == nil {
+		o.Supervisor = supervisor.New(o.Config, o.Events)
+	}
+	if o.Store == nil {
+		o.Store = store.NewDatastore(o.Config)
+	}
+
+	// Create the server (with its health checks) and subscribe the metrics, audit log, webhooks and notifications to its events
+	intake, stopIntake := context.WithCancel(context.Background())
+	s := &Server{
+		disableDiscovery:              o.DisableDiscovery,
+		events:                        o.Events,
+		gater:                         gater,
+		host:                          h,
+		inflight:                      newInflightTracker(),
+		intake:                        intake,
+		notifier:                      notifier,
+		logger:                        config.WithField(o.Config.Services.Log, config.LogFieldModule, "p2p"),
+		peers:                         newPeerTracker(o.Config.Clock()),
+		propagation:                   newPropagationTracker(),
+		queue:                         newAlertQueue(o.Config.P2P.AlertQueueSize),
+		stopIntake:                    stopIntake,
+		syncJobs:                      newSyncJobTracker(syncJobTimeout),
+		topicNames:                    o.TopicNames,
+		privateKey:                    &pk,
+		config:                        o.Config,
+		quitPeerInitializationChannel: make(chan bool),
+		startedAt:                     time.Now(),
+		store:                         o.Store,
+		supervisor:                    o.Supervisor,
+		webhooks:                      webhook.NewDispatcher(o.Config),
+	}
+	if o.Config.Cluster.Enabled {
+		s.cluster = cluster.NewElector(o.Config, cluster.NewDatastoreLeases(o.Config), s.leadershipChanged)
+	}
+	s.health = health.NewService(health.DefaultCheckTimeout, s.healthCheckers()...)
+	s.subscribe()
+	return s, nil
+}
+
+// newHost will create the host listening on the P2P IP and port, with the private key of the P2P key file
+func newHost(conf *config.Config, gater *conngater.BasicConnectionGater) (host.Host, crypto.PrivKey, error) {
+	// Read the private key (generated if the file doesn't exist, unless the keygen command is required)
+	var err error
+	var generated bool
+	var pk crypto.PrivKey
+	if pk, generated, err = LoadPrivateKey(
+		conf.P2P.PrivateKeyPath, !conf.P2P.DisableKeyGeneration,
+	); err != nil {
+		return nil, nil, err
+	}
+
+	// Record the key in the audit log (identified by the peer ID)
+	var peerID peer.ID
+	if peerID, err = peer.IDFromPrivateKey(pk); err != nil {
+		return nil, nil, err
+	}
+	if err = conf.Services.Audit.Record(
+		context.Background(), audit.EventKeyLoaded, audit.ActorSystem, conf.P2P.PrivateKeyPath,
+		map[string]string{"generated": strconv.FormatBool(generated), "peer_id": peerID.String()},
+	); err != nil {
+		conf.Services.Log.Errorf("failed to record the key in the audit log: %s", err.Error())
 	}
 
 	var extMultiAddr maddr.Multiaddr
-	if o.Config.P2P.BroadcastIP != "" {
-		extMultiAddr, err = maddr.NewMultiaddr(fmt.Sprintf("/ip4/%s/tcp/%s", o.Config.P2P.BroadcastIP, o.Config.P2P.Port))
+	if conf.P2P.BroadcastIP != "" {
+		extMultiAddr, err = maddr.NewMultiaddr(fmt.Sprintf("/ip4/%s/tcp/%s", conf.P2P.BroadcastIP, conf.P2P.Port))
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
@@ -91,55 +217,75 @@ func NewServer(o ServerOptions) (*Server, error) {
 		return addrs
 	}
 
-	// Create a new host
+	// Create the host
 	var h host.Host
 	if h, err = libp2p.New(
-		libp2p.ListenAddrStrings(fmt.Sprintf("/ip4/%s/tcp/%s", o.Config.P2P.IP, o.Config.P2P.Port)),
-		libp2p.Identity(*pk),
+		libp2p.ListenAddrStrings(fmt.Sprintf("/ip4/%s/tcp/%s", conf.P2P.IP, conf.P2P.Port)),
+		libp2p.Identity(pk),
 		libp2p.EnableHolePunching(),
 		libp2p.AddrsFactory(addressFactory),
+		libp2p.ConnectionGater(gater),
 	); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-
-	// Print out the peer ID and addresses
-	o.Config.Services.Log.Debugf("peer ID: %s", h.ID().String())
-	o.Config.Services.Log.Info("connect to me on:")
-	for _, addr := range h.Addrs() {
-		o.Config.Services.Log.Infof(" %s/p2p/%s", addr, h.ID().String())
-	}
-
-	// Return the server
-	return &Server{
-		host:                          h,
-		topicNames:                    o.TopicNames,
-		privateKey:                    pk,
-		config:                        o.Config,
-		quitPeerInitializationChannel: make(chan bool),
-	}, nil
+	return h, pk, nil
 }
 
 // Start the server and subscribe to all topics
 func (s *Server) Start(ctx context.Context) error {
-	s.config.Services.Log.Info("p2p service initializing & starting")
+	s.logger.Info("p2p service initializing & starting")
 
-	// Initialize the DHT
-	kademliaDHT, err := s.initDHT(ctx)
-	if err != nil {
+	// Stop the syncs with the server context as well
+	context.AfterFunc(ctx, s.stopIntake)
+
+	// Initialize the DHT (unless the peers are connected by the caller)
+	var err error
+	var routingDiscovery *drouting.RoutingDiscovery
+	if !s.disableDiscovery {
+		var kademliaDHT *dht.IpfsDHT
+		if kademliaDHT, err = s.initDHT(ctx); err != nil {
+			return err
+		}
+		s.dht = kademliaDHT
+		routingDiscovery = drouting.NewRoutingDiscovery(kademliaDHT)
+	}
+
+	// Load any active peer bans into the connection gater
+	if err = s.loadPeerBans(ctx); err != nil {
 		return err
 	}
-	s.dht = kademliaDHT
 
 	// Advertise our existence so that other peers can find us
-	routingDiscovery := drouting.NewRoutingDiscovery(kademliaDHT)
-	for _, topicName := range s.topicNames {
-		dutil.Advertise(ctx, routingDiscovery, topicName)
+	if routingDiscovery != nil {
+		for _, topicName := range s.topicNames {
+			dutil.Advertise(ctx, routingDiscovery, topicName)
+		}
 	}
 
-	s.quitPeerDiscoveryChannel = s.RunPeerDiscovery(ctx, routingDiscovery)
-	s.quitAlertProcessingChannel = s.RunAlertProcessingCron(ctx)
+	// Elect the cluster leader (only the leader processes the alerts)
+	if s.cluster != nil {
+		s.supervisor.Go(ctx, "cluster_election", s.cluster.Run)
+	}
 
-	ps, err := pubsub.NewGossipSub(ctx, s.host, pubsub.WithDiscovery(routingDiscovery))
+	if routingDiscovery != nil {
+		s.quitPeerDiscoveryChannel = s.RunPeerDiscovery(ctx, routingDiscovery)
+	} else {
+		s.connected = true
+	}
+	s.quitAlertProcessingChannel = s.RunAlertProcessingCron(ctx)
+	s.quitPeerBanExpiryChannel = s.RunPeerBanExpiryCron(ctx)
+	s.quitPeerStatePruneChannel = s.RunPeerStatePruneCron(ctx)
+	s.quitHeartbeatChannel = s.RunHeartbeatCron(ctx)
+	s.quitOutboxChannel = s.RunOutboxCron(ctx)
+	s.quitNodeHealthChannel = s.RunNodeHealthCron(ctx)
+	s.webhooks.Start(ctx)
+	s.notifier.Start(ctx)
+
+	options := []pubsub.Option{pubsub.WithRawTracer(s.propagation)}
+	if routingDiscovery != nil {
+		options = append(options, pubsub.WithDiscovery(routingDiscovery))
+	}
+	ps, err := pubsub.NewGossipSub(ctx, s.host, options...)
 	if err != nil {
 		return err
 	}
@@ -147,27 +293,44 @@ func (s *Server) Start(ctx context.Context) error {
 	subscriptions := map[string]*pubsub.Subscription{}
 
 	s.host.SetStreamHandler(protocol.ID(s.config.P2P.AlertSystemProtocolID), func(stream network.Stream) {
+		if !s.config.Services.StreamHandlers.Acquire() {
+			s.logger.Warnf("too many sync streams, resetting the stream of peer %s", stream.Conn().RemotePeer().String())
+			_ = stream.Reset()
+			return
+		}
+		defer s.config.Services.StreamHandlers.Release()
+		if !s.inflight.begin() {
+			_ = stream.Reset()
+			return
+		}
+		defer s.inflight.end()
+
 		t := StreamThread{
-			stream: stream,
-			config: s.config,
-			ctx:    ctx,
-			peer:   stream.Conn().RemotePeer(),
+			stream:   stream,
+			config:   s.config,
+			ctx:      ctx,
+			events:   s.events,
+			isLeader: s.IsLeader,
+			logger:   config.WithField(s.logger, config.LogFieldPeerID, stream.Conn().RemotePeer().String()),
+			peer:     stream.Conn().RemotePeer(),
+			store:    s.store,
 		}
 
 		if err = t.ProcessSyncMessage(ctx); err != nil {
-			s.config.Services.Log.Errorf("failed to process sync message: %v", err.Error())
+			s.logger.Errorf("failed to process sync message: %v", err.Error())
 			//_ = stream.Reset()
 		} else {
-			s.config.Services.Log.Debugf("closing stream %v for peer %v", stream.ID(), t.peer.String())
+			s.logger.Debugf("closing stream %v for peer %v", stream.ID(), t.peer.String())
 			//_ = stream.Close()
 		}
 		//_ = stream.Close()
 	})
 
-	s.config.Services.Log.Debugf("stream handler set")
+	s.logger.Debugf("stream handler set")
 	for !s.connected {
 		time.Sleep(5 * time.Second)
 	}
+	s.supervisor.Go(ctx, "alert_queue", s.processQueue)
 	for _, topicName := range s.topicNames {
 		var topic *pubsub.Topic
 		if topic, err = ps.Join(topicName); err != nil {
@@ -182,16 +345,18 @@ func (s *Server) Start(ctx context.Context) error {
 		subscriptions[topicName] = sub
 
 		// Sync the subscriber
-		go s.Subscribe(ctx, sub, s.host.ID())
+		s.supervisor.Go(ctx, "pubsub_subscriber", func(ctx context.Context) {
+			s.Subscribe(ctx, sub, s.host.ID())
+		})
 	}
 	s.topics = topics
 	s.subscriptions = subscriptions
-	s.config.Services.Log.Infof("P2P successfully started")
+	s.logger.Infof("P2P successfully started")
 	go func() {
 		for { //nolint:gosimple // This is the only way to perform this loop at the moment
 			select {
 			case <-ctx.Done():
-				s.config.Services.Log.Info("p2p service shutting down")
+				s.logger.Info("p2p service shutting down")
 				return
 			}
 		}
@@ -199,149 +364,233 @@ func (s *Server) Start(ctx context.Context) error {
 	return nil
 }
 
+// Supervisor will return the supervisor that recovers the panics in the background jobs
+func (s *Server) Supervisor() *supervisor.Supervisor {
+	return s.supervisor
+}
+
 // Connected returns true if the server is connected
 func (s *Server) Connected() bool {
 	return s.connected
 }
 
-// Stop the server
-func (s *Server) Stop(_ context.Context) error {
-	// todo there needs to be a way to stop the server
-	s.config.Services.Log.Info("stopping P2P service")
-	s.quitPeerDiscoveryChannel <- true
-	s.quitAlertProcessingChannel <- true
-	s.quitPeerInitializationChannel <- true
+// Stop the server (stops all background jobs and releases the cluster lease, then closes the DHT and host)
+func (s *Server) Stop(ctx context.Context) error {
+	s.logger.Info("stopping P2P service")
+	for _, quit := range []chan bool{
+		s.quitPeerInitializationChannel,
+		s.quitPeerDiscoveryChannel,
+		s.quitAlertProcessingChannel,
+		s.quitPeerBanExpiryChannel,
+		s.quitPeerStatePruneChannel,
+		s.quitHeartbeatChannel,
+		s.quitOutboxChannel,
+		s.quitNodeHealthChannel,
+	} {
+		signalQuit(quit)
+	}
+	s.webhooks.Stop()
+	if err := s.notifier.Close(); err != nil {
+		s.logger.Errorf("error closing the notification channels: %s", err.Error())
+	}
+
+	// Hand the leadership over to a standby (the alerts being processed are done)
+	if s.cluster != nil {
+		if err := s.cluster.Stop(ctx); err != nil {
+			s.logger.Errorf("error releasing the cluster lease: %s", err.Error())
+		}
+	}
+
+	// Close the DHT and the host (closes all peer connections)
+	if s.dht != nil {
+		if err := s.dht.Close(); err != nil {
+			s.logger.Errorf("error closing dht: %s", err.Error())
+		}
+	}
+	return s.host.Close()
+}
+
+// StopIntake will stop taking alerts (gossip, sync streams and the retries), the alerts being processed continue
+func (s *Server) StopIntake(_ context.Context) error {
+	s.logger.Info("stopping the alert intake")
+	s.inflight.close()
+	s.stopIntake()
+	signalQuit(s.quitAlertProcessingChannel)
+	signalQuit(s.quitOutboxChannel)
+	s.host.RemoveStreamHandler(protocol.ID(s.config.P2P.AlertSystemProtocolID))
+	for _, sub := range s.subscriptions {
+		sub.Cancel()
+	}
 	return nil
 }
 
+// DrainAlerts will wait until the alerts being processed are enforced and saved (or the context is done)
+func (s *Server) DrainAlerts(ctx context.Context) error {
+	return s.inflight.wait(ctx)
+}
+
+// FlushNotifications will deliver the queued webhooks and notifications, then stop their workers
+func (s *Server) FlushNotifications(ctx context.Context) error {
+	return errors.Join(s.webhooks.Flush(ctx), s.notifier.Flush(ctx))
+}
+
+// signalQuit will signal the quit channel without blocking (the job may not be running)
+func signalQuit(quit chan bool) {
+	if quit == nil {
+		return
+	}
+	select {
+	case quit <- true:
+	default:
+	}
+}
+
 // RunAlertProcessingCron starts a cron job to attempt to retry unprocessed alerts
 func (s *Server) RunAlertProcessingCron(ctx context.Context) chan bool {
-	ticker := time.NewTicker(s.config.AlertProcessingInterval)
+	ticker := s.config.Clock().NewTicker(s.config.AlertProcessingInterval)
 	quit := make(chan bool, 1)
-	go func() {
+	s.supervisor.Go(ctx, "alert_processing", func(ctx context.Context) {
 		for {
 			select {
-			case <-ticker.C:
+			case <-ticker.C():
 				err := s.processAlerts(ctx)
 				if err != nil {
-					s.config.Services.Log.Errorf("error processing alerts: %v", err.Error())
+					s.logger.Errorf("error processing alerts: %v", err.Error())
 				}
 			case <-quit:
 				ticker.Stop()
 				return
 			}
 		}
-	}()
+	})
 	return quit
 }
 
-// processAlerts performs the alert processing
+// processAlerts performs the alert processing (on the cluster leader)
 func (s *Server) processAlerts(ctx context.Context) error {
-	alerts, err := models.GetAllUnprocessedAlerts(ctx, nil, model.WithAllDependencies(s.config))
+	if !s.IsLeader() || !s.inflight.begin() {
+		return nil
+	}
+	defer s.inflight.end()
+
+	alerts, err := s.store.ListUnprocessed(ctx)
 	if err != nil {
 		return err
 	}
-	s.config.Services.Log.Infof("Attempting to process %d failed alerts", len(alerts))
+	s.logger.Infof("Attempting to process %d failed alerts", len(alerts))
 	success := 0
 	for _, alert := range alerts {
-		alert.SetOptions(model.WithAllDependencies(s.config))
-		// Serialize the alert data and hash
-		err := alert.ReadRaw()
-		if err != nil {
-			continue
-		}
-		alert.SerializeData()
-		// Process the alert
-		ak := alert.ProcessAlertMessage()
-		if ak == nil {
-			continue
-		}
-		if err = ak.Read(alert.GetRawMessage()); err != nil {
+		var processed bool
+		if processed, err = s.retryAlert(ctx, alert); err != nil {
 			return err
+		} else if processed {
+			success++
 		}
-		s.config.Services.Log.Debugf("attempting to process alert %d of type %d", alert.SequenceNumber, alert.GetAlertType())
-		alert.Processed = true
-		if err = ak.Do(ctx); err != nil {
-			s.config.Services.Log.Errorf("failed to process alert %d; err: %v", alert.SequenceNumber, err.Error())
-			alert.Processed = false
+	}
+	s.logger.Infof("Processed %d failed alerts", success)
+	return nil
+}
+
+// retryAlert will retry the actions of an unprocessed alert, true if it is processed
+func (s *Server) retryAlert(ctx context.Context, alert *models.AlertMessage) (bool, error) {
+	alert.SetOptions(model.WithAllDependencies(s.config))
+	// Serialize the alert data and hash
+	err := alert.ReadRaw()
+	if err != nil {
+		return false, nil
+	}
+	alert.SerializeData()
+	// Process the alert
+	ak := alert.ProcessAlertMessage()
+	if ak == nil {
+		return false, nil
+	}
+	if err = ak.Read(alert.GetRawMessage()); err != nil {
+		return false, err
+	}
+	alertCtx, logger := config.ContextWithField(ctx, s.logger, config.LogFieldAlertSequence, alert.SequenceNumber)
+
+	// Lock the alert (another instance sharing the datastore may be retrying it)
+	release, locked := lockAlert(alertCtx, s.config, logger, alert.SequenceNumber)
+	if !locked {
+		return false, nil
+	}
+	defer release()
+	if s.config.AlertLocks.Enabled {
+		var current *models.AlertMessage
+		if current, err = s.store.GetBySequence(ctx, alert.SequenceNumber); err != nil {
+			return false, err
+		} else if current != nil && current.Processed {
+			logger.Infof("alert %d was already processed by another instance", alert.SequenceNumber)
+			return false, nil
 		}
+	}
 
-		if alert.Processed {
-			success++
-			// Save the alert
-			if err = alert.Save(ctx); err != nil {
-				return err
-			}
+	logger.Debugf("attempting to process alert %d of type %d", alert.SequenceNumber, alert.GetAlertType())
+	alert.Processed = true
+	actionErr := ak.Do(alertCtx)
+	s.recordNodeAction(ctx, alert, actionErr)
+	if actionErr != nil {
+		logger.Errorf("failed to process alert %d; err: %v", alert.SequenceNumber, actionErr.Error())
+		alert.Processed = false
+	}
+
+	if alert.Processed {
+		// Save the alert (with the enforced event in the outbox)
+		queueEnforced(s.config, alert, events.SourceRetry, "", actionErr)
+		if err = s.store.SaveAlert(ctx, alert); err != nil {
+			return false, err
 		}
 	}
-	s.config.Services.Log.Infof("Processed %d failed alerts", success)
-	return nil
+	publishEnforced(ctx, s.events, s.config, alert, events.SourceRetry, "", actionErr)
+	return alert.Processed, nil
+}
+
+// recordNodeAction will record the result of the alert action executed against the node
+func (s *Server) recordNodeAction(ctx context.Context, alert *models.AlertMessage, actionErr error) {
+	if _, err := models.RecordNodeAction(
+		ctx, alert, actionErr, model.WithAllDependencies(s.config),
+	); err != nil {
+		s.logger.Errorf("failed to record node action for alert %d: %s", alert.SequenceNumber, err.Error())
+	}
+}
+
+// observeLatency will record the end-to-end latency (receipt to node action) and the gossip propagation delay
+func (s *Server) observeLatency(ctx context.Context, alert *models.AlertMessage, alertType string) {
+	latency := time.Since(alert.ReceivedAt())
+	metrics.Observe(ctx, metrics.AlertLatency.WithLabelValues(alertType), latency.Seconds())
+	delay, ok := alert.PropagationDelay()
+	if ok {
+		metrics.Observe(ctx, metrics.AlertPropagationDelay.WithLabelValues(alertType), delay.Seconds())
+	}
+	s.logger.Debugf("alert %d enforced %s after receipt (propagation delay %s)", alert.SequenceNumber, latency, delay)
 }
 
 // RunPeerDiscovery starts a cron job to resync peers and update routable peers
 func (s *Server) RunPeerDiscovery(ctx context.Context, routingDiscovery *drouting.RoutingDiscovery) chan bool {
-	ticker := time.NewTicker(s.config.P2P.PeerDiscoveryInterval)
+	ticker := s.config.Clock().NewTicker(s.config.P2P.PeerDiscoveryInterval)
 	quit := make(chan bool, 1)
-	go func() {
+	s.supervisor.Go(ctx, "peer_discovery", func(ctx context.Context) {
 		err := s.discoverPeers(ctx, routingDiscovery)
 		if err != nil {
-			s.config.Services.Log.Errorf("error discovering peers: %v", err.Error())
+			s.logger.Errorf("error discovering peers: %v", err.Error())
 		}
 		for {
 			select {
-			case <-ticker.C:
+			case <-ticker.C():
 				err := s.discoverPeers(ctx, routingDiscovery)
 				if err != nil {
-					s.config.Services.Log.Errorf("error discovering peers: %v", err.Error())
+					s.logger.Errorf("error discovering peers: %v", err.Error())
 				}
 			case <-quit:
 				ticker.Stop()
 				return
 			}
 		}
-	}()
+	})
 	return quit
 }
 
-// generatePrivateKey generates a private key and stores it in `private_key` file
-func generatePrivateKey(filePath string) (*crypto.PrivKey, error) {
-	// Generate a new key pair
-	privateKey, _, err := crypto.GenerateEd25519Key(rand.Reader)
-	if err != nil {
-		return nil, err
-	}
-
-	// Convert private key to bytes
-	var privateBytes []byte
-	if privateBytes, err = crypto.MarshalPrivateKey(privateKey); err != nil {
-		return nil, err
-	}
-
-	// Save private key to a file
-	if err = os.WriteFile(filePath, privateBytes, 0644); err != nil { //nolint:gosec // This is a local private key
-		return nil, err
-	}
-
-	return &privateKey, nil
-}
-
-// readPrivateKey reads a private key from `private_key` file
-func readPrivateKey(filePath string) (*crypto.PrivKey, error) {
-	// Read private key from a file
-	privateBytes, err := os.ReadFile(filePath) //nolint:gosec // This is a local private key
-	if err != nil {
-		return nil, err
-	}
-
-	// Unmarshal the private key bytes into a key
-	var privateKey crypto.PrivKey
-	if privateKey, err = crypto.UnmarshalPrivateKey(privateBytes); err != nil {
-		return nil, err
-	}
-
-	return &privateKey, nil
-}
-
 // Subscriptions lists all current subscriptions
 func (s *Server) Subscriptions() map[string]*pubsub.Subscription {
 	return s.subscriptions
@@ -354,13 +603,13 @@ func (s *Server) Topics() map[string]*pubsub.Topic {
 
 // discoverPeers will discover peers
 func (s *Server) discoverPeers(ctx context.Context, routingDiscovery *drouting.RoutingDiscovery) error {
-	s.config.Services.Log.Infof("Running peer discovery at %s", time.Now().String())
+	s.logger.Infof("Running peer discovery at %s", time.Now().String())
 
 	// Look for others who have announced and attempt to connect to them
 	connected := 0
 	for connected < 2 {
 		for _, topicName := range s.topicNames {
-			s.config.Services.Log.Debugf("searching for peers for topic %s..\n", topicName)
+			s.logger.Debugf("searching for peers for topic %s..\n", topicName)
 
 			var peerChan <-chan peer.AddrInfo
 			var err error
@@ -377,40 +626,25 @@ func (s *Server) discoverPeers(ctx context.Context, routingDiscovery *drouting.R
 				}
 
 				// Failed to connect to peer
-				s.config.Services.Log.Debugf("attempting connection to %s", foundPeer.ID.String())
+				s.logger.Debugf("attempting connection to %s", foundPeer.ID.String())
 
 				if err = s.host.Connect(ctx, foundPeer); err != nil {
 					// we fail to connect to a lot of peers. Just ignore it for now.
-					s.config.Services.Log.Debugf("failed connecting to %s, error: %s", foundPeer.ID.String(), err.Error())
+					s.logger.Debugf("failed connecting to %s, error: %s", foundPeer.ID.String(), err.Error())
 					continue
 				}
 
 				// Connected to peer
-				s.config.Services.Log.Infof("connected to: %s", foundPeer.ID.String())
-
-				// Open a stream to the peer
-				var stream network.Stream
-				if stream, err = s.host.NewStream(ctx, foundPeer.ID, protocol.ID(s.config.P2P.AlertSystemProtocolID)); err != nil {
-					s.config.Services.Log.Debugf("failed new stream to %s error: %s", foundPeer.ID.String(), err.Error())
-					continue
-				}
-
-				// Sync the stream thread
-				t := StreamThread{
-					config:      s.config,
-					ctx:         ctx,
-					peer:        foundPeer.ID,
-					stream:      stream,
-					quitChannel: s.quitPeerDiscoveryChannel,
-				}
+				s.logger.Infof("connected to: %s", foundPeer.ID.String())
 
-				// Sync the stream thread
-				if err = t.Sync(ctx); err != nil {
-					s.config.Services.Log.Debugf("failed to start stream thread to %s error: %s", foundPeer.ID.String(), err.Error())
+				// Sync with the peer
+				var latestSequence uint32
+				if latestSequence, err = s.syncPeer(ctx, foundPeer.ID, s.quitPeerDiscoveryChannel); err != nil {
+					s.logger.Debugf("failed to sync with %s error: %s", foundPeer.ID.String(), err.Error())
 					continue
 				}
 
-				s.config.Services.Log.Infof("successfully synced up to %d from peer %s", t.LatestSequence(), foundPeer.ID.String())
+				s.logger.Infof("successfully synced up to %d from peer %s", latestSequence, foundPeer.ID.String())
 
 				// Set the flag
 				connected++
@@ -420,23 +654,87 @@ func (s *Server) discoverPeers(ctx context.Context, routingDiscovery *drouting.R
 	}
 
 	// We are connected
-	s.config.Services.Log.Debugf("peer discovery complete")
-	s.config.Services.Log.Debugf("connected to %d peers\n", len(s.host.Network().Peers()))
-	s.config.Services.Log.Debugf("peerstore has %d peers\n", len(s.host.Peerstore().Peers()))
-	s.config.Services.Log.Infof("Successfully discovered %d active peers at %s", connected, time.Now().String())
+	s.logger.Debugf("peer discovery complete")
+	s.logger.Debugf("connected to %d peers\n", len(s.host.Network().Peers()))
+	s.logger.Debugf("peerstore has %d peers\n", len(s.host.Peerstore().Peers()))
+	s.logger.Infof("Successfully discovered %d active peers at %s", connected, time.Now().String())
 	s.connected = true
 	return nil
 }
 
+// syncPeer will open a sync stream to the peer and sync any missing alerts (returns the peer's latest sequence)
+func (s *Server) syncPeer(ctx context.Context, peerID peer.ID, quitChannel chan bool) (_ uint32, err error) {
+	start := time.Now()
+	defer func() {
+		metrics.SyncOperations.WithLabelValues(metrics.PeerLabel(peerID.String()), metrics.Result(err)).Inc()
+		metrics.SyncDuration.Observe(time.Since(start).Seconds())
+		config.LogSlow(
+			config.WithField(s.logger, config.LogFieldPeerID, peerID.String()), s.config.SlowLog.Sync, start,
+			"sync with peer %s", peerID.String(),
+		)
+	}()
+
+	// Wait for the sync at shutdown, and cancel it once the alert intake is stopped
+	if !s.inflight.begin() {
+		return 0, ErrIntakeStopped
+	}
+	defer s.inflight.end()
+	syncCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(s.intake, cancel)()
+
+	// Open a stream to the peer
+	var stream network.Stream
+	if stream, err = s.host.NewStream(syncCtx, peerID, protocol.ID(s.config.P2P.AlertSystemProtocolID)); err != nil {
+		return 0, err
+	}
+
+	// Sync the stream thread
+	t := StreamThread{
+		config:      s.config,
+		ctx:         syncCtx,
+		events:      s.events,
+		fastSync:    s.fastSync.alerts(s.config),
+		isLeader:    s.IsLeader,
+		logger:      config.WithField(s.logger, config.LogFieldPeerID, peerID.String()),
+		peer:        peerID,
+		store:       s.store,
+		stream:      stream,
+		quitChannel: quitChannel,
+	}
+	s.peers.syncStarted(peerID)
+	err = t.Sync(syncCtx)
+	s.peers.syncFinished(peerID, t.LatestSequence(), err)
+
+	// Backfill the alerts older than the fast sync in the background
+	if t.snapshotFrom > 0 && t.myLatestSequence >= t.snapshotFrom {
+		s.fastSync.synced(t.snapshotFrom, t.myLatestSequence)
+		if err == nil {
+			s.supervisor.Go(ctx, "sync_backfill", func(ctx context.Context) { s.backfill(ctx, peerID) })
+		}
+	} else {
+		if t.reenforceFrom > 0 { // The fast sync fell back to the in-order sync
+			s.fastSync.synced(t.reenforceFrom, t.reenforceTo)
+		}
+		if err == nil {
+			s.finishFastSync(ctx)
+		}
+	}
+	return t.LatestSequence(), err
+}
+
 // Subscribe will subscribe to the alert system
 func (s *Server) Subscribe(ctx context.Context, subscriber *pubsub.Subscription, hostID peer.ID) {
-	s.config.Services.Log.Infof("subscribing to %s topic", subscriber.Topic())
+	s.logger.Infof("subscribing to %s topic", subscriber.Topic())
 	for {
 
 		msg, err := subscriber.Next(ctx)
 
-		if err != nil {
-			s.config.Services.Log.Infof("error subscribing via next: %s", err.Error())
+		if errors.Is(err, pubsub.ErrSubscriptionCancelled) || ctx.Err() != nil {
+			s.logger.Infof("unsubscribed from %s topic", subscriber.Topic())
+			return
+		} else if err != nil {
+			s.logger.Infof("error subscribing via next: %s", err.Error())
 			continue
 		}
 
@@ -445,81 +743,169 @@ func (s *Server) Subscribe(ctx context.Context, subscriber *pubsub.Subscription,
 			continue
 		}
 
-		// Read the alert key header
-		var ak *models.AlertMessage
-		if ak, err = models.NewAlertFromBytes(msg.Data, model.WithAllDependencies(s.config)); err != nil {
-			s.config.Services.Log.Errorf("error reading alert key: %s", err.Error())
+		// Dropped on purpose (fault injection, the alert is synced from the peers later)
+		if s.config.Services.Chaos.DropGossip() {
+			s.logger.Debugf("chaos: dropped gossip message from %s", msg.ReceivedFrom.String())
 			continue
 		}
 
-		// Set the hash
-		ak.SerializeData()
+		// Queue the message (waits while the queue is full)
+		s.queue.push(ctx, subscriber.Topic(), msg)
+	}
+}
 
-		// Ensure signatures are valid
-		var valid bool
-		if valid, err = ak.AreSignaturesValid(ctx); err != nil {
-			s.config.Services.Log.Infof("error verifying signatures: %s", err.Error())
-			continue
-		}
+// handleAlertMessage will verify, process and save an alert message received from a peer
+// Each stage is traced (receive -> verify -> process -> persist) so slow alerts can be diagnosed
+func (s *Server) handleAlertMessage(ctx context.Context, topic string, msg *pubsub.Message) {
+	tags := map[string]string{
+		config.LogFieldModule: "p2p",
+		config.LogFieldPeerID: msg.ReceivedFrom.String(),
+	}
+	defer s.supervisor.Recover("alert_message", tags)
 
-		// Ensure the signature is valid
-		if !valid {
-			// TODO save these messages still and ban the peer?
-			s.config.Services.Log.Info("signature block is invalid")
-			continue
-		}
+	// Not taken once the shutdown started (the alert is synced from the peers after the restart)
+	if !s.inflight.begin() {
+		return
+	}
+	defer s.inflight.end()
+
+	var err error
+	receivedAt := time.Now()
+	alertType, result := metrics.LabelUnknown, metrics.ResultError
+	ctx, span := tracing.Start(ctx, tracing.SpanAlertReceive, tracing.AttrPeerID.String(msg.ReceivedFrom.String()))
+	defer func() {
+		tracing.End(span, err)
+		metrics.Inc(ctx, metrics.PubSubMessages.WithLabelValues(
+			topic, metrics.PeerLabel(msg.ReceivedFrom.String()), alertType, result,
+		))
+	}()
 
-		// Ensure the sequence number is correct
-		if _, err = models.GetAlertMessageBySequenceNumber(
-			ctx, ak.SequenceNumber-1, model.WithAllDependencies(s.config),
-		); err != nil {
-			// TODO save these messages still and ban the peer? and possibly resync
-			s.config.Services.Log.Errorf("failed to find prior sequenced alert (num %d): %s", ak.SequenceNumber-1, err.Error())
-			continue
-		}
+	// Read the alert key header (the child logger is carried by the context down to the alert action)
+	var logger config.LoggerInterface
+	ctx, logger = config.ContextWithField(ctx, s.logger, config.LogFieldPeerID, msg.ReceivedFrom.String())
+	var ak *models.AlertMessage
+	if ak, err = models.NewAlertFromBytes(msg.Data, model.WithAllDependencies(s.config)); err != nil {
+		logger.Errorf("error reading alert key: %s", err.Error())
+		s.peers.messageReceived(msg.ReceivedFrom, false)
+		result = metrics.ResultInvalid
+		return
+	}
 
-		// Check if the alert already exists
-		var dup *models.AlertMessage
-		if dup, err = models.GetAlertMessageBySequenceNumber(
-			ctx, ak.SequenceNumber, model.WithAllDependencies(s.config),
-		); err == nil && dup != nil && len(dup.Hash) > 0 {
-			// TODO save these messages still?
-			s.config.Services.Log.Errorf("alert %s already has sequence number %d", dup.Hash, ak.SequenceNumber)
-			continue
-		}
+	// Set the hash
+	ak.SerializeData()
+	ak.SetReceivedAt(receivedAt)
+	s.propagation.setSequence(msg.ID, ak.SequenceNumber)
+	ctx, logger = config.ContextWithField(ctx, logger, config.LogFieldAlertSequence, ak.SequenceNumber)
+	tags[config.LogFieldAlertSequence] = strconv.FormatUint(uint64(ak.SequenceNumber), 10)
+	alertType = metrics.AlertTypeLabel(ak.GetAlertType().Name())
+	span.SetAttributes(
+		tracing.AttrAlertSequence.Int64(int64(ak.SequenceNumber)),
+		tracing.AttrAlertType.Int64(int64(ak.GetAlertType())),
+	)
+	s.events.Publish(ctx, &events.Event{
+		Alert: ak, PeerID: msg.ReceivedFrom.String(), Source: events.SourceGossip, Type: events.AlertReceived,
+	})
 
-		// Did we get a real error?
-		if err != nil && !errors.Is(err, datastore.ErrNoResults) {
-			s.config.Services.Log.Errorf("error looking for duplicate alert: %s", err.Error())
-			continue
-		}
+	// Ensure signatures are valid
+	var valid bool
+	verifyStart := time.Now()
+	verifyCtx, verifySpan := tracing.Start(ctx, tracing.SpanAlertVerify)
+	valid, err = ak.AreSignaturesValid(verifyCtx)
+	tracing.End(verifySpan, err)
+	config.LogSlow(logger, s.config.SlowLog.Signature, verifyStart, "signature verification of alert %d", ak.SequenceNumber)
+	if err != nil {
+		logger.Infof("error verifying signatures: %s", err.Error())
+		metrics.Inc(verifyCtx, metrics.SignatureVerifications.WithLabelValues(alertType, metrics.ResultError))
+		return
+	}
 
-		// Process the alert message into correct interface
-		am := ak.ProcessAlertMessage()
-		if err = am.Read(ak.GetRawMessage()); err != nil {
-			s.config.Services.Log.Errorf("failed to read message: %s", err.Error())
-			continue
-		}
-		ak.Processed = true
+	// Ensure the signature is valid
+	if !valid {
+		// TODO save these messages still and ban the peer?
+		logger.Info("signature block is invalid")
+		metrics.Inc(verifyCtx, metrics.SignatureVerifications.WithLabelValues(alertType, metrics.ResultInvalid))
+		s.peers.messageReceived(msg.ReceivedFrom, false)
+		result = metrics.ResultInvalid
+		err = errors.New("signature block is invalid")
+		return
+	}
+	metrics.Inc(verifyCtx, metrics.SignatureVerifications.WithLabelValues(alertType, metrics.ResultOK))
+	s.peers.messageReceived(msg.ReceivedFrom, true)
+	s.peers.sequenceSeen(msg.ReceivedFrom, ak.SequenceNumber)
+
+	// Left to the cluster leader (it enforces and saves the alert in the shared datastore)
+	if !s.IsLeader() {
+		logger.Debugf("standby, alert %d is left to the cluster leader", ak.SequenceNumber)
+		result = metrics.ResultStandby
+		return
+	}
 
-		// Perform alert action
-		if err = am.Do(ctx); err != nil {
-			s.config.Services.Log.Errorf("failed to do alert action: %s", err.Error())
-			ak.Processed = false
-		}
+	// Ensure the sequence number is correct
+	if _, err = s.store.GetBySequence(ctx, ak.SequenceNumber-1); err != nil {
+		// TODO save these messages still and ban the peer? and possibly resync
+		logger.Errorf("failed to find prior sequenced alert (num %d): %s", ak.SequenceNumber-1, err.Error())
+		return
+	}
 
-		// Save the alert message
-		if err = ak.Save(ctx); err != nil {
-			s.config.Services.Log.Errorf("failed to save alert message: %s", err.Error())
-		}
+	// Lock the alert (another instance sharing the datastore may be enforcing it, it is saved when released)
+	release, locked := lockAlert(ctx, s.config, logger, ak.SequenceNumber)
+	if !locked {
+		result = metrics.ResultLocked
+		return
+	}
+	defer release()
+
+	// Check if the alert already exists
+	var dup *models.AlertMessage
+	if dup, err = s.store.GetBySequence(ctx, ak.SequenceNumber); err == nil && dup != nil && len(dup.Hash) > 0 {
+		// TODO save these messages still?
+		logger.Errorf("alert %s already has sequence number %d", dup.Hash, ak.SequenceNumber)
+		result = metrics.ResultDuplicate
+		return
+	}
 
-		s.config.Services.Log.Infof("[%s] got alert type: %d, from: %s", subscriber.Topic(), ak.GetAlertType(), msg.ReceivedFrom.String())
+	// Did we get a real error?
+	if err != nil && !errors.Is(err, datastore.ErrNoResults) {
+		logger.Errorf("error looking for duplicate alert: %s", err.Error())
+		return
+	}
+	err = nil
+	s.events.Publish(ctx, &events.Event{
+		Alert: ak, PeerID: msg.ReceivedFrom.String(), Source: events.SourceGossip, Type: events.AlertVerified,
+	})
 
-		// Send the webhook
-		if len(s.config.AlertWebhookURL) > 0 {
-			if err = webhook.PostAlert(ctx, s.config.Services.HTTPClient, s.config.AlertWebhookURL, ak); err != nil {
-				s.config.Services.Log.Errorf("error processing webhook request: %s", err.Error())
-			}
-		}
+	// Process the alert message into correct interface
+	am := ak.ProcessAlertMessage()
+	if err = am.Read(ak.GetRawMessage()); err != nil {
+		logger.Errorf("failed to read message: %s", err.Error())
+		return
 	}
+	ak.Processed = true
+
+	// Perform alert action
+	processCtx, processSpan := tracing.Start(ctx, tracing.SpanAlertProcess)
+	processErr := am.Do(processCtx)
+	tracing.End(processSpan, processErr)
+	s.observeLatency(ctx, ak, alertType)
+	s.recordNodeAction(ctx, ak, processErr)
+	if processErr != nil {
+		logger.Errorf("failed to do alert action: %s", processErr.Error())
+		ak.Processed = false
+	}
+
+	// Save the alert message (with the enforced event in the outbox)
+	queueEnforced(s.config, ak, events.SourceGossip, msg.ReceivedFrom.String(), processErr)
+	persistCtx, persistSpan := tracing.Start(ctx, tracing.SpanAlertPersist)
+	err = s.store.SaveAlert(persistCtx, ak)
+	tracing.End(persistSpan, err)
+	if err != nil {
+		logger.Errorf("failed to save alert message: %s", err.Error())
+	} else {
+		result = metrics.ResultOK
+	}
+
+	logger.Infof("got alert type %d on topic %s", ak.GetAlertType(), topic)
+
+	// Publish the result (webhooks, audit log and subscribers)
+	publishEnforced(ctx, s.events, s.config, ak, events.SourceGossip, msg.ReceivedFrom.String(), processErr)
 }
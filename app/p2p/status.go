@@ -0,0 +1,51 @@
+package p2p
+
+import (
+	"context"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/buildinfo"
+	"github.com/bitcoin-sv/alert-system/app/cluster"
+	"github.com/bitcoin-sv/alert-system/app/health"
+	"github.com/bitcoin-sv/alert-system/app/heartbeat"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+)
+
+// Status is the operator summary of the alert system (the heartbeat, the sync state, the backlog and the health)
+type Status struct {
+	heartbeat.Heartbeat
+	BestSequence  uint32         `json:"best_sequence"`            // Best (highest) sequence observed from the peers
+	Cluster       *cluster.State `json:"cluster,omitempty"`        // Role in the cluster (if clustering is enabled)
+	Health        *health.Report `json:"health"`                   // Aggregated health
+	LastSyncedAt  *time.Time     `json:"last_synced_at,omitempty"` // Last successful sync with any peer
+	PendingAlerts int64          `json:"pending_alerts"`           // Alerts that weren't successfully processed
+	PendingEvents int64          `json:"pending_events"`           // Outbox events not published yet
+	QueuedAlerts  int            `json:"queued_alerts"`            // Gossiped alert messages waiting to be processed
+	Version       string         `json:"version"`                  // Release version
+}
+
+// Status will return the status summary (the health is checked once, the heartbeat reuses its node check)
+func (s *Server) Status(ctx context.Context) *Status {
+	report := s.Health(ctx)
+	sync := s.SyncState()
+	st := &Status{
+		Heartbeat:    *s.heartbeat(ctx, report),
+		BestSequence: sync.BestSequence,
+		Cluster:      s.ClusterState(),
+		Health:       report,
+		LastSyncedAt: sync.LastSyncedAt,
+		QueuedAlerts: s.queue.depth(),
+		Version:      buildinfo.Get().Version,
+	}
+
+	// Count the backlog
+	var err error
+	if st.PendingAlerts, err = models.CountUnprocessedAlerts(ctx, model.WithAllDependencies(s.config)); err != nil {
+		s.logger.Errorf("status failed to count the unprocessed alerts: %s", err.Error())
+	}
+	if st.PendingEvents, err = models.CountPendingOutboxEvents(ctx, model.WithAllDependencies(s.config)); err != nil {
+		s.logger.Errorf("status failed to count the pending outbox events: %s", err.Error())
+	}
+	return st
+}
@@ -0,0 +1,49 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bitcoin-sv/alert-system/app/models"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+)
+
+// ProcessAlert will process the raw alert like a gossiped one (verified, enforced and saved) without publishing it,
+// returns the saved alert (used by the simulation, where a recorded alert history is replayed)
+// The action of a saved alert may have failed (the alert is not marked processed)
+func (s *Server) ProcessAlert(ctx context.Context, raw []byte) (*models.AlertMessage, error) {
+	topicName := s.config.P2P.TopicName
+	s.handleAlertMessage(ctx, topicName, &pubsub.Message{
+		Message:      &pb.Message{Data: raw, Topic: &topicName},
+		ReceivedFrom: s.host.ID(),
+	})
+
+	// The alert is only saved if it was valid (the reason is logged)
+	alert, err := models.NewAlertFromBytes(raw)
+	if err != nil {
+		return nil, err
+	}
+	var saved *models.AlertMessage
+	if saved, err = s.store.GetBySequence(ctx, alert.SequenceNumber); err != nil {
+		return nil, err
+	} else if saved == nil || saved.Hash != alert.Hash {
+		return nil, ErrAlertNotSaved
+	}
+	return saved, nil
+}
+
+// SubmitAlert will process the raw alert like a gossiped one (verified, enforced and saved) then publish it to the
+// peers, returns the saved alert (used by the devnet, where the alerts are signed locally)
+func (s *Server) SubmitAlert(ctx context.Context, raw []byte) (*models.AlertMessage, error) {
+	topicName := s.config.P2P.TopicName
+	topic, ok := s.topics[topicName]
+	if !ok {
+		return nil, fmt.Errorf("not subscribed to topic %s", topicName)
+	}
+	saved, err := s.ProcessAlert(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	return saved, topic.Publish(ctx, raw)
+}
@@ -2,6 +2,11 @@ package p2p
 
 import (
 	"encoding/binary"
+	"io"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libsv/go-p2p/wire"
 )
 
 // IWantLatest is the byte for "I want latest"
@@ -16,6 +21,10 @@ const IGotSequenceNumber = 0x03
 // IGotLatest is the byte for "I got latest"
 const IGotLatest = 0x04
 
+// MaxSyncMessageSize is the max size of a sync message read from a peer (an alert of the max gossip size and the
+// message header), a peer sending a larger length is not read
+const MaxSyncMessageSize = pubsub.DefaultMaxMessageSize + 5
+
 // SyncMessage is the message for syncing
 type SyncMessage struct {
 	Data           []byte `json:"data"`
@@ -41,6 +50,22 @@ func NewSyncMessageFromBytes(in []byte) (*SyncMessage, error) {
 	return &s, nil
 }
 
+// TypeName will return the name of the message type (used as the metrics label)
+func (s *SyncMessage) TypeName() string {
+	switch s.Type {
+	case IWantLatest:
+		return "want_latest"
+	case IWantSequenceNumber:
+		return "want_sequence_number"
+	case IGotSequenceNumber:
+		return "got_sequence_number"
+	case IGotLatest:
+		return "got_latest"
+	default:
+		return "unknown"
+	}
+}
+
 // Serialize will serialize the sync message
 func (s *SyncMessage) Serialize() []byte {
 	var ret []byte
@@ -49,3 +74,13 @@ func (s *SyncMessage) Serialize() []byte {
 	ret = append(ret, s.Data...)
 	return ret
 }
+
+// ReadSyncFrame will read the next length-prefixed sync message of the stream (empty when the peer is done)
+func ReadSyncFrame(r io.Reader) ([]byte, error) {
+	return wire.ReadVarBytes(r, 0, MaxSyncMessageSize, config.ApplicationName)
+}
+
+// WriteSyncFrame will write the sync message to the stream, prefixed with its length
+func WriteSyncFrame(w io.Writer, msg *SyncMessage) error {
+	return wire.WriteVarBytes(w, 0, msg.Serialize())
+}
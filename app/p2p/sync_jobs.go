@@ -0,0 +1,228 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// Sync job statuses
+const (
+	SyncJobStatusCompleted = "completed" // All peers were synced
+	SyncJobStatusFailed    = "failed"    // At least one peer failed to sync
+	SyncJobStatusRunning   = "running"   // The sync is in progress
+)
+
+// Sync job limits
+const (
+	maxSyncJobs    = 100              // Max jobs kept for polling (oldest finished jobs are dropped)
+	syncJobTimeout = 10 * time.Minute // Max time for a job to sync all peers
+)
+
+// errSyncJobStopped is the error of a job that stopped before finishing (it panicked)
+var errSyncJobStopped = errors.New("sync job stopped before finishing, see the logs")
+
+// SyncJob is an on-demand sync with one or all connected peers
+type SyncJob struct {
+	Error      string         `json:"error,omitempty"`
+	FinishedAt *time.Time     `json:"finished_at"`
+	ID         string         `json:"id"`
+	PeerID     string         `json:"peer_id,omitempty"` // Target peer (empty is all connected peers)
+	Peers      []*SyncJobPeer `json:"peers"`
+	StartedAt  time.Time      `json:"started_at"`
+	Status     string         `json:"status"`
+}
+
+// SyncJobPeer is the sync progress for a single peer in a job
+type SyncJobPeer struct {
+	Error          string `json:"error,omitempty"`
+	LatestSequence uint32 `json:"latest_sequence"`
+	PeerID         string `json:"peer_id"`
+	Status         string `json:"status"`
+}
+
+// copy will return a deep copy of the job (safe to return while the job is running)
+func (j *SyncJob) copy() *SyncJob {
+	job := *j
+	job.Peers = make([]*SyncJobPeer, 0, len(j.Peers))
+	for _, p := range j.Peers {
+		jobPeer := *p
+		job.Peers = append(job.Peers, &jobPeer)
+	}
+	return &job
+}
+
+// syncJobTracker keeps the recent sync jobs for polling (one job runs at a time)
+type syncJobTracker struct {
+	sync.RWMutex
+	jobs    map[string]*SyncJob
+	order   []string
+	timeout time.Duration // Max time for a job to sync all peers
+}
+
+// newSyncJobTracker will create a new sync job tracker
+func newSyncJobTracker(timeout time.Duration) *syncJobTracker {
+	return &syncJobTracker{jobs: make(map[string]*SyncJob), timeout: timeout}
+}
+
+// add will add the job unless a job is running (dropping the oldest finished job if full)
+func (t *syncJobTracker) add(job *SyncJob) error {
+	t.Lock()
+	defer t.Unlock()
+	for _, id := range t.order {
+		if t.jobs[id].Status == SyncJobStatusRunning {
+			return fmt.Errorf("%w: %s", ErrSyncJobRunning, id)
+		}
+	}
+	if len(t.order) >= maxSyncJobs {
+		for i, id := range t.order {
+			if t.jobs[id].Status != SyncJobStatusRunning {
+				delete(t.jobs, id)
+				t.order = append(t.order[:i], t.order[i+1:]...)
+				break
+			}
+		}
+	}
+	t.jobs[job.ID] = job
+	t.order = append(t.order, job.ID)
+	return nil
+}
+
+// get will return a copy of the job (nil if not found)
+func (t *syncJobTracker) get(id string) *SyncJob {
+	t.RLock()
+	defer t.RUnlock()
+	if job, ok := t.jobs[id]; ok {
+		return job.copy()
+	}
+	return nil
+}
+
+// update will update the job while holding the lock
+func (t *syncJobTracker) update(fn func()) {
+	t.Lock()
+	defer t.Unlock()
+	fn()
+}
+
+// StartSync will start an on-demand sync with the peer (or all connected peers if empty)
+// The sync runs in the background until the alert intake is stopped, poll the returned job ID for progress
+// with SyncJob() (ErrSyncJobRunning if a job is running)
+func (s *Server) StartSync(peerID string) (*SyncJob, error) {
+
+	// Only the cluster leader saves the synced alerts
+	if !s.IsLeader() {
+		return nil, ErrStandby
+	}
+
+	// Get the peers to sync with
+	var targets []peer.ID
+	if len(peerID) > 0 {
+		target, err := peer.Decode(peerID)
+		if err != nil {
+			return nil, err
+		} else if s.host.Network().Connectedness(target) != network.Connected {
+			return nil, ErrPeerNotConnected
+		}
+		targets = []peer.ID{target}
+	} else if targets = s.host.Network().Peers(); len(targets) == 0 {
+		return nil, ErrNoConnectedPeers
+	}
+
+	// Create the job
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	job := &SyncJob{
+		ID:        id.String(),
+		PeerID:    peerID,
+		Peers:     make([]*SyncJobPeer, 0, len(targets)),
+		StartedAt: time.Now().UTC(),
+		Status:    SyncJobStatusRunning,
+	}
+	for _, target := range targets {
+		job.Peers = append(job.Peers, &SyncJobPeer{PeerID: target.String(), Status: PeerSyncStatusUnknown})
+	}
+	if err = s.syncJobs.add(job); err != nil {
+		return nil, err
+	}
+	s.logger.Infof("started sync job %s with %d peer(s)", job.ID, len(targets))
+
+	// Sync in the background (not tied to the request, cancelled once the alert intake is stopped)
+	started := job.copy()
+	s.supervisor.Go(s.intake, "sync_job", func(ctx context.Context) {
+		s.runSyncJob(ctx, job, targets)
+	})
+	return started, nil
+}
+
+// SyncJob will return the sync job by ID (nil if not found)
+func (s *Server) SyncJob(id string) *SyncJob {
+	return s.syncJobs.get(id)
+}
+
+// runSyncJob will sync with each peer in turn, recording the progress on the job
+// The job fails if a peer fails to sync, the job times out or the context is done (the peers left are not synced)
+func (s *Server) runSyncJob(ctx context.Context, job *SyncJob, targets []peer.ID) {
+	ctx, cancel := context.WithTimeout(ctx, s.syncJobs.timeout)
+	defer cancel()
+
+	jobErr := errSyncJobStopped
+	defer s.finishSyncJob(job, &jobErr)
+	defer s.supervisor.Recover("sync_job", map[string]string{"sync_job": job.ID})
+
+	failed := false
+	for i, target := range targets {
+		if ctx.Err() != nil {
+			break
+		}
+		jobPeer := job.Peers[i]
+		s.syncJobs.update(func() {
+			jobPeer.Status = PeerSyncStatusSyncing
+		})
+		latestSequence, err := s.syncPeer(ctx, target, nil)
+		s.syncJobs.update(func() {
+			jobPeer.LatestSequence = latestSequence
+			if err != nil {
+				failed = true
+				jobPeer.Error = err.Error()
+				jobPeer.Status = PeerSyncStatusFailed
+				return
+			}
+			jobPeer.Status = PeerSyncStatusSynced
+		})
+	}
+
+	// The job error (if any)
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		jobErr = fmt.Errorf("sync job timed out after %s", s.syncJobs.timeout.String())
+	case ctx.Err() != nil:
+		jobErr = ErrIntakeStopped
+	case failed:
+		jobErr = errors.New("one or more peers failed to sync")
+	default:
+		jobErr = nil
+	}
+}
+
+// finishSyncJob will record the end of the job (failed with the error, if any)
+func (s *Server) finishSyncJob(job *SyncJob, jobErr *error) {
+	s.syncJobs.update(func() {
+		now := time.Now().UTC()
+		job.FinishedAt = &now
+		job.Status = SyncJobStatusCompleted
+		if *jobErr != nil {
+			job.Error = (*jobErr).Error()
+			job.Status = SyncJobStatusFailed
+		}
+	})
+	s.logger.Infof("sync job %s finished with status %s", job.ID, job.Status)
+}
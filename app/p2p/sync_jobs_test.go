@@ -0,0 +1,151 @@
+package p2p
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/app/store"
+	"github.com/bitcoin-sv/alert-system/app/supervisor"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSyncProtocolID is the alert system protocol of the sync job tests
+const testSyncProtocolID = "/bitcoin/alert-system/test"
+
+// newTestSyncServer will return a server syncing with a connected peer (with an in-memory store holding the
+// genesis alert) and the peer host
+func newTestSyncServer(t *testing.T, timeout time.Duration) (*Server, host.Host) {
+	mn, err := mocknet.FullMeshConnected(2)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = mn.Close()
+	})
+
+	conf := &config.Config{}
+	conf.Services.Log = &config.ExtendedLogger{Logger: log.New(io.Discard, "", 0)}
+	conf.P2P.AlertSystemProtocolID = testSyncProtocolID
+	alertStore := store.NewMemory()
+	require.NoError(t, alertStore.SaveAlert(context.Background(), models.NewAlertMessage(model.New())))
+	intake, stopIntake := context.WithCancel(context.Background())
+	t.Cleanup(stopIntake)
+	return &Server{
+		config:     conf,
+		events:     events.NewBus(),
+		host:       mn.Hosts()[0],
+		inflight:   newInflightTracker(),
+		intake:     intake,
+		logger:     conf.Services.Log,
+		peers:      newPeerTracker(conf.Clock()),
+		stopIntake: stopIntake,
+		store:      alertStore,
+		supervisor: supervisor.New(conf, nil),
+		syncJobs:   newSyncJobTracker(timeout),
+	}, mn.Hosts()[1]
+}
+
+// stallSync will make the peer accept the sync stream without ever replying
+func stallSync(peerHost host.Host) {
+	peerHost.SetStreamHandler(testSyncProtocolID, func(stream network.Stream) {
+		_, _ = io.Copy(io.Discard, stream)
+	})
+}
+
+// waitSyncJob will wait for the job to finish and return it
+func waitSyncJob(t *testing.T, s *Server, id string) *SyncJob {
+	var job *SyncJob
+	require.Eventually(t, func() bool {
+		job = s.SyncJob(id)
+		return job != nil && job.Status != SyncJobStatusRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+// TestServer_StartSync will test the lifecycle of the sync jobs
+func TestServer_StartSync(t *testing.T) {
+	t.Run("no connected peers", func(t *testing.T) {
+		s, peerHost := newTestSyncServer(t, time.Minute)
+		require.NoError(t, s.host.Network().ClosePeer(peerHost.ID()))
+
+		_, err := s.StartSync("")
+		require.ErrorIs(t, err, ErrNoConnectedPeers)
+	})
+
+	t.Run("peer without the protocol, the job fails", func(t *testing.T) {
+		s, peerHost := newTestSyncServer(t, time.Minute)
+
+		job, err := s.StartSync(peerHost.ID().String())
+		require.NoError(t, err)
+		assert.Equal(t, SyncJobStatusRunning, job.Status)
+
+		job = waitSyncJob(t, s, job.ID)
+		assert.Equal(t, SyncJobStatusFailed, job.Status)
+		assert.NotNil(t, job.FinishedAt)
+		require.Len(t, job.Peers, 1)
+		assert.Equal(t, PeerSyncStatusFailed, job.Peers[0].Status)
+		assert.NotEmpty(t, job.Peers[0].Error)
+	})
+
+	t.Run("one job at a time", func(t *testing.T) {
+		s, peerHost := newTestSyncServer(t, time.Minute)
+		stallSync(peerHost)
+
+		job, err := s.StartSync("")
+		require.NoError(t, err)
+		_, err = s.StartSync("")
+		require.ErrorIs(t, err, ErrSyncJobRunning)
+
+		// A new job starts once the running job is finished
+		s.stopIntake()
+		waitSyncJob(t, s, job.ID)
+		s.intake, s.stopIntake = context.WithCancel(context.Background())
+		t.Cleanup(s.stopIntake)
+		s.inflight = newInflightTracker()
+		_, err = s.StartSync("")
+		require.NoError(t, err)
+	})
+
+	t.Run("timed out", func(t *testing.T) {
+		s, peerHost := newTestSyncServer(t, 100*time.Millisecond)
+		stallSync(peerHost)
+
+		job, err := s.StartSync("")
+		require.NoError(t, err)
+
+		job = waitSyncJob(t, s, job.ID)
+		assert.Equal(t, SyncJobStatusFailed, job.Status)
+		assert.Contains(t, job.Error, "timed out")
+		assert.Equal(t, PeerSyncStatusFailed, job.Peers[0].Status)
+	})
+
+	t.Run("cancelled by stopping the intake", func(t *testing.T) {
+		s, peerHost := newTestSyncServer(t, time.Minute)
+		stallSync(peerHost)
+
+		job, err := s.StartSync("")
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			return s.SyncJob(job.ID).Peers[0].Status == PeerSyncStatusSyncing
+		}, 5*time.Second, 10*time.Millisecond)
+		require.NoError(t, s.StopIntake(context.Background()))
+
+		job = waitSyncJob(t, s, job.ID)
+		assert.Equal(t, SyncJobStatusFailed, job.Status)
+		assert.Equal(t, ErrIntakeStopped.Error(), job.Error)
+
+		// The sync was waited for, and no new sync starts
+		require.NoError(t, s.inflight.wait(context.Background()))
+		_, err = s.syncPeer(context.Background(), peerHost.ID(), nil)
+		require.ErrorIs(t, err, ErrIntakeStopped)
+	})
+}
@@ -0,0 +1,54 @@
+package p2p
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/libsv/go-p2p/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// FuzzSyncFraming will test the sync stream of a peer is read without panicking or allocating more than the max
+// sync message size (the frames, the sync messages and the alerts they carry)
+func FuzzSyncFraming(f *testing.F) {
+	var stream bytes.Buffer
+	for _, msg := range []*SyncMessage{
+		{Type: IWantLatest},
+		{Type: IGotLatest, SequenceNumber: 7},
+		{Type: IWantSequenceNumber, SequenceNumber: 2},
+		{Type: IGotSequenceNumber, SequenceNumber: 2, Data: bytes.Repeat([]byte{1}, 240)},
+	} {
+		require.NoError(f, WriteSyncFrame(&stream, msg))
+	}
+	f.Add(stream.Bytes())
+	f.Add([]byte{0})
+	f.Add([]byte{1, 9})
+	f.Add([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r := bytes.NewReader(data)
+		for {
+			b, err := ReadSyncFrame(r)
+			if err != nil || len(b) == 0 {
+				return
+			}
+			require.LessOrEqual(t, len(b), MaxSyncMessageSize)
+
+			var msg *SyncMessage
+			if msg, err = NewSyncMessageFromBytes(b); err != nil {
+				return
+			}
+			if msg.Type != IWantLatest {
+				require.Equal(t, b, msg.Serialize())
+			}
+			if msg.Type == IGotSequenceNumber {
+				_, _ = models.NewAlertFromBytes(msg.Data)
+			}
+
+			var frame bytes.Buffer
+			require.NoError(t, WriteSyncFrame(&frame, msg))
+			require.Equal(t, wire.VarIntSerializeSize(uint64(len(msg.Serialize())))+len(msg.Serialize()), frame.Len())
+		}
+	})
+}
@@ -4,15 +4,17 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
-	"math"
 	"time"
 
 	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/bitcoin-sv/alert-system/app/metrics"
 	"github.com/bitcoin-sv/alert-system/app/models"
 	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/app/reporting"
+	"github.com/bitcoin-sv/alert-system/app/store"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
-	"github.com/libsv/go-p2p/wire"
 )
 
 // Thread is an interface for a thread
@@ -25,13 +27,26 @@ type Thread interface {
 type StreamThread struct {
 	config           *config.Config
 	ctx              context.Context // TODO should remove this, should be passed in via methods only
+	events           *events.Bus
+	fastSync         uint32      // Syncs only the latest alerts first if more are missing (0 syncs them all in order)
+	isLeader         func() bool // Syncs the alerts if true (nil is a single instance, always the leader)
 	latestSequence   uint32
+	logger           config.LoggerInterface
 	myLatestSequence uint32
 	peer             peer.ID
+	reenforceFrom    uint32 // First alert enforced by a fast sync that fell back to the in-order sync (0 if none)
+	reenforceTo      uint32 // Last alert enforced by a fast sync that fell back to the in-order sync
+	snapshotFrom     uint32 // First alert synced by the fast sync (0 if all the missing alerts were synced)
+	store            store.AlertStore
 	stream           network.Stream
 	quitChannel      chan bool
 }
 
+// leader will return true if the thread saves the synced alerts (a cluster standby only serves its alerts)
+func (s *StreamThread) leader() bool {
+	return s.isLeader == nil || s.isLeader()
+}
+
 // LatestSequence will return the threads latest sequence
 func (s *StreamThread) LatestSequence() uint32 {
 	return s.latestSequence
@@ -40,49 +55,61 @@ func (s *StreamThread) LatestSequence() uint32 {
 // Sync will start the thread
 func (s *StreamThread) Sync(ctx context.Context) error {
 
-	// Get the latest alert
-	a, err := models.GetLatestAlert(ctx, nil, model.WithAllDependencies(s.config))
-	if err != nil {
-		s.config.Services.Log.Errorf("failed to get latest alert: %s", err.Error())
+	// Get the sequence to sync from
+	var err error
+	if s.myLatestSequence, err = s.resumeSequence(ctx); err != nil {
 		return err
-	} else if a == nil {
-		s.config.Services.Log.Error(ErrAlertNotLatest.Error())
-		return ErrAlertNotLatest
 	}
 
-	s.myLatestSequence = a.SequenceNumber
 	// construct get latest message
 	msg := SyncMessage{
 		Type: IWantLatest,
 	}
-	data := msg.Serialize()
 
 	defer func() {
 		_ = s.stream.Close()
 	}()
 
-	if err = wire.WriteVarBytes(s.stream, 0, data); err != nil {
+	if err = s.write(&msg); err != nil {
 		return err
 	}
 
-	s.config.Services.Log.Debugf("requested latest sequence in stream %s", s.stream.ID())
+	s.logger.Debugf("requested latest sequence in stream %s", s.stream.ID())
 
 	return s.ProcessSyncMessage(ctx)
 
 }
 
+// write will send the sync message to the peer
+func (s *StreamThread) write(msg *SyncMessage) error {
+	metrics.SyncMessages.WithLabelValues(metrics.DirectionOut, msg.TypeName()).Inc()
+	return WriteSyncFrame(s.stream, msg)
+}
+
+// respond will send the response to the peer (delayed by the fault injection, if enabled)
+func (s *StreamThread) respond(ctx context.Context, msg *SyncMessage) error {
+	if err := s.config.Services.Chaos.DelaySync(ctx); err != nil {
+		return err
+	}
+	return s.write(msg)
+}
+
 // ProcessSyncMessage will process the sync message
 func (s *StreamThread) ProcessSyncMessage(ctx context.Context) error {
-	done := make(chan error)
+	done := make(chan error, 1) // Buffered, the reader does not block once the sync is given up
 	go func() {
+		defer reporting.Recover(s.config.Services.Reporter, map[string]string{
+			config.LogFieldModule: "p2p",
+			config.LogFieldPeerID: s.peer.String(),
+		})
 		for {
-			b, err := wire.ReadVarBytes(s.stream, 0, math.MaxUint64, config.ApplicationName)
+			b, err := ReadSyncFrame(s.stream)
 			if err != nil {
 				if s.stream.Conn().IsClosed() || s.stream.Stat().Transient {
 					done <- nil
 					return
 				}
-				s.config.Services.Log.Debugf("failed to read sync message: %s; closing stream", err.Error())
+				s.logger.Debugf("failed to read sync message: %s; closing stream", err.Error())
 				done <- s.stream.Close()
 				return
 			}
@@ -94,54 +121,55 @@ func (s *StreamThread) ProcessSyncMessage(ctx context.Context) error {
 			}
 			var msg *SyncMessage
 			if msg, err = NewSyncMessageFromBytes(b); err != nil {
-				s.config.Services.Log.Errorf("failed to convert to sync message: %s", err.Error())
+				s.logger.Errorf("failed to convert to sync message: %s", err.Error())
 				done <- err
 				return
 			}
+			metrics.SyncMessages.WithLabelValues(metrics.DirectionIn, msg.TypeName()).Inc()
 			switch msg.Type {
 			case IGotLatest:
-				s.config.Services.Log.Debugf("received latest sequence %d from peer %s", msg.SequenceNumber, s.peer.String())
+				s.logger.Debugf("received latest sequence %d from peer %s", msg.SequenceNumber, s.peer.String())
 				if err = s.ProcessGotLatest(ctx, msg); err != nil {
 					done <- err
 					return
 				}
-				if s.myLatestSequence >= s.latestSequence {
+				if s.myLatestSequence >= s.latestSequence || !s.leader() {
 					_ = s.stream.Close()
 					done <- nil
 					return
 				}
-				s.config.Services.Log.Debugf("wrote msg requesting next sequence %d from peer %s", s.myLatestSequence+1, s.peer.String())
+				s.logger.Debugf("wrote msg requesting next sequence %d from peer %s", s.myLatestSequence+1, s.peer.String())
 			case IGotSequenceNumber:
-				s.config.Services.Log.Debugf("received IGotSequenceNumber %d from peer %s", msg.SequenceNumber, s.peer.String())
+				s.logger.Debugf("received IGotSequenceNumber %d from peer %s", msg.SequenceNumber, s.peer.String())
 				if err = s.ProcessGotSequenceNumber(msg); err != nil {
 					done <- err
 					return
 				}
-				if s.myLatestSequence == s.latestSequence {
+				if s.myLatestSequence == s.latestSequence || !s.leader() {
 					_ = s.stream.Close()
 					done <- nil
 					return
 				}
-				s.config.Services.Log.Debugf("wrote msg requesting next sequence %d from peer %s", msg.SequenceNumber+1, s.peer.String())
+				s.logger.Debugf("wrote msg requesting next sequence %d from peer %s", msg.SequenceNumber+1, s.peer.String())
 			case IWantSequenceNumber:
-				s.config.Services.Log.Debugf("received IWantSequenceNumber %d from peer %s", msg.SequenceNumber, s.peer.String())
+				s.logger.Debugf("received IWantSequenceNumber %d from peer %s", msg.SequenceNumber, s.peer.String())
 				if err = s.ProcessWantSequenceNumber(ctx, msg); err != nil {
 					done <- err
 					return
 				}
-				s.config.Services.Log.Debugf("wrote sequence %d to peer %s", msg.SequenceNumber, s.peer.String())
+				s.logger.Debugf("wrote sequence %d to peer %s", msg.SequenceNumber, s.peer.String())
 				if msg.SequenceNumber == s.myLatestSequence {
 					err = s.stream.Close()
 					done <- err
 					return
 				}
 			case IWantLatest:
-				s.config.Services.Log.Debugf("received IWantLatest from peer %s", s.peer.String())
+				s.logger.Debugf("received IWantLatest from peer %s", s.peer.String())
 				if err = s.ProcessWantLatest(ctx); err != nil {
 					done <- err
 					return
 				}
-				s.config.Services.Log.Debugf("wrote latest sequence %d to peer %s", s.myLatestSequence, s.peer.String())
+				s.logger.Debugf("wrote latest sequence %d to peer %s", s.myLatestSequence, s.peer.String())
 			}
 		}
 	}()
@@ -150,87 +178,146 @@ func (s *StreamThread) ProcessSyncMessage(ctx context.Context) error {
 		return nil
 	case err := <-done:
 		return err
+	case <-ctx.Done(): // Timed out or cancelled (reset the stream to stop the reader)
+		_ = s.stream.Reset()
+		return ctx.Err()
 	case <-time.After(time.Minute * 1):
 		return fmt.Errorf("sync from peer %s process timed out after 1 minute", s.peer.String())
 	}
 }
 
+// resumeSequence will return the sequence the alerts are synced after: the latest alert, or the alert before the
+// first missing one (the older alerts of a fast sync not backfilled yet, the saved alerts after it are skipped)
+func (s *StreamThread) resumeSequence(ctx context.Context) (uint32, error) {
+	a, err := s.store.GetLatest(ctx)
+	if err != nil {
+		s.logger.Errorf("failed to get latest alert: %s", err.Error())
+		return 0, err
+	} else if a == nil {
+		s.logger.Error(ErrAlertNotLatest.Error())
+		return 0, ErrAlertNotLatest
+	}
+	var missing []uint32
+	if missing, err = s.store.ListMissing(ctx, 1); err != nil {
+		s.logger.Errorf("failed to get the missing alerts: %s", err.Error())
+		return 0, err
+	} else if len(missing) > 0 {
+		return missing[0] - 1, nil
+	}
+	return a.SequenceNumber, nil
+}
+
 // ProcessGotLatest will process the got latest message
 func (s *StreamThread) ProcessGotLatest(ctx context.Context, msg *SyncMessage) error {
-	a, err := models.GetLatestAlert(ctx, nil, model.WithAllDependencies(s.config))
+	sequence, err := s.resumeSequence(ctx)
 	if err != nil {
-		s.config.Services.Log.Errorf("failed to get latest alert to send to peer: %s", err.Error())
 		return err
-	} else if a == nil {
-		s.config.Services.Log.Error(ErrAlertNotLatest.Error())
-		return ErrAlertNotLatest
 	}
 
-	s.myLatestSequence = a.SequenceNumber // this is redundant, but doesn't hurt
-	if msg.SequenceNumber < a.SequenceNumber {
-		s.config.Services.Log.Debugf("peer %s is not synced yet, ignoring...", s.peer.String())
+	s.myLatestSequence = sequence // this is redundant, but doesn't hurt
+	if msg.SequenceNumber < sequence {
+		s.logger.Debugf("peer %s is not synced yet, ignoring...", s.peer.String())
 		return nil
 	}
 
 	s.latestSequence = msg.SequenceNumber
-	if msg.SequenceNumber == a.SequenceNumber {
-		s.config.Services.Log.Debugf("peer %s is synced to current state as us, closing stream.", s.peer.String())
+	if msg.SequenceNumber == sequence {
+		s.logger.Debugf("peer %s is synced to current state as us, closing stream.", s.peer.String())
 		_ = s.stream.Close()
 		return nil
 	}
-	s.config.Services.Log.Infof("peer %s has sequence %d and we have %d", s.peer.String(), msg.SequenceNumber, a.SequenceNumber)
+	s.logger.Infof("peer %s has sequence %d and we have %d", s.peer.String(), msg.SequenceNumber, sequence)
+	if !s.leader() {
+		s.logger.Debugf("standby, the cluster leader syncs up to sequence %d", msg.SequenceNumber)
+		return nil
+	}
+
+	// Sync the latest alerts first if many are missing (the older ones are backfilled after)
+	next := sequence + 1
+	if s.fastSync > 0 && msg.SequenceNumber-sequence > s.fastSync {
+		next = msg.SequenceNumber - s.fastSync + 1
+		s.snapshotFrom = next
+		s.logger.Infof("fast sync of the latest %d alerts from peer %s, %d to %d are backfilled after",
+			s.fastSync, s.peer.String(), sequence+1, next-1)
+	}
 
 	// need to get next sequence
 	res := SyncMessage{
 		Type:           IWantSequenceNumber,
-		SequenceNumber: a.SequenceNumber + 1,
+		SequenceNumber: next,
 	}
-	return wire.WriteVarBytes(s.stream, 0, res.Serialize())
+	return s.write(&res)
 }
 
 // ProcessGotSequenceNumber will process the got sequence number message
 func (s *StreamThread) ProcessGotSequenceNumber(msg *SyncMessage) error {
+	// Left to the cluster leader (the leadership was lost during the sync)
+	if !s.leader() {
+		s.logger.Infof("standby, sequence %d is left to the cluster leader", msg.SequenceNumber)
+		return nil
+	}
+
 	// Sync with a new alert
 	a, err := models.NewAlertFromBytes(msg.Data, model.WithAllDependencies(s.config), model.New())
 	if err != nil {
 		// todo probably want to ban this peer?
 		return err
 	}
+	ctx, logger := config.ContextWithField(s.ctx, s.logger, config.LogFieldAlertSequence, a.SequenceNumber)
+	s.events.Publish(ctx, &events.Event{
+		Alert: a, PeerID: s.peer.String(), Source: events.SourceSync, Type: events.AlertReceived,
+	})
+
+	// The keys are not rotated out of order (the older alerts are signed by the keys it replaces)
+	if s.snapshotFrom > 0 && a.GetAlertType() == models.AlertTypeSetKeys {
+		return s.syncInOrder(ctx, fmt.Sprintf("alert %d of the fast sync sets the keys", a.SequenceNumber))
+	}
 
 	// Verify signatures
 	var valid bool
-	if valid, err = a.AreSignaturesValid(s.ctx); err != nil {
+	alertType := metrics.AlertTypeLabel(a.GetAlertType().Name())
+	verifyStart := time.Now()
+	valid, err = a.AreSignaturesValid(ctx)
+	config.LogSlow(logger, s.config.SlowLog.Signature, verifyStart, "signature verification of alert %d", a.SequenceNumber)
+	if err != nil {
+		metrics.SignatureVerifications.WithLabelValues(alertType, metrics.ResultError).Inc()
 		return err
+	} else if !valid && s.snapshotFrom > 0 { // The keys may have been rotated by a skipped alert
+		return s.syncInOrder(ctx, fmt.Sprintf("alert %d of the fast sync is not signed by the active keys", a.SequenceNumber))
 	} else if !valid { // Not valid
-		s.config.Services.Log.Error(ErrInvalidAlerts.Error())
+		metrics.SignatureVerifications.WithLabelValues(alertType, metrics.ResultInvalid).Inc()
+		logger.Error(ErrInvalidAlerts.Error())
 		return ErrInvalidAlerts
 	}
+	metrics.SignatureVerifications.WithLabelValues(alertType, metrics.ResultOK).Inc()
+	s.events.Publish(s.ctx, &events.Event{
+		Alert: a, PeerID: s.peer.String(), Source: events.SourceSync, Type: events.AlertVerified,
+	})
 
 	// Serialize the alert data and hash
 	a.SerializeData()
 
-	// Process the alert (if it's a set keys alert)
-	// TODO: For now lets just process all alerts... why not?
-	// if a.GetAlertType() == models.AlertTypeSetKeys || a.GetAlertType() == models.AlertTypeInvalidateBlock {
-	ak := a.ProcessAlertMessage()
-	if err = ak.Read(a.GetRawMessage()); err != nil {
-		return err
-	}
-	a.Processed = true
-	if err = ak.Do(s.ctx); err != nil {
-		s.config.Services.Log.Errorf("failed to process alert %d; err: %v", a.SequenceNumber, err.Error())
-		a.Processed = false
+	// Lock the alert (another instance sharing the datastore may be enforcing it, the sync is retried later)
+	release, locked := lockAlert(ctx, s.config, logger, a.SequenceNumber)
+	if !locked {
+		return ErrAlertLocked
 	}
+	defer release()
 
-	// Save the alert
-	if err = a.Save(s.ctx); err != nil {
+	// Enforce the alert unless it was saved since the sync started (by another instance or the fast sync)
+	var saved *models.AlertMessage
+	if saved, err = s.store.GetBySequence(ctx, a.SequenceNumber); err != nil {
+		return err
+	} else if saved != nil && len(saved.Hash) > 0 {
+		logger.Infof("alert %d was already saved (by another instance or the fast sync)", a.SequenceNumber)
+	} else if err = s.enforce(ctx, logger, a); err != nil {
 		return err
 	}
 
 	// Update the latest sequence
 	s.myLatestSequence = a.SequenceNumber
 	if s.myLatestSequence == s.latestSequence {
-		s.config.Services.Log.Infof("successfully synced up to sequence %d", s.latestSequence)
+		s.logger.Infof("successfully synced up to sequence %d", s.latestSequence)
 		_ = s.stream.Close()
 		return nil
 	}
@@ -240,22 +327,68 @@ func (s *StreamThread) ProcessGotSequenceNumber(msg *SyncMessage) error {
 		Type:           IWantSequenceNumber,
 		SequenceNumber: a.SequenceNumber + 1,
 	}
-	return wire.WriteVarBytes(s.stream, 0, res.Serialize())
+	return s.write(&res)
+}
+
+// syncInOrder will fall back from the fast sync to the in-order sync of the missing alerts, so each alert is
+// verified against the keys active at its sequence (the alerts of the fast sync enforced so far are already saved,
+// they are skipped by the in-order sync and enforced again once no alert is missing)
+func (s *StreamThread) syncInOrder(ctx context.Context, reason string) error {
+	sequence, err := s.resumeSequence(ctx)
+	if err != nil {
+		return err
+	}
+	if s.myLatestSequence >= s.snapshotFrom {
+		s.reenforceFrom, s.reenforceTo = s.snapshotFrom, s.myLatestSequence
+	}
+	s.snapshotFrom = 0
+	s.myLatestSequence = sequence
+	s.logger.Infof("%s, syncing the alerts from %d in order", reason, sequence+1)
+	res := SyncMessage{
+		Type:           IWantSequenceNumber,
+		SequenceNumber: sequence + 1,
+	}
+	return s.write(&res)
+}
+
+// enforce will execute the actions of the synced alert, then save and publish it
+func (s *StreamThread) enforce(ctx context.Context, logger config.LoggerInterface, a *models.AlertMessage) error {
+	// Process the alert (if it's a set keys alert)
+	// TODO: For now lets just process all alerts... why not?
+	// if a.GetAlertType() == models.AlertTypeSetKeys || a.GetAlertType() == models.AlertTypeInvalidateBlock {
+	ak := a.ProcessAlertMessage()
+	if err := ak.Read(a.GetRawMessage()); err != nil {
+		return err
+	}
+	a.Processed = true
+	actionErr := ak.Do(ctx)
+	if actionErr != nil {
+		logger.Errorf("failed to process alert %d; err: %v", a.SequenceNumber, actionErr.Error())
+		a.Processed = false
+	}
+
+	// Save the alert (with the enforced event in the outbox)
+	queueEnforced(s.config, a, events.SourceSync, s.peer.String(), actionErr)
+	if err := s.store.SaveAlert(ctx, a); err != nil {
+		return err
+	}
+	publishEnforced(s.ctx, s.events, s.config, a, events.SourceSync, s.peer.String(), actionErr)
+	return nil
 }
 
 // ProcessWantSequenceNumber will process the want sequence number message
 func (s *StreamThread) ProcessWantSequenceNumber(ctx context.Context, msg *SyncMessage) error {
-	a, err := models.GetAlertMessageBySequenceNumber(ctx, msg.SequenceNumber, model.WithAllDependencies(s.config))
+	a, err := s.store.GetBySequence(ctx, msg.SequenceNumber)
 	if err != nil {
-		s.config.Services.Log.Errorf("failed to get latest alert to send to peer: %s", err.Error())
+		s.logger.Errorf("failed to get latest alert to send to peer: %s", err.Error())
 		return err
 	} else if a == nil {
-		s.config.Services.Log.Error(ErrAlertNotFoundBySequence.Error())
+		s.logger.Error(ErrAlertNotFoundBySequence.Error())
 		return ErrAlertNotFoundBySequence
 	}
 	var data []byte
 	if data, err = hex.DecodeString(a.Raw); err != nil {
-		s.config.Services.Log.Errorf("failed to decode raw alert data: %s", err.Error())
+		s.logger.Errorf("failed to decode raw alert data: %s", err.Error())
 		return err
 	}
 	res := SyncMessage{
@@ -263,24 +396,24 @@ func (s *StreamThread) ProcessWantSequenceNumber(ctx context.Context, msg *SyncM
 		SequenceNumber: a.SequenceNumber,
 		Data:           data,
 	}
-	return wire.WriteVarBytes(s.stream, 0, res.Serialize())
+	return s.respond(ctx, &res)
 }
 
 // ProcessWantLatest will process the want latest message
 func (s *StreamThread) ProcessWantLatest(ctx context.Context) error {
-	a, err := models.GetLatestAlert(ctx, nil, model.WithAllDependencies(s.config))
+	a, err := s.store.GetLatest(ctx)
 	if err != nil {
-		s.config.Services.Log.Errorf("failed to get latest alert to send to peer: %s", err.Error())
+		s.logger.Errorf("failed to get latest alert to send to peer: %s", err.Error())
 		return err
 	} else if a == nil {
-		s.config.Services.Log.Error(ErrAlertNotLatest.Error())
+		s.logger.Error(ErrAlertNotLatest.Error())
 		return ErrAlertNotLatest
 	}
 	s.myLatestSequence = a.SequenceNumber
 
 	var data []byte
 	if data, err = hex.DecodeString(a.Raw); err != nil {
-		s.config.Services.Log.Errorf("failed to decode raw alert data: %s", err.Error())
+		s.logger.Errorf("failed to decode raw alert data: %s", err.Error())
 		return err
 	}
 	res := SyncMessage{
@@ -288,5 +421,5 @@ func (s *StreamThread) ProcessWantLatest(ctx context.Context) error {
 		SequenceNumber: a.SequenceNumber,
 		Data:           data,
 	}
-	return wire.WriteVarBytes(s.stream, 0, res.Serialize())
+	return s.respond(ctx, &res)
 }
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/bitcoin-sv/alert-system/utils"
+	apirouter "github.com/mrz1836/go-api-router"
+)
+
+// Pagination params for list endpoints
+const (
+	ParamCursor = "cursor" // Opaque cursor returned as next_cursor by the previous page
+	ParamLimit  = "limit"  // Max number of items per page
+)
+
+// PageRequest is the page requested by the client (cursor and limit)
+type PageRequest struct {
+	Cursor    *utils.Cursor // Position after which the page starts (nil is the first page)
+	Limit     int           // Page size (defaulted and capped)
+	Requested bool          // True if the client sent a cursor or limit (list endpoints stay unpaginated otherwise)
+}
+
+// GetPageRequest will get the requested page from the request params
+func GetPageRequest(req *http.Request) (*PageRequest, error) {
+	params := apirouter.GetParams(req)
+	cursor, hasCursor := params.GetStringOk(ParamCursor)
+	limit, hasLimit := params.GetIntOk(ParamLimit)
+
+	page := &PageRequest{
+		Limit:     utils.PageSize(limit),
+		Requested: hasCursor || hasLimit,
+	}
+	var err error
+	if page.Cursor, err = utils.DecodeCursor(cursor); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// EncodeNextCursor will encode the cursor for the next page (empty if there are no more pages)
+func EncodeNextCursor(cursor *utils.Cursor) string {
+	if cursor == nil {
+		return ""
+	}
+	return cursor.Encode()
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/julienschmidt/httprouter"
+	apirouter "github.com/mrz1836/go-api-router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetPageRequest will test the method GetPageRequest()
+func TestGetPageRequest(t *testing.T) {
+	t.Parallel()
+
+	// getPage will run the request through the router (parses the params) and return the page
+	getPage := func(target string) (*PageRequest, error) {
+		var page *PageRequest
+		var err error
+		a, _ := NewStack(new(config.Config))
+		a.Request(apirouter.New(), func(_ http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+			page, err = GetPageRequest(req)
+		})(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil), nil)
+		return page, err
+	}
+
+	t.Run("no params, not requested", func(t *testing.T) {
+		page, err := getPage("/alerts")
+		require.NoError(t, err)
+		assert.False(t, page.Requested)
+		assert.Nil(t, page.Cursor)
+		assert.Equal(t, utils.DefaultPageSize, page.Limit)
+	})
+
+	t.Run("limit only", func(t *testing.T) {
+		page, err := getPage("/alerts?limit=5")
+		require.NoError(t, err)
+		assert.True(t, page.Requested)
+		assert.Nil(t, page.Cursor)
+		assert.Equal(t, 5, page.Limit)
+	})
+
+	t.Run("cursor and limit above the max", func(t *testing.T) {
+		cursor := &utils.Cursor{Sequence: 10, Timestamp: 1700000000}
+		page, err := getPage("/alerts?limit=100000&cursor=" + cursor.Encode())
+		require.NoError(t, err)
+		assert.True(t, page.Requested)
+		assert.Equal(t, cursor, page.Cursor)
+		assert.Equal(t, utils.MaxPageSize, page.Limit)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, err := getPage("/alerts?cursor=invalid!")
+		require.ErrorIs(t, err, utils.ErrInvalidCursor)
+	})
+}
+
+// TestEncodeNextCursor will test the method EncodeNextCursor()
+func TestEncodeNextCursor(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, EncodeNextCursor(nil))
+	assert.NotEmpty(t, EncodeNextCursor(&utils.Cursor{Sequence: 1}))
+}
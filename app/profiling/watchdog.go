@@ -0,0 +1,278 @@
+// Package profiling is the watchdog capturing pprof profiles of a long-running node under resource pressure
+// The CPU and memory usage of the process are sampled on an interval, once one stays above its threshold for the
+// sustained duration a CPU profile and a heap profile are written to the profile directory (the oldest are removed)
+package profiling
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/metrics"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	appmetrics "github.com/bitcoin-sv/alert-system/app/metrics"
+)
+
+// Watchdog defaults
+const (
+	DefaultCPUProfileDuration = 30 * time.Second // Default duration of the captured CPU profile
+	DefaultInterval           = 10 * time.Second // Default interval between the usage samples
+	filePrefix                = "profile-"       // File name prefix of the profiles
+)
+
+// Reasons of a capture
+const (
+	ReasonCPU    = "cpu"    // The CPU usage stayed above the threshold
+	ReasonMemory = "memory" // The memory usage stayed above the threshold
+)
+
+// Usage is a sample of the resource usage of the process
+type Usage struct {
+	CPUPercent  float64 // CPU used since the last sample, in percent of all the CPUs
+	MemoryBytes uint64  // Memory obtained from the OS by the Go runtime (and not released)
+}
+
+// WatchdogOptions are the options for the profiling watchdog
+type WatchdogOptions struct {
+	CPUPercent         float64                                          // CPU threshold in percent of all the CPUs (0 to ignore the CPU)
+	CPUProfileDuration time.Duration                                    // Duration of the CPU profile (DefaultCPUProfileDuration if 0)
+	Dir                string                                           // Directory of the profiles (created if missing)
+	Interval           time.Duration                                    // Interval between the samples (DefaultInterval if 0)
+	MaxProfiles        int                                              // Captures kept (the oldest are removed, 0 keeps all)
+	MemoryBytes        uint64                                           // Memory threshold (0 to ignore the memory)
+	OnCapture          func(reason string, usage Usage, paths []string) // Called once the profiles are written (optional)
+	OnError            func(err error)                                  // Called if a sample or a capture fails (optional)
+	Sample             func() (Usage, error)                            // Samples the usage (the process usage if nil)
+	Sustained          time.Duration                                    // Time a threshold must be exceeded before capturing
+}
+
+// Watchdog captures the profiles when the resource usage stays above the thresholds
+type Watchdog struct {
+	aboveSince time.Time // First sample of the current pressure (zero if below the thresholds)
+	done       chan struct{}
+	mu         sync.Mutex
+	opts       WatchdogOptions
+	quit       chan struct{}
+	started    bool
+}
+
+// NewWatchdog will create the watchdog, call Start() to sample the usage on the interval
+func NewWatchdog(opts WatchdogOptions) *Watchdog {
+	if opts.CPUProfileDuration <= 0 {
+		opts.CPUProfileDuration = DefaultCPUProfileDuration
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.Sample == nil {
+		opts.Sample = newProcessSampler().sample
+	}
+	return &Watchdog{
+		done: make(chan struct{}),
+		opts: opts,
+		quit: make(chan struct{}),
+	}
+}
+
+// Start will sample the usage on the interval until Stop() is called
+func (w *Watchdog) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started {
+		return
+	}
+	w.started = true
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(w.opts.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				w.Check(time.Now())
+			case <-w.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop will stop the sampling (waits for a capture in progress)
+func (w *Watchdog) Stop() {
+	w.mu.Lock()
+	started := w.started
+	w.mu.Unlock()
+	close(w.quit)
+	if started {
+		<-w.done
+	}
+}
+
+// Check will sample the usage once and capture the profiles if a threshold is exceeded for the sustained duration
+// The pressure must be sustained again before the next capture. Returns the reason of the capture (empty if none)
+func (w *Watchdog) Check(now time.Time) string {
+	usage, err := w.opts.Sample()
+	if err != nil {
+		w.failed(err)
+		return ""
+	}
+	reason := w.exceeded(usage)
+	if len(reason) == 0 {
+		w.aboveSince = time.Time{}
+		return ""
+	} else if w.aboveSince.IsZero() {
+		w.aboveSince = now
+	}
+	if now.Sub(w.aboveSince) < w.opts.Sustained {
+		return ""
+	}
+	w.aboveSince = time.Time{}
+
+	paths, err := w.Capture(time.Now())
+	if err != nil {
+		w.failed(err)
+		return ""
+	}
+	appmetrics.ProfileCaptures.WithLabelValues(reason).Inc()
+	if w.opts.OnCapture != nil {
+		w.opts.OnCapture(reason, usage, paths)
+	}
+	return reason
+}
+
+// exceeded will return the threshold exceeded by the usage (empty if none)
+func (w *Watchdog) exceeded(usage Usage) string {
+	if w.opts.CPUPercent > 0 && usage.CPUPercent >= w.opts.CPUPercent {
+		return ReasonCPU
+	} else if w.opts.MemoryBytes > 0 && usage.MemoryBytes >= w.opts.MemoryBytes {
+		return ReasonMemory
+	}
+	return ""
+}
+
+// Capture will write a CPU profile (recorded for the profile duration) and a heap profile, then remove the oldest
+// captures over the max. Returns the paths of the profiles
+func (w *Watchdog) Capture(now time.Time) ([]string, error) {
+	if err := os.MkdirAll(w.opts.Dir, 0o700); err != nil {
+		return nil, err
+	}
+	name := filePrefix + now.UTC().Format("20060102T150405.000000000Z")
+	cpuPath := filepath.Join(w.opts.Dir, name+"-cpu.pprof")
+	heapPath := filepath.Join(w.opts.Dir, name+"-heap.pprof")
+
+	// Record the CPU profile (fails if another one is recorded, e.g. from /debug/pprof/profile)
+	if err := writeProfile(cpuPath, func(f *os.File) error {
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return err
+		}
+		select {
+		case <-time.After(w.opts.CPUProfileDuration):
+		case <-w.quit:
+		}
+		pprof.StopCPUProfile()
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("cpu profile: %w", err)
+	}
+
+	// Write the heap profile (from the last garbage collection)
+	if err := writeProfile(heapPath, func(f *os.File) error {
+		return pprof.Lookup("heap").WriteTo(f, 0)
+	}); err != nil {
+		return []string{cpuPath}, fmt.Errorf("heap profile: %w", err)
+	}
+	return []string{cpuPath, heapPath}, pruneProfiles(w.opts.Dir, w.opts.MaxProfiles)
+}
+
+// failed will report the error
+func (w *Watchdog) failed(err error) {
+	if w.opts.OnError != nil {
+		w.opts.OnError(err)
+	}
+}
+
+// writeProfile will create the profile file (only readable by the owner) and write it
+func writeProfile(path string, write func(f *os.File) error) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600) //nolint:gosec // Path from the config
+	if err != nil {
+		return err
+	}
+	if err = write(f); err != nil {
+		_ = f.Close()
+		return errors.Join(err, os.Remove(path))
+	}
+	return f.Close()
+}
+
+// pruneProfiles will remove the oldest captures over the max (a capture is the CPU and heap profiles of a time)
+func pruneProfiles(dir string, max int) error {
+	if max <= 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	captures := make(map[string][]string)
+	for _, entry := range entries {
+		if name := entry.Name(); !entry.IsDir() && strings.HasPrefix(name, filePrefix) {
+			capture := name[:strings.LastIndex(name, "-")]
+			captures[capture] = append(captures[capture], name)
+		}
+	}
+	names := make([]string, 0, len(captures))
+	for capture := range captures {
+		names = append(names, capture)
+	}
+	sort.Strings(names)
+	for ; len(names) > max; names = names[1:] {
+		for _, file := range captures[names[0]] {
+			if err = os.Remove(filepath.Join(dir, file)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// processSampler samples the usage of this process
+type processSampler struct {
+	lastCPU  time.Duration
+	lastTime time.Time
+	samples  []metrics.Sample
+}
+
+// newProcessSampler will create the sampler of this process (the CPU usage is measured from the first sample)
+func newProcessSampler() *processSampler {
+	p := &processSampler{samples: []metrics.Sample{
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/released:bytes"},
+	}}
+	p.lastCPU, _ = processCPUTime()
+	p.lastTime = time.Now()
+	return p
+}
+
+// sample will return the CPU used since the last sample and the memory held by the Go runtime
+func (p *processSampler) sample() (Usage, error) {
+	cpu, err := processCPUTime()
+	if err != nil {
+		return Usage{}, err
+	}
+	now := time.Now()
+	var usage Usage
+	if elapsed := now.Sub(p.lastTime); elapsed > 0 {
+		usage.CPUPercent = float64(cpu-p.lastCPU) / float64(elapsed) / float64(runtime.NumCPU()) * 100
+	}
+	p.lastCPU, p.lastTime = cpu, now
+
+	metrics.Read(p.samples)
+	usage.MemoryBytes = p.samples[0].Value.Uint64() - p.samples[1].Value.Uint64()
+	return usage, nil
+}
@@ -0,0 +1,115 @@
+package profiling
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestWatchdog will return a watchdog sampling the usage set by the test
+func newTestWatchdog(t *testing.T, usage *Usage, opts WatchdogOptions) (*Watchdog, *[]string) {
+	var captured []string
+	opts.CPUProfileDuration = 10 * time.Millisecond
+	opts.Dir = t.TempDir()
+	opts.OnCapture = func(reason string, _ Usage, paths []string) {
+		captured = append(captured, reason)
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Positive(t, info.Size())
+		}
+	}
+	opts.Sample = func() (Usage, error) { return *usage, nil }
+	return NewWatchdog(opts), &captured
+}
+
+// TestWatchdog_Check will test the captures under sustained pressure
+func TestWatchdog_Check(t *testing.T) {
+	t.Run("captured once the pressure is sustained", func(t *testing.T) {
+		usage := &Usage{CPUPercent: 95}
+		w, captured := newTestWatchdog(t, usage, WatchdogOptions{CPUPercent: 90, Sustained: time.Minute})
+		start := time.Now()
+
+		assert.Empty(t, w.Check(start))
+		assert.Empty(t, w.Check(start.Add(30*time.Second)))
+		assert.Equal(t, ReasonCPU, w.Check(start.Add(time.Minute)))
+		assert.Empty(t, w.Check(start.Add(70*time.Second)), "sustained again before the next capture")
+		assert.Equal(t, []string{ReasonCPU}, *captured)
+
+		files, err := os.ReadDir(w.opts.Dir)
+		require.NoError(t, err)
+		assert.Len(t, files, 2, "cpu and heap profiles")
+	})
+
+	t.Run("the pressure must be continuous", func(t *testing.T) {
+		usage := &Usage{MemoryBytes: 2 << 30}
+		w, captured := newTestWatchdog(t, usage, WatchdogOptions{MemoryBytes: 1 << 30, Sustained: time.Minute})
+		start := time.Now()
+
+		assert.Empty(t, w.Check(start))
+		usage.MemoryBytes = 1 << 20
+		assert.Empty(t, w.Check(start.Add(30*time.Second)))
+		usage.MemoryBytes = 2 << 30
+		assert.Empty(t, w.Check(start.Add(time.Minute)))
+		assert.Equal(t, ReasonMemory, w.Check(start.Add(2*time.Minute)))
+		assert.Equal(t, []string{ReasonMemory}, *captured)
+	})
+
+	t.Run("ignored thresholds", func(t *testing.T) {
+		usage := &Usage{CPUPercent: 100, MemoryBytes: 8 << 30}
+		w, captured := newTestWatchdog(t, usage, WatchdogOptions{})
+		assert.Empty(t, w.Check(time.Now()))
+		assert.Empty(t, *captured)
+	})
+
+	t.Run("sample error", func(t *testing.T) {
+		var reported error
+		w := NewWatchdog(WatchdogOptions{
+			CPUPercent: 1,
+			OnError:    func(err error) { reported = err },
+			Sample:     func() (Usage, error) { return Usage{}, errors.New("no usage") },
+		})
+		assert.Empty(t, w.Check(time.Now()))
+		require.Error(t, reported)
+	})
+}
+
+// TestPruneProfiles will test the removal of the oldest captures
+func TestPruneProfiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"profile-20240101T000000.000000000Z-cpu.pprof", "profile-20240101T000000.000000000Z-heap.pprof",
+		"profile-20240102T000000.000000000Z-cpu.pprof", "profile-20240102T000000.000000000Z-heap.pprof",
+		"profile-20240103T000000.000000000Z-cpu.pprof", "notes.txt",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o600))
+	}
+	require.NoError(t, pruneProfiles(dir, 2))
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	assert.Equal(t, []string{
+		"notes.txt",
+		"profile-20240102T000000.000000000Z-cpu.pprof", "profile-20240102T000000.000000000Z-heap.pprof",
+		"profile-20240103T000000.000000000Z-cpu.pprof",
+	}, names)
+}
+
+// TestProcessSampler will test the usage of this process
+func TestProcessSampler(t *testing.T) {
+	p := newProcessSampler()
+	time.Sleep(10 * time.Millisecond)
+	usage, err := p.sample()
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, usage.CPUPercent, float64(0))
+	assert.Positive(t, usage.MemoryBytes)
+}
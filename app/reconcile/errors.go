@@ -0,0 +1,9 @@
+package reconcile
+
+import "errors"
+
+// Reconciliation errors
+var (
+	ErrNotProcessed      = errors.New("node did not process the funds")
+	ErrUnknownDifference = errors.New("unknown difference")
+)
@@ -0,0 +1,282 @@
+// Package reconcile is the reconciliation of the node state with the alerts (e.g. after restoring a node from a snapshot)
+// The state the processed alerts set on the node (banned peers, invalidated blocks and frozen funds) is compared to
+// the live node, the latest alert of each peer, block or fund wins. Differences are only reported for the subjects of
+// the alerts: a ban or a frozen fund set on the node by other means is left alone.
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	bnmodels "github.com/libsv/go-bn/models"
+)
+
+// Kinds of differences (the action applying the expected state)
+const (
+	KindBan        = "ban"        // The peer is not banned
+	KindFreeze     = "freeze"     // The funds are not frozen at the expected heights
+	KindInvalidate = "invalidate" // The block is in the active chain
+	KindUnban      = "unban"      // The peer is still banned
+)
+
+// DefaultBanTime is the node default bantime, the node lifts the bans of the alerts after it (setban without a bantime)
+const DefaultBanTime = 24 * time.Hour
+
+// Expected is the node state expected from the processed alerts
+type Expected struct {
+	Bans          map[string]uint32        // Banned subnets (with the sequence of the alert)
+	ExpiredBans   map[string]uint32        // Subnets whose ban was lifted by the node (with the sequence of the alert)
+	Funds         map[string]*ExpectedFund // Frozen funds (by txid:vout)
+	InvalidBlocks map[string]uint32        // Invalidated block hashes (with the sequence of the alert)
+	Unbans        map[string]uint32        // Subnets unbanned by the latest alert (with the sequence of the alert)
+	Unreadable    []uint32                 // Sequences of the alerts which could not be read
+}
+
+// ExpectedFund is a fund frozen (or unfrozen, at the end height) by an alert
+type ExpectedFund struct {
+	Fund     bnmodels.Fund
+	Sequence uint32
+}
+
+// Difference is a difference between the expected and the live node state
+type Difference struct {
+	Expected string         `json:"expected"` // Expected state
+	Kind     string         `json:"kind"`     // Action applying the expected state (see Kind*)
+	Live     string         `json:"live"`     // State of the node
+	Sequence uint32         `json:"sequence"` // Alert setting the expected state
+	Subject  string         `json:"subject"`  // Peer subnet, block hash or txid:vout
+	fund     *bnmodels.Fund // Fund to freeze (freeze only)
+}
+
+// String will return the difference as a line
+func (d *Difference) String() string {
+	return fmt.Sprintf("%-10s %s (alert %d): expected %s, node has %s", d.Kind, d.Subject, d.Sequence, d.Expected, d.Live)
+}
+
+// Expect will return the node state expected from the alerts (in sequence order, unprocessed alerts are skipped)
+// The ban of an alert processed more than the DefaultBanTime before now is expired (not expected on the node)
+func Expect(alerts []*models.AlertMessage, now time.Time) *Expected {
+	expected := &Expected{
+		Bans:          make(map[string]uint32),
+		ExpiredBans:   make(map[string]uint32),
+		Funds:         make(map[string]*ExpectedFund),
+		InvalidBlocks: make(map[string]uint32),
+		Unbans:        make(map[string]uint32),
+	}
+	for _, alert := range alerts {
+		if !alert.Processed {
+			continue
+		}
+		if err := alert.ReadRaw(); err != nil {
+			expected.Unreadable = append(expected.Unreadable, alert.SequenceNumber)
+			continue
+		}
+		ak := alert.ProcessAlertMessage()
+		if ak == nil {
+			continue
+		} else if err := ak.Read(alert.GetRawMessage()); err != nil {
+			expected.Unreadable = append(expected.Unreadable, alert.SequenceNumber)
+			continue
+		}
+		switch a := ak.(type) {
+		case *models.AlertMessageBanPeer:
+			subnet := normalizeSubnet(string(a.Peer))
+			delete(expected.Unbans, subnet)
+			if banExpired(alert, now) {
+				delete(expected.Bans, subnet)
+				expected.ExpiredBans[subnet] = alert.SequenceNumber
+				continue
+			}
+			delete(expected.ExpiredBans, subnet)
+			expected.Bans[subnet] = alert.SequenceNumber
+		case *models.AlertMessageUnbanPeer:
+			subnet := normalizeSubnet(string(a.Peer))
+			expected.Unbans[subnet] = alert.SequenceNumber
+			delete(expected.Bans, subnet)
+			delete(expected.ExpiredBans, subnet)
+		case *models.AlertMessageInvalidateBlock:
+			if a.BlockHash != nil {
+				expected.InvalidBlocks[a.BlockHash.String()] = alert.SequenceNumber
+			}
+		case *models.AlertMessageFreezeUtxo:
+			expected.addFunds(a.Funds, alert.SequenceNumber)
+		case *models.AlertMessageUnfreezeUtxo:
+			expected.addFunds(a.Funds, alert.SequenceNumber)
+		}
+	}
+	return expected
+}
+
+// banExpired will return true if the node lifted the ban of the alert (processed more than the ban time ago)
+// The alert record is last updated when it is processed, an alert without the time is expected to be banned
+func banExpired(alert *models.AlertMessage, now time.Time) bool {
+	return !alert.UpdatedAt.IsZero() && now.Sub(alert.UpdatedAt) >= DefaultBanTime
+}
+
+// addFunds will set the expected state of the funds (the enforcement heights replace the previous ones)
+func (e *Expected) addFunds(funds []bnmodels.Fund, sequence uint32) {
+	for _, fund := range funds {
+		e.Funds[fundKey(fund.TxOut)] = &ExpectedFund{Fund: fund, Sequence: sequence}
+	}
+}
+
+// Compare will return the differences between the expected state and the node (ordered by alert sequence)
+func Compare(ctx context.Context, node config.NodeInterface, expected *Expected) ([]*Difference, error) {
+	var differences []*Difference
+
+	// Banned peers
+	banned, err := node.ListBanned(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing the banned peers: %w", err)
+	}
+	liveBans := make(map[string]bool, len(banned))
+	for _, subnet := range banned {
+		liveBans[normalizeSubnet(subnet.Address)] = true
+	}
+	for subnet, sequence := range expected.Bans {
+		if !liveBans[subnet] {
+			differences = append(differences, &Difference{
+				Expected: "banned", Kind: KindBan, Live: "not banned", Sequence: sequence, Subject: subnet,
+			})
+		}
+	}
+	for subnet, sequence := range expected.Unbans {
+		if liveBans[subnet] {
+			differences = append(differences, &Difference{
+				Expected: "not banned", Kind: KindUnban, Live: "banned", Sequence: sequence, Subject: subnet,
+			})
+		}
+	}
+
+	// Invalidated blocks (an unknown block cannot be in the active chain)
+	for hash, sequence := range expected.InvalidBlocks {
+		var active bool
+		if active, err = node.InActiveChain(ctx, hash); err != nil {
+			return nil, fmt.Errorf("getting the block %s: %w", hash, err)
+		} else if active {
+			differences = append(differences, &Difference{
+				Expected: "invalid", Kind: KindInvalidate, Live: "in the active chain", Sequence: sequence, Subject: hash,
+			})
+		}
+	}
+
+	// Frozen funds (a fund whose enforcement ended may have been dropped by the node)
+	if len(expected.Funds) > 0 {
+		var funds []bnmodels.Fund
+		if funds, err = node.QueryBlacklistedFunds(ctx); err != nil {
+			return nil, fmt.Errorf("querying the frozen funds: %w", err)
+		}
+		var height uint32
+		if height, err = node.BlockCount(ctx); err != nil {
+			return nil, fmt.Errorf("getting the block count: %w", err)
+		}
+		liveFunds := make(map[string]bnmodels.Fund, len(funds))
+		for _, fund := range funds {
+			liveFunds[fundKey(fund.TxOut)] = fund
+		}
+		for key, fund := range expected.Funds {
+			live, ok := liveFunds[key]
+			if !ok && enforcementEnded(fund.Fund.EnforceAtHeight, height) {
+				continue
+			} else if ok && sameEnforcement(live, fund.Fund) {
+				continue
+			}
+			liveState := "not frozen"
+			if ok {
+				liveState = enforcementString(live.EnforceAtHeight)
+			}
+			differences = append(differences, &Difference{
+				Expected: enforcementString(fund.Fund.EnforceAtHeight), Kind: KindFreeze, Live: liveState,
+				Sequence: fund.Sequence, Subject: key, fund: &fund.Fund,
+			})
+		}
+	}
+
+	sort.Slice(differences, func(i, j int) bool {
+		if differences[i].Sequence != differences[j].Sequence {
+			return differences[i].Sequence < differences[j].Sequence
+		}
+		return differences[i].Subject < differences[j].Subject
+	})
+	return differences, nil
+}
+
+// Apply will apply the expected state of the difference to the node
+// A ban is set again for the node default bantime (from now, not from the alert)
+func Apply(ctx context.Context, node config.NodeInterface, difference *Difference) error {
+	switch difference.Kind {
+	case KindBan:
+		return node.BanPeer(ctx, difference.Subject)
+	case KindUnban:
+		return node.UnbanPeer(ctx, difference.Subject)
+	case KindInvalidate:
+		return node.InvalidateBlock(ctx, difference.Subject)
+	case KindFreeze:
+		if difference.fund == nil {
+			return fmt.Errorf("%w: %s", ErrUnknownDifference, difference.Subject)
+		}
+		res, err := node.AddToConsensusBlacklist(ctx, []bnmodels.Fund{*difference.fund})
+		if err != nil {
+			return err
+		} else if res != nil && len(res.NotProcessed) > 0 {
+			return fmt.Errorf("%w: %s", ErrNotProcessed, res.NotProcessed[0].Reason)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownDifference, difference.Kind)
+}
+
+// normalizeSubnet will return the peer as the subnet listed by the node (an IP is a /32 or /128 subnet)
+func normalizeSubnet(peer string) string {
+	peer = strings.TrimSpace(peer)
+	if prefix, err := netip.ParsePrefix(peer); err == nil {
+		return prefix.Masked().String()
+	} else if addr, err := netip.ParseAddr(peer); err == nil {
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()).String()
+	}
+	return peer
+}
+
+// fundKey will return the key of the fund (txid:vout)
+func fundKey(out bnmodels.TxOut) string {
+	return fmt.Sprintf("%s:%d", out.TxId, out.Vout)
+}
+
+// sameEnforcement will return true if the funds are frozen at the same heights
+func sameEnforcement(a, b bnmodels.Fund) bool {
+	if a.PolicyExpiresWithConsensus != b.PolicyExpiresWithConsensus || len(a.EnforceAtHeight) != len(b.EnforceAtHeight) {
+		return false
+	}
+	for i := range a.EnforceAtHeight {
+		if a.EnforceAtHeight[i] != b.EnforceAtHeight[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// enforcementEnded will return true if all the enforcement heights ended at the height
+func enforcementEnded(enforce []bnmodels.Enforce, height uint32) bool {
+	for _, e := range enforce {
+		if e.Stop > int(height) {
+			return false
+		}
+	}
+	return true
+}
+
+// enforcementString will return the enforcement heights (e.g. frozen at 100-200)
+func enforcementString(enforce []bnmodels.Enforce) string {
+	heights := make([]string, 0, len(enforce))
+	for _, e := range enforce {
+		heights = append(heights, fmt.Sprintf("%d-%d", e.Start, e.Stop))
+	}
+	return "frozen at " + strings.Join(heights, ",")
+}
@@ -0,0 +1,236 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/config/mocks"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	bnmodels "github.com/libsv/go-bn/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testBlockHash is the hash of the invalidated block in the tests
+const testBlockHash = "00000000000000000a1b2c3d4e5f60718293a4b5c6d7e8f90001020304050607"
+
+// testTxID is the transaction of the frozen fund in the tests
+const testTxID = "0102030405060708091011121314151617181920212223242526272829303132"
+
+// newTestAlert will return a processed alert as loaded from the datastore (only the raw alert is set)
+func newTestAlert(t *testing.T, sequence uint32, alertType models.AlertType, message []byte) *models.AlertMessage {
+	conf := &config.Config{}
+	conf.Services.Log = &config.ExtendedLogger{Logger: log.New(io.Discard, "", 0)}
+	alert := models.NewAlertMessage(model.WithAllDependencies(conf))
+	alert.SequenceNumber = sequence
+	alert.SetAlertType(alertType)
+	alert.SetRawMessage(message)
+	alert.SetSignatures([][]byte{make([]byte, 65), make([]byte, 65), make([]byte, 65)})
+	_ = alert.Serialize()
+
+	loaded := models.NewAlertMessage(model.WithAllDependencies(conf))
+	loaded.Raw = alert.Raw
+	loaded.SequenceNumber = sequence
+	loaded.Processed = true
+	return loaded
+}
+
+// peerMessage will return the message of a ban or unban peer alert
+func peerMessage(peer string) []byte {
+	msg := append([]byte{byte(len(peer))}, peer...)
+	return append(msg, 0) // No reason
+}
+
+// blockMessage will return the message of an invalidate block alert
+func blockMessage(t *testing.T, hash string) []byte {
+	raw, err := hex.DecodeString(hash)
+	require.NoError(t, err)
+	for i, j := 0, len(raw)-1; i < j; i, j = i+1, j-1 { // Hashes are displayed reversed
+		raw[i], raw[j] = raw[j], raw[i]
+	}
+	return append(raw, 0) // No reason
+}
+
+// fundMessage will return the message of a freeze or unfreeze alert
+func fundMessage(t *testing.T, txID string, vout, start, end uint64) []byte {
+	raw, err := hex.DecodeString(txID)
+	require.NoError(t, err)
+	msg := bytes.Clone(raw)
+	msg = binary.LittleEndian.AppendUint64(msg, vout)
+	msg = binary.LittleEndian.AppendUint64(msg, start)
+	msg = binary.LittleEndian.AppendUint64(msg, end)
+	return append(msg, 0)
+}
+
+// TestExpect will test the method Expect()
+func TestExpect(t *testing.T) {
+	t.Parallel()
+
+	unprocessed := newTestAlert(t, 6, models.AlertTypeBanPeer, peerMessage("10.0.0.9"))
+	unprocessed.Processed = false
+	unreadable := newTestAlert(t, 7, models.AlertTypeBanPeer, peerMessage("10.0.0.8"))
+	unreadable.Raw = "00"
+
+	expected := Expect([]*models.AlertMessage{
+		newTestAlert(t, 1, models.AlertTypeBanPeer, peerMessage("10.0.0.1")),
+		newTestAlert(t, 2, models.AlertTypeBanPeer, peerMessage("10.0.0.2")),
+		newTestAlert(t, 3, models.AlertTypeUnbanPeer, peerMessage("10.0.0.2/32")),
+		newTestAlert(t, 4, models.AlertTypeInvalidateBlock, blockMessage(t, testBlockHash)),
+		newTestAlert(t, 5, models.AlertTypeFreezeUtxo, fundMessage(t, testTxID, 1, 100, 200)),
+		unprocessed,
+		unreadable,
+		newTestAlert(t, 8, models.AlertTypeUnfreezeUtxo, fundMessage(t, testTxID, 1, 100, 150)),
+	}, time.Now())
+
+	assert.Equal(t, map[string]uint32{"10.0.0.1/32": 1}, expected.Bans)
+	assert.Empty(t, expected.ExpiredBans)
+	assert.Equal(t, map[string]uint32{"10.0.0.2/32": 3}, expected.Unbans)
+	assert.Equal(t, map[string]uint32{testBlockHash: 4}, expected.InvalidBlocks)
+	require.Len(t, expected.Funds, 1)
+	fund := expected.Funds[testTxID+":1"]
+	require.NotNil(t, fund)
+	assert.Equal(t, uint32(8), fund.Sequence)
+	assert.Equal(t, []bnmodels.Enforce{{Start: 100, Stop: 150}}, fund.Fund.EnforceAtHeight)
+	assert.Equal(t, []uint32{7}, expected.Unreadable)
+}
+
+// TestExpect_BanTime will test that the bans lifted by the node after the ban time are not expected
+func TestExpect_BanTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	expired := newTestAlert(t, 1, models.AlertTypeBanPeer, peerMessage("10.0.0.1"))
+	expired.UpdatedAt = now.Add(-DefaultBanTime)
+	active := newTestAlert(t, 2, models.AlertTypeBanPeer, peerMessage("10.0.0.2"))
+	active.UpdatedAt = now.Add(-DefaultBanTime + time.Hour)
+	rebanned := newTestAlert(t, 3, models.AlertTypeBanPeer, peerMessage("10.0.0.3"))
+	rebanned.UpdatedAt = now.Add(-2 * DefaultBanTime)
+	reban := newTestAlert(t, 4, models.AlertTypeBanPeer, peerMessage("10.0.0.3"))
+	reban.UpdatedAt = now.Add(-time.Hour)
+
+	expected := Expect([]*models.AlertMessage{expired, active, rebanned, reban}, now)
+	assert.Equal(t, map[string]uint32{"10.0.0.2/32": 2, "10.0.0.3/32": 4}, expected.Bans)
+	assert.Equal(t, map[string]uint32{"10.0.0.1/32": 1}, expected.ExpiredBans)
+
+	// The expired ban is not a difference
+	differences, err := Compare(context.Background(), &mocks.Node{}, expected)
+	require.NoError(t, err)
+	require.Len(t, differences, 2)
+	assert.Equal(t, "10.0.0.2/32", differences[0].Subject)
+	assert.Equal(t, "10.0.0.3/32", differences[1].Subject)
+}
+
+// TestCompare will test the method Compare()
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	expected := Expect([]*models.AlertMessage{
+		newTestAlert(t, 1, models.AlertTypeBanPeer, peerMessage("10.0.0.1")),
+		newTestAlert(t, 2, models.AlertTypeBanPeer, peerMessage("10.0.0.2")),
+		newTestAlert(t, 3, models.AlertTypeUnbanPeer, peerMessage("10.0.0.3")),
+		newTestAlert(t, 4, models.AlertTypeInvalidateBlock, blockMessage(t, testBlockHash)),
+		newTestAlert(t, 5, models.AlertTypeFreezeUtxo, fundMessage(t, testTxID, 1, 100, 200)),
+		newTestAlert(t, 6, models.AlertTypeFreezeUtxo, fundMessage(t, testTxID, 2, 10, 20)),
+	}, time.Now())
+
+	t.Run("in sync", func(t *testing.T) {
+		node := &mocks.Node{
+			BlockCountFunc:    func(context.Context) (uint32, error) { return 150, nil },
+			InActiveChainFunc: func(context.Context, string) (bool, error) { return false, nil },
+			ListBannedFunc: func(context.Context) ([]*bnmodels.BannedSubnet, error) {
+				return []*bnmodels.BannedSubnet{{Address: "10.0.0.1/32"}, {Address: "10.0.0.2/32"}, {Address: "10.0.0.4/32"}}, nil
+			},
+			QueryBlacklistedFundsFunc: func(context.Context) ([]bnmodels.Fund, error) {
+				return []bnmodels.Fund{{
+					TxOut:           bnmodels.TxOut{TxId: testTxID, Vout: 1},
+					EnforceAtHeight: []bnmodels.Enforce{{Start: 100, Stop: 200}},
+				}}, nil // The enforcement of the second fund ended
+			},
+		}
+		differences, err := Compare(context.Background(), node, expected)
+		require.NoError(t, err)
+		assert.Empty(t, differences)
+	})
+
+	t.Run("restored from a snapshot", func(t *testing.T) {
+		node := &mocks.Node{
+			BlockCountFunc: func(context.Context) (uint32, error) { return 15, nil },
+			ListBannedFunc: func(context.Context) ([]*bnmodels.BannedSubnet, error) {
+				return []*bnmodels.BannedSubnet{{Address: "10.0.0.1/32"}, {Address: "10.0.0.3/32"}}, nil
+			},
+		}
+		differences, err := Compare(context.Background(), node, expected)
+		require.NoError(t, err)
+		require.Len(t, differences, 5)
+		assert.Equal(t, KindBan, differences[0].Kind)
+		assert.Equal(t, "10.0.0.2/32", differences[0].Subject)
+		assert.Equal(t, KindUnban, differences[1].Kind)
+		assert.Equal(t, "10.0.0.3/32", differences[1].Subject)
+		assert.Equal(t, KindInvalidate, differences[2].Kind)
+		assert.Equal(t, testBlockHash, differences[2].Subject)
+		assert.Equal(t, KindFreeze, differences[3].Kind)
+		assert.Equal(t, testTxID+":1", differences[3].Subject)
+		assert.Equal(t, "frozen at 100-200", differences[3].Expected)
+		assert.Equal(t, "not frozen", differences[3].Live)
+		assert.Equal(t, uint32(6), differences[4].Sequence)
+	})
+}
+
+// TestApply will test the method Apply()
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	node := &mocks.Node{
+		AddToConsensusBlacklistFunc: func(_ context.Context, funds []bnmodels.Fund) (*bnmodels.AddToConsensusBlacklistResponse, error) {
+			calls = append(calls, "freeze "+fundKey(funds[0].TxOut))
+			return &bnmodels.AddToConsensusBlacklistResponse{}, nil
+		},
+		BanPeerFunc: func(_ context.Context, peer string) error {
+			calls = append(calls, "ban "+peer)
+			return nil
+		},
+		InvalidateBlockFunc: func(_ context.Context, hash string) error {
+			calls = append(calls, "invalidate "+hash)
+			return nil
+		},
+		UnbanPeerFunc: func(_ context.Context, peer string) error {
+			calls = append(calls, "unban "+peer)
+			return nil
+		},
+	}
+	fund := &bnmodels.Fund{TxOut: bnmodels.TxOut{TxId: testTxID, Vout: 1}}
+	for _, difference := range []*Difference{
+		{Kind: KindBan, Subject: "10.0.0.1/32"},
+		{Kind: KindUnban, Subject: "10.0.0.2/32"},
+		{Kind: KindInvalidate, Subject: testBlockHash},
+		{Kind: KindFreeze, Subject: testTxID + ":1", fund: fund},
+	} {
+		require.NoError(t, Apply(context.Background(), node, difference))
+	}
+	assert.Equal(t, []string{
+		"ban 10.0.0.1/32", "unban 10.0.0.2/32", "invalidate " + testBlockHash, "freeze " + testTxID + ":1",
+	}, calls)
+
+	require.ErrorIs(t, Apply(context.Background(), node, &Difference{Kind: KindFreeze}), ErrUnknownDifference)
+	require.ErrorIs(t, Apply(context.Background(), node, &Difference{Kind: "other"}), ErrUnknownDifference)
+}
+
+// TestNormalizeSubnet will test the method normalizeSubnet()
+func TestNormalizeSubnet(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "10.0.0.1/32", normalizeSubnet("10.0.0.1"))
+	assert.Equal(t, "10.0.0.0/24", normalizeSubnet(" 10.0.0.1/24 "))
+	assert.Equal(t, "2001:db8::1/128", normalizeSubnet("2001:db8::1"))
+	assert.Equal(t, "10.0.0.1/32", normalizeSubnet("::ffff:10.0.0.1"))
+	assert.Equal(t, "not-an-ip", normalizeSubnet("not-an-ip"))
+}
@@ -0,0 +1,6 @@
+package reporting
+
+import "errors"
+
+// ErrInvalidDSN is when the Sentry DSN is not valid (scheme://public_key@host/project_id)
+var ErrInvalidDSN = errors.New("invalid error reporting dsn, expected scheme://public_key@host/project_id")
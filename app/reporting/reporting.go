@@ -0,0 +1,69 @@
+// Package reporting reports panics and error logs to an error tracker (Sentry)
+// so crashes in unattended alert nodes surface immediately
+package reporting
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// Event levels
+const (
+	LevelError = "error" // Error logs
+	LevelFatal = "fatal" // Panics and fatal logs
+)
+
+// DefaultFlushTimeout is the max time to wait for the pending events before crashing
+const DefaultFlushTimeout = 2 * time.Second
+
+// Event is an error (or panic) reported to the error tracker
+type Event struct {
+	Level     string            // Event level (error or fatal)
+	Message   string            // Error message
+	Stack     string            // Stack trace (if any)
+	Tags      map[string]string // Context (module, peer_id, alert_sequence, request_id, etc.)
+	Timestamp time.Time         // When the error occurred
+}
+
+// Reporter is the interface for an error tracker
+type Reporter interface {
+	Capture(event *Event)
+	Flush(ctx context.Context) error
+}
+
+// Recover will report a panic (with the tags) and then continue panicking
+// Use it as the first defer of a goroutine: defer reporting.Recover(reporter, tags)
+func Recover(reporter Reporter, tags map[string]string) {
+	if reporter == nil {
+		return
+	}
+	if r := recover(); r != nil {
+		reporter.Capture(&Event{
+			Level:     LevelFatal,
+			Message:   fmt.Sprintf("panic: %v", r),
+			Stack:     string(debug.Stack()),
+			Tags:      copyTags(tags),
+			Timestamp: time.Now().UTC(),
+		})
+		Flush(reporter)
+		panic(r)
+	}
+}
+
+// Flush will wait (up to the DefaultFlushTimeout) for the pending events to be sent
+func Flush(reporter Reporter) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultFlushTimeout)
+	defer cancel()
+	_ = reporter.Flush(ctx)
+}
+
+// copyTags will copy the tags (so later changes are not reported)
+func copyTags(tags map[string]string) map[string]string {
+	c := make(map[string]string, len(tags))
+	for k, v := range tags {
+		c[k] = v
+	}
+	return c
+}
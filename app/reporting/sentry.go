@@ -0,0 +1,77 @@
+package reporting
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// Sentry defaults
+const (
+	DefaultQueueSize = 100            // Events queued before new events are dropped
+	sentryLogger     = "alert-system" // Reported as the event logger
+)
+
+// SentryOptions are the options for the Sentry reporter
+type SentryOptions struct {
+	DSN         string // Sentry DSN (scheme://public_key@host/project_id)
+	Environment string // Environment (local, development, production, etc.)
+	Release     string // Release (version) of the alert system
+}
+
+// Sentry reports the events with the Sentry SDK (sent in the background by its transport)
+type Sentry struct {
+	client *sentry.Client
+}
+
+// NewSentry will parse the DSN and create the Sentry client
+func NewSentry(opts SentryOptions) (*Sentry, error) {
+	if _, err := sentry.NewDsn(opts.DSN); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDSN, err)
+	}
+	transport := sentry.NewHTTPTransport()
+	transport.BufferSize = DefaultQueueSize
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:         opts.DSN,
+		Environment: opts.Environment,
+		Release:     opts.Release,
+		Transport:   transport,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDSN, err)
+	}
+	return &Sentry{client: client}, nil
+}
+
+// Capture will queue the event to be sent (dropped if the queue is full)
+func (s *Sentry) Capture(event *Event) {
+	e := sentry.NewEvent()
+	e.Level = sentry.Level(event.Level)
+	e.Logger = sentryLogger
+	e.Message = event.Message
+	e.Timestamp = event.Timestamp
+	for k, v := range event.Tags {
+		e.Tags[k] = v
+	}
+	if len(event.Stack) > 0 {
+		e.Extra["stack"] = event.Stack
+	}
+	s.client.CaptureEvent(e, nil, nil)
+}
+
+// Flush will wait for the queued events to be sent (or the context to be done)
+func (s *Sentry) Flush(ctx context.Context) error {
+	timeout := DefaultFlushTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	if !s.client.Flush(timeout) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return context.DeadlineExceeded
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package reporting
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewSentry will test parsing the DSN
+func TestNewSentry(t *testing.T) {
+	for _, dsn := range []string{
+		"",
+		"https://sentry.example.com/1",
+		"https://key@/1",
+		"https://key@sentry.example.com",
+		"https://key@sentry.example.com/",
+		"://key@sentry.example.com/1",
+	} {
+		_, err := NewSentry(SentryOptions{DSN: dsn})
+		require.ErrorIs(t, err, ErrInvalidDSN, dsn)
+	}
+
+	s, err := NewSentry(SentryOptions{DSN: "https://key@sentry.example.com/prefix/42", Environment: "test"})
+	require.NoError(t, err)
+	dsn, err := sentry.NewDsn(s.client.Options().Dsn)
+	require.NoError(t, err)
+	assert.Equal(t, "https://sentry.example.com/prefix/api/42/envelope/", dsn.GetAPIURL().String())
+	assert.Equal(t, "test", s.client.Options().Environment)
+}
+
+// sentryEvent is the event of a Sentry envelope
+type sentryEvent struct {
+	Environment string            `json:"environment"`
+	EventID     string            `json:"event_id"`
+	Extra       map[string]string `json:"extra"`
+	Level       string            `json:"level"`
+	Logger      string            `json:"logger"`
+	Message     string            `json:"message"`
+	Tags        map[string]string `json:"tags"`
+}
+
+// TestSentry will test sending the events to the envelope API
+func TestSentry(t *testing.T) {
+	received := make(chan *sentryEvent, 1)
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/1/envelope/", req.URL.Path)
+		auth = req.Header.Get("X-Sentry-Auth")
+
+		// Envelope header, item header and the event
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		lines := strings.Split(string(body), "\n")
+		assert.GreaterOrEqual(t, len(lines), 3)
+		event := new(sentryEvent)
+		assert.NoError(t, json.Unmarshal([]byte(lines[2]), event))
+		received <- event
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	s, err := NewSentry(SentryOptions{
+		DSN:         strings.Replace(srv.URL, "http://", "http://public@", 1) + "/1",
+		Environment: "test",
+	})
+	require.NoError(t, err)
+
+	s.Capture(&Event{
+		Level:     LevelFatal,
+		Message:   "panic: boom",
+		Stack:     "goroutine 1 [running]:",
+		Tags:      map[string]string{"alert_sequence": "7"},
+		Timestamp: time.Now(),
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, s.Flush(ctx))
+
+	event := <-received
+	assert.Equal(t, LevelFatal, event.Level)
+	assert.Equal(t, "panic: boom", event.Message)
+	assert.Equal(t, "alert-system", event.Logger)
+	assert.Equal(t, "test", event.Environment)
+	assert.Equal(t, "7", event.Tags["alert_sequence"])
+	assert.Equal(t, "goroutine 1 [running]:", event.Extra["stack"])
+	assert.Len(t, event.EventID, 32)
+	assert.Contains(t, auth, "sentry_key=public")
+}
+
+// TestRecover will test reporting a panic
+func TestRecover(t *testing.T) {
+	t.Run("panic is reported and continues", func(t *testing.T) {
+		reporter := &recordReporter{}
+		tags := map[string]string{"peer_id": "peer"}
+		assert.PanicsWithValue(t, "boom", func() {
+			defer Recover(reporter, tags)
+			tags["alert_sequence"] = "7"
+			panic("boom")
+		})
+		require.Len(t, reporter.events, 1)
+		assert.Equal(t, LevelFatal, reporter.events[0].Level)
+		assert.Equal(t, "panic: boom", reporter.events[0].Message)
+		assert.Equal(t, "7", reporter.events[0].Tags["alert_sequence"])
+		assert.Contains(t, reporter.events[0].Stack, "TestRecover")
+		assert.True(t, reporter.flushed)
+	})
+
+	t.Run("no panic", func(t *testing.T) {
+		reporter := &recordReporter{}
+		assert.NotPanics(t, func() {
+			defer Recover(reporter, nil)
+		})
+		assert.Empty(t, reporter.events)
+	})
+
+	t.Run("no reporter", func(t *testing.T) {
+		assert.Panics(t, func() {
+			defer Recover(nil, nil)
+			panic("boom")
+		})
+	})
+}
+
+// recordReporter records the captured events
+type recordReporter struct {
+	events  []*Event
+	flushed bool
+}
+
+// Capture will record the event
+func (r *recordReporter) Capture(event *Event) {
+	r.events = append(r.events, event)
+}
+
+// Flush will record the flush
+func (r *recordReporter) Flush(_ context.Context) error {
+	r.flushed = true
+	return nil
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/gofrs/uuid"
+)
+
+// HeaderRequestID is the header used to propagate the request (correlation) ID
+const HeaderRequestID = "X-Request-ID"
+
+// Principals recorded in the access log
+const (
+	principalAdmin     = "admin"        // Authenticated with the admin token
+	principalAnonymous = "anonymous"    // Not authenticated
+	principalSocket    = "admin_socket" // Received on the admin socket (trusted, see WithAdminSocket)
+)
+
+// maxRequestIDLength is the max length of an inbound request ID (longer IDs are replaced)
+const maxRequestIDLength = 128
+
+// requestContextKey is the key for values stored on the request context
+type requestContextKey string
+
+// requestInfoKey is the context key for the request info
+const requestInfoKey requestContextKey = "request_info"
+
+// adminSocketKey is the context key marking the requests received on the admin socket
+const adminSocketKey requestContextKey = "admin_socket"
+
+// requestInfo is the per-request information shared between the middleware and handlers
+type requestInfo struct {
+	id        string
+	principal string
+}
+
+// GetRequestID will return the request (correlation) ID from the context (if set)
+func GetRequestID(ctx context.Context) string {
+	if info, ok := ctx.Value(requestInfoKey).(*requestInfo); ok {
+		return info.id
+	}
+	return ""
+}
+
+// WithAdminSocket will mark the requests of the handler as received on the admin socket
+// The socket is only reachable by the users allowed by its file permissions, so its requests are trusted as admin
+// requests (no admin token) and are not checked against the allowlists
+func WithAdminSocket(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		h.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), adminSocketKey, true)))
+	})
+}
+
+// FromAdminSocket will return true if the request was received on the admin socket
+func FromAdminSocket(req *http.Request) bool {
+	trusted, _ := req.Context().Value(adminSocketKey).(bool)
+	return trusted
+}
+
+// setPrincipal will set the authenticated principal on the request info (if set)
+func setPrincipal(req *http.Request, principal string) {
+	if info, ok := req.Context().Value(requestInfoKey).(*requestInfo); ok {
+		info.principal = principal
+	}
+}
+
+// withRequestInfo will use the inbound request ID (or generate one) and store it on the request
+func withRequestInfo(w http.ResponseWriter, req *http.Request) (*http.Request, *requestInfo) {
+	info := &requestInfo{id: req.Header.Get(HeaderRequestID)}
+	if !isValidRequestID(info.id) {
+		guid, _ := uuid.NewV4()
+		info.id = guid.String()
+	}
+	w.Header().Set(HeaderRequestID, info.id)
+	return req.WithContext(context.WithValue(req.Context(), requestInfoKey, info)), info
+}
+
+// isValidRequestID will return true if the ID is safe to propagate (printable and a sane length)
+func isValidRequestID(id string) bool {
+	if len(id) == 0 || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// statusRecorder captures the response status for the access log and the metrics
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader will record the status and write the header
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write will record the default status (if not set) and write the body
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Logger will return the logger for the request (includes the request ID in all logs)
+// The Request middleware also carries it on the request context (see config.LoggerFromContext)
+func (a *Action) Logger(req *http.Request) config.LoggerInterface {
+	if logger := config.LoggerFromContext(req.Context(), nil); logger != nil {
+		return logger
+	}
+	id := GetRequestID(req.Context())
+	if len(id) == 0 {
+		return a.Config.Services.Log
+	}
+	return config.WithField(
+		config.WithField(a.Config.Services.Log, config.LogFieldModule, "api"), config.LogFieldRequestID, id,
+	)
+}
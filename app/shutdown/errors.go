@@ -0,0 +1,9 @@
+package shutdown
+
+import "errors"
+
+// Errors for the shutdown package
+var (
+	ErrStagePanicked = errors.New("stage panicked")
+	ErrStageTimeout  = errors.New("stage timed out")
+)
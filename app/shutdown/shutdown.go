@@ -0,0 +1,89 @@
+// Package shutdown is the ordered, graceful shutdown of the alert system
+// The stages run one after the other, each with its own deadline, so a slow subsystem cannot eat the time of the
+// next one and the datastore is only closed once nothing uses it: stop intake -> finish the in-flight alerts ->
+// flush the notifications -> close the web server -> close P2P -> close the datastore.
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+)
+
+// Stage is a step of the shutdown
+type Stage struct {
+	Name    string                          // Stage name (logged)
+	Stop    func(ctx context.Context) error // Stops the subsystem (the context is done at the deadline)
+	Timeout time.Duration                   // Deadline of the stage (no deadline if zero)
+}
+
+// Manager runs the shutdown stages in order
+type Manager struct {
+	logger config.LoggerInterface
+	stages []Stage
+}
+
+// New will return a shutdown manager without stages
+func New(logger config.LoggerInterface) *Manager {
+	return &Manager{logger: logger}
+}
+
+// Add will add a stage (run after the stages added before)
+func (m *Manager) Add(name string, timeout time.Duration, stop func(ctx context.Context) error) {
+	m.stages = append(m.stages, Stage{Name: name, Stop: stop, Timeout: timeout})
+}
+
+// Shutdown will run the stages in order and return the errors of the stages that failed or timed out
+// A stage that misses its deadline is abandoned (not waited for) and the next stage is started
+func (m *Manager) Shutdown(ctx context.Context) error {
+	start := time.Now()
+	var errs []error
+	for _, stage := range m.stages {
+		if err := m.run(ctx, stage); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", stage.Name, err))
+		}
+	}
+	m.logger.Infof("shutdown completed in %s (%d of %d stages failed)", time.Since(start).Round(time.Millisecond), len(errs), len(m.stages))
+	return errors.Join(errs...)
+}
+
+// run will run the stage until it returns or its deadline is reached
+func (m *Manager) run(ctx context.Context, stage Stage) error {
+	m.logger.Infof("shutdown: %s", stage.Name)
+	start := time.Now()
+	stageCtx, cancel := ctx, context.CancelFunc(func() {})
+	if stage.Timeout > 0 {
+		stageCtx, cancel = context.WithTimeout(ctx, stage.Timeout)
+	}
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("%w: %v", ErrStagePanicked, r)
+			}
+		}()
+		done <- stage.Stop(stageCtx)
+	}()
+
+	var err error
+	select {
+	case err = <-done: // A stage returning the context error also missed its deadline
+		if ctxErr := stageCtx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+			err = fmt.Errorf("%w after %s", ErrStageTimeout, stage.Timeout)
+		}
+	case <-stageCtx.Done():
+		err = fmt.Errorf("%w after %s", ErrStageTimeout, stage.Timeout)
+	}
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		m.logger.Errorf("shutdown: %s failed after %s: %s", stage.Name, elapsed, err.Error())
+		return err
+	}
+	m.logger.Infof("shutdown: %s done in %s", stage.Name, elapsed)
+	return nil
+}
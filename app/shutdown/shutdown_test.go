@@ -0,0 +1,103 @@
+package shutdown
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logBuffer is a log output kept in memory
+type logBuffer struct {
+	bytes.Buffer
+	mu sync.Mutex
+}
+
+// Write will write the log line
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.Buffer.Write(p)
+}
+
+// Close will do nothing
+func (b *logBuffer) Close() error {
+	return nil
+}
+
+// String will return the logs
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.Buffer.String()
+}
+
+// newManager will return a manager logging to the buffer
+func newManager() (*Manager, *logBuffer) {
+	logs := &logBuffer{}
+	return New(config.NewExtendedLogger(logs, config.LogLevelInfo, nil)), logs
+}
+
+// TestManager_Shutdown will test the stages run in order with their deadlines
+func TestManager_Shutdown(t *testing.T) {
+	t.Run("stages run in order", func(t *testing.T) {
+		m, logs := newManager()
+		var order []string
+		for _, name := range []string{"intake", "alerts", "datastore"} {
+			name := name
+			m.Add(name, time.Second, func(context.Context) error {
+				order = append(order, name)
+				return nil
+			})
+		}
+		require.NoError(t, m.Shutdown(context.Background()))
+		assert.Equal(t, []string{"intake", "alerts", "datastore"}, order)
+		assert.Contains(t, logs.String(), "shutdown: alerts done in")
+		assert.Contains(t, logs.String(), "(0 of 3 stages failed)")
+	})
+
+	t.Run("a failed or slow stage does not stop the next stages", func(t *testing.T) {
+		m, logs := newManager()
+		errFlush := errors.New("flush failed")
+		m.Add("notifications", time.Second, func(context.Context) error {
+			return errFlush
+		})
+		m.Add("alerts", 20*time.Millisecond, func(context.Context) error {
+			time.Sleep(time.Second) // Ignores the deadline
+			return nil
+		})
+		m.Add("webserver", time.Second, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		closed := false
+		m.Add("datastore", 0, func(context.Context) error {
+			closed = true
+			return nil
+		})
+
+		start := time.Now()
+		err := m.Shutdown(context.Background())
+		require.ErrorIs(t, err, errFlush)
+		require.ErrorIs(t, err, ErrStageTimeout)
+		assert.Contains(t, err.Error(), "alerts: stage timed out after 20ms")
+		assert.Contains(t, err.Error(), "webserver: stage timed out after 1s")
+		assert.True(t, closed)
+		assert.Less(t, time.Since(start), 1900*time.Millisecond, "the slow stage is not waited for")
+		assert.Contains(t, logs.String(), "(3 of 4 stages failed)")
+	})
+
+	t.Run("a panicking stage", func(t *testing.T) {
+		m, _ := newManager()
+		m.Add("p2p", time.Second, func(context.Context) error {
+			panic("closed twice")
+		})
+		require.ErrorIs(t, m.Shutdown(context.Background()), ErrStagePanicked)
+	})
+}
@@ -0,0 +1,84 @@
+// Package sigcache is the bounded LRU cache of the alert signature verification results
+// A re-gossiped duplicate (or a re-synced alert) with the same data, signatures and active keys reuses the result
+// instead of verifying the secp256k1 signatures again
+package sigcache
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/bitcoin-sv/alert-system/app/metrics"
+)
+
+// entry is a cached verification result
+type entry struct {
+	key   string
+	valid bool
+}
+
+// Cache is the LRU cache of the verification results (a nil cache is disabled)
+type Cache struct {
+	entries map[string]*list.Element
+	mu      sync.Mutex
+	order   *list.List // Most recently used first
+	size    int
+}
+
+// New will create a cache of the last size results (nil, disabled, if the size is not positive)
+func New(size int) *Cache {
+	if size <= 0 {
+		return nil
+	}
+	return &Cache{
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+		size:    size,
+	}
+}
+
+// Get will return the cached result of the key, false if it is not cached
+func (c *Cache) Get(key string) (valid, ok bool) {
+	if c == nil {
+		return false, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, found := c.entries[key]
+	if !found {
+		metrics.SignatureCacheLookups.WithLabelValues(metrics.ResultMiss).Inc()
+		return false, false
+	}
+	metrics.SignatureCacheLookups.WithLabelValues(metrics.ResultHit).Inc()
+	c.order.MoveToFront(element)
+	return element.Value.(*entry).valid, true
+}
+
+// Add will cache the result of the key (the least recently used result is evicted once the cache is full)
+func (c *Cache) Add(key string, valid bool) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, found := c.entries[key]; found {
+		element.Value.(*entry).valid = valid
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&entry{key: key, valid: valid})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).key)
+	}
+}
+
+// Len will return the number of cached results
+func (c *Cache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
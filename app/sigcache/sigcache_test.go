@@ -0,0 +1,70 @@
+package sigcache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCache will test the LRU cache of the verification results
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("disabled", func(t *testing.T) {
+		c := New(0)
+		assert.Nil(t, c)
+		c.Add("a", true)
+		_, ok := c.Get("a")
+		assert.False(t, ok)
+		assert.Equal(t, 0, c.Len())
+	})
+
+	t.Run("cached results", func(t *testing.T) {
+		c := New(2)
+		c.Add("valid", true)
+		c.Add("invalid", false)
+		valid, ok := c.Get("valid")
+		assert.True(t, ok)
+		assert.True(t, valid)
+		valid, ok = c.Get("invalid")
+		assert.True(t, ok)
+		assert.False(t, valid)
+		_, ok = c.Get("missing")
+		assert.False(t, ok)
+	})
+
+	t.Run("least recently used evicted", func(t *testing.T) {
+		c := New(2)
+		c.Add("a", true)
+		c.Add("b", true)
+		_, _ = c.Get("a")
+		c.Add("c", true)
+		assert.Equal(t, 2, c.Len())
+		_, ok := c.Get("b")
+		assert.False(t, ok, "b was the least recently used")
+		_, ok = c.Get("a")
+		assert.True(t, ok)
+		_, ok = c.Get("c")
+		assert.True(t, ok)
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		c := New(10)
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					key := strconv.Itoa((i + j) % 20)
+					c.Add(key, true)
+					_, _ = c.Get(key)
+				}
+			}(i)
+		}
+		wg.Wait()
+		assert.Equal(t, 10, c.Len())
+	})
+}
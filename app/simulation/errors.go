@@ -0,0 +1,9 @@
+package simulation
+
+import "errors"
+
+// Errors for the simulation package
+var (
+	ErrNoAlerts      = errors.New("no alerts to replay after the latest saved alert")
+	ErrNodeNotMocked = errors.New("the simulation only runs against the mock node, enable node_mock.enabled")
+)
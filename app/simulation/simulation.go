@@ -0,0 +1,243 @@
+// Package simulation replays a recorded alert history (the JSON lines written by the export command) through the
+// alert pipeline of a standalone node: each alert is verified, enforced against the mock node and saved in order,
+// like a gossiped alert, to validate an upgrade against the real history and benchmark the processing throughput
+package simulation
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/app/p2p"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+)
+
+// maxLineSize is the max size of a line of the export file (a confiscation alert can carry large transactions)
+const maxLineSize = 16 * 1024 * 1024
+
+// Options are the options of a simulation
+type Options struct {
+	MaxDelay time.Duration // Cap of the wait between two alerts (0 for no cap)
+	Speed    float64       // The recorded time between two alerts is divided by the speed (0 replays them back to back)
+}
+
+// Alert is a recorded alert of the history
+type Alert struct {
+	AlertType      models.AlertType
+	Raw            []byte // Wire format, as gossiped (replayed as is)
+	SequenceNumber uint32
+	Timestamp      time.Time
+}
+
+// Result is the result of a replayed alert
+type Result struct {
+	AlertType      string        `json:"alert_type"`
+	Duration       time.Duration `json:"duration"`        // Processing time (verified, enforced and saved)
+	Error          string        `json:"error,omitempty"` // Why the alert was rejected or its action failed
+	Processed      bool          `json:"processed"`       // Saved and its action succeeded
+	Saved          bool          `json:"saved"`
+	SequenceNumber uint32        `json:"sequence_number"`
+}
+
+// Report is the report of a simulation (the throughput excludes the waits between the alerts)
+type Report struct {
+	Alerts     int           `json:"alerts"`     // Replayed alerts
+	Elapsed    time.Duration `json:"elapsed"`    // Including the waits between the alerts
+	Failed     int           `json:"failed"`     // Saved, but their action failed on the mock node
+	Max        time.Duration `json:"max"`        // Slowest alert
+	P50        time.Duration `json:"p50"`        // Median processing time
+	P99        time.Duration `json:"p99"`        // 99th percentile processing time
+	Processed  int           `json:"processed"`  // Saved and enforced
+	Processing time.Duration `json:"processing"` // Sum of the processing times
+	Rejected   int           `json:"rejected"`   // Not saved (invalid signatures, out of sequence, ...)
+	Results    []*Result     `json:"results"`
+	Throughput float64       `json:"throughput"` // Alerts processed per second
+}
+
+// ReadExport will read the alerts of an export file (JSON lines, see the export command) in sequence order
+func ReadExport(r io.Reader) ([]*Alert, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var alerts []*Alert
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record struct {
+			Raw string `json:"raw"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		raw, err := hex.DecodeString(record.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid raw alert: %w", line, err)
+		}
+		var alert *models.AlertMessage
+		if alert, err = models.NewAlertFromBytes(raw); err != nil {
+			return nil, fmt.Errorf("line %d: invalid raw alert: %w", line, err)
+		}
+		alerts = append(alerts, &Alert{
+			AlertType:      alert.GetAlertType(),
+			Raw:            raw,
+			SequenceNumber: alert.SequenceNumber,
+			Timestamp:      time.Unix(int64(alert.Timestamp()), 0), //nolint:gosec // Unix seconds
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].SequenceNumber < alerts[j].SequenceNumber
+	})
+	return alerts, nil
+}
+
+// Run will replay the alerts after the latest saved alert (the genesis alert on a new datastore) through a
+// standalone P2P server on an in-memory host, and return the report
+// The services of the config are not closed
+func Run(ctx context.Context, conf *config.Config, alerts []*Alert, opts Options) (*Report, error) {
+	if !conf.NodeMock.Enabled {
+		return nil, ErrNodeNotMocked
+	}
+	if err := models.CreateGenesisAlert(ctx, model.WithAllDependencies(conf)); err != nil {
+		return nil, err
+	}
+	latest, err := models.GetLatestAlert(ctx, nil, model.WithAllDependencies(conf))
+	if err != nil {
+		return nil, err
+	}
+	for len(alerts) > 0 && latest != nil && alerts[0].SequenceNumber <= latest.SequenceNumber {
+		alerts = alerts[1:]
+	}
+	if len(alerts) == 0 {
+		return nil, ErrNoAlerts
+	}
+
+	// Start a standalone server (no peers, the alerts are not published)
+	mn := mocknet.New()
+	defer func() {
+		_ = mn.Close()
+	}()
+	var server *p2p.Server
+	if server, err = newServer(ctx, conf, mn); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if stopErr := server.Stop(ctx); stopErr != nil {
+			conf.Services.Log.Errorf("error stopping the simulation server: %s", stopErr.Error())
+		}
+	}()
+
+	report := &Report{Results: make([]*Result, 0, len(alerts))}
+	start := time.Now()
+	for i, alert := range alerts {
+		if i > 0 {
+			if err = wait(ctx, alert.Timestamp.Sub(alerts[i-1].Timestamp), opts); err != nil {
+				return nil, err
+			}
+		}
+		report.add(replay(ctx, server, alert))
+	}
+	report.Elapsed = time.Since(start)
+	report.summarize()
+	return report, nil
+}
+
+// newServer will create and start the P2P server on an in-memory host
+func newServer(ctx context.Context, conf *config.Config, mn mocknet.Mocknet) (*p2p.Server, error) {
+	h, err := mn.GenPeer()
+	if err != nil {
+		return nil, err
+	}
+	var server *p2p.Server
+	if server, err = p2p.NewServer(p2p.ServerOptions{
+		Config:           conf,
+		DisableDiscovery: true,
+		Host:             h,
+		TopicNames:       []string{conf.P2P.TopicName},
+	}); err != nil {
+		return nil, err
+	}
+	if err = server.Start(ctx); err != nil {
+		_ = server.Stop(ctx)
+		return nil, err
+	}
+	return server, nil
+}
+
+// wait will wait the recorded time between two alerts divided by the speed (capped by the max delay)
+func wait(ctx context.Context, recorded time.Duration, opts Options) error {
+	if opts.Speed <= 0 || recorded <= 0 {
+		return nil
+	}
+	delay := time.Duration(float64(recorded) / opts.Speed)
+	if opts.MaxDelay > 0 && delay > opts.MaxDelay {
+		delay = opts.MaxDelay
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// replay will process the alert and return its result
+func replay(ctx context.Context, server *p2p.Server, alert *Alert) *Result {
+	result := &Result{AlertType: alert.AlertType.Name(), SequenceNumber: alert.SequenceNumber}
+	start := time.Now()
+	saved, err := server.ProcessAlert(ctx, alert.Raw)
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Error = err.Error()
+	} else if result.Saved, result.Processed = true, saved.Processed; !saved.Processed {
+		result.Error = "alert action failed on the mock node, see the logs for the reason"
+	}
+	return result
+}
+
+// add will count the result of a replayed alert
+func (r *Report) add(result *Result) {
+	r.Alerts++
+	r.Processing += result.Duration
+	if result.Processed {
+		r.Processed++
+	} else if result.Saved {
+		r.Failed++
+	} else {
+		r.Rejected++
+	}
+	r.Results = append(r.Results, result)
+}
+
+// summarize will set the throughput and the processing time percentiles
+func (r *Report) summarize() {
+	if r.Alerts == 0 {
+		return
+	}
+	durations := make([]time.Duration, 0, len(r.Results))
+	for _, result := range r.Results {
+		durations = append(durations, result.Duration)
+	}
+	sort.Slice(durations, func(i, j int) bool {
+		return durations[i] < durations[j]
+	})
+	r.P50 = durations[(len(durations)-1)*50/100]
+	r.P99 = durations[(len(durations)-1)*99/100]
+	r.Max = durations[len(durations)-1]
+	if r.Processing > 0 {
+		r.Throughput = float64(r.Alerts) / r.Processing.Seconds()
+	}
+}
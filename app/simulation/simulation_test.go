@@ -0,0 +1,143 @@
+package simulation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newExport will return the export file of the informational alerts (one per sequence, in the given order)
+func newExport(t *testing.T, sequences ...uint32) []byte {
+	var export bytes.Buffer
+	encoder := json.NewEncoder(&export)
+	for _, sequence := range sequences {
+		alert, err := testutil.NewInformationalAlert(sequence, "simulation test")
+		require.NoError(t, err)
+		alert.Serialize()
+		require.NoError(t, encoder.Encode(alert))
+	}
+	return export.Bytes()
+}
+
+// TestReadExport will test the alerts of an export file are read in sequence order
+func TestReadExport(t *testing.T) {
+	t.Run("valid export", func(t *testing.T) {
+		export := newExport(t, 2, 1)
+		alerts, err := ReadExport(bytes.NewReader(append(export, '\n')))
+		require.NoError(t, err)
+		require.Len(t, alerts, 2)
+		assert.Equal(t, uint32(1), alerts[0].SequenceNumber)
+		assert.Equal(t, uint32(2), alerts[1].SequenceNumber)
+		assert.Equal(t, models.AlertTypeInformational, alerts[0].AlertType)
+		assert.WithinDuration(t, time.Now(), alerts[0].Timestamp, time.Minute)
+
+		alert, err := models.NewAlertFromBytes(alerts[0].Raw)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(1), alert.SequenceNumber)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		export := append(newExport(t, 1), []byte("{\n")...)
+		_, err := ReadExport(bytes.NewReader(export))
+		require.ErrorContains(t, err, "line 2")
+	})
+
+	t.Run("invalid raw alert", func(t *testing.T) {
+		_, err := ReadExport(bytes.NewReader([]byte(`{"raw":"0102"}`)))
+		require.ErrorContains(t, err, "line 1: invalid raw alert")
+	})
+}
+
+// TestWait will test the recorded time between two alerts is divided by the speed
+func TestWait(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("back to back", func(t *testing.T) {
+		start := time.Now()
+		require.NoError(t, wait(ctx, time.Hour, Options{}))
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("speed", func(t *testing.T) {
+		start := time.Now()
+		require.NoError(t, wait(ctx, time.Second, Options{Speed: 20}))
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+
+	t.Run("max delay", func(t *testing.T) {
+		start := time.Now()
+		require.NoError(t, wait(ctx, time.Hour, Options{MaxDelay: 10 * time.Millisecond, Speed: 1}))
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		require.ErrorIs(t, wait(canceled, time.Hour, Options{Speed: 1}), context.Canceled)
+	})
+}
+
+// TestReport will test the results are counted and summarized
+func TestReport(t *testing.T) {
+	r := &Report{}
+	for i := 1; i <= 100; i++ {
+		r.add(&Result{Duration: time.Duration(i) * time.Millisecond, Processed: true, Saved: true})
+	}
+	r.add(&Result{Duration: time.Second, Saved: true, Error: "failed"})
+	r.add(&Result{Duration: time.Millisecond, Error: "rejected"})
+	r.summarize()
+
+	assert.Equal(t, 102, r.Alerts)
+	assert.Equal(t, 100, r.Processed)
+	assert.Equal(t, 1, r.Failed)
+	assert.Equal(t, 1, r.Rejected)
+	assert.Equal(t, 50*time.Millisecond, r.P50)
+	assert.Equal(t, 99*time.Millisecond, r.P99)
+	assert.Equal(t, time.Second, r.Max)
+	assert.InDelta(t, 102/r.Processing.Seconds(), r.Throughput, 0.001)
+}
+
+// TestRun will test the alerts are replayed through the alert pipeline
+func TestRun(t *testing.T) {
+	t.Run("node not mocked", func(t *testing.T) {
+		_, err := Run(context.Background(), &config.Config{}, nil, Options{})
+		require.ErrorIs(t, err, ErrNodeNotMocked)
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("integration test of the alert pipeline")
+		}
+		require.NoError(t, os.Setenv(config.EnvironmentKey, config.EnvironmentTest))
+		ctx := context.Background()
+		conf, err := config.LoadDependencies(ctx, models.BaseModels, true)
+		require.NoError(t, err)
+		defer conf.CloseAll(ctx)
+		conf.NodeMock.Enabled = true
+
+		// Sequence 4 is out of sequence (3 is missing)
+		var alerts []*Alert
+		alerts, err = ReadExport(bytes.NewReader(newExport(t, 0, 1, 2, 4)))
+		require.NoError(t, err)
+
+		var report *Report
+		report, err = Run(ctx, conf, alerts, Options{})
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.Alerts)
+		assert.Equal(t, 2, report.Processed)
+		assert.Equal(t, 1, report.Rejected)
+		assert.Equal(t, uint32(4), report.Results[2].SequenceNumber)
+
+		_, err = Run(ctx, conf, alerts[:3], Options{})
+		require.ErrorIs(t, err, ErrNoAlerts)
+	})
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+)
+
+// Datastore is the store of the go-datastore models (the datastore of the config services)
+type Datastore struct {
+	config *config.Config
+}
+
+// NewDatastore will create the store of the datastore of the config
+func NewDatastore(conf *config.Config) *Datastore {
+	return &Datastore{config: conf}
+}
+
+// GetActivePeerBan will get the active ban of the peer
+func (d *Datastore) GetActivePeerBan(ctx context.Context, peerID string) (*models.PeerBan, error) {
+	return models.GetActivePeerBan(ctx, peerID, model.WithAllDependencies(d.config))
+}
+
+// GetBySequence will get the alert with the sequence number
+func (d *Datastore) GetBySequence(ctx context.Context, sequenceNumber uint32) (*models.AlertMessage, error) {
+	return models.GetAlertMessageBySequenceNumber(ctx, sequenceNumber, model.WithAllDependencies(d.config))
+}
+
+// GetLatest will get the alert with the highest sequence number
+func (d *Datastore) GetLatest(ctx context.Context) (*models.AlertMessage, error) {
+	return models.GetLatestAlert(ctx, nil, model.WithAllDependencies(d.config))
+}
+
+// ListActivePeerBans will get the active peer bans
+func (d *Datastore) ListActivePeerBans(ctx context.Context) ([]*models.PeerBan, error) {
+	return models.GetActivePeerBans(ctx, nil, model.WithAllDependencies(d.config))
+}
+
+// ListMissing will get the sequence numbers missing between the first and the latest alert (up to the limit)
+func (d *Datastore) ListMissing(ctx context.Context, limit int) ([]uint32, error) {
+	return models.GetMissingSequences(ctx, limit, model.WithAllDependencies(d.config))
+}
+
+// ListRange will get the alerts from and to the sequence numbers (inclusive, ordered by sequence number)
+func (d *Datastore) ListRange(ctx context.Context, from, to uint32) ([]*models.AlertMessage, error) {
+	return models.GetAlertsInRange(ctx, from, to, nil, model.WithAllDependencies(d.config))
+}
+
+// ListUnprocessed will get the alerts that weren't successfully processed (ordered by sequence number)
+func (d *Datastore) ListUnprocessed(ctx context.Context) ([]*models.AlertMessage, error) {
+	return models.GetAllUnprocessedAlerts(ctx, nil, model.WithAllDependencies(d.config))
+}
+
+// SaveAlert will save the alert (with its search terms and outbox events, in one transaction)
+func (d *Datastore) SaveAlert(ctx context.Context, alert *models.AlertMessage) error {
+	return alert.Save(ctx)
+}
+
+// SavePeerBan will save the peer ban
+func (d *Datastore) SavePeerBan(ctx context.Context, ban *models.PeerBan) error {
+	return ban.Save(ctx)
+}
@@ -0,0 +1,172 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/bitcoin-sv/alert-system/app/models"
+)
+
+// Memory is the store of the alerts and peer bans in memory (lost when the process stops, the alerts are synced
+// from the peers again)
+// The records are copied when they are saved and returned, so the callers do not share them
+type Memory struct {
+	alerts map[uint32]*models.AlertMessage // By sequence number
+	bans   map[uint64]*models.PeerBan      // By ID
+	lastID uint64
+	mu     sync.RWMutex
+}
+
+// NewMemory will create an empty memory store
+func NewMemory() *Memory {
+	return &Memory{
+		alerts: make(map[uint32]*models.AlertMessage),
+		bans:   make(map[uint64]*models.PeerBan),
+	}
+}
+
+// copyAlert will return a copy of the alert
+func copyAlert(alert *models.AlertMessage) *models.AlertMessage {
+	c := *alert
+	return &c
+}
+
+// copyBan will return a copy of the peer ban
+func copyBan(ban *models.PeerBan) *models.PeerBan {
+	c := *ban
+	return &c
+}
+
+// GetActivePeerBan will get the active ban of the peer
+func (m *Memory) GetActivePeerBan(ctx context.Context, peerID string) (*models.PeerBan, error) {
+	bans, _ := m.ListActivePeerBans(ctx)
+	for _, ban := range bans {
+		if ban.PeerID == peerID {
+			return ban, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetBySequence will get the alert with the sequence number
+func (m *Memory) GetBySequence(_ context.Context, sequenceNumber uint32) (*models.AlertMessage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if alert, ok := m.alerts[sequenceNumber]; ok {
+		return copyAlert(alert), nil
+	}
+	return nil, nil
+}
+
+// GetLatest will get the alert with the highest sequence number
+func (m *Memory) GetLatest(_ context.Context) (*models.AlertMessage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var latest *models.AlertMessage
+	for _, alert := range m.alerts {
+		if latest == nil || alert.SequenceNumber > latest.SequenceNumber {
+			latest = alert
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	return copyAlert(latest), nil
+}
+
+// ListActivePeerBans will get the active peer bans (ordered by ID)
+func (m *Memory) ListActivePeerBans(_ context.Context) ([]*models.PeerBan, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	bans := make([]*models.PeerBan, 0)
+	for _, ban := range m.bans {
+		if ban.Active {
+			bans = append(bans, copyBan(ban))
+		}
+	}
+	sort.Slice(bans, func(i, j int) bool {
+		return bans[i].ID < bans[j].ID
+	})
+	return bans, nil
+}
+
+// ListMissing will get the sequence numbers missing between the first and the latest alert (up to the limit)
+func (m *Memory) ListMissing(_ context.Context, limit int) ([]uint32, error) {
+	m.mu.RLock()
+	sequences := make([]uint32, 0, len(m.alerts))
+	for sequence := range m.alerts {
+		sequences = append(sequences, sequence)
+	}
+	m.mu.RUnlock()
+	sort.Slice(sequences, func(i, j int) bool {
+		return sequences[i] < sequences[j]
+	})
+
+	// Find the gaps between each alert
+	missing := make([]uint32, 0)
+	for i := 1; i < len(sequences) && len(missing) < limit; i++ {
+		for seq := sequences[i-1] + 1; seq < sequences[i] && len(missing) < limit; seq++ {
+			missing = append(missing, seq)
+		}
+	}
+	return missing, nil
+}
+
+// ListRange will get the alerts from and to the sequence numbers (inclusive, ordered by sequence number)
+func (m *Memory) ListRange(_ context.Context, from, to uint32) ([]*models.AlertMessage, error) {
+	return m.list(func(alert *models.AlertMessage) bool {
+		return alert.SequenceNumber >= from && alert.SequenceNumber <= to
+	}), nil
+}
+
+// ListUnprocessed will get the alerts that weren't successfully processed (ordered by sequence number)
+func (m *Memory) ListUnprocessed(_ context.Context) ([]*models.AlertMessage, error) {
+	return m.list(func(alert *models.AlertMessage) bool {
+		return !alert.Processed
+	}), nil
+}
+
+// list will return copies of the alerts matching the filter (ordered by sequence number)
+func (m *Memory) list(filter func(alert *models.AlertMessage) bool) []*models.AlertMessage {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	alerts := make([]*models.AlertMessage, 0)
+	for _, alert := range m.alerts {
+		if filter(alert) {
+			alerts = append(alerts, copyAlert(alert))
+		}
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].SequenceNumber < alerts[j].SequenceNumber
+	})
+	return alerts
+}
+
+// SaveAlert will save the alert (replacing the alert with the same sequence number)
+func (m *Memory) SaveAlert(_ context.Context, alert *models.AlertMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if alert.ID == 0 {
+		m.lastID++
+		alert.ID = m.lastID
+	}
+	alert.SetRecordTime(alert.IsNew())
+	alert.NotNew()
+	m.alerts[alert.SequenceNumber] = copyAlert(alert)
+	return nil
+}
+
+// SavePeerBan will save the peer ban
+func (m *Memory) SavePeerBan(_ context.Context, ban *models.PeerBan) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ban.ID == 0 {
+		m.lastID++
+		ban.ID = m.lastID
+	}
+	ban.SetRecordTime(ban.IsNew())
+	ban.NotNew()
+	m.bans[ban.ID] = copyBan(ban)
+	return nil
+}
@@ -0,0 +1,126 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Both stores implement the interface
+var (
+	_ AlertStore = (*Datastore)(nil)
+	_ AlertStore = (*Memory)(nil)
+)
+
+// newTestAlert will create a new alert with the sequence number
+func newTestAlert(sequenceNumber uint32, processed bool) *models.AlertMessage {
+	alert := models.NewAlertMessage(model.New())
+	alert.SequenceNumber = sequenceNumber
+	alert.Processed = processed
+	return alert
+}
+
+// TestMemory_Alerts will test saving and getting the alerts
+func TestMemory_Alerts(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	// Empty store
+	latest, err := m.GetLatest(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+
+	for _, seq := range []uint32{0, 1, 2, 5} {
+		require.NoError(t, m.SaveAlert(ctx, newTestAlert(seq, seq != 2)))
+	}
+
+	t.Run("get by sequence", func(t *testing.T) {
+		alert, getErr := m.GetBySequence(ctx, 1)
+		require.NoError(t, getErr)
+		require.NotNil(t, alert)
+		assert.Equal(t, uint32(1), alert.SequenceNumber)
+		assert.NotZero(t, alert.ID)
+		assert.False(t, alert.IsNew())
+		assert.False(t, alert.CreatedAt.IsZero())
+
+		alert, getErr = m.GetBySequence(ctx, 3)
+		require.NoError(t, getErr)
+		assert.Nil(t, alert)
+	})
+
+	t.Run("latest", func(t *testing.T) {
+		alert, getErr := m.GetLatest(ctx)
+		require.NoError(t, getErr)
+		assert.Equal(t, uint32(5), alert.SequenceNumber)
+	})
+
+	t.Run("range", func(t *testing.T) {
+		alerts, listErr := m.ListRange(ctx, 1, 4)
+		require.NoError(t, listErr)
+		require.Len(t, alerts, 2)
+		assert.Equal(t, uint32(1), alerts[0].SequenceNumber)
+		assert.Equal(t, uint32(2), alerts[1].SequenceNumber)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		missing, listErr := m.ListMissing(ctx, 10)
+		require.NoError(t, listErr)
+		assert.Equal(t, []uint32{3, 4}, missing)
+
+		missing, listErr = m.ListMissing(ctx, 1)
+		require.NoError(t, listErr)
+		assert.Equal(t, []uint32{3}, missing)
+	})
+
+	t.Run("unprocessed", func(t *testing.T) {
+		alerts, listErr := m.ListUnprocessed(ctx)
+		require.NoError(t, listErr)
+		require.Len(t, alerts, 1)
+		assert.Equal(t, uint32(2), alerts[0].SequenceNumber)
+
+		// The returned alerts are copies, saved again once processed
+		alerts[0].Processed = true
+		alerts, listErr = m.ListUnprocessed(ctx)
+		require.NoError(t, listErr)
+		require.Len(t, alerts, 1)
+		alerts[0].Processed = true
+		require.NoError(t, m.SaveAlert(ctx, alerts[0]))
+		alerts, listErr = m.ListUnprocessed(ctx)
+		require.NoError(t, listErr)
+		assert.Empty(t, alerts)
+	})
+}
+
+// TestMemory_PeerBans will test saving and getting the peer bans
+func TestMemory_PeerBans(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	for _, peerID := range []string{"peer1", "peer2"} {
+		ban := models.NewPeerBan(model.New())
+		ban.PeerID = peerID
+		ban.Active = true
+		require.NoError(t, m.SavePeerBan(ctx, ban))
+	}
+
+	ban, err := m.GetActivePeerBan(ctx, "peer2")
+	require.NoError(t, err)
+	require.NotNil(t, ban)
+	assert.Equal(t, "peer2", ban.PeerID)
+
+	// Lift the ban
+	ban.Active = false
+	require.NoError(t, m.SavePeerBan(ctx, ban))
+	ban, err = m.GetActivePeerBan(ctx, "peer2")
+	require.NoError(t, err)
+	assert.Nil(t, ban)
+
+	bans, err := m.ListActivePeerBans(ctx)
+	require.NoError(t, err)
+	require.Len(t, bans, 1)
+	assert.Equal(t, "peer1", bans[0].PeerID)
+}
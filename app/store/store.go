@@ -0,0 +1,25 @@
+// Package store is the storage of the alerts and peer bans used by the alert processing (receive, sync, enforce,
+// retry and ban), decoupled from go-datastore
+// Datastore is the default store (the go-datastore models), Memory keeps the records in memory (tests and embedders
+// without a database). The other records (node actions, outbox events, locks, webhooks) stay in the datastore
+package store
+
+import (
+	"context"
+
+	"github.com/bitcoin-sv/alert-system/app/models"
+)
+
+// AlertStore is the storage of the alerts and peer bans
+// The getters return nil (and no error) if the record is not found
+type AlertStore interface {
+	GetActivePeerBan(ctx context.Context, peerID string) (*models.PeerBan, error)
+	GetBySequence(ctx context.Context, sequenceNumber uint32) (*models.AlertMessage, error)
+	GetLatest(ctx context.Context) (*models.AlertMessage, error)
+	ListActivePeerBans(ctx context.Context) ([]*models.PeerBan, error)
+	ListMissing(ctx context.Context, limit int) ([]uint32, error)
+	ListRange(ctx context.Context, from, to uint32) ([]*models.AlertMessage, error)
+	ListUnprocessed(ctx context.Context) ([]*models.AlertMessage, error)
+	SaveAlert(ctx context.Context, alert *models.AlertMessage) error
+	SavePeerBan(ctx context.Context, ban *models.PeerBan) error
+}
@@ -0,0 +1,155 @@
+package supervisor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"time"
+)
+
+// bundlePrefix is the file name prefix of the diagnostic bundles
+const bundlePrefix = "panic-"
+
+// redacted replaces the secrets in the config dump
+const redacted = "[redacted]"
+
+// redactKeys are the config keys (or parts of keys) that are redacted in the config dump
+var redactKeys = []string{"dsn", "password", "secret", "token", "url"}
+
+// Bundle is the diagnostic bundle written when a goroutine panics
+type Bundle struct {
+	Config     map[string]interface{} `json:"config"`     // Config with the secrets redacted
+	Events     []*RecentEvent         `json:"events"`     // Recent events (oldest first)
+	Goroutine  string                 `json:"goroutine"`  // Goroutine that panicked
+	Goroutines string                 `json:"goroutines"` // Stacks of all the goroutines
+	Panic      string                 `json:"panic"`
+	Stack      string                 `json:"stack"` // Stack of the goroutine that panicked
+	Tags       map[string]string      `json:"tags,omitempty"`
+	Time       time.Time              `json:"time"`
+}
+
+// writeBundle will write the diagnostic bundle (and remove the oldest bundles over the max)
+// Returns the path of the bundle (empty if bundles are disabled)
+func (s *Supervisor) writeBundle(name string, r interface{}, stack []byte, tags map[string]string) (string, error) {
+	conf := s.config.Diagnostics
+	if conf.MaxBundles <= 0 || len(conf.Dir) == 0 {
+		return "", nil
+	}
+
+	bundle := &Bundle{
+		Events:    s.recentEvents(),
+		Goroutine: name,
+		Panic:     fmt.Sprintf("%v", r),
+		Stack:     string(stack),
+		Tags:      tags,
+		Time:      time.Now().UTC(),
+	}
+
+	// Dump the goroutines
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 2); err != nil {
+		return "", err
+	}
+	bundle.Goroutines = buf.String()
+
+	// Dump the config
+	var err error
+	if bundle.Config, err = redactConfig(s.config); err != nil {
+		return "", err
+	}
+
+	// Write the bundle (it can contain peer IDs and node hosts, so it is only readable by the owner)
+	var b []byte
+	if b, err = json.MarshalIndent(bundle, "", "  "); err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(conf.Dir, 0o700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(conf.Dir, bundlePrefix+bundle.Time.Format("20060102T150405.000000000Z")+"-"+fileName(name)+".json")
+	if err = os.WriteFile(path, b, 0o600); err != nil {
+		return "", err
+	}
+	return path, pruneBundles(conf.Dir, conf.MaxBundles)
+}
+
+// pruneBundles will remove the oldest bundles over the max
+func pruneBundles(dir string, max int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	bundles := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), bundlePrefix) {
+			bundles = append(bundles, entry.Name())
+		}
+	}
+	sort.Strings(bundles)
+	for len(bundles) > max {
+		if err = os.Remove(filepath.Join(dir, bundles[0])); err != nil {
+			return err
+		}
+		bundles = bundles[1:]
+	}
+	return nil
+}
+
+// redactConfig will return the config as a map with the secrets (passwords, tokens, DSN and URLs) redacted
+func redactConfig(conf interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]interface{})
+	if err = json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	redact(m)
+	return m, nil
+}
+
+// redact will redact the secrets in the value (recursively)
+func redact(v interface{}) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, child := range value {
+			if isSecret(k) && child != nil && child != "" {
+				value[k] = redacted
+				continue
+			}
+			redact(child)
+		}
+	case []interface{}:
+		for _, child := range value {
+			redact(child)
+		}
+	}
+}
+
+// isSecret will return true if the config key holds a secret
+func isSecret(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range redactKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// fileName will return the goroutine name safe for a file name
+func fileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
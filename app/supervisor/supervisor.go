@@ -0,0 +1,166 @@
+// Package supervisor recovers the panics in the long-running goroutines of the alert system
+// A panic is logged (with the stack), counted, reported and written to a diagnostic bundle on disk,
+// then the goroutine is restarted (with a backoff) instead of crashing the alert system
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/bitcoin-sv/alert-system/app/metrics"
+	"github.com/bitcoin-sv/alert-system/app/reporting"
+)
+
+// Restart backoff (doubles after each panic, reset once the goroutine has been running for the max delay)
+const (
+	maxRestartDelay = time.Minute
+	minRestartDelay = time.Second
+)
+
+// maxRecentEvents is the number of recent events kept for the diagnostic bundle
+const maxRecentEvents = 100
+
+// RecentEvent is an event published on the bus (kept for the diagnostic bundle)
+type RecentEvent struct {
+	Error    string      `json:"error,omitempty"`
+	Node     string      `json:"node,omitempty"`
+	PeerID   string      `json:"peer_id,omitempty"`
+	Sequence uint32      `json:"sequence,omitempty"`
+	Source   string      `json:"source,omitempty"`
+	Time     time.Time   `json:"time"`
+	Type     events.Type `json:"type"`
+}
+
+// Supervisor runs and recovers the long-running goroutines
+type Supervisor struct {
+	config       *config.Config
+	logger       config.LoggerInterface
+	mu           sync.Mutex
+	recent       []*RecentEvent
+	restartDelay time.Duration
+}
+
+// New will create a new supervisor that keeps the recent events of the bus for the diagnostic bundles
+func New(conf *config.Config, bus *events.Bus) *Supervisor {
+	s := &Supervisor{
+		config:       conf,
+		logger:       config.WithField(conf.Services.Log, config.LogFieldModule, "supervisor"),
+		recent:       make([]*RecentEvent, 0, maxRecentEvents),
+		restartDelay: minRestartDelay,
+	}
+	if bus != nil {
+		bus.Subscribe(s.record)
+	}
+	return s
+}
+
+// Go will run the goroutine, restarting it if it panics (until the context is done)
+// The goroutine is not restarted if it returns normally
+func (s *Supervisor) Go(ctx context.Context, name string, fn func(ctx context.Context)) {
+	go func() {
+		delay := s.restartDelay
+		for {
+			start := time.Now()
+			if !s.run(ctx, name, fn) || ctx.Err() != nil {
+				return
+			}
+			if time.Since(start) >= maxRestartDelay {
+				delay = s.restartDelay
+			}
+			s.logger.Warnf("restarting %s in %s", name, delay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			if delay *= 2; delay > maxRestartDelay {
+				delay = maxRestartDelay
+			}
+		}
+	}()
+}
+
+// run will run the goroutine and return true if it panicked
+func (s *Supervisor) run(ctx context.Context, name string, fn func(ctx context.Context)) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+			s.Panicked(name, r, debug.Stack(), nil)
+		}
+	}()
+	fn(ctx)
+	return false
+}
+
+// Recover will recover a panic (use it as a defer) so the caller returns normally
+// Use it for work that can be dropped (a message or a request) rather than restarting the goroutine
+// A nil supervisor does not recover the panic
+func (s *Supervisor) Recover(name string, tags map[string]string) {
+	if s == nil {
+		return
+	}
+	if r := recover(); r != nil {
+		s.Panicked(name, r, debug.Stack(), tags)
+	}
+}
+
+// Panicked will log the panic (with the stack), count it, report it and write the diagnostic bundle
+func (s *Supervisor) Panicked(name string, r interface{}, stack []byte, tags map[string]string) {
+	message := fmt.Sprintf("panic in %s: %v", name, r)
+	s.logger.Errorf("%s\n%s", message, stack)
+	metrics.Panics.WithLabelValues(name).Inc()
+
+	// Report the panic
+	if s.config.Services.Reporter != nil {
+		reportTags := map[string]string{"goroutine": name}
+		for k, v := range tags {
+			reportTags[k] = v
+		}
+		s.config.Services.Reporter.Capture(&reporting.Event{
+			Level:     reporting.LevelFatal,
+			Message:   message,
+			Stack:     string(stack),
+			Tags:      reportTags,
+			Timestamp: time.Now().UTC(),
+		})
+	}
+
+	// Write the diagnostic bundle
+	if path, err := s.writeBundle(name, r, stack, tags); err != nil {
+		s.logger.Errorf("failed to write diagnostic bundle: %s", err.Error())
+	} else if len(path) > 0 {
+		s.logger.Errorf("diagnostic bundle written to %s", path)
+	}
+}
+
+// record will keep the event for the diagnostic bundle (dropping the oldest)
+func (s *Supervisor) record(_ context.Context, e *events.Event) {
+	recent := &RecentEvent{Node: e.Node, PeerID: e.PeerID, Source: e.Source, Time: e.Time, Type: e.Type}
+	if e.Err != nil {
+		recent.Error = e.Err.Error()
+	}
+	if e.Alert != nil {
+		recent.Sequence = e.Alert.SequenceNumber
+	} else if e.Ban != nil {
+		recent.PeerID = e.Ban.PeerID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.recent) >= maxRecentEvents {
+		s.recent = append(s.recent[:0:0], s.recent[1:]...)
+	}
+	s.recent = append(s.recent, recent)
+}
+
+// recentEvents will return a copy of the recent events (oldest first)
+func (s *Supervisor) recentEvents() []*RecentEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append(make([]*RecentEvent, 0, len(s.recent)), s.recent...)
+}
@@ -0,0 +1,208 @@
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/bitcoin-sv/alert-system/app/metrics"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/reporting"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nopWriteCloser discards the log output
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close will do nothing
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// testReporter captures the reported events
+type testReporter struct {
+	mu     sync.Mutex
+	events []*reporting.Event
+}
+
+// Capture will keep the event
+func (r *testReporter) Capture(event *reporting.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// Flush will do nothing
+func (r *testReporter) Flush(context.Context) error {
+	return nil
+}
+
+// newTestSupervisor will create a supervisor writing the bundles to a temp directory
+func newTestSupervisor(t *testing.T, bus *events.Bus) (*Supervisor, *config.Config, *testReporter) {
+	reporter := &testReporter{}
+	conf := &config.Config{
+		Diagnostics: config.DiagnosticsConfig{Dir: t.TempDir(), MaxBundles: 2},
+		RPCConnections: []config.RPCConfig{
+			{Host: "localhost:8332", Password: "rpc-password", User: "rpc-user"},
+		},
+		Services: config.Services{
+			Log:      config.NewExtendedLogger(nopWriteCloser{io.Discard}, config.LogLevelError, nil),
+			Reporter: reporter,
+		},
+	}
+	s := New(conf, bus)
+	s.restartDelay = time.Millisecond
+	return s, conf, reporter
+}
+
+// readBundles will read the bundles in the directory
+func readBundles(t *testing.T, dir string) []*Bundle {
+	paths, err := filepath.Glob(filepath.Join(dir, bundlePrefix+"*.json"))
+	require.NoError(t, err)
+	bundles := make([]*Bundle, 0, len(paths))
+	for _, path := range paths {
+		var b []byte
+		b, err = os.ReadFile(path)
+		require.NoError(t, err)
+		bundle := &Bundle{}
+		require.NoError(t, json.Unmarshal(b, bundle))
+		bundles = append(bundles, bundle)
+	}
+	return bundles
+}
+
+// TestSupervisor_Go will test restarting a goroutine that panics
+func TestSupervisor_Go(t *testing.T) {
+	t.Run("restarted after a panic", func(t *testing.T) {
+		s, conf, reporter := newTestSupervisor(t, nil)
+		before := testutil.ToFloat64(metrics.Panics.WithLabelValues("test_restart"))
+
+		var runs atomic.Int32
+		done := make(chan struct{})
+		s.Go(context.Background(), "test_restart", func(context.Context) {
+			if runs.Add(1) < 4 {
+				panic("boom")
+			}
+			close(done)
+		})
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("goroutine was not restarted")
+		}
+		assert.Equal(t, int32(4), runs.Load())
+		assert.Equal(t, before+3, testutil.ToFloat64(metrics.Panics.WithLabelValues("test_restart")))
+		reporter.mu.Lock()
+		require.Len(t, reporter.events, 3)
+		assert.Equal(t, "panic in test_restart: boom", reporter.events[0].Message)
+		assert.Equal(t, "test_restart", reporter.events[0].Tags["goroutine"])
+		reporter.mu.Unlock()
+
+		// Only the latest bundles are kept
+		bundles := readBundles(t, conf.Diagnostics.Dir)
+		require.Len(t, bundles, 2)
+		assert.Equal(t, "test_restart", bundles[0].Goroutine)
+		assert.Equal(t, "boom", bundles[0].Panic)
+		assert.Contains(t, bundles[0].Stack, "supervisor_test.go")
+		assert.Contains(t, bundles[0].Goroutines, "goroutine")
+	})
+
+	t.Run("not restarted if the context is done", func(t *testing.T) {
+		s, _, _ := newTestSupervisor(t, nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		var runs atomic.Int32
+		done := make(chan struct{})
+		s.Go(ctx, "test_cancel", func(context.Context) {
+			runs.Add(1)
+			cancel()
+			defer close(done)
+			panic("boom")
+		})
+		<-done
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, int32(1), runs.Load())
+	})
+}
+
+// TestSupervisor_Recover will test recovering a panic so the caller returns normally
+func TestSupervisor_Recover(t *testing.T) {
+	t.Run("panic is recovered", func(t *testing.T) {
+		bus := events.NewBus()
+		s, conf, reporter := newTestSupervisor(t, bus)
+		bus.Publish(context.Background(), &events.Event{
+			Alert: &models.AlertMessage{SequenceNumber: 7}, Err: errors.New("node is down"), Source: events.SourceGossip, Type: events.AlertEnforced,
+		})
+
+		func() {
+			defer s.Recover("test_recover", map[string]string{config.LogFieldPeerID: "peer"})
+			panic(fmt.Errorf("nil alert"))
+		}()
+
+		reporter.mu.Lock()
+		require.Len(t, reporter.events, 1)
+		assert.Equal(t, "peer", reporter.events[0].Tags[config.LogFieldPeerID])
+		reporter.mu.Unlock()
+
+		bundles := readBundles(t, conf.Diagnostics.Dir)
+		require.Len(t, bundles, 1)
+		assert.Equal(t, "nil alert", bundles[0].Panic)
+		assert.Equal(t, "peer", bundles[0].Tags[config.LogFieldPeerID])
+		require.Len(t, bundles[0].Events, 1)
+		assert.Equal(t, uint32(7), bundles[0].Events[0].Sequence)
+		assert.Equal(t, "node is down", bundles[0].Events[0].Error)
+
+		// Secrets are redacted
+		rpc := bundles[0].Config["rpc_connections"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, redacted, rpc["password"])
+		assert.Equal(t, "rpc-user", rpc["user"])
+	})
+
+	t.Run("nil supervisor does not recover", func(t *testing.T) {
+		var s *Supervisor
+		assert.Panics(t, func() {
+			defer s.Recover("test_nil", nil)
+			panic("boom")
+		})
+	})
+
+	t.Run("bundles disabled", func(t *testing.T) {
+		s, conf, _ := newTestSupervisor(t, nil)
+		conf.Diagnostics.MaxBundles = -1
+		func() {
+			defer s.Recover("test_disabled", nil)
+			panic("boom")
+		}()
+		assert.Empty(t, readBundles(t, conf.Diagnostics.Dir))
+	})
+}
+
+// TestRedact will test redacting the secrets in the config dump
+func TestRedact(t *testing.T) {
+	m := map[string]interface{}{
+		"admin_token": "secret-token",
+		"heartbeat":   map[string]interface{}{"interval": "1m", "url": "https://hc-ping.com/uuid"},
+		"log_level":   "info",
+		"reporting":   map[string]interface{}{"dsn": ""},
+	}
+	redact(m)
+	assert.Equal(t, redacted, m["admin_token"])
+	assert.Equal(t, redacted, m["heartbeat"].(map[string]interface{})["url"])
+	assert.Equal(t, "1m", m["heartbeat"].(map[string]interface{})["interval"])
+	assert.Equal(t, "info", m["log_level"])
+	assert.Equal(t, "", m["reporting"].(map[string]interface{})["dsn"])
+}
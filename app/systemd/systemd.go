@@ -0,0 +1,67 @@
+// Package systemd is the integration with the systemd service manager (sd_notify)
+// With Type=notify the alert system reports it is ready once it is serving, and with WatchdogSec set
+// the watchdog keepalives are only sent while it is healthy, so systemd restarts a wedged daemon.
+// Nothing is sent if the alert system was not started by systemd (NOTIFY_SOCKET is not set).
+package systemd
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/daemon"
+)
+
+// Ready will tell systemd the service is ready (true if the state was sent)
+func Ready() (bool, error) {
+	return daemon.SdNotify(false, daemon.SdNotifyReady)
+}
+
+// MainPID will tell systemd the main process is now pid (after a restart, NotifyAccess=all is required)
+// (true if the state was sent)
+func MainPID(pid int) (bool, error) {
+	return daemon.SdNotify(false, "MAINPID="+strconv.Itoa(pid))
+}
+
+// Stopping will tell systemd the service is shutting down (true if the state was sent)
+func Stopping() (bool, error) {
+	return daemon.SdNotify(false, daemon.SdNotifyStopping)
+}
+
+// Status will send a status line shown by systemctl status (true if the state was sent)
+func Status(status string) (bool, error) {
+	return daemon.SdNotify(false, "STATUS="+status)
+}
+
+// WatchdogInterval will return the interval to send the keepalives at (half the watchdog timeout),
+// or zero if the watchdog is not enabled for this process
+func WatchdogInterval() (time.Duration, error) {
+	timeout, err := daemon.SdWatchdogEnabled(false)
+	if err != nil || timeout == 0 {
+		return 0, err
+	}
+	return timeout / 2, nil
+}
+
+// RunWatchdog will send a keepalive each interval while check passes, until the context is done
+// A failing check skips the keepalive (onFailure is called with why), systemd restarts the service
+// if no keepalive is received within the watchdog timeout
+func RunWatchdog(ctx context.Context, interval time.Duration, check func(ctx context.Context) error,
+	onFailure func(err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := check(ctx); err != nil {
+				onFailure(err)
+				continue
+			}
+			if _, err := daemon.SdNotify(false, daemon.SdNotifyWatchdog); err != nil {
+				onFailure(err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package systemd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listenNotify will listen on a notify socket and set NOTIFY_SOCKET
+func listenNotify(t *testing.T) *net.UnixConn {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	t.Setenv("NOTIFY_SOCKET", path)
+	return conn
+}
+
+// readNotify will read the next state sent to the notify socket
+func readNotify(t *testing.T, conn *net.UnixConn) string {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 256)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	return string(buf[:n])
+}
+
+// TestNotify will test the states sent to systemd
+func TestNotify(t *testing.T) {
+	t.Run("not started by systemd", func(t *testing.T) {
+		t.Setenv("NOTIFY_SOCKET", "")
+		sent, err := Ready()
+		require.NoError(t, err)
+		assert.False(t, sent)
+	})
+
+	t.Run("ready, status and stopping", func(t *testing.T) {
+		conn := listenNotify(t)
+
+		sent, err := Ready()
+		require.NoError(t, err)
+		assert.True(t, sent)
+		assert.Equal(t, "READY=1", readNotify(t, conn))
+
+		_, err = Status("serving")
+		require.NoError(t, err)
+		assert.Equal(t, "STATUS=serving", readNotify(t, conn))
+
+		_, err = Stopping()
+		require.NoError(t, err)
+		assert.Equal(t, "STOPPING=1", readNotify(t, conn))
+	})
+}
+
+// TestWatchdogInterval will test the keepalive interval
+func TestWatchdogInterval(t *testing.T) {
+	t.Run("not enabled", func(t *testing.T) {
+		t.Setenv("WATCHDOG_USEC", "")
+		interval, err := WatchdogInterval()
+		require.NoError(t, err)
+		assert.Zero(t, interval)
+	})
+
+	t.Run("half the timeout", func(t *testing.T) {
+		t.Setenv("WATCHDOG_USEC", "30000000")
+		t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+		interval, err := WatchdogInterval()
+		require.NoError(t, err)
+		assert.Equal(t, 15*time.Second, interval)
+	})
+
+	t.Run("another process", func(t *testing.T) {
+		t.Setenv("WATCHDOG_USEC", "30000000")
+		t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()+1))
+		interval, err := WatchdogInterval()
+		require.NoError(t, err)
+		assert.Zero(t, interval)
+	})
+}
+
+// TestRunWatchdog will test the keepalives are only sent while healthy
+func TestRunWatchdog(t *testing.T) {
+	conn := listenNotify(t)
+
+	var healthy atomic.Bool
+	failures := make(chan error, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go RunWatchdog(ctx, 10*time.Millisecond, func(context.Context) error {
+		if healthy.Load() {
+			return nil
+		}
+		return errors.New("unhealthy")
+	}, func(err error) {
+		select {
+		case failures <- err:
+		default:
+		}
+	})
+
+	// No keepalive while unhealthy
+	select {
+	case err := <-failures:
+		require.EqualError(t, err, "unhealthy")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the check did not run")
+	}
+
+	healthy.Store(true)
+	assert.Equal(t, "WATCHDOG=1", readNotify(t, conn))
+}
@@ -0,0 +1,263 @@
+// Package containers provisions real datastore containers (Postgres and MySQL) and a Redis container for the tests,
+// with the testcontainers-go modules, so the datastore code (SQL dialects, locking, migrations) is tested against
+// each backend by the package suites and by the embedders of the alert system
+//
+// A suite opts in by starting the container of the engine in ALERT_SYSTEM_TEST_DATASTORE (StartFromEnv), then
+// pointing the test config at it before loading the dependencies (Setenv, with its own table prefix per test)
+package containers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/mrz1836/go-datastore"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/mysql"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/modules/redis"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// TestEngineKey is the environment variable selecting the datastore engine of the tests (sqlite if empty)
+const TestEngineKey = "ALERT_SYSTEM_TEST_DATASTORE"
+
+// Images of the containers (override with ALERT_SYSTEM_TEST_POSTGRES_IMAGE, ALERT_SYSTEM_TEST_MYSQL_IMAGE and
+// ALERT_SYSTEM_TEST_REDIS_IMAGE)
+const (
+	DefaultMySQLImage    = "mysql:8.0"
+	DefaultPostgresImage = "postgres:16-alpine"
+	DefaultRedisImage    = "redis:7-alpine"
+)
+
+// redisImageKey is the environment variable overriding the image of the Redis container
+const redisImageKey = "ALERT_SYSTEM_TEST_REDIS_IMAGE"
+
+// Credentials of the containers (throwaway, the port is only published on localhost)
+const (
+	databaseName = "alert_system_db"
+	password     = "alert_system"
+)
+
+// readyTimeout is the max time for a container to accept connections
+const readyTimeout = 2 * time.Minute
+
+// pollInterval is the interval between the readiness checks
+const pollInterval = 500 * time.Millisecond
+
+// Container is a running datastore container
+type Container struct {
+	Engine    datastore.Engine
+	Host      string
+	ID        string
+	Image     string
+	Name      string // Database name
+	Password  string
+	Port      string // Port published on the host
+	User      string
+	container testcontainers.Container
+}
+
+// engineSpec is how the container of an engine is run and checked
+type engineSpec struct {
+	imageKey string
+	image    string
+	port     nat.Port
+	ready    []string // Command run in the container, succeeds once the server accepts TCP connections
+	run      func(ctx context.Context, opts ...testcontainers.ContainerCustomizer) (testcontainers.Container, error)
+	user     string
+}
+
+// specs are the supported engines
+var specs = map[datastore.Engine]engineSpec{
+	datastore.PostgreSQL: {
+		image:    DefaultPostgresImage,
+		imageKey: "ALERT_SYSTEM_TEST_POSTGRES_IMAGE",
+		port:     "5432/tcp",
+		ready: []string{ // Over TCP: the init server only listens on the socket
+			"psql", "-h", "127.0.0.1", "-U", "postgres", "-d", databaseName, "-c", "SELECT 1",
+		},
+		run: func(ctx context.Context, opts ...testcontainers.ContainerCustomizer) (testcontainers.Container, error) {
+			return postgres.RunContainer(ctx, append(opts,
+				postgres.WithDatabase(databaseName), postgres.WithUsername("postgres"), postgres.WithPassword(password),
+			)...)
+		},
+		user: "postgres",
+	},
+	datastore.MySQL: {
+		image:    DefaultMySQLImage,
+		imageKey: "ALERT_SYSTEM_TEST_MYSQL_IMAGE",
+		port:     "3306/tcp",
+		ready: []string{ // Over TCP: the init server skips networking
+			"mysql", "-h", "127.0.0.1", "-uroot", "-p" + password, "-e", "SELECT 1", databaseName,
+		},
+		run: func(ctx context.Context, opts ...testcontainers.ContainerCustomizer) (testcontainers.Container, error) {
+			return mysql.RunContainer(ctx, append(opts,
+				mysql.WithDatabase(databaseName), mysql.WithUsername("root"), mysql.WithPassword(password),
+			)...)
+		},
+		user: "root",
+	},
+}
+
+// Available will return true if a docker daemon is reachable
+func Available(ctx context.Context) bool {
+	provider, err := testcontainers.NewDockerProvider()
+	if err != nil {
+		return false
+	}
+	return provider.Health(ctx) == nil // Closes the provider
+}
+
+// StartFromEnv will start the container of the engine set in ALERT_SYSTEM_TEST_DATASTORE
+// Returns nil if it is empty or sqlite (the tests use the in-memory sqlite datastore)
+func StartFromEnv(ctx context.Context) (*Container, error) {
+	engine := datastore.Engine(strings.ToLower(os.Getenv(TestEngineKey)))
+	if len(engine) == 0 || engine == datastore.SQLite {
+		return nil, nil
+	}
+	return Start(ctx, engine)
+}
+
+// Start will run the container of the engine and wait for it to accept connections
+// The container is removed when stopped (see Stop)
+func Start(ctx context.Context, engine datastore.Engine) (*Container, error) {
+	spec, ok := specs[engine]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrEngineUnsupported, engine)
+	}
+	if !Available(ctx) {
+		return nil, ErrDockerUnavailable
+	}
+	image := imageFromEnv(spec.imageKey, spec.image)
+
+	container, err := spec.run(ctx,
+		testcontainers.WithImage(image),
+		testcontainers.WithWaitStrategyAndDeadline(readyTimeout, wait.ForExec(spec.ready).WithPollInterval(pollInterval)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error starting the %s container: %w", engine, err)
+	}
+	c := &Container{
+		Engine:    engine,
+		ID:        container.GetContainerID(),
+		Image:     image,
+		Name:      databaseName,
+		Password:  password,
+		User:      spec.user,
+		container: container,
+	}
+	if c.Host, c.Port, err = endpoint(ctx, container, spec.port); err != nil {
+		terminate(container)
+		return nil, err
+	}
+	return c, nil
+}
+
+// Stop will stop and remove the container
+func (c *Container) Stop(ctx context.Context) error {
+	return c.container.Terminate(ctx)
+}
+
+// Env will return the environment variables pointing the config at the container, with the table prefix
+// (a new prefix gives the test new tables, created by the auto migration)
+func (c *Container) Env(tablePrefix string) map[string]string {
+	env := map[string]string{
+		"ALERT_SYSTEM_DATASTORE__ENGINE":       c.Engine.String(),
+		"ALERT_SYSTEM_DATASTORE__PASSWORD":     c.Password,
+		"ALERT_SYSTEM_DATASTORE__TABLE_PREFIX": tablePrefix,
+	}
+	for _, conn := range []string{"SQL_READ", "SQL_WRITE"} {
+		prefix := "ALERT_SYSTEM_DATASTORE__" + conn + "__"
+		env[prefix+"DRIVER"] = c.Engine.String()
+		env[prefix+"HOST"] = c.Host
+		env[prefix+"NAME"] = c.Name
+		env[prefix+"PASSWORD"] = c.Password
+		env[prefix+"PORT"] = c.Port
+		env[prefix+"USER"] = c.User
+	}
+	return env
+}
+
+// Setenv will set the environment variables of Env (the keys must be in the config file, like the test config)
+func (c *Container) Setenv(tablePrefix string) error {
+	for key, value := range c.Env(tablePrefix) {
+		if err := os.Setenv(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Unsetenv will remove the environment variables of Env (the config file values are used again)
+func (c *Container) Unsetenv() {
+	for key := range c.Env("") {
+		_ = os.Unsetenv(key)
+	}
+}
+
+// Redis is a running Redis container
+type Redis struct {
+	Host      string
+	ID        string
+	Image     string
+	Port      string // Port published on the host
+	URL       string // Connection URL (redis://host:port)
+	container *redis.RedisContainer
+}
+
+// StartRedis will run the Redis container and wait for it to accept connections
+// The container is removed when stopped (see Stop)
+func StartRedis(ctx context.Context) (*Redis, error) {
+	if !Available(ctx) {
+		return nil, ErrDockerUnavailable
+	}
+	image := imageFromEnv(redisImageKey, DefaultRedisImage)
+	container, err := redis.RunContainer(ctx, testcontainers.WithImage(image))
+	if err != nil {
+		return nil, fmt.Errorf("error starting the redis container: %w", err)
+	}
+	r := &Redis{ID: container.GetContainerID(), Image: image, container: container}
+	if r.Host, r.Port, err = endpoint(ctx, container, "6379/tcp"); err == nil {
+		r.URL, err = container.ConnectionString(ctx)
+	}
+	if err != nil {
+		terminate(container)
+		return nil, err
+	}
+	return r, nil
+}
+
+// Stop will stop and remove the container
+func (r *Redis) Stop(ctx context.Context) error {
+	return r.container.Terminate(ctx)
+}
+
+// imageFromEnv will return the image set in the environment variable (the default image if not set)
+func imageFromEnv(key, image string) string {
+	if override := os.Getenv(key); len(override) > 0 {
+		return override
+	}
+	return image
+}
+
+// endpoint will return the host and the port published for the port of the container
+func endpoint(ctx context.Context, container testcontainers.Container, port nat.Port) (string, string, error) {
+	host, err := container.Host(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	var published nat.Port
+	if published, err = container.MappedPort(ctx, port); err != nil {
+		return "", "", err
+	}
+	return host, published.Port(), nil
+}
+
+// terminate will remove the container that started without a published port
+func terminate(container testcontainers.Container) {
+	_ = container.Terminate(context.Background())
+}
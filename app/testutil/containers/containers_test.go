@@ -0,0 +1,97 @@
+package containers
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/mrz1836/go-datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestContainer_Env will test the config of both connections points at the container
+func TestContainer_Env(t *testing.T) {
+	c := &Container{
+		Engine:   datastore.PostgreSQL,
+		Host:     "127.0.0.1",
+		Name:     databaseName,
+		Password: password,
+		Port:     "49153",
+		User:     "postgres",
+	}
+	env := c.Env("prefix")
+	assert.Equal(t, "postgresql", env["ALERT_SYSTEM_DATASTORE__ENGINE"])
+	assert.Equal(t, "prefix", env["ALERT_SYSTEM_DATASTORE__TABLE_PREFIX"])
+	for _, conn := range []string{"SQL_READ", "SQL_WRITE"} {
+		assert.Equal(t, "127.0.0.1", env["ALERT_SYSTEM_DATASTORE__"+conn+"__HOST"])
+		assert.Equal(t, "49153", env["ALERT_SYSTEM_DATASTORE__"+conn+"__PORT"])
+		assert.Equal(t, "postgres", env["ALERT_SYSTEM_DATASTORE__"+conn+"__USER"])
+		assert.Equal(t, password, env["ALERT_SYSTEM_DATASTORE__"+conn+"__PASSWORD"])
+	}
+
+	require.NoError(t, c.Setenv("prefix"))
+	assert.Equal(t, "49153", os.Getenv("ALERT_SYSTEM_DATASTORE__SQL_WRITE__PORT"))
+	c.Unsetenv()
+	assert.Empty(t, os.Getenv("ALERT_SYSTEM_DATASTORE__SQL_WRITE__PORT"))
+}
+
+// TestStart will test the containers accept connections (skipped without docker)
+func TestStart(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unsupported engine", func(t *testing.T) {
+		_, err := Start(ctx, datastore.MongoDB)
+		require.ErrorIs(t, err, ErrEngineUnsupported)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		t.Setenv(TestEngineKey, "sqlite")
+		c, err := StartFromEnv(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, c)
+	})
+
+	for _, engine := range []datastore.Engine{datastore.PostgreSQL, datastore.MySQL} {
+		t.Run(engine.String(), func(t *testing.T) {
+			if testing.Short() || !Available(ctx) {
+				t.Skip("integration test of the datastore containers (needs docker)")
+			}
+			c, err := Start(ctx, engine)
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, c.Stop(context.Background()))
+			}()
+
+			conn, err := net.Dial("tcp", net.JoinHostPort(c.Host, c.Port))
+			require.NoError(t, err)
+			_ = conn.Close()
+		})
+	}
+}
+
+// TestStartRedis will test the Redis container accepts connections (skipped without docker)
+func TestStartRedis(t *testing.T) {
+	ctx := context.Background()
+	if testing.Short() || !Available(ctx) {
+		t.Skip("integration test of the redis container (needs docker)")
+	}
+	r, err := StartRedis(ctx)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, r.Stop(context.Background()))
+	}()
+	assert.Equal(t, "redis://"+net.JoinHostPort(r.Host, r.Port), r.URL)
+
+	conn, err := net.Dial("tcp", net.JoinHostPort(r.Host, r.Port))
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("PING\r\n"))
+	require.NoError(t, err)
+	reply := make([]byte, 7)
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+	assert.Equal(t, "+PONG\r\n", string(reply))
+	_ = conn.Close()
+}
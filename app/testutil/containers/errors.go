@@ -0,0 +1,9 @@
+package containers
+
+import "errors"
+
+// Errors for the containers package
+var (
+	ErrDockerUnavailable = errors.New("docker is not available, the test containers cannot be started")
+	ErrEngineUnsupported = errors.New("datastore engine has no container")
+)
@@ -0,0 +1,256 @@
+// Package testutil is the in-process test network of the alert system: N nodes connected over the in-memory
+// transport of libp2p (mocknet), each with the test config, an in-memory datastore and a mock node, so the gossip,
+// the sync and the signature threshold can be integration tested without Docker or network access
+package testutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/devnet"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/app/p2p"
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/libp2p/go-libp2p/core/host"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/libsv/go-p2p/wire"
+)
+
+// pollInterval is the interval between the checks of the Wait functions
+const pollInterval = 50 * time.Millisecond
+
+// Network is the in-memory network of the alert system nodes (all connected to each other)
+type Network struct {
+	Mocknet mocknet.Mocknet
+	Nodes   []*Node
+	cancel  context.CancelFunc
+}
+
+// Node is an alert system node of the network
+type Node struct {
+	Config *config.Config // Test config with its own in-memory datastore and mock node
+	Host   host.Host      // In-memory libp2p host
+	Server *p2p.Server    // Started P2P server (no DHT or peer discovery, the hosts are connected by the network)
+}
+
+// NewNetwork will start n alert system nodes connected to each other over the in-memory transport
+// Each node has the genesis alert, Close() stops the nodes and closes their datastores
+func NewNetwork(ctx context.Context, n int) (_ *Network, err error) {
+	if err = os.Setenv(config.EnvironmentKey, config.EnvironmentTest); err != nil {
+		return nil, err
+	}
+	var mn mocknet.Mocknet
+	if mn, err = mocknet.FullMeshConnected(n); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	network := &Network{Mocknet: mn, cancel: cancel}
+	defer func() {
+		if err != nil {
+			network.Close(context.Background())
+		}
+	}()
+
+	for _, h := range mn.Hosts() {
+		var node *Node
+		if node, err = newNode(ctx, h); err != nil {
+			return nil, err
+		}
+		network.Nodes = append(network.Nodes, node)
+	}
+
+	// Wait for the nodes to see the subscriptions of the others (a message published before is not delivered)
+	if err = network.waitForSubscriptions(ctx); err != nil {
+		return nil, err
+	}
+	return network, nil
+}
+
+// waitForSubscriptions will wait until each node sees the topic subscriptions of all the other nodes
+func (n *Network) waitForSubscriptions(ctx context.Context) error {
+	for _, node := range n.Nodes {
+		topic := node.Server.Topics()[node.Config.P2P.TopicName]
+		for len(topic.ListPeers()) < len(n.Nodes)-1 {
+			if err := wait(ctx); err != nil {
+				return fmt.Errorf("nodes are not subscribed: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// newNode will create and start the node on the host
+func newNode(ctx context.Context, h host.Host) (*Node, error) {
+	conf, err := config.LoadDependencies(ctx, models.BaseModels, true)
+	if err != nil {
+		return nil, err
+	}
+	node := &Node{Config: conf, Host: h}
+	if err = models.CreateGenesisAlert(ctx, model.WithAllDependencies(conf)); err != nil {
+		conf.CloseAll(ctx)
+		return nil, err
+	}
+	if node.Server, err = p2p.NewServer(p2p.ServerOptions{
+		Config:           conf,
+		DisableDiscovery: true,
+		Host:             h,
+		TopicNames:       []string{conf.P2P.TopicName},
+	}); err != nil {
+		conf.CloseAll(ctx)
+		return nil, err
+	}
+	if err = node.Server.Start(ctx); err != nil {
+		_ = node.Server.Stop(ctx)
+		conf.CloseAll(ctx)
+		return nil, err
+	}
+	return node, nil
+}
+
+// Close will stop the nodes, close their datastores and the in-memory network
+func (n *Network) Close(ctx context.Context) {
+	n.cancel()
+	for _, node := range n.Nodes {
+		if err := node.Server.Stop(ctx); err != nil {
+			node.Config.Services.Log.Errorf("error stopping the test node: %s", err.Error())
+		}
+		node.Config.CloseAll(ctx)
+	}
+	_ = n.Mocknet.Close()
+}
+
+// NewAlert will create an alert signed with the private keys (the genesis keys if none)
+// The alert is not saved, see Node.Save() and Node.Publish()
+func NewAlert(sequence uint32, alertType models.AlertType, message []byte, privateKeys ...string) (*models.AlertMessage, error) {
+	if len(privateKeys) == 0 {
+		privateKeys = []string{utils.Key1, utils.Key2, utils.Key3}
+	}
+	data, err := devnet.NewAlert(sequence, uint32(alertType), message, privateKeys)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewAlertFromBytes(data)
+}
+
+// NewInformationalAlert will create an informational alert with the text, signed with the private keys (the
+// genesis keys if none)
+func NewInformationalAlert(sequence uint32, text string, privateKeys ...string) (*models.AlertMessage, error) {
+	var message bytes.Buffer
+	if err := wire.WriteVarInt(&message, 0, uint64(len(text))); err != nil {
+		return nil, err
+	}
+	message.WriteString(text)
+	return NewAlert(sequence, models.AlertTypeInformational, message.Bytes(), privateKeys...)
+}
+
+// NewSetKeysAlert will create an alert rotating the keys to the public keys (5 compressed hex keys), signed with the
+// private keys (the genesis keys if none)
+func NewSetKeysAlert(sequence uint32, publicKeys []string, privateKeys ...string) (*models.AlertMessage, error) {
+	var message []byte
+	for _, publicKey := range publicKeys {
+		key, err := hex.DecodeString(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		message = append(message, key...)
+	}
+	return NewAlert(sequence, models.AlertTypeSetKeys, message, privateKeys...)
+}
+
+// Save will save the alert on the node as enforced (without gossiping it), e.g. history for the other nodes to sync
+func (n *Node) Save(ctx context.Context, alert *models.AlertMessage) error {
+	saved, err := models.NewAlertFromBytes(alert.Serialize(), model.WithAllDependencies(n.Config))
+	if err != nil {
+		return err
+	}
+	saved.SerializeData()
+	saved.Processed = true
+	return saved.Save(ctx)
+}
+
+// Publish will gossip the alert from the node (the node does not process its own message, save it first if needed)
+func (n *Node) Publish(ctx context.Context, alert *models.AlertMessage) error {
+	topic, ok := n.Server.Topics()[n.Config.P2P.TopicName]
+	if !ok {
+		return fmt.Errorf("node is not subscribed to topic %s", n.Config.P2P.TopicName)
+	}
+	return topic.Publish(ctx, alert.Serialize())
+}
+
+// Sync will sync the missing alerts from the peer node and wait for the sync to finish
+func (n *Node) Sync(ctx context.Context, peer *Node) error {
+	job, err := n.Server.StartSync(peer.Host.ID().String())
+	if err != nil {
+		return err
+	}
+	for {
+		if job = n.Server.SyncJob(job.ID); job == nil {
+			return errors.New("sync job was dropped")
+		} else if job.Status == p2p.SyncJobStatusFailed {
+			return fmt.Errorf("%s: %s", job.Error, job.Peers[0].Error)
+		} else if job.Status == p2p.SyncJobStatusCompleted {
+			return nil
+		}
+		if err = wait(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// MissingSequences will return the sequences missing between the first and the latest alert saved on the node
+func (n *Node) MissingSequences(ctx context.Context) ([]uint32, error) {
+	return models.GetMissingSequences(ctx, 100, model.WithAllDependencies(n.Config))
+}
+
+// LatestSequence will return the sequence of the latest alert saved on the node
+func (n *Node) LatestSequence(ctx context.Context) (uint32, error) {
+	alert, err := models.GetLatestAlert(ctx, nil, model.WithAllDependencies(n.Config))
+	if err != nil {
+		return 0, err
+	} else if alert == nil {
+		return 0, errors.New("no alert saved")
+	}
+	return alert.SequenceNumber, nil
+}
+
+// WaitForAlert will wait until the alert is saved on the node (or the context is done), returns the saved alert
+func (n *Node) WaitForAlert(ctx context.Context, sequence uint32) (*models.AlertMessage, error) {
+	for {
+		alert, err := models.GetAlertMessageBySequenceNumber(ctx, sequence, model.WithAllDependencies(n.Config))
+		if err != nil {
+			return nil, err
+		} else if alert != nil {
+			return alert, nil
+		}
+		if err = wait(ctx); err != nil {
+			return nil, fmt.Errorf("alert %d was not saved: %w", sequence, err)
+		}
+	}
+}
+
+// WaitForAlert will wait until the alert is saved on all the nodes (or the context is done)
+func (n *Network) WaitForAlert(ctx context.Context, sequence uint32) error {
+	for i, node := range n.Nodes {
+		if _, err := node.WaitForAlert(ctx, sequence); err != nil {
+			return fmt.Errorf("node %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// wait will wait for the poll interval (the error of the context if it is done first)
+func wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(pollInterval):
+		return nil
+	}
+}
@@ -0,0 +1,150 @@
+package testutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/devnet"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimeout is the max time for an alert to reach the nodes
+const testTimeout = 30 * time.Second
+
+// newTestNetwork will start the network, closed at the end of the test (skipped by the short tests)
+func newTestNetwork(t *testing.T, n int) *Network {
+	if testing.Short() {
+		t.Skip("integration test of an in-memory network")
+	}
+	network, err := NewNetwork(context.Background(), n)
+	require.NoError(t, err)
+	require.Len(t, network.Nodes, n)
+	t.Cleanup(func() {
+		network.Close(context.Background())
+	})
+	return network
+}
+
+// TestNetwork_Gossip will test a gossiped alert is saved by all the other nodes
+func TestNetwork_Gossip(t *testing.T) {
+	network := newTestNetwork(t, 3)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	alert, err := NewInformationalAlert(1, "gossip test")
+	require.NoError(t, err)
+	origin := network.Nodes[0]
+	require.NoError(t, origin.Save(ctx, alert))
+	require.NoError(t, origin.Publish(ctx, alert))
+	require.NoError(t, network.WaitForAlert(ctx, 1))
+
+	for _, node := range network.Nodes {
+		saved, err := node.WaitForAlert(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, alert.Hash, saved.Hash)
+		assert.True(t, saved.Processed)
+	}
+}
+
+// TestNetwork_Sync will test a node syncs the alerts it missed from a peer
+func TestNetwork_Sync(t *testing.T) {
+	network := newTestNetwork(t, 2)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	peer, node := network.Nodes[0], network.Nodes[1]
+	for sequence := uint32(1); sequence <= 3; sequence++ {
+		alert, err := NewInformationalAlert(sequence, "sync test")
+		require.NoError(t, err)
+		require.NoError(t, peer.Save(ctx, alert))
+	}
+
+	require.NoError(t, node.Sync(ctx, peer))
+	latest, err := node.LatestSequence(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), latest)
+}
+
+// TestNetwork_FastSyncKeyRotation will test the fast sync of the latest alerts when the keys are rotated by an alert
+// that is skipped (or synced first), the keys are rotated in order and every alert is saved
+func TestNetwork_FastSyncKeyRotation(t *testing.T) {
+	tests := map[string]uint32{
+		"rotation in the skipped alerts":   2,
+		"rotation in the fast sync alerts": 5,
+	}
+	for name, rotation := range tests {
+		t.Run(name, func(t *testing.T) {
+			network := newTestNetwork(t, 2)
+			ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+			defer cancel()
+
+			// The alerts after the rotation are signed by the new keys
+			rotated, err := devnet.NewSigner()
+			require.NoError(t, err)
+			peer, node := network.Nodes[0], network.Nodes[1]
+			for sequence := uint32(1); sequence <= 6; sequence++ {
+				var alert *models.AlertMessage
+				switch {
+				case sequence == rotation:
+					alert, err = NewSetKeysAlert(sequence, rotated.PublicKeys)
+				case sequence > rotation:
+					alert, err = NewInformationalAlert(sequence, "fast sync test", rotated.PrivateKeys[:devnet.Signatures]...)
+				default:
+					alert, err = NewInformationalAlert(sequence, "fast sync test")
+				}
+				require.NoError(t, err)
+				require.NoError(t, peer.Save(ctx, alert))
+			}
+
+			// Only the latest 2 alerts are synced first
+			node.Config.P2P.FastSyncAlerts = 2
+			require.NoError(t, node.Sync(ctx, peer))
+			_, err = node.WaitForAlert(ctx, 6)
+			require.NoError(t, err)
+			for {
+				missing, missingErr := node.MissingSequences(ctx)
+				require.NoError(t, missingErr)
+				if len(missing) == 0 {
+					break
+				}
+				require.NoError(t, wait(ctx), "alerts %v are not backfilled", missing)
+			}
+
+			keys, err := models.GetActivePublicKey(ctx, nil, model.WithAllDependencies(node.Config))
+			require.NoError(t, err)
+			require.Len(t, keys, devnet.KeyCount)
+			for _, key := range keys {
+				assert.Contains(t, rotated.PublicKeys, key.Key)
+			}
+		})
+	}
+}
+
+// TestNetwork_Threshold will test an alert without enough signatures is not saved by the nodes
+func TestNetwork_Threshold(t *testing.T) {
+	network := newTestNetwork(t, 2)
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	// Signed by a single genesis key
+	alert, err := NewInformationalAlert(1, "threshold test", utils.Key1)
+	require.NoError(t, err)
+	require.NoError(t, network.Nodes[0].Publish(ctx, alert))
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 2*time.Second)
+	defer waitCancel()
+	_, err = network.Nodes[1].WaitForAlert(waitCtx, 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Signed by the genesis keys (the threshold)
+	alert, err = NewInformationalAlert(1, "threshold test")
+	require.NoError(t, err)
+	require.NoError(t, network.Nodes[0].Publish(ctx, alert))
+	_, err = network.Nodes[1].WaitForAlert(ctx, 1)
+	require.NoError(t, err)
+}
@@ -0,0 +1,46 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// Exporter defaults
+const (
+	DefaultBatchSize     = 512             // Max spans per export request
+	DefaultFlushInterval = 5 * time.Second // Max time a span waits before it is exported
+	DefaultQueueSize     = 2048            // Spans waiting to be exported (new spans are dropped if full)
+)
+
+// Options are the options for the tracer provider
+type Options struct {
+	Endpoint    string  // OTLP/HTTP collector endpoint (e.g. http://localhost:4318), traces are posted to /v1/traces
+	SampleRatio float64 // Fraction of new traces that are sampled (child spans follow their parent)
+	ServiceName string  // Reported as the service.name resource attribute
+}
+
+// Provider is the OpenTelemetry SDK tracer provider (batching the sampled spans to the OTLP/HTTP exporter)
+type Provider = sdktrace.TracerProvider
+
+// NewProvider will create a new tracer provider exporting to the collector endpoint
+// Register it with otel.SetTracerProvider() and call Shutdown() to flush the remaining spans
+func NewProvider(ctx context.Context, opts Options) (*Provider, error) {
+	exporter, err := newExporter(ctx, opts.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(
+			exporter,
+			sdktrace.WithBatchTimeout(DefaultFlushInterval),
+			sdktrace.WithMaxExportBatchSize(DefaultBatchSize),
+			sdktrace.WithMaxQueueSize(DefaultQueueSize),
+		),
+		sdktrace.WithResource(sdkresource.NewSchemaless(semconv.ServiceName(opts.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	), nil
+}
@@ -0,0 +1,115 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// collector is a fake OTLP/HTTP collector
+type collector struct {
+	mu       sync.Mutex
+	paths    []string
+	requests []*coltracepb.ExportTraceServiceRequest
+}
+
+// spans will return all the spans received by the collector
+func (c *collector) spans() (spans []*tracepb.Span) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, req := range c.requests {
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				spans = append(spans, ss.Spans...)
+			}
+		}
+	}
+	return
+}
+
+// newCollector will start a fake collector
+func newCollector(t *testing.T) (*collector, *httptest.Server) {
+	c := &collector{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		raw, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		body := new(coltracepb.ExportTraceServiceRequest)
+		require.NoError(t, proto.Unmarshal(raw, body))
+		c.mu.Lock()
+		c.paths = append(c.paths, req.URL.Path)
+		c.requests = append(c.requests, body)
+		c.mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	return c, srv
+}
+
+// TestProvider will test the tracer provider and exporter
+func TestProvider(t *testing.T) {
+	t.Run("spans are exported on shutdown", func(t *testing.T) {
+		c, srv := newCollector(t)
+		p, err := NewProvider(context.Background(), Options{Endpoint: srv.URL + "/", SampleRatio: 1, ServiceName: "test"})
+		require.NoError(t, err)
+
+		ctx, root := p.Tracer(TracerName).Start(context.Background(), SpanAlertReceive)
+		root.SetAttributes(AttrAlertSequence.Int64(5), AttrPeerID.String("peer"))
+		_, child := p.Tracer(TracerName).Start(ctx, SpanAlertVerify)
+		End(child, errors.New("bad signature"))
+		End(root, nil)
+
+		require.NoError(t, p.Shutdown(context.Background()))
+
+		spans := c.spans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, []string{"/v1/traces"}, c.paths)
+		resource := c.requests[0].ResourceSpans[0].Resource
+		require.NotNil(t, resource)
+		assert.Equal(t, "service.name", resource.Attributes[0].Key)
+		assert.Equal(t, "test", resource.Attributes[0].Value.GetStringValue())
+
+		verify, receive := spans[0], spans[1]
+		assert.Equal(t, SpanAlertVerify, verify.Name)
+		assert.Equal(t, SpanAlertReceive, receive.Name)
+		assert.Equal(t, receive.TraceId, verify.TraceId)
+		assert.Equal(t, receive.SpanId, verify.ParentSpanId)
+		assert.Empty(t, receive.ParentSpanId)
+		assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, verify.Status.Code)
+		assert.Equal(t, "bad signature", verify.Status.Message)
+		require.Len(t, verify.Events, 1)
+		assert.Equal(t, "exception", verify.Events[0].Name)
+
+		require.Len(t, receive.Attributes, 2)
+		assert.Equal(t, string(AttrAlertSequence), receive.Attributes[0].Key)
+		assert.Equal(t, int64(5), receive.Attributes[0].Value.GetIntValue())
+	})
+
+	t.Run("nothing is exported with a zero sample ratio", func(t *testing.T) {
+		c, srv := newCollector(t)
+		p, err := NewProvider(context.Background(), Options{Endpoint: srv.URL, SampleRatio: 0})
+		require.NoError(t, err)
+
+		_, s := p.Tracer(TracerName).Start(context.Background(), SpanAlertReceive)
+		assert.False(t, s.IsRecording())
+		End(s, nil)
+
+		require.NoError(t, p.Shutdown(context.Background()))
+		assert.Empty(t, c.spans())
+	})
+
+	t.Run("invalid endpoint", func(t *testing.T) {
+		_, err := NewProvider(context.Background(), Options{Endpoint: "localhost"})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,48 @@
+// Package tracing provides OpenTelemetry tracing for the alert pipeline
+// Spans are started with the global tracer provider (a no-op unless NewProvider is registered)
+// and exported to an OTLP/HTTP collector in batches
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation scope for the alert system spans
+const TracerName = "github.com/bitcoin-sv/alert-system"
+
+// Span names for each stage of the alert pipeline
+const (
+	SpanAlertPersist = "alert.persist" // Saving the alert (and child models)
+	SpanAlertProcess = "alert.process" // Performing the alert action (node RPC calls)
+	SpanAlertReceive = "alert.receive" // Alert message received from a peer (root span)
+	SpanAlertVerify  = "alert.verify"  // Verifying the alert signatures
+	SpanNodeRPC      = "node.rpc"      // RPC call to the bitcoin node
+)
+
+// Span attribute keys
+const (
+	AttrAlertSequence = attribute.Key("alert.sequence")
+	AttrAlertType     = attribute.Key("alert.type")
+	AttrPeerID        = attribute.Key("peer.id")
+	AttrRPCHost       = attribute.Key("rpc.host")
+	AttrRPCMethod     = attribute.Key("rpc.method")
+)
+
+// Start will start a span (a child of the span in the context, if any)
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End will end the span, recording the error (and setting the error status) if not nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
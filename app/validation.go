@@ -0,0 +1,74 @@
+package app
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Content types accepted for request bodies (parsed into the request params by the router)
+var allowedContentTypes = []string{
+	"application/json",
+	"application/x-www-form-urlencoded",
+	"multipart/form-data",
+	"text/plain", // JSON-RPC clients (the bitcoind examples post JSON as text/plain)
+}
+
+// FieldError is a validation error for a single request field
+type FieldError struct {
+	Field   string `json:"field"`   // Request field (param) name
+	Message string `json:"message"` // Why the field is invalid
+}
+
+// ValidationError is a request validation error with all the invalid fields
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Add will add an invalid field
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// Err will return the validation error or nil if all fields are valid
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Error will return the error message
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		messages = append(messages, f.Field+": "+f.Message)
+	}
+	return ErrRequestInvalid.Error() + " (" + strings.Join(messages, ", ") + ")"
+}
+
+// hasBody will return true if the request has (or may have) a body
+func hasBody(req *http.Request) bool {
+	return req.ContentLength > 0 || (req.ContentLength == -1 && req.Body != nil && req.Body != http.NoBody)
+}
+
+// validateBody will validate the size and content type of the request body
+// Returns the status code and error for the response (0 and nil if valid)
+func validateBody(req *http.Request, maxBodyBytes int64) (int, error) {
+	if !hasBody(req) {
+		return 0, nil
+	}
+	if maxBodyBytes > 0 && req.ContentLength > maxBodyBytes {
+		return http.StatusRequestEntityTooLarge, ErrBodyTooLarge
+	}
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return http.StatusUnsupportedMediaType, ErrUnsupportedContentType
+	}
+	for _, allowed := range allowedContentTypes {
+		if mediaType == allowed {
+			return 0, nil
+		}
+	}
+	return http.StatusUnsupportedMediaType, ErrUnsupportedContentType
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	apirouter "github.com/mrz1836/go-api-router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidationError will test the ValidationError methods
+func TestValidationError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no fields", func(t *testing.T) {
+		validation := new(ValidationError)
+		require.NoError(t, validation.Err())
+	})
+
+	t.Run("invalid fields", func(t *testing.T) {
+		validation := new(ValidationError)
+		validation.Add("url", "is required")
+		validation.Add("events", "event [foo] is not supported")
+		err := validation.Err()
+		require.Error(t, err)
+		assert.Equal(t, "request is invalid (url: is required, events: event [foo] is not supported)", err.Error())
+	})
+}
+
+// TestAPIErrorResponse_Validation will test the structured validation errors in APIErrorResponse()
+func TestAPIErrorResponse_Validation(t *testing.T) {
+	t.Parallel()
+
+	validation := new(ValidationError)
+	validation.Add("url", "is required")
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	APIErrorResponse(w, req, http.StatusBadRequest, validation)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	apiErr := new(APIError)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), apiErr))
+	assert.Equal(t, ErrRequestInvalid.Error(), apiErr.Message)
+	assert.Equal(t, []FieldError{{Field: "url", Message: "is required"}}, apiErr.Errors)
+
+	t.Run("other errors have no fields", func(t *testing.T) {
+		w = httptest.NewRecorder()
+		APIErrorResponse(w, req, http.StatusBadRequest, errors.New("bad"))
+		assert.NotContains(t, w.Body.String(), `"errors"`)
+	})
+}
+
+// TestAction_Request_Body will test the body validation in Request()
+func TestAction_Request_Body(t *testing.T) {
+	t.Parallel()
+
+	newAction := func() Action {
+		dep := new(config.Config)
+		dep.WebServer = config.WebServerConfig{DisableCompression: true, MaxBodyBytes: 16}
+		a, _ := NewStack(dep)
+		return a
+	}
+
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		status      int
+	}{
+		{"no body", "", "", http.StatusOK},
+		{"json body", `{"url":"x"}`, "application/json; charset=utf-8", http.StatusOK},
+		{"form body", "url=x", "application/x-www-form-urlencoded", http.StatusOK},
+		{"text body", `{"method":"x"}`, "text/plain", http.StatusOK},
+		{"missing content type", `{"url":"x"}`, "", http.StatusUnsupportedMediaType},
+		{"unsupported content type", "<url/>", "application/xml", http.StatusUnsupportedMediaType},
+		{"body too large", `{"url":"https://example.com"}`, "application/json", http.StatusRequestEntityTooLarge},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := newAction()
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body))
+			if len(test.contentType) > 0 {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			a.Request(apirouter.New(), testHandle)(w, req, nil)
+			assert.Equal(t, test.status, w.Code)
+		})
+	}
+}
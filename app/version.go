@@ -0,0 +1,79 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Version negotiation headers and media types
+const (
+	HeaderAPIVersion  = "X-API-Version"                  // Requested (and served) API version, e.g. "1"
+	HeaderDeprecation = "Deprecation"                    // Set on deprecated routes
+	HeaderLink        = "Link"                           // Points deprecated routes to their successor
+	HeaderSunset      = "Sunset"                         // Date a deprecated route will be removed
+	mediaTypePrefix   = "application/vnd.alert-system.v" // Versioned media type, e.g. application/vnd.alert-system.v1+json
+)
+
+// CurrentAPIVersion is the latest API version (served when no version is requested)
+const CurrentAPIVersion = "1"
+
+// SupportedAPIVersions are the API versions this server can serve
+var SupportedAPIVersions = []string{CurrentAPIVersion}
+
+// isSupportedAPIVersion will return true if the version is supported
+func isSupportedAPIVersion(version string) bool {
+	for _, v := range SupportedAPIVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// requestedAPIVersion will return the API version requested by the client (X-API-Version or the
+// versioned media type in the Accept header) or an empty string if no version was requested
+func requestedAPIVersion(req *http.Request) string {
+	if version := strings.TrimPrefix(strings.TrimSpace(req.Header.Get(HeaderAPIVersion)), "v"); len(version) > 0 {
+		return version
+	}
+	for _, part := range strings.Split(req.Header.Get("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if version, found := strings.CutPrefix(strings.ToLower(mediaType), mediaTypePrefix); found {
+			version, _, _ = strings.Cut(version, "+")
+			return version
+		}
+	}
+	return ""
+}
+
+// negotiateAPIVersion will pick the API version to serve (the current version if none was requested)
+func negotiateAPIVersion(req *http.Request) (string, error) {
+	version := requestedAPIVersion(req)
+	if len(version) == 0 {
+		return CurrentAPIVersion, nil
+	} else if !isSupportedAPIVersion(version) {
+		return "", ErrUnsupportedAPIVersion
+	}
+	return version, nil
+}
+
+// Deprecated will mark a legacy (unversioned) route as deprecated, pointing clients to the
+// same path under the v1 prefix and announcing the sunset date (if configured)
+func (a *Action) Deprecated(h httprouter.Handle) httprouter.Handle {
+	var sunset string
+	if date, err := time.Parse(config.LegacySunsetLayout, a.Config.WebServer.LegacySunset); err == nil {
+		sunset = date.UTC().Format(http.TimeFormat)
+	}
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		w.Header().Set(HeaderDeprecation, "true")
+		w.Header().Set(HeaderLink, "<"+APIVersion1+req.URL.Path+`>; rel="successor-version"`)
+		if len(sunset) > 0 {
+			w.Header().Set(HeaderSunset, sunset)
+		}
+		h(w, req, ps)
+	}
+}
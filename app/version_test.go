@@ -0,0 +1,97 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	apirouter "github.com/mrz1836/go-api-router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNegotiateAPIVersion will test the method negotiateAPIVersion()
+func TestNegotiateAPIVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		header        string
+		accept        string
+		expected      string
+		expectedError error
+	}{
+		{"no version requested", "", "", CurrentAPIVersion, nil},
+		{"plain json accept", "", "application/json", CurrentAPIVersion, nil},
+		{"version header", "1", "", "1", nil},
+		{"version header with prefix", "v1", "", "1", nil},
+		{"versioned media type", "", "application/vnd.alert-system.v1+json", "1", nil},
+		{"versioned media type in list", "", "text/html, application/vnd.alert-system.v1+json;q=0.9", "1", nil},
+		{"unsupported version header", "2", "", "", ErrUnsupportedAPIVersion},
+		{"unsupported media type version", "", "application/vnd.alert-system.v9+json", "", ErrUnsupportedAPIVersion},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if len(test.header) > 0 {
+				req.Header.Set(HeaderAPIVersion, test.header)
+			}
+			if len(test.accept) > 0 {
+				req.Header.Set("Accept", test.accept)
+			}
+			version, err := negotiateAPIVersion(req)
+			require.ErrorIs(t, err, test.expectedError)
+			assert.Equal(t, test.expected, version)
+		})
+	}
+}
+
+// TestAction_Request_Version will test the version negotiation in Request()
+func TestAction_Request_Version(t *testing.T) {
+	t.Parallel()
+
+	t.Run("supported version is served", func(t *testing.T) {
+		a, _ := NewStack(new(config.Config))
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		a.Request(apirouter.New(), testHandle)(w, req, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, CurrentAPIVersion, w.Header().Get(HeaderAPIVersion))
+	})
+
+	t.Run("unsupported version is not acceptable", func(t *testing.T) {
+		a, _ := NewStack(new(config.Config))
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderAPIVersion, "99")
+		a.Request(apirouter.New(), testHandle)(w, req, nil)
+		require.Equal(t, http.StatusNotAcceptable, w.Code)
+	})
+}
+
+// TestAction_Deprecated will test the method Deprecated()
+func TestAction_Deprecated(t *testing.T) {
+	t.Parallel()
+
+	t.Run("deprecation and successor headers", func(t *testing.T) {
+		a, _ := NewStack(new(config.Config))
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/alerts", nil)
+		a.Deprecated(testHandle)(w, req, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "true", w.Header().Get(HeaderDeprecation))
+		assert.Equal(t, `</api/v1/alerts>; rel="successor-version"`, w.Header().Get(HeaderLink))
+		assert.Empty(t, w.Header().Get(HeaderSunset))
+	})
+
+	t.Run("sunset date is announced", func(t *testing.T) {
+		dep := new(config.Config)
+		dep.WebServer = config.WebServerConfig{LegacySunset: "2030-01-31"}
+		a, _ := NewStack(dep)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		a.Deprecated(testHandle)(w, req, nil)
+		assert.Equal(t, "Thu, 31 Jan 2030 00:00:00 GMT", w.Header().Get(HeaderSunset))
+	})
+}
@@ -0,0 +1,464 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/events"
+	"github.com/bitcoin-sv/alert-system/app/metrics"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/app/reporting"
+	"github.com/tokenized/pkg/json"
+)
+
+// Events that can be delivered to registered webhooks (in the order of the alert lifecycle)
+const (
+	EventAlertFailed    = "alert.failed"    // Alert was verified but the action failed on the node
+	EventAlertProcessed = "alert.processed" // Alert was verified and enforced on the node
+	EventAlertReceived  = "alert.received"  // Alert was received from a peer (the signatures are not verified yet)
+	EventAlertVerified  = "alert.verified"  // Alert signatures and sequence were verified (before it is enforced)
+)
+
+// Headers sent with each delivery to a registered webhook
+const (
+	HeaderEvent              = "X-Alert-System-Event"               // The event name
+	HeaderIdempotencyKey     = "Idempotency-Key"                    // The key of the delivery (the same for every attempt)
+	HeaderSignature          = "X-Alert-System-Signature"           // The HMAC-SHA256 signature of the body (sha256=<hex>)
+	HeaderTimestamp          = "X-Alert-System-Timestamp"           // The Unix time of the attempt
+	HeaderTimestampSignature = "X-Alert-System-Timestamp-Signature" // The HMAC-SHA256 signature of the timestamp and body (sha256=<hex>)
+)
+
+// Events is the list of all supported events
+var Events = []string{
+	EventAlertFailed,
+	EventAlertProcessed,
+	EventAlertReceived,
+	EventAlertVerified,
+}
+
+// IsValidEvent will return true if the event is supported
+func IsValidEvent(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign will return the HMAC-SHA256 signature of the body using the shared secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignTimestamp will return the HMAC-SHA256 signature of "<timestamp>.<body>" using the shared secret
+// Unlike Sign, a replayed delivery can be rejected by its signed timestamp (see Verify)
+func SignTimestamp(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify will verify the timestamped signature of a received delivery (for the consumers written in Go)
+// The delivery is rejected if its timestamp is further than the tolerance from now (e.g. 5 minutes)
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	return verify(secret, header, body, tolerance, time.Now())
+}
+
+// verify will verify the timestamped signature at the given time
+func verify(secret string, header http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: missing or invalid timestamp", ErrInvalidSignature)
+	}
+	if !hmac.Equal([]byte(header.Get(HeaderTimestampSignature)), []byte(SignTimestamp(secret, timestamp, body))) {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w: signed %s ago", ErrSignatureExpired, age.Round(time.Second).String())
+	}
+	return nil
+}
+
+// newIdempotencyKey will return a random key for a delivery
+func newIdempotencyKey() string {
+	key := make([]byte, 16)
+	_, _ = rand.Read(key)
+	return hex.EncodeToString(key)
+}
+
+// delivery is a single payload to deliver to a registered webhook
+type delivery struct {
+	attempt        int
+	body           []byte
+	created        time.Time // When the delivery was queued (for the max age)
+	event          string
+	idempotencyKey string                  // The same for every attempt
+	record         *models.WebhookDelivery // Delivery status in the datastore (nil if it could not be saved)
+	status         int                     // HTTP status of the last attempt (0 if there was no response)
+	timestamp      int64                   // Signed timestamp of the last attempt
+	webhook        *models.Webhook
+}
+
+// Dispatcher delivers events to the registered webhooks (with retries)
+type Dispatcher struct {
+	config  *config.Config
+	handled *events.Dedup // Events already dispatched (a replayed outbox event is delivered once)
+	logger  config.LoggerInterface
+	mu      sync.Mutex
+	queue   chan *delivery
+	quit    chan struct{}
+	retries map[*delivery]*time.Timer // Scheduled retries (abandoned when stopped)
+	stop    sync.Once
+	wg      sync.WaitGroup
+}
+
+// NewDispatcher will create a new webhook dispatcher
+func NewDispatcher(conf *config.Config) *Dispatcher {
+	return &Dispatcher{
+		config:  conf,
+		handled: events.NewDedup(events.DefaultDedupSize),
+		logger:  config.WithField(conf.Services.Log, config.LogFieldModule, "webhook"),
+		queue:   make(chan *delivery, conf.Webhooks.QueueSize),
+		quit:    make(chan struct{}),
+		retries: make(map[*delivery]*time.Timer),
+	}
+}
+
+// Start will start the delivery workers
+func (d *Dispatcher) Start(ctx context.Context) {
+	for i := 0; i < d.config.Webhooks.Workers; i++ {
+		d.wg.Add(1)
+		go d.worker(ctx)
+	}
+}
+
+// Stop will stop the delivery workers (the queued deliveries and scheduled retries are recorded as abandoned)
+func (d *Dispatcher) Stop() {
+	d.stopWorkers()
+	d.abandonAll(context.Background())
+}
+
+// Flush will stop the delivery workers and attempt the queued deliveries once until the queue is empty or the
+// context is done (the deliveries still failing, not attempted or waiting for a retry are recorded as abandoned)
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	d.stopWorkers()
+	defer d.abandonAll(context.WithoutCancel(ctx))
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%w: %d webhook deliveries not attempted", err, len(d.queue))
+		}
+		select {
+		case del := <-d.queue:
+			d.process(ctx, del)
+		default:
+			return nil
+		}
+	}
+}
+
+// stopWorkers will stop the delivery workers and wait until they are done
+func (d *Dispatcher) stopWorkers() {
+	d.stop.Do(func() {
+		close(d.quit)
+	})
+	d.wg.Wait()
+}
+
+// stopped will return true once the dispatcher is stopped
+func (d *Dispatcher) stopped() bool {
+	select {
+	case <-d.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+// abandonAll will cancel the scheduled retries and record them and the queued deliveries as abandoned
+func (d *Dispatcher) abandonAll(ctx context.Context) {
+	d.mu.Lock()
+	retries := d.retries
+	d.retries = make(map[*delivery]*time.Timer)
+	d.mu.Unlock()
+	for del, timer := range retries {
+		if timer.Stop() { // Otherwise the retry is running and abandoned by enqueue
+			d.abandon(ctx, del, ErrDispatcherStopped)
+		}
+	}
+	for {
+		select {
+		case del := <-d.queue:
+			d.abandon(ctx, del, ErrDispatcherStopped)
+		default:
+			return
+		}
+	}
+}
+
+// abandon will record the delivery as abandoned for the reason (the dispatcher stopped or the queue is full)
+func (d *Dispatcher) abandon(ctx context.Context, del *delivery, reason error) {
+	metrics.WebhookDeliveries.WithLabelValues(del.event, metrics.ResultError).Inc()
+	if del.record != nil {
+		del.record.Status = models.DeliveryStatusAbandoned
+		if del.record.Attempts == 0 {
+			del.record.Error = reason.Error()
+		}
+		if err := del.record.Save(ctx); err != nil {
+			d.logger.Errorf("failed to record %s delivery to webhook %d: %s", del.event, del.webhook.ID, err.Error())
+		}
+	}
+	d.logger.Warnf("abandoning %s delivery to webhook %d after %d attempts: %s", del.event, del.webhook.ID, del.attempt, reason.Error())
+}
+
+// Dispatch will queue the alert event for all the active webhooks subscribed to the event
+// The event ID (the outbox dedup key, empty if the event is not saved in the outbox) is sent as the
+// idempotency key, so a replayed event is delivered once
+func (d *Dispatcher) Dispatch(ctx context.Context, event string, alert *models.AlertMessage, eventID string) error {
+
+	// Get the active webhooks
+	webhooks, err := models.GetActiveWebhooks(ctx, nil, model.WithAllDependencies(d.config))
+	if err != nil {
+		return err
+	} else if len(webhooks) == 0 {
+		return nil
+	}
+
+	// Create the payload
+	var p *Payload
+	if p, err = NewPayload(alert); err != nil {
+		return err
+	}
+	p.Event = event
+
+	// Marshal the payload
+	var body []byte
+	if body, err = json.Marshal(p); err != nil {
+		return err
+	}
+	d.dispatch(ctx, webhooks, event, alert.SequenceNumber, body, eventID)
+	return nil
+}
+
+// dispatch will queue a delivery for each subscribed webhook (recording it as pending)
+// A replayed event is skipped if it was dispatched by this process, or if its delivery was recorded as
+// delivered or failed (a delivery interrupted by a restart is resumed with its record)
+func (d *Dispatcher) dispatch(ctx context.Context, webhooks []*models.Webhook, event string, sequenceNumber uint32,
+	body []byte, eventID string) {
+	if !d.handled.First(eventID) {
+		d.logger.Debugf("skipping replayed %s event %s", event, eventID)
+		return
+	}
+	for _, webhook := range webhooks {
+		if !webhook.HasEvent(event) {
+			continue
+		}
+		del := &delivery{body: body, created: time.Now(), event: event, idempotencyKey: newIdempotencyKey(), webhook: webhook}
+		if len(eventID) > 0 {
+			del.idempotencyKey = eventIdempotencyKey(eventID, webhook.ID)
+			existing, err := models.GetWebhookDeliveryByKey(ctx, webhook.ID, del.idempotencyKey, model.WithAllDependencies(d.config))
+			if err != nil {
+				d.logger.Errorf("failed to get %s delivery to webhook %d: %s", event, webhook.ID, err.Error())
+			} else if existing != nil {
+				if existing.Status == models.DeliveryStatusDelivered || existing.Status == models.DeliveryStatusFailed {
+					d.logger.Debugf("skipping replayed %s delivery to webhook %d (%s)", event, webhook.ID, existing.Status)
+					continue
+				}
+				del.record = existing
+				d.enqueue(del)
+				continue
+			}
+		}
+		record := models.NewWebhookDelivery(model.WithAllDependencies(d.config), model.New())
+		record.Event = event
+		record.IdempotencyKey = del.idempotencyKey
+		record.SequenceNumber = sequenceNumber
+		record.Status = models.DeliveryStatusPending
+		record.WebhookID = webhook.ID
+		if err := record.Save(ctx); err != nil {
+			d.logger.Errorf("failed to record %s delivery to webhook %d: %s", event, webhook.ID, err.Error())
+		} else {
+			del.record = record
+		}
+		d.enqueue(del)
+	}
+}
+
+// eventIdempotencyKey will return the idempotency key of the event delivery to the webhook
+// (the same for every replay of the event)
+func eventIdempotencyKey(eventID string, webhookID uint64) string {
+	key := sha256.Sum256([]byte(eventID + "/" + strconv.FormatUint(webhookID, 10)))
+	return hex.EncodeToString(key[:16])
+}
+
+// enqueue will add the delivery to the queue (abandoned if the queue is full or the workers are stopped)
+func (d *Dispatcher) enqueue(del *delivery) {
+	if d.stopped() {
+		d.abandon(context.Background(), del, ErrDispatcherStopped)
+		return
+	}
+	select {
+	case d.queue <- del:
+	case <-d.quit:
+		d.abandon(context.Background(), del, ErrDispatcherStopped)
+	default:
+		d.abandon(context.Background(), del, ErrQueueFull)
+	}
+}
+
+// worker will deliver queued payloads until stopped
+func (d *Dispatcher) worker(ctx context.Context) {
+	defer d.wg.Done()
+	defer reporting.Recover(d.config.Services.Reporter, map[string]string{config.LogFieldModule: "webhook"})
+	for {
+		select {
+		case del := <-d.queue:
+			d.process(ctx, del)
+		case <-d.quit:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// process will attempt the delivery and schedule a retry on failure
+func (d *Dispatcher) process(ctx context.Context, del *delivery) {
+	start := time.Now()
+	err := d.deliver(ctx, del)
+	d.recordAttempt(ctx, del, time.Since(start), err)
+	if err == nil {
+		metrics.WebhookDeliveries.WithLabelValues(del.event, metrics.ResultOK).Inc()
+		d.record(ctx, del, models.DeliveryStatusDelivered, nil)
+		return
+	}
+
+	// Give up after the max retries, or if the retry would be older than the max age
+	del.attempt++
+	backoff := d.config.Webhooks.RetryInterval * time.Duration(1<<(del.attempt-1))
+	maxAge := d.config.Webhooks.MaxAge
+	expired := maxAge > 0 && time.Since(del.created)+backoff > maxAge
+	if expired {
+		err = fmt.Errorf("%w (%s): %s", ErrMaxAgeExceeded, maxAge.String(), err.Error())
+	}
+	if expired || del.attempt > d.config.Webhooks.MaxRetries {
+		metrics.WebhookDeliveries.WithLabelValues(del.event, metrics.ResultError).Inc()
+		d.record(ctx, del, models.DeliveryStatusFailed, err)
+		d.logger.Errorf("giving up on %s delivery to webhook %d after %d attempts: %s", del.event, del.webhook.ID, del.attempt, err.Error())
+		return
+	}
+
+	// No retry once stopped (attempted by Flush)
+	if d.stopped() {
+		metrics.WebhookDeliveries.WithLabelValues(del.event, metrics.ResultError).Inc()
+		d.record(ctx, del, models.DeliveryStatusAbandoned, fmt.Errorf("%w: %s", ErrDispatcherStopped, err.Error()))
+		d.logger.Warnf("abandoning %s delivery to webhook %d after %d attempts: %s", del.event, del.webhook.ID, del.attempt, err.Error())
+		return
+	}
+
+	// Retry with a backoff (doubles each attempt)
+	metrics.WebhookDeliveries.WithLabelValues(del.event, metrics.ResultRetry).Inc()
+	d.record(ctx, del, models.DeliveryStatusRetrying, err)
+	d.logger.Debugf("retrying %s delivery to webhook %d in %s: %s", del.event, del.webhook.ID, backoff.String(), err.Error())
+	d.mu.Lock()
+	d.retries[del] = time.AfterFunc(backoff, func() {
+		d.mu.Lock()
+		delete(d.retries, del)
+		d.mu.Unlock()
+		d.enqueue(del)
+	})
+	d.mu.Unlock()
+}
+
+// recordAttempt will save the attempt in the delivery attempts log (if the delivery is recorded)
+func (d *Dispatcher) recordAttempt(ctx context.Context, del *delivery, duration time.Duration, err error) {
+	if del.record == nil {
+		return
+	}
+	attempt := models.NewWebhookAttempt(model.WithAllDependencies(d.config), model.New())
+	attempt.Attempt = del.attempt + 1
+	attempt.DeliveryID = del.record.ID
+	attempt.Duration = duration.Milliseconds()
+	attempt.StatusCode = del.status
+	attempt.Timestamp = del.timestamp
+	attempt.WebhookID = del.webhook.ID
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	if saveErr := attempt.Save(ctx); saveErr != nil {
+		d.logger.Errorf("failed to record %s delivery attempt to webhook %d: %s", del.event, del.webhook.ID, saveErr.Error())
+	}
+}
+
+// record will save the status of the delivery attempt (if the delivery is recorded)
+func (d *Dispatcher) record(ctx context.Context, del *delivery, status string, err error) {
+	if del.record == nil {
+		return
+	}
+	del.record.Attempts++
+	del.record.Error = ""
+	del.record.Status = status
+	del.record.StatusCode = del.status
+	if err != nil {
+		del.record.Error = err.Error()
+	}
+	if saveErr := del.record.Save(ctx); saveErr != nil {
+		d.logger.Errorf("failed to record %s delivery to webhook %d: %s", del.event, del.webhook.ID, saveErr.Error())
+	}
+}
+
+// deliver will post the payload to the webhook URL
+func (d *Dispatcher) deliver(ctx context.Context, del *delivery) error {
+
+	// Create the http request
+	del.status = 0
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, del.webhook.URL, bytes.NewReader(del.body),
+	)
+	if err != nil {
+		return err
+	}
+	del.timestamp = time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, del.event)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(del.timestamp, 10))
+	if len(del.idempotencyKey) > 0 {
+		req.Header.Set(HeaderIdempotencyKey, del.idempotencyKey)
+	}
+	if len(del.webhook.Secret) > 0 {
+		req.Header.Set(HeaderSignature, Sign(del.webhook.Secret, del.body))
+		req.Header.Set(HeaderTimestampSignature, SignTimestamp(del.webhook.Secret, del.timestamp, del.body))
+	}
+
+	// Fire the http request
+	var res *http.Response
+	if res, err = d.config.Services.HTTPClient.Do(req); err != nil {
+		return err
+	}
+	defer func() {
+		if res != nil && res.Body != nil {
+			_ = res.Body.Close()
+		}
+	}()
+
+	// Validate the response (any 2xx is accepted)
+	del.status = res.StatusCode
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status code [%d] delivering to webhook", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,391 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSign will test the method Sign()
+func TestSign(t *testing.T) {
+	t.Parallel()
+
+	t.Run("known signature", func(t *testing.T) {
+		sig := Sign("secret", []byte(`{"sequence":1}`))
+		assert.Equal(t, "sha256=", sig[:7])
+		assert.Len(t, sig, 7+64)
+		assert.Equal(t, sig, Sign("secret", []byte(`{"sequence":1}`)))
+	})
+
+	t.Run("different secret", func(t *testing.T) {
+		assert.NotEqual(t, Sign("secret", []byte("body")), Sign("other", []byte("body")))
+	})
+}
+
+// TestSignTimestamp will test the methods SignTimestamp() and Verify()
+func TestSignTimestamp(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"sequence":1}`)
+	now := time.Unix(1704164645, 0)
+	signed := func(timestamp int64) http.Header {
+		header := http.Header{}
+		header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+		header.Set(HeaderTimestampSignature, SignTimestamp("secret", timestamp, body))
+		return header
+	}
+
+	t.Run("signature covers the timestamp", func(t *testing.T) {
+		sig := SignTimestamp("secret", now.Unix(), body)
+		assert.Len(t, sig, 7+64)
+		assert.NotEqual(t, sig, SignTimestamp("secret", now.Unix()+1, body))
+		assert.NotEqual(t, sig, Sign("secret", body))
+	})
+
+	t.Run("valid signature", func(t *testing.T) {
+		require.NoError(t, verify("secret", signed(now.Unix()-60), body, 5*time.Minute, now))
+	})
+
+	t.Run("replayed delivery", func(t *testing.T) {
+		err := verify("secret", signed(now.Unix()-600), body, 5*time.Minute, now)
+		require.ErrorIs(t, err, ErrSignatureExpired)
+		assert.Contains(t, err.Error(), "signed 10m0s ago")
+	})
+
+	t.Run("tampered timestamp or body", func(t *testing.T) {
+		header := signed(now.Unix() - 600)
+		header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+		require.ErrorIs(t, verify("secret", header, body, 5*time.Minute, now), ErrInvalidSignature)
+		require.ErrorIs(t, verify("secret", signed(now.Unix()), []byte(`{"sequence":2}`), 5*time.Minute, now), ErrInvalidSignature)
+		require.ErrorIs(t, verify("other", signed(now.Unix()), body, 5*time.Minute, now), ErrInvalidSignature)
+	})
+
+	t.Run("missing timestamp", func(t *testing.T) {
+		require.ErrorIs(t, verify("secret", http.Header{}, body, 5*time.Minute, now), ErrInvalidSignature)
+	})
+}
+
+// TestIsValidEvent will test the method IsValidEvent()
+func TestIsValidEvent(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsValidEvent(EventAlertProcessed))
+	assert.True(t, IsValidEvent(EventAlertFailed))
+	assert.True(t, IsValidEvent(EventAlertReceived))
+	assert.True(t, IsValidEvent(EventAlertVerified))
+	assert.False(t, IsValidEvent("alert.unknown"))
+	assert.False(t, IsValidEvent(""))
+}
+
+// TestDispatcher_deliver will test the method deliver()
+func TestDispatcher_deliver(t *testing.T) {
+	t.Parallel()
+
+	newDispatcher := func(client config.HTTPInterface) *Dispatcher {
+		conf := &config.Config{Services: config.Services{HTTPClient: client}}
+		conf.Webhooks.QueueSize = 1
+		return NewDispatcher(conf)
+	}
+
+	t.Run("signed delivery", func(t *testing.T) {
+		body := []byte(`{"sequence":1}`)
+		d := newDispatcher(&MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, EventAlertProcessed, req.Header.Get(HeaderEvent))
+				assert.Equal(t, Sign("secret", body), req.Header.Get(HeaderSignature))
+				assert.Equal(t, "key", req.Header.Get(HeaderIdempotencyKey))
+				require.NoError(t, Verify("secret", req.Header, body, time.Minute))
+				return &http.Response{StatusCode: http.StatusNoContent}, nil
+			},
+		})
+		err := d.deliver(context.Background(), &delivery{
+			body:           body,
+			event:          EventAlertProcessed,
+			idempotencyKey: "key",
+			webhook:        &models.Webhook{URL: "https://example.com/hook", Secret: "secret"},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("no secret, no signature", func(t *testing.T) {
+		d := newDispatcher(&MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				assert.Empty(t, req.Header.Get(HeaderSignature))
+				assert.Empty(t, req.Header.Get(HeaderTimestampSignature))
+				assert.NotEmpty(t, req.Header.Get(HeaderTimestamp))
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			},
+		})
+		err := d.deliver(context.Background(), &delivery{
+			event:   EventAlertFailed,
+			webhook: &models.Webhook{URL: "https://example.com/hook"},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("http client error", func(t *testing.T) {
+		d := newDispatcher(&MockHTTPClient{
+			DoFunc: func(_ *http.Request) (*http.Response, error) {
+				return nil, errors.New("HTTP client error")
+			},
+		})
+		err := d.deliver(context.Background(), &delivery{
+			event:   EventAlertProcessed,
+			webhook: &models.Webhook{URL: "https://example.com/hook"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "HTTP client error")
+	})
+
+	t.Run("invalid response status", func(t *testing.T) {
+		d := newDispatcher(&MockHTTPClient{
+			DoFunc: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusInternalServerError}, nil
+			},
+		})
+		err := d.deliver(context.Background(), &delivery{
+			event:   EventAlertProcessed,
+			webhook: &models.Webhook{URL: "https://example.com/hook"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unexpected status code [500]")
+	})
+
+	t.Run("status is kept for the delivery record", func(t *testing.T) {
+		d := newDispatcher(&MockHTTPClient{
+			DoFunc: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusAccepted}, nil
+			},
+		})
+		del := &delivery{
+			event:   EventAlertReceived,
+			status:  http.StatusBadGateway,
+			webhook: &models.Webhook{URL: "https://example.com/hook"},
+		}
+		require.NoError(t, d.deliver(context.Background(), del))
+		assert.Equal(t, http.StatusAccepted, del.status)
+
+		// Not recorded (no datastore record)
+		d.record(context.Background(), del, models.DeliveryStatusDelivered, nil)
+		assert.Nil(t, del.record)
+	})
+}
+
+// TestDispatcher_dispatch will test that a replayed event is delivered once, with the same idempotency key
+func TestDispatcher_dispatch(t *testing.T) {
+	t.Parallel()
+
+	keys := make(chan string, 10)
+	conf := &config.Config{Services: config.Services{
+		HTTPClient: &MockHTTPClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				keys <- req.Header.Get(HeaderIdempotencyKey)
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			},
+		},
+		Log: config.NewExtendedLogger(nopWriteCloser{io.Discard}, config.LogLevelError, nil),
+	}}
+	conf.Webhooks.QueueSize = 10
+	conf.Webhooks.Workers = 1
+	d := NewDispatcher(conf)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d.Start(ctx)
+	defer d.Stop()
+
+	webhooks := []*models.Webhook{{ID: 1, URL: "https://example.com/hook"}}
+	d.dispatch(ctx, webhooks, EventAlertProcessed, 42, []byte(`{}`), "alert.enforced:42:gossip:1")
+	d.dispatch(ctx, webhooks, EventAlertProcessed, 42, []byte(`{}`), "alert.enforced:42:gossip:1")
+
+	select {
+	case key := <-keys:
+		assert.Equal(t, eventIdempotencyKey("alert.enforced:42:gossip:1", 1), key)
+		assert.Len(t, key, 32)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+	select {
+	case <-keys:
+		t.Fatal("replayed event was delivered again")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Another event is delivered with its own key
+	assert.NotEqual(t, eventIdempotencyKey("alert.enforced:42:gossip:1", 1), eventIdempotencyKey("alert.enforced:42:gossip:1", 2))
+	d.dispatch(ctx, webhooks, EventAlertProcessed, 43, []byte(`{}`), "alert.enforced:43:gossip:1")
+	select {
+	case key := <-keys:
+		assert.Equal(t, eventIdempotencyKey("alert.enforced:43:gossip:1", 1), key)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+	}
+}
+
+// TestDispatcher_process will test giving up on the deliveries older than the max age
+func TestDispatcher_process(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Services: config.Services{
+		HTTPClient: &MockHTTPClient{
+			DoFunc: func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusServiceUnavailable}, nil
+			},
+		},
+		Log: config.NewExtendedLogger(nopWriteCloser{io.Discard}, config.LogLevelError, nil),
+	}}
+	conf.Webhooks.MaxAge = time.Hour
+	conf.Webhooks.MaxRetries = 5
+	conf.Webhooks.QueueSize = 1
+	conf.Webhooks.RetryInterval = time.Millisecond
+	d := NewDispatcher(conf)
+	defer d.Stop()
+
+	t.Run("retry is queued", func(t *testing.T) {
+		del := &delivery{created: time.Now(), event: EventAlertProcessed, webhook: &models.Webhook{URL: "https://example.com/hook"}}
+		d.process(context.Background(), del)
+		assert.Equal(t, 1, del.attempt)
+		assert.Equal(t, http.StatusServiceUnavailable, del.status)
+		select {
+		case retried := <-d.queue:
+			assert.Equal(t, del, retried)
+		case <-time.After(5 * time.Second):
+			t.Fatal("retry was not queued")
+		}
+	})
+
+	t.Run("older than the max age", func(t *testing.T) {
+		del := &delivery{created: time.Now().Add(-2 * time.Hour), event: EventAlertProcessed, webhook: &models.Webhook{URL: "https://example.com/hook"}}
+		d.process(context.Background(), del)
+		assert.Equal(t, 1, del.attempt)
+		select {
+		case <-d.queue:
+			t.Fatal("expired delivery was retried")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
+
+// nopWriteCloser discards the log output
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close will do nothing
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// TestDispatcher_Flush will test attempting the queued deliveries at shutdown
+func TestDispatcher_Flush(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	conf := &config.Config{Services: config.Services{
+		HTTPClient: &MockHTTPClient{
+			DoFunc: func(_ *http.Request) (*http.Response, error) {
+				attempts.Add(1)
+				return &http.Response{StatusCode: http.StatusServiceUnavailable}, nil
+			},
+		},
+		Log: config.NewExtendedLogger(nopWriteCloser{io.Discard}, config.LogLevelError, nil),
+	}}
+	conf.Webhooks.MaxRetries = 5
+	conf.Webhooks.QueueSize = 2
+	conf.Webhooks.RetryInterval = time.Millisecond
+	d := NewDispatcher(conf)
+	deliveries := make([]*delivery, 0, 2)
+	for i := 0; i < 2; i++ {
+		del := &delivery{
+			created: time.Now(), event: EventAlertProcessed,
+			record: models.NewWebhookDelivery(model.WithAllDependencies(conf), model.New()), webhook: &models.Webhook{URL: "https://example.com/hook"},
+		}
+		deliveries = append(deliveries, del)
+		d.enqueue(del)
+	}
+
+	// Attempted once, the retries are dropped and the deliveries are abandoned (not left retrying)
+	require.NoError(t, d.Flush(context.Background()))
+	time.Sleep(20 * time.Millisecond) // Longer than the retry interval
+	assert.Empty(t, d.queue)
+	assert.Equal(t, int32(2), attempts.Load())
+	for _, del := range deliveries {
+		assert.Equal(t, models.DeliveryStatusAbandoned, del.record.Status)
+		assert.Equal(t, 1, del.record.Attempts)
+		assert.Contains(t, del.record.Error, ErrDispatcherStopped.Error())
+	}
+}
+
+// TestDispatcher_enqueue will test abandoning the delivery when the queue is full
+func TestDispatcher_enqueue(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Services: config.Services{
+		Log: config.NewExtendedLogger(nopWriteCloser{io.Discard}, config.LogLevelError, nil),
+	}}
+	conf.Webhooks.QueueSize = 1
+	d := NewDispatcher(conf)
+	defer d.Stop()
+
+	queued := &delivery{created: time.Now(), event: EventAlertProcessed, record: models.NewWebhookDelivery(model.WithAllDependencies(conf), model.New()), webhook: &models.Webhook{URL: "https://example.com/hook"}}
+	d.enqueue(queued)
+	full := &delivery{created: time.Now(), event: EventAlertProcessed, record: models.NewWebhookDelivery(model.WithAllDependencies(conf), model.New()), webhook: &models.Webhook{URL: "https://example.com/hook"}}
+	d.enqueue(full)
+
+	require.Len(t, d.queue, 1)
+	assert.Equal(t, queued, <-d.queue)
+	assert.NotEqual(t, models.DeliveryStatusAbandoned, queued.record.Status)
+	assert.Equal(t, models.DeliveryStatusAbandoned, full.record.Status)
+	assert.Equal(t, ErrQueueFull.Error(), full.record.Error)
+}
+
+// TestDispatcher_Stop will test abandoning the scheduled retries and the queued deliveries when stopped
+func TestDispatcher_Stop(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	conf := &config.Config{Services: config.Services{
+		HTTPClient: &MockHTTPClient{
+			DoFunc: func(_ *http.Request) (*http.Response, error) {
+				attempts.Add(1)
+				return &http.Response{StatusCode: http.StatusServiceUnavailable}, nil
+			},
+		},
+		Log: config.NewExtendedLogger(nopWriteCloser{io.Discard}, config.LogLevelError, nil),
+	}}
+	conf.Webhooks.MaxRetries = 5
+	conf.Webhooks.QueueSize = 1
+	conf.Webhooks.RetryInterval = time.Hour
+	d := NewDispatcher(conf)
+
+	// A retry is scheduled, another delivery is queued
+	retrying := &delivery{created: time.Now(), event: EventAlertProcessed, record: models.NewWebhookDelivery(model.WithAllDependencies(conf), model.New()), webhook: &models.Webhook{URL: "https://example.com/hook"}}
+	d.process(context.Background(), retrying)
+	require.Equal(t, models.DeliveryStatusRetrying, retrying.record.Status)
+	queued := &delivery{created: time.Now(), event: EventAlertProcessed, record: models.NewWebhookDelivery(model.WithAllDependencies(conf), model.New()), webhook: &models.Webhook{URL: "https://example.com/hook"}}
+	d.enqueue(queued)
+
+	d.Stop()
+	assert.Empty(t, d.queue)
+	assert.Empty(t, d.retries)
+	assert.Equal(t, int32(1), attempts.Load())
+	assert.Equal(t, models.DeliveryStatusAbandoned, retrying.record.Status)
+	assert.Equal(t, models.DeliveryStatusAbandoned, queued.record.Status)
+	assert.Equal(t, ErrDispatcherStopped.Error(), queued.record.Error)
+
+	// Queued after the stop
+	late := &delivery{created: time.Now(), event: EventAlertProcessed, record: models.NewWebhookDelivery(model.WithAllDependencies(conf), model.New()), webhook: &models.Webhook{URL: "https://example.com/hook"}}
+	d.enqueue(late)
+	assert.Empty(t, d.queue)
+	assert.Equal(t, models.DeliveryStatusAbandoned, late.record.Status)
+}
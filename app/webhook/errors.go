@@ -0,0 +1,12 @@
+package webhook
+
+import "errors"
+
+// Webhook errors
+var (
+	ErrDispatcherStopped = errors.New("webhook dispatcher stopped before the delivery succeeded")
+	ErrInvalidSignature  = errors.New("webhook signature is invalid")
+	ErrMaxAgeExceeded    = errors.New("webhook delivery is older than the max age")
+	ErrQueueFull         = errors.New("webhook queue is full")
+	ErrSignatureExpired  = errors.New("webhook signature timestamp is outside the tolerance")
+)
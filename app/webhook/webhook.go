@@ -17,11 +17,29 @@ import (
 // Payload is the payload for the webhook
 type Payload struct {
 	AlertType models.AlertType `json:"alert_type"`
+	Event     string           `json:"event,omitempty"`
 	Raw       string           `json:"raw"`
 	Sequence  uint32           `json:"sequence"`
 	Text      string           `json:"text"`
 }
 
+// NewPayload will create the webhook payload for the alert
+func NewPayload(alert *models.AlertMessage) (*Payload, error) {
+	am := alert.ProcessAlertMessage()
+	if am == nil {
+		return nil, fmt.Errorf("alert type [%d] is not supported", alert.GetAlertType())
+	}
+	if err := am.Read(alert.GetRawMessage()); err != nil {
+		return nil, err
+	}
+	return &Payload{
+		AlertType: alert.GetAlertType(),
+		Sequence:  alert.SequenceNumber,
+		Raw:       hex.EncodeToString(alert.GetRawMessage()),
+		Text:      fmt.Sprintf("Sequence [`%d`], alert type [`%s`], message: [`%s`], processed: [`%v`]", alert.SequenceNumber, alert.GetAlertType().Name(), am.MessageString(), alert.Processed),
+	}, nil
+}
+
 // PostAlert sends an alert to a webhook URL using the provided http client
 func PostAlert(ctx context.Context, httpClient config.HTTPInterface, url string, alert *models.AlertMessage) error {
 	var err error
@@ -35,17 +53,10 @@ func PostAlert(ctx context.Context, httpClient config.HTTPInterface, url string,
 		return fmt.Errorf("webhook URL [%s] is does not have a valid prefix", url)
 	}
 
-	am := alert.ProcessAlertMessage()
-	err = am.Read(alert.GetRawMessage())
-	if err != nil {
-		return err
-	}
 	// Create the payload
-	p := Payload{
-		AlertType: alert.GetAlertType(),
-		Sequence:  alert.SequenceNumber,
-		Raw:       hex.EncodeToString(alert.GetRawMessage()),
-		Text:      fmt.Sprintf("Sequence [`%d`], alert type [`%s`], message: [`%s`], processed: [`%v`]", alert.SequenceNumber, alert.GetAlertType().Name(), am.MessageString(), alert.Processed),
+	var p *Payload
+	if p, err = NewPayload(alert); err != nil {
+		return err
 	}
 
 	// Marshal the payload
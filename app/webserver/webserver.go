@@ -5,13 +5,23 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"net"
 	"net/http"
+	"os"
 	"strings"
 
+	"github.com/bitcoin-sv/alert-system/app"
+	"github.com/bitcoin-sv/alert-system/app/api/admin"
 	"github.com/bitcoin-sv/alert-system/app/api/base"
 	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/handoff"
+	"github.com/bitcoin-sv/alert-system/app/p2p"
 	apirouter "github.com/mrz1836/go-api-router"
 	"github.com/newrelic/go-agent/v3/integrations/nrhttprouter"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 const (
@@ -20,14 +30,23 @@ const (
 
 // Server is the configuration, services, and actual web server
 type Server struct {
-	Config    *config.Config
-	Router    *apirouter.Router
-	WebServer *http.Server
+	AdminSocketServer *http.Server // API server on the admin socket (if an admin socket is set)
+	ChallengeServer   *http.Server // ACME HTTP-01 challenge server (if auto cert is enabled)
+	Config            *config.Config
+	P2P               *p2p.Server
+	Router            *apirouter.Router
+	WebServer         *http.Server
+	listening         chan struct{} // Closed once the web server is listening
 }
 
 // NewServer will return a new server service
-func NewServer(conf *config.Config) *Server {
-	return &Server{Config: conf}
+func NewServer(conf *config.Config, p2pServer *p2p.Server) *Server {
+	return &Server{Config: conf, P2P: p2pServer, listening: make(chan struct{})}
+}
+
+// Listening will return a channel closed once the web server is listening (requests are accepted)
+func (s *Server) Listening() <-chan struct{} {
+	return s.listening
 }
 
 // Serve will load a server and start serving
@@ -38,7 +57,8 @@ func (s *Server) Serve() {
 		Addr:              ":" + s.Config.WebServer.Port,
 		Handler:           s.Handlers(),
 		IdleTimeout:       s.Config.WebServer.IdleTimeout,
-		ReadHeaderTimeout: s.Config.WebServer.ReadTimeout,
+		MaxHeaderBytes:    s.Config.WebServer.MaxHeaderBytes,
+		ReadHeaderTimeout: s.Config.WebServer.ReadHeaderTimeout,
 		ReadTimeout:       s.Config.WebServer.ReadTimeout,
 		WriteTimeout:      s.Config.WebServer.WriteTimeout,
 		TLSConfig: &tls.Config{
@@ -59,23 +79,167 @@ func (s *Server) Serve() {
 	// Turn off keep alive
 	// s.WebServer.SetKeepAlivesEnabled(false)
 
-	// Listen and serve
-	if err := s.WebServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+	// Configure HTTP/2 (and h2c if enabled)
+	err := configureHTTP2(s.WebServer, s.Config.WebServer.HTTP2)
+	if err != nil {
+		s.Config.Services.Log.Errorf("error configuring http2: %s", err.Error())
+		return
+	}
+
+	// Serve the API on the admin socket (if set)
+	if len(s.Config.WebServer.AdminSocket) > 0 {
+		if err = s.serveAdminSocket(s.Config.WebServer.AdminSocket); err != nil {
+			s.Config.Services.Log.Errorf("error listening on the admin socket %s: %s", s.Config.WebServer.AdminSocket, err.Error())
+		}
+	}
+
+	// Listen, then serve (TLS via ACME if enabled)
+	var listener net.Listener
+	if listener, err = handoff.Listen(context.Background(), "web", s.WebServer.Addr, s.Config.WebServer.ReusePort); err != nil {
+		s.Config.Services.Log.Errorf("error listening on %s: %s", s.WebServer.Addr, err.Error())
+		return
+	}
+	if s.listening != nil {
+		close(s.listening)
+	}
+	if s.Config.WebServer.AutoCert.Enabled {
+		err = s.serveAutoCert(listener)
+	} else {
+		err = s.WebServer.Serve(listener)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		s.Config.Services.Log.Info("shutting down web server [" + err.Error() + "]...")
 	}
 }
 
+// configureHTTP2 will configure HTTP/2 on the server
+// HTTP/2 is negotiated over TLS (ALPN), h2c serves cleartext HTTP/2 (prior knowledge or upgrade)
+// for deployments where a proxy terminates TLS. If disabled, only HTTP/1.1 is served.
+func configureHTTP2(srv *http.Server, conf config.HTTP2Config) error {
+	if conf.Disabled {
+		srv.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+		if srv.TLSConfig != nil {
+			srv.TLSConfig.NextProtos = []string{"http/1.1"}
+		}
+		return nil
+	}
+	h2 := &http2.Server{
+		IdleTimeout:              srv.IdleTimeout,
+		MaxConcurrentStreams:     conf.MaxStreams,
+		MaxUploadBufferPerStream: conf.StreamBuffer,
+	}
+	if err := http2.ConfigureServer(srv, h2); err != nil {
+		return err
+	}
+	if conf.H2C {
+		srv.Handler = h2c.NewHandler(srv.Handler, h2)
+	}
+	return nil
+}
+
+// serveAutoCert will serve TLS using certificates from Let's Encrypt (ACME)
+// The HTTP-01 challenge handler also redirects all other HTTP requests to HTTPS
+func (s *Server) serveAutoCert(listener net.Listener) error {
+	manager := newCertManager(s.Config.WebServer.AutoCert)
+	s.WebServer.TLSConfig.GetCertificate = manager.GetCertificate
+	s.WebServer.TLSConfig.NextProtos = append(s.WebServer.TLSConfig.NextProtos, acme.ALPNProto)
+
+	// Start the HTTP-01 challenge server
+	s.ChallengeServer = &http.Server{
+		Addr:              ":" + s.Config.WebServer.AutoCert.HTTPPort,
+		Handler:           manager.HTTPHandler(nil),
+		IdleTimeout:       s.Config.WebServer.IdleTimeout,
+		MaxHeaderBytes:    s.Config.WebServer.MaxHeaderBytes,
+		ReadHeaderTimeout: s.Config.WebServer.ReadHeaderTimeout,
+		ReadTimeout:       s.Config.WebServer.ReadTimeout,
+		WriteTimeout:      s.Config.WebServer.WriteTimeout,
+	}
+	challengeListener, err := handoff.Listen(context.Background(), "acme", s.ChallengeServer.Addr, s.Config.WebServer.ReusePort)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := s.ChallengeServer.Serve(challengeListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.Config.Services.Log.Errorf("error serving acme challenge server: %s", err.Error())
+		}
+	}()
+
+	s.Config.Services.Log.Infof("serving tls for domains %s", strings.Join(s.Config.WebServer.AutoCert.Domains, ","))
+	return s.WebServer.ServeTLS(listener, "", "")
+}
+
+// serveAdminSocket will serve the API on the admin socket, a Unix socket only its owner can connect to (0600)
+// where the admin routes need no token. A stale socket file is replaced and the file is kept on shutdown
+// (after a restart the new process is already listening on it).
+func (s *Server) serveAdminSocket(path string) error {
+	_ = os.Remove(path)
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	if unixListener, ok := listener.(*net.UnixListener); ok {
+		unixListener.SetUnlinkOnClose(false)
+	}
+	if err = os.Chmod(path, 0o600); err != nil {
+		_ = listener.Close()
+		return err
+	}
+
+	s.AdminSocketServer = &http.Server{
+		Handler:           app.WithAdminSocket(s.WebServer.Handler),
+		IdleTimeout:       s.Config.WebServer.IdleTimeout,
+		MaxHeaderBytes:    s.Config.WebServer.MaxHeaderBytes,
+		ReadHeaderTimeout: s.Config.WebServer.ReadHeaderTimeout,
+		ReadTimeout:       s.Config.WebServer.ReadTimeout,
+		WriteTimeout:      s.Config.WebServer.WriteTimeout,
+	}
+	go func() {
+		if err := s.AdminSocketServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.Config.Services.Log.Errorf("error serving the admin socket: %s", err.Error())
+		}
+	}()
+	return nil
+}
+
+// newCertManager will create the ACME certificate manager
+func newCertManager(conf config.AutoCertConfig) *autocert.Manager {
+	return &autocert.Manager{
+		Cache:      autocert.DirCache(conf.CacheDir),
+		Email:      conf.Email,
+		HostPolicy: autocert.HostWhitelist(conf.Domains...),
+		Prompt:     autocert.AcceptTOS,
+	}
+}
+
 // Shutdown will stop the web server
+// New connections are refused and in-flight requests are drained until the context is done,
+// then any remaining connections are closed. Services (P2P, datastore) are closed by the caller.
 func (s *Server) Shutdown(ctx context.Context) error {
-	if s.Config != nil {
-		s.Config.CloseAll(ctx) // Should have been executed in main.go, but might panic and not run?
+	if s.AdminSocketServer != nil {
+		if err := shutdownServer(ctx, s.AdminSocketServer); err != nil {
+			return err
+		}
+	}
+	if s.ChallengeServer != nil {
+		if err := shutdownServer(ctx, s.ChallengeServer); err != nil {
+			return err
+		}
 	}
 	if s.WebServer != nil {
-		return s.WebServer.Shutdown(ctx)
+		return shutdownServer(ctx, s.WebServer)
 	}
 	return nil
 }
 
+// shutdownServer will gracefully shut down the server, closing it if the grace period expires
+func shutdownServer(ctx context.Context, srv *http.Server) error {
+	err := srv.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return srv.Close()
+	}
+	return err
+}
+
 // Handlers will return handlers
 func (s *Server) Handlers() *nrhttprouter.Router {
 
@@ -83,7 +247,7 @@ func (s *Server) Handlers() *nrhttprouter.Router {
 	s.Router = apirouter.New()
 
 	// Custom logger
-	s.Router.Logger = s.Config.Services.Log
+	s.Router.Logger = config.WithField(s.Config.Services.Log, config.LogFieldModule, "webserver")
 
 	// Turned on all CORs for now
 	s.Router.CrossOriginEnabled = true
@@ -100,7 +264,8 @@ func (s *Server) Handlers() *nrhttprouter.Router {
 	}, ",")
 
 	// Register all actions (routes / handlers)
-	base.RegisterRoutes(s.Router, s.Config)
+	base.RegisterRoutes(s.Router, s.Config, s.P2P)
+	admin.RegisterRoutes(s.Router, s.Config, s.P2P)
 
 	// Return the router
 	return s.Router.HTTPRouter
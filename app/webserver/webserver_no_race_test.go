@@ -36,7 +36,7 @@ func TestServer_Shutdown_NoRace(t *testing.T) {
 		require.NotNil(t, dependencies)
 
 		// Sync a new server
-		s := NewServer(dependencies)
+		s := NewServer(dependencies, nil)
 		require.NotNil(t, s)
 
 		// todo having an issue starting webserver and shutting down (in different routines)
@@ -63,6 +63,13 @@ func TestServer_Shutdown_NoRace(t *testing.T) {
 			s.Serve()
 		}()
 
+		// Wait for the server to listen
+		select {
+		case <-s.Listening():
+		case <-time.After(5 * time.Second):
+			t.Fatal("server is not listening")
+		}
+
 		// Delay
 		t.Log("server loaded, waiting 1 second...")
 		time.Sleep(1 * time.Second)
@@ -2,12 +2,21 @@ package webserver
 
 import (
 	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/bitcoin-sv/alert-system/app"
 	"github.com/bitcoin-sv/alert-system/app/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
 )
 
 // TestNewServer will test the method NewServer()
@@ -15,7 +24,7 @@ func TestNewServer(t *testing.T) {
 	t.Parallel()
 
 	t.Run("empty values", func(t *testing.T) {
-		s := NewServer(nil)
+		s := NewServer(nil, nil)
 		require.NotNil(t, s)
 		assert.Nil(t, s.Config)
 		assert.Nil(t, s.Router)
@@ -24,12 +33,13 @@ func TestNewServer(t *testing.T) {
 
 	t.Run("set values", func(t *testing.T) {
 		dependencies := &config.Config{}
-		s := NewServer(dependencies)
+		s := NewServer(dependencies, nil)
 		require.NotNil(t, s)
 		assert.Equal(t, dependencies, s.Config)
 		assert.Equal(t, dependencies, s.Config)
 		assert.Nil(t, s.Router)
 		assert.Nil(t, s.WebServer)
+		assert.NotNil(t, s.Listening())
 	})
 }
 
@@ -38,7 +48,7 @@ func TestServer_Shutdown(t *testing.T) {
 	t.Parallel()
 
 	t.Run("no server, services", func(t *testing.T) {
-		s := NewServer(nil)
+		s := NewServer(nil, nil)
 		require.NotNil(t, s)
 
 		err := s.Shutdown(context.Background())
@@ -48,7 +58,7 @@ func TestServer_Shutdown(t *testing.T) {
 	t.Run("basic app config and services", func(t *testing.T) {
 		dependencies := &config.Config{}
 
-		s := NewServer(dependencies)
+		s := NewServer(dependencies, nil)
 		require.NotNil(t, s)
 
 		err := s.Shutdown(context.Background())
@@ -74,7 +84,7 @@ func TestServer_Shutdown(t *testing.T) {
 		require.NotNil(t, appConfig)
 
 		// Sync a new server
-		s := NewServer(appConfig)
+		s := NewServer(appConfig, nil)
 		require.NotNil(t, s)
 
 		// Shutdown the server
@@ -82,3 +92,170 @@ func TestServer_Shutdown(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+// TestServer_serveAdminSocket will test the method serveAdminSocket()
+func TestServer_serveAdminSocket(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "admin.sock")
+	require.NoError(t, os.WriteFile(path, nil, 0o600)) // Stale socket file
+	s := NewServer(&config.Config{}, nil)
+	s.WebServer = &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = io.WriteString(w, strconv.FormatBool(app.FromAdminSocket(req)))
+	})}
+	require.NoError(t, s.serveAdminSocket(path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	res, err := client.Get("http://alert-system/")
+	require.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "true", string(body))
+
+	require.NoError(t, s.Shutdown(context.Background()))
+}
+
+// TestNewCertManager will test the method newCertManager()
+func TestNewCertManager(t *testing.T) {
+	t.Parallel()
+
+	m := newCertManager(config.AutoCertConfig{
+		CacheDir: t.TempDir(),
+		Domains:  []string{"alerts.example.com"},
+		Email:    "ops@example.com",
+		Enabled:  true,
+	})
+	require.NotNil(t, m)
+	assert.Equal(t, "ops@example.com", m.Email)
+	require.NoError(t, m.HostPolicy(context.Background(), "alerts.example.com"))
+	require.Error(t, m.HostPolicy(context.Background(), "other.example.com"))
+}
+
+// TestShutdownServer will test the method shutdownServer()
+func TestShutdownServer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("in-flight request is drained", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		srv := &http.Server{ //nolint:gosec // test server
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				time.Sleep(100 * time.Millisecond)
+				w.WriteHeader(http.StatusOK)
+			}),
+		}
+		go func() {
+			_ = srv.Serve(listener)
+		}()
+
+		status := make(chan int, 1)
+		go func() {
+			res, reqErr := http.Get("http://" + listener.Addr().String()) //nolint:noctx // test request
+			if reqErr != nil {
+				status <- 0
+				return
+			}
+			_ = res.Body.Close()
+			status <- res.StatusCode
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, shutdownServer(ctx, srv))
+		assert.Equal(t, http.StatusOK, <-status)
+	})
+
+	t.Run("grace period expires", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		block := make(chan struct{})
+		defer close(block)
+		srv := &http.Server{ //nolint:gosec // test server
+			Handler: http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+				<-block
+			}),
+		}
+		go func() {
+			_ = srv.Serve(listener)
+		}()
+		go func() {
+			res, reqErr := http.Get("http://" + listener.Addr().String()) //nolint:noctx // test request
+			if reqErr == nil {
+				_ = res.Body.Close()
+			}
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		require.NoError(t, shutdownServer(ctx, srv))
+	})
+}
+
+// TestConfigureHTTP2 will test the method configureHTTP2()
+func TestConfigureHTTP2(t *testing.T) {
+	t.Parallel()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(req.Proto))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		srv := &http.Server{Handler: handler, TLSConfig: &tls.Config{NextProtos: []string{"h2", "http/1.1"}}} //nolint:gosec // test server
+		require.NoError(t, configureHTTP2(srv, config.HTTP2Config{Disabled: true}))
+		assert.Equal(t, []string{"http/1.1"}, srv.TLSConfig.NextProtos)
+		assert.NotNil(t, srv.TLSNextProto)
+		assert.Empty(t, srv.TLSNextProto)
+	})
+
+	t.Run("enabled over tls", func(t *testing.T) {
+		srv := &http.Server{Handler: handler, TLSConfig: &tls.Config{MinVersion: tls.VersionTLS12}} //nolint:gosec // test server
+		require.NoError(t, configureHTTP2(srv, config.HTTP2Config{MaxStreams: 10}))
+		assert.Contains(t, srv.TLSConfig.NextProtos, "h2")
+		assert.Contains(t, srv.TLSNextProto, "h2")
+	})
+
+	t.Run("h2c serves cleartext http2", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		srv := &http.Server{Handler: handler, ReadHeaderTimeout: time.Second}
+		require.NoError(t, configureHTTP2(srv, config.HTTP2Config{H2C: true, MaxStreams: 10}))
+		go func() {
+			_ = srv.Serve(listener)
+		}()
+		defer func() {
+			_ = srv.Close()
+		}()
+
+		// Prior knowledge client (cleartext HTTP/2 without an upgrade)
+		client := &http.Client{Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return new(net.Dialer).DialContext(ctx, network, addr)
+			},
+		}}
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://"+listener.Addr().String(), nil)
+		require.NoError(t, err)
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		defer func() {
+			_ = res.Body.Close()
+		}()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "HTTP/2.0", string(body))
+	})
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/spf13/cobra"
+)
+
+// genesisResult is the output of the bootstrap-genesis command
+type genesisResult struct {
+	Hash       string   `json:"hash"`
+	PublicKeys []string `json:"public_keys"`
+	Raw        string   `json:"raw"`
+	Saved      bool     `json:"saved"`
+}
+
+// bootstrapGenesisOptions are the flags of the bootstrap-genesis command
+type bootstrapGenesisOptions struct {
+	alertFile   string
+	asJSON      bool
+	configs     *configFlags
+	dryRun      bool
+	publicKeys  string
+	signingKeys string
+}
+
+// newBootstrapGenesisCommand will create the bootstrap-genesis command
+func newBootstrapGenesisCommand() *cobra.Command {
+	o := &bootstrapGenesisOptions{}
+	cmd := &cobra.Command{
+		Use:   "bootstrap-genesis",
+		Short: "create and save the genesis alert of a new alert network",
+		Args:  cobra.NoArgs,
+		RunE:  runCommand(o.run),
+	}
+	o.configs = newConfigFlags(cmd.Flags())
+	cmd.Flags().StringVar(&o.alertFile, "alert", "", "file with the pre-signed genesis alert in hex (- for stdin)")
+	cmd.Flags().StringVar(&o.signingKeys, "signing-keys", "", "file with the private keys signing the genesis alert, one per line (hex or WIF)")
+	cmd.Flags().StringVar(&o.publicKeys, "public-keys", "", "comma separated public keys set by the genesis alert (overrides genesis_keys)")
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", false, "print the genesis alert without saving it")
+	cmd.Flags().BoolVar(&o.asJSON, "json", false, "print the genesis alert as JSON")
+	return cmd
+}
+
+// run will create and save the genesis alert of a new alert network and return the exit code
+// The alert is signed with the private keys of the signing keys file, or read from a pre-signed alert (the raw
+// hex printed by a dry run, so every operator of the network saves the same alert). Without either, it is the
+// genesis alert every instance creates at startup. The public keys it sets are the genesis_keys of the config.
+func (o *bootstrapGenesisOptions) run() int {
+	if len(o.alertFile) > 0 && len(o.signingKeys) > 0 {
+		fmt.Fprintln(os.Stderr, "--alert and --signing-keys cannot be used together (a pre-signed alert is already signed)")
+		return exitUsage
+	} else if err := o.configs.apply(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid flags: %s\n", err.Error())
+		return exitUsage
+	}
+
+	// The datastore is only loaded (and migrated) to save the alert
+	ctx := context.Background()
+	conf, err := config.ValidateConfigFile()
+	if !o.dryRun && err == nil {
+		conf, err = o.configs.load(ctx)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading configuration: %s\n", err.Error())
+		return exitError
+	}
+	var opts []model.Options
+	if !o.dryRun {
+		defer conf.CloseAll(ctx)
+		opts = append(opts, model.WithAllDependencies(conf))
+	}
+
+	// Sign or read the genesis alert
+	var genesis *models.AlertMessage
+	if genesis, err = loadGenesisAlert(o.alertFile, o.signingKeys, opts...); err != nil {
+		fmt.Fprintf(os.Stderr, "error creating the genesis alert: %s\n", err.Error())
+		return exitError
+	}
+	result := &genesisResult{Hash: genesis.Hash, PublicKeys: conf.GenesisKeys, Raw: genesis.Raw}
+	if len(o.publicKeys) > 0 {
+		result.PublicKeys = nil
+		for _, key := range strings.Split(o.publicKeys, ",") {
+			result.PublicKeys = append(result.PublicKeys, strings.TrimSpace(key))
+		}
+	}
+	if err = models.ValidatePublicKeys(result.PublicKeys); err != nil {
+		fmt.Fprintf(os.Stderr, "error creating the genesis alert: %s\n", err.Error())
+		return exitError
+	}
+
+	// Save the alert and the keys it sets
+	if !o.dryRun {
+		if err = models.SaveGenesisAlert(ctx, genesis, result.PublicKeys, opts...); err != nil {
+			fmt.Fprintf(os.Stderr, "error saving the genesis alert: %s\n", err.Error())
+			return exitError
+		}
+		result.Saved = true
+	}
+
+	if o.asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(result)
+		return exitOK
+	}
+	fmt.Printf("genesis alert %s\n", result.Hash)
+	fmt.Printf("raw           %s\n", result.Raw)
+	fmt.Printf("public keys   %s\n", strings.Join(result.PublicKeys, ","))
+	if result.Saved {
+		fmt.Println("saved the genesis alert and its public keys")
+	} else {
+		fmt.Println("not saved (dry run)")
+	}
+	return exitOK
+}
+
+// loadGenesisAlert will read the pre-signed genesis alert, or sign it with the signing keys (the genesis keys if
+// neither file is set)
+func loadGenesisAlert(alertFile, signingKeys string, opts ...model.Options) (*models.AlertMessage, error) {
+	if len(alertFile) > 0 {
+		content, err := readKeyFile(alertFile)
+		if err != nil {
+			return nil, err
+		}
+		var raw []byte
+		if raw, err = hex.DecodeString(string(bytes.TrimSpace(content))); err != nil {
+			return nil, fmt.Errorf("%w: %s", models.ErrInvalidGenesisAlert, err.Error())
+		}
+		return models.GenesisAlertFromBytes(raw, opts...)
+	}
+	var keys []string
+	if len(signingKeys) > 0 {
+		content, err := readKeyFile(signingKeys)
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); len(line) > 0 && !strings.HasPrefix(line, "#") {
+				keys = append(keys, line)
+			}
+		}
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("%w: no private keys in %s", models.ErrInvalidGenesisAlert, signingKeys)
+		}
+	}
+	return models.NewGenesisAlert(keys, opts...)
+}
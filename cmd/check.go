@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/health"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/app/p2p"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/spf13/cobra"
+)
+
+// checkResult is a row of the check table
+type checkResult struct {
+	Detail string `json:"detail,omitempty"`
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+}
+
+// checkOptions are the flags of the check command
+type checkOptions struct {
+	asJSON  bool
+	configs *configFlags
+	timeout time.Duration
+}
+
+// newCheckCommand will create the check command
+func newCheckCommand() *cobra.Command {
+	o := &checkOptions{}
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "test each external dependency once (datastore, nodes, P2P key, ports, bootstrap peers)",
+		Args:  cobra.NoArgs,
+		RunE:  runCommand(o.run),
+	}
+	o.configs = newConfigFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&o.asJSON, "json", false, "print the results as JSON")
+	cmd.Flags().DurationVar(&o.timeout, "timeout", 15*time.Second, "max time a single check can take")
+	return cmd
+}
+
+// run will test each external dependency once (datastore, nodes, P2P key, ports and bootstrap peers),
+// print a pass/fail table and return the exit code (1 if a check failed)
+// Nothing is started or written, so it can run in install scripts and next to a running alert system
+// (the port checks fail if the alert system is running, they test that the ports can be bound)
+func (o *checkOptions) run() int {
+	if err := o.configs.apply(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid flags: %s\n", err.Error())
+		return exitUsage
+	}
+
+	// Nothing else can be checked without a valid configuration
+	conf, err := config.ValidateConfigFile()
+	if err != nil {
+		return printCheckResults([]*checkResult{{Name: "config", Detail: err.Error()}}, o.asJSON)
+	}
+	results := []*checkResult{{Name: "config", Passed: true, Detail: "valid"}}
+
+	// Run the checks concurrently (each with the timeout)
+	checkers := checkDependencies(conf)
+	report := health.NewService(o.timeout, checkers...).Check(context.Background())
+	for _, checker := range checkers {
+		c := report.Check(checker.Name)
+		result := &checkResult{Name: c.Name, Passed: c.Status == health.StatusOK, Detail: c.Message}
+		if len(c.Error) > 0 {
+			result.Detail = c.Error
+		}
+		results = append(results, result)
+	}
+	return printCheckResults(results, o.asJSON)
+}
+
+// checkDependencies will return the checkers of the external dependencies, in the order they are printed
+func checkDependencies(conf *config.Config) []health.Checker {
+	checkers := []health.Checker{{Name: "datastore", Critical: true, Check: checkDatastore}}
+	for i := range conf.RPCConnections {
+		node := config.NewNodeConfig(conf.RPCConnections[i].User, conf.RPCConnections[i].Password, conf.RPCConnections[i].Host)
+		checkers = append(checkers, health.Checker{Name: "node " + node.GetRPCHost(), Critical: true, Check: func(ctx context.Context) (string, error) {
+			hash, err := node.BestBlockHash(ctx)
+			if err != nil {
+				return "", err
+			}
+			return "best block " + hash, nil
+		}})
+	}
+	return append(checkers,
+		health.Checker{Name: "p2p key", Critical: true, Check: func(_ context.Context) (string, error) {
+			pk, _, err := p2p.LoadPrivateKey(conf.P2P.PrivateKeyPath, false)
+			if err != nil {
+				return "", err
+			}
+			var peerID peer.ID
+			if peerID, err = peer.IDFromPrivateKey(pk); err != nil {
+				return "", err
+			}
+			return "peer ID " + peerID.String(), nil
+		}},
+		health.Checker{Name: "p2p port", Critical: true, Check: func(_ context.Context) (string, error) {
+			return checkBind(net.JoinHostPort(conf.P2P.IP, conf.P2P.Port))
+		}},
+		health.Checker{Name: "web port", Critical: true, Check: func(_ context.Context) (string, error) {
+			return checkBind(":" + conf.WebServer.Port)
+		}},
+		health.Checker{Name: "bootstrap peers", Critical: true, Check: func(ctx context.Context) (string, error) {
+			connected, total, err := p2p.DialBootstrapPeers(ctx, conf)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%d of %d peers reached", connected, total), nil
+		}},
+	)
+}
+
+// checkDatastore will connect to the datastore and read the latest alert (the tables are not migrated)
+func checkDatastore(ctx context.Context) (string, error) {
+	conf, err := config.LoadDependencies(ctx, nil, false)
+	if err != nil {
+		return "", err
+	}
+	defer conf.CloseAll(ctx)
+
+	var alert *models.AlertMessage
+	if alert, err = models.GetLatestAlert(ctx, nil, model.WithAllDependencies(conf)); err != nil {
+		return "", err
+	} else if alert == nil {
+		return "no alerts stored", nil
+	}
+	return fmt.Sprintf("latest alert %d", alert.SequenceNumber), nil
+}
+
+// checkBind will test that the address can be listened on
+func checkBind(address string) (string, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return "", err
+	}
+	_ = listener.Close()
+	return "can listen on " + address, nil
+}
+
+// printCheckResults will print the results and return the exit code
+func printCheckResults(results []*checkResult, asJSON bool) int {
+	code := exitOK
+	for _, result := range results {
+		if !result.Passed {
+			code = exitError
+		}
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(results)
+		return code
+	}
+	fmt.Printf("%-24s %-6s %s\n", "CHECK", "RESULT", "DETAIL")
+	for _, result := range results {
+		outcome := "pass"
+		if !result.Passed {
+			outcome = "fail"
+		}
+		fmt.Printf("%-24s %-6s %s\n", result.Name, outcome, result.Detail)
+	}
+	return code
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/spf13/cobra"
+)
+
+// exportOptions are the flags of the export command
+type exportOptions struct {
+	configs *configFlags
+	from    uint
+	output  string
+	to      uint
+}
+
+// newExportCommand will create the export command
+func newExportCommand() *cobra.Command {
+	o := &exportOptions{}
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "export the stored alerts as JSON lines",
+		Args:  cobra.NoArgs,
+		RunE:  runCommand(o.run),
+	}
+	o.configs = newConfigFlags(cmd.Flags())
+	cmd.Flags().UintVar(&o.from, "from", 0, "first sequence number exported")
+	cmd.Flags().UintVar(&o.to, "to", 0, "last sequence number exported (0 for the latest)")
+	cmd.Flags().StringVar(&o.output, "output", "", "output file (stdout if empty)")
+	return cmd
+}
+
+// run will write the stored alerts (in sequence order) as JSON lines and return the exit code
+func (o *exportOptions) run() int {
+	ctx := context.Background()
+	conf, err := o.configs.load(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading configuration: %s\n", err.Error())
+		return exitError
+	}
+	defer conf.CloseAll(ctx)
+
+	var alerts []*models.AlertMessage
+	if alerts, err = models.GetAllAlerts(ctx, nil, model.WithAllDependencies(conf)); err != nil {
+		fmt.Fprintf(os.Stderr, "error getting the alerts: %s\n", err.Error())
+		return exitError
+	}
+
+	var w io.Writer = os.Stdout
+	if len(o.output) > 0 {
+		var file *os.File
+		if file, err = os.Create(o.output); err != nil {
+			fmt.Fprintf(os.Stderr, "error creating %s: %s\n", o.output, err.Error())
+			return exitError
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+		w = file
+	}
+
+	encoder := json.NewEncoder(w)
+	exported := 0
+	for _, alert := range alerts {
+		if alert.SequenceNumber < uint32(o.from) || (o.to > 0 && alert.SequenceNumber > uint32(o.to)) {
+			continue
+		}
+		if err = encoder.Encode(alert); err != nil {
+			fmt.Fprintf(os.Stderr, "error exporting alert %d: %s\n", alert.SequenceNumber, err.Error())
+			return exitError
+		}
+		exported++
+	}
+	fmt.Fprintf(os.Stderr, "exported %d alerts\n", exported)
+	return exitOK
+}
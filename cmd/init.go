@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/p2p"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/mrz1836/go-datastore"
+	"github.com/spf13/cobra"
+)
+
+// initNetworks are the networks the wizard can set up (their embedded configuration is the template)
+var initNetworks = []string{config.EnvironmentMainnet, config.EnvironmentTestnet, config.EnvironmentStn}
+
+// initDatastores are the datastore engines the wizard can set up
+var initDatastores = []string{datastore.SQLite.String(), datastore.PostgreSQL.String(), datastore.MySQL.String()}
+
+// initOptions are the flags of the init command
+type initOptions struct {
+	force  bool
+	output string
+}
+
+// newInitCommand will create the init command
+func newInitCommand() *cobra.Command {
+	o := &initOptions{}
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "walk through the setup and write a validated configuration file",
+		Args:  cobra.NoArgs,
+		RunE:  runCommand(o.run),
+	}
+	cmd.Flags().StringVar(&o.output, "output", "config.json", "configuration file written")
+	cmd.Flags().BoolVar(&o.force, "force", false, "overwrite the configuration file if it exists")
+	return cmd
+}
+
+// run will walk a new operator through the network, node RPC, datastore, P2P port and key, then write the
+// configuration file (validated before it is written) and return the exit code
+// The answers are read from stdin, an empty answer (or the end of the input) keeps the default in brackets
+func (o *initOptions) run() int {
+	if _, err := os.Stat(o.output); err == nil && !o.force {
+		fmt.Fprintf(os.Stderr, "%s already exists, use --force to overwrite it\n", o.output)
+		return exitUsage
+	}
+	p := &prompter{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+
+	// Network (the template with its genesis keys, topic and defaults)
+	network := p.choose("Network", initNetworks, config.EnvironmentMainnet)
+	template, err := config.EnvironmentFile(network)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading the %s configuration: %s\n", network, err.Error())
+		return exitError
+	}
+	values := make(map[string]interface{})
+	if err = json.Unmarshal(template, &values); err != nil {
+		fmt.Fprintf(os.Stderr, "error loading the %s configuration: %s\n", network, err.Error())
+		return exitError
+	}
+
+	// Node RPC (bitcoin.conf or credentials)
+	rpcHost := "http://localhost:8332"
+	if connections, ok := values["rpc_connections"].([]interface{}); ok && len(connections) > 0 {
+		if host, hostOK := connections[0].(map[string]interface{})["host"].(string); hostOK {
+			rpcHost = host
+		}
+	}
+	if confPath := p.ask("Path to the bitcoin.conf of the node (empty to enter the RPC credentials)", ""); len(confPath) > 0 {
+		values["bitcoin_config_path"] = confPath
+	} else {
+		values["rpc_connections"] = []interface{}{map[string]interface{}{
+			"host":     p.ask("Node RPC URL", rpcHost),
+			"user":     p.ask("Node RPC user", ""),
+			"password": p.ask("Node RPC password (the input is visible)", ""),
+		}}
+	}
+
+	// Datastore
+	store := section(values, "datastore")
+	engine := p.choose("Datastore", initDatastores, datastore.SQLite.String())
+	store["engine"] = engine
+	if engine == datastore.SQLite.String() {
+		sqlite := section(store, "sqlite")
+		path, _ := sqlite["database_path"].(string)
+		sqlite["database_path"] = p.ask("SQLite database file", path)
+	} else {
+		defaults := section(store, "sql_write")
+		value := func(key string) string {
+			v, _ := defaults[key].(string)
+			return v
+		}
+		port := value("port")
+		if engine == datastore.MySQL.String() {
+			port = "3306"
+		}
+		sql := map[string]string{
+			"driver":   engine,
+			"host":     p.ask("Database host", value("host")),
+			"port":     p.askPort("Database port", port),
+			"name":     p.ask("Database name", value("name")),
+			"user":     p.ask("Database user", value("user")),
+			"password": p.ask("Database password (the input is visible)", ""),
+		}
+		for _, key := range []string{"sql_read", "sql_write"} {
+			for name, value := range sql {
+				section(store, key)[name] = value
+			}
+		}
+	}
+
+	// P2P port and private key
+	peerToPeer := section(values, "p2p")
+	port, _ := peerToPeer["port"].(string)
+	peerToPeer["port"] = p.askPort("P2P port", port)
+	keyPath := filepath.Join(".", config.LocalPrivateKeyDirectory, config.LocalPrivateKeyDefault)
+	if home, homeErr := os.UserHomeDir(); homeErr == nil {
+		keyPath = filepath.Join(home, config.LocalPrivateKeyDirectory, config.LocalPrivateKeyDefault)
+	}
+	keyPath = p.ask("P2P private key file (created if it does not exist)", keyPath)
+	peerToPeer["private_key_path"] = keyPath
+
+	// Validate the configuration before it is written
+	var content []byte
+	if content, err = json.MarshalIndent(values, "", "  "); err != nil {
+		fmt.Fprintf(os.Stderr, "error encoding the configuration: %s\n", err.Error())
+		return exitError
+	}
+	if err = writeValidConfig(o.output, network, append(content, '\n')); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration: %s\n", err.Error())
+		return exitError
+	}
+	fmt.Printf("\nwrote %s\n", o.output)
+
+	// Create the private key (the identity of the peer)
+	if err = os.MkdirAll(filepath.Dir(keyPath), 0o750); err != nil {
+		fmt.Fprintf(os.Stderr, "private key %s: %s\n", keyPath, err.Error())
+		return exitError
+	}
+	pk, generated, err := p2p.LoadPrivateKey(keyPath, true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "private key %s: %s\n", keyPath, err.Error())
+		return exitError
+	}
+	var peerID peer.ID
+	if peerID, err = peer.IDFromPrivateKey(pk); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid private key %s: %s\n", keyPath, err.Error())
+		return exitError
+	}
+	if generated {
+		fmt.Printf("generated private key %s\n", keyPath)
+	}
+	fmt.Printf("peer ID: %s\n\n", peerID.String())
+	fmt.Printf("check the dependencies, then start the alert system:\n")
+	fmt.Printf("  alert-system check --config %s --env %s\n", o.output, network)
+	fmt.Printf("  alert-system serve --config %s --env %s\n", o.output, network)
+	return exitOK
+}
+
+// writeValidConfig will write the configuration file if it is valid for the network (only readable by the owner,
+// it has the credentials). A temporary file is validated then renamed, an existing file is replaced.
+func writeValidConfig(path, network string, content []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o600); err != nil {
+		return err
+	}
+	configs := &configFlags{file: tmp, environment: network}
+	err := configs.apply()
+	if err == nil {
+		_, err = config.ValidateConfigFile()
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Setenv(config.EnvironmentCustomFilePath, path)
+}
+
+// section will return the object of the configuration (created if missing)
+func section(values map[string]interface{}, key string) map[string]interface{} {
+	if object, ok := values[key].(map[string]interface{}); ok {
+		return object
+	}
+	object := make(map[string]interface{})
+	values[key] = object
+	return object
+}
+
+// prompter will ask the questions of the wizard
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask will return the answer to the question (the default if the answer is empty or the input ended)
+func (p *prompter) ask(question, def string) string {
+	if len(def) > 0 {
+		_, _ = fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		_, _ = fmt.Fprintf(p.out, "%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if answer := strings.TrimSpace(line); len(answer) > 0 {
+		return answer
+	} else if errors.Is(err, io.EOF) {
+		_, _ = fmt.Fprintln(p.out)
+	}
+	return def
+}
+
+// choose will return one of the choices (asked again until the answer is a choice)
+func (p *prompter) choose(question string, choices []string, def string) string {
+	for {
+		answer := strings.ToLower(p.ask(question+" ("+strings.Join(choices, ", ")+")", def))
+		for _, choice := range choices {
+			if answer == choice {
+				return choice
+			}
+		}
+		if p.in.Buffered() == 0 && p.ended() {
+			return def
+		}
+		_, _ = fmt.Fprintf(p.out, "%q is not one of %s\n", answer, strings.Join(choices, ", "))
+	}
+}
+
+// askPort will return a port number (asked again until the answer is a port)
+func (p *prompter) askPort(question, def string) string {
+	for {
+		answer := p.ask(question, def)
+		if port, err := strconv.Atoi(answer); err == nil && port > 0 && port < 65536 {
+			return answer
+		}
+		if p.in.Buffered() == 0 && p.ended() {
+			return def
+		}
+		_, _ = fmt.Fprintf(p.out, "%q is not a port number\n", answer)
+	}
+}
+
+// ended will return true if the input ended (no more answers, the defaults are used)
+func (p *prompter) ended() bool {
+	_, err := p.in.Peek(1)
+	return err != nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/p2p"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/spf13/cobra"
+)
+
+// keygenOptions are the flags of the keygen command
+type keygenOptions struct {
+	configs    *configFlags
+	export     bool
+	importFile string
+	path       string
+	rotate     bool
+}
+
+// newKeygenCommand will create the keygen command
+func newKeygenCommand() *cobra.Command {
+	o := &keygenOptions{}
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "create, rotate, import or export the P2P private key and print the peer ID",
+		Args:  cobra.NoArgs,
+		RunE:  runCommand(o.run),
+	}
+	o.configs = newConfigFlags(cmd.Flags())
+	cmd.Flags().StringVar(&o.path, "path", "", "private key file (the configured p2p.private_key_path if empty)")
+	cmd.Flags().BoolVar(&o.rotate, "rotate", false, "back up the private key and replace it with a new key")
+	cmd.Flags().StringVar(&o.importFile, "import", "", "back up the private key and replace it with this key (file or base64, - for stdin)")
+	cmd.Flags().BoolVar(&o.export, "export", false, "print the private key in base64")
+	return cmd
+}
+
+// run will manage the P2P private key at the configured path and return the exit code
+// By default the key is created if it does not exist yet, then the peer ID and addresses are printed
+//
+//	--rotate        backs up the key (<path>.<time>.bak) and replaces it with a new key
+//	--import <file> backs up the key and replaces it with the imported key (file or base64, - for stdin)
+//	--export        prints the key in base64 (only the key, to pipe it into a secrets manager)
+func (o *keygenOptions) run() int {
+	if o.rotate && len(o.importFile) > 0 {
+		fmt.Fprintln(os.Stderr, "--rotate and --import cannot be used together")
+		return exitUsage
+	}
+
+	// The configuration is required for the key path (and the addresses of the peer)
+	var conf *config.Config
+	if err := o.configs.apply(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid flags: %s\n", err.Error())
+		return exitUsage
+	}
+	if loaded, err := config.ValidateConfigFile(); err == nil {
+		conf = loaded
+		if len(o.path) == 0 {
+			o.path = conf.P2P.PrivateKeyPath
+		}
+	} else if len(o.path) == 0 {
+		fmt.Fprintf(os.Stderr, "invalid configuration: %s\n", err.Error())
+		return exitError
+	}
+
+	// Load, rotate or import the key
+	var pk crypto.PrivKey
+	var backup string
+	var generated bool
+	var err error
+	switch {
+	case o.rotate:
+		pk, backup, err = p2p.RotatePrivateKey(o.path)
+	case len(o.importFile) > 0:
+		var key []byte
+		if key, err = readKeyFile(o.importFile); err == nil {
+			pk, backup, err = p2p.ImportPrivateKey(o.path, key)
+		}
+	default:
+		pk, generated, err = p2p.LoadPrivateKey(o.path, true)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "private key %s: %s\n", o.path, err.Error())
+		return exitError
+	}
+
+	// Export the key (nothing else is printed)
+	if o.export {
+		var encoded string
+		if encoded, err = p2p.EncodePrivateKey(pk); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode private key %s: %s\n", o.path, err.Error())
+			return exitError
+		}
+		fmt.Println(encoded)
+		return exitOK
+	}
+
+	var peerID peer.ID
+	if peerID, err = peer.IDFromPrivateKey(pk); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid private key %s: %s\n", o.path, err.Error())
+		return exitError
+	}
+	switch {
+	case generated:
+		fmt.Printf("generated private key %s\n", o.path)
+	case o.rotate:
+		fmt.Printf("rotated private key %s\n", o.path)
+	case len(o.importFile) > 0:
+		fmt.Printf("imported private key %s\n", o.path)
+	}
+	if len(backup) > 0 {
+		fmt.Printf("previous key backed up to %s\n", backup)
+	}
+	fmt.Printf("peer ID: %s\n", peerID.String())
+	if conf != nil {
+		for _, address := range p2p.PeerAddresses(conf, peerID) {
+			fmt.Printf("address: %s\n", address)
+		}
+	}
+	return exitOK
+}
+
+// readKeyFile will read the key to import, or the genesis alert or signing keys (- for stdin)
+func readKeyFile(name string) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(name) //nolint:gosec // This is a local private key
+}
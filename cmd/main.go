@@ -3,95 +3,170 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
 	"os"
-	"os/signal"
+	"strconv"
+	"strings"
 
 	"github.com/bitcoin-sv/alert-system/app/config"
 	"github.com/bitcoin-sv/alert-system/app/models"
-	"github.com/bitcoin-sv/alert-system/app/models/model"
-	"github.com/bitcoin-sv/alert-system/app/p2p"
-	"github.com/bitcoin-sv/alert-system/app/webserver"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
+// Command exit codes (the status command has its own)
+const (
+	exitOK    = 0 // Command succeeded
+	exitError = 1 // Command failed
+	exitUsage = 2 // Unknown command or invalid flags
+)
+
+// exitCode is the error of a command exiting with a code other than exitOK (already reported by the command)
+type exitCode int
+
+// Error will return the exit code as text
+func (c exitCode) Error() string {
+	return "exit code " + strconv.Itoa(int(c))
+}
+
+// newRootCommand will create the alert-system command and its subcommands
+// The commands are cobra commands, the flags are double-dash (the single-dash flags of the existing scripts and
+// service registrations are rewritten by run)
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:               "alert-system [command] [flags]",
+		Short:             "Bitcoin SV alert system",
+		Long:              "Bitcoin SV alert system (serve is run if no command is given, alert-system --version prints the build info)",
+		CompletionOptions: cobra.CompletionOptions{DisableDefaultCmd: true},
+		SilenceErrors:     true, // Printed by run, with the usage hint
+		SilenceUsage:      true,
+	}
+	root.AddCommand(
+		newServeCommand(),
+		newInitCommand(),
+		newValidateConfigCommand(),
+		newCheckCommand(),
+		newKeygenCommand(),
+		newBootstrapGenesisCommand(),
+		newMigrateCommand(),
+		newExportCommand(),
+		newReplayCommand(),
+		newSimulateCommand(),
+		newReconcileCommand(),
+		newServiceCommand(),
+		newProbeCommand(),
+		newStatusCommand(),
+		newVersionCommand(),
+	)
+	return root
+}
+
 // main is the entry point for the alert-system
 func main() {
+	// Started by the Windows service manager (the args are the registered serve command)
+	if code, ok := runWindowsService(os.Args[1:]); ok {
+		os.Exit(code)
+	}
+	os.Exit(run(context.Background(), os.Args[1:]))
+}
 
-	// Load the configuration and services
-	_appConfig, err := config.LoadDependencies(context.Background(), models.BaseModels, false)
-	if err != nil {
-		log.Fatalf("error loading configuration: %s", err.Error())
+// run will run the command named by the first argument and return the exit code
+// Without a command (or with flags only) the alert system is served, like before the commands existed
+func run(ctx context.Context, args []string) int {
+	args = normalizeArgs(args)
+	if len(args) > 0 && args[0] == "--version" { // Like most binaries
+		args = append([]string{"version"}, args[1:]...)
+	} else if len(args) == 0 || (strings.HasPrefix(args[0], "-") && args[0] != "-h" && args[0] != "--help") {
+		args = append([]string{"serve"}, args...)
 	}
-	defer func() {
-		_appConfig.CloseAll(context.Background())
-	}()
-
-	// Ensure we have the genesis alert in the database
-	if err = models.CreateGenesisAlert(
-		context.Background(), model.WithAllDependencies(_appConfig),
-	); err != nil {
-		_appConfig.Services.Log.Fatalf("error creating genesis alert: %s", err.Error())
+
+	root := newRootCommand()
+	root.SetArgs(args)
+	err := root.ExecuteContext(ctx)
+	var code exitCode
+	if errors.As(err, &code) {
+		return int(code)
+	} else if err != nil { // Unknown command, invalid flags or arguments
+		fmt.Fprintf(os.Stderr, "%s\nrun 'alert-system --help' for the commands, or 'alert-system <command> --help' for the flags of a command\n", err.Error())
+		return exitUsage
 	}
+	return exitOK
+}
 
-	// Ensure that RPC connection is valid
-	if !_appConfig.DisableRPCVerification {
-		if _, err = _appConfig.Services.Node.BestBlockHash(context.Background()); err != nil {
-			_appConfig.Services.Log.Errorf("error talking to Bitcoin node with supplied RPC credentials: %s", err.Error())
-			return
+// normalizeArgs will rewrite the single-dash long flags (e.g. -config) to double-dash flags (--config)
+// The negative numbers, the - argument (stdin) and the arguments after -- are kept
+func normalizeArgs(args []string) []string {
+	normalized := make([]string, 0, len(args))
+	for i, arg := range args {
+		if arg == "--" {
+			return append(normalized, args[i:]...)
 		}
+		if len(arg) > 2 && arg[0] == '-' && arg[1] != '-' && (arg[1] < '0' || arg[1] > '9') {
+			arg = "-" + arg
+		}
+		normalized = append(normalized, arg)
 	}
+	return normalized
+}
 
-	// Create the p2p server
-	var p2pServer *p2p.Server
-	if p2pServer, err = p2p.NewServer(p2p.ServerOptions{
-		TopicNames: []string{_appConfig.P2P.TopicName},
-		Config:     _appConfig,
-	}); err != nil {
-		_appConfig.Services.Log.Fatalf("error creating p2p server: %s", err.Error())
+// runCommand will run the command returning its exit code as a cobra command
+func runCommand(run func() int) func(*cobra.Command, []string) error {
+	return func(_ *cobra.Command, _ []string) error {
+		return exitWith(run())
 	}
+}
 
-	// Create a new (web) server
-	webServer := webserver.NewServer(_appConfig)
-
-	// Sync a channel to listen for interrupts
-	idleConnectionsClosed := make(chan struct{})
-	go func(appConfig *config.Config) {
-		sigint := make(chan os.Signal, 1)
-		signal.Notify(sigint, os.Interrupt)
-
-		// Log when a signal is received
-		appConfig.Services.Log.Info("waiting for interrupt signal")
-		<-sigint
+// exitWith will return the error of the exit code (nil for exitOK)
+func exitWith(code int) error {
+	if code == exitOK {
+		return nil
+	}
+	return exitCode(code)
+}
 
-		// Log that we are starting the shutdown process
-		appConfig.Services.Log.Info("interrupt signal received, starting shutdown process")
+// configFlags are the flags shared by the commands that load the configuration
+type configFlags struct {
+	environment string
+	file        string
+}
 
-		// We received an interrupt signal, shut down the server
-		ctxTimeout, cancel := context.WithTimeout(context.Background(), config.DefaultServerShutdown)
-		defer cancel()
-		if err = webServer.Shutdown(ctxTimeout); err != nil {
-			appConfig.Services.Log.Infof("error shutting down webserver: %s", err.Error())
-		}
+// newConfigFlags will register the shared configuration flags on the command flags
+func newConfigFlags(flags *pflag.FlagSet) *configFlags {
+	c := &configFlags{}
+	flags.StringVar(&c.file, "config", "", "config file path (overrides "+config.EnvironmentCustomFilePath+")")
+	flags.StringVar(&c.environment, "env", "", "environment, e.g. mainnet or testnet (overrides "+config.EnvironmentKey+")")
+	return c
+}
 
-		// Shutdown the p2p server
-		if err = p2pServer.Stop(ctxTimeout); err != nil {
-			appConfig.Services.Log.Infof("error shutting down p2p server: %s", err.Error())
+// apply will set the environment variables read by the config loader
+func (c *configFlags) apply() error {
+	if len(c.file) > 0 {
+		if err := os.Setenv(config.EnvironmentCustomFilePath, c.file); err != nil {
+			return err
 		}
-
-		close(idleConnectionsClosed)
-		if err = appConfig.Services.Log.CloseWriter(); err != nil {
-			log.Printf("error closing logger: %s", err)
+	}
+	if len(c.environment) > 0 {
+		if err := os.Setenv(config.EnvironmentKey, c.environment); err != nil {
+			return err
 		}
-	}(_appConfig)
-
-	// Start the p2p server
-	if err = p2pServer.Start(context.Background()); err != nil {
-		_appConfig.Services.Log.Fatalf("error starting p2p server: %s", err.Error())
 	}
+	return nil
+}
 
-	// Serve the web server and then wait endlessly
-	webServer.Serve()
+// load will load the configuration and services (node, datastore, ...)
+func (c *configFlags) load(ctx context.Context) (*config.Config, error) {
+	if err := c.apply(); err != nil {
+		return nil, err
+	}
+	return config.LoadDependencies(ctx, models.BaseModels, false)
+}
 
-	// Wait for the idle connection to close
-	<-idleConnectionsClosed
+// loadWithoutMigration will load the configuration and services without migrating the datastore
+// (for the commands that inspect or change the tables themselves)
+func (c *configFlags) loadWithoutMigration(ctx context.Context) (*config.Config, error) {
+	if err := c.apply(); err != nil {
+		return nil, err
+	}
+	return config.LoadDependencies(ctx, nil, false)
 }
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parseCommand will find the command of the args and parse its flags (without running it)
+func parseCommand(t *testing.T, args ...string) *cobra.Command {
+	cmd, rest, err := newRootCommand().Find(normalizeArgs(args))
+	require.NoError(t, err)
+	require.NoError(t, cmd.ParseFlags(rest))
+	return cmd
+}
+
+// TestNormalizeArgs will test rewriting the single-dash long flags
+func TestNormalizeArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{"no args", []string{}, []string{}},
+		{"single-dash flags", []string{"check", "-config", "config.json", "-env=mainnet"}, []string{"check", "--config", "config.json", "--env=mainnet"}},
+		{"double-dash flags", []string{"version", "--json"}, []string{"version", "--json"}},
+		{"shorthand flag", []string{"check", "-h"}, []string{"check", "-h"}},
+		{"stdin and negative number", []string{"keygen", "-import", "-", "-1"}, []string{"keygen", "--import", "-", "-1"}},
+		{"arguments after --", []string{"serve", "--", "-devnet"}, []string{"serve", "--", "-devnet"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, normalizeArgs(test.args))
+		})
+	}
+}
+
+// TestNewRootCommand will test parsing the commands and their flags
+func TestNewRootCommand(t *testing.T) {
+	t.Parallel()
+
+	t.Run("config flags", func(t *testing.T) {
+		cmd := parseCommand(t, "check", "--config", "config.json", "-env", "testnet", "--timeout", "3s")
+		assert.Equal(t, "check", cmd.Name())
+		assert.Equal(t, "config.json", cmd.Flags().Lookup("config").Value.String())
+		assert.Equal(t, "testnet", cmd.Flags().Lookup("env").Value.String())
+		assert.Equal(t, "3s", cmd.Flags().Lookup("timeout").Value.String())
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		cmd := parseCommand(t, "replay")
+		assert.Equal(t, "1", cmd.Flags().Lookup("from").Value.String())
+		assert.Equal(t, "0", cmd.Flags().Lookup("to").Value.String())
+		assert.Equal(t, "false", cmd.Flags().Lookup("dry-run").Value.String())
+	})
+
+	t.Run("every command is registered", func(t *testing.T) {
+		for _, name := range []string{
+			"serve", "init", "validate-config", "check", "keygen", "bootstrap-genesis", "migrate", "export",
+			"replay", "simulate", "reconcile", "service", "probe", "status", "version",
+		} {
+			assert.Equal(t, name, parseCommand(t, name).Name())
+		}
+	})
+
+	t.Run("unknown flag", func(t *testing.T) {
+		cmd, rest, err := newRootCommand().Find([]string{"version", "--yaml"})
+		require.NoError(t, err)
+		require.Error(t, cmd.ParseFlags(rest))
+	})
+}
+
+// TestRun will test the exit codes of the commands
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected int
+	}{
+		{"help", []string{"--help"}, exitOK},
+		{"version", []string{"version", "--json"}, exitOK},
+		{"version flag", []string{"-version"}, exitOK},
+		{"unknown command", []string{"unknown"}, exitUsage},
+		{"unknown flag", []string{"version", "--yaml"}, exitUsage},
+		{"unexpected argument", []string{"version", "json"}, exitUsage},
+		{"invalid migration", []string{"migrate", "sideways"}, exitUsage},
+		{"probe without endpoint", []string{"probe"}, exitUsage},
+		{"conflicting flags", []string{"keygen", "--rotate", "--import", "key.txt"}, exitUsage},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, run(context.Background(), test.args))
+		})
+	}
+}
+
+// TestConfigFlags_apply will test setting the environment variables read by the config loader
+func TestConfigFlags_apply(t *testing.T) {
+	t.Setenv(config.EnvironmentCustomFilePath, "")
+	t.Setenv(config.EnvironmentKey, "mainnet")
+
+	// Unset flags keep the environment
+	require.NoError(t, (&configFlags{}).apply())
+	assert.Empty(t, os.Getenv(config.EnvironmentCustomFilePath))
+	assert.Equal(t, "mainnet", os.Getenv(config.EnvironmentKey))
+
+	require.NoError(t, (&configFlags{environment: "testnet", file: "config.json"}).apply())
+	assert.Equal(t, "config.json", os.Getenv(config.EnvironmentCustomFilePath))
+	assert.Equal(t, "testnet", os.Getenv(config.EnvironmentKey))
+}
+
+// TestValidateConfig will test loading the configuration of the flags
+func TestValidateConfig(t *testing.T) {
+	t.Setenv(config.EnvironmentCustomFilePath, "")
+	t.Setenv(config.EnvironmentKey, "")
+
+	t.Run("environment flag", func(t *testing.T) {
+		assert.Equal(t, exitOK, run(context.Background(), []string{"validate-config", "--env", config.EnvironmentTest}))
+		assert.Equal(t, config.EnvironmentTest, os.Getenv(config.EnvironmentKey))
+	})
+
+	t.Run("single-dash config file flag", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(file, []byte("{invalid"), 0o600))
+		assert.Equal(t, exitError, run(context.Background(), []string{"validate-config", "-config", file, "-env", config.EnvironmentTest}))
+		assert.Equal(t, file, os.Getenv(config.EnvironmentCustomFilePath))
+	})
+}
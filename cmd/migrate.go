@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/spf13/cobra"
+)
+
+// migrateOptions are the flags of the migrate command
+type migrateOptions struct {
+	asJSON  bool
+	configs *configFlags
+	yes     bool
+}
+
+// newMigrateCommand will create the migrate command
+func newMigrateCommand() *cobra.Command {
+	o := &migrateOptions{}
+	cmd := &cobra.Command{
+		Use:       "migrate [up|down|status]",
+		Short:     "create or update (up), drop (down) or list (status) the datastore tables",
+		Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{"up", "down", "status"},
+		RunE: func(_ *cobra.Command, args []string) error {
+			action := "up"
+			if len(args) > 0 {
+				action = args[0]
+			}
+			return exitWith(o.run(action))
+		},
+	}
+	o.configs = newConfigFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&o.asJSON, "json", false, "print the status as JSON (status)")
+	cmd.Flags().BoolVar(&o.yes, "yes", false, "confirm dropping the tables (down)")
+	return cmd
+}
+
+// run will run the datastore migration (up, down or status) and return the exit code
+// Cluster deployments can set datastore.auto_migrate to false and run it once (as a job)
+// instead of every instance migrating at startup
+//
+//	up     creates or updates the tables of the models (the default)
+//	down   drops the tables of the models (all the data is lost, --yes is required)
+//	status prints the tables of the models and whether they exist (exit code 1 if a table is missing)
+func (o *migrateOptions) run(action string) int {
+	if action == "down" && !o.yes {
+		fmt.Fprintln(os.Stderr, "migrate down drops the tables and all the alerts, run it with --yes to confirm")
+		return exitUsage
+	}
+
+	// The datastore is not migrated when loading, the action does it
+	ctx := context.Background()
+	conf, err := o.configs.loadWithoutMigration(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading configuration: %s\n", err.Error())
+		return exitError
+	}
+	defer conf.CloseAll(ctx)
+
+	switch action {
+	case "down":
+		if err = models.DropSchema(conf.Services.Datastore); err != nil {
+			fmt.Fprintf(os.Stderr, "error dropping the tables: %s\n", err.Error())
+			return exitError
+		}
+		fmt.Printf("dropped %d tables\n", len(models.BaseModels))
+	case "status":
+		return printSchemaStatus(models.GetSchemaStatus(ctx, conf.Services.Datastore), o.asJSON)
+	default:
+		if err = conf.Services.Datastore.AutoMigrateDatabase(ctx, models.BaseModels...); err != nil {
+			fmt.Fprintf(os.Stderr, "error migrating the datastore: %s\n", err.Error())
+			return exitError
+		}
+		fmt.Printf("migrated %d models\n", len(models.BaseModels))
+	}
+	return exitOK
+}
+
+// printSchemaStatus will print the tables and return the exit code (1 if a table is missing)
+func printSchemaStatus(tables []*models.SchemaTable, asJSON bool) int {
+	code := exitOK
+	for _, table := range tables {
+		if !table.Exists {
+			code = exitError
+		}
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(tables)
+		return code
+	}
+	fmt.Printf("%-20s %-28s %-8s %s\n", "MODEL", "TABLE", "STATUS", "ROWS")
+	for _, table := range tables {
+		if table.Exists {
+			fmt.Printf("%-20s %-28s %-8s %d\n", table.Model, table.Table, "ok", table.Rows)
+		} else {
+			fmt.Printf("%-20s %-28s %-8s %s\n", table.Model, table.Table, "missing", table.Error)
+		}
+	}
+	return code
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Probe command exit codes (what container healthchecks and exec probes expect)
+const (
+	probeExitHealthy   = 0 // The endpoint responded with a 2xx
+	probeExitUnhealthy = 1 // The endpoint failed or could not be reached
+)
+
+// probeOptions are the flags of the probe command
+type probeOptions struct {
+	baseURL string
+	live    bool
+	ready   bool
+	socket  string
+	timeout time.Duration
+}
+
+// newProbeCommand will create the probe command
+func newProbeCommand() *cobra.Command {
+	o := &probeOptions{}
+	cmd := &cobra.Command{
+		Use:   "probe",
+		Short: "exit 0 if the local alert system is live (--live) or ready (--ready), 1 if not",
+		Args:  cobra.NoArgs,
+		RunE:  runCommand(o.run),
+	}
+	cmd.Flags().BoolVar(&o.ready, "ready", false, "probe the readiness (/readyz)")
+	cmd.Flags().BoolVar(&o.live, "live", false, "probe the liveness (/livez)")
+	cmd.Flags().StringVar(&o.baseURL, "url", "http://localhost:3000", "URL of the web server of the alert system")
+	cmd.Flags().StringVar(&o.socket, "socket", os.Getenv(envAdminSocket), "admin socket of the alert system (instead of the URL)")
+	cmd.Flags().DurationVar(&o.timeout, "timeout", 3*time.Second, "max time to wait for the response")
+	return cmd
+}
+
+// run will check the liveness (--live, the web server is serving) or the readiness (--ready, no critical check
+// failed) of the local alert system and return the exit code, for a Docker HEALTHCHECK or a Kubernetes exec probe
+// in images without curl. The endpoint is requested on the admin socket if set.
+func (o *probeOptions) run() int {
+	path := "/livez"
+	if o.ready == o.live {
+		fmt.Fprintln(os.Stderr, "set one of --ready or --live")
+		return exitUsage
+	} else if o.ready {
+		path = "/readyz"
+	}
+
+	client, base := http.DefaultClient, strings.TrimRight(o.baseURL, "/")
+	if len(o.socket) > 0 {
+		client, base = socketClient(o.socket), "http://"+socketHost
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+path, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid url: %s\n", err.Error())
+		return exitUsage
+	}
+	var res *http.Response
+	if res, err = client.Do(req); err != nil {
+		fmt.Printf("%s: not reachable: %s\n", path, err.Error())
+		return probeExitUnhealthy
+	}
+	_ = res.Body.Close()
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		fmt.Printf("%s: %s\n", path, res.Status)
+		return probeExitUnhealthy
+	}
+	fmt.Printf("%s: ok\n", path)
+	return probeExitHealthy
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/app/reconcile"
+	"github.com/spf13/cobra"
+)
+
+// reconcileResult is the output of the reconcile command
+type reconcileResult struct {
+	Alerts      int                    `json:"alerts"`      // Processed alerts the expected state is computed from
+	Differences []*reconcileDifference `json:"differences"` // Differences with the node
+	Node        string                 `json:"node"`        // RPC host of the node
+	Unprocessed int                    `json:"unprocessed"` // Alerts not processed (see the replay command)
+	Unreadable  []uint32               `json:"unreadable"`  // Sequences of the alerts which could not be read
+}
+
+// reconcileDifference is a difference with the node and the result of applying it
+type reconcileDifference struct {
+	*reconcile.Difference
+	Applied bool   `json:"applied"`
+	Error   string `json:"error,omitempty"`
+}
+
+// reconcileOptions are the flags of the reconcile command
+type reconcileOptions struct {
+	apply   bool
+	asJSON  bool
+	configs *configFlags
+}
+
+// newReconcileCommand will create the reconcile command
+func newReconcileCommand() *cobra.Command {
+	o := &reconcileOptions{}
+	cmd := &cobra.Command{
+		Use:   "reconcile",
+		Short: "compare the node with the bans, invalid blocks and frozen funds of the alerts",
+		Args:  cobra.NoArgs,
+		RunE:  runCommand(o.run),
+	}
+	o.configs = newConfigFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&o.apply, "apply", false, "apply the differences to the node (only printed by default)")
+	cmd.Flags().BoolVar(&o.asJSON, "json", false, "print the differences as JSON")
+	return cmd
+}
+
+// run will compare the node with the state the processed alerts set (banned peers, invalidated blocks and frozen
+// funds) and return the exit code, the differences are applied with --apply (e.g. after restoring the node from a
+// snapshot). The exit code is an error if differences are left.
+func (o *reconcileOptions) run() int {
+	ctx := context.Background()
+	conf, err := o.configs.load(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading configuration: %s\n", err.Error())
+		return exitError
+	}
+	defer conf.CloseAll(ctx)
+
+	// Compute the expected state from the alerts and compare it to the node
+	var alerts []*models.AlertMessage
+	if alerts, err = models.GetAllAlerts(ctx, nil, model.WithAllDependencies(conf)); err != nil {
+		fmt.Fprintf(os.Stderr, "error getting the alerts: %s\n", err.Error())
+		return exitError
+	}
+	result := &reconcileResult{Node: conf.Services.Node.GetRPCHost()}
+	bySequence := make(map[uint32]*models.AlertMessage, len(alerts))
+	for _, alert := range alerts {
+		if alert.Processed {
+			result.Alerts++
+		} else {
+			result.Unprocessed++
+		}
+		bySequence[alert.SequenceNumber] = alert
+	}
+	expected := reconcile.Expect(alerts, time.Now().UTC())
+	result.Unreadable = expected.Unreadable
+	var differences []*reconcile.Difference
+	if differences, err = reconcile.Compare(ctx, conf.Services.Node, expected); err != nil {
+		fmt.Fprintf(os.Stderr, "error reading the node state: %s\n", err.Error())
+		return exitError
+	}
+
+	// Apply the differences (recorded as node actions of their alerts)
+	left := 0
+	for _, difference := range differences {
+		d := &reconcileDifference{Difference: difference}
+		result.Differences = append(result.Differences, d)
+		if !o.apply {
+			left++
+			continue
+		}
+		applyErr := reconcile.Apply(ctx, conf.Services.Node, difference)
+		if alert := bySequence[difference.Sequence]; alert != nil {
+			if _, err = models.RecordNodeAction(ctx, alert, applyErr, model.WithAllDependencies(conf)); err != nil {
+				fmt.Fprintf(os.Stderr, "alert %d: failed to record the node action: %s\n", alert.SequenceNumber, err.Error())
+			}
+		}
+		if applyErr != nil {
+			d.Error = applyErr.Error()
+			left++
+			continue
+		}
+		d.Applied = true
+	}
+
+	if o.asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(result)
+	} else {
+		printReconcile(result, o.apply)
+	}
+	if left > 0 {
+		return exitError
+	}
+	return exitOK
+}
+
+// printReconcile will print the differences, one per line
+func printReconcile(result *reconcileResult, apply bool) {
+	for _, d := range result.Differences {
+		switch {
+		case d.Applied:
+			fmt.Printf("%s: applied\n", d.String())
+		case len(d.Error) > 0:
+			fmt.Printf("%s: failed: %s\n", d.String(), d.Error)
+		default:
+			fmt.Println(d.String())
+		}
+	}
+	for _, sequence := range result.Unreadable {
+		fmt.Printf("alert %d: could not be read, skipped\n", sequence)
+	}
+	if result.Unprocessed > 0 {
+		fmt.Printf("%d alerts were not processed, run replay to execute them\n", result.Unprocessed)
+	}
+	switch {
+	case len(result.Differences) == 0:
+		fmt.Printf("%s is in sync with the %d processed alerts\n", result.Node, result.Alerts)
+	case !apply:
+		fmt.Printf("%d differences with %s, run with --apply to apply them\n", len(result.Differences), result.Node)
+	}
+}
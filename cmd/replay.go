@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/spf13/cobra"
+)
+
+// replayOptions are the flags of the replay command
+type replayOptions struct {
+	configs *configFlags
+	dryRun  bool
+	from    uint
+	to      uint
+}
+
+// newReplayCommand will create the replay command
+func newReplayCommand() *cobra.Command {
+	o := &replayOptions{}
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "execute the stored alerts against the node again",
+		Args:  cobra.NoArgs,
+		RunE:  runCommand(o.run),
+	}
+	o.configs = newConfigFlags(cmd.Flags())
+	cmd.Flags().UintVar(&o.from, "from", 1, "first sequence number replayed (the genesis alert is 0)")
+	cmd.Flags().UintVar(&o.to, "to", 0, "last sequence number replayed (0 for the latest)")
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", false, "print the alerts that would be replayed without executing them")
+	return cmd
+}
+
+// run will execute the stored alerts against the node again (in sequence order) and return the exit code
+// Useful after restoring the node, unprocessed alerts that succeed are marked processed
+func (o *replayOptions) run() int {
+	ctx := context.Background()
+	conf, err := o.configs.load(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading configuration: %s\n", err.Error())
+		return exitError
+	}
+	defer conf.CloseAll(ctx)
+
+	var alerts []*models.AlertMessage
+	if alerts, err = models.GetAllAlerts(ctx, nil, model.WithAllDependencies(conf)); err != nil {
+		fmt.Fprintf(os.Stderr, "error getting the alerts: %s\n", err.Error())
+		return exitError
+	}
+
+	failed := 0
+	for _, alert := range alerts {
+		if alert.SequenceNumber < uint32(o.from) || (o.to > 0 && alert.SequenceNumber > uint32(o.to)) {
+			continue
+		}
+		if err = alert.ReadRaw(); err != nil {
+			fmt.Fprintf(os.Stderr, "alert %d: invalid raw alert: %s\n", alert.SequenceNumber, err.Error())
+			failed++
+			continue
+		}
+		if o.dryRun {
+			fmt.Printf("alert %d (type %d): would be replayed\n", alert.SequenceNumber, alert.GetAlertType())
+			continue
+		}
+		if err = replayAlert(ctx, conf, alert); err != nil {
+			fmt.Printf("alert %d (type %d): failed: %s\n", alert.SequenceNumber, alert.GetAlertType(), err.Error())
+			failed++
+			continue
+		}
+		fmt.Printf("alert %d (type %d): ok\n", alert.SequenceNumber, alert.GetAlertType())
+	}
+	if failed > 0 {
+		return exitError
+	}
+	return exitOK
+}
+
+// replayAlert will execute the alert action against the node and record the result
+func replayAlert(ctx context.Context, conf *config.Config, alert *models.AlertMessage) error {
+	ak := alert.ProcessAlertMessage()
+	if ak == nil {
+		return nil // Alert type without an action
+	}
+	if err := ak.Read(alert.GetRawMessage()); err != nil {
+		return err
+	}
+	actionErr := ak.Do(ctx)
+	if _, err := models.RecordNodeAction(ctx, alert, actionErr, model.WithAllDependencies(conf)); err != nil {
+		fmt.Fprintf(os.Stderr, "alert %d: failed to record the node action: %s\n", alert.SequenceNumber, err.Error())
+	}
+	if actionErr != nil || alert.Processed {
+		return actionErr
+	}
+	alert.Processed = true
+	return alert.Save(ctx)
+}
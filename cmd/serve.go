@@ -0,0 +1,448 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/bitcoin-sv/alert-system/app/audit"
+	"github.com/bitcoin-sv/alert-system/app/budget"
+	"github.com/bitcoin-sv/alert-system/app/buildinfo"
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/handoff"
+	"github.com/bitcoin-sv/alert-system/app/instance"
+	"github.com/bitcoin-sv/alert-system/app/metrics"
+	"github.com/bitcoin-sv/alert-system/app/models"
+	"github.com/bitcoin-sv/alert-system/app/models/model"
+	"github.com/bitcoin-sv/alert-system/app/p2p"
+	"github.com/bitcoin-sv/alert-system/app/profiling"
+	"github.com/bitcoin-sv/alert-system/app/reporting"
+	"github.com/bitcoin-sv/alert-system/app/shutdown"
+	"github.com/bitcoin-sv/alert-system/app/systemd"
+	"github.com/bitcoin-sv/alert-system/app/tracing"
+	"github.com/bitcoin-sv/alert-system/app/webserver"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+)
+
+// serveOptions are the flags of the serve command
+type serveOptions struct {
+	configs *configFlags
+	devnet  bool
+}
+
+// newServeCommand will create the serve command
+func newServeCommand() *cobra.Command {
+	o := &serveOptions{}
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "start the alert system (P2P, web server and alert processing)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return exitWith(o.run(cmd.Context()))
+		},
+	}
+	o.configs = newConfigFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&o.devnet, "devnet", false, "run a local devnet (throwaway genesis keys, mock node and in-memory datastore)")
+	return cmd
+}
+
+// run will start the alert system and return the exit code once it is shut down
+// (interrupt signal or the context is done, e.g. the Windows service is stopped)
+func (o *serveOptions) run(ctx context.Context) int {
+	if o.devnet {
+		o.configs.environment = config.EnvironmentDevnet
+	}
+
+	// Load the configuration and services
+	_appConfig, err := o.configs.load(ctx)
+	if err != nil {
+		log.Printf("error loading configuration: %s", err.Error())
+		return exitError
+	}
+
+	// Closed once, by the shutdown manager or on an early return
+	var closeOnce sync.Once
+	closeAll := func(ctx context.Context) {
+		closeOnce.Do(func() {
+			_appConfig.CloseAll(ctx)
+		})
+	}
+	defer closeAll(context.Background())
+
+	// Report a crash before exiting (if error reporting is enabled)
+	defer reporting.Recover(_appConfig.Services.Reporter, nil)
+
+	// Log the build that is running
+	_appConfig.Services.Log.Infof("starting alert-system %s", buildinfo.Get().String())
+
+	// Print the throwaway genesis keys of the devnet (the alerts are signed by POST /api/v1/devnet/alerts)
+	if signer := _appConfig.Services.Devnet; signer != nil {
+		_appConfig.Services.Log.Warnf("running a devnet, never use its throwaway genesis keys on a real network")
+		for i := range signer.PublicKeys {
+			_appConfig.Services.Log.Infof("devnet genesis key %d: public %s private %s", i+1, signer.PublicKeys[i], signer.PrivateKeys[i])
+		}
+	}
+
+	// Faults are injected on purpose (never on mainnet, see the chaos config)
+	if c := _appConfig.Chaos; c.Enabled {
+		_appConfig.Services.Log.Warnf(
+			"chaos enabled: dropping %.1f%% of the gossip, delaying sync responses by %s, failing every %d node rpc calls",
+			c.GossipDropPercent, c.SyncDelay, c.RPCFailEvery,
+		)
+	}
+
+	// Ensure no other instance is enforcing with the same identity (and write the PID file)
+	lockFile := _appConfig.Instance.LockFile
+	if _appConfig.Instance.DisableLock {
+		lockFile = ""
+	}
+	// (the lock is inherited from the previous process on a restart)
+	var guard *instance.Guard
+	if lock := handoff.File("lock"); lock != nil {
+		guard, err = instance.Adopt(lock, _appConfig.Instance.PIDFile)
+	} else {
+		guard, err = instance.Acquire(lockFile, _appConfig.Instance.PIDFile)
+	}
+	if err != nil {
+		_appConfig.Services.Log.Errorf("error starting alert-system: %s", err.Error())
+		return exitError
+	}
+	defer func() {
+		if err = guard.Release(); err != nil {
+			log.Printf("error releasing the instance lock: %s", err.Error())
+		}
+	}()
+
+	// Start tracing the alert pipeline (exported via OTLP)
+	var tracerProvider *tracing.Provider
+	if _appConfig.Tracing.Enabled {
+		if tracerProvider, err = tracing.NewProvider(ctx, tracing.Options{
+			Endpoint:    _appConfig.Tracing.Endpoint,
+			SampleRatio: _appConfig.Tracing.SampleRatio,
+			ServiceName: _appConfig.Tracing.ServiceName,
+		}); err != nil {
+			log.Printf("error starting the tracing: %s", err.Error())
+			return exitError
+		}
+		otel.SetTracerProvider(tracerProvider)
+	}
+
+	// Start pushing the metrics to the StatsD agent
+	var statsd *metrics.StatsD
+	if len(_appConfig.StatsD.Address) > 0 {
+		if statsd, err = metrics.NewStatsD(metrics.StatsDOptions{
+			Address:   _appConfig.StatsD.Address,
+			DogStatsD: _appConfig.StatsD.DogStatsD,
+			Interval:  _appConfig.StatsD.Interval,
+			OnError: func(err error) {
+				_appConfig.Services.Log.Errorf("error pushing metrics to statsd: %s", err.Error())
+			},
+			Prefix: _appConfig.StatsD.Prefix,
+		}); err != nil {
+			_appConfig.Services.Log.Fatalf("error connecting to statsd: %s", err.Error())
+		}
+		statsd.Start()
+	}
+
+	// Capture the profiles under sustained resource pressure (if enabled)
+	var watchdog *profiling.Watchdog
+	if _appConfig.Profiling.Enabled {
+		watchdog = newProfilingWatchdog(_appConfig)
+		watchdog.Start()
+	}
+
+	// Enforce the memory and goroutine budget (protects a node running on the same host)
+	budgetMonitor := newBudgetMonitor(_appConfig)
+	budgetMonitor.Start()
+
+	// Start the audit log and record the loaded configuration
+	if _appConfig.Audit.Enabled {
+		if _appConfig.Services.Audit, err = newAuditLog(context.Background(), _appConfig); err != nil {
+			_appConfig.Services.Log.Fatalf("error loading audit log: %s", err.Error())
+		}
+		if err = _appConfig.Services.Audit.Record(
+			context.Background(), audit.EventConfigLoaded, audit.ActorSystem,
+			os.Getenv(config.EnvironmentKey), configDetails(_appConfig),
+		); err != nil {
+			_appConfig.Services.Log.Fatalf("error recording configuration in the audit log: %s", err.Error())
+		}
+	}
+
+	// Ensure we have the genesis alert in the database
+	if err = models.CreateGenesisAlert(
+		context.Background(), model.WithAllDependencies(_appConfig),
+	); err != nil {
+		_appConfig.Services.Log.Fatalf("error creating genesis alert: %s", err.Error())
+	}
+
+	// Index the searchable fields of any alerts saved before search was added
+	if err = models.IndexAlertHistory(
+		context.Background(), model.WithAllDependencies(_appConfig),
+	); err != nil {
+		_appConfig.Services.Log.Errorf("error indexing alert history for search: %s", err.Error())
+	}
+
+	// Ensure that RPC connection is valid
+	if !_appConfig.DisableRPCVerification {
+		if _, err = _appConfig.Services.Node.BestBlockHash(context.Background()); err != nil {
+			_appConfig.Services.Log.Errorf("error talking to Bitcoin node with supplied RPC credentials: %s", err.Error())
+			return exitError
+		}
+	}
+
+	// Create the p2p server
+	var p2pServer *p2p.Server
+	if p2pServer, err = p2p.NewServer(p2p.ServerOptions{
+		TopicNames:       []string{_appConfig.P2P.TopicName},
+		Config:           _appConfig,
+		DisableDiscovery: _appConfig.Devnet, // A standalone node
+	}); err != nil {
+		_appConfig.Services.Log.Fatalf("error creating p2p server: %s", err.Error())
+	}
+
+	// Create a new (web) server
+	webServer := webserver.NewServer(_appConfig, p2pServer)
+
+	// Stopped at shutdown (the systemd watchdog keepalives)
+	notifyCtx, stopNotify := context.WithCancel(context.Background())
+	defer stopNotify()
+
+	// Sync a channel to listen for interrupts
+	idleConnectionsClosed := make(chan struct{})
+	go func(appConfig *config.Config) {
+		sigint := make(chan os.Signal, 1)
+		signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
+		restart := make(chan os.Signal, 1)
+		handoff.NotifyRestart(restart)
+
+		// Log when a signal is received (or the service is stopped)
+		// On a restart signal the new process takes over the listeners, this one shuts down once it is ready
+		appConfig.Services.Log.Info("waiting for interrupt signal")
+		handedOff := false
+		for wait := true; wait; {
+			select {
+			case <-sigint:
+				appConfig.Services.Log.Info("interrupt signal received, starting shutdown process")
+				wait = false
+			case <-ctx.Done():
+				appConfig.Services.Log.Info("stop requested, starting shutdown process")
+				wait = false
+			case <-restart:
+				handedOff = restartProcess(appConfig, guard)
+				wait = !handedOff
+			}
+		}
+		stopNotify()
+		if watchdog != nil {
+			watchdog.Stop()
+		}
+		budgetMonitor.Stop()
+		if !handedOff { // systemd is told by the new process (MAINPID)
+			if _, err = systemd.Stopping(); err != nil {
+				appConfig.Services.Log.Infof("error notifying systemd: %s", err.Error())
+			}
+		}
+
+		// Shut down in order, each stage with its own deadline (a half-processed alert is finished before
+		// the P2P server and the datastore it uses are closed)
+		manager := shutdown.New(appConfig.Services.Log)
+		manager.Add("stop the alert intake", appConfig.Shutdown.P2P, p2pServer.StopIntake)
+		manager.Add("finish the alerts being processed", appConfig.Shutdown.Alerts, p2pServer.DrainAlerts)
+		manager.Add("deliver the queued notifications", appConfig.Shutdown.Notifications, p2pServer.FlushNotifications)
+		manager.Add("close the web server", appConfig.WebServer.ShutdownTimeout, webServer.Shutdown)
+		manager.Add("close the p2p server", appConfig.Shutdown.P2P, p2pServer.Stop)
+		manager.Add("flush the telemetry", appConfig.WebServer.ShutdownTimeout, func(ctx context.Context) error {
+			return flushTelemetry(ctx, appConfig, tracerProvider, statsd)
+		})
+		manager.Add("close the datastore", appConfig.Shutdown.Datastore, func(ctx context.Context) error {
+			closeAll(ctx)
+			return nil
+		})
+		if err = manager.Shutdown(context.Background()); err != nil {
+			appConfig.Services.Log.Errorf("error shutting down: %s", err.Error())
+		}
+
+		close(idleConnectionsClosed)
+		if err = appConfig.Services.Log.CloseWriter(); err != nil {
+			log.Printf("error closing logger: %s", err)
+		}
+	}(_appConfig)
+
+	// Start the p2p server
+	if err = p2pServer.Start(context.Background()); err != nil {
+		_appConfig.Services.Log.Fatalf("error starting p2p server: %s", err.Error())
+	}
+
+	// Tell the previous process (after a restart) and systemd we are ready once the web server is listening
+	go notifyReady(notifyCtx, _appConfig, p2pServer, webServer)
+
+	// Serve the web server and then wait endlessly
+	webServer.Serve()
+
+	// Wait for the idle connection to close
+	<-idleConnectionsClosed
+	return exitOK
+}
+
+// notifyReady will tell the previous process (after a restart) and systemd we are ready once the web server is
+// listening (the datastore and P2P are up by then) and, if WatchdogSec is set, send the watchdog keepalives while
+// the alert system is healthy
+func notifyReady(ctx context.Context, appConfig *config.Config, p2pServer *p2p.Server, webServer *webserver.Server) {
+	select {
+	case <-webServer.Listening():
+	case <-ctx.Done():
+		return
+	}
+	if handoff.Restarted() {
+		if err := handoff.Ready(); err != nil {
+			appConfig.Services.Log.Errorf("error notifying the previous process: %s", err.Error())
+		}
+		if _, err := systemd.MainPID(os.Getpid()); err != nil {
+			appConfig.Services.Log.Errorf("error notifying systemd: %s", err.Error())
+		}
+	}
+	if sent, err := systemd.Ready(); err != nil {
+		appConfig.Services.Log.Errorf("error notifying systemd: %s", err.Error())
+		return
+	} else if !sent {
+		return
+	}
+	appConfig.Services.Log.Info("notified systemd the alert system is ready")
+
+	interval, err := systemd.WatchdogInterval()
+	if err != nil {
+		appConfig.Services.Log.Errorf("error reading the systemd watchdog: %s", err.Error())
+		return
+	} else if interval == 0 {
+		return
+	}
+	appConfig.Services.Log.Infof("sending systemd watchdog keepalives every %s while healthy", interval)
+	systemd.RunWatchdog(ctx, interval, func(ctx context.Context) error {
+		return p2pServer.Health(ctx).Err()
+	}, func(err error) {
+		appConfig.Services.Log.Warnf("skipped systemd watchdog keepalive: %s", err.Error())
+	})
+}
+
+// restartProcess will start the new process with the listeners and the instance lock and wait until it is ready
+// (true once it took over, the alert system keeps running if it failed)
+func restartProcess(appConfig *config.Config, guard *instance.Guard) bool {
+	appConfig.Services.Log.Info("restart signal received, starting the new process")
+	ctx, cancel := context.WithTimeout(context.Background(), appConfig.Shutdown.Restart)
+	defer cancel()
+	pid, err := handoff.Restart(ctx, map[string]*os.File{"lock": guard.File()})
+	if err != nil {
+		appConfig.Services.Log.Errorf("error restarting, the alert system keeps running: %s", err.Error())
+		return false
+	}
+	guard.Handoff()
+	appConfig.Services.Log.Infof("new process %d is ready, starting shutdown process", pid)
+	return true
+}
+
+// newProfilingWatchdog will create the watchdog capturing the CPU and heap profiles under sustained resource pressure
+func newProfilingWatchdog(appConfig *config.Config) *profiling.Watchdog {
+	conf := appConfig.Profiling
+	opts := profiling.WatchdogOptions{
+		CPUProfileDuration: conf.CPUProfileDuration,
+		Dir:                conf.Dir,
+		Interval:           conf.Interval,
+		MaxProfiles:        conf.MaxProfiles,
+		OnCapture: func(reason string, usage profiling.Usage, paths []string) {
+			appConfig.Services.Log.Warnf("sustained %s pressure (cpu %.0f%%, memory %d MB), profiles written to %s",
+				reason, usage.CPUPercent, usage.MemoryBytes>>20, strings.Join(paths, ", "))
+		},
+		OnError: func(err error) {
+			appConfig.Services.Log.Errorf("error in the profiling watchdog: %s", err.Error())
+		},
+		Sustained: conf.Sustained,
+	}
+	if conf.CPUPercent > 0 {
+		opts.CPUPercent = conf.CPUPercent
+	}
+	if conf.MemoryMB > 0 {
+		opts.MemoryBytes = uint64(conf.MemoryMB) << 20
+	}
+	return profiling.NewWatchdog(opts)
+}
+
+// newBudgetMonitor will set the soft memory limit and create the monitor warning once the memory or the concurrent
+// handlers approach their limit
+func newBudgetMonitor(appConfig *config.Config) *budget.Monitor {
+	conf := appConfig.Budget
+	memoryLimit, fromEnv := budget.SetMemoryLimit(int64(conf.MemoryLimitMB) << 20)
+	if fromEnv {
+		appConfig.Services.Log.Infof("using the memory limit of GOMEMLIMIT (%d MB)", memoryLimit>>20)
+	}
+	return budget.NewMonitor(budget.MonitorOptions{
+		Interval:    conf.Interval,
+		Limiters:    []*budget.Limiter{appConfig.Services.APIHandlers, appConfig.Services.StreamHandlers},
+		MemoryLimit: memoryLimit,
+		OnWarning: func(resource string, used, limit uint64) {
+			unit := ""
+			if resource == budget.ResourceMemory {
+				used, limit, unit = used>>20, limit>>20, " MB"
+			}
+			appConfig.Services.Log.Warnf(
+				"%s budget is approaching its limit: %d%s of %d%s used", resource, used, unit, limit, unit,
+			)
+		},
+		WarnPercent: conf.WarnPercent,
+	})
+}
+
+// flushTelemetry will export the remaining spans, push the remaining metrics and send the remaining error reports
+func flushTelemetry(ctx context.Context, appConfig *config.Config, tracerProvider *tracing.Provider, statsd *metrics.StatsD) error {
+	var errs []error
+	if tracerProvider != nil {
+		if err := tracerProvider.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("tracing: %w", err))
+		}
+	}
+	if statsd != nil {
+		if err := statsd.Stop(); err != nil {
+			errs = append(errs, fmt.Errorf("statsd: %w", err))
+		}
+	}
+	if appConfig.Services.Reporter != nil {
+		if err := appConfig.Services.Reporter.Flush(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("error reports: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// newAuditLog will open the audit log (the file or the datastore table) and continue its hash chain
+func newAuditLog(ctx context.Context, appConfig *config.Config) (*audit.Log, error) {
+	if appConfig.Audit.Output == config.AuditOutputDatastore {
+		return audit.New(ctx, models.NewAuditStore(model.WithAllDependencies(appConfig)))
+	}
+	store, err := audit.NewFileStore(appConfig.Audit.File)
+	if err != nil {
+		return nil, err
+	}
+	return audit.New(ctx, store)
+}
+
+// configDetails will return the audit details of the loaded configuration (the file and a hash of the settings)
+func configDetails(appConfig *config.Config) map[string]string {
+	details := make(map[string]string)
+	if file := os.Getenv(config.EnvironmentCustomFilePath); len(file) > 0 {
+		details["file"] = file
+	}
+	if b, err := json.Marshal(appConfig); err == nil {
+		sum := sha256.Sum256(b)
+		details["sha256"] = hex.EncodeToString(sum[:])
+	}
+	return details
+}
@@ -0,0 +1,29 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// runWindowsService will return false, there is no Windows service manager
+func runWindowsService(_ []string) (code int, ok bool) {
+	return exitOK, false
+}
+
+// newServiceCommand will create the service command, Windows services are only supported on Windows
+// (the flags are not parsed, the command only returns the usage exit code)
+func newServiceCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:                "service install|uninstall|start|stop",
+		Short:              "install, uninstall, start or stop the Windows service",
+		DisableFlagParsing: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			fmt.Fprintln(os.Stderr, "the service command is only supported on Windows (use systemd on Linux, see the README)")
+			return exitCode(exitUsage)
+		},
+	}
+}
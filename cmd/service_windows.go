@@ -0,0 +1,241 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// Windows service defaults
+const (
+	defaultServiceName = "alert-system"                                // Service and event log source name
+	serviceDescription = "Bitcoin SV alert system (P2P alert network)" // Shown in the services console
+	serviceDisplayName = "BSV Alert System"                            // Shown in the services console
+	serviceRestartWait = 10 * time.Second                              // Delay before the service is restarted after a failure
+	serviceResetPeriod = 24 * 60 * 60                                  // Seconds without failure before the failure count is reset
+)
+
+// Windows service errors
+var (
+	errServiceExists      = errors.New("service already exists (uninstall it first)")
+	errServiceStopTimeout = errors.New("timed out waiting for the service to stop")
+)
+
+// windowsService runs the registered command (serve) until the service is stopped
+type windowsService struct {
+	args []string
+}
+
+// Execute will serve the alert system and stop it when the service manager asks for it
+func (w *windowsService) Execute(_ []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	changes <- svc.Status{State: svc.StartPending}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan int, 1)
+	go func() {
+		done <- run(ctx, w.args)
+	}()
+	changes <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	var code int
+	for {
+		select {
+		case code = <-done: // Stopped on its own (e.g. the node could not be reached)
+			return code != exitOK, uint32(code) //nolint:gosec // Exit codes are small
+		case r := <-requests:
+			switch r.Cmd {
+			case svc.Interrogate:
+				changes <- r.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				cancel()
+				code = <-done
+				return code != exitOK, uint32(code) //nolint:gosec // Exit codes are small
+			}
+		}
+	}
+}
+
+// runWindowsService will serve the alert system as a Windows service if started by the service manager
+// (the args are the serve command and flags registered by service install), ok is false otherwise
+func runWindowsService(args []string) (code int, ok bool) {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return exitOK, false
+	}
+	if err = svc.Run(defaultServiceName, &windowsService{args: args}); err != nil {
+		return exitError, true
+	}
+	return exitOK, true
+}
+
+// serviceOptions are the flags of the service command
+type serviceOptions struct {
+	configs *configFlags
+	name    string
+	timeout time.Duration
+}
+
+// newServiceCommand will create the service command
+func newServiceCommand() *cobra.Command {
+	o := &serviceOptions{}
+	cmd := &cobra.Command{
+		Use:       "service install|uninstall|start|stop",
+		Short:     "install, uninstall, start or stop the Windows service",
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{"install", "uninstall", "start", "stop"},
+		RunE: func(_ *cobra.Command, args []string) error {
+			return exitWith(o.run(args[0]))
+		},
+	}
+	o.configs = newConfigFlags(cmd.Flags())
+	cmd.Flags().StringVar(&o.name, "name", defaultServiceName, "service name (also the event log source, set log_syslog.tag to match)")
+	cmd.Flags().DurationVar(&o.timeout, "timeout", 60*time.Second, "max time to wait for the service to stop (stop)")
+	return cmd
+}
+
+// run will install, uninstall, start or stop the Windows service and return the exit code
+//
+//	install   registers the service (started automatically, restarted on failure) and its event log source
+//	uninstall removes the service and its event log source
+//	start     starts the service
+//	stop      stops the service and waits until it is stopped
+func (o *serviceOptions) run(action string) int {
+
+	manager, err := mgr.Connect()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error connecting to the service manager: %s\n", err.Error())
+		return exitError
+	}
+	defer func() {
+		_ = manager.Disconnect()
+	}()
+
+	switch action {
+	case "install":
+		err = installService(manager, o.name, o.configs)
+	case "uninstall":
+		err = uninstallService(manager, o.name)
+	case "start":
+		err = startService(manager, o.name)
+	case "stop":
+		err = stopService(manager, o.name, o.timeout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error running service %s %s: %s\n", action, o.name, err.Error())
+		return exitError
+	}
+	fmt.Printf("service %s: %s done\n", o.name, action)
+	return exitOK
+}
+
+// installService will register the service to run the serve command (with the config flags)
+func installService(manager *mgr.Mgr, name string, configs *configFlags) error {
+	if s, err := manager.OpenService(name); err == nil {
+		_ = s.Close()
+		return errServiceExists
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	// The service runs in another directory, the config file path must be absolute
+	args := []string{"serve"}
+	if len(configs.file) > 0 {
+		var file string
+		if file, err = filepath.Abs(configs.file); err != nil {
+			return err
+		}
+		args = append(args, "--config", file)
+	}
+	if len(configs.environment) > 0 {
+		args = append(args, "--env", configs.environment)
+	}
+
+	var s *mgr.Service
+	if s, err = manager.CreateService(name, exe, mgr.Config{
+		Description: serviceDescription,
+		DisplayName: serviceDisplayName,
+		StartType:   mgr.StartAutomatic,
+	}, args...); err != nil {
+		return err
+	}
+	defer func() {
+		_ = s.Close()
+	}()
+	if err = s.SetRecoveryActions([]mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: serviceRestartWait},
+	}, serviceResetPeriod); err != nil {
+		_ = s.Delete()
+		return err
+	}
+	if err = eventlog.InstallAsEventCreate(name, eventlog.Error|eventlog.Warning|eventlog.Info); err != nil {
+		_ = s.Delete()
+		return err
+	}
+	return nil
+}
+
+// uninstallService will remove the service and its event log source
+func uninstallService(manager *mgr.Mgr, name string) error {
+	s, err := manager.OpenService(name)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = s.Close()
+	}()
+	if err = s.Delete(); err != nil {
+		return err
+	}
+	return eventlog.Remove(name)
+}
+
+// startService will start the service
+func startService(manager *mgr.Mgr, name string) error {
+	s, err := manager.OpenService(name)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = s.Close()
+	}()
+	return s.Start()
+}
+
+// stopService will stop the service and wait until it is stopped
+func stopService(manager *mgr.Mgr, name string, timeout time.Duration) error {
+	s, err := manager.OpenService(name)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = s.Close()
+	}()
+	var status svc.Status
+	if status, err = s.Control(svc.Stop); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(timeout)
+	for status.State != svc.Stopped {
+		if time.Now().After(deadline) {
+			return errServiceStopTimeout
+		}
+		time.Sleep(500 * time.Millisecond)
+		if status, err = s.Query(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/simulation"
+	"github.com/spf13/cobra"
+)
+
+// simulateOptions are the flags of the simulate command
+type simulateOptions struct {
+	asJSON   bool
+	configs  *configFlags
+	input    string
+	maxDelay time.Duration
+	speed    float64
+}
+
+// newSimulateCommand will create the simulate command
+func newSimulateCommand() *cobra.Command {
+	o := &simulateOptions{}
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "replay an exported alert history against the mock node and report the throughput",
+		Args:  cobra.NoArgs,
+		RunE:  runCommand(o.run),
+	}
+	o.configs = newConfigFlags(cmd.Flags())
+	cmd.Flags().StringVar(&o.input, "input", "", "export file to replay (JSON lines written by the export command)")
+	cmd.Flags().Float64Var(&o.speed, "speed", 0, "replay speed, e.g. 3600 replays an hour of history per second (0 replays the alerts back to back)")
+	cmd.Flags().DurationVar(&o.maxDelay, "max-delay", 0, "cap of the wait between two alerts (0 for no cap)")
+	cmd.Flags().BoolVar(&o.asJSON, "json", false, "print the report as JSON (with the result of each alert)")
+	return cmd
+}
+
+// run will replay a recorded alert history (an export file) through the alert pipeline against the mock node
+// and return the exit code, the throughput report is printed (text or JSON)
+func (o *simulateOptions) run() int {
+	if len(o.input) == 0 {
+		fmt.Fprintln(os.Stderr, "the export file to replay is required (--input)")
+		return exitUsage
+	}
+	if len(o.configs.environment) == 0 && len(o.configs.file) == 0 {
+		o.configs.environment = config.EnvironmentSimulation
+	}
+
+	file, err := os.Open(o.input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error opening %s: %s\n", o.input, err.Error())
+		return exitError
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	var alerts []*simulation.Alert
+	if alerts, err = simulation.ReadExport(file); err != nil {
+		fmt.Fprintf(os.Stderr, "error reading %s: %s\n", o.input, err.Error())
+		return exitError
+	}
+
+	// Stopped on Ctrl-C (between two alerts)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var conf *config.Config
+	if conf, err = o.configs.load(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "error loading configuration: %s\n", err.Error())
+		return exitError
+	}
+	defer conf.CloseAll(context.Background())
+
+	var report *simulation.Report
+	if report, err = simulation.Run(ctx, conf, alerts, simulation.Options{
+		MaxDelay: o.maxDelay,
+		Speed:    o.speed,
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "error running the simulation: %s\n", err.Error())
+		return exitError
+	}
+
+	if o.asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(report)
+	} else {
+		for _, result := range report.Results {
+			if len(result.Error) > 0 {
+				fmt.Printf("alert %d (%s): %s\n", result.SequenceNumber, result.AlertType, result.Error)
+			}
+		}
+		fmt.Printf("alerts:     %d (%d processed, %d failed, %d rejected)\n",
+			report.Alerts, report.Processed, report.Failed, report.Rejected)
+		fmt.Printf("elapsed:    %s (processing %s)\n", report.Elapsed.Round(time.Millisecond), report.Processing.Round(time.Microsecond))
+		fmt.Printf("throughput: %.1f alerts/s\n", report.Throughput)
+		fmt.Printf("latency:    p50 %s, p99 %s, max %s\n", report.P50, report.P99, report.Max)
+	}
+	if report.Processed < report.Alerts {
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bitcoin-sv/alert-system/app"
+	"github.com/bitcoin-sv/alert-system/app/cluster"
+	"github.com/bitcoin-sv/alert-system/app/health"
+	"github.com/bitcoin-sv/alert-system/app/p2p"
+	"github.com/spf13/cobra"
+)
+
+// Status command exit codes
+const (
+	statusExitHealthy     = 0 // All critical checks passed (ok or degraded)
+	statusExitUnhealthy   = 1 // A critical check failed
+	statusExitUnreachable = 2 // The alert system could not be reached (or refused the request)
+)
+
+// Environment variables of the admin API settings (the same variables as the configuration)
+const (
+	envAdminSocket = "ALERT_SYSTEM_WEB_SERVER__ADMIN_SOCKET"
+	envAdminToken  = "ALERT_SYSTEM_WEB_SERVER__ADMIN_TOKEN"
+)
+
+// socketHost is the host of the admin API requests sent on the admin socket (any host is served)
+const socketHost = "alert-system"
+
+// statusOptions are the flags of the status command
+type statusOptions struct {
+	adminURL   string
+	asJSON     bool
+	healthOnly bool
+	socket     string
+	timeout    time.Duration
+	token      string
+	url        string
+}
+
+// newStatusCommand will create the status command
+func newStatusCommand() *cobra.Command {
+	o := &statusOptions{}
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "print the status summary of a running alert system",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			o.healthOnly = o.healthOnly || cmd.Flags().Changed("url")
+			return exitWith(o.run())
+		},
+	}
+	cmd.Flags().StringVar(&o.adminURL, "admin", "http://localhost:3000", "admin API URL of the running alert system")
+	cmd.Flags().StringVar(&o.socket, "socket", os.Getenv(envAdminSocket), "admin socket of the running alert system (no token needed)")
+	cmd.Flags().StringVar(&o.token, "token", os.Getenv(envAdminToken), "admin token (defaults to "+envAdminToken+")")
+	cmd.Flags().BoolVar(&o.healthOnly, "health", false, "print the aggregated health only (the /readyz document)")
+	cmd.Flags().StringVar(&o.url, "url", "http://localhost:3000/readyz", "readiness URL of the running alert system (implies --health)")
+	cmd.Flags().BoolVar(&o.asJSON, "json", false, "print the status document as JSON")
+	cmd.Flags().DurationVar(&o.timeout, "timeout", 10*time.Second, "max time to wait for the response")
+	return cmd
+}
+
+// run will print the status summary of a running alert system (peers, sequences, node health and backlog) from
+// the admin API, on the admin socket or with the admin token, and return the exit code
+// With --health (or --url) it prints the aggregated health instead (the /readyz document, no admin access needed)
+func (o *statusOptions) run() int {
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+	defer cancel()
+	if o.healthOnly {
+		return healthStatus(ctx, o.url, o.asJSON)
+	}
+
+	// Get the status summary (on the admin socket if set)
+	client, base := http.DefaultClient, strings.TrimRight(o.adminURL, "/")
+	if len(o.socket) > 0 {
+		client, base = socketClient(o.socket), "http://"+socketHost
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+app.APIVersion1+"/admin/status", nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid url: %s\n", err.Error())
+		return statusExitUnreachable
+	}
+	if len(o.socket) == 0 && len(o.token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+o.token)
+	}
+	var res *http.Response
+	if res, err = client.Do(req); err != nil {
+		fmt.Fprintf(os.Stderr, "alert system is not reachable: %s\n", err.Error())
+		return statusExitUnreachable
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+		fmt.Fprintf(os.Stderr, "the admin API refused the request (%s), set --token or --socket\n", res.Status)
+		return statusExitUnreachable
+	}
+
+	// Read the status document (the body is not a status if the P2P server is not running)
+	st := &p2p.Status{}
+	if err = json.NewDecoder(res.Body).Decode(st); err != nil || st.Health == nil {
+		fmt.Fprintf(os.Stderr, "unexpected response from %s: %s\n", req.URL.String(), res.Status)
+		return statusExitUnreachable
+	}
+	if o.asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(st)
+	} else {
+		printStatus(st)
+	}
+	if !st.Health.Healthy() {
+		return statusExitUnhealthy
+	}
+	return statusExitHealthy
+}
+
+// healthStatus will print the aggregated health of a running alert system (the /readyz document)
+// and return the exit code
+func healthStatus(ctx context.Context, url string, asJSON bool) int {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid url: %s\n", err.Error())
+		return statusExitUnreachable
+	}
+	var res *http.Response
+	if res, err = http.DefaultClient.Do(req); err != nil {
+		fmt.Fprintf(os.Stderr, "alert system is not reachable: %s\n", err.Error())
+		return statusExitUnreachable
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	// Read the status document (the body is not a report if the P2P server is not running)
+	report := &health.Report{}
+	if err = json.NewDecoder(res.Body).Decode(report); err != nil || len(report.Status) == 0 {
+		fmt.Fprintf(os.Stderr, "unexpected response from %s: %s\n", url, res.Status)
+		return statusExitUnreachable
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(report)
+	} else {
+		fmt.Println(report.String())
+	}
+	if !report.Healthy() {
+		return statusExitUnhealthy
+	}
+	return statusExitHealthy
+}
+
+// socketClient will return an HTTP client sending the requests on the Unix socket
+func socketClient(path string) *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+}
+
+// printStatus will print the status summary, one line per topic (the failing health checks are listed)
+func printStatus(st *p2p.Status) {
+	fmt.Printf("alert system %s, peer %s\n", st.Version, st.PeerID)
+	fmt.Printf("health        %s\n", st.Health.Status)
+	for _, c := range st.Health.Checks {
+		if c.Status != health.StatusOK {
+			fmt.Println(strings.TrimRight(fmt.Sprintf("  %-11s %s %s", c.Name, c.Status, c.Error), " "))
+		}
+	}
+	fmt.Printf("uptime        %s\n", time.Duration(st.UptimeSeconds)*time.Second)
+	if st.Cluster != nil {
+		if st.Cluster.Role == cluster.RoleLeader {
+			fmt.Printf("cluster       leader %s (term %d)\n", st.Cluster.Instance, st.Cluster.Term)
+		} else {
+			fmt.Printf("cluster       standby %s, leader %s (term %d)\n", st.Cluster.Instance, st.Cluster.Leader, st.Cluster.Term)
+		}
+	}
+	fmt.Printf("peers         %d\n", st.PeerCount)
+	sequence := fmt.Sprintf("latest %d, best seen %d", st.LatestSequence, st.BestSequence)
+	if st.LastSyncedAt != nil {
+		sequence += ", last synced " + st.LastSyncedAt.Format(time.RFC3339)
+	}
+	fmt.Printf("sequence      %s\n", sequence)
+	if st.NodeHealthy {
+		fmt.Println("node          healthy")
+	} else {
+		fmt.Printf("node          unhealthy (%s)\n", st.NodeError)
+	}
+	fmt.Printf("pending       %d alerts, %d outbox events, %d queued messages\n",
+		st.PendingAlerts, st.PendingEvents, st.QueuedAlerts)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bitcoin-sv/alert-system/app/config"
+	"github.com/bitcoin-sv/alert-system/app/notify"
+	"github.com/spf13/cobra"
+)
+
+// validateConfigOptions are the flags of the validate-config command
+type validateConfigOptions struct {
+	configs *configFlags
+}
+
+// newValidateConfigCommand will create the validate-config command
+func newValidateConfigCommand() *cobra.Command {
+	o := &validateConfigOptions{}
+	cmd := &cobra.Command{
+		Use:   "validate-config",
+		Short: "load and validate the configuration without starting anything",
+		Args:  cobra.NoArgs,
+		RunE:  runCommand(o.run),
+	}
+	o.configs = newConfigFlags(cmd.Flags())
+	return cmd
+}
+
+// run will load the configuration and check it (including the notification channels, templates and routing
+// rules) without connecting to the node or the datastore, and return the exit code
+func (o *validateConfigOptions) run() int {
+	if err := o.configs.apply(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid flags: %s\n", err.Error())
+		return exitUsage
+	}
+	conf, err := config.ValidateConfigFile()
+	if err == nil {
+		_, err = notify.New(conf)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration: %s\n", err.Error())
+		return exitError
+	}
+	fmt.Printf("configuration is valid (%s)\n", os.Getenv(config.EnvironmentKey))
+	return exitOK
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/bitcoin-sv/alert-system/app/buildinfo"
+	"github.com/spf13/cobra"
+)
+
+// versionOptions are the flags of the version command
+type versionOptions struct {
+	asJSON bool
+}
+
+// newVersionCommand will create the version command
+func newVersionCommand() *cobra.Command {
+	o := &versionOptions{}
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "print the build info",
+		Args:  cobra.NoArgs,
+		RunE:  runCommand(o.run),
+	}
+	cmd.Flags().BoolVar(&o.asJSON, "json", false, "print the build info as JSON")
+	return cmd
+}
+
+// run will print the build info of the alert system binary and return the exit code
+func (o *versionOptions) run() int {
+	info := buildinfo.Get()
+	if o.asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(info)
+	} else {
+		fmt.Println("alert-system " + info.String())
+	}
+	return exitOK
+}
@@ -0,0 +1,9 @@
+package alertsystem
+
+import "errors"
+
+// ErrAlreadyRunning is returned when the alert system is run twice
+var ErrAlreadyRunning = errors.New("alert system is already running")
+
+// ErrStopped is returned when a stopped alert system is run again (create a new one with New)
+var ErrStopped = errors.New("alert system is stopped")
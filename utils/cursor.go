@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// Page size limits for cursor pagination
+const (
+	DefaultPageSize = 100  // Default number of items per page
+	MaxPageSize     = 1000 // Max number of items per page
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// Cursor is the position of the last item returned in a page (keyset pagination, no OFFSET scans)
+// The next page starts after the item with this sequence (or ID for string keyed lists)
+type Cursor struct {
+	ID        string `json:"i,omitempty"` // String key of the last item (lists not keyed by a number, e.g. peers)
+	Sequence  uint64 `json:"s,omitempty"` // Numeric key of the last item (alert sequence number, record ID)
+	Timestamp int64  `json:"t,omitempty"` // Unix timestamp of the last item (when it was created)
+}
+
+// Encode will encode the cursor into an opaque (URL safe) string
+func (c *Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor will decode an opaque cursor string (an empty string is no cursor, the first page)
+func DecodeCursor(cursor string) (*Cursor, error) {
+	if len(cursor) == 0 {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c := new(Cursor)
+	if err = json.Unmarshal(b, c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// PageSize will return a valid page size (the default if not set, capped at the max)
+func PageSize(limit int) int {
+	if limit <= 0 {
+		return DefaultPageSize
+	} else if limit > MaxPageSize {
+		return MaxPageSize
+	}
+	return limit
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCursor_Encode tests the method Encode() and the function DecodeCursor()
+func TestCursor_Encode(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		c := &Cursor{Sequence: 42, Timestamp: 1700000000}
+		decoded, err := DecodeCursor(c.Encode())
+		require.NoError(t, err)
+		assert.Equal(t, c, decoded)
+	})
+
+	t.Run("round trip with a string key", func(t *testing.T) {
+		c := &Cursor{ID: "12D3KooWPeer"}
+		decoded, err := DecodeCursor(c.Encode())
+		require.NoError(t, err)
+		assert.Equal(t, c, decoded)
+	})
+
+	t.Run("empty cursor is the first page", func(t *testing.T) {
+		decoded, err := DecodeCursor("")
+		require.NoError(t, err)
+		assert.Nil(t, decoded)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, err := DecodeCursor("not a cursor!")
+		require.ErrorIs(t, err, ErrInvalidCursor)
+
+		_, err = DecodeCursor("bm90LWpzb24")
+		require.ErrorIs(t, err, ErrInvalidCursor)
+	})
+}
+
+// TestPageSize tests the function PageSize
+func TestPageSize(t *testing.T) {
+	assert.Equal(t, DefaultPageSize, PageSize(0))
+	assert.Equal(t, DefaultPageSize, PageSize(-5))
+	assert.Equal(t, 25, PageSize(25))
+	assert.Equal(t, MaxPageSize, PageSize(MaxPageSize+1))
+}
@@ -3,7 +3,23 @@ package utils
 // Universal fields for the application
 const (
 	FieldActive         = "active"          // Active is boolean field for active models
+	FieldAlertType      = "alert_type"      // AlertType is the alert type
+	FieldCluster        = "cluster"         // Cluster is the cluster name of a cluster lease
+	FieldCreatedAt      = "created_at"      // Created at timestamp on every model
+	FieldDedupKey       = "dedup_key"       // DedupKey is the unique key of an outbox event
 	FieldDeletedAt      = "deleted_at"      // Deleted at timestamp on every model
+	FieldDeliveryID     = "delivery_id"     // DeliveryID is the webhook delivery of an attempt
+	FieldExpiresAt      = "expires_at"      // ExpiresAt is the expiration of a peer ban, cluster lease or alert lock
+	FieldHolder         = "holder"          // Holder is the instance holding a cluster lease or alert lock
 	FieldID             = "id"              // ID is a generic id for many models
+	FieldIdempotencyKey = "idempotency_key" // IdempotencyKey is the key sent with every attempt of a webhook delivery
+	FieldPeerID         = "peer_id"         // PeerID is the libp2p peer ID
+	FieldRPCHost        = "rpc_host"        // RPCHost is the host of the node RPC connection
+	FieldSearchField    = "field"           // SearchField is the searchable field of an alert search term
+	FieldSearchValue    = "value"           // SearchValue is the searchable value of an alert search term
 	FieldSequenceNumber = "sequence_number" // SequenceNumber is used for the alert message sequencing
+	FieldStatus         = "status"          // Status is the delivery status of a webhook delivery or outbox event
+	FieldTerm           = "term"            // Term is the leader term of a cluster lease (incremented on each new leader)
+	FieldUpdatedAt      = "updated_at"      // Updated at timestamp on every model
+	FieldWebhookID      = "webhook_id"      // WebhookID is the registered webhook of a delivery
 )
@@ -12,6 +12,9 @@ const (
 	// GreaterThanCondition is the greater than condition for database queries
 	GreaterThanCondition = "$gt"
 
+	// InCondition is the in (list of values) condition for database queries
+	InCondition = "$in"
+
 	// LessThanOrEqualCondition is the less than or equal condition for database queries
 	LessThanOrEqualCondition = "$lte"
 